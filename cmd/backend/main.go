@@ -26,24 +26,44 @@ import (
 	authAPI "go-backend/internal/backend/auth/api"
 	"go-backend/internal/backend/auth/provider"
 	authService "go-backend/internal/backend/auth/service"
+	budgetAPI "go-backend/internal/backend/budget/api"
+	budgetRepo "go-backend/internal/backend/budget/repo"
+	budgetService "go-backend/internal/backend/budget/service"
+	categoryAPI "go-backend/internal/backend/category/api"
+	categoryRepo "go-backend/internal/backend/category/repo"
+	categoryService "go-backend/internal/backend/category/service"
 	"go-backend/internal/backend/config"
+	dedupAPI "go-backend/internal/backend/dedup/api"
+	dedupRepo "go-backend/internal/backend/dedup/repo"
+	dedupService "go-backend/internal/backend/dedup/service"
 	favoritesAPI "go-backend/internal/backend/favorite/api"
 	favoritesRepo "go-backend/internal/backend/favorite/repo"
 	favoritesService "go-backend/internal/backend/favorite/service"
 	listAPI "go-backend/internal/backend/list/api"
 	listRepo "go-backend/internal/backend/list/repo"
 	listService "go-backend/internal/backend/list/service"
+	pantryAPI "go-backend/internal/backend/pantry/api"
+	pantryRepo "go-backend/internal/backend/pantry/repo"
+	pantryService "go-backend/internal/backend/pantry/service"
 	productAPI "go-backend/internal/backend/product/api"
 	productRepo "go-backend/internal/backend/product/repo"
 	productService "go-backend/internal/backend/product/service"
+	recipeAPI "go-backend/internal/backend/recipe/api"
+	recipeRepo "go-backend/internal/backend/recipe/repo"
+	recipeService "go-backend/internal/backend/recipe/service"
 	shopMapAPI "go-backend/internal/backend/shopmap/api"
 	shopMapRepo "go-backend/internal/backend/shopmap/repo"
 	shopMapService "go-backend/internal/backend/shopmap/service"
 	swaggerAPI "go-backend/internal/backend/swagger/api"
+	"go-backend/internal/backend/trash"
+	trashAPI "go-backend/internal/backend/trash/api"
+	trashService "go-backend/internal/backend/trash/service"
 	userAPI "go-backend/internal/backend/user/api"
 	userRepo "go-backend/internal/backend/user/repo"
 	userService "go-backend/internal/backend/user/service"
 	"go-backend/pkg/bd"
+	"go-backend/pkg/blob"
+	"go-backend/pkg/clock"
 	"go-backend/pkg/hashing"
 )
 
@@ -129,6 +149,10 @@ func main() {
 	if err != nil {
 		parentLogger.Fatal().Err(err).Msg("can't initialize product storage")
 	}
+	categoryRepo, err := categoryRepo.NewRepo(ctx, gormDB)
+	if err != nil {
+		parentLogger.Fatal().Err(err).Msg("initializing category repo")
+	}
 	favoritesRepo, err := favoritesRepo.NewRepo(ctx, gormDB)
 	if err != nil {
 		parentLogger.Fatal().Err(err).Msg("initializing favorites repo")
@@ -137,6 +161,23 @@ func main() {
 	if err != nil {
 		parentLogger.Fatal().Err(err).Msg("initalizing list repo")
 	}
+	budgetRepo, err := budgetRepo.NewRepo(ctx, gormDB)
+	if err != nil {
+		parentLogger.Fatal().Err(err).Msg("initializing budget repo")
+	}
+	pantryRepo, err := pantryRepo.NewRepo(ctx, gormDB, clock.Real{}.Now())
+	if err != nil {
+		parentLogger.Fatal().Err(err).Msg("initializing pantry repo")
+	}
+	recipeRepo, err := recipeRepo.NewRepo(ctx, gormDB)
+	if err != nil {
+		parentLogger.Fatal().Err(err).Msg("initializing recipe repo")
+	}
+	dedupRepo := dedupRepo.NewRepo(gormDB, productRepo, listRepo, favoritesRepo, pantryRepo, recipeRepo)
+	blobStore, err := newBlobStore(ctx, envCfg.Blob, gormDB)
+	if err != nil {
+		parentLogger.Fatal().Err(err).Msg("initializing blob store")
+	}
 
 	// business logic
 	userService := userService.NewService(userDB, hashing.HashMaster{})
@@ -149,10 +190,50 @@ func main() {
 			RefreshTokenExpires: appCfg.Auth.RefreshTokenLiveTime,
 		},
 	)
-	shopMapService := shopMapService.NewService(parentLogger, userService, shopMapRepo)
 	productService := productService.NewService(productRepo)
-	favoriteService := favoritesService.NewService(favoritesRepo, userService)
-	listService := listService.NewService(listRepo, parentLogger)
+	if err = productService.LoadIndex(ctx); err != nil {
+		parentLogger.Fatal().Err(err).Msg("can't load product search index")
+	}
+	categoryService := categoryService.NewService(categoryRepo, productService, budgetRepo, shopMapRepo, parentLogger)
+	shopMapService := shopMapService.NewService(parentLogger, userService, shopMapRepo, categoryService, clock.Real{})
+	favoriteService := favoritesService.NewService(favoritesRepo, userService, clock.Real{})
+	budgetService := budgetService.NewService(budgetRepo, listRepo, listRepo, clock.Real{}, parentLogger)
+	listService := listService.NewService(
+		listRepo,
+		productService,
+		shopMapService,
+		budgetService,
+		blobStore,
+		clock.Real{},
+		parentLogger,
+	)
+	pantryService := pantryService.NewService(
+		pantryRepo,
+		listRepo,
+		listService,
+		productService,
+		clock.Real{},
+		parentLogger,
+	)
+	recipeService := recipeService.NewService(recipeRepo, listService, productService, clock.Real{}, parentLogger)
+	dedupService := dedupService.NewService(
+		dedupRepo,
+		productRepo,
+		productService,
+		listService,
+		clock.Real{},
+		parentLogger,
+	)
+	trashService := trashService.NewService(
+		map[trash.Kind]trashService.Bin{
+			trash.KindList:      listService,
+			trash.KindShopMap:   shopMapService,
+			trash.KindFavorites: favoriteService,
+		},
+		appCfg.Trash.Retention,
+		clock.Real{},
+		parentLogger,
+	)
 
 	// API
 
@@ -167,12 +248,24 @@ func main() {
 	apiGroup.Use(jwtMiddleware.Middleware())
 
 	productAPI.RegisterREST(apiGroup, productService)
+	categoryAPI.RegisterREST(apiGroup, categoryService, parentLogger)
+	dedupAPI.RegisterREST(apiGroup, dedupService, parentLogger)
 	shopMapAPI.RegisterREST(apiGroup, shopMapService, parentLogger)
 	favoritesAPI.RegisterREST(apiGroup, favoriteService, parentLogger.With().Logger())
 	listAPI.RegisterREST(apiGroup, listService, parentLogger)
+	trashAPI.RegisterREST(apiGroup, trashService, parentLogger)
+	budgetAPI.RegisterREST(apiGroup, budgetService, parentLogger)
+	pantryAPI.RegisterREST(apiGroup, pantryService, parentLogger)
+	recipeAPI.RegisterREST(apiGroup, recipeService, parentLogger)
 
 	listAPI.RegisterWebSocket(apiGroup, listService, parentLogger)
 
+	go listService.RunSchedules(ctx)
+	go listService.RunPurchaseHistories(ctx)
+	go pantryService.RunImports(ctx)
+	go productService.RunIndexRefresh(ctx)
+	go trashService.RunPurge(ctx)
+
 	go func() {
 		if err = router.RunListener(listener); err != nil && ctx.Err() == nil {
 			parentLogger.Fatal().Err(err).Msg("listener returns error")
@@ -211,3 +304,15 @@ func decodeECDSA(pemEncoded string) (*ecdsa.PrivateKey, error) {
 
 	return parsedKey, nil
 }
+
+// newBlobStore opens storage of attachments, files on a local disk are seen by a single replica only
+func newBlobStore(ctx context.Context, cfg config.BlobEnv, db *gorm.DB) (blob.Store, error) {
+	switch cfg.Store {
+	case "db":
+		return blob.NewDBStore(ctx, db) //nolint:wrapcheck // errors of store are wrapped already
+	case "file":
+		return blob.NewFileStore(cfg.Dir) //nolint:wrapcheck // errors of store are wrapped already
+	default:
+		return nil, fmt.Errorf("unknown blob store %q, it must be db or file", cfg.Store)
+	}
+}
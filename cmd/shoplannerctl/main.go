@@ -1,7 +1,54 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+)
+
+// errUsage is returned when command line is wrong, usage is printed for it
+var errUsage = errors.New("wrong usage")
+
+const usage = `usage: shoplannerctl <group> <command> [flags] [args]
+
+commands:
+  products import-off [-batch N] [-state file] <file.jsonl.gz>
+        import Open Food Facts dump into product catalog, it's resumed from the state file when it exists
+
+environment:
+  DB_PATH  path to database of the backend
+`
 
 func main() {
-	fmt.Println("hello shoplannerctl")
+	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.InfoLevel).With().Timestamp().Logger()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err := run(ctx, os.Args[1:], log)
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2) //nolint:gocritic // nothing to clean up
+	} else if err != nil {
+		log.Error().Err(err).Msg("command failed")
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, log zerolog.Logger) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	switch group, command := args[0], args[1]; {
+	case group == "products" && command == "import-off":
+		return importOFF(ctx, args[2:], log)
+	default:
+		return fmt.Errorf("%w: unknown command %s %s", errUsage, group, command)
+	}
 }
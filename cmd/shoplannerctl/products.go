@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/config"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/product/openfoodfacts"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/pkg/myerr"
+)
+
+const defaultImportBatch = 500
+
+// importStats are numbers of lines of dump by outcome
+type importStats struct {
+	Lines     int
+	Created   int
+	Updated   int
+	Skipped   int
+	Malformed int
+	Resumed   int
+}
+
+// importOFF upserts products of Open Food Facts dump by batches, number of imported lines is kept in state file
+// after every batch, so interrupted import continues from the last saved batch
+func importOFF(ctx context.Context, args []string, log zerolog.Logger) error {
+	flags := flag.NewFlagSet("import-off", flag.ContinueOnError)
+	batchSize := flags.Int("batch", defaultImportBatch, "number of products saved in one transaction")
+	statePath := flags.String("state", "", "file with progress of import, <file>.progress by default")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *batchSize <= 0 {
+		return errUsage
+	}
+
+	path := flags.Arg(0)
+	if *statePath == "" {
+		*statePath = path + ".progress"
+	}
+
+	products, err := newProductService(ctx)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	stats, err := importDump(ctx, products, path, *statePath, *batchSize, log)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("lines: %d (resumed after %d)\ncreated: %d\nupdated: %d\nskipped: %d\nmalformed: %d\ntook: %s\n",
+		stats.Lines, stats.Resumed, stats.Created, stats.Updated, stats.Skipped, stats.Malformed,
+		time.Since(started).Round(time.Millisecond))
+
+	return nil
+}
+
+// upserter saves batches of imported products, it's the product service
+type upserter interface {
+	Upsert(ctx context.Context, batch []product.Options) (int, int, error)
+}
+
+// importDump imports dump at path starting after lines saved in state file
+func importDump(
+	ctx context.Context,
+	products upserter,
+	path string,
+	statePath string,
+	batchSize int,
+	log zerolog.Logger,
+) (
+	importStats,
+	error,
+) {
+	var stats importStats
+
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, fmt.Errorf("can't open dump: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := openfoodfacts.NewReader(file)
+	if err != nil {
+		return stats, err
+	}
+	defer reader.Close()
+
+	if stats.Resumed, err = readProgress(statePath); err != nil {
+		return stats, err
+	}
+
+	if err = reader.Skip(stats.Resumed); err != nil {
+		return stats, fmt.Errorf("can't skip %d imported lines: %w", stats.Resumed, err)
+	}
+
+	batch := make([]product.Options, 0, batchSize)
+
+	for done := false; !done; {
+		record, err := reader.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			done = true
+		case errors.Is(err, myerr.ErrInvalidArgument):
+			stats.Malformed++
+			log.Debug().Err(err).Msg("skipped malformed line")
+		case err != nil:
+			return stats, err
+		default:
+			options, err := record.Options()
+			if err != nil {
+				stats.Skipped++
+				log.Debug().Err(err).Msg("skipped product")
+			} else {
+				batch = append(batch, options)
+			}
+		}
+
+		if len(batch) < batchSize && !done {
+			continue
+		}
+
+		if len(batch) != 0 {
+			created, updated, err := products.Upsert(ctx, batch)
+			if err != nil {
+				return stats, fmt.Errorf("can't import batch ending at line %d: %w", reader.Line(), err)
+			}
+
+			stats.Created += created
+			stats.Updated += updated
+			batch = batch[:0]
+		}
+
+		stats.Lines = reader.Line()
+
+		if err = writeProgress(statePath, reader.Line()); err != nil {
+			return stats, err
+		}
+
+		log.Info().Int("line", reader.Line()).Int("created", stats.Created).Int("updated", stats.Updated).
+			Msg("imported batch")
+
+		if ctx.Err() != nil {
+			return stats, fmt.Errorf("import interrupted at line %d, run it again to resume: %w",
+				reader.Line(), ctx.Err())
+		}
+	}
+
+	return stats, nil
+}
+
+func newProductService(ctx context.Context) (*productService.Service, error) {
+	env, err := config.ParseEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(sqlite.Open(env.Database.Path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return nil, fmt.Errorf("can't open database %s: %w", env.Database.Path, err)
+	}
+
+	repo, err := productRepo.NewGormRepo(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return productService.NewService(repo), nil
+}
+
+// readProgress returns number of imported lines, it's zero when import hasn't started
+func readProgress(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("can't read progress of import: %w", err)
+	}
+
+	lines, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || lines < 0 {
+		return 0, fmt.Errorf("%w: progress file %s is corrupted, delete it to start over", myerr.ErrInvalidArgument, path)
+	}
+
+	return lines, nil
+}
+
+// writeProgress replaces progress file atomically, so it's never left half written
+func writeProgress(path string, lines int) error {
+	if err := os.WriteFile(path+".tmp", []byte(strconv.Itoa(lines)+"\n"), 0o600); err != nil {
+		return fmt.Errorf("can't save progress of import: %w", err)
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("can't save progress of import: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/pkg/myerr"
+)
+
+// dump has five products and a malformed line
+const dump = `{"code":"4006381333931","product_name":"milk"}
+{"code":"5901234123457","product_name":"kefir"}
+not json
+{"code":"96385074","product_name":"bread"}
+{"code":"73513537","product_name":"eggs"}
+{"code":"012345678905","product_name":"butter"}
+`
+
+// interrupting cancels the context after the first batch like Ctrl+C does
+type interrupting struct {
+	*productService.Service
+	cancel context.CancelFunc
+}
+
+func (i interrupting) Upsert(ctx context.Context, batch []product.Options) (int, int, error) {
+	defer i.cancel()
+
+	return i.Service.Upsert(ctx, batch)
+}
+
+func writeDump(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "products.jsonl.gz")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(dump))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+
+	return path
+}
+
+func newProducts(t *testing.T) (*productService.Service, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "products.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	repo, err := productRepo.NewGormRepo(context.Background(), db)
+	require.NoError(t, err)
+
+	return productService.NewService(repo), db
+}
+
+func TestImportDumpResumes(t *testing.T) {
+	path := writeDump(t)
+	statePath := path + ".progress"
+	products, db := newProducts(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stats, err := importDump(ctx, interrupting{Service: products, cancel: cancel}, path, statePath, 2, zerolog.Nop())
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, importStats{Lines: 2, Created: 2}, stats)
+
+	lines, err := readProgress(statePath)
+	require.NoError(t, err)
+	require.Equal(t, 2, lines)
+
+	stats, err = importDump(context.Background(), products, path, statePath, 2, zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, importStats{Lines: 6, Created: 3, Malformed: 1, Resumed: 2}, stats)
+
+	var count int64
+	require.NoError(t, db.Model(new(productRepo.Product)).Count(&count).Error)
+	require.EqualValues(t, 5, count)
+
+	// finished import has nothing left
+	stats, err = importDump(context.Background(), products, path, statePath, 2, zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, importStats{Lines: 6, Resumed: 6}, stats)
+
+	// import started over updates products found by barcodes
+	require.NoError(t, os.Remove(statePath))
+
+	stats, err = importDump(context.Background(), products, path, statePath, 2, zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, importStats{Lines: 6, Updated: 5, Malformed: 1}, stats)
+
+	require.NoError(t, db.Model(new(productRepo.Product)).Count(&count).Error)
+	require.EqualValues(t, 5, count)
+}
+
+func TestReadProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.jsonl.gz.progress")
+
+	lines, err := readProgress(path)
+	require.NoError(t, err)
+	require.Zero(t, lines, "import starts from the beginning without progress")
+
+	require.NoError(t, writeProgress(path, 1500))
+
+	lines, err = readProgress(path)
+	require.NoError(t, err)
+	require.Equal(t, 1500, lines)
+
+	for _, content := range []string{"", "15\x0000", "-3\n", "1500 lines"} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err = readProgress(path)
+		require.ErrorIs(t, err, myerr.ErrInvalidArgument, "progress %q", content)
+	}
+
+	// corrupted progress isn't overwritten by the import
+	_, err = importDump(context.Background(), nil, writeDump(t), path, 2, zerolog.Nop())
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "1500 lines", string(content))
+}
@@ -15,6 +15,70 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/products/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products dedup"
+                ],
+                "summary": "get groups of products which look like the same one, only for admins",
+                "operationId": "products-duplicates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "number of skipped groups",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/admin/products/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products dedup"
+                ],
+                "summary": "merge products into the target one which replaces them everywhere, only for admins",
+                "operationId": "products-merge",
+                "parameters": [
+                    {
+                        "description": "merged products and the target one",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dedup.MergeOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
         "/auth/login": {
             "post": {
                 "consumes": [
@@ -102,7 +166,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/favorite/id/{id}": {
+        "/budgets": {
             "get": {
                 "security": [
                     {
@@ -113,24 +177,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Favorites"
+                    "Budgets"
                 ],
-                "summary": "get list of favorite products by id",
-                "operationId": "get-favorite-list-id",
+                "summary": "get budgets which user owns or is a member of",
+                "operationId": "budgets-get",
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "create new budget owned by the user",
+                "operationId": "budget-create",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id of favorites list",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "options of new budget",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/budget.Options"
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/favorite/id/{id}/product": {
-            "post": {
+        "/budgets/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -140,66 +225,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Favorites"
+                    "Budgets"
                 ],
-                "summary": "add new products to list of favorties",
-                "operationId": "add-favorite-products",
+                "summary": "get budget by id",
+                "operationId": "budget-get-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of favorites list",
+                        "description": "budget id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "ids of new products",
-                        "name": "products",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.ProductList"
-                        }
                     }
                 ],
                 "responses": {}
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Favorites"
+                    "Budgets"
                 ],
-                "summary": "remove some products from favorite list",
-                "operationId": "remove-favorite-products",
+                "summary": "update title, period, limits and members of budget, only its owner and admins can do it",
+                "operationId": "budget-update",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of favorites list",
+                        "description": "budget id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "ids of new products",
-                        "name": "products",
+                        "description": "new options of budget",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.ProductList"
+                            "$ref": "#/definitions/budget.Options"
                         }
                     }
                 ],
                 "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "delete budget, only its owner can do it",
+                "operationId": "budget-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "budget id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
             }
         },
-        "/favorite/user": {
+        "/budgets/{id}/status": {
             "get": {
                 "security": [
                     {
@@ -210,14 +311,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Favorites"
+                    "Budgets"
+                ],
+                "summary": "get money spent and left in the budget period",
+                "operationId": "budget-status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "budget id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp or date inside the period, now by default",
+                        "name": "at",
+                        "in": "query"
+                    }
                 ],
-                "summary": "get all favorites lists, related to logged user",
-                "operationId": "get-user-favorites-lists",
                 "responses": {}
             }
         },
-        "/lists": {
+        "/categories": {
             "get": {
                 "security": [
                     {
@@ -228,10 +344,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Categories"
                 ],
-                "summary": "get product lists by user id",
-                "operationId": "product-list-get",
+                "summary": "get all categories sorted by name, children refer parents by ids",
+                "operationId": "categories-get",
                 "responses": {}
             },
             "post": {
@@ -247,25 +363,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Categories"
                 ],
-                "summary": "creates new product list",
-                "operationId": "product-list-create",
+                "summary": "create category, only for admins",
+                "operationId": "category-create",
                 "parameters": [
                     {
-                        "description": "options of new product list",
-                        "name": "opts",
+                        "description": "name, parent, localized names and aliases",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/list.ListOptions"
+                            "$ref": "#/definitions/category.Options"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/lists/{id}": {
+        "/categories/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "merge categories into the target one which replaces them everywhere, only for admins",
+                "operationId": "categories-merge",
+                "parameters": [
+                    {
+                        "description": "merged categories and the target one",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/category.MergeOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/categories/{id}": {
             "get": {
                 "security": [
                     {
@@ -276,14 +424,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Categories"
                 ],
-                "summary": "get product list by list id",
-                "operationId": "product-list-get-by-id",
+                "summary": "get category",
+                "operationId": "category-get-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of product list",
+                        "description": "category id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -304,26 +452,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Categories"
                 ],
-                "summary": "update product list by id",
-                "operationId": "product-list-update-by-id",
+                "summary": "update category, renamed category is renamed in shop maps and budgets, only for admins",
+                "operationId": "category-update",
                 "parameters": [
                     {
-                        "description": "opts to update",
+                        "type": "string",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new options of category",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/list.ListOptions"
+                            "$ref": "#/definitions/category.Options"
                         }
-                    },
-                    {
-                        "type": "string",
-                        "description": "id of product list",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {}
@@ -335,14 +483,14 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "ProductList"
+                    "Categories"
                 ],
-                "summary": "delete product list by id",
-                "operationId": "product-list-delete-by-id",
+                "summary": "delete category without children and products, only for admins",
+                "operationId": "category-delete",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of product list",
+                        "description": "category id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -351,8 +499,8 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/lists/{id}/members": {
-            "post": {
+        "/favorite/id/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -362,28 +510,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Favorites"
                 ],
-                "summary": "add viewers to product list",
-                "operationId": "product-list-add-members",
+                "summary": "get list of favorite products by id",
+                "operationId": "get-favorite-list-id",
                 "parameters": [
-                    {
-                        "description": "users to add",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/list.MemberOptions"
-                            }
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "id of product list",
+                        "description": "id of favorites list",
                         "name": "id",
-                        "in": "path",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -395,33 +531,15 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "ProductList"
+                    "Favorites"
                 ],
-                "summary": "delete members from product list",
-                "operationId": "product-list-delete-members",
+                "summary": "move group list of favorite products to the trash",
+                "operationId": "delete-favorite-list",
                 "parameters": [
-                    {
-                        "description": "id of members to delete",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "product list id",
+                        "description": "id of favorites list",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -430,42 +548,36 @@ const docTemplate = `{
                 "responses": {}
             }
         },
-        "/lists/{id}/products": {
+        "/favorite/id/{id}/product": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ProductList"
+                    "Favorites"
                 ],
-                "summary": "add new products to product list",
-                "operationId": "product-list-add-products",
+                "summary": "add new products to list of favorties",
+                "operationId": "add-favorite-products",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product list id",
+                        "description": "id of favorites list",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "new products",
-                        "name": "body",
+                        "description": "ids of new products",
+                        "name": "products",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "$ref": "#/definitions/api.ProductStateOptions"
-                            }
+                            "$ref": "#/definitions/api.ProductList"
                         }
                     }
                 ],
@@ -477,112 +589,110 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "ProductList"
+                    "Favorites"
                 ],
-                "summary": "delete products from product list",
-                "operationId": "product-list-delete-products",
+                "summary": "remove some products from favorite list",
+                "operationId": "remove-favorite-products",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product list id",
+                        "description": "id of favorites list",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "ids of deleting products",
-                        "name": "body",
+                        "description": "ids of new products",
+                        "name": "products",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/api.ProductList"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/lists/{id}/products/{product_id}": {
-            "patch": {
+        "/favorite/user": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "tags": [
-                    "ProductList"
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "update single product state in given product list",
-                "operationId": "product-list-update-state",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "product list id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "product state product id",
-                        "name": "product_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "product state options",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.ProductStateOptions"
-                        }
-                    }
+                "tags": [
+                    "Favorites"
                 ],
+                "summary": "get all favorites lists, related to logged user",
+                "operationId": "get-user-favorites-lists",
                 "responses": {}
             }
         },
-        "/lists/{id}/reorder": {
-            "patch": {
+        "/lists": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "ProductList"
                 ],
-                "summary": "change order of products in product list",
-                "operationId": "product-list-reorder-states",
+                "summary": "get summaries of product lists of the user",
+                "operationId": "product-list-get",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product list id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "status of lists: planning, processing or archived",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "description": "ids of products in new order",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "string",
+                        "description": "role of the user in lists",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "words which titles of lists must contain",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "updatedAt",
+                        "description": "sort of lists: updatedAt, createdAt or title",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "max number of lists",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
-            }
-        },
-        "/product": {
+            },
             "post": {
                 "security": [
                     {
@@ -596,124 +706,122 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "ProductList"
                 ],
-                "summary": "Creates new product",
-                "operationId": "product-create",
+                "summary": "creates new product list",
+                "operationId": "product-list-create",
                 "parameters": [
                     {
-                        "description": "product to create",
-                        "name": "product",
+                        "description": "options of new product list",
+                        "name": "opts",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/product.Options"
+                            "$ref": "#/definitions/list.ListOptions"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/product/id/{id}": {
-            "get": {
+        "/lists/from-template/{id}": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "ListTemplate"
                 ],
-                "summary": "Get product info",
-                "operationId": "product-get",
+                "summary": "create new planning list from template",
+                "operationId": "product-list-create-from-template",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product id",
+                        "description": "template id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {}
-            },
-            "put": {
+            }
+        },
+        "/lists/merge": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "ProductList"
                 ],
-                "summary": "Update existing new product",
-                "operationId": "product-update",
+                "summary": "add products and members of source lists to the target list",
+                "operationId": "product-list-merge",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "product id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "product to update",
-                        "name": "product",
+                        "description": "source and target lists",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/product.Options"
+                            "$ref": "#/definitions/list.MergeOptions"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/product/list/{ids}": {
+        "/lists/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "ProductList"
                 ],
-                "summary": "Get products info",
-                "operationId": "product-get-list",
+                "summary": "get product list by list id",
+                "operationId": "product-list-get-by-id",
                 "parameters": [
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "product id",
-                        "name": "ids",
+                        "type": "string",
+                        "description": "id of product list",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "revision id or RFC3339 timestamp to view list at past point",
+                        "name": "as_of",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "returns only states assigned to given user id or to current user for 'me'",
+                        "name": "assignee",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
-            }
-        },
-        "/shopmap": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -726,144 +834,203 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "Creates new shop map",
-                "operationId": "shopmap-create",
+                "summary": "update product list by id",
+                "operationId": "product-list-update-by-id",
                 "parameters": [
                     {
-                        "description": "shop map to create",
-                        "name": "config",
+                        "description": "opts to update",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/shopmap.Options"
+                            "$ref": "#/definitions/list.ListOptions"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of product list",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {}
-            }
-        },
-        "/shopmap/id/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "Get existing shop map by it's ID",
-                "operationId": "shopmap-get-id",
+                "summary": "delete product list by id",
+                "operationId": "product-list-delete-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of shop map",
+                        "description": "id of product list",
                         "name": "id",
-                        "in": "path"
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {}
-            },
-            "put": {
+            }
+        },
+        "/lists/{id}/arrange": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "fully updates shop map",
-                "operationId": "shopmap-update",
+                "summary": "order products in product list by categories of shop map",
+                "operationId": "product-list-arrange",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of shop map",
+                        "description": "product list id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "new configuration",
-                        "name": "config",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/shopmap.Options"
-                        }
+                        "type": "string",
+                        "description": "shop map id, default shop map of the list is used if it's absent",
+                        "name": "shopmap_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "deprecated alias of shopmap_id",
+                        "name": "map_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
-            },
-            "delete": {
+            }
+        },
+        "/lists/{id}/batch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "Deletes shop map",
-                "operationId": "shopmap-delete",
+                "summary": "apply ordered operations to product list in one transaction",
+                "operationId": "product-list-apply-batch",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of shop map",
+                        "description": "product list id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "operations in order of applying",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/list.Operation"
+                            }
+                        }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/shopmap/id/{id}/join": {
-            "patch": {
+        "/lists/{id}/duplicate": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "create planning copy of product list owned by the user",
+                "operationId": "product-list-duplicate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
-                    "application/json"
+                    "text/markdown",
+                    "text/csv",
+                    "text/plain",
+                    "application/pdf"
                 ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "only reorder categories in given shop map",
-                "operationId": "shopmap-join",
+                "summary": "export product list as a file",
+                "operationId": "product-list-export",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of shop map",
+                        "description": "product list id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format of file: md, csv, txt or pdf",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "shop map id, states are grouped by its categories",
+                        "name": "map_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {}
             }
         },
-        "/shopmap/id/{id}/reorder": {
-            "patch": {
+        "/lists/{id}/finish": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -876,35 +1043,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
                 ],
-                "summary": "only reorder categories in given shop map",
-                "operationId": "shopmap-reorder",
+                "summary": "finish shopping, list is archived and waiting items are marked as missed",
+                "operationId": "product-list-finish-session",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of shop map",
+                        "description": "product list id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "new order of categories",
-                        "name": "categories",
+                        "description": "options of finishing",
+                        "name": "opts",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/list.FinishOptions"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/shopmap/user": {
+        "/lists/{id}/history": {
             "get": {
                 "security": [
                     {
@@ -915,148 +1079,2513 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ShopMap"
+                    "ProductList"
+                ],
+                "summary": "get change log of product list",
+                "operationId": "product-list-get-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of product list",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "number of skipped entries",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "max number of entries",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get shop maps of current logged user",
-                "operationId": "shopmap-get-current-user",
                 "responses": {}
             }
         },
-        "/user": {
-            "get": {
+        "/lists/{id}/import/text": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "ProductList"
                 ],
-                "summary": "list all users",
-                "operationId": "user-get-all",
-                "responses": {
-                    "200": {
-                        "description": "OK"
+                "summary": "import plain-text list, preview is returned unless commit is true",
+                "operationId": "product-list-import-text",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "pasted text",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.ImportOptions"
+                        }
                     }
-                }
+                ],
+                "responses": {}
             }
         },
-        "/user/register": {
+        "/lists/{id}/members": {
             "post": {
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "ProductList"
                 ],
-                "summary": "creates new user",
-                "operationId": "user-register",
+                "summary": "add viewers to product list",
+                "operationId": "product-list-add-members",
                 "parameters": [
                     {
-                        "description": "data for creating new user",
-                        "name": "opts",
+                        "description": "users to add",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.CreateOptions"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/list.MemberOptions"
+                            }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of product list",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {}
-            }
-        }
-    },
-    "definitions": {
-        "api.ProductList": {
-            "type": "object",
-            "properties": {
-                "product_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
                     }
-                }
-            }
-        },
-        "api.ProductStateOptions": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "form_idx": {
-                    "type": "integer"
-                },
-                "replacement": {
-                    "type": "object",
-                    "properties": {
-                        "count": {
-                            "type": "integer"
-                        },
-                        "form_idx": {
-                            "type": "integer"
-                        },
-                        "product_id": {
-                            "type": "integer"
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "delete members from product list",
+                "operationId": "product-list-delete-members",
+                "parameters": [
+                    {
+                        "description": "id of members to delete",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                },
-                "status": {
-                    "type": "string"
-                }
-            }
-        },
-        "api.RefreshRequest": {
-            "type": "object",
-            "properties": {
-                "refresh_token": {
-                    "type": "string"
-                }
+                ],
+                "responses": {}
             }
         },
-        "api.TokenResponse": {
-            "type": "object",
-            "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "expires": {
-                    "type": "string"
-                },
-                "refresh_token": {
+        "/lists/{id}/products": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "add new products to product list",
+                "operationId": "product-list-add-products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new products",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/api.ProductStateOptions"
+                            }
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "delete products from product list",
+                "operationId": "product-list-delete-products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ids of deleting products",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "update single product state in given product list",
+                "operationId": "product-list-update-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "product state options",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ProductStateOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/assignee": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "set or remove assignee of product state",
+                "operationId": "product-list-assign-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new assignee, state is released if it's null",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.AssignOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/attachments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/webp"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "attach image to list item, body is a content of image",
+                "operationId": "product-list-add-attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "image content",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/attachments/{attachment_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "get content of image attached to list item",
+                "operationId": "product-list-get-attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "attachment id",
+                        "name": "attachment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "delete image attached to list item",
+                "operationId": "product-list-delete-attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "attachment id",
+                        "name": "attachment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/claim": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "claim product state, fails if it's claimed by other member",
+                "operationId": "product-list-claim-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "release claimed product state",
+                "operationId": "product-list-release-state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/comments": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "add comment to list item",
+                "operationId": "product-list-add-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "comment",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.CommentOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/products/{product_id}/comments/{comment_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "delete comment of list item",
+                "operationId": "product-list-delete-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "product state product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comment id",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/redo": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "redo last undone changes of current user",
+                "operationId": "product-list-redo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "number of changes to redo",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/reorder": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "change order of products in product list",
+                "operationId": "product-list-reorder-states",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ids of products in new order",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/scan/{code}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "mark waiting product with scanned barcode taken, otherwise offer states it may replace",
+                "operationId": "product-list-scan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "EAN-13, UPC-A or EAN-8 barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "get shopping sessions of product list",
+                "operationId": "product-list-get-sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/split": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "move products of product list to a new list",
+                "operationId": "product-list-split",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "moved products and title of new list",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.SplitOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "start shopping, list is moved to processing",
+                "operationId": "product-list-start-session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "members who are shopping, current user if empty",
+                        "name": "opts",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.StartOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "get products bought in archived lists of members which are not in the list, overdue ones go first",
+                "operationId": "product-list-get-suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of product list",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "max number of suggestions",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/template": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "save product list as a template without members and statuses",
+                "operationId": "product-list-save-template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "options of new template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.TemplateOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/lists/{id}/undo": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ProductList"
+                ],
+                "summary": "undo last changes of current user",
+                "operationId": "product-list-undo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "number of changes to undo",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/mealplans": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "get meal plans of the user, the latest weeks go first",
+                "operationId": "mealplans-get",
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "create meal plan of a week",
+                "operationId": "mealplan-create",
+                "parameters": [
+                    {
+                        "description": "title, week and meals",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipe.MealPlanOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/mealplans/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "get meal plan by id",
+                "operationId": "mealplan-get-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "meal plan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "update meal plan",
+                "operationId": "mealplan-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "meal plan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new options of meal plan",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipe.MealPlanOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "delete meal plan",
+                "operationId": "mealplan-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "meal plan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/mealplans/{id}/to-list": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MealPlans"
+                ],
+                "summary": "add ingredients of all meals of the plan to the list, quantities of the same product are summed",
+                "operationId": "mealplan-to-list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "meal plan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "list where ingredients are added",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipe.ToListOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/pantry": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "get items of the pantry, the ones expiring first go first",
+                "operationId": "pantry-get",
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "put product to the pantry of the user",
+                "operationId": "pantry-add",
+                "parameters": [
+                    {
+                        "description": "product, quantity, location and expiry date",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pantry.NewItem"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/pantry/expiring": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "get items which expire soon or are already expired",
+                "operationId": "pantry-expiring",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "number of days from now, 3 by default",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/pantry/low": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "get products whose stock is below their thresholds",
+                "operationId": "pantry-low",
+                "responses": {}
+            }
+        },
+        "/pantry/thresholds": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "get low stock thresholds of the user",
+                "operationId": "pantry-thresholds-get",
+                "responses": {}
+            }
+        },
+        "/pantry/thresholds/{product_id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "set low stock threshold of product, with list id the product is added to the list when stock is low",
+                "operationId": "pantry-threshold-set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "minimal quantity and planning list",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pantry.ThresholdOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "delete low stock threshold of product",
+                "operationId": "pantry-threshold-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product id",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/pantry/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "update quantity, location and expiry date of pantry item",
+                "operationId": "pantry-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pantry item id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new options of item",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pantry.ItemOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "delete pantry item without recording consumption",
+                "operationId": "pantry-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pantry item id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/pantry/{id}/consume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pantry"
+                ],
+                "summary": "record consumption of pantry item, the item is deleted when nothing is left",
+                "operationId": "pantry-consume",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pantry item id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "consumed quantity",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/pantry.Consumption"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/product": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Creates new product",
+                "operationId": "product-create",
+                "parameters": [
+                    {
+                        "description": "product to create",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/product/barcode/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get product by EAN-13, UPC-A or EAN-8 barcode",
+                "operationId": "product-get-by-barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "barcode",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/product/id/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get product info",
+                "operationId": "product-get",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Update existing new product",
+                "operationId": "product-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "product to update",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/product/list/{ids}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get products info",
+                "operationId": "product-get-list",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "product id",
+                        "name": "ids",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/product/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Search products by names, aliases and categories, typos and transliteration are tolerated",
+                "operationId": "product-search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of skipped products",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 20 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/recipes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "get recipes of the user sorted by title",
+                "operationId": "recipes-get",
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "create new recipe",
+                "operationId": "recipe-create",
+                "parameters": [
+                    {
+                        "description": "title, servings, instructions and ingredients",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipe.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/recipes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "get recipe, with servings its ingredients are scaled to them",
+                "operationId": "recipe-get-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "recipe id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of servings, servings of the recipe by default",
+                        "name": "servings",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "update recipe",
+                "operationId": "recipe-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "recipe id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new options of recipe",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/recipe.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "delete recipe, its meals are deleted from meal plans",
+                "operationId": "recipe-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "recipe id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/shopmap": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "Creates new shop map",
+                "operationId": "shopmap-create",
+                "parameters": [
+                    {
+                        "description": "shop map to create",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shopmap.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/shopmap/id/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "Get existing shop map by it's ID",
+                "operationId": "shopmap-get-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of shop map",
+                        "name": "id",
+                        "in": "path"
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "fully updates shop map",
+                "operationId": "shopmap-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of shop map",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shopmap.Options"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "Deletes shop map",
+                "operationId": "shopmap-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of shop map",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/shopmap/id/{id}/join": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "only reorder categories in given shop map",
+                "operationId": "shopmap-join",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of shop map",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/shopmap/id/{id}/reorder": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "only reorder categories in given shop map",
+                "operationId": "shopmap-reorder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of shop map",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new order of categories",
+                        "name": "categories",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/shopmap/user": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ShopMap"
+                ],
+                "summary": "Get shop maps of current logged user",
+                "operationId": "shopmap-get-current-user",
+                "responses": {}
+            }
+        },
+        "/stats/spending": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "get money spent in lists of the user by periods, categories, shop maps and currencies",
+                "operationId": "stats-spending",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp or date of the range start, 30 days before its end by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp or date of the range end, which isn't included, now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day, week or month, weeks start on Monday, month by default",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "get templates which user owns or is a member of",
+                "operationId": "list-templates-get",
+                "responses": {}
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "get template by id",
+                "operationId": "list-template-get-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "update title, default shop map and members of template",
+                "operationId": "list-template-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new options of template",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.TemplateOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "delete template with its schedules",
+                "operationId": "list-template-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/templates/{id}/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "get schedules of template",
+                "operationId": "list-template-get-schedules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "add schedule which creates lists from template",
+                "operationId": "list-template-create-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "period of schedule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/list.ScheduleOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/templates/{id}/schedules/{schedule_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "ListTemplate"
+                ],
+                "summary": "delete schedule of template",
+                "operationId": "list-template-delete-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "template id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "schedule id",
+                        "name": "schedule_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "get lists, shop maps and favorites of the user moved to the trash, which can be restored",
+                "operationId": "trash-get",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/trash.Item"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/{kind}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "restore entity from the trash",
+                "operationId": "trash-restore",
+                "parameters": [
+                    {
+                        "enum": [
+                            "list",
+                            "shopMap",
+                            "favorites"
+                        ],
+                        "type": "string",
+                        "description": "kind of entity",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of entity",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/user": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "list all users",
+                "operationId": "user-get-all",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "creates new user",
+                "operationId": "user-register",
+                "parameters": [
+                    {
+                        "description": "data for creating new user",
+                        "name": "opts",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateOptions"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        }
+    },
+    "definitions": {
+        "api.ProductList": {
+            "type": "object",
+            "properties": {
+                "product_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "quantities": {
+                    "description": "Quantities are optional quantities of added products by product ids",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/product.Quantity"
+                    }
+                }
+            }
+        },
+        "api.ProductStateOptions": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is a deprecated number of pieces, it's used only if quantity isn't sent",
+                    "type": "integer"
+                },
+                "form_idx": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "$ref": "#/definitions/product.Quantity"
+                },
+                "replacement": {
+                    "type": "object",
+                    "properties": {
+                        "count": {
+                            "description": "Count is a deprecated number of pieces, it's used only if quantity isn't sent",
+                            "type": "integer"
+                        },
+                        "form_idx": {
+                            "type": "integer"
+                        },
+                        "product_id": {
+                            "type": "integer"
+                        },
+                        "quantity": {
+                            "$ref": "#/definitions/product.Quantity"
+                        }
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
+                }
+            }
+        },
+        "auth.Credentials": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "budget.CategoryLimit": {
+            "type": "object",
+            "required": [
+                "category"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "string"
+                }
+            }
+        },
+        "budget.MemberOptions": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "budget.Options": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "category_limits": {
+                    "description": "CategoryLimits are limits of products of some categories, they're checked besides the limit of the whole budget",
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/budget.CategoryLimit"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/budget.MemberOptions"
+                    }
+                },
+                "period": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "category.MergeOptions": {
+            "type": "object",
+            "required": [
+                "source_ids"
+            ],
+            "properties": {
+                "source_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "type": {
+                "target_id": {
                     "type": "string"
+                }
+            }
+        },
+        "category.Options": {
+            "type": "object",
+            "required": [
+                "aliases",
+                "name",
+                "names"
+            ],
+            "properties": {
+                "aliases": {
+                    "description": "Aliases are other spellings of the name, e.g. names of merged categories",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "user_id": {
-                    "type": "string"
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "names": {
+                    "description": "Names are localized names by language codes",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string",
+                    "x-nullable": true
                 }
             }
         },
-        "auth.Credentials": {
+        "dedup.MergeOptions": {
             "type": "object",
+            "required": [
+                "source_ids"
+            ],
             "properties": {
-                "device_id": {
-                    "type": "string"
+                "source_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "login": {
+                "target_id": {
                     "type": "string"
-                },
-                "password": {
+                }
+            }
+        },
+        "list.AssignOptions": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "type": "string",
+                    "x-nullable": true
+                }
+            }
+        },
+        "list.CommentOptions": {
+            "type": "object",
+            "properties": {
+                "text": {
                     "type": "string"
                 }
             }
         },
+        "list.FinishOptions": {
+            "type": "object",
+            "properties": {
+                "roll_over_missed": {
+                    "description": "RollOverMissed moves missed items into a new planning list",
+                    "type": "boolean"
+                }
+            }
+        },
+        "list.ImportOptions": {
+            "type": "object"
+        },
         "list.ListOptions": {
             "type": "object",
             "properties": {
+                "shop_map_id": {
+                    "description": "ShopMapID is a default shop map of the list, new states are inserted by order of its categories",
+                    "type": "string",
+                    "x-nullable": true
+                },
                 "status": {
                     "type": "string"
                 },
@@ -1076,9 +3605,193 @@ const docTemplate = `{
                 }
             }
         },
+        "list.MergeOptions": {
+            "type": "object",
+            "required": [
+                "source_ids"
+            ],
+            "properties": {
+                "delete_sources": {
+                    "description": "DeleteSources moves source lists to the trash after merge, it requires owner role in every source list",
+                    "type": "boolean"
+                },
+                "source_ids": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "target_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "list.Operation": {
+            "type": "object"
+        },
+        "list.ScheduleOptions": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "description": "Cron is an expression of five fields like \"0 9 * * fri\"",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "day": {
+                    "description": "Day is a day of month, months without the day are skipped",
+                    "type": "integer",
+                    "maximum": 31,
+                    "minimum": 0
+                },
+                "period": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "Time is a time of day like 09:00",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "Timezone is a name of IANA time zone like Europe/Berlin, UTC is used if it's empty",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "weekday": {
+                    "description": "Weekday is a day of week from 0 (sunday) to 6 (saturday)",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                }
+            }
+        },
+        "list.SplitOptions": {
+            "type": "object",
+            "required": [
+                "product_ids"
+            ],
+            "properties": {
+                "product_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "description": "Title is a title of the new list, the title of source list is used if it's empty",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "list.StartOptions": {
+            "type": "object",
+            "properties": {
+                "shoppers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "list.TemplateOptions": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/list.MemberOptions"
+                    }
+                },
+                "shop_map_id": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "pantry.Consumption": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "$ref": "#/definitions/product.Quantity"
+                }
+            }
+        },
+        "pantry.ItemOptions": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "location": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "$ref": "#/definitions/product.Quantity"
+                }
+            }
+        },
+        "pantry.NewItem": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "location": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "$ref": "#/definitions/product.Quantity"
+                }
+            }
+        },
+        "pantry.ThresholdOptions": {
+            "type": "object",
+            "properties": {
+                "list_id": {
+                    "description": "ListID is a planning list where the product is added when its stock falls below Min.\nWithout it low stock is only suggested.",
+                    "type": "string",
+                    "x-nullable": true
+                },
+                "min": {
+                    "$ref": "#/definitions/product.Quantity"
+                }
+            }
+        },
         "product.Options": {
             "type": "object",
             "properties": {
+                "aliases": {
+                    "description": "Aliases are other names of the product, the product is found by them too",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "barcodes": {
+                    "description": "Barcodes are EAN-13, UPC-A or EAN-8 barcodes of the product, each barcode belongs to one product only",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "category": {
                     "type": "string"
                 },
@@ -1093,6 +3806,108 @@ const docTemplate = `{
                 }
             }
         },
+        "product.Quantity": {
+            "type": "object",
+            "properties": {
+                "unit": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "recipe.Ingredient": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "$ref": "#/definitions/product.Quantity"
+                }
+            }
+        },
+        "recipe.Meal": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "description": "Day is a day of the week starting from 0 for Monday",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "recipe_id": {
+                    "type": "string"
+                },
+                "servings": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "slot": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipe.MealPlanOptions": {
+            "type": "object",
+            "properties": {
+                "meals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recipe.Meal"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "week_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "recipe.Options": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "ingredients": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/recipe.Ingredient"
+                    }
+                },
+                "instructions": {
+                    "type": "string",
+                    "maxLength": 16384
+                },
+                "servings": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "recipe.ToListOptions": {
+            "type": "object",
+            "properties": {
+                "list_id": {
+                    "type": "string"
+                }
+            }
+        },
         "shopmap.Options": {
             "type": "object",
             "required": [
@@ -1118,6 +3933,29 @@ const docTemplate = `{
                 }
             }
         },
+        "trash.Item": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "deleted_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
         "user.CreateOptions": {
             "type": "object",
             "required": [
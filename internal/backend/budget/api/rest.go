@@ -0,0 +1,177 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/budget"
+	"go-backend/internal/backend/budget/service"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	group := r.Group("/budgets")
+
+	log = log.With().Str("component", "budget rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	group.POST("", h.Create)
+	group.GET("", h.GetBudgets)
+	group.GET("/:id", h.GetByID)
+	group.PUT("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+	group.GET("/:id/status", h.GetStatus)
+}
+
+// @Summary create new budget owned by the user
+// @ID budget-create
+// @Tags Budgets
+// @Param body body budget.Options true "options of new budget"
+// @Produce json
+// @Accept json
+// @Router /budgets [post]
+// @Security ApiKeyAuth
+func (h *Handler) Create(ctx *gin.Context) {
+	var opts budget.Options
+
+	if ok := h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	model, err := h.service.Create(ctx, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get budgets which user owns or is a member of
+// @ID budgets-get
+// @Tags Budgets
+// @Produce json
+// @Router /budgets [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetBudgets(ctx *gin.Context) {
+	budgets, err := h.service.GetByUserID(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, budgets)
+}
+
+// @Summary get budget by id
+// @ID budget-get-by-id
+// @Tags Budgets
+// @Param id path string true "budget id"
+// @Produce json
+// @Router /budgets/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetByID(ctx *gin.Context) {
+	budgetID, ok := rerr.PathID[budget.Budget](ctx)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.GetByID(ctx, budgetID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary update title, period, limits and members of budget, only its owner and admins can do it
+// @ID budget-update
+// @Tags Budgets
+// @Param id path string true "budget id"
+// @Param body body budget.Options true "new options of budget"
+// @Produce json
+// @Accept json
+// @Router /budgets/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) Update(ctx *gin.Context) {
+	var opts budget.Options
+
+	budgetID, ok := rerr.PathID[budget.Budget](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	model, err := h.service.Update(ctx, budgetID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete budget, only its owner can do it
+// @ID budget-delete
+// @Tags Budgets
+// @Param id path string true "budget id"
+// @Router /budgets/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) Delete(ctx *gin.Context) {
+	budgetID, ok := rerr.PathID[budget.Budget](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, budgetID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary get money spent and left in the budget period
+// @ID budget-status
+// @Tags Budgets
+// @Param id path string true "budget id"
+// @Param at query string false "RFC3339 timestamp or date inside the period, now by default"
+// @Produce json
+// @Router /budgets/{id}/status [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetStatus(ctx *gin.Context) {
+	budgetID, ok := rerr.PathID[budget.Budget](ctx)
+	if !ok {
+		return
+	}
+
+	at, ok := rerr.QueryTime(ctx, "at", time.Now())
+	if !ok {
+		return
+	}
+
+	status, err := h.service.GetStatus(ctx, budgetID, api.GetUserID(ctx), at)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
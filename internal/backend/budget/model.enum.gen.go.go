@@ -0,0 +1,326 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package budget
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MemberTypeOwner is a MemberType of type Owner.
+	MemberTypeOwner MemberType = iota + 1
+	// MemberTypeAdmin is a MemberType of type Admin.
+	MemberTypeAdmin
+	// MemberTypeViewer is a MemberType of type Viewer.
+	MemberTypeViewer
+)
+
+var ErrInvalidMemberType = fmt.Errorf("not a valid MemberType, try [%s]", strings.Join(_MemberTypeNames, ", "))
+
+const _MemberTypeName = "owneradminviewer"
+
+var _MemberTypeNames = []string{
+	_MemberTypeName[0:5],
+	_MemberTypeName[5:10],
+	_MemberTypeName[10:16],
+}
+
+// MemberTypeNames returns a list of possible string values of MemberType.
+func MemberTypeNames() []string {
+	tmp := make([]string, len(_MemberTypeNames))
+	copy(tmp, _MemberTypeNames)
+	return tmp
+}
+
+// MemberTypeValues returns a list of the values for MemberType
+func MemberTypeValues() []MemberType {
+	return []MemberType{
+		MemberTypeOwner,
+		MemberTypeAdmin,
+		MemberTypeViewer,
+	}
+}
+
+var _MemberTypeMap = map[MemberType]string{
+	MemberTypeOwner:  _MemberTypeName[0:5],
+	MemberTypeAdmin:  _MemberTypeName[5:10],
+	MemberTypeViewer: _MemberTypeName[10:16],
+}
+
+// String implements the Stringer interface.
+func (x MemberType) String() string {
+	if str, ok := _MemberTypeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("MemberType(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x MemberType) IsValid() bool {
+	_, ok := _MemberTypeMap[x]
+	return ok
+}
+
+var _MemberTypeValue = map[string]MemberType{
+	_MemberTypeName[0:5]:   MemberTypeOwner,
+	_MemberTypeName[5:10]:  MemberTypeAdmin,
+	_MemberTypeName[10:16]: MemberTypeViewer,
+}
+
+// ParseMemberType attempts to convert a string to a MemberType.
+func ParseMemberType(name string) (MemberType, error) {
+	if x, ok := _MemberTypeValue[name]; ok {
+		return x, nil
+	}
+	return MemberType(0), fmt.Errorf("%s is %w", name, ErrInvalidMemberType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x MemberType) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *MemberType) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseMemberType(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errMemberTypeNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *MemberType) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = MemberType(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = MemberType(v)
+	case string:
+		*x, err = ParseMemberType(v)
+	case []byte:
+		*x, err = ParseMemberType(string(v))
+	case MemberType:
+		*x = v
+	case int:
+		*x = MemberType(v)
+	case *MemberType:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = MemberType(v)
+	case uint64:
+		*x = MemberType(v)
+	case *int:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = MemberType(*v)
+	case *int64:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = MemberType(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = MemberType(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = MemberType(*v)
+	case *uint:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = MemberType(*v)
+	case *uint64:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x = MemberType(*v)
+	case *string:
+		if v == nil {
+			return errMemberTypeNilPtr
+		}
+		*x, err = ParseMemberType(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x MemberType) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+const (
+	// PeriodWeek is a Period of type Week.
+	PeriodWeek Period = iota + 1
+	// PeriodMonth is a Period of type Month.
+	PeriodMonth
+)
+
+var ErrInvalidPeriod = fmt.Errorf("not a valid Period, try [%s]", strings.Join(_PeriodNames, ", "))
+
+const _PeriodName = "weekmonth"
+
+var _PeriodNames = []string{
+	_PeriodName[0:4],
+	_PeriodName[4:9],
+}
+
+// PeriodNames returns a list of possible string values of Period.
+func PeriodNames() []string {
+	tmp := make([]string, len(_PeriodNames))
+	copy(tmp, _PeriodNames)
+	return tmp
+}
+
+// PeriodValues returns a list of the values for Period
+func PeriodValues() []Period {
+	return []Period{
+		PeriodWeek,
+		PeriodMonth,
+	}
+}
+
+var _PeriodMap = map[Period]string{
+	PeriodWeek:  _PeriodName[0:4],
+	PeriodMonth: _PeriodName[4:9],
+}
+
+// String implements the Stringer interface.
+func (x Period) String() string {
+	if str, ok := _PeriodMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Period(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Period) IsValid() bool {
+	_, ok := _PeriodMap[x]
+	return ok
+}
+
+var _PeriodValue = map[string]Period{
+	_PeriodName[0:4]: PeriodWeek,
+	_PeriodName[4:9]: PeriodMonth,
+}
+
+// ParsePeriod attempts to convert a string to a Period.
+func ParsePeriod(name string) (Period, error) {
+	if x, ok := _PeriodValue[name]; ok {
+		return x, nil
+	}
+	return Period(0), fmt.Errorf("%s is %w", name, ErrInvalidPeriod)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Period) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Period) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParsePeriod(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errPeriodNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *Period) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = Period(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = Period(v)
+	case string:
+		*x, err = ParsePeriod(v)
+	case []byte:
+		*x, err = ParsePeriod(string(v))
+	case Period:
+		*x = v
+	case int:
+		*x = Period(v)
+	case *Period:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = Period(v)
+	case uint64:
+		*x = Period(v)
+	case *int:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = Period(*v)
+	case *int64:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = Period(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = Period(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = Period(*v)
+	case *uint:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = Period(*v)
+	case *uint64:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x = Period(*v)
+	case *string:
+		if v == nil {
+			return errPeriodNilPtr
+		}
+		*x, err = ParsePeriod(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x Period) Value() (driver.Value, error) {
+	return x.String(), nil
+}
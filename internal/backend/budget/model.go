@@ -0,0 +1,179 @@
+package budget
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+//go:generate python $GOENUM
+
+// ENUM(week=1, month)
+type Period int32
+
+// ENUM(owner=1, admin, viewer)
+type MemberType int32
+
+// Start returns the beginning of UTC period which contains t, weeks start on Monday
+func (p Period) Start(t time.Time) time.Time {
+	if p == PeriodWeek {
+		return list.SpendingPeriodWeek.Start(t)
+	}
+
+	return list.SpendingPeriodMonth.Start(t)
+}
+
+// End returns the beginning of the next period after the one which contains t
+func (p Period) End(t time.Time) time.Time {
+	if p == PeriodWeek {
+		return p.Start(t).AddDate(0, 0, 7)
+	}
+
+	return p.Start(t).AddDate(0, 1, 0)
+}
+
+// CategoryLimit limits money spent on products of a category
+type CategoryLimit struct {
+	Category product.Category `json:"category" swaggertype:"string" validate:"required"`
+	Limit    decimal.Decimal  `json:"limit" swaggertype:"string"`
+}
+
+type MemberOptions struct {
+	UserID id.ID[user.User] `json:"user_id" swaggertype:"string"`
+	Role   MemberType       `json:"role" swaggertype:"string"`
+}
+
+// Options are editable options of a budget.
+// Members are users besides the owner, whose purchases are counted, a budget without members is a personal one.
+type Options struct {
+	Title    string          `json:"title" validate:"required,max=255"`
+	Period   Period          `json:"period" swaggertype:"string"`
+	Currency string          `json:"currency"`
+	Limit    decimal.Decimal `json:"limit" swaggertype:"string"`
+	// CategoryLimits are limits of products of some categories, they're checked besides the limit of the whole budget
+	CategoryLimits []CategoryLimit `json:"category_limits" validate:"unique=Category,dive"`
+	Members        []MemberOptions `json:"members" validate:"unique=UserID"`
+}
+
+// Budget limits money spent in a period in lists which its owner or members are members of.
+// Only purchases in the currency of the budget are counted.
+type Budget struct {
+	Options
+
+	ID        id.ID[Budget]    `json:"id" swaggertype:"string"`
+	OwnerID   id.ID[user.User] `json:"owner_id" swaggertype:"string"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CheckRole returns role of the user, the role must be the same or higher than the given one
+func (b Budget) CheckRole(userID id.ID[user.User], role MemberType) (MemberType, error) {
+	current := MemberTypeOwner
+
+	if userID != b.OwnerID {
+		idx := slices.IndexFunc(b.Members, func(m MemberOptions) bool { return m.UserID == userID })
+		if idx == -1 {
+			return 0, fmt.Errorf("%w: user %s is not a member of budget %s", myerr.ErrForbidden, userID, b.ID)
+		}
+
+		current = b.Members[idx].Role
+	}
+
+	if role < current {
+		return current, fmt.Errorf("%w: role of user %s is not enough", myerr.ErrForbidden, userID)
+	}
+
+	return current, nil
+}
+
+// UserIDs returns the owner and members of the budget
+func (b Budget) UserIDs() []id.ID[user.User] {
+	return append([]id.ID[user.User]{b.OwnerID}, lo.Map(b.Members, func(item MemberOptions, _ int) id.ID[user.User] {
+		return item.UserID
+	})...)
+}
+
+// Status returns progress of the budget in the period containing at, purchases in other currencies are skipped
+func (b Budget) Status(purchases []list.Purchase, at time.Time) Status {
+	status := Status{
+		BudgetID:    b.ID,
+		PeriodStart: b.Period.Start(at),
+		PeriodEnd:   b.Period.End(at),
+		Currency:    b.Currency,
+		Limit:       b.Limit,
+		Spent:       decimal.Zero,
+		Remaining:   b.Limit,
+		Exceeded:    false,
+		Items:       0,
+		Categories: lo.Map(b.CategoryLimits, func(item CategoryLimit, _ int) CategoryStatus {
+			return CategoryStatus{
+				Category: item.Category,
+				Limit:    item.Limit,
+				Spent:    decimal.Zero,
+				Exceeded: false,
+			}
+		}),
+	}
+
+	for _, purchase := range purchases {
+		if purchase.Price.Currency != b.Currency ||
+			purchase.PurchasedAt.Before(status.PeriodStart) || !purchase.PurchasedAt.Before(status.PeriodEnd) {
+			continue
+		}
+
+		status.Spent = status.Spent.Add(purchase.Price.Amount)
+		status.Items++
+
+		category, found := purchase.Category.Get()
+		if !found {
+			continue
+		}
+
+		idx := slices.IndexFunc(status.Categories, func(c CategoryStatus) bool { return c.Category == category })
+		if idx != -1 {
+			status.Categories[idx].Spent = status.Categories[idx].Spent.Add(purchase.Price.Amount)
+		}
+	}
+
+	status.Remaining = status.Limit.Sub(status.Spent)
+	status.Exceeded = status.Spent.GreaterThan(status.Limit)
+
+	for i, category := range status.Categories {
+		status.Categories[i].Exceeded = category.Spent.GreaterThan(category.Limit)
+	}
+
+	return status
+}
+
+// CategoryStatus is money spent on products of a category with a limit
+type CategoryStatus struct {
+	Category product.Category `json:"category" swaggertype:"string"`
+	Limit    decimal.Decimal  `json:"limit" swaggertype:"string"`
+	Spent    decimal.Decimal  `json:"spent" swaggertype:"string"`
+	Exceeded bool             `json:"exceeded"`
+}
+
+// Status is progress of a budget in a period from PeriodStart till PeriodEnd
+type Status struct {
+	BudgetID    id.ID[Budget]   `json:"budget_id" swaggertype:"string"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Currency    string          `json:"currency"`
+	Limit       decimal.Decimal `json:"limit" swaggertype:"string"`
+	Spent       decimal.Decimal `json:"spent" swaggertype:"string"`
+	// Remaining is negative when the limit is exceeded
+	Remaining  decimal.Decimal  `json:"remaining" swaggertype:"string"`
+	Exceeded   bool             `json:"exceeded"`
+	Categories []CategoryStatus `json:"categories"`
+	// Items is a number of counted purchases
+	Items int `json:"items"`
+}
@@ -0,0 +1,86 @@
+package budget_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/budget"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestStatus(t *testing.T) {
+	at := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	model := budget.Budget{
+		Options: budget.Options{
+			Title:    "groceries",
+			Period:   budget.PeriodWeek,
+			Currency: "EUR",
+			Limit:    decimal.RequireFromString("10"),
+			CategoryLimits: []budget.CategoryLimit{
+				{Category: "dairy", Limit: decimal.RequireFromString("3")},
+			},
+		},
+	}
+
+	purchase := func(amount, currency string, at time.Time, category mo.Option[product.Category]) list.Purchase {
+		return list.Purchase{
+			Category:    category,
+			PurchasedAt: at,
+			Price:       list.Price{Amount: decimal.RequireFromString(amount), Currency: currency},
+		}
+	}
+
+	status := model.Status([]list.Purchase{
+		purchase("4", "EUR", at, mo.Some(product.Category("dairy"))),
+		purchase("5.5", "EUR", at.Add(-time.Hour), mo.None[product.Category]()),
+		purchase("100", "USD", at, mo.None[product.Category]()),
+		purchase("100", "EUR", at.AddDate(0, 0, -7), mo.None[product.Category]()),
+	}, at)
+
+	require.Equal(t, time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC), status.PeriodStart)
+	require.Equal(t, time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), status.PeriodEnd)
+	require.Equal(t, 2, status.Items)
+	require.True(t, decimal.RequireFromString("9.5").Equal(status.Spent))
+	require.True(t, decimal.RequireFromString("0.5").Equal(status.Remaining))
+	require.False(t, status.Exceeded)
+	require.Len(t, status.Categories, 1)
+	require.True(t, decimal.RequireFromString("4").Equal(status.Categories[0].Spent))
+	require.True(t, status.Categories[0].Exceeded)
+}
+
+func TestCheckRole(t *testing.T) {
+	ownerID, adminID, viewerID := id.NewID[user.User](), id.NewID[user.User](), id.NewID[user.User]()
+	model := budget.Budget{
+		Options: budget.Options{
+			Members: []budget.MemberOptions{
+				{UserID: adminID, Role: budget.MemberTypeAdmin},
+				{UserID: viewerID, Role: budget.MemberTypeViewer},
+			},
+		},
+		OwnerID: ownerID,
+	}
+
+	role, err := model.CheckRole(ownerID, budget.MemberTypeOwner)
+	require.NoError(t, err)
+	require.Equal(t, budget.MemberTypeOwner, role)
+
+	_, err = model.CheckRole(adminID, budget.MemberTypeAdmin)
+	require.NoError(t, err)
+
+	_, err = model.CheckRole(adminID, budget.MemberTypeOwner)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	_, err = model.CheckRole(viewerID, budget.MemberTypeAdmin)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	_, err = model.CheckRole(id.NewID[user.User](), budget.MemberTypeViewer)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+}
@@ -0,0 +1,313 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/budget"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+// Budget keeps limits as text in Amount columns, so they stay exact in every database
+type Budget struct {
+	ID             string                `gorm:"primaryKey;size:36;notNull"`
+	OwnerID        string                `gorm:"size:36;notNull;index"`
+	Title          string                `gorm:"notNull;size:255"`
+	Period         int32                 `gorm:"notNull"`
+	Currency       string                `gorm:"notNull;size:3"`
+	Amount         string                `gorm:"notNull;size:32"`
+	CreatedAt      time.Time             `gorm:"notNull"`
+	UpdatedAt      time.Time             `gorm:"notNull"`
+	Members        []BudgetMember        `gorm:"foreignKey:BudgetID;constraint:OnDelete:CASCADE"`
+	CategoryLimits []BudgetCategoryLimit `gorm:"foreignKey:BudgetID;constraint:OnDelete:CASCADE"`
+}
+
+type BudgetMember struct {
+	ID         string `gorm:"primaryKey;size:36;notNull"`
+	BudgetID   string `gorm:"size:36;notNull;uniqueIndex:idx_budget_user"`
+	UserID     string `gorm:"size:36;notNull;uniqueIndex:idx_budget_user;index"`
+	MemberType int32  `gorm:"notNull"`
+}
+
+type BudgetCategoryLimit struct {
+	BudgetID string `gorm:"primaryKey;size:36;notNull"`
+	Category string `gorm:"primaryKey;size:255;notNull"`
+	Amount   string `gorm:"notNull;size:32"`
+}
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(ctx context.Context, db *gorm.DB) (*Repo, error) {
+	if err := db.WithContext(ctx).AutoMigrate(new(Budget), new(BudgetMember), new(BudgetCategoryLimit)); err != nil {
+		return nil, fmt.Errorf("can't create budget tables: %w", err)
+	}
+
+	return &Repo{db: db}, nil
+}
+
+func (r *Repo) Create(ctx context.Context, model budget.Budget) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(budgetToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert budget %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, budgetID id.ID[budget.Budget]) (budget.Budget, error) {
+	entity, err := r.get(ctx, r.db, budgetID)
+	if err != nil {
+		return budget.Budget{}, err
+	}
+
+	return budgetToModel(entity), nil
+}
+
+// GetByUserIDs returns budgets which any of the users owns or is a member of
+func (r *Repo) GetByUserIDs(ctx context.Context, userIDs []id.ID[user.User]) ([]budget.Budget, error) {
+	var entities []Budget
+
+	rawIDs := lo.Map(userIDs, func(item id.ID[user.User], _ int) string { return item.String() })
+	memberOf := r.db.Model(new(BudgetMember)).Select("budget_id").Where("user_id IN ?", rawIDs)
+
+	err := r.db.WithContext(ctx).
+		Preload("Members").
+		Preload("CategoryLimits").
+		Where("owner_id IN ? OR id IN (?)", rawIDs, memberOf).
+		Order("created_at").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select budgets of users %v: %w", userIDs, err)
+	}
+
+	return lo.Map(entities, func(item Budget, _ int) budget.Budget { return budgetToModel(item) }), nil
+}
+
+// GetAndUpdate replaces budget by result of updateFunc
+func (r *Repo) GetAndUpdate(
+	ctx context.Context,
+	budgetID id.ID[budget.Budget],
+	updateFunc func(budget.Budget) (budget.Budget, error),
+) (
+	budget.Budget,
+	error,
+) {
+	var model budget.Budget
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entity, err := r.get(ctx, tx, budgetID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(budgetToModel(entity)); err != nil {
+			return err
+		}
+
+		entity = budgetToEntity(model)
+
+		if err = deleteContents(ctx, tx, budgetID); err != nil {
+			return err
+		}
+
+		if len(entity.Members) != 0 {
+			if err = tx.WithContext(ctx).Create(&entity.Members).Error; err != nil {
+				return fmt.Errorf("can't insert members of budget %s: %w", budgetID, err)
+			}
+		}
+
+		if len(entity.CategoryLimits) != 0 {
+			if err = tx.WithContext(ctx).Create(&entity.CategoryLimits).Error; err != nil {
+				return fmt.Errorf("can't insert category limits of budget %s: %w", budgetID, err)
+			}
+		}
+
+		query := &Budget{ID: entity.ID} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Model(query).
+			Select("title", "period", "currency", "amount", "updated_at").
+			Updates(&entity).Error
+		if err != nil {
+			return fmt.Errorf("can't update budget %s: %w", budgetID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return budget.Budget{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// Delete deletes budget if checkFunc allows it
+func (r *Repo) Delete(ctx context.Context, budgetID id.ID[budget.Budget], checkFunc func(budget.Budget) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entity, err := r.get(ctx, tx, budgetID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkFunc(budgetToModel(entity)); err != nil {
+			return err
+		}
+
+		if err = deleteContents(ctx, tx, budgetID); err != nil {
+			return err
+		}
+
+		if err = tx.WithContext(ctx).Delete(&Budget{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+			return fmt.Errorf("can't delete budget %s: %w", budgetID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// RenameCategories replaces renamed categories of limits by the name, it joins the transaction of ctx.
+// A budget may already have a limit of the name, it's kept.
+func (r *Repo) RenameCategories(
+	ctx context.Context,
+	name product.Category,
+	renamed func(product.Category) bool,
+) error {
+	err := dbtx.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var categories []string
+
+		err := tx.WithContext(ctx).Model(new(BudgetCategoryLimit)).Distinct().Pluck("category", &categories).Error
+		if err != nil {
+			return fmt.Errorf("can't select categories of budgets: %w", err)
+		}
+
+		olds := lo.Filter(categories, func(item string, _ int) bool { return renamed(product.Category(item)) })
+
+		for _, old := range olds {
+			existing := tx.WithContext(ctx).Model(new(BudgetCategoryLimit)).
+				Select("budget_id").
+				Where("category = ?", string(name))
+
+			err = tx.WithContext(ctx).
+				Where("category = ? AND budget_id IN (?)", old, existing).
+				Delete(new(BudgetCategoryLimit)).Error
+			if err != nil {
+				return fmt.Errorf("can't delete duplicated limits of category %q: %w", old, err)
+			}
+
+			err = tx.WithContext(ctx).Model(new(BudgetCategoryLimit)).
+				Where("category = ?", old).
+				Update("category", string(name)).Error
+			if err != nil {
+				return fmt.Errorf("can't rename limits of category %q: %w", old, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func deleteContents(ctx context.Context, tx *gorm.DB, budgetID id.ID[budget.Budget]) error {
+	for _, child := range []any{new(BudgetMember), new(BudgetCategoryLimit)} {
+		if err := tx.WithContext(ctx).Where("budget_id = ?", budgetID.String()).Delete(child).Error; err != nil {
+			return fmt.Errorf("can't delete contents of budget %s: %w", budgetID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repo) get(ctx context.Context, tx *gorm.DB, budgetID id.ID[budget.Budget]) (Budget, error) {
+	var entity Budget
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Members").
+		Preload("CategoryLimits").
+		Where("id = ?", budgetID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: budget %s", myerr.ErrNotFound, budgetID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select budget %s: %w", budgetID, err)
+	}
+
+	return entity, nil
+}
+
+func budgetToModel(entity Budget) budget.Budget {
+	return budget.Budget{
+		Options: budget.Options{
+			Title:    entity.Title,
+			Period:   budget.Period(entity.Period),
+			Currency: entity.Currency,
+			Limit:    god.Believe(decimal.NewFromString(entity.Amount)),
+			CategoryLimits: lo.Map(entity.CategoryLimits, func(item BudgetCategoryLimit, _ int) budget.CategoryLimit {
+				return budget.CategoryLimit{
+					Category: product.Category(item.Category),
+					Limit:    god.Believe(decimal.NewFromString(item.Amount)),
+				}
+			}),
+			Members: lo.Map(entity.Members, func(item BudgetMember, _ int) budget.MemberOptions {
+				return budget.MemberOptions{
+					UserID: id.ID[user.User]{UUID: god.Believe(uuid.Parse(item.UserID))},
+					Role:   budget.MemberType(item.MemberType),
+				}
+			}),
+		},
+		ID:        id.ID[budget.Budget]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		OwnerID:   id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func budgetToEntity(model budget.Budget) Budget {
+	return Budget{
+		ID:        model.ID.String(),
+		OwnerID:   model.OwnerID.String(),
+		Title:     model.Title,
+		Period:    int32(model.Period),
+		Currency:  model.Currency,
+		Amount:    model.Limit.String(),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		Members: lo.Map(model.Members, func(item budget.MemberOptions, _ int) BudgetMember {
+			return BudgetMember{
+				ID:         uuid.NewString(),
+				BudgetID:   model.ID.String(),
+				UserID:     item.UserID.String(),
+				MemberType: int32(item.Role),
+			}
+		}),
+		CategoryLimits: lo.Map(model.CategoryLimits, func(item budget.CategoryLimit, _ int) BudgetCategoryLimit {
+			return BudgetCategoryLimit{
+				BudgetID: model.ID.String(),
+				Category: string(item.Category),
+				Amount:   item.Limit.String(),
+			}
+		}),
+	}
+}
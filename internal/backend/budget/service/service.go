@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+
+	"go-backend/internal/backend/budget"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+type repo interface {
+	Create(context.Context, budget.Budget) error
+	GetByID(context.Context, id.ID[budget.Budget]) (budget.Budget, error)
+	GetByUserIDs(context.Context, []id.ID[user.User]) ([]budget.Budget, error)
+	GetAndUpdate(
+		context.Context,
+		id.ID[budget.Budget],
+		func(budget.Budget) (budget.Budget, error),
+	) (
+		budget.Budget,
+		error,
+	)
+	Delete(context.Context, id.ID[budget.Budget], func(budget.Budget) error) error
+}
+
+// purchases gives prices paid in lists of users
+type purchases interface {
+	GetPurchases(context.Context, []id.ID[user.User], time.Time, time.Time) ([]list.Purchase, error)
+}
+
+// listMates tells which users share lists, only they can be members of budgets of each other
+type listMates interface {
+	GetListMates(context.Context, id.ID[user.User], []id.ID[user.User]) ([]id.ID[user.User], error)
+}
+
+type Service struct {
+	repo      repo
+	purchases purchases
+	mates     listMates
+	clock     clock.Clock
+	log       zerolog.Logger
+}
+
+func NewService(
+	repo repo,
+	purchases purchases,
+	mates listMates,
+	clock clock.Clock,
+	log zerolog.Logger,
+) *Service {
+	return &Service{
+		repo:      repo,
+		purchases: purchases,
+		mates:     mates,
+		clock:     clock,
+		log:       log.With().Str("component", "budget service").Logger(),
+	}
+}
+
+func (s *Service) Create(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	options budget.Options,
+) (
+	budget.Budget,
+	error,
+) {
+	if err := validateOptions(options, ownerID); err != nil {
+		return budget.Budget{}, err
+	}
+
+	if err := s.checkMembers(ctx, ownerID, options.Members, nil); err != nil {
+		return budget.Budget{}, err
+	}
+
+	now := s.clock.Now()
+	model := budget.Budget{
+		Options:   options,
+		ID:        id.NewID[budget.Budget](),
+		OwnerID:   ownerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, model); err != nil {
+		return budget.Budget{}, fmt.Errorf("can't create budget: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetByID returns budget which the user owns or is a member of
+func (s *Service) GetByID(
+	ctx context.Context,
+	budgetID id.ID[budget.Budget],
+	userID id.ID[user.User],
+) (
+	budget.Budget,
+	error,
+) {
+	model, err := s.repo.GetByID(ctx, budgetID)
+	if err != nil {
+		return budget.Budget{}, fmt.Errorf("can't get budget %s: %w", budgetID, err)
+	}
+
+	if _, err = model.CheckRole(userID, budget.MemberTypeViewer); err != nil {
+		return budget.Budget{}, err
+	}
+
+	return model, nil
+}
+
+// GetByUserID returns budgets which the user owns or is a member of
+func (s *Service) GetByUserID(ctx context.Context, userID id.ID[user.User]) ([]budget.Budget, error) {
+	budgets, err := s.repo.GetByUserIDs(ctx, []id.ID[user.User]{userID})
+	if err != nil {
+		return nil, fmt.Errorf("can't get budgets of user %s: %w", userID, err)
+	}
+
+	return budgets, nil
+}
+
+// Update replaces options of the budget, only its owner and admins can do it
+func (s *Service) Update(
+	ctx context.Context,
+	budgetID id.ID[budget.Budget],
+	userID id.ID[user.User],
+	options budget.Options,
+) (
+	budget.Budget,
+	error,
+) {
+	model, err := s.repo.GetAndUpdate(ctx, budgetID, func(model budget.Budget) (budget.Budget, error) {
+		if _, err := model.CheckRole(userID, budget.MemberTypeAdmin); err != nil {
+			return model, err
+		}
+
+		if err := validateOptions(options, model.OwnerID); err != nil {
+			return model, err
+		}
+
+		if err := s.checkMembers(ctx, model.OwnerID, options.Members, model.Members); err != nil {
+			return model, err
+		}
+
+		model.Options = options
+		model.UpdatedAt = s.clock.Now()
+
+		return model, nil
+	})
+	if err != nil {
+		return budget.Budget{}, fmt.Errorf("can't update budget %s: %w", budgetID, err)
+	}
+
+	return model, nil
+}
+
+// Delete deletes the budget, only its owner can do it
+func (s *Service) Delete(ctx context.Context, budgetID id.ID[budget.Budget], userID id.ID[user.User]) error {
+	err := s.repo.Delete(ctx, budgetID, func(model budget.Budget) error {
+		_, err := model.CheckRole(userID, budget.MemberTypeOwner)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete budget %s: %w", budgetID, err)
+	}
+
+	return nil
+}
+
+// GetStatus returns progress of the budget in the period which contains at
+func (s *Service) GetStatus(
+	ctx context.Context,
+	budgetID id.ID[budget.Budget],
+	userID id.ID[user.User],
+	at time.Time,
+) (
+	budget.Status,
+	error,
+) {
+	model, err := s.GetByID(ctx, budgetID, userID)
+	if err != nil {
+		return budget.Status{}, err
+	}
+
+	purchases, err := s.purchases.GetPurchases(ctx, model.UserIDs(), model.Period.Start(at), model.Period.End(at))
+	if err != nil {
+		return budget.Status{}, fmt.Errorf("can't get purchases of budget %s: %w", budgetID, err)
+	}
+
+	return model.Status(purchases, at), nil
+}
+
+// CheckPurchases returns limits of budgets of list members which are exceeded by the new purchases.
+// The purchases must be already saved, so they're counted in spending of the current period.
+func (s *Service) CheckPurchases(
+	ctx context.Context,
+	model list.ProductList,
+	newPurchases []list.Purchase,
+) (
+	[]list.BudgetExceededChange,
+	error,
+) {
+	if len(newPurchases) == 0 {
+		return nil, nil
+	}
+
+	userIDs := lo.Map(model.Members, func(item list.Member, _ int) id.ID[user.User] { return item.UserID })
+
+	budgets, err := s.repo.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get budgets of members of list %s: %w", model.ID, err)
+	}
+
+	now := s.clock.Now()
+	changes := make([]list.BudgetExceededChange, 0)
+
+	for _, item := range budgets {
+		added := item.Status(newPurchases, now)
+		if added.Items == 0 {
+			continue
+		}
+
+		purchases, err := s.purchases.GetPurchases(ctx, item.UserIDs(), added.PeriodStart, added.PeriodEnd)
+		if err != nil {
+			return nil, fmt.Errorf("can't get purchases of budget %s: %w", item.ID, err)
+		}
+
+		status := item.Status(purchases, now)
+
+		if exceededNow(status.Limit, status.Spent, added.Spent) {
+			changes = append(changes, exceededChange(item, mo.None[product.Category](), status.Limit, status.Spent))
+		}
+
+		for i, category := range status.Categories {
+			if exceededNow(category.Limit, category.Spent, added.Categories[i].Spent) {
+				changes = append(changes, exceededChange(item, mo.Some(category.Category), category.Limit, category.Spent))
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// exceededNow reports whether spent is over the limit, but wasn't before added was spent
+func exceededNow(limit, spent, added decimal.Decimal) bool {
+	return spent.GreaterThan(limit) && !spent.Sub(added).GreaterThan(limit)
+}
+
+func exceededChange(
+	model budget.Budget,
+	category mo.Option[product.Category],
+	limit, spent decimal.Decimal,
+) list.BudgetExceededChange {
+	return list.BudgetExceededChange{
+		BudgetID: model.ID.UUID,
+		UserIDs:  model.UserIDs(),
+		Title:    model.Title,
+		Category: category,
+		Limit:    list.Price{Amount: limit, Currency: model.Currency},
+		Spent:    list.Price{Amount: spent, Currency: model.Currency},
+	}
+}
+
+// checkMembers checks that members added to the budget share a list with its owner,
+// otherwise anyone could count spending of strangers and get alerts about their lists
+func (s *Service) checkMembers(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	members, oldMembers []budget.MemberOptions,
+) error {
+	added := lo.Without(
+		lo.Map(members, func(item budget.MemberOptions, _ int) id.ID[user.User] { return item.UserID }),
+		lo.Map(oldMembers, func(item budget.MemberOptions, _ int) id.ID[user.User] { return item.UserID })...,
+	)
+	if len(added) == 0 {
+		return nil
+	}
+
+	mates, err := s.mates.GetListMates(ctx, ownerID, added)
+	if err != nil {
+		return fmt.Errorf("can't check members of budget: %w", err)
+	}
+
+	for _, userID := range added {
+		if !lo.Contains(mates, userID) {
+			return fmt.Errorf("%w: budget member %s doesn't share a list with the owner",
+				myerr.ErrInvalidArgument, userID)
+		}
+	}
+
+	return nil
+}
+
+// validateOptions checks options of a budget owned by ownerID
+func validateOptions(options budget.Options, ownerID id.ID[user.User]) error {
+	if err := validator.New().Struct(options); err != nil {
+		return fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	if !options.Period.IsValid() {
+		return fmt.Errorf("%w: unknown budget period %d", myerr.ErrInvalidArgument, options.Period)
+	}
+
+	if err := (list.Price{Amount: options.Limit, Currency: options.Currency}).Validate(); err != nil {
+		return err
+	}
+
+	if !options.Limit.IsPositive() {
+		return fmt.Errorf("%w: limit of budget must be positive", myerr.ErrInvalidArgument)
+	}
+
+	for _, item := range options.CategoryLimits {
+		if !item.Limit.IsPositive() {
+			return fmt.Errorf("%w: limit of category %s must be positive", myerr.ErrInvalidArgument, item.Category)
+		}
+	}
+
+	for _, member := range options.Members {
+		if member.UserID == ownerID {
+			return fmt.Errorf("%w: owner of budget can't be its member", myerr.ErrInvalidArgument)
+		}
+
+		if member.Role <= budget.MemberTypeOwner || !member.Role.IsValid() {
+			return fmt.Errorf("%w: role of budget member %s must be admin or viewer",
+				myerr.ErrInvalidArgument, member.UserID)
+		}
+	}
+
+	return nil
+}
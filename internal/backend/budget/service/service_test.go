@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/budget"
+	"go-backend/internal/backend/budget/repo"
+	"go-backend/internal/backend/budget/service"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// lists keeps purchases in memory, mates are users who share a list with anyone
+type lists struct {
+	purchases []list.Purchase
+	mates     []id.ID[user.User]
+}
+
+func (l *lists) GetPurchases(context.Context, []id.ID[user.User], time.Time, time.Time) ([]list.Purchase, error) {
+	return l.purchases, nil
+}
+
+func (l *lists) GetListMates(
+	_ context.Context,
+	_ id.ID[user.User],
+	candidateIDs []id.ID[user.User],
+) (
+	[]id.ID[user.User],
+	error,
+) {
+	var mates []id.ID[user.User]
+	for _, userID := range candidateIDs {
+		if slices.Contains(l.mates, userID) {
+			mates = append(mates, userID)
+		}
+	}
+
+	return mates, nil
+}
+
+func newService(t *testing.T, lists *lists, now time.Time) *service.Service {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "budget.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	budgets, err := repo.NewRepo(context.Background(), db)
+	require.NoError(t, err)
+
+	return service.NewService(budgets, lists, lists, clock.NewFake(now), zerolog.Nop())
+}
+
+func newOptions(members ...budget.MemberOptions) budget.Options {
+	return budget.Options{
+		Title:          "groceries",
+		Period:         budget.PeriodWeek,
+		Currency:       "EUR",
+		Limit:          decimal.RequireFromString("10"),
+		CategoryLimits: []budget.CategoryLimit{},
+		Members:        members,
+	}
+}
+
+func TestMembers(t *testing.T) {
+	ctx := context.Background()
+	ownerID, mateID, strangerID := id.NewID[user.User](), id.NewID[user.User](), id.NewID[user.User]()
+	mates := &lists{mates: []id.ID[user.User]{mateID}}
+	s := newService(t, mates, time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC))
+
+	mate := budget.MemberOptions{UserID: mateID, Role: budget.MemberTypeViewer}
+	stranger := budget.MemberOptions{UserID: strangerID, Role: budget.MemberTypeViewer}
+
+	_, err := s.Create(ctx, ownerID, newOptions(stranger))
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument, "strangers can't be members")
+
+	model, err := s.Create(ctx, ownerID, newOptions(mate))
+	require.NoError(t, err)
+
+	_, err = s.Update(ctx, model.ID, ownerID, newOptions(mate, stranger))
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	// members stay when they don't share lists with the owner anymore
+	mates.mates = nil
+	model.Title = "food"
+	_, err = s.Update(ctx, model.ID, ownerID, model.Options)
+	require.NoError(t, err)
+}
+
+func TestCheckPurchases(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)
+	ownerID, mateID := id.NewID[user.User](), id.NewID[user.User]()
+	purchases := &lists{mates: []id.ID[user.User]{mateID}}
+	s := newService(t, purchases, now)
+
+	mate := budget.MemberOptions{UserID: mateID, Role: budget.MemberTypeViewer}
+	model, err := s.Create(ctx, ownerID, newOptions(mate))
+	require.NoError(t, err)
+
+	purchase := list.Purchase{
+		ListID:      id.NewID[list.ProductList](),
+		PurchasedAt: now,
+		Price:       list.Price{Amount: decimal.RequireFromString("12"), Currency: "EUR"},
+	}
+	purchases.purchases = []list.Purchase{purchase}
+
+	// the list has a member out of the budget, who doesn't get the alert
+	changes, err := s.CheckPurchases(ctx, list.ProductList{Members: []list.Member{
+		{MemberOptions: list.MemberOptions{UserID: ownerID, Role: list.MemberTypeOwner}},
+		{MemberOptions: list.MemberOptions{UserID: id.NewID[user.User](), Role: list.MemberTypeEditor}},
+	}}, []list.Purchase{purchase})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, model.ID.UUID, changes[0].BudgetID)
+	require.Equal(t, []id.ID[user.User]{ownerID, mateID}, changes[0].UserIDs)
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/category"
+	"go-backend/internal/backend/category/service"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	log = log.With().Str("component", "category rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	group := r.Group("/categories")
+	group.GET("", h.GetAll)
+	group.GET("/:id", h.Get)
+
+	admin := group.Group("", api.NewRoleMiddleware(user.RoleAdmin))
+	admin.POST("", h.Create)
+	admin.PUT("/:id", h.Update)
+	admin.DELETE("/:id", h.Delete)
+	admin.POST("/merge", h.Merge)
+}
+
+// @Summary get all categories sorted by name, children refer parents by ids
+// @ID categories-get
+// @Tags Categories
+// @Produce json
+// @Router /categories [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetAll(ctx *gin.Context) {
+	categories, err := h.service.GetAll(ctx)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, categories)
+}
+
+// @Summary get category
+// @ID category-get-by-id
+// @Tags Categories
+// @Param id path string true "category id"
+// @Produce json
+// @Router /categories/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) Get(ctx *gin.Context) {
+	categoryID, ok := rerr.PathID[category.Category](ctx)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.GetByID(ctx, categoryID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary create category, only for admins
+// @ID category-create
+// @Tags Categories
+// @Param body body category.Options true "name, parent, localized names and aliases"
+// @Produce json
+// @Accept json
+// @Router /categories [post]
+// @Security ApiKeyAuth
+func (h *Handler) Create(ctx *gin.Context) {
+	var options category.Options
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.Create(ctx, options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary update category, renamed category is renamed in shop maps and budgets, only for admins
+// @ID category-update
+// @Tags Categories
+// @Param id path string true "category id"
+// @Param body body category.Options true "new options of category"
+// @Produce json
+// @Accept json
+// @Router /categories/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) Update(ctx *gin.Context) {
+	categoryID, ok := rerr.PathID[category.Category](ctx)
+	if !ok {
+		return
+	}
+
+	var options category.Options
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.Update(ctx, categoryID, options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete category without children and products, only for admins
+// @ID category-delete
+// @Tags Categories
+// @Param id path string true "category id"
+// @Router /categories/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) Delete(ctx *gin.Context) {
+	categoryID, ok := rerr.PathID[category.Category](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, categoryID); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary merge categories into the target one which replaces them everywhere, only for admins
+// @ID categories-merge
+// @Tags Categories
+// @Param body body category.MergeOptions true "merged categories and the target one"
+// @Produce json
+// @Accept json
+// @Router /categories/merge [post]
+// @Security ApiKeyAuth
+func (h *Handler) Merge(ctx *gin.Context) {
+	var options category.MergeOptions
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.Merge(ctx, options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
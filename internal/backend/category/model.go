@@ -0,0 +1,170 @@
+// Package category manages tree of product categories, products and shop maps refer categories by names
+package category
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// Options are editable options of a category
+type Options struct {
+	Name     product.Category           `json:"name" swaggertype:"string" validate:"required,max=255"`
+	ParentID mo.Option[id.ID[Category]] `json:"parent_id" swaggertype:"string" extensions:"x-nullable"`
+	// Names are localized names by language codes
+	Names map[string]product.Category `json:"names" validate:"dive,keys,required,max=8,endkeys,required,max=255"`
+	// Aliases are other spellings of the name, e.g. names of merged categories
+	Aliases []product.Category `json:"aliases" validate:"dive,required,max=255"`
+}
+
+type Category struct {
+	Options
+
+	ID id.ID[Category] `json:"id" swaggertype:"string"`
+}
+
+// MergeOptions are categories merged into the target one
+type MergeOptions struct {
+	SourceIDs []id.ID[Category] `json:"source_ids" swaggertype:"array,string" validate:"required,min=1,unique"`
+	TargetID  id.ID[Category]   `json:"target_id" swaggertype:"string"`
+}
+
+// Keys returns normalized names, localized names and aliases of the category
+func (c Category) Keys() []string {
+	names := append([]product.Category{c.Name}, c.Aliases...)
+	for _, lang := range slices.Sorted(maps.Keys(c.Names)) {
+		names = append(names, c.Names[lang])
+	}
+
+	return lo.Uniq(lo.Map(names, func(item product.Category, _ int) string { return NormalizeName(item) }))
+}
+
+// Absorb returns the category with names of the source one, the name of the source becomes an alias,
+// its localized names are kept unless the category has names in the same languages
+func (c Category) Absorb(source Category) Category {
+	names := make(map[string]product.Category, len(c.Names)+len(source.Names))
+	maps.Copy(names, c.Names)
+
+	aliases := slices.Clone(c.Aliases)
+	aliases = append(aliases, source.Name)
+	aliases = append(aliases, source.Aliases...)
+
+	for lang, name := range source.Names {
+		if _, found := names[lang]; found {
+			aliases = append(aliases, name)
+		} else {
+			names[lang] = name
+		}
+	}
+
+	own := NormalizeName(c.Name)
+	c.Names = names
+	c.Aliases = lo.UniqBy(
+		lo.Filter(aliases, func(item product.Category, _ int) bool { return NormalizeName(item) != own }),
+		NormalizeName,
+	)
+
+	return c
+}
+
+// NormalizeName returns a key of category name which ignores case, punctuation and extra spaces
+func NormalizeName(name product.Category) string {
+	return product.NormalizeName(product.Name(name))
+}
+
+// Tree is a set of categories with parent-child relations
+type Tree struct {
+	categories map[id.ID[Category]]Category
+}
+
+func NewTree(categories []Category) Tree {
+	return Tree{categories: lo.KeyBy(categories, func(item Category) id.ID[Category] { return item.ID })}
+}
+
+// Get returns category of the tree
+func (t Tree) Get(categoryID id.ID[Category]) (Category, error) {
+	model, found := t.categories[categoryID]
+	if !found {
+		return model, fmt.Errorf("%w: category %s", myerr.ErrNotFound, categoryID)
+	}
+
+	return model, nil
+}
+
+// Check returns error if the category can't be put into the tree:
+// its parent doesn't exist, it becomes an ancestor of itself or its names belong to other categories
+func (t Tree) Check(model Category) error {
+	for parentID, found := model.ParentID.Get(); found; {
+		if parentID == model.ID {
+			return fmt.Errorf("%w: category %s can't be a descendant of itself", myerr.ErrInvalidArgument, model.ID)
+		}
+
+		parent, err := t.Get(parentID)
+		if err != nil {
+			return fmt.Errorf("%w: parent of category %s doesn't exist: %w", myerr.ErrInvalidArgument, model.ID, err)
+		}
+
+		parentID, found = parent.ParentID.Get()
+	}
+
+	keys := model.Keys()
+
+	for _, other := range t.categories {
+		if other.ID == model.ID {
+			continue
+		}
+
+		if common := lo.Intersect(keys, other.Keys()); len(common) > 0 {
+			return fmt.Errorf("%w: name %q belongs to category %s", myerr.ErrAlreadyExists, common[0], other.ID)
+		}
+	}
+
+	return nil
+}
+
+// Merge returns the target category with names of source ones, the target must not be a descendant of sources
+func (t Tree) Merge(options MergeOptions) (Category, error) {
+	target, err := t.Get(options.TargetID)
+	if err != nil {
+		return target, err
+	}
+
+	for _, sourceID := range options.SourceIDs {
+		source, err := t.Get(sourceID)
+		if err != nil {
+			return target, err
+		}
+
+		if t.IsDescendant(target.ID, sourceID) {
+			return target, fmt.Errorf(
+				"%w: category %s can't be merged into itself or its descendant",
+				myerr.ErrInvalidArgument,
+				sourceID,
+			)
+		}
+
+		target = target.Absorb(source)
+	}
+
+	return target, nil
+}
+
+// IsDescendant returns true if the category is the ancestor or its descendant
+func (t Tree) IsDescendant(categoryID, ancestorID id.ID[Category]) bool {
+	for current, found := categoryID, true; found; {
+		if current == ancestorID {
+			return true
+		}
+
+		current, found = t.categories[current].ParentID.Get()
+	}
+
+	return false
+}
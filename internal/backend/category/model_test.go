@@ -0,0 +1,65 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/category"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestTree(t *testing.T) {
+	newCategory := func(name string, parentID mo.Option[id.ID[category.Category]]) category.Category {
+		return category.Category{
+			Options: category.Options{
+				Name:     product.Category(name),
+				ParentID: parentID,
+				Names:    map[string]product.Category{},
+				Aliases:  []product.Category{},
+			},
+			ID: id.NewID[category.Category](),
+		}
+	}
+
+	food := newCategory("Food", mo.None[id.ID[category.Category]]())
+	dairy := newCategory("Dairy", mo.Some(food.ID))
+	dairy.Names["ru"] = "Молочные продукты"
+	cheese := newCategory("Cheese", mo.Some(dairy.ID))
+	molochka := newCategory("Молочка", mo.None[id.ID[category.Category]]())
+	molochka.Names = map[string]product.Category{"ru": "Молочка", "be": "Малочка"}
+	molochka.Aliases = []product.Category{"dairy products"}
+
+	tree := category.NewTree([]category.Category{food, dairy, cheese, molochka})
+
+	// names are compared normalized
+	require.NoError(t, tree.Check(newCategory("Meat", mo.Some(food.ID))))
+	require.ErrorIs(t, tree.Check(newCategory(" dairy!", mo.None[id.ID[category.Category]]())), myerr.ErrAlreadyExists)
+	require.ErrorIs(t, tree.Check(newCategory("молочные продукты", mo.None[id.ID[category.Category]]())),
+		myerr.ErrAlreadyExists)
+	require.NoError(t, tree.Check(dairy))
+
+	// parents must exist and can't be descendants
+	unknown := mo.Some(id.NewID[category.Category]())
+	require.ErrorIs(t, tree.Check(newCategory("Meat", unknown)), myerr.ErrInvalidArgument)
+	food.ParentID = mo.Some(cheese.ID)
+	require.ErrorIs(t, tree.Check(food), myerr.ErrInvalidArgument)
+
+	merged, err := tree.Merge(category.MergeOptions{
+		SourceIDs: []id.ID[category.Category]{molochka.ID},
+		TargetID:  dairy.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]product.Category{"ru": "Молочные продукты", "be": "Малочка"}, merged.Names)
+	require.Equal(t, []product.Category{"Молочка", "dairy products"}, merged.Aliases)
+	require.Equal(t, dairy.ParentID, merged.ParentID)
+
+	_, err = tree.Merge(category.MergeOptions{SourceIDs: []id.ID[category.Category]{food.ID}, TargetID: cheese.ID})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+	_, err = tree.Merge(category.MergeOptions{SourceIDs: []id.ID[category.Category]{id.NewID[category.Category]()},
+		TargetID: dairy.ID})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
@@ -0,0 +1,309 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/category"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+// Repo stores categories in product category tables
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(ctx context.Context, db *gorm.DB) (*Repo, error) {
+	err := db.WithContext(ctx).AutoMigrate(new(productRepo.ProductCategory), new(productRepo.ProductCategoryName))
+	if err != nil {
+		return nil, fmt.Errorf("can't create category tables: %w", err)
+	}
+
+	return &Repo{db: db}, nil
+}
+
+func (r *Repo) Create(ctx context.Context, model category.Category) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(modelToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert category %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+// GetAll returns all categories sorted by name
+func (r *Repo) GetAll(ctx context.Context) ([]category.Category, error) {
+	var entities []productRepo.ProductCategory
+
+	if err := r.db.WithContext(ctx).Preload("Names").Order("name").Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("can't select categories: %w", err)
+	}
+
+	return lo.Map(entities, func(item productRepo.ProductCategory, _ int) category.Category {
+		return entityToModel(item)
+	}), nil
+}
+
+func (r *Repo) GetByID(ctx context.Context, categoryID id.ID[category.Category]) (category.Category, error) {
+	entity, err := r.get(ctx, r.db, categoryID)
+	if err != nil {
+		return category.Category{}, err
+	}
+
+	return entityToModel(entity), nil
+}
+
+// GetAndUpdate replaces category by result of updateFunc, it joins the transaction of ctx
+func (r *Repo) GetAndUpdate(
+	ctx context.Context,
+	categoryID id.ID[category.Category],
+	updateFunc func(category.Category) (category.Category, error),
+) (
+	category.Category,
+	error,
+) {
+	var model category.Category
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.get(ctx, tx, categoryID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(entityToModel(entity)); err != nil {
+			return err
+		}
+
+		return r.save(ctx, tx, modelToEntity(model))
+	})
+	if err != nil {
+		return category.Category{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// Delete deletes category, categories with children or products can't be deleted
+func (r *Repo) Delete(ctx context.Context, categoryID id.ID[category.Category]) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := r.get(ctx, tx, categoryID); err != nil {
+			return err
+		}
+
+		var children, products int64
+
+		err := tx.WithContext(ctx).Model(new(productRepo.ProductCategory)).
+			Where("parent_id = ?", categoryID.String()).Count(&children).Error
+		if err != nil {
+			return fmt.Errorf("can't count children of category %s: %w", categoryID, err)
+		}
+
+		err = tx.WithContext(ctx).Model(new(productRepo.Product)).
+			Where("category_id = ?", categoryID.String()).Count(&products).Error
+		if err != nil {
+			return fmt.Errorf("can't count products of category %s: %w", categoryID, err)
+		}
+
+		if children != 0 || products != 0 {
+			return fmt.Errorf(
+				"%w: category %s has %d children and %d products, merge it instead",
+				myerr.ErrConflict,
+				categoryID,
+				children,
+				products,
+			)
+		}
+
+		return r.delete(ctx, tx, []string{categoryID.String()})
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// Merge replaces sources by the target category in products, children of sources become children of the target
+// and sources are deleted. It joins the transaction of ctx.
+func (r *Repo) Merge(
+	ctx context.Context,
+	target category.Category,
+	sources []category.Category,
+) error {
+	sourceIDs := lo.Map(sources, func(item category.Category, _ int) string { return item.ID.String() })
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		err := tx.WithContext(ctx).Model(new(productRepo.Product)).
+			Where("category_id IN ?", sourceIDs).
+			Update("category_id", target.ID.String()).Error
+		if err != nil {
+			return fmt.Errorf("can't move products to category %s: %w", target.ID, err)
+		}
+
+		err = tx.WithContext(ctx).Model(new(productRepo.ProductCategory)).
+			Where("parent_id IN ?", sourceIDs).
+			Update("parent_id", target.ID.String()).Error
+		if err != nil {
+			return fmt.Errorf("can't move children to category %s: %w", target.ID, err)
+		}
+
+		if err = r.delete(ctx, tx, sourceIDs); err != nil {
+			return err
+		}
+
+		return r.save(ctx, tx, modelToEntity(target))
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
+
+// Transaction calls f in one transaction, updates of categories and other repositories called with ctx of f join it
+func (r *Repo) Transaction(ctx context.Context, f func(context.Context) error) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, _ *gorm.DB) error {
+		return f(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
+
+func (r *Repo) get(
+	ctx context.Context,
+	tx *gorm.DB,
+	categoryID id.ID[category.Category],
+) (
+	productRepo.ProductCategory,
+	error,
+) {
+	var entity productRepo.ProductCategory
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Names").
+		Where("id = ?", categoryID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: category %s", myerr.ErrNotFound, categoryID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select category %s: %w", categoryID, err)
+	}
+
+	return entity, nil
+}
+
+// save updates category and replaces its localized names and aliases
+func (r *Repo) save(ctx context.Context, tx *gorm.DB, entity productRepo.ProductCategory) error {
+	err := tx.WithContext(ctx).Where("category_id = ?", entity.ID).Delete(new(productRepo.ProductCategoryName)).Error
+	if err != nil {
+		return fmt.Errorf("can't delete names of category %s: %w", entity.ID, err)
+	}
+
+	if len(entity.Names) != 0 {
+		if err = tx.WithContext(ctx).Create(&entity.Names).Error; err != nil {
+			return fmt.Errorf("can't insert names of category %s: %w", entity.ID, err)
+		}
+	}
+
+	query := &productRepo.ProductCategory{ID: entity.ID} //nolint:exhaustruct
+
+	err = tx.WithContext(ctx).Model(query).
+		Select("name", "normalized_name", "parent_id").
+		Omit(clause.Associations).
+		Updates(&entity).Error
+	if err != nil {
+		return fmt.Errorf("can't update category %s: %w", entity.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) delete(ctx context.Context, tx *gorm.DB, categoryIDs []string) error {
+	err := tx.WithContext(ctx).Where("category_id IN ?", categoryIDs).Delete(new(productRepo.ProductCategoryName)).Error
+	if err != nil {
+		return fmt.Errorf("can't delete names of categories %v: %w", categoryIDs, err)
+	}
+
+	err = tx.WithContext(ctx).Where("id IN ?", categoryIDs).Delete(new(productRepo.ProductCategory)).Error
+	if err != nil {
+		return fmt.Errorf("can't delete categories %v: %w", categoryIDs, err)
+	}
+
+	return nil
+}
+
+func entityToModel(entity productRepo.ProductCategory) category.Category {
+	model := category.Category{
+		Options: category.Options{
+			Name:     product.Category(entity.Name),
+			ParentID: mo.None[id.ID[category.Category]](),
+			Names:    map[string]product.Category{},
+			Aliases:  []product.Category{},
+		},
+		ID: id.ID[category.Category]{UUID: god.Believe(uuid.Parse(entity.ID))},
+	}
+
+	if entity.ParentID.Valid {
+		model.ParentID = mo.Some(id.ID[category.Category]{UUID: god.Believe(uuid.Parse(entity.ParentID.String))})
+	}
+
+	for _, name := range entity.Names {
+		if name.Lang == "" {
+			model.Aliases = append(model.Aliases, product.Category(name.Name))
+		} else {
+			model.Names[name.Lang] = product.Category(name.Name)
+		}
+	}
+
+	return model
+}
+
+func modelToEntity(model category.Category) productRepo.ProductCategory {
+	newName := func(lang string, name product.Category) productRepo.ProductCategoryName {
+		return productRepo.ProductCategoryName{
+			ID:             uuid.NewString(),
+			CategoryID:     model.ID.String(),
+			Lang:           lang,
+			Name:           string(name),
+			NormalizedName: category.NormalizeName(name),
+		}
+	}
+
+	names := lo.Map(model.Aliases, func(item product.Category, _ int) productRepo.ProductCategoryName {
+		return newName("", item)
+	})
+	for _, lang := range slices.Sorted(maps.Keys(model.Names)) {
+		names = append(names, newName(lang, model.Names[lang]))
+	}
+
+	parentID := sql.NullString{String: "", Valid: false}
+	if value, found := model.ParentID.Get(); found {
+		parentID = sql.NullString{String: value.String(), Valid: true}
+	}
+
+	return productRepo.ProductCategory{
+		ID:             model.ID.String(),
+		Name:           string(model.Name),
+		NormalizedName: category.NormalizeName(model.Name),
+		ParentID:       parentID,
+		Names:          names,
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/category"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+type repo interface {
+	Create(context.Context, category.Category) error
+	GetAll(context.Context) ([]category.Category, error)
+	GetByID(context.Context, id.ID[category.Category]) (category.Category, error)
+	GetAndUpdate(
+		context.Context,
+		id.ID[category.Category],
+		func(category.Category) (category.Category, error),
+	) (
+		category.Category,
+		error,
+	)
+	Delete(context.Context, id.ID[category.Category]) error
+	Merge(context.Context, category.Category, []category.Category) error
+	Transaction(context.Context, func(context.Context) error) error
+}
+
+// references are shop maps and budgets, they refer categories by names and are rewritten
+// in the transaction of renames and merges
+type references interface {
+	RenameCategories(ctx context.Context, name product.Category, renamed func(product.Category) bool) error
+}
+
+// products have categories in the search index, it's reloaded when categories are renamed
+type products interface {
+	LoadIndex(context.Context) error
+}
+
+type Service struct {
+	repo     repo
+	products products
+	budgets  references
+	shopMaps references
+	log      zerolog.Logger
+	// lock serializes changes of the tree, names are checked against all categories
+	lock sync.Mutex
+}
+
+func NewService(repo repo, products products, budgets, shopMaps references, log zerolog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		budgets:  budgets,
+		shopMaps: shopMaps,
+		log:      log.With().Str("component", "category service").Logger(),
+		lock:     sync.Mutex{},
+	}
+}
+
+func (s *Service) Create(ctx context.Context, options category.Options) (category.Category, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	model := category.Category{
+		Options: options,
+		ID:      id.NewID[category.Category](),
+	}
+
+	if err := s.check(ctx, model); err != nil {
+		return category.Category{}, err
+	}
+
+	if err := s.repo.Create(ctx, model); err != nil {
+		return category.Category{}, fmt.Errorf("can't create category: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetAll returns all categories sorted by name, children refer parents by ids
+func (s *Service) GetAll(ctx context.Context) ([]category.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, categoryID id.ID[category.Category]) (category.Category, error) {
+	model, err := s.repo.GetByID(ctx, categoryID)
+	if err != nil {
+		return category.Category{}, fmt.Errorf("can't get category %s: %w", categoryID, err)
+	}
+
+	return model, nil
+}
+
+// Update changes the category, renamed category is renamed in shop maps and budgets too
+func (s *Service) Update(
+	ctx context.Context,
+	categoryID id.ID[category.Category],
+	options category.Options,
+) (
+	category.Category,
+	error,
+) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.check(ctx, category.Category{Options: options, ID: categoryID}); err != nil {
+		return category.Category{}, err
+	}
+
+	var model category.Category
+	var old product.Category
+
+	err := s.repo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		model, err = s.repo.GetAndUpdate(ctx, categoryID, func(model category.Category) (category.Category, error) {
+			old = model.Name
+			model.Options = options
+
+			return model, nil
+		})
+		if err != nil || old == model.Name {
+			return err
+		}
+
+		return s.rename(ctx, []string{category.NormalizeName(old)}, model.Name)
+	})
+	if err != nil {
+		return category.Category{}, fmt.Errorf("can't update category %s: %w", categoryID, err)
+	}
+
+	if old != model.Name {
+		s.reloadProducts(ctx)
+	}
+
+	return model, nil
+}
+
+// Delete deletes category without children and products
+func (s *Service) Delete(ctx context.Context, categoryID id.ID[category.Category]) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.repo.Delete(ctx, categoryID); err != nil {
+		return fmt.Errorf("can't delete category %s: %w", categoryID, err)
+	}
+
+	return nil
+}
+
+// Merge moves products, children and names of source categories to the target one and deletes sources,
+// shop maps and budgets get the target category instead of sources
+func (s *Service) Merge(ctx context.Context, options category.MergeOptions) (category.Category, error) {
+	if err := validator.New().Struct(options); err != nil {
+		return category.Category{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tree, err := s.tree(ctx)
+	if err != nil {
+		return category.Category{}, err
+	}
+
+	target, err := tree.Merge(options)
+	if err != nil {
+		return category.Category{}, err
+	}
+
+	sources := lo.Map(options.SourceIDs, func(item id.ID[category.Category], _ int) category.Category {
+		return god.Believe(tree.Get(item))
+	})
+
+	keys := lo.FlatMap(sources, func(item category.Category, _ int) []string { return item.Keys() })
+
+	err = s.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Merge(ctx, target, sources); err != nil {
+			return err
+		}
+
+		return s.rename(ctx, keys, target.Name)
+	})
+	if err != nil {
+		return category.Category{}, fmt.Errorf("can't merge categories into %s: %w", target.ID, err)
+	}
+
+	s.reloadProducts(ctx)
+
+	return target, nil
+}
+
+// Canonical returns names of categories which have the given names, localized names or aliases,
+// unknown names are returned as is
+func (s *Service) Canonical(ctx context.Context, names []product.Category) ([]product.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get categories: %w", err)
+	}
+
+	canonical := make(map[string]product.Category, len(categories))
+	for _, model := range categories {
+		for _, key := range model.Keys() {
+			canonical[key] = model.Name
+		}
+	}
+
+	return lo.Map(names, func(item product.Category, _ int) product.Category {
+		if name, found := canonical[category.NormalizeName(item)]; found {
+			return name
+		}
+
+		return item
+	}), nil
+}
+
+// check validates options of the category and checks it against other categories
+func (s *Service) check(ctx context.Context, model category.Category) error {
+	if err := validator.New().Struct(model.Options); err != nil {
+		return fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	tree, err := s.tree(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tree.Check(model)
+}
+
+func (s *Service) tree(ctx context.Context) (category.Tree, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return category.Tree{}, fmt.Errorf("can't get categories: %w", err)
+	}
+
+	return category.NewTree(categories), nil
+}
+
+// rename replaces categories with the normalized names by the name in budgets and shop maps
+func (s *Service) rename(ctx context.Context, keys []string, name product.Category) error {
+	renamed := func(item product.Category) bool {
+		return item != name && slices.Contains(keys, category.NormalizeName(item))
+	}
+
+	if err := s.budgets.RenameCategories(ctx, name, renamed); err != nil {
+		return fmt.Errorf("can't rename categories of budgets: %w", err)
+	}
+
+	if err := s.shopMaps.RenameCategories(ctx, name, renamed); err != nil {
+		return fmt.Errorf("can't rename categories of shop maps: %w", err)
+	}
+
+	return nil
+}
+
+// reloadProducts refreshes categories of products in the search index, failures only make search stale
+func (s *Service) reloadProducts(ctx context.Context) {
+	if err := s.products.LoadIndex(ctx); err != nil {
+		s.log.Err(err).Msg("can't reload product search index")
+	}
+}
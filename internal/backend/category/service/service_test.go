@@ -0,0 +1,215 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/budget"
+	budgetRepo "go-backend/internal/backend/budget/repo"
+	"go-backend/internal/backend/category"
+	"go-backend/internal/backend/category/repo"
+	"go-backend/internal/backend/category/service"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/internal/backend/shopmap"
+	shopMapRepo "go-backend/internal/backend/shopmap/repo"
+	"go-backend/internal/backend/shopmap/repo/sqlgen"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+// fixture is a category service over sqlite, budgets and shop maps share the database
+type fixture struct {
+	db       *gorm.DB
+	service  *service.Service
+	catalog  *productService.Service
+	budgets  *budgetRepo.Repo
+	shopMaps *shopMapRepo.ShopMapRepo
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "category.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	categories, err := repo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	budgets, err := budgetRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	shopMaps, err := shopMapRepo.NewShopMapRepo(ctx, sqlDB)
+	require.NoError(t, err)
+
+	catalog := productService.NewService(products)
+
+	return fixture{
+		db:       db,
+		service:  service.NewService(categories, catalog, budgets, shopMaps, zerolog.Nop()),
+		catalog:  catalog,
+		budgets:  budgets,
+		shopMaps: shopMaps,
+	}
+}
+
+func (f fixture) addCategory(t *testing.T, name product.Category) category.Category {
+	t.Helper()
+
+	model, err := f.service.Create(context.Background(), category.Options{
+		Name:     name,
+		ParentID: mo.None[id.ID[category.Category]](),
+		Names:    map[string]product.Category{},
+		Aliases:  []product.Category{},
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+func (f fixture) addBudget(t *testing.T, limits ...budget.CategoryLimit) budget.Budget {
+	t.Helper()
+
+	model := budget.Budget{
+		Options: budget.Options{
+			Title:          "groceries",
+			Period:         budget.PeriodWeek,
+			Currency:       "EUR",
+			Limit:          decimal.RequireFromString("100"),
+			CategoryLimits: limits,
+			Members:        []budget.MemberOptions{},
+		},
+		ID:        id.NewID[budget.Budget](),
+		OwnerID:   id.NewID[user.User](),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, f.budgets.Create(context.Background(), model))
+
+	return model
+}
+
+// addShopMap creates shop map with the categories, they're inserted directly as the repo uses LOAD DATA of MySQL
+func (f fixture) addShopMap(t *testing.T, categories ...string) shopmap.ShopMap {
+	t.Helper()
+
+	model := shopmap.ShopMap{
+		Options: shopmap.Options{
+			CategoryList: []product.Category{},
+			ViewerIDList: []id.ID[user.User]{},
+			Title:        "corner",
+		},
+		ID:        id.NewID[shopmap.ShopMap](),
+		OwnerID:   id.NewID[user.User](),
+		CreatedAt: date.NewCreateDate[shopmap.ShopMap](),
+		UpdatedAt: date.NewUpdateDate[shopmap.ShopMap](),
+	}
+	require.NoError(t, f.shopMaps.Create(context.Background(), model))
+
+	for number, name := range categories {
+		require.NoError(t, f.db.Table("shop_map_categories").Create(&sqlgen.ShopMapCategory{
+			MapID:    model.ID.String(),
+			Number:   uint32(number), //nolint:gosec // slice index can't be negative
+			Category: name,
+		}).Error)
+	}
+
+	return model
+}
+
+func limit(name product.Category, amount string) budget.CategoryLimit {
+	return budget.CategoryLimit{Category: name, Limit: decimal.RequireFromString(amount)}
+}
+
+func (f fixture) limits(t *testing.T, budgetID id.ID[budget.Budget]) map[product.Category]string {
+	t.Helper()
+
+	model, err := f.budgets.GetByID(context.Background(), budgetID)
+	require.NoError(t, err)
+
+	limits := map[product.Category]string{}
+	for _, item := range model.CategoryLimits {
+		limits[item.Category] = item.Limit.String()
+	}
+
+	return limits
+}
+
+func (f fixture) mapCategories(t *testing.T, mapID id.ID[shopmap.ShopMap]) []product.Category {
+	t.Helper()
+
+	model, err := f.shopMaps.GetByID(context.Background(), mapID)
+	require.NoError(t, err)
+
+	return model.CategoryList
+}
+
+func TestMerge(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	dairy, milk := f.addCategory(t, "dairy"), f.addCategory(t, "milk")
+
+	kefir, err := f.catalog.Create(ctx, product.Options{
+		Name:     "kefir",
+		Category: mo.Some[product.Category]("milk"),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: []product.Barcode{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[product.Category]("milk"), kefir.Category)
+
+	both, other := f.addBudget(t, limit("milk", "5"), limit("dairy", "10")), f.addBudget(t, limit("Milk", "3"))
+	shop := f.addShopMap(t, "milk", "bakery", "dairy")
+
+	target, err := f.service.Merge(ctx, category.MergeOptions{
+		SourceIDs: []id.ID[category.Category]{milk.ID},
+		TargetID:  dairy.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []product.Category{"milk"}, target.Aliases)
+
+	require.Equal(t, map[product.Category]string{"dairy": "10"}, f.limits(t, both.ID), "limits of the target are kept")
+	require.Equal(t, map[product.Category]string{"dairy": "3"}, f.limits(t, other.ID))
+	require.Equal(t, []product.Category{"dairy", "bakery"}, f.mapCategories(t, shop.ID))
+
+	kefir, err = f.catalog.ID(ctx, kefir.ID)
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[product.Category]("dairy"), kefir.Category)
+}
+
+func TestRename(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	dairy := f.addCategory(t, "dairy")
+
+	groceries := f.addBudget(t, limit("Dairy", "10"), limit("bakery", "5"))
+	shop := f.addShopMap(t, "bakery", "dairy")
+
+	dairy.Name = "dairy products"
+	_, err := f.service.Update(ctx, dairy.ID, dairy.Options)
+	require.NoError(t, err)
+
+	require.Equal(t, map[product.Category]string{"dairy products": "10", "bakery": "5"}, f.limits(t, groceries.ID))
+	require.Equal(t, []product.Category{"bakery", "dairy products"}, f.mapCategories(t, shop.ID))
+}
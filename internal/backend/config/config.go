@@ -13,6 +13,7 @@ import (
 type Config struct {
 	Service ListenerCfg `yaml:"listener"`
 	Auth    AuthCfg     `yaml:"auth"`
+	Trash   TrashCfg    `yaml:"trash"`
 }
 
 type ListenerCfg struct {
@@ -26,9 +27,15 @@ type AuthCfg struct {
 	AccessTokenLiveTime  time.Duration `yaml:"access_token_livetime"`
 }
 
+// TrashCfg configures how long deleted entities can be restored
+type TrashCfg struct {
+	Retention time.Duration `yaml:"retention"`
+}
+
 type Env struct {
 	Database DatabaseEnv
 	Auth     AuthEnv
+	Blob     BlobEnv
 }
 
 type AuthEnv struct {
@@ -39,6 +46,13 @@ type DatabaseEnv struct {
 	Path string `env:"DB_PATH"`
 }
 
+// BlobEnv configures storage of attachments.
+// Store is "file" to keep them in Dir of a single replica or "db" to keep them in the database shared by replicas.
+type BlobEnv struct {
+	Store string `env:"BLOB_STORE, default=file"`
+	Dir   string `env:"BLOB_DIR, default=/var/lib/shoplanner/blobs"`
+}
+
 func ParseConfig(path string) (Config, error) {
 	var cfg Config
 
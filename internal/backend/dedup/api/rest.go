@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/dedup/service"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	log = log.With().Str("component", "product dedup rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	group := r.Group("/admin/products", api.NewRoleMiddleware(user.RoleAdmin))
+	group.GET("/duplicates", h.Duplicates)
+	group.POST("/merge", h.Merge)
+}
+
+// @Summary get groups of products which look like the same one, only for admins
+// @ID products-duplicates
+// @Tags Products dedup
+// @Param offset query int false "number of skipped groups"
+// @Param limit query int false "page size, 20 by default and 100 at most"
+// @Produce json
+// @Router /admin/products/duplicates [get]
+// @Security ApiKeyAuth
+func (h *Handler) Duplicates(ctx *gin.Context) {
+	offset, ok := rerr.QueryInt(ctx, "offset", 0)
+	if !ok {
+		return
+	}
+
+	limit, ok := rerr.QueryInt(ctx, "limit", 0)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.Duplicates(ctx, offset, limit)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// @Summary merge products into the target one which replaces them everywhere, only for admins
+// @ID products-merge
+// @Tags Products dedup
+// @Param body body dedup.MergeOptions true "merged products and the target one"
+// @Produce json
+// @Accept json
+// @Router /admin/products/merge [post]
+// @Security ApiKeyAuth
+func (h *Handler) Merge(ctx *gin.Context) {
+	var options dedup.MergeOptions
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.Merge(ctx, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
@@ -0,0 +1,244 @@
+// Package dedup finds products which look like the same one and merges them
+package dedup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// Group is a set of products with the same normalized name or alias in the same category,
+// at most one of them has barcodes. The oldest product goes first.
+type Group struct {
+	// Key is a normalized name shared by products
+	Key      string                      `json:"key"`
+	Category mo.Option[product.Category] `json:"category" swaggertype:"string" extensions:"x-nullable"`
+	Products []product.Product           `json:"products"`
+}
+
+// Page is a page of duplicate groups sorted by keys
+type Page struct {
+	Groups []Group `json:"groups"`
+	Total  int     `json:"total"`
+}
+
+// MergeOptions are products replaced by the target one everywhere
+type MergeOptions struct {
+	SourceIDs []id.ID[product.Product] `json:"source_ids" swaggertype:"array,string" validate:"required,min=1,unique"`
+	TargetID  id.ID[product.Product]   `json:"target_id" swaggertype:"string"`
+}
+
+// Merge is a plan of merging source products into the target one
+type Merge struct {
+	// Target is the surviving product with names, forms and barcodes of sources
+	Target    product.Product
+	SourceIDs []id.ID[product.Product]
+	// Forms are indexes of forms of the target by indexes of forms of sources
+	Forms map[id.ID[product.Product]][]int32
+}
+
+// FindDuplicates groups products with equal normalized names or aliases and categories.
+// Products which have barcodes are different goods, so a group gets at most one of them.
+func FindDuplicates(products []product.Product) []Group {
+	products = slices.Clone(products)
+	slices.SortStableFunc(products, func(a, b product.Product) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt.Time); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+
+	sets := newDisjointSets(len(products))
+	first := map[string]int{}
+
+	for i, model := range products {
+		category := NormalizeCategory(model.Category)
+		sets.barcoded[i] = len(model.Barcodes) != 0
+
+		for _, key := range keys(model) {
+			key = key + "\x00" + category
+			if j, found := first[key]; found {
+				sets.union(j, i)
+			} else {
+				first[key] = i
+			}
+		}
+	}
+
+	members := map[int][]product.Product{}
+	for i, model := range products {
+		members[sets.find(i)] = append(members[sets.find(i)], model)
+	}
+
+	groups := []Group{}
+
+	for root, models := range members {
+		if len(models) < 2 {
+			continue
+		}
+
+		groups = append(groups, Group{
+			Key:      product.NormalizeName(products[root].Name),
+			Category: products[root].Category,
+			Products: models,
+		})
+	}
+
+	slices.SortFunc(groups, func(a, b Group) int {
+		if c := strings.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Products[0].ID.String(), b.Products[0].ID.String())
+	})
+
+	return groups
+}
+
+// NormalizeCategory returns a key of category, products without category have empty key
+func NormalizeCategory(category mo.Option[product.Category]) string {
+	return product.NormalizeName(product.Name(category.OrEmpty()))
+}
+
+// NewMerge returns a plan of merging sources into the target. The target keeps its name and category,
+// names and aliases of sources become its aliases, their forms and barcodes are appended to its ones.
+func NewMerge(target product.Product, sources []product.Product) (Merge, error) {
+	merge := Merge{
+		Target:    target,
+		SourceIDs: make([]id.ID[product.Product], 0, len(sources)),
+		Forms:     make(map[id.ID[product.Product]][]int32, len(sources)),
+	}
+
+	merged := &merge.Target
+	merged.Forms = slices.Clone(merged.Forms)
+	merged.Aliases = slices.Clone(merged.Aliases)
+	merged.Barcodes = slices.Clone(merged.Barcodes)
+
+	for _, source := range sources {
+		if source.ID == target.ID {
+			return Merge{}, fmt.Errorf("%w: product %s can't be merged into itself",
+				myerr.ErrInvalidArgument, target.ID)
+		}
+
+		merge.SourceIDs = append(merge.SourceIDs, source.ID)
+
+		if merged.Category.IsAbsent() {
+			merged.Category = source.Category
+		}
+
+		for _, name := range append([]product.Name{source.Name}, source.Aliases...) {
+			if !slices.Contains(keys(*merged), product.NormalizeName(name)) {
+				merged.Aliases = append(merged.Aliases, name)
+			}
+		}
+
+		forms := make([]int32, 0, len(source.Forms))
+
+		for _, form := range source.Forms {
+			idx := slices.Index(merged.Forms, form)
+			if idx == -1 {
+				idx = len(merged.Forms)
+				merged.Forms = append(merged.Forms, form)
+			}
+
+			forms = append(forms, int32(idx)) //nolint:gosec
+		}
+
+		merge.Forms[source.ID] = forms
+
+		for _, barcode := range source.Barcodes {
+			if !slices.Contains(merged.Barcodes, barcode) {
+				merged.Barcodes = append(merged.Barcodes, barcode)
+			}
+		}
+	}
+
+	return merge, nil
+}
+
+// FormIdx returns index of form of the target which replaces the form of the product
+func (m Merge) FormIdx(productID id.ID[product.Product], formIdx mo.Option[int32]) mo.Option[int32] {
+	idx, found := formIdx.Get()
+	if !found || productID == m.Target.ID {
+		return formIdx
+	}
+
+	forms := m.Forms[productID]
+	if idx < 0 || int(idx) >= len(forms) {
+		return mo.None[int32]()
+	}
+
+	return mo.Some(forms[idx])
+}
+
+// SumQuantities returns sum of quantities of merged items, the first quantity is kept if units are incompatible
+func SumQuantities(a, b mo.Option[product.Quantity]) mo.Option[product.Quantity] {
+	aQuantity, aFound := a.Get()
+	bQuantity, bFound := b.Get()
+
+	if !aFound || !bFound {
+		return lo.Ternary(aFound, a, b)
+	}
+
+	sum, err := aQuantity.Add(bQuantity)
+	if err != nil {
+		return a
+	}
+
+	return mo.Some(sum)
+}
+
+// keys returns normalized name and aliases of the product
+func keys(model product.Product) []string {
+	return lo.Uniq(append(
+		[]string{product.NormalizeName(model.Name)},
+		lo.Map(model.Aliases, func(item product.Name, _ int) string { return product.NormalizeName(item) })...,
+	))
+}
+
+// disjointSets are groups of products, groups which both have barcoded products aren't joined
+type disjointSets struct {
+	parents  []int
+	barcoded []bool
+}
+
+func newDisjointSets(size int) disjointSets {
+	parents := make([]int, size)
+	for i := range parents {
+		parents[i] = i
+	}
+
+	return disjointSets{parents: parents, barcoded: make([]bool, size)}
+}
+
+func (s disjointSets) find(i int) int {
+	for s.parents[i] != i {
+		s.parents[i] = s.parents[s.parents[i]]
+		i = s.parents[i]
+	}
+
+	return i
+}
+
+// union joins group of j into group of i, the oldest product stays the root
+func (s disjointSets) union(i, j int) {
+	i, j = s.find(i), s.find(j)
+	if i == j || (s.barcoded[i] && s.barcoded[j]) {
+		return
+	}
+
+	if j < i {
+		i, j = j, i
+	}
+
+	s.parents[j] = i
+	s.barcoded[i] = s.barcoded[i] || s.barcoded[j]
+}
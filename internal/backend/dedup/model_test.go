@@ -0,0 +1,107 @@
+package dedup_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newProduct := func(name string, category string, age int) product.Product {
+		options := product.NewZeroOptions()
+		options.Name = product.Name(name)
+		options.Category = mo.EmptyableToOption(product.Category(category))
+
+		return product.Product{
+			Options:   options,
+			ID:        id.NewID[product.Product](),
+			CreatedAt: date.CreateDate[product.Product]{Time: start.Add(time.Duration(age) * time.Hour)},
+			UpdatedAt: date.UpdateDate[product.Product]{Time: start},
+		}
+	}
+
+	milk := newProduct("Milk 3.2%", "Dairy", 0)
+	milkCopy := newProduct("milk 3,2 %", " dairy", 1)
+	moloko := newProduct("Молоко", "Dairy", 2)
+	moloko.Aliases = []product.Name{"MILK 3.2"}
+	otherMilk := newProduct("Milk 3.2%", "Drinks", 3)
+
+	// products with different barcodes are different goods even with the same name
+	bread := newProduct("Bread", "", 4)
+	bread.Barcodes = []product.Barcode{"4006381333931"}
+	otherBread := newProduct("bread", "", 5)
+	otherBread.Barcodes = []product.Barcode{"96385074"}
+	plainBread := newProduct("Bread!", "", 6)
+
+	groups := dedup.FindDuplicates([]product.Product{plainBread, otherBread, bread, otherMilk, moloko, milkCopy, milk})
+	require.Len(t, groups, 2)
+
+	require.Equal(t, "bread", groups[0].Key)
+	require.Equal(t, []product.Product{bread, plainBread}, groups[0].Products)
+
+	require.Equal(t, "milk 3 2", groups[1].Key)
+	require.Equal(t, mo.Some[product.Category]("Dairy"), groups[1].Category)
+	require.Equal(t, []product.Product{milk, milkCopy, moloko}, groups[1].Products)
+}
+
+func TestNewMerge(t *testing.T) {
+	target := product.Product{
+		Options: product.Options{
+			Name:     "Milk 3.2%",
+			Category: mo.None[product.Category](),
+			Forms:    []product.Form{"bottle"},
+			Aliases:  []product.Name{},
+			Barcodes: []product.Barcode{"4006381333931"},
+		},
+		ID:        id.NewID[product.Product](),
+		CreatedAt: date.NewCreateDate[product.Product](),
+		UpdatedAt: date.NewUpdateDate[product.Product](),
+	}
+	source := product.Product{
+		Options: product.Options{
+			Name:     "milk 3,2 %",
+			Category: mo.Some[product.Category]("Dairy"),
+			Forms:    []product.Form{"pack", "bottle"},
+			Aliases:  []product.Name{"Молоко"},
+			Barcodes: []product.Barcode{"96385074"},
+		},
+		ID:        id.NewID[product.Product](),
+		CreatedAt: date.NewCreateDate[product.Product](),
+		UpdatedAt: date.NewUpdateDate[product.Product](),
+	}
+
+	merge, err := dedup.NewMerge(target, []product.Product{source})
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[product.Product]{source.ID}, merge.SourceIDs)
+	require.Equal(t, product.Name("Milk 3.2%"), merge.Target.Name)
+	require.Equal(t, mo.Some[product.Category]("Dairy"), merge.Target.Category)
+	require.Equal(t, []product.Name{"Молоко"}, merge.Target.Aliases)
+	require.Equal(t, []product.Form{"bottle", "pack"}, merge.Target.Forms)
+	require.Equal(t, []product.Barcode{"4006381333931", "96385074"}, merge.Target.Barcodes)
+	require.Equal(t, []product.Form{"bottle"}, target.Forms)
+
+	require.Equal(t, mo.Some[int32](1), merge.FormIdx(source.ID, mo.Some[int32](0)))
+	require.Equal(t, mo.Some[int32](0), merge.FormIdx(source.ID, mo.Some[int32](1)))
+	require.Equal(t, mo.None[int32](), merge.FormIdx(source.ID, mo.Some[int32](2)))
+	require.Equal(t, mo.Some[int32](0), merge.FormIdx(target.ID, mo.Some[int32](0)))
+
+	_, err = dedup.NewMerge(target, []product.Product{target})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	sum := dedup.SumQuantities(mo.Some(product.Quantity{Value: 1, Unit: product.UnitL}),
+		mo.Some(product.Quantity{Value: 500, Unit: product.UnitMl}))
+	require.InDelta(t, 1.5, sum.MustGet().Value, 1e-9)
+	require.Equal(t, product.UnitL, sum.MustGet().Unit)
+
+	pieces := mo.Some(product.NewPieces(2))
+	require.Equal(t, pieces, dedup.SumQuantities(pieces, mo.Some(product.Quantity{Value: 1, Unit: product.UnitL})))
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/id"
+	"go-backend/pkg/mymysql"
+)
+
+// products replace sources by the target, see productRepo.GormRepo.Merge
+type products interface {
+	Merge(context.Context, dedup.Merge) (product.Product, error)
+}
+
+// lists rewrite states, templates, purchase histories and undo records, and save revisions of lists
+// whose products are merged, see listRepo.Repo.MergeProducts and listRepo.Repo.Commit
+type lists interface {
+	MergeProducts(context.Context, dedup.Merge) ([]id.ID[list.ProductList], []id.ID[list.ProductList], error)
+	Commit(context.Context, []id.ID[list.ProductList], list.ChangeMeta) error
+}
+
+// references are favorites, pantries and recipes, they refer products and are rewritten
+// in the transaction of merge
+type references interface {
+	MergeProducts(context.Context, dedup.Merge) error
+}
+
+// Repo merges products in one transaction, tables are rewritten by repos which own them
+type Repo struct {
+	db         *gorm.DB
+	products   products
+	lists      lists
+	references []references
+}
+
+func NewRepo(db *gorm.DB, products products, lists lists, references ...references) *Repo {
+	return &Repo{db: db, products: products, lists: lists, references: references}
+}
+
+// Merge replaces sources by the target product in lists, templates, favorites, pantries, recipes and purchase
+// histories in one transaction. Items of merged products in the same list, template and so on are merged into one.
+// Sources are deleted, and lists with them get new revisions by meta.
+// It returns the saved target and active lists which had sources.
+func (r *Repo) Merge(
+	ctx context.Context,
+	merge dedup.Merge,
+	meta list.ChangeMeta,
+) (
+	product.Product,
+	[]id.ID[list.ProductList],
+	error,
+) {
+	var target product.Product
+
+	var active []id.ID[list.ProductList]
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, _ *gorm.DB) error {
+		var listIDs []id.ID[list.ProductList]
+
+		var err error
+		if listIDs, active, err = r.lists.MergeProducts(ctx, merge); err != nil {
+			return err
+		}
+
+		for _, references := range r.references {
+			if err = references.MergeProducts(ctx, merge); err != nil {
+				return err
+			}
+		}
+
+		if target, err = r.products.Merge(ctx, merge); err != nil {
+			return err
+		}
+
+		return r.lists.Commit(ctx, listIDs, meta)
+	})
+	if err != nil {
+		return product.Product{}, nil, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return target, active, nil
+}
@@ -0,0 +1,240 @@
+package repo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/dedup/repo"
+	favoriteRepo "go-backend/internal/backend/favorite/repo"
+	"go-backend/internal/backend/list"
+	listRepo "go-backend/internal/backend/list/repo"
+	pantryRepo "go-backend/internal/backend/pantry/repo"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	recipeRepo "go-backend/internal/backend/recipe/repo"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+// fixture is a dedup repo over sqlite with tables of every repo referring products
+type fixture struct {
+	repo     *repo.Repo
+	lists    *listRepo.Repo
+	products *productRepo.GormRepo
+	ownerID  id.ID[user.User]
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dedup.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	lists, err := listRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	favorites, err := favoriteRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	pantries, err := pantryRepo.NewRepo(ctx, db, time.Now())
+	require.NoError(t, err)
+
+	recipes, err := recipeRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	ownerID := id.NewID[user.User]()
+	require.NoError(t, db.Create(&userRepo.User{
+		ID:    ownerID.String(),
+		Login: "owner",
+		Hash:  "",
+		Role:  int32(user.RoleUser),
+	}).Error)
+
+	return fixture{
+		repo:     repo.NewRepo(db, products, lists, favorites, pantries, recipes),
+		lists:    lists,
+		products: products,
+		ownerID:  ownerID,
+	}
+}
+
+func (f fixture) addProduct(t *testing.T, name product.Name) product.Product {
+	t.Helper()
+
+	model, err := f.products.Create(context.Background(), product.Product{
+		Options: product.Options{
+			Name:     name,
+			Category: mo.None[product.Category](),
+			Forms:    []product.Form{},
+			Aliases:  []product.Name{},
+			Barcodes: []product.Barcode{},
+		},
+		ID:        id.NewID[product.Product](),
+		CreatedAt: date.NewCreateDate[product.Product](),
+		UpdatedAt: date.NewUpdateDate[product.Product](),
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+// addList creates list of the owner with the product, it was updated a day ago
+func (f fixture) addList(t *testing.T, status list.ExecStatus, p product.Product) list.ProductList {
+	t.Helper()
+
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	model := list.ProductList{
+		ListOptions: list.ListOptions{Status: status, Title: "weekly"},
+		States: []list.ProductState{{
+			ProductStateOptions: list.ProductStateOptions{
+				Quantity: mo.Some(product.NewPieces(1)),
+				Status:   list.StateStatusWaiting,
+			},
+			Product:   p,
+			CreatedAt: date.NewCreateDate[list.ProductState](),
+			UpdatedAt: date.NewUpdateDate[list.ProductState](),
+		}},
+		Members: []list.Member{{
+			MemberOptions: list.MemberOptions{UserID: f.ownerID, Role: list.MemberTypeOwner},
+			UserName:      "",
+			CreatedAt:     date.NewCreateDate[list.Member](),
+			UpdatedAt:     date.NewUpdateDate[list.Member](),
+		}},
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.UpdateDate[list.ProductList]{Time: dayAgo},
+		CreatedAt: date.CreateDate[list.ProductList]{Time: dayAgo},
+	}
+	require.NoError(t, f.lists.CreateList(context.Background(), model, list.ChangeMeta{
+		Author: f.ownerID,
+		Type:   list.EventTypeFull,
+		At:     dayAgo,
+	}))
+
+	return model
+}
+
+func TestMergeCommitsLists(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, source := f.addProduct(t, "milk"), f.addProduct(t, "Milk")
+
+	planning := f.addList(t, list.ExecStatusPlanning, source)
+	archived := f.addList(t, list.ExecStatusArchived, source)
+	untouched := f.addList(t, list.ExecStatusPlanning, milk)
+
+	merge, err := dedup.NewMerge(milk, []product.Product{source})
+	require.NoError(t, err)
+
+	adminID := id.NewID[user.User]()
+	meta := list.ChangeMeta{Author: adminID, Type: list.EventTypeFull, At: time.Now()}
+	_, active, err := f.repo.Merge(ctx, merge, meta)
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[list.ProductList]{planning.ID}, active)
+
+	for _, model := range []list.ProductList{planning, archived} {
+		saved, err := f.lists.GetByListID(ctx, model.ID)
+		require.NoError(t, err)
+		require.Equal(t, milk.ID, saved.States[0].Product.ID)
+		require.True(t, saved.UpdatedAt.After(model.UpdatedAt.Time), "lists with merged products are updated")
+
+		history, err := f.lists.GetHistory(ctx, model.ID, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		require.Equal(t, adminID, history[0].Author)
+	}
+
+	history, err := f.lists.GetHistory(ctx, untouched.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1, "lists without sources aren't committed")
+}
+
+func TestMergeRewritesUndoRecords(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, source := f.addProduct(t, "milk"), f.addProduct(t, "Milk")
+
+	model := f.addList(t, list.ExecStatusPlanning, source)
+	meta := list.ChangeMeta{Author: f.ownerID, Type: list.EventTypeStateUpdated, At: time.Now().Add(-time.Hour)}
+
+	// the first change adds the target, the second one changes quantity of the source
+	_, err := f.lists.GetAndUpdate(ctx, model.ID, meta, func(model list.ProductList) (list.ProductList, error) {
+		state := model.States[0]
+		state.Product = milk
+		state.Quantity = mo.Some(product.NewPieces(2))
+		model.States = append(model.States, state)
+
+		return model, nil
+	})
+	require.NoError(t, err)
+
+	meta.At = meta.At.Add(time.Minute)
+	_, err = f.lists.GetAndUpdate(ctx, model.ID, meta, func(model list.ProductList) (list.ProductList, error) {
+		model.States[0].Quantity = mo.Some(product.NewPieces(3))
+		return model, nil
+	})
+	require.NoError(t, err)
+
+	merge, err := dedup.NewMerge(milk, []product.Product{source})
+	require.NoError(t, err)
+
+	adminMeta := list.ChangeMeta{Author: id.NewID[user.User](), Type: list.EventTypeFull, At: time.Now()}
+	_, _, err = f.repo.Merge(ctx, merge, adminMeta)
+	require.NoError(t, err)
+
+	saved, err := f.lists.GetByListID(ctx, model.ID)
+	require.NoError(t, err)
+	require.Len(t, saved.States, 1)
+	require.Equal(t, milk.ID, saved.States[0].Product.ID)
+	require.Equal(t, mo.Some(product.NewPieces(5)), saved.States[0].Quantity)
+
+	var records []list.UndoRecord
+
+	meta.At = time.Now()
+	_, err = f.lists.GetAndUndo(ctx, model.ID, meta, 2, func(
+		model list.ProductList,
+		undone []list.UndoRecord,
+	) (
+		list.ProductList,
+		error,
+	) {
+		records = undone
+		return model, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 2, "undo records of lists with merged products are kept")
+
+	for _, record := range records {
+		for _, snapshot := range []list.ProductList{record.Before, record.After} {
+			require.Equal(t, []id.ID[product.Product]{milk.ID}, productIDs(snapshot))
+		}
+	}
+
+	// the quantity of the source is restored in the state of the target
+	require.Equal(t, mo.Some(product.NewPieces(1)), records[0].Before.States[0].Quantity)
+}
+
+func productIDs(model list.ProductList) []id.ID[product.Product] {
+	ids := make([]id.ID[product.Product], 0, len(model.States))
+	for _, state := range model.States {
+		ids = append(ids, state.Product.ID)
+	}
+
+	return ids
+}
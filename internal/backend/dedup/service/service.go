@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+type repo interface {
+	Merge(context.Context, dedup.Merge, list.ChangeMeta) (product.Product, []id.ID[list.ProductList], error)
+}
+
+type products interface {
+	GetAll(context.Context) ([]product.Product, error)
+	GetByListID(context.Context, []id.ID[product.Product]) ([]product.Product, error)
+}
+
+// index is the product search index, merged products are removed from it
+type index interface {
+	Reindex(product.Product, []id.ID[product.Product])
+}
+
+// lists get events about lists whose products are merged
+type lists interface {
+	Refresh(context.Context, []id.ID[list.ProductList]) error
+}
+
+const (
+	// defaultLimit and maxLimit are default and maximal sizes of pages of duplicate groups
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Service struct {
+	repo     repo
+	products products
+	index    index
+	lists    lists
+	clock    clock.Clock
+	log      zerolog.Logger
+	// lock serializes merges, products of a merge must not be merged concurrently
+	lock sync.Mutex
+}
+
+func NewService(
+	repo repo,
+	products products,
+	index index,
+	lists lists,
+	clock clock.Clock,
+	log zerolog.Logger,
+) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		index:    index,
+		lists:    lists,
+		clock:    clock,
+		log:      log.With().Str("component", "product dedup service").Logger(),
+		lock:     sync.Mutex{},
+	}
+}
+
+// Duplicates returns a page of groups of products which look like the same one, zero limit means the default one
+func (s *Service) Duplicates(ctx context.Context, offset, limit int) (dedup.Page, error) {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	if offset < 0 || limit < 0 || limit > maxLimit {
+		return dedup.Page{}, fmt.Errorf(
+			"%w: offset must not be negative and limit must be between 1 and %d",
+			myerr.ErrInvalidArgument,
+			maxLimit,
+		)
+	}
+
+	models, err := s.products.GetAll(ctx)
+	if err != nil {
+		return dedup.Page{}, fmt.Errorf("can't get products: %w", err)
+	}
+
+	groups := dedup.FindDuplicates(models)
+
+	return dedup.Page{
+		Groups: groups[min(offset, len(groups)):min(offset+limit, len(groups))],
+		Total:  len(groups),
+	}, nil
+}
+
+// Merge replaces source products by the target one everywhere and deletes them, lists which had sources
+// get revisions by the user and active ones get full updates
+func (s *Service) Merge(
+	ctx context.Context,
+	userID id.ID[user.User],
+	options dedup.MergeOptions,
+) (
+	product.Product,
+	error,
+) {
+	if err := validator.New().Struct(options); err != nil {
+		return product.Product{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	if slices.Contains(options.SourceIDs, options.TargetID) {
+		return product.Product{}, fmt.Errorf("%w: product %s can't be merged into itself",
+			myerr.ErrInvalidArgument, options.TargetID)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	models, err := s.products.GetByListID(ctx, append([]id.ID[product.Product]{options.TargetID}, options.SourceIDs...))
+	if err != nil {
+		return product.Product{}, fmt.Errorf("can't get merged products: %w", err)
+	}
+
+	targetIdx := slices.IndexFunc(models, func(item product.Product) bool { return item.ID == options.TargetID })
+	if targetIdx == -1 || len(models) != len(options.SourceIDs)+1 {
+		return product.Product{}, fmt.Errorf("%w: some of merged products don't exist", myerr.ErrNotFound)
+	}
+
+	merge, err := dedup.NewMerge(models[targetIdx], slices.Delete(slices.Clone(models), targetIdx, targetIdx+1))
+	if err != nil {
+		return product.Product{}, err
+	}
+
+	target, listIDs, err := s.repo.Merge(ctx, merge, list.ChangeMeta{
+		Author: userID,
+		Type:   list.EventTypeFull,
+		At:     s.clock.Now(),
+	})
+	if err != nil {
+		return product.Product{}, fmt.Errorf("can't merge products into %s: %w", options.TargetID, err)
+	}
+
+	s.index.Reindex(target, merge.SourceIDs)
+
+	if err = s.lists.Refresh(ctx, listIDs); err != nil {
+		s.log.Err(err).Msg("can't send updates of lists with merged products")
+	}
+
+	return target, nil
+}
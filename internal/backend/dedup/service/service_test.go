@@ -0,0 +1,310 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/dedup/repo"
+	"go-backend/internal/backend/dedup/service"
+	favoriteRepo "go-backend/internal/backend/favorite/repo"
+	"go-backend/internal/backend/list"
+	listRepo "go-backend/internal/backend/list/repo"
+	listService "go-backend/internal/backend/list/service"
+	pantryRepo "go-backend/internal/backend/pantry/repo"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	recipeRepo "go-backend/internal/backend/recipe/repo"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/blob"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// fixture is a dedup service over sqlite, events of merged lists are sent by the real list service
+type fixture struct {
+	db      *gorm.DB
+	service *service.Service
+	catalog *productService.Service
+	lists   *listService.Service
+	repo    *listRepo.Repo
+	clock   *clock.Fake
+	ownerID id.ID[user.User]
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dedup.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	lists, err := listRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	favorites, err := favoriteRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	pantries, err := pantryRepo.NewRepo(ctx, db, time.Now())
+	require.NoError(t, err)
+
+	recipes, err := recipeRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	ownerID := id.NewID[user.User]()
+	require.NoError(t, db.Create(&userRepo.User{
+		ID:    ownerID.String(),
+		Login: "owner",
+		Hash:  "",
+		Role:  int32(user.RoleUser),
+	}).Error)
+
+	now := clock.NewFake(time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC))
+	catalog := productService.NewService(products)
+	listsService := listService.NewService(lists, catalog, shopMaps{}, budgets{}, blobs, now, zerolog.Nop())
+
+	return fixture{
+		db: db,
+		service: service.NewService(
+			repo.NewRepo(db, products, lists, favorites, pantries, recipes),
+			products,
+			catalog,
+			listsService,
+			now,
+			zerolog.Nop(),
+		),
+		catalog: catalog,
+		lists:   listsService,
+		repo:    lists,
+		clock:   now,
+		ownerID: ownerID,
+	}
+}
+
+// addProduct creates product in the category, products get only categories which exist
+func (f fixture) addProduct(
+	t *testing.T,
+	name product.Name,
+	category product.Category,
+	barcodes ...product.Barcode,
+) product.Product {
+	t.Helper()
+
+	if category != "" {
+		require.NoError(t, f.db.FirstOrCreate(&productRepo.ProductCategory{
+			ID:             uuid.NewString(),
+			Name:           string(category),
+			NormalizedName: product.NormalizeName(product.Name(category)),
+			ParentID:       sql.NullString{String: "", Valid: false},
+			Names:          []productRepo.ProductCategoryName{},
+		}, "name = ?", category).Error)
+	}
+
+	model, err := f.catalog.Create(context.Background(), product.Options{
+		Name:     name,
+		Category: mo.EmptyableToOption(category),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: append([]product.Barcode{}, barcodes...),
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+// addList creates list of the owner with waiting states of the products
+func (f fixture) addList(t *testing.T, status list.ExecStatus, products ...product.Product) list.ProductList {
+	t.Helper()
+
+	model := list.ProductList{
+		ListOptions: list.ListOptions{Status: status, Title: "weekly"},
+		States: lo.Map(products, func(item product.Product, _ int) list.ProductState {
+			return list.ProductState{
+				ProductStateOptions: list.ProductStateOptions{
+					Quantity: mo.Some(product.NewPieces(1)),
+					Status:   list.StateStatusWaiting,
+				},
+				Product:   item,
+				CreatedAt: date.CreateDate[list.ProductState]{Time: f.clock.Now()},
+				UpdatedAt: date.UpdateDate[list.ProductState]{Time: f.clock.Now()},
+			}
+		}),
+		Members: []list.Member{{
+			MemberOptions: list.MemberOptions{UserID: f.ownerID, Role: list.MemberTypeOwner},
+			UserName:      "",
+			CreatedAt:     date.CreateDate[list.Member]{Time: f.clock.Now()},
+			UpdatedAt:     date.UpdateDate[list.Member]{Time: f.clock.Now()},
+		}},
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.UpdateDate[list.ProductList]{Time: f.clock.Now()},
+		CreatedAt: date.CreateDate[list.ProductList]{Time: f.clock.Now()},
+	}
+	require.NoError(t, f.repo.CreateList(context.Background(), model, list.ChangeMeta{
+		Author: f.ownerID,
+		Type:   list.EventTypeFull,
+		At:     f.clock.Now(),
+	}))
+
+	return model
+}
+
+// listen returns events of the list sent to the owner, the first full update is skipped
+func (f fixture) listen(t *testing.T, listID id.ID[list.ProductList]) <-chan list.Event {
+	t.Helper()
+
+	events, err := f.lists.ListenEvents(context.Background(), f.ownerID, listID)
+	require.NoError(t, err)
+	<-events
+
+	t.Cleanup(func() { require.NoError(t, f.lists.StopListenEvents(f.ownerID, listID)) })
+
+	return events
+}
+
+func names(group dedup.Group) []product.Name {
+	return lo.Map(group.Products, func(item product.Product, _ int) product.Name { return item.Name })
+}
+
+func TestDuplicates(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	f.addProduct(t, "Milk 3.2%", "dairy")
+	f.addProduct(t, "bread", "bakery")
+	f.addProduct(t, "milk 3,2 %", "dairy")
+	// the same name in other category is another product
+	f.addProduct(t, "milk 3.2 %", "cocktails")
+	// products with barcodes are different goods, the one without barcodes joins the first of them
+	f.addProduct(t, "Kefir", "", "4006381333931")
+	f.addProduct(t, "kefir", "", "5901234123457")
+	f.addProduct(t, "KEFIR", "")
+
+	page, err := f.service.Duplicates(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Len(t, page.Groups, 2)
+
+	require.Equal(t, "kefir", page.Groups[0].Key)
+	require.Equal(t, mo.None[product.Category](), page.Groups[0].Category)
+	require.Equal(t, []product.Name{"Kefir", "KEFIR"}, names(page.Groups[0]))
+
+	require.Equal(t, "milk 3 2", page.Groups[1].Key)
+	require.Equal(t, mo.Some[product.Category]("dairy"), page.Groups[1].Category)
+	require.Equal(t, []product.Name{"Milk 3.2%", "milk 3,2 %"}, names(page.Groups[1]))
+
+	page, err = f.service.Duplicates(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Len(t, page.Groups, 1)
+	require.Equal(t, "milk 3 2", page.Groups[0].Key)
+
+	page, err = f.service.Duplicates(ctx, 5, 1)
+	require.NoError(t, err)
+	require.Empty(t, page.Groups)
+
+	_, err = f.service.Duplicates(ctx, 0, 101)
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestMergeSendsEvents(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, source := f.addProduct(t, "milk", "dairy"), f.addProduct(t, "Milk", "dairy")
+	bread := f.addProduct(t, "bread", "")
+
+	planning := f.addList(t, list.ExecStatusPlanning, source, bread)
+	archived := f.addList(t, list.ExecStatusArchived, source)
+	untouched := f.addList(t, list.ExecStatusPlanning, milk)
+
+	planningEvents, archivedEvents := f.listen(t, planning.ID), f.listen(t, archived.ID)
+	untouchedEvents := f.listen(t, untouched.ID)
+
+	require.NoError(t, f.catalog.LoadIndex(ctx))
+
+	adminID := id.NewID[user.User]()
+	target, err := f.service.Merge(ctx, adminID, dedup.MergeOptions{
+		SourceIDs: []id.ID[product.Product]{source.ID},
+		TargetID:  milk.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, milk.ID, target.ID)
+
+	// only active lists which had sources get full updates
+	event := <-planningEvents
+	require.Equal(t, planning.ID, event.ListID)
+	require.Equal(t, list.EventTypeFull, event.Change.Type)
+
+	full, ok := event.Change.Data.(list.FullUpdateChange)
+	require.True(t, ok)
+	require.Equal(t, []id.ID[product.Product]{milk.ID, bread.ID}, lo.Map(full.States,
+		func(item list.ProductState, _ int) id.ID[product.Product] { return item.Product.ID },
+	))
+
+	for _, events := range []<-chan list.Event{archivedEvents, untouchedEvents} {
+		select {
+		case event := <-events:
+			require.Failf(t, "unexpected event", "list %s got event %s", event.ListID, event.Change.Type)
+		default:
+		}
+	}
+
+	// the source isn't found anymore and the target is found by its name
+	found, err := f.catalog.Search("milk", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[product.Product]{milk.ID}, lo.Map(found.Hits,
+		func(item product.SearchHit, _ int) id.ID[product.Product] { return item.Product.ID },
+	))
+
+	_, err = f.service.Merge(ctx, adminID, dedup.MergeOptions{
+		SourceIDs: []id.ID[product.Product]{source.ID},
+		TargetID:  milk.ID,
+	})
+	require.ErrorIs(t, err, myerr.ErrNotFound, "merged products are deleted")
+}
+
+// shopMaps has no shop maps, lists of the tests have no default ones
+type shopMaps struct{}
+
+func (shopMaps) GetByID(_ context.Context, mapID id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error) {
+	return shopmap.ShopMap{}, fmt.Errorf("%w: shop map %s", myerr.ErrNotFound, mapID)
+}
+
+// budgets are never exceeded
+type budgets struct{}
+
+func (budgets) CheckPurchases(
+	context.Context,
+	list.ProductList,
+	[]list.Purchase,
+) (
+	[]list.BudgetExceededChange,
+	error,
+) {
+	return nil, nil
+}
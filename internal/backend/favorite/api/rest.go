@@ -17,6 +17,8 @@ import (
 
 type ProductList struct {
 	ProductIDs []string `json:"product_ids"`
+	// Quantities are optional quantities of added products by product ids
+	Quantities map[string]product.Quantity `json:"quantities"`
 }
 
 type Handler struct {
@@ -37,6 +39,7 @@ func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logg
 	}
 
 	group.GET("/id/:id", h.GetFavoriteListByID)
+	group.DELETE("/id/:id", h.DeleteList)
 	group.GET("/user", h.GetUserLists)
 	group.DELETE("/id/:id/product", h.DeleteProductList)
 	group.POST("/id/:id/product", h.AppendProductList)
@@ -70,6 +73,28 @@ func (h *Handler) GetFavoriteListByID(c *gin.Context) {
 	c.JSON(http.StatusOK, models)
 }
 
+// @Summary	move group list of favorite products to the trash
+// @ID			delete-favorite-list
+// @Tags		Favorites
+//
+// @Param		id	path	string	true	"id of favorites list"
+// @Router		/favorite/id/{id} [delete]
+// @Security	ApiKeyAuth
+func (h *Handler) DeleteList(c *gin.Context) {
+	listID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "id must be valid uuid")
+		return
+	}
+
+	if err = h.service.DeleteList(c, id.ID[favorite.List]{UUID: listID}, api.GetUserID(c)); err != nil {
+		h.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // @Summary	add new products to list of favorties
 // @ID			add-favorite-products
 // @Tags		Favorites
@@ -106,15 +131,26 @@ func (h *Handler) AppendProductList(ctx *gin.Context) {
 		idList = append(idList, id.ID[product.Product]{UUID: parsedUUID})
 	}
 
+	quantities := make(map[id.ID[product.Product]]product.Quantity, len(req.Quantities))
+	for rawID, quantity := range req.Quantities {
+		parsedUUID, parseError := uuid.Parse(rawID)
+		if parseError != nil {
+			ctx.String(http.StatusBadRequest, "id must be valid UUID")
+			return
+		}
+
+		quantities[id.ID[product.Product]{UUID: parsedUUID}] = quantity
+	}
+
 	model, err := h.service.AddProducts(
 		ctx,
 		id.ID[favorite.List]{UUID: listUUID},
 		api.GetUserID(ctx),
 		idList,
+		quantities,
 	)
 	if err != nil {
-		h.log.Err(err).Msg("adding products")
-		ctx.String(http.StatusInternalServerError, "internal error")
+		h.HandleError(ctx, err)
 		return
 	}
 
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/samber/mo"
+
 	"go-backend/internal/backend/product"
 	"go-backend/internal/backend/user"
 	"go-backend/pkg/date"
@@ -32,9 +34,10 @@ func ErrProductNotFound(listID id.ID[List], productID id.ID[product.Product]) er
 }
 
 type Favorite struct {
-	Product   product.Product           `json:"product"`
-	CreatedAt date.CreateDate[Favorite] `json:"created_at"`
-	UpdatedAt date.UpdateDate[Favorite] `json:"updated_at"`
+	Product   product.Product             `json:"product"`
+	Quantity  mo.Option[product.Quantity] `json:"quantity" extensions:"x-nullable"`
+	CreatedAt date.CreateDate[Favorite]   `json:"created_at"`
+	UpdatedAt date.UpdateDate[Favorite]   `json:"updated_at"`
 }
 
 // ENUM(owner=1,admin,editor,viewer)
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/dedup"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/dbtx"
+)
+
+// MergeProducts replaces sources by the target in favorites lists, it joins the transaction of ctx.
+// Favorites of merged products in the same list are merged into one.
+func (r *Repo) MergeProducts(ctx context.Context, merge dedup.Merge) error {
+	m := productRepo.NewMerged(merge)
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		favorites, err := productRepo.LoadMerged[FavoriteProduct](ctx, tx, m, "favorite_list_id", "created_at")
+		if err != nil {
+			return err
+		}
+
+		kept, dropped := productRepo.CombineMerged(
+			favorites,
+			m,
+			func(item *FavoriteProduct) (string, *string) { return item.FavoriteListID, &item.ProductID },
+			func(kept, other FavoriteProduct) FavoriteProduct {
+				kept.Quantity = productRepo.SumMerged(kept.Quantity, other.Quantity)
+				return kept
+			},
+		)
+
+		return productRepo.SaveMerged(ctx, tx, kept, dropped, func(item FavoriteProduct) string { return item.ID })
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
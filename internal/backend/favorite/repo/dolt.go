@@ -29,6 +29,9 @@ type FavoriteList struct {
 	UpdatedAt time.Time         `gorm:"notNull"`
 	Members   []FavoriteMember  `gorm:"notNull"`
 	Products  []FavoriteProduct `gorm:"notNull"`
+	// DeletedAt is set when list is moved to the trash, such lists aren't selected by default
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	DeletedBy *string        `gorm:"size:36"`
 }
 
 type FavoriteMember struct {
@@ -42,12 +45,13 @@ type FavoriteMember struct {
 }
 
 type FavoriteProduct struct {
-	ID             string       `gorm:"primaryKey;size:36;notNull"`
-	ProductID      string       `gorm:"size:36;notNull;uniqueIndex:idx_unique_product"`
-	FavoriteListID string       `gorm:"size:36;notNull;uniqueIndex:idx_unique_product"`
-	Product        repo.Product `gorm:"references:ID"`
-	CreatedAt      time.Time    `gorm:"notNull"`
-	UpdatedAt      time.Time    `gorm:"notNull"`
+	ID             string        `gorm:"primaryKey;size:36;notNull"`
+	ProductID      string        `gorm:"size:36;notNull;uniqueIndex:idx_unique_product"`
+	FavoriteListID string        `gorm:"size:36;notNull;uniqueIndex:idx_unique_product"`
+	Product        repo.Product  `gorm:"references:ID"`
+	Quantity       repo.Quantity `gorm:"embedded;embeddedPrefix:quantity_"`
+	CreatedAt      time.Time     `gorm:"notNull"`
+	UpdatedAt      time.Time     `gorm:"notNull"`
 }
 
 type Repo struct {
@@ -69,14 +73,6 @@ func (r *Repo) CreateList(ctx context.Context, model favorite.List) error {
 	return nil
 }
 
-func (r *Repo) DeleteList(ctx context.Context, listID id.ID[favorite.List]) error {
-	if err := r.db.WithContext(ctx).Delete(&FavoriteList{ID: listID.String()}).Error; err != nil { // nolint:exhaustruct
-		return fmt.Errorf("can't delete favorites list %s: %w", listID, err)
-	}
-
-	return nil
-}
-
 func (r *Repo) GetByID(ctx context.Context, listID id.ID[favorite.List]) (favorite.List, error) {
 	entity := FavoriteList{ID: listID.String()} // nolint:exhaustruct
 	err := r.db.WithContext(ctx).
@@ -215,7 +211,7 @@ func (r *Repo) GetAndUpdate(
 		//nolint
 		err = tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "product_id"}, {Name: "favorite_list_id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at", "quantity_value", "quantity_unit"}),
 		}).
 			Create(entity.Products).
 			Error
@@ -268,6 +264,8 @@ func modelToEntity(model favorite.List) FavoriteList {
 		UpdatedAt: model.UpdatedAt.Time,
 		Members:   make([]FavoriteMember, 0, len(model.Members)),
 		Products:  make([]FavoriteProduct, 0, len(model.Products)),
+		DeletedAt: gorm.DeletedAt{},
+		DeletedBy: nil,
 	}
 	for _, member := range model.Members {
 		entity.Members = append(entity.Members, memberToEntity(model.ID, member))
@@ -305,6 +303,7 @@ func productToEntity(listID id.ID[favorite.List], model favorite.Favorite) Favor
 			Forms:      []repo.ProductForm{},
 		},
 		FavoriteListID: listID.String(),
+		Quantity:       repo.QuantityToEntity(model.Quantity),
 		CreatedAt:      model.CreatedAt.Time,
 		UpdatedAt:      model.UpdatedAt.Time,
 	}
@@ -333,11 +332,14 @@ func entityToProduct(entity FavoriteProduct) favorite.Favorite {
 				Forms: lo.Map(entity.Product.Forms, func(item repo.ProductForm, _ int) product.Form {
 					return product.Form(item.Name)
 				}),
+				Aliases:  []product.Name{},
+				Barcodes: []product.Barcode{},
 			},
 			ID:        id.ID[product.Product]{UUID: god.Believe(uuid.Parse(entity.ProductID))},
 			CreatedAt: date.CreateDate[product.Product]{Time: entity.Product.CreatedAt},
 			UpdatedAt: date.UpdateDate[product.Product]{Time: entity.Product.UpdatedAt},
 		},
+		Quantity:  repo.QuantityToModel(entity.Quantity, nil),
 		CreatedAt: date.CreateDate[favorite.Favorite]{Time: entity.CreatedAt},
 		UpdatedAt: date.UpdateDate[favorite.Favorite]{Time: entity.UpdatedAt},
 	}
@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/favorite"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// TrashList moves list to the trash, lists in the trash aren't returned by other methods
+func (r *Repo) TrashList(
+	ctx context.Context,
+	listID id.ID[favorite.List],
+	userID id.ID[user.User],
+	deletedAt time.Time,
+) error {
+	entity := FavoriteList{ID: listID.String()} // nolint:exhaustruct
+
+	err := r.db.WithContext(ctx).Model(&entity).
+		UpdateColumns(map[string]any{"deleted_at": deletedAt.UTC(), "deleted_by": userID.String()}).Error
+	if err != nil {
+		return fmt.Errorf("can't move favorites list %s to the trash: %w", listID, err)
+	}
+
+	return nil
+}
+
+// GetTrash returns lists of the owner moved to the trash after given time
+func (r *Repo) GetTrash(ctx context.Context, ownerID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	var entities []FavoriteList
+
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at > ? AND id IN (?)", after.UTC(), r.ownedLists(ownerID)).
+		Order("deleted_at DESC").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select trashed favorites lists of user %s: %w", ownerID, err)
+	}
+
+	return lo.Map(entities, func(entity FavoriteList, _ int) trash.Item {
+		return trash.Item{
+			Kind:      trash.KindFavorites,
+			ID:        god.Believe(uuid.Parse(entity.ID)),
+			Title:     favorite.ListType(entity.ListType).String(),
+			DeletedBy: id.ID[user.User]{UUID: god.Believe(uuid.Parse(lo.FromPtr(entity.DeletedBy)))},
+			DeletedAt: entity.DeletedAt.Time,
+			ExpiresAt: time.Time{},
+		}
+	}), nil
+}
+
+// RestoreList takes list of the owner moved to the trash after given time back
+func (r *Repo) RestoreList(
+	ctx context.Context,
+	listID id.ID[favorite.List],
+	ownerID id.ID[user.User],
+	after time.Time,
+) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(new(FavoriteList)).
+		Where("id = ? AND deleted_at > ? AND id IN (?)", listID.String(), after.UTC(), r.ownedLists(ownerID)).
+		UpdateColumns(map[string]any{"deleted_at": nil, "deleted_by": nil})
+	if result.Error != nil {
+		return fmt.Errorf("can't restore favorites list %s: %w", listID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: favorites list %s of user %s isn't in the trash", myerr.ErrNotFound, listID, ownerID)
+	}
+
+	return nil
+}
+
+// PurgeLists deletes at most limit lists moved to the trash before given time with their members and products
+func (r *Repo) PurgeLists(ctx context.Context, before time.Time, limit int) (int, error) {
+	var listIDs []string
+
+	err := r.db.WithContext(ctx).Unscoped().Model(new(FavoriteList)).
+		Where("deleted_at < ?", before.UTC()).
+		Order("deleted_at").
+		Limit(limit).
+		Pluck("id", &listIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("can't select expired favorites lists: %w", err)
+	}
+
+	if len(listIDs) == 0 {
+		return 0, nil
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []any{new(FavoriteMember), new(FavoriteProduct)} {
+			if err := tx.Unscoped().Where("favorite_list_id IN ?", listIDs).Delete(table).Error; err != nil {
+				return fmt.Errorf("can't delete rows of favorites lists: %w", err)
+			}
+		}
+
+		if err := tx.Unscoped().Where("id IN ?", listIDs).Delete(new(FavoriteList)).Error; err != nil {
+			return fmt.Errorf("can't delete favorites lists: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return len(listIDs), nil
+}
+
+// ownedLists selects ids of lists owned by the user
+func (r *Repo) ownedLists(ownerID id.ID[user.User]) *gorm.DB {
+	return r.db.Model(new(FavoriteMember)).
+		Select("favorite_list_id").
+		Where("user_id = ? AND member_type = ?", ownerID.String(), int32(favorite.MemberTypeOwner))
+}
@@ -10,9 +10,12 @@ import (
 
 	"go-backend/internal/backend/favorite"
 	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/trash"
 	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
 	"go-backend/pkg/date"
 	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
 )
 
 type favoritesRepo interface {
@@ -23,6 +26,10 @@ type favoritesRepo interface {
 		favorite.List,
 		error,
 	)
+	TrashList(context.Context, id.ID[favorite.List], id.ID[user.User], time.Time) error
+	GetTrash(context.Context, id.ID[user.User], time.Time) ([]trash.Item, error)
+	RestoreList(context.Context, id.ID[favorite.List], id.ID[user.User], time.Time) error
+	PurgeLists(context.Context, time.Time, int) (int, error)
 }
 
 type users interface {
@@ -31,11 +38,12 @@ type users interface {
 
 // Service is the service
 type Service struct {
-	repo favoritesRepo
+	repo  favoritesRepo
+	clock clock.Clock
 }
 
-func NewService(repo favoritesRepo, users users) *Service {
-	s := &Service{repo: repo}
+func NewService(repo favoritesRepo, users users, clock clock.Clock) *Service {
+	s := &Service{repo: repo, clock: clock}
 	users.RegisterSubscriber(s)
 	return s
 }
@@ -45,23 +53,46 @@ func (s *Service) AddProducts(
 	listID id.ID[favorite.List],
 	userID id.ID[user.User],
 	productIDs []id.ID[product.Product],
+	quantities map[id.ID[product.Product]]product.Quantity,
 ) (
 	favorite.List,
 	error,
 ) {
-	fmt.Println(productIDs)
+	for productID, quantity := range quantities {
+		if err := quantity.Validate(); err != nil {
+			return favorite.List{}, fmt.Errorf("invalid quantity of product %s: %w", productID, err)
+		}
+	}
+
 	model, err := s.repoGetAndUpdate(ctx, listID, func(list favorite.List) (favorite.List, error) {
 		if err := list.AllowedToEdit(userID); err != nil {
 			return favorite.List{}, fmt.Errorf("user %s is not allowed to edit list %s: %w", userID, listID, err)
 		}
 		for _, productID := range productIDs {
+			quantity, found := quantities[productID]
+			quantityOpt := mo.TupleToOption(quantity, found)
+
+			idx := slices.IndexFunc(list.Products, func(f favorite.Favorite) bool { return f.Product.ID == productID })
+			if idx != -1 {
+				list.Products[idx].Quantity = quantityOpt
+				list.Products[idx].UpdatedAt = date.NewUpdateDate[favorite.Favorite]()
+				continue
+			}
+
 			list.Products = append(list.Products, favorite.Favorite{
 				Product: product.Product{
-					Options:   product.Options{Name: "", Category: mo.None[product.Category](), Forms: []product.Form{}},
+					Options: product.Options{
+						Name:     "",
+						Category: mo.None[product.Category](),
+						Forms:    []product.Form{},
+						Aliases:  []product.Name{},
+						Barcodes: []product.Barcode{},
+					},
 					ID:        productID,
 					CreatedAt: date.CreateDate[product.Product]{Time: time.Time{}},
 					UpdatedAt: date.UpdateDate[product.Product]{Time: time.Time{}},
 				},
+				Quantity:  quantityOpt,
 				CreatedAt: date.NewCreateDate[favorite.Favorite](),
 				UpdatedAt: date.NewUpdateDate[favorite.Favorite](),
 			})
@@ -137,6 +168,31 @@ func (s *Service) GetListByID(
 	return model, nil
 }
 
+// DeleteList moves list to the trash, only owner can delete it and personal lists can't be deleted
+func (s *Service) DeleteList(ctx context.Context, listID id.ID[favorite.List], userID id.ID[user.User]) error {
+	model, err := s.repo.GetByID(ctx, listID)
+	if err != nil {
+		return fmt.Errorf("can't get list %s: %w", listID, err)
+	}
+
+	isOwner := slices.ContainsFunc(model.Members, func(member favorite.Member) bool {
+		return member.UserID == userID && member.Type == favorite.MemberTypeOwner
+	})
+	if !isOwner {
+		return fmt.Errorf("%w: only owner can delete favorites list %s", myerr.ErrForbidden, listID)
+	}
+
+	if model.Type == favorite.ListTypePersonal {
+		return fmt.Errorf("%w: personal favorites list %s can't be deleted", myerr.ErrInvalidArgument, listID)
+	}
+
+	if err = s.repo.TrashList(ctx, listID, userID, s.clock.Now()); err != nil {
+		return fmt.Errorf("can't move list %s to the trash: %w", listID, err)
+	}
+
+	return nil
+}
+
 func (s *Service) GetListsByUserID(ctx context.Context, userID id.ID[user.User]) ([]favorite.List, error) {
 	models, err := s.repo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-backend/internal/backend/favorite"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+// purgeBatch is the number of lists purged in one call of the repository
+const purgeBatch = 100
+
+// GetTrash returns lists of the owner moved to the trash after given time
+func (s *Service) GetTrash(ctx context.Context, userID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	items, err := s.repo.GetTrash(ctx, userID, after)
+	if err != nil {
+		return nil, fmt.Errorf("can't get trashed favorites lists of user %s: %w", userID, err)
+	}
+
+	return items, nil
+}
+
+// RestoreFromTrash takes list moved to the trash after given time back, only owner can restore it
+func (s *Service) RestoreFromTrash(
+	ctx context.Context,
+	userID id.ID[user.User],
+	entityID uuid.UUID,
+	after time.Time,
+) error {
+	listID := id.ID[favorite.List]{UUID: entityID}
+
+	if err := s.repo.RestoreList(ctx, listID, userID, after); err != nil {
+		return fmt.Errorf("can't restore favorites list %s: %w", listID, err)
+	}
+
+	return nil
+}
+
+// PurgeTrash deletes lists moved to the trash before given time
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+
+	for {
+		count, err := s.repo.PurgeLists(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, fmt.Errorf("can't purge favorites lists: %w", err)
+		}
+
+		purged += count
+		if count < purgeBatch {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/favorite"
+	"go-backend/internal/backend/favorite/repo"
+	"go-backend/internal/backend/favorite/service"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+type users struct{}
+
+func (users) RegisterSubscriber(user.Subscriber) {}
+
+func newGroupList(members map[id.ID[user.User]]favorite.MemberType) favorite.List {
+	model := favorite.List{
+		ID:        id.NewID[favorite.List](),
+		Members:   []favorite.Member{},
+		CreatedAt: date.NewCreateDate[favorite.List](),
+		UpdatedAt: date.NewUpdateDate[favorite.List](),
+		Products:  []favorite.Favorite{},
+		Type:      favorite.ListTypeGroup,
+	}
+
+	for userID, role := range members {
+		model.Members = append(model.Members, favorite.Member{
+			UserID:    userID,
+			Type:      role,
+			CreatedAt: date.NewCreateDate[favorite.Member](),
+			UpdatedAt: date.NewUpdateDate[favorite.Member](),
+		})
+	}
+
+	return model
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "favorite.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	_, err = productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	favorites, err := repo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	deletedAt := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(deletedAt)
+	s := service.NewService(favorites, users{}, fake)
+
+	ownerID, editorID := id.NewID[user.User](), id.NewID[user.User]()
+	model := newGroupList(map[id.ID[user.User]]favorite.MemberType{
+		ownerID:  favorite.MemberTypeOwner,
+		editorID: favorite.MemberTypeEditor,
+	})
+	require.NoError(t, favorites.CreateList(ctx, model))
+
+	require.ErrorIs(t, s.DeleteList(ctx, model.ID, editorID), myerr.ErrForbidden)
+	require.NoError(t, s.DeleteList(ctx, model.ID, ownerID))
+
+	_, err = favorites.GetByID(ctx, model.ID)
+	require.Error(t, err, "lists in the trash aren't returned")
+
+	items, err := s.GetTrash(ctx, ownerID, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, trash.Item{
+		Kind:      trash.KindFavorites,
+		ID:        model.ID.UUID,
+		Title:     favorite.ListTypeGroup.String(),
+		DeletedBy: ownerID,
+		DeletedAt: deletedAt,
+		ExpiresAt: time.Time{},
+	}, items[0])
+
+	items, err = s.GetTrash(ctx, editorID, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, items, "only the owner sees the list in the trash")
+
+	require.ErrorIs(t, s.RestoreFromTrash(ctx, editorID, model.ID.UUID, deletedAt.Add(-time.Hour)), myerr.ErrNotFound)
+	require.ErrorIs(t, s.RestoreFromTrash(ctx, ownerID, model.ID.UUID, deletedAt.Add(time.Hour)), myerr.ErrNotFound)
+	require.NoError(t, s.RestoreFromTrash(ctx, ownerID, model.ID.UUID, deletedAt.Add(-time.Hour)))
+
+	restored, err := favorites.GetByID(ctx, model.ID)
+	require.NoError(t, err)
+	require.Len(t, restored.Members, 2)
+
+	// the list is purged with its members once it's in the trash longer than retention
+	fake.Add(time.Hour)
+	require.NoError(t, s.DeleteList(ctx, model.ID, ownerID))
+
+	purged, err := s.PurgeTrash(ctx, deletedAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	purged, err = s.PurgeTrash(ctx, deletedAt.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	var members int64
+	require.NoError(t, db.Model(new(repo.FavoriteMember)).Where("favorite_list_id = ?", model.ID.String()).
+		Count(&members).Error)
+	require.Zero(t, members)
+	require.ErrorIs(t, s.RestoreFromTrash(ctx, ownerID, model.ID.UUID, deletedAt), myerr.ErrNotFound)
+}
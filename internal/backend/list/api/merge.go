@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+// @Summary create planning copy of product list owned by the user
+// @ID product-list-duplicate
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Produce json
+// @Router /lists/{id}/duplicate [post]
+// @Security ApiKeyAuth
+func (h *Handler) Duplicate(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.Duplicate(ctx, listID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary add products and members of source lists to the target list
+// @ID product-list-merge
+// @Tags ProductList
+// @Param body body list.MergeOptions true "source and target lists"
+// @Produce json
+// @Accept json
+// @Router /lists/merge [post]
+// @Security ApiKeyAuth
+func (h *Handler) Merge(ctx *gin.Context) {
+	var opts list.MergeOptions
+
+	if ok := h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	model, err := h.service.Merge(ctx, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary move products of product list to a new list
+// @ID product-list-split
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param body body list.SplitOptions true "moved products and title of new list"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/split [post]
+// @Security ApiKeyAuth
+func (h *Handler) Split(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	var opts list.SplitOptions
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	model, err := h.service.Split(ctx, listID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
@@ -1,31 +1,49 @@
 package api
 
 import (
+	"errors"
+	"mime"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
+	"github.com/samber/mo"
 
 	"go-backend/internal/backend/auth/api"
 	"go-backend/internal/backend/list"
 	"go-backend/internal/backend/list/service"
 	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
 	"go-backend/internal/backend/user"
 	"go-backend/pkg/api/rest/rerr"
 	"go-backend/pkg/id"
 )
 
 type ProductStateOptions struct {
+	Quantity *product.Quantity `json:"quantity"`
+	// Count is a deprecated number of pieces, it's used only if quantity isn't sent
 	Count       *int32 `json:"count"`
 	FormIdx     *int32 `json:"form_idx"`
 	Status      string `json:"status"`
 	Replacement *struct {
+		Quantity *product.Quantity `json:"quantity"`
+		// Count is a deprecated number of pieces, it's used only if quantity isn't sent
 		Count     *int32 `json:"count"`
 		FormIdx   *int32 `json:"form_idx"`
 		ProductID *int32 `json:"product_id"`
 	} `json:"replacement"`
+	Note string `json:"note"`
 }
 
+const (
+	defaultHistoryLimit    = 50
+	defaultSummaryLimit    = 50
+	defaultSuggestionLimit = 20
+	maxAttachmentSize      = 10 << 20
+)
+
 type Handler struct {
 	rerr.BaseHandler
 
@@ -42,8 +60,9 @@ func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logg
 	}
 
 	group.POST("", h.CreateList)
-	group.GET("", h.GetByUserID)
+	group.GET("", h.GetSummaries)
 	group.GET("/:id", h.GetByListID)
+	group.GET("/:id/history", h.GetHistory)
 	group.DELETE("/:id", h.Delete)
 	group.POST("/:id/products", h.AddProducts)
 	group.DELETE("/:id/products", h.DeleteProducts)
@@ -51,7 +70,43 @@ func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logg
 	group.DELETE("/:id/members", h.DeleteViewerList)
 	group.PUT("/:id", h.Update)
 	group.PATCH("/:id/reorder", h.ReorderState)
+	group.POST("/:id/arrange", h.Arrange)
 	group.PATCH("/:id/products/:product_id", h.UpdateProductState)
+	group.PUT("/:id/products/:product_id/assignee", h.Assign)
+	group.POST("/:id/products/:product_id/claim", h.Claim)
+	group.DELETE("/:id/products/:product_id/claim", h.Release)
+	group.POST("/:id/scan/:code", h.Scan)
+	group.POST("/:id/products/:product_id/comments", h.AddComment)
+	group.DELETE("/:id/products/:product_id/comments/:comment_id", h.DeleteComment)
+	group.POST("/:id/products/:product_id/attachments", h.AddAttachment)
+	group.GET("/:id/products/:product_id/attachments/:attachment_id", h.GetAttachment)
+	group.DELETE("/:id/products/:product_id/attachments/:attachment_id", h.DeleteAttachment)
+	group.POST("/:id/batch", h.ApplyBatch)
+	group.POST("/:id/import/text", h.ImportText)
+	group.GET("/:id/export", h.Export)
+	group.POST("/:id/undo", h.Undo)
+	group.POST("/:id/redo", h.Redo)
+	group.POST("/:id/start", h.StartSession)
+	group.POST("/:id/finish", h.FinishSession)
+	group.GET("/:id/sessions", h.GetSessions)
+	group.GET("/:id/suggestions", h.GetSuggestions)
+	group.POST("/:id/template", h.SaveTemplate)
+	group.POST("/from-template/:id", h.CreateFromTemplate)
+	group.POST("/:id/duplicate", h.Duplicate)
+	group.POST("/:id/split", h.Split)
+	group.POST("/merge", h.Merge)
+
+	templates := r.Group("/templates")
+	templates.GET("", h.GetTemplates)
+	templates.GET("/:id", h.GetTemplate)
+	templates.PUT("/:id", h.UpdateTemplate)
+	templates.DELETE("/:id", h.DeleteTemplate)
+	templates.POST("/:id/schedules", h.CreateSchedule)
+	templates.GET("/:id/schedules", h.GetSchedules)
+	templates.DELETE("/:id/schedules/:schedule_id", h.DeleteSchedule)
+
+	stats := r.Group("/stats")
+	stats.GET("/spending", h.GetSpending)
 }
 
 // @Summary creates new product list
@@ -82,38 +137,142 @@ func (h *Handler) CreateList(ctx *gin.Context) {
 // @ID product-list-get-by-id
 // @Tags ProductList
 // @Param id path string true "id of product list"
+// @Param as_of query string false "revision id or RFC3339 timestamp to view list at past point"
+// @Param assignee query string false "returns only states assigned to given user id or to current user for 'me'"
 // @Produce json
 // @Router /lists/{id} [get]
 // @Security	ApiKeyAuth
 func (h *Handler) GetByListID(ctx *gin.Context) {
+	var model list.ProductList
+	var err error
+
 	listID, ok := rerr.PathID[list.ProductList](ctx)
 	if !ok {
 		return
 	}
 
-	model, err := h.service.GetByID(ctx, listID, api.GetUserID(ctx))
+	assignee, ok := queryAssignee(ctx)
+	if !ok {
+		return
+	}
+
+	if asOf := ctx.Query("as_of"); asOf != "" {
+		model, err = h.service.GetAsOf(ctx, listID, api.GetUserID(ctx), asOf)
+	} else {
+		model, err = h.service.GetByID(ctx, listID, api.GetUserID(ctx))
+	}
 	if err != nil {
 		h.HandleError(ctx, err)
 		return
 	}
 
+	if assigneeID, found := assignee.Get(); found {
+		model = model.AssignedTo(assigneeID)
+	}
+
 	ctx.JSON(http.StatusOK, model)
 }
 
-// @Summary get product lists by user id
+// @Summary get change log of product list
+// @ID product-list-get-history
+// @Tags ProductList
+// @Param id path string true "id of product list"
+// @Param offset query int false "number of skipped entries" default(0)
+// @Param limit query int false "max number of entries" default(50)
+// @Produce json
+// @Router /lists/{id}/history [get]
+// @Security	ApiKeyAuth
+func (h *Handler) GetHistory(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	offset, ok := rerr.QueryInt(ctx, "offset", 0)
+	if !ok {
+		return
+	}
+
+	limit, ok := rerr.QueryInt(ctx, "limit", defaultHistoryLimit)
+	if !ok {
+		return
+	}
+
+	entries, err := h.service.GetHistory(ctx, listID, api.GetUserID(ctx), offset, limit)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// @Summary get summaries of product lists of the user
 // @ID product-list-get
 // @Tags ProductList
+// @Param status query string false "status of lists: planning, processing or archived"
+// @Param role query string false "role of the user in lists"
+// @Param q query string false "words which titles of lists must contain"
+// @Param sort query string false "sort of lists: updatedAt, createdAt or title" default(updatedAt)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "max number of lists" default(50)
 // @Produce json
 // @Router /lists [get]
 // @Security	ApiKeyAuth
-func (h *Handler) GetByUserID(ctx *gin.Context) {
-	models, err := h.service.GetByUserID(ctx, api.GetUserID(ctx))
+func (h *Handler) GetSummaries(ctx *gin.Context) {
+	status, ok := queryOption(ctx, "status", list.ParseExecStatus, list.ExecStatusNames())
+	if !ok {
+		return
+	}
+
+	role, ok := queryOption(ctx, "role", list.ParseMemberType, list.MemberTypeNames())
+	if !ok {
+		return
+	}
+
+	sort, ok := queryOption(ctx, "sort", list.ParseListSort, list.ListSortNames())
+	if !ok {
+		return
+	}
+
+	limit, ok := rerr.QueryInt(ctx, "limit", defaultSummaryLimit)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.GetSummaries(ctx, api.GetUserID(ctx), list.ListFilter{
+		Status: status,
+		Role:   role,
+		Search: ctx.Query("q"),
+		Sort:   sort.OrElse(list.ListSortUpdatedAt),
+		Cursor: ctx.Query("cursor"),
+		Limit:  limit,
+	})
 	if err != nil {
 		h.HandleError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models)
+	ctx.JSON(http.StatusOK, page)
+}
+
+// queryOption parses optional enum query parameter, it responds with bad request if the value is unknown
+func queryOption[T any](ctx *gin.Context, name string, parse func(string) (T, error), names []string) (
+	mo.Option[T],
+	bool,
+) {
+	raw, found := ctx.GetQuery(name)
+	if !found {
+		return mo.None[T](), true
+	}
+
+	value, err := parse(raw)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "%s must be one of: %s", name, strings.Join(names, ", "))
+		return mo.None[T](), false
+	}
+
+	return mo.Some(value), true
 }
 
 // @Summary delete product list by id
@@ -310,6 +469,34 @@ func (h *Handler) ReorderState(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary order products in product list by categories of shop map
+// @ID product-list-arrange
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param shopmap_id query string false "shop map id, default shop map of the list is used if it's absent"
+// @Param map_id query string false "deprecated alias of shopmap_id"
+// @Router /lists/{id}/arrange [post]
+// @Security ApiKeyAuth
+func (h *Handler) Arrange(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	mapID, ok := queryMapID(ctx, "shopmap_id", "map_id")
+	if !ok {
+		return
+	}
+
+	model, err := h.service.Arrange(ctx, listID, api.GetUserID(ctx), mapID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
 // @Summary update single product state in given product list
 // @ID product-list-update-state
 // @Tags ProductList
@@ -342,3 +529,560 @@ func (h *Handler) UpdateProductState(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, state)
 }
+
+// @Summary apply ordered operations to product list in one transaction
+// @ID product-list-apply-batch
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param body body []list.Operation true "operations in order of applying"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/batch [post]
+// @Security ApiKeyAuth
+func (h *Handler) ApplyBatch(ctx *gin.Context) {
+	var operations []list.Operation
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &operations); !ok {
+		return
+	}
+
+	result, err := h.service.ApplyBatch(ctx, listID, api.GetUserID(ctx), operations)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary undo last changes of current user
+// @ID product-list-undo
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param count query int false "number of changes to undo" default(1)
+// @Produce json
+// @Router /lists/{id}/undo [post]
+// @Security ApiKeyAuth
+func (h *Handler) Undo(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	count, ok := rerr.QueryInt(ctx, "count", 1)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.Undo(ctx, listID, api.GetUserID(ctx), count)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary redo last undone changes of current user
+// @ID product-list-redo
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param count query int false "number of changes to redo" default(1)
+// @Produce json
+// @Router /lists/{id}/redo [post]
+// @Security ApiKeyAuth
+func (h *Handler) Redo(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	count, ok := rerr.QueryInt(ctx, "count", 1)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.Redo(ctx, listID, api.GetUserID(ctx), count)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary start shopping, list is moved to processing
+// @ID product-list-start-session
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param opts body list.StartOptions true "members who are shopping, current user if empty"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/start [post]
+// @Security ApiKeyAuth
+func (h *Handler) StartSession(ctx *gin.Context) {
+	var opts list.StartOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	session, err := h.service.StartSession(ctx, listID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// @Summary finish shopping, list is archived and waiting items are marked as missed
+// @ID product-list-finish-session
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param opts body list.FinishOptions true "options of finishing"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/finish [post]
+// @Security ApiKeyAuth
+func (h *Handler) FinishSession(ctx *gin.Context) {
+	var opts list.FinishOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	session, err := h.service.FinishSession(ctx, listID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// @Summary get shopping sessions of product list
+// @ID product-list-get-sessions
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Produce json
+// @Router /lists/{id}/sessions [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetSessions(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.GetSessions(ctx, listID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// @Summary set or remove assignee of product state
+// @ID product-list-assign-state
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param body body list.AssignOptions true "new assignee, state is released if it's null"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/products/{product_id}/assignee [put]
+// @Security ApiKeyAuth
+func (h *Handler) Assign(ctx *gin.Context) {
+	var opts list.AssignOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	state, err := h.service.Assign(ctx, listID, api.GetUserID(ctx), productID, opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// @Summary claim product state, fails if it's claimed by other member
+// @ID product-list-claim-state
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Produce json
+// @Router /lists/{id}/products/{product_id}/claim [post]
+// @Security ApiKeyAuth
+func (h *Handler) Claim(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	state, err := h.service.Claim(ctx, listID, api.GetUserID(ctx), productID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// @Summary mark waiting product with scanned barcode taken, otherwise offer states it may replace
+// @ID product-list-scan
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param code path string true "EAN-13, UPC-A or EAN-8 barcode"
+// @Produce json
+// @Router /lists/{id}/scan/{code} [post]
+// @Security ApiKeyAuth
+func (h *Handler) Scan(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Scan(ctx, listID, api.GetUserID(ctx), ctx.Param("code"))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary release claimed product state
+// @ID product-list-release-state
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Produce json
+// @Router /lists/{id}/products/{product_id}/claim [delete]
+// @Security ApiKeyAuth
+func (h *Handler) Release(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	state, err := h.service.Release(ctx, listID, api.GetUserID(ctx), productID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// queryAssignee parses assignee query parameter, "me" means current user
+func queryAssignee(ctx *gin.Context) (mo.Option[id.ID[user.User]], bool) {
+	switch ctx.Query("assignee") {
+	case "":
+		return mo.None[id.ID[user.User]](), true
+	case "me":
+		return mo.Some(api.GetUserID(ctx)), true
+	}
+
+	assigneeID, ok := rerr.QueryID[user.User](ctx, "assignee")
+
+	return mo.Some(assigneeID), ok
+}
+
+// @Summary add comment to list item
+// @ID product-list-add-comment
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param body body list.CommentOptions true "comment"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/products/{product_id}/comments [post]
+// @Security ApiKeyAuth
+func (h *Handler) AddComment(ctx *gin.Context) {
+	var opts list.CommentOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	comment, err := h.service.AddComment(ctx, listID, api.GetUserID(ctx), productID, opts.Text)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+// @Summary delete comment of list item
+// @ID product-list-delete-comment
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param comment_id path string true "comment id"
+// @Router /lists/{id}/products/{product_id}/comments/{comment_id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteComment(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+	commentID, ok := rerr.Path[list.Comment](ctx, "comment_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteComment(ctx, listID, api.GetUserID(ctx), productID, commentID); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary attach image to list item, body is a content of image
+// @ID product-list-add-attachment
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param body body string true "image content"
+// @Accept image/png
+// @Accept image/jpeg
+// @Accept image/webp
+// @Produce json
+// @Router /lists/{id}/products/{product_id}/attachments [post]
+// @Security ApiKeyAuth
+func (h *Handler) AddAttachment(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxAttachmentSize)
+	defer body.Close()
+
+	attachment, err := h.service.AddAttachment(ctx, listID, api.GetUserID(ctx), productID, body)
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		ctx.String(http.StatusRequestEntityTooLarge, "image must not be larger than %d bytes", tooLarge.Limit)
+		return
+	} else if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attachment)
+}
+
+// @Summary get content of image attached to list item
+// @ID product-list-get-attachment
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param attachment_id path string true "attachment id"
+// @Router /lists/{id}/products/{product_id}/attachments/{attachment_id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetAttachment(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := rerr.Path[list.Attachment](ctx, "attachment_id")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.GetAttachment(ctx, listID, api.GetUserID(ctx), productID, attachmentID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+	defer content.Close()
+
+	// images attached before types were detected by content are served as plain binary data
+	contentType := attachment.ContentType
+	if !slices.Contains(list.AttachmentTypes, contentType) {
+		contentType = "application/octet-stream"
+	}
+
+	// images are downloaded instead of being opened by browser on the API origin
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.ID.String()})
+
+	ctx.DataFromReader(http.StatusOK, attachment.Size, contentType, content, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Content-Disposition":    disposition,
+	})
+}
+
+// @Summary delete image attached to list item
+// @ID product-list-delete-attachment
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param product_id path string true "product state product id"
+// @Param attachment_id path string true "attachment id"
+// @Router /lists/{id}/products/{product_id}/attachments/{attachment_id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteAttachment(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+	attachmentID, ok := rerr.Path[list.Attachment](ctx, "attachment_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAttachment(ctx, listID, api.GetUserID(ctx), productID, attachmentID); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary import plain-text list, preview is returned unless commit is true
+// @ID product-list-import-text
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param body body list.ImportOptions true "pasted text"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/import/text [post]
+// @Security ApiKeyAuth
+func (h *Handler) ImportText(ctx *gin.Context) {
+	var opts list.ImportOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	result, err := h.service.ImportText(ctx, listID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @Summary export product list as a file
+// @ID product-list-export
+// @Tags ProductList
+// @Param id path string true "product list id"
+// @Param format query string true "format of file: md, csv, txt or pdf"
+// @Param map_id query string false "shop map id, states are grouped by its categories"
+// @Produce text/markdown,text/csv,text/plain,application/pdf
+// @Router /lists/{id}/export [get]
+// @Security ApiKeyAuth
+func (h *Handler) Export(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	format, err := list.ParseExportFormat(ctx.Query("format"))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "format must be one of: %s", strings.Join(list.ExportFormatNames(), ", "))
+		return
+	}
+
+	mapID, ok := queryMapID(ctx, "map_id")
+	if !ok {
+		return
+	}
+
+	content, err := h.service.Export(ctx, listID, api.GetUserID(ctx), format, mapID)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": "list-" + listID.String() + "." + format.String(),
+	}))
+	ctx.Data(http.StatusOK, format.ContentType(), content)
+}
+
+// queryMapID returns optional shop map id from the first of given queries that is sent,
+// it writes 400 if the id is invalid
+func queryMapID(ctx *gin.Context, names ...string) (mo.Option[id.ID[shopmap.ShopMap]], bool) {
+	for _, name := range names {
+		if _, found := ctx.GetQuery(name); !found {
+			continue
+		}
+
+		mapID, ok := rerr.QueryID[shopmap.ShopMap](ctx, name)
+		if !ok {
+			return mo.None[id.ID[shopmap.ShopMap]](), false
+		}
+
+		return mo.Some(mapID), true
+	}
+
+	return mo.None[id.ID[shopmap.ShopMap]](), true
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+func TestQueryAssignee(t *testing.T) {
+	userID, otherID := id.NewID[user.User](), id.NewID[user.User]()
+
+	for _, c := range []struct {
+		query    string
+		assignee mo.Option[id.ID[user.User]]
+		ok       bool
+	}{
+		{query: "", assignee: mo.None[id.ID[user.User]](), ok: true},
+		{query: "me", assignee: mo.Some(userID), ok: true},
+		{query: otherID.String(), assignee: mo.Some(otherID), ok: true},
+		{query: "somebody", ok: false},
+	} {
+		t.Run(c.query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/lists/1?assignee="+c.query, nil)
+			ctx.Set("userId", userID)
+
+			assignee, ok := queryAssignee(ctx)
+			require.Equal(t, c.ok, ok)
+			if !c.ok {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				return
+			}
+
+			require.Equal(t, c.assignee, assignee)
+		})
+	}
+}
+
+func TestQueryMapID(t *testing.T) {
+	mapID, otherID := id.NewID[shopmap.ShopMap](), id.NewID[shopmap.ShopMap]()
+
+	for _, c := range []struct {
+		name  string
+		query string
+		mapID mo.Option[id.ID[shopmap.ShopMap]]
+		ok    bool
+	}{
+		{name: "absent", query: "", mapID: mo.None[id.ID[shopmap.ShopMap]](), ok: true},
+		{name: "shopmap_id", query: "shopmap_id=" + mapID.String(), mapID: mo.Some(mapID), ok: true},
+		{name: "map_id", query: "map_id=" + mapID.String(), mapID: mo.Some(mapID), ok: true},
+		{
+			name:  "shopmap_id wins",
+			query: "shopmap_id=" + mapID.String() + "&map_id=" + otherID.String(),
+			mapID: mo.Some(mapID),
+			ok:    true,
+		},
+		{name: "invalid", query: "shopmap_id=corner", ok: false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodPost, "/lists/1/arrange?"+c.query, nil)
+
+			parsed, ok := queryMapID(ctx, "shopmap_id", "map_id")
+			require.Equal(t, c.ok, ok)
+			if !c.ok {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				return
+			}
+
+			require.Equal(t, c.mapID, parsed)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+// defaultSpendingRange is a range of spending statistics when its start isn't set
+const defaultSpendingRange = 30 * 24 * time.Hour
+
+// @Summary get money spent in lists of the user by periods, categories, shop maps and currencies
+// @ID stats-spending
+// @Tags Stats
+// @Param from query string false "RFC3339 timestamp or date of the range start, 30 days before its end by default"
+// @Param to query string false "RFC3339 timestamp or date of the range end, which isn't included, now by default"
+// @Param period query string false "day, week or month, weeks start on Monday, month by default"
+// @Produce json
+// @Router /stats/spending [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetSpending(ctx *gin.Context) {
+	to, ok := rerr.QueryTime(ctx, "to", time.Now())
+	if !ok {
+		return
+	}
+
+	from, ok := rerr.QueryTime(ctx, "from", to.Add(-defaultSpendingRange))
+	if !ok {
+		return
+	}
+
+	period, ok := queryOption(ctx, "period", list.ParseSpendingPeriod, list.SpendingPeriodNames())
+	if !ok {
+		return
+	}
+
+	spending, err := h.service.GetSpending(ctx, api.GetUserID(ctx), list.SpendingFilter{
+		From:   from,
+		To:     to,
+		Period: period.OrElse(list.SpendingPeriodMonth),
+	})
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, spending)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+// @Summary get products bought in archived lists of members which are not in the list, overdue ones go first
+// @ID product-list-get-suggestions
+// @Tags ProductList
+// @Param id path string true "id of product list"
+// @Param limit query int false "max number of suggestions" default(20)
+// @Produce json
+// @Router /lists/{id}/suggestions [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetSuggestions(ctx *gin.Context) {
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	limit, ok := rerr.QueryInt(ctx, "limit", defaultSuggestionLimit)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.service.GetSuggestions(ctx, listID, api.GetUserID(ctx), limit)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suggestions)
+}
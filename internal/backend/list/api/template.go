@@ -0,0 +1,229 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+// @Summary save product list as a template without members and statuses
+// @ID product-list-save-template
+// @Tags ListTemplate
+// @Param id path string true "product list id"
+// @Param body body list.TemplateOptions true "options of new template"
+// @Produce json
+// @Accept json
+// @Router /lists/{id}/template [post]
+// @Security ApiKeyAuth
+func (h *Handler) SaveTemplate(ctx *gin.Context) {
+	var opts list.TemplateOptions
+
+	listID, ok := rerr.PathID[list.ProductList](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	template, err := h.service.SaveTemplate(ctx, listID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary create new planning list from template
+// @ID product-list-create-from-template
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Produce json
+// @Router /lists/from-template/{id} [post]
+// @Security ApiKeyAuth
+func (h *Handler) CreateFromTemplate(ctx *gin.Context) {
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.CreateFromTemplate(ctx, templateID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get templates which user owns or is a member of
+// @ID list-templates-get
+// @Tags ListTemplate
+// @Produce json
+// @Router /templates [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetTemplates(ctx *gin.Context) {
+	templates, err := h.service.GetTemplates(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// @Summary get template by id
+// @ID list-template-get-by-id
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Produce json
+// @Router /templates/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetTemplate(ctx *gin.Context) {
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	template, err := h.service.GetTemplate(ctx, templateID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary update title, default shop map and members of template
+// @ID list-template-update
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Param body body list.TemplateOptions true "new options of template"
+// @Produce json
+// @Accept json
+// @Router /templates/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) UpdateTemplate(ctx *gin.Context) {
+	var opts list.TemplateOptions
+
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(ctx, templateID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// @Summary delete template with its schedules
+// @ID list-template-delete
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Router /templates/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteTemplate(ctx *gin.Context) {
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTemplate(ctx, templateID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary add schedule which creates lists from template
+// @ID list-template-create-schedule
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Param body body list.ScheduleOptions true "period of schedule"
+// @Produce json
+// @Accept json
+// @Router /templates/{id}/schedules [post]
+// @Security ApiKeyAuth
+func (h *Handler) CreateSchedule(ctx *gin.Context) {
+	var opts list.ScheduleOptions
+
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &opts); !ok {
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(ctx, templateID, api.GetUserID(ctx), opts)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+// @Summary get schedules of template
+// @ID list-template-get-schedules
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Produce json
+// @Router /templates/{id}/schedules [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetSchedules(ctx *gin.Context) {
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.service.GetSchedules(ctx, templateID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+// @Summary delete schedule of template
+// @ID list-template-delete-schedule
+// @Tags ListTemplate
+// @Param id path string true "template id"
+// @Param schedule_id path string true "schedule id"
+// @Router /templates/{id}/schedules/{schedule_id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteSchedule(ctx *gin.Context) {
+	templateID, ok := rerr.PathID[list.Template](ctx)
+	if !ok {
+		return
+	}
+
+	scheduleID, ok := rerr.Path[list.Schedule](ctx, "schedule_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSchedule(ctx, templateID, scheduleID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
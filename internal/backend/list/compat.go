@@ -0,0 +1,170 @@
+package list
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+// legacyCount is a number of pieces which old clients and snapshots send in "count" field instead of quantity
+type legacyCount struct {
+	Count    mo.Option[int32]            `json:"count"`
+	Quantity mo.Option[product.Quantity] `json:"quantity"`
+}
+
+// quantity returns decoded q or count of pieces if quantity isn't sent, the quantity wins if both are sent
+func (c legacyCount) quantity(q mo.Option[product.Quantity]) mo.Option[product.Quantity] {
+	count, found := c.Count.Get()
+	if c.Quantity.IsPresent() || !found {
+		return q
+	}
+
+	return mo.Some(product.NewPieces(count))
+}
+
+// legacyCountOf returns deprecated "count" sent along with a whole quantity in pieces during the deprecation window,
+// it's omitted for other quantities
+func legacyCountOf(q mo.Option[product.Quantity]) *int32 {
+	quantity, found := q.Get()
+	if !found || quantity.Unit != product.UnitPieces || quantity.Value != math.Trunc(quantity.Value) ||
+		quantity.Value > math.MaxInt32 {
+		return nil
+	}
+
+	count := int32(quantity.Value)
+
+	return &count
+}
+
+// MarshalJSON adds deprecated "count" to a quantity in pieces
+func (r ProductStateReplacement) MarshalJSON() ([]byte, error) {
+	type replacement ProductStateReplacement
+
+	return json.Marshal(struct {
+		replacement
+
+		Count *int32 `json:"count,omitempty"`
+	}{replacement: replacement(r), Count: legacyCountOf(r.Quantity)})
+}
+
+// UnmarshalJSON accepts deprecated "count" as a quantity in pieces
+func (r *ProductStateReplacement) UnmarshalJSON(data []byte) error {
+	type replacement ProductStateReplacement
+
+	var legacy legacyCount
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, (*replacement)(r)); err != nil {
+		return err
+	}
+
+	r.Quantity = legacy.quantity(r.Quantity)
+
+	return nil
+}
+
+// stateOptions are ProductStateOptions without their methods, so they're encoded as a part of other structs
+type stateOptions ProductStateOptions
+
+// legacyOptions are options with deprecated "count"
+type legacyOptions struct {
+	stateOptions
+
+	Count *int32 `json:"count,omitempty"`
+}
+
+func newLegacyOptions(o ProductStateOptions) legacyOptions {
+	return legacyOptions{stateOptions: stateOptions(o), Count: legacyCountOf(o.Quantity)}
+}
+
+// MarshalJSON adds deprecated "count" to a quantity in pieces
+func (o ProductStateOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newLegacyOptions(o))
+}
+
+// UnmarshalJSON accepts deprecated "count" as a quantity in pieces
+func (o *ProductStateOptions) UnmarshalJSON(data []byte) error {
+	type options ProductStateOptions
+
+	var legacy legacyCount
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, (*options)(o)); err != nil {
+		return err
+	}
+
+	o.Quantity = legacy.quantity(o.Quantity)
+
+	return nil
+}
+
+// MarshalJSON encodes state with deprecated "count" of its options,
+// otherwise the promoted encoder of options would skip the rest of fields
+func (s ProductState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		legacyOptions
+
+		Product     product.Product               `json:"product"`
+		Assignee    mo.Option[id.ID[user.User]]   `json:"assignee"`
+		Comments    []Comment                     `json:"comments"`
+		Attachments []Attachment                  `json:"attachments"`
+		CreatedAt   date.CreateDate[ProductState] `json:"created_at"`
+		UpdatedAt   date.UpdateDate[ProductState] `json:"updated_at"`
+		PurchasedAt mo.Option[time.Time]          `json:"purchased_at"`
+	}{
+		legacyOptions: newLegacyOptions(s.ProductStateOptions),
+		Product:       s.Product,
+		Assignee:      s.Assignee,
+		Comments:      s.Comments,
+		Attachments:   s.Attachments,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+		PurchasedAt:   s.PurchasedAt,
+	})
+}
+
+// UnmarshalJSON decodes embedded options by their own decoder and the rest of fields separately,
+// otherwise the promoted decoder of options would skip the rest of fields
+func (s *ProductState) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Product     product.Product               `json:"product"`
+		Assignee    mo.Option[id.ID[user.User]]   `json:"assignee"`
+		Comments    []Comment                     `json:"comments"`
+		Attachments []Attachment                  `json:"attachments"`
+		CreatedAt   date.CreateDate[ProductState] `json:"created_at"`
+		UpdatedAt   date.UpdateDate[ProductState] `json:"updated_at"`
+		PurchasedAt mo.Option[time.Time]          `json:"purchased_at"`
+	}
+
+	if err := s.ProductStateOptions.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*s = ProductState{
+		ProductStateOptions: s.ProductStateOptions,
+		Product:             fields.Product,
+		Assignee:            fields.Assignee,
+		Comments:            fields.Comments,
+		Attachments:         fields.Attachments,
+		CreatedAt:           fields.CreatedAt,
+		UpdatedAt:           fields.UpdatedAt,
+		PurchasedAt:         fields.PurchasedAt,
+	}
+
+	return nil
+}
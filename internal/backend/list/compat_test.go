@@ -0,0 +1,123 @@
+package list_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+func TestLegacyCount(t *testing.T) {
+	pieces := func(value float64) mo.Option[product.Quantity] {
+		return mo.Some(product.Quantity{Value: value, Unit: product.UnitPieces})
+	}
+
+	cases := []struct {
+		name        string
+		body        string
+		quantity    mo.Option[product.Quantity]
+		replacement mo.Option[product.Quantity]
+	}{
+		{name: "count", body: `{"count": 3}`, quantity: pieces(3)},
+		{name: "null count", body: `{"count": null}`},
+		{
+			name:     "quantity wins",
+			body:     `{"count": 3, "quantity": {"value": 1.5, "unit": "kg"}}`,
+			quantity: mo.Some(product.Quantity{Value: 1.5, Unit: product.UnitKg}),
+		},
+		{
+			name:        "replacement count",
+			body:        `{"count": 2, "replacement": {"count": 4, "product": {}}}`,
+			quantity:    pieces(2),
+			replacement: pieces(4),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var options list.ProductStateOptions
+			require.NoError(t, json.Unmarshal([]byte(c.body), &options))
+			require.Equal(t, c.quantity, options.Quantity)
+			require.Equal(t, c.replacement, options.Replacement.OrEmpty().Quantity)
+		})
+	}
+
+	var options list.ProductStateOptions
+	require.Error(t, json.Unmarshal([]byte(`{"count": "three"}`), &options))
+}
+
+func TestProductStateJSON(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	author := id.NewID[user.User]()
+	state := list.ProductState{
+		ProductStateOptions: list.ProductStateOptions{
+			Quantity: mo.Some(product.NewPieces(2)),
+			Status:   list.StateStatusTaken,
+			Note:     "fresh",
+		},
+		Product:     product.Product{ID: id.NewID[product.Product](), Options: product.Options{Name: "milk"}},
+		Assignee:    mo.Some(author),
+		Comments:    []list.Comment{{ID: id.NewID[list.Comment](), Author: author, Text: "2.5%", CreatedAt: now}},
+		Attachments: []list.Attachment{{ID: id.NewID[list.Attachment](), Author: author, CreatedAt: now}},
+		CreatedAt:   date.CreateDate[list.ProductState]{Time: now},
+		UpdatedAt:   date.UpdateDate[list.ProductState]{Time: now},
+		PurchasedAt: mo.Some(now),
+	}
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	var decoded list.ProductState
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, state, decoded)
+
+	// a snapshot saved before quantities keeps the count of pieces
+	require.NoError(t, json.Unmarshal([]byte(`{"count": 5, "product": {"name": "eggs"}}`), &decoded))
+	require.Equal(t, mo.Some(product.NewPieces(5)), decoded.Quantity)
+	require.Equal(t, product.Name("eggs"), decoded.Product.Name)
+}
+
+func TestLegacyCountIsSent(t *testing.T) {
+	state := list.ProductState{
+		ProductStateOptions: list.ProductStateOptions{
+			Quantity: mo.Some(product.NewPieces(2)),
+			Status:   list.StateStatusReplaced,
+			Replacement: mo.Some(list.ProductStateReplacement{
+				Quantity: mo.Some(product.Quantity{Value: 0.5, Unit: product.UnitKg}),
+				Product:  product.Product{ID: id.NewID[product.Product](), Options: product.Options{Name: "kefir"}},
+			}),
+		},
+		Product: product.Product{ID: id.NewID[product.Product](), Options: product.Options{Name: "milk"}},
+	}
+
+	data, err := json.Marshal(list.FullUpdateChange{ProductList: list.ProductList{States: []list.ProductState{state}}})
+	require.NoError(t, err)
+
+	var sent struct {
+		States []struct {
+			Count       *int32          `json:"count"`
+			Status      string          `json:"status"`
+			Product     product.Product `json:"product"`
+			Replacement struct {
+				Count *int32 `json:"count"`
+			} `json:"replacement"`
+		} `json:"states"`
+	}
+	require.NoError(t, json.Unmarshal(data, &sent))
+	require.Len(t, sent.States, 1)
+
+	// old clients still get count of pieces, other quantities have no count
+	require.Equal(t, lo.ToPtr[int32](2), sent.States[0].Count)
+	require.Nil(t, sent.States[0].Replacement.Count)
+	require.Equal(t, list.StateStatusReplaced.String(), sent.States[0].Status)
+	require.Equal(t, product.Name("milk"), sent.States[0].Product.Name)
+}
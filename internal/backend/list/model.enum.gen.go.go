@@ -32,11 +32,35 @@ const (
 	EventTypeStatesReordered
 	// EventTypeStateUpdated is a EventType of type StateUpdated.
 	EventTypeStateUpdated
+	// EventTypeBatch is a EventType of type Batch.
+	EventTypeBatch
+	// EventTypeUndone is a EventType of type Undone.
+	EventTypeUndone
+	// EventTypeRedone is a EventType of type Redone.
+	EventTypeRedone
+	// EventTypeSessionStarted is a EventType of type SessionStarted.
+	EventTypeSessionStarted
+	// EventTypeSessionFinished is a EventType of type SessionFinished.
+	EventTypeSessionFinished
+	// EventTypeStateAssigned is a EventType of type StateAssigned.
+	EventTypeStateAssigned
+	// EventTypeCommentAdded is a EventType of type CommentAdded.
+	EventTypeCommentAdded
+	// EventTypeCommentDeleted is a EventType of type CommentDeleted.
+	EventTypeCommentDeleted
+	// EventTypeAttachmentAdded is a EventType of type AttachmentAdded.
+	EventTypeAttachmentAdded
+	// EventTypeAttachmentDeleted is a EventType of type AttachmentDeleted.
+	EventTypeAttachmentDeleted
+	// EventTypeRestored is a EventType of type Restored.
+	EventTypeRestored
+	// EventTypeBudgetExceeded is a EventType of type BudgetExceeded.
+	EventTypeBudgetExceeded
 )
 
 var ErrInvalidEventType = fmt.Errorf("not a valid EventType, try [%s]", strings.Join(_EventTypeNames, ", "))
 
-const _EventTypeName = "fullproductsAddedproductsRemovedmembersAddedmembersRemovedoptsUpdateddeletedstatesReorderedstateUpdated"
+const _EventTypeName = "fullproductsAddedproductsRemovedmembersAddedmembersRemovedoptsUpdateddeletedstatesReorderedstateUpdatedbatchundoneredonesessionStartedsessionFinishedstateAssignedcommentAddedcommentDeletedattachmentAddedattachmentDeletedrestoredbudgetExceeded"
 
 var _EventTypeNames = []string{
 	_EventTypeName[0:4],
@@ -48,6 +72,18 @@ var _EventTypeNames = []string{
 	_EventTypeName[69:76],
 	_EventTypeName[76:91],
 	_EventTypeName[91:103],
+	_EventTypeName[103:108],
+	_EventTypeName[108:114],
+	_EventTypeName[114:120],
+	_EventTypeName[120:134],
+	_EventTypeName[134:149],
+	_EventTypeName[149:162],
+	_EventTypeName[162:174],
+	_EventTypeName[174:188],
+	_EventTypeName[188:203],
+	_EventTypeName[203:220],
+	_EventTypeName[220:228],
+	_EventTypeName[228:242],
 }
 
 // EventTypeNames returns a list of possible string values of EventType.
@@ -69,19 +105,43 @@ func EventTypeValues() []EventType {
 		EventTypeDeleted,
 		EventTypeStatesReordered,
 		EventTypeStateUpdated,
+		EventTypeBatch,
+		EventTypeUndone,
+		EventTypeRedone,
+		EventTypeSessionStarted,
+		EventTypeSessionFinished,
+		EventTypeStateAssigned,
+		EventTypeCommentAdded,
+		EventTypeCommentDeleted,
+		EventTypeAttachmentAdded,
+		EventTypeAttachmentDeleted,
+		EventTypeRestored,
+		EventTypeBudgetExceeded,
 	}
 }
 
 var _EventTypeMap = map[EventType]string{
-	EventTypeFull:            _EventTypeName[0:4],
-	EventTypeProductsAdded:   _EventTypeName[4:17],
-	EventTypeProductsRemoved: _EventTypeName[17:32],
-	EventTypeMembersAdded:    _EventTypeName[32:44],
-	EventTypeMembersRemoved:  _EventTypeName[44:58],
-	EventTypeOptsUpdated:     _EventTypeName[58:69],
-	EventTypeDeleted:         _EventTypeName[69:76],
-	EventTypeStatesReordered: _EventTypeName[76:91],
-	EventTypeStateUpdated:    _EventTypeName[91:103],
+	EventTypeFull:              _EventTypeName[0:4],
+	EventTypeProductsAdded:     _EventTypeName[4:17],
+	EventTypeProductsRemoved:   _EventTypeName[17:32],
+	EventTypeMembersAdded:      _EventTypeName[32:44],
+	EventTypeMembersRemoved:    _EventTypeName[44:58],
+	EventTypeOptsUpdated:       _EventTypeName[58:69],
+	EventTypeDeleted:           _EventTypeName[69:76],
+	EventTypeStatesReordered:   _EventTypeName[76:91],
+	EventTypeStateUpdated:      _EventTypeName[91:103],
+	EventTypeBatch:             _EventTypeName[103:108],
+	EventTypeUndone:            _EventTypeName[108:114],
+	EventTypeRedone:            _EventTypeName[114:120],
+	EventTypeSessionStarted:    _EventTypeName[120:134],
+	EventTypeSessionFinished:   _EventTypeName[134:149],
+	EventTypeStateAssigned:     _EventTypeName[149:162],
+	EventTypeCommentAdded:      _EventTypeName[162:174],
+	EventTypeCommentDeleted:    _EventTypeName[174:188],
+	EventTypeAttachmentAdded:   _EventTypeName[188:203],
+	EventTypeAttachmentDeleted: _EventTypeName[203:220],
+	EventTypeRestored:          _EventTypeName[220:228],
+	EventTypeBudgetExceeded:    _EventTypeName[228:242],
 }
 
 // String implements the Stringer interface.
@@ -100,15 +160,27 @@ func (x EventType) IsValid() bool {
 }
 
 var _EventTypeValue = map[string]EventType{
-	_EventTypeName[0:4]:    EventTypeFull,
-	_EventTypeName[4:17]:   EventTypeProductsAdded,
-	_EventTypeName[17:32]:  EventTypeProductsRemoved,
-	_EventTypeName[32:44]:  EventTypeMembersAdded,
-	_EventTypeName[44:58]:  EventTypeMembersRemoved,
-	_EventTypeName[58:69]:  EventTypeOptsUpdated,
-	_EventTypeName[69:76]:  EventTypeDeleted,
-	_EventTypeName[76:91]:  EventTypeStatesReordered,
-	_EventTypeName[91:103]: EventTypeStateUpdated,
+	_EventTypeName[0:4]:     EventTypeFull,
+	_EventTypeName[4:17]:    EventTypeProductsAdded,
+	_EventTypeName[17:32]:   EventTypeProductsRemoved,
+	_EventTypeName[32:44]:   EventTypeMembersAdded,
+	_EventTypeName[44:58]:   EventTypeMembersRemoved,
+	_EventTypeName[58:69]:   EventTypeOptsUpdated,
+	_EventTypeName[69:76]:   EventTypeDeleted,
+	_EventTypeName[76:91]:   EventTypeStatesReordered,
+	_EventTypeName[91:103]:  EventTypeStateUpdated,
+	_EventTypeName[103:108]: EventTypeBatch,
+	_EventTypeName[108:114]: EventTypeUndone,
+	_EventTypeName[114:120]: EventTypeRedone,
+	_EventTypeName[120:134]: EventTypeSessionStarted,
+	_EventTypeName[134:149]: EventTypeSessionFinished,
+	_EventTypeName[149:162]: EventTypeStateAssigned,
+	_EventTypeName[162:174]: EventTypeCommentAdded,
+	_EventTypeName[174:188]: EventTypeCommentDeleted,
+	_EventTypeName[188:203]: EventTypeAttachmentAdded,
+	_EventTypeName[203:220]: EventTypeAttachmentDeleted,
+	_EventTypeName[220:228]: EventTypeRestored,
+	_EventTypeName[228:242]: EventTypeBudgetExceeded,
 }
 
 // ParseEventType attempts to convert a string to a EventType.
@@ -367,6 +439,330 @@ func (x ExecStatus) Value() (driver.Value, error) {
 	return x.String(), nil
 }
 
+const (
+	// ExportFormatMd is a ExportFormat of type Md.
+	ExportFormatMd ExportFormat = iota + 1
+	// ExportFormatCsv is a ExportFormat of type Csv.
+	ExportFormatCsv
+	// ExportFormatTxt is a ExportFormat of type Txt.
+	ExportFormatTxt
+	// ExportFormatPdf is a ExportFormat of type Pdf.
+	ExportFormatPdf
+)
+
+var ErrInvalidExportFormat = fmt.Errorf("not a valid ExportFormat, try [%s]", strings.Join(_ExportFormatNames, ", "))
+
+const _ExportFormatName = "mdcsvtxtpdf"
+
+var _ExportFormatNames = []string{
+	_ExportFormatName[0:2],
+	_ExportFormatName[2:5],
+	_ExportFormatName[5:8],
+	_ExportFormatName[8:11],
+}
+
+// ExportFormatNames returns a list of possible string values of ExportFormat.
+func ExportFormatNames() []string {
+	tmp := make([]string, len(_ExportFormatNames))
+	copy(tmp, _ExportFormatNames)
+	return tmp
+}
+
+// ExportFormatValues returns a list of the values for ExportFormat
+func ExportFormatValues() []ExportFormat {
+	return []ExportFormat{
+		ExportFormatMd,
+		ExportFormatCsv,
+		ExportFormatTxt,
+		ExportFormatPdf,
+	}
+}
+
+var _ExportFormatMap = map[ExportFormat]string{
+	ExportFormatMd:  _ExportFormatName[0:2],
+	ExportFormatCsv: _ExportFormatName[2:5],
+	ExportFormatTxt: _ExportFormatName[5:8],
+	ExportFormatPdf: _ExportFormatName[8:11],
+}
+
+// String implements the Stringer interface.
+func (x ExportFormat) String() string {
+	if str, ok := _ExportFormatMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ExportFormat(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ExportFormat) IsValid() bool {
+	_, ok := _ExportFormatMap[x]
+	return ok
+}
+
+var _ExportFormatValue = map[string]ExportFormat{
+	_ExportFormatName[0:2]:  ExportFormatMd,
+	_ExportFormatName[2:5]:  ExportFormatCsv,
+	_ExportFormatName[5:8]:  ExportFormatTxt,
+	_ExportFormatName[8:11]: ExportFormatPdf,
+}
+
+// ParseExportFormat attempts to convert a string to a ExportFormat.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	if x, ok := _ExportFormatValue[name]; ok {
+		return x, nil
+	}
+	return ExportFormat(0), fmt.Errorf("%s is %w", name, ErrInvalidExportFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ExportFormat) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ExportFormat) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseExportFormat(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errExportFormatNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *ExportFormat) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = ExportFormat(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = ExportFormat(v)
+	case string:
+		*x, err = ParseExportFormat(v)
+	case []byte:
+		*x, err = ParseExportFormat(string(v))
+	case ExportFormat:
+		*x = v
+	case int:
+		*x = ExportFormat(v)
+	case *ExportFormat:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = ExportFormat(v)
+	case uint64:
+		*x = ExportFormat(v)
+	case *int:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = ExportFormat(*v)
+	case *int64:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = ExportFormat(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = ExportFormat(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = ExportFormat(*v)
+	case *uint:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = ExportFormat(*v)
+	case *uint64:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x = ExportFormat(*v)
+	case *string:
+		if v == nil {
+			return errExportFormatNilPtr
+		}
+		*x, err = ParseExportFormat(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x ExportFormat) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+const (
+	// ListSortUpdatedAt is a ListSort of type UpdatedAt.
+	ListSortUpdatedAt ListSort = iota + 1
+	// ListSortCreatedAt is a ListSort of type CreatedAt.
+	ListSortCreatedAt
+	// ListSortTitle is a ListSort of type Title.
+	ListSortTitle
+)
+
+var ErrInvalidListSort = fmt.Errorf("not a valid ListSort, try [%s]", strings.Join(_ListSortNames, ", "))
+
+const _ListSortName = "updatedAtcreatedAttitle"
+
+var _ListSortNames = []string{
+	_ListSortName[0:9],
+	_ListSortName[9:18],
+	_ListSortName[18:23],
+}
+
+// ListSortNames returns a list of possible string values of ListSort.
+func ListSortNames() []string {
+	tmp := make([]string, len(_ListSortNames))
+	copy(tmp, _ListSortNames)
+	return tmp
+}
+
+// ListSortValues returns a list of the values for ListSort
+func ListSortValues() []ListSort {
+	return []ListSort{
+		ListSortUpdatedAt,
+		ListSortCreatedAt,
+		ListSortTitle,
+	}
+}
+
+var _ListSortMap = map[ListSort]string{
+	ListSortUpdatedAt: _ListSortName[0:9],
+	ListSortCreatedAt: _ListSortName[9:18],
+	ListSortTitle:     _ListSortName[18:23],
+}
+
+// String implements the Stringer interface.
+func (x ListSort) String() string {
+	if str, ok := _ListSortMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("ListSort(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ListSort) IsValid() bool {
+	_, ok := _ListSortMap[x]
+	return ok
+}
+
+var _ListSortValue = map[string]ListSort{
+	_ListSortName[0:9]:   ListSortUpdatedAt,
+	_ListSortName[9:18]:  ListSortCreatedAt,
+	_ListSortName[18:23]: ListSortTitle,
+}
+
+// ParseListSort attempts to convert a string to a ListSort.
+func ParseListSort(name string) (ListSort, error) {
+	if x, ok := _ListSortValue[name]; ok {
+		return x, nil
+	}
+	return ListSort(0), fmt.Errorf("%s is %w", name, ErrInvalidListSort)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ListSort) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ListSort) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseListSort(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errListSortNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *ListSort) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = ListSort(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = ListSort(v)
+	case string:
+		*x, err = ParseListSort(v)
+	case []byte:
+		*x, err = ParseListSort(string(v))
+	case ListSort:
+		*x = v
+	case int:
+		*x = ListSort(v)
+	case *ListSort:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = ListSort(v)
+	case uint64:
+		*x = ListSort(v)
+	case *int:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = ListSort(*v)
+	case *int64:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = ListSort(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = ListSort(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = ListSort(*v)
+	case *uint:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = ListSort(*v)
+	case *uint64:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x = ListSort(*v)
+	case *string:
+		if v == nil {
+			return errListSortNilPtr
+		}
+		*x, err = ParseListSort(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x ListSort) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
 const (
 	// MemberTypeOwner is a MemberType of type Owner.
 	MemberTypeOwner MemberType = iota + 1
@@ -538,6 +934,489 @@ func (x MemberType) Value() (driver.Value, error) {
 	return x.String(), nil
 }
 
+const (
+	// OperationTypeAddProducts is a OperationType of type AddProducts.
+	OperationTypeAddProducts OperationType = iota + 1
+	// OperationTypeUpdateState is a OperationType of type UpdateState.
+	OperationTypeUpdateState
+	// OperationTypeDeleteProducts is a OperationType of type DeleteProducts.
+	OperationTypeDeleteProducts
+	// OperationTypeReorderStates is a OperationType of type ReorderStates.
+	OperationTypeReorderStates
+)
+
+var ErrInvalidOperationType = fmt.Errorf("not a valid OperationType, try [%s]", strings.Join(_OperationTypeNames, ", "))
+
+const _OperationTypeName = "addProductsupdateStatedeleteProductsreorderStates"
+
+var _OperationTypeNames = []string{
+	_OperationTypeName[0:11],
+	_OperationTypeName[11:22],
+	_OperationTypeName[22:36],
+	_OperationTypeName[36:49],
+}
+
+// OperationTypeNames returns a list of possible string values of OperationType.
+func OperationTypeNames() []string {
+	tmp := make([]string, len(_OperationTypeNames))
+	copy(tmp, _OperationTypeNames)
+	return tmp
+}
+
+// OperationTypeValues returns a list of the values for OperationType
+func OperationTypeValues() []OperationType {
+	return []OperationType{
+		OperationTypeAddProducts,
+		OperationTypeUpdateState,
+		OperationTypeDeleteProducts,
+		OperationTypeReorderStates,
+	}
+}
+
+var _OperationTypeMap = map[OperationType]string{
+	OperationTypeAddProducts:    _OperationTypeName[0:11],
+	OperationTypeUpdateState:    _OperationTypeName[11:22],
+	OperationTypeDeleteProducts: _OperationTypeName[22:36],
+	OperationTypeReorderStates:  _OperationTypeName[36:49],
+}
+
+// String implements the Stringer interface.
+func (x OperationType) String() string {
+	if str, ok := _OperationTypeMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("OperationType(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x OperationType) IsValid() bool {
+	_, ok := _OperationTypeMap[x]
+	return ok
+}
+
+var _OperationTypeValue = map[string]OperationType{
+	_OperationTypeName[0:11]:  OperationTypeAddProducts,
+	_OperationTypeName[11:22]: OperationTypeUpdateState,
+	_OperationTypeName[22:36]: OperationTypeDeleteProducts,
+	_OperationTypeName[36:49]: OperationTypeReorderStates,
+}
+
+// ParseOperationType attempts to convert a string to a OperationType.
+func ParseOperationType(name string) (OperationType, error) {
+	if x, ok := _OperationTypeValue[name]; ok {
+		return x, nil
+	}
+	return OperationType(0), fmt.Errorf("%s is %w", name, ErrInvalidOperationType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x OperationType) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *OperationType) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseOperationType(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errOperationTypeNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *OperationType) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = OperationType(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = OperationType(v)
+	case string:
+		*x, err = ParseOperationType(v)
+	case []byte:
+		*x, err = ParseOperationType(string(v))
+	case OperationType:
+		*x = v
+	case int:
+		*x = OperationType(v)
+	case *OperationType:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = OperationType(v)
+	case uint64:
+		*x = OperationType(v)
+	case *int:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = OperationType(*v)
+	case *int64:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = OperationType(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = OperationType(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = OperationType(*v)
+	case *uint:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = OperationType(*v)
+	case *uint64:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x = OperationType(*v)
+	case *string:
+		if v == nil {
+			return errOperationTypeNilPtr
+		}
+		*x, err = ParseOperationType(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x OperationType) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+const (
+	// SchedulePeriodWeekly is a SchedulePeriod of type Weekly.
+	SchedulePeriodWeekly SchedulePeriod = iota + 1
+	// SchedulePeriodMonthly is a SchedulePeriod of type Monthly.
+	SchedulePeriodMonthly
+	// SchedulePeriodCron is a SchedulePeriod of type Cron.
+	SchedulePeriodCron
+)
+
+var ErrInvalidSchedulePeriod = fmt.Errorf("not a valid SchedulePeriod, try [%s]", strings.Join(_SchedulePeriodNames, ", "))
+
+const _SchedulePeriodName = "weeklymonthlycron"
+
+var _SchedulePeriodNames = []string{
+	_SchedulePeriodName[0:6],
+	_SchedulePeriodName[6:13],
+	_SchedulePeriodName[13:17],
+}
+
+// SchedulePeriodNames returns a list of possible string values of SchedulePeriod.
+func SchedulePeriodNames() []string {
+	tmp := make([]string, len(_SchedulePeriodNames))
+	copy(tmp, _SchedulePeriodNames)
+	return tmp
+}
+
+// SchedulePeriodValues returns a list of the values for SchedulePeriod
+func SchedulePeriodValues() []SchedulePeriod {
+	return []SchedulePeriod{
+		SchedulePeriodWeekly,
+		SchedulePeriodMonthly,
+		SchedulePeriodCron,
+	}
+}
+
+var _SchedulePeriodMap = map[SchedulePeriod]string{
+	SchedulePeriodWeekly:  _SchedulePeriodName[0:6],
+	SchedulePeriodMonthly: _SchedulePeriodName[6:13],
+	SchedulePeriodCron:    _SchedulePeriodName[13:17],
+}
+
+// String implements the Stringer interface.
+func (x SchedulePeriod) String() string {
+	if str, ok := _SchedulePeriodMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("SchedulePeriod(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SchedulePeriod) IsValid() bool {
+	_, ok := _SchedulePeriodMap[x]
+	return ok
+}
+
+var _SchedulePeriodValue = map[string]SchedulePeriod{
+	_SchedulePeriodName[0:6]:   SchedulePeriodWeekly,
+	_SchedulePeriodName[6:13]:  SchedulePeriodMonthly,
+	_SchedulePeriodName[13:17]: SchedulePeriodCron,
+}
+
+// ParseSchedulePeriod attempts to convert a string to a SchedulePeriod.
+func ParseSchedulePeriod(name string) (SchedulePeriod, error) {
+	if x, ok := _SchedulePeriodValue[name]; ok {
+		return x, nil
+	}
+	return SchedulePeriod(0), fmt.Errorf("%s is %w", name, ErrInvalidSchedulePeriod)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SchedulePeriod) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SchedulePeriod) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseSchedulePeriod(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errSchedulePeriodNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *SchedulePeriod) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = SchedulePeriod(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = SchedulePeriod(v)
+	case string:
+		*x, err = ParseSchedulePeriod(v)
+	case []byte:
+		*x, err = ParseSchedulePeriod(string(v))
+	case SchedulePeriod:
+		*x = v
+	case int:
+		*x = SchedulePeriod(v)
+	case *SchedulePeriod:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = SchedulePeriod(v)
+	case uint64:
+		*x = SchedulePeriod(v)
+	case *int:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = SchedulePeriod(*v)
+	case *int64:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = SchedulePeriod(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = SchedulePeriod(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = SchedulePeriod(*v)
+	case *uint:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = SchedulePeriod(*v)
+	case *uint64:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x = SchedulePeriod(*v)
+	case *string:
+		if v == nil {
+			return errSchedulePeriodNilPtr
+		}
+		*x, err = ParseSchedulePeriod(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x SchedulePeriod) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
+const (
+	// SpendingPeriodDay is a SpendingPeriod of type Day.
+	SpendingPeriodDay SpendingPeriod = iota + 1
+	// SpendingPeriodWeek is a SpendingPeriod of type Week.
+	SpendingPeriodWeek
+	// SpendingPeriodMonth is a SpendingPeriod of type Month.
+	SpendingPeriodMonth
+)
+
+var ErrInvalidSpendingPeriod = fmt.Errorf("not a valid SpendingPeriod, try [%s]", strings.Join(_SpendingPeriodNames, ", "))
+
+const _SpendingPeriodName = "dayweekmonth"
+
+var _SpendingPeriodNames = []string{
+	_SpendingPeriodName[0:3],
+	_SpendingPeriodName[3:7],
+	_SpendingPeriodName[7:12],
+}
+
+// SpendingPeriodNames returns a list of possible string values of SpendingPeriod.
+func SpendingPeriodNames() []string {
+	tmp := make([]string, len(_SpendingPeriodNames))
+	copy(tmp, _SpendingPeriodNames)
+	return tmp
+}
+
+// SpendingPeriodValues returns a list of the values for SpendingPeriod
+func SpendingPeriodValues() []SpendingPeriod {
+	return []SpendingPeriod{
+		SpendingPeriodDay,
+		SpendingPeriodWeek,
+		SpendingPeriodMonth,
+	}
+}
+
+var _SpendingPeriodMap = map[SpendingPeriod]string{
+	SpendingPeriodDay:   _SpendingPeriodName[0:3],
+	SpendingPeriodWeek:  _SpendingPeriodName[3:7],
+	SpendingPeriodMonth: _SpendingPeriodName[7:12],
+}
+
+// String implements the Stringer interface.
+func (x SpendingPeriod) String() string {
+	if str, ok := _SpendingPeriodMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("SpendingPeriod(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x SpendingPeriod) IsValid() bool {
+	_, ok := _SpendingPeriodMap[x]
+	return ok
+}
+
+var _SpendingPeriodValue = map[string]SpendingPeriod{
+	_SpendingPeriodName[0:3]:  SpendingPeriodDay,
+	_SpendingPeriodName[3:7]:  SpendingPeriodWeek,
+	_SpendingPeriodName[7:12]: SpendingPeriodMonth,
+}
+
+// ParseSpendingPeriod attempts to convert a string to a SpendingPeriod.
+func ParseSpendingPeriod(name string) (SpendingPeriod, error) {
+	if x, ok := _SpendingPeriodValue[name]; ok {
+		return x, nil
+	}
+	return SpendingPeriod(0), fmt.Errorf("%s is %w", name, ErrInvalidSpendingPeriod)
+}
+
+// MarshalText implements the text marshaller method.
+func (x SpendingPeriod) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *SpendingPeriod) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseSpendingPeriod(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errSpendingPeriodNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *SpendingPeriod) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = SpendingPeriod(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = SpendingPeriod(v)
+	case string:
+		*x, err = ParseSpendingPeriod(v)
+	case []byte:
+		*x, err = ParseSpendingPeriod(string(v))
+	case SpendingPeriod:
+		*x = v
+	case int:
+		*x = SpendingPeriod(v)
+	case *SpendingPeriod:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = SpendingPeriod(v)
+	case uint64:
+		*x = SpendingPeriod(v)
+	case *int:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = SpendingPeriod(*v)
+	case *int64:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = SpendingPeriod(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = SpendingPeriod(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = SpendingPeriod(*v)
+	case *uint:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = SpendingPeriod(*v)
+	case *uint64:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x = SpendingPeriod(*v)
+	case *string:
+		if v == nil {
+			return errSpendingPeriodNilPtr
+		}
+		*x, err = ParseSpendingPeriod(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x SpendingPeriod) Value() (driver.Value, error) {
+	return x.String(), nil
+}
+
 const (
 	// StateStatusWaiting is a StateStatus of type Waiting.
 	StateStatusWaiting StateStatus = iota + 1
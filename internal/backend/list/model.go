@@ -9,7 +9,9 @@ import (
 	"github.com/samber/mo"
 
 	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
 	"go-backend/internal/backend/user"
+	"go-backend/pkg/cron"
 	"go-backend/pkg/date"
 	"go-backend/pkg/id"
 	"go-backend/pkg/myerr"
@@ -24,24 +26,85 @@ type StateStatus int
 type ExecStatus int32
 
 type ProductStateReplacement struct {
-	Count     mo.Option[int32] `json:"count" swaggertype:"number" extensions:"x-nullable"`
-	FormIndex mo.Option[int32] `json:"form_idx" swaggertype:"number" extensions:"x-nullable"`
-	Product   product.Product  `json:"product"`
+	Quantity  mo.Option[product.Quantity] `json:"quantity" extensions:"x-nullable"`
+	FormIndex mo.Option[int32]            `json:"form_idx" swaggertype:"number" extensions:"x-nullable"`
+	Product   product.Product             `json:"product"`
+	// Price is an actual price paid for the replacement
+	Price mo.Option[Price] `json:"price" extensions:"x-nullable"`
 }
 
 type ProductStateOptions struct {
-	Count       mo.Option[int32]                   `json:"count" swaggertype:"number" extensions:"x-nullable"`
+	Quantity    mo.Option[product.Quantity]        `json:"quantity" extensions:"x-nullable"`
 	FormIndex   mo.Option[int32]                   `json:"form_idx" swaggertype:"number" extensions:"x-nullable"`
 	Status      StateStatus                        `json:"status" swaggertype:"string"`
 	Replacement mo.Option[ProductStateReplacement] `json:"replacement"`
+	Note        string                             `json:"note"`
+	// Price is an actual price paid for taken product
+	Price mo.Option[Price] `json:"price" extensions:"x-nullable"`
+}
+
+// Comment is a message in a thread of a list item
+type Comment struct {
+	ID        id.ID[Comment]   `json:"id" swaggertype:"string"`
+	Author    id.ID[user.User] `json:"author" swaggertype:"string"`
+	Text      string           `json:"text" validate:"required,max=4096"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// CommentOptions is a content of new comment
+type CommentOptions struct {
+	Text string `json:"text"`
+}
+
+// AttachmentTypes are types of images which can be attached, types are detected by content of images.
+// SVG isn't allowed, as it can run scripts when it's opened by a browser.
+var AttachmentTypes = []string{"image/png", "image/jpeg", "image/webp"}
+
+// Attachment is an image attached to a list item, its content is kept in a blob store
+type Attachment struct {
+	ID          id.ID[Attachment] `json:"id" swaggertype:"string"`
+	Author      id.ID[user.User]  `json:"author" swaggertype:"string"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	CreatedAt   time.Time         `json:"created_at"`
+	// BlobKey is a key of the content in the blob store, it's kept when the item is moved to another list
+	BlobKey string `json:"-"`
+}
+
+// AttachmentKey returns blob key of content of attachment added to the list
+func AttachmentKey(listID id.ID[ProductList], attachmentID id.ID[Attachment]) string {
+	return "lists/" + listID.String() + "/" + attachmentID.String()
 }
 
 type ProductState struct {
 	ProductStateOptions
 
-	Product   product.Product               `json:"product"`
-	CreatedAt date.CreateDate[ProductState] `json:"created_at"`
-	UpdatedAt date.UpdateDate[ProductState] `json:"updated_at"`
+	Product product.Product `json:"product"`
+	// Assignee is a member who fetches the product during shopping
+	Assignee    mo.Option[id.ID[user.User]]   `json:"assignee" swaggertype:"string" extensions:"x-nullable"`
+	Comments    []Comment                     `json:"comments"`
+	Attachments []Attachment                  `json:"attachments"`
+	CreatedAt   date.CreateDate[ProductState] `json:"created_at"`
+	UpdatedAt   date.UpdateDate[ProductState] `json:"updated_at"`
+	// PurchasedAt is a time when product became taken or replaced
+	PurchasedAt mo.Option[time.Time] `json:"purchased_at" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// Purchased reports whether product is taken or replaced
+func (s ProductState) Purchased() bool {
+	return s.Status == StateStatusTaken || s.Status == StateStatusReplaced
+}
+
+// Spent returns price paid for purchased product, it's a price of the replacement if product is replaced
+func (s ProductState) Spent() mo.Option[Price] {
+	switch s.Status {
+	case StateStatusTaken:
+		return s.Price
+	case StateStatusReplaced:
+		return s.Replacement.OrEmpty().Price
+	default:
+		return mo.None[Price]()
+	}
 }
 
 // ENUM(owner=1,admin,editor,executing,viewer)
@@ -72,6 +135,8 @@ func NewZeroMember() Member {
 type ListOptions struct { //nolint
 	Status ExecStatus `json:"status" swaggertype:"string"`
 	Title  string     `json:"title"`
+	// ShopMapID is a default shop map of the list, new states are inserted by order of its categories
+	ShopMapID mo.Option[id.ID[shopmap.ShopMap]] `json:"shop_map_id" swaggertype:"string" extensions:"x-nullable"`
 }
 
 type ProductList struct {
@@ -97,6 +162,101 @@ func (l ProductList) CheckRole(userID id.ID[user.User], role MemberType) (Member
 	return member, nil
 }
 
+// Spent sums prices of products purchased since given time by currencies
+func (l ProductList) Spent(since time.Time) []Price {
+	var prices []Price
+
+	for _, state := range l.States {
+		price, found := state.Spent().Get()
+		if found && !state.PurchasedAt.OrEmpty().Before(since) {
+			prices = append(prices, price)
+		}
+	}
+
+	return SumPrices(prices)
+}
+
+// AssignedTo returns the list with states assigned to given user only
+func (l ProductList) AssignedTo(userID id.ID[user.User]) ProductList {
+	l.States = slices.DeleteFunc(slices.Clone(l.States), func(state ProductState) bool {
+		return state.Assignee.OrEmpty() != userID
+	})
+
+	return l
+}
+
+// AssignOptions sets assignee of product state, state is released if Assignee is null
+type AssignOptions struct {
+	Assignee mo.Option[id.ID[user.User]] `json:"assignee" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// ENUM(updatedAt=1, createdAt, title)
+type ListSort int32
+
+// ListFilter selects summaries of lists of a user.
+// Lists are sorted by Sort, recently updated or created lists go first, titles are sorted alphabetically.
+type ListFilter struct {
+	Status mo.Option[ExecStatus]
+	// Role is a role of the user in selected lists
+	Role mo.Option[MemberType]
+	// Search is a text whose every word must be found in titles of selected lists
+	Search string
+	Sort   ListSort
+	// Cursor is a position after the last list of the previous page, the first page is returned if it's empty
+	Cursor string
+	Limit  int
+}
+
+// ListSummary is a lightweight view of a list without its states and members
+type ListSummary struct {
+	ID        id.ID[ProductList]                `json:"id" swaggertype:"string"`
+	Title     string                            `json:"title"`
+	Status    ExecStatus                        `json:"status" swaggertype:"string"`
+	ShopMapID mo.Option[id.ID[shopmap.ShopMap]] `json:"shop_map_id" swaggertype:"string" extensions:"x-nullable"`
+	// Role is a role of the user who requested the summary
+	Role MemberType `json:"role" swaggertype:"string"`
+	// Counts are numbers of items by their statuses, absent statuses have no items
+	Counts      map[StateStatus]int `json:"counts" swaggertype:"object,integer"`
+	ItemCount   int                 `json:"item_count"`
+	MemberCount int                 `json:"member_count"`
+	// Spent are sums of prices of purchased products by currencies
+	Spent     []Price   `json:"spent"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListPage is a page of list summaries, NextCursor is empty on the last page
+type ListPage struct {
+	Lists      []ListSummary `json:"lists"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// MergeOptions selects lists whose products and members are added to the target list
+type MergeOptions struct {
+	SourceIDs []id.ID[ProductList] `json:"source_ids" validate:"required,min=1,max=20,unique" swaggertype:"array,string"`
+	TargetID  id.ID[ProductList]   `json:"target_id" swaggertype:"string"`
+	// DeleteSources moves source lists to the trash after merge, it requires owner role in every source list
+	DeleteSources bool `json:"delete_sources"`
+}
+
+// SplitOptions selects products moved from a list to a new one
+type SplitOptions struct {
+	ProductIDs []id.ID[product.Product] `json:"product_ids" validate:"required,min=1,unique" swaggertype:"array,string"`
+	// Title is a title of the new list, the title of source list is used if it's empty
+	Title string `json:"title" validate:"max=255"`
+}
+
+// ListsUpdate is a change of several lists made at once
+type ListsUpdate struct {
+	// Updated are changed lists, only lists selected for update can be changed
+	Updated []ProductList
+	// Created are new lists
+	Created []ProductList
+	// Deleted are ids of lists moved to the trash at DeletedAt, only lists selected for update can be deleted
+	Deleted   []id.ID[ProductList]
+	DeletedAt time.Time
+}
+
 type FullUpdateChange struct {
 	change
 	ProductList
@@ -120,8 +280,15 @@ type ListOptionsChange struct { //nolint:revive
 	NewOptions ListOptions `json:"new_options"` // done
 }
 
+// ListDeletedChange is sent when list is moved to the trash, it keeps "deleted" name of the event for clients
 type ListDeletedChange struct{ change } //nolint:revive // done
 
+// ListRestoredChange is sent when list is restored from the trash
+type ListRestoredChange struct {
+	change
+	ProductList
+}
+
 type MembersAddedChange struct {
 	change
 
@@ -147,6 +314,13 @@ type StatesReorderedChange struct {
 	IDs []id.ID[product.Product] `json:"ids"`
 }
 
+// BatchChange holds changes of every operation applied by a single batch in the order of operations
+type BatchChange struct {
+	change
+
+	Changes []Change `json:"changes"`
+}
+
 // ENUM(full=1,
 // productsAdded,
 // productsRemoved,
@@ -155,9 +329,82 @@ type StatesReorderedChange struct {
 // optsUpdated,
 // deleted,
 // statesReordered,
-// stateUpdated)
+// stateUpdated,
+// batch,
+// undone,
+// redone,
+// sessionStarted,
+// sessionFinished,
+// stateAssigned,
+// commentAdded,
+// commentDeleted,
+// attachmentAdded,
+// attachmentDeleted,
+// restored,
+// budgetExceeded)
 type EventType int32
 
+// AssigneeChange is sent when product state is assigned to a member or released
+type AssigneeChange struct {
+	change
+
+	ProductID id.ID[product.Product]      `json:"product_id" swaggertype:"string"`
+	Assignee  mo.Option[id.ID[user.User]] `json:"assignee" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// CommentAddedChange is sent when comment is added to a list item
+type CommentAddedChange struct {
+	change
+
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	Comment   Comment                `json:"comment"`
+}
+
+// CommentDeletedChange is sent when comment of a list item is deleted
+type CommentDeletedChange struct {
+	change
+
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	CommentID id.ID[Comment]         `json:"comment_id" swaggertype:"string"`
+}
+
+// AttachmentAddedChange is sent when image is attached to a list item
+type AttachmentAddedChange struct {
+	change
+
+	ProductID  id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	Attachment Attachment             `json:"attachment"`
+}
+
+// AttachmentDeletedChange is sent when attachment of a list item is deleted
+type AttachmentDeletedChange struct {
+	change
+
+	ProductID    id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	AttachmentID id.ID[Attachment]      `json:"attachment_id" swaggertype:"string"`
+}
+
+// BudgetExceededChange is sent when a purchase in a list under execution pushes spending of a budget over its limit.
+// Category is a category whose limit is exceeded, the limit of the whole budget is exceeded when it's absent.
+// UserIDs are the owner and members of the budget, only they get the alert.
+type BudgetExceededChange struct {
+	change
+
+	BudgetID uuid.UUID                   `json:"budget_id" swaggertype:"string"`
+	UserIDs  []id.ID[user.User]          `json:"-"`
+	Title    string                      `json:"title"`
+	Category mo.Option[product.Category] `json:"category" swaggertype:"string" extensions:"x-nullable"`
+	Limit    Price                       `json:"limit"`
+	Spent    Price                       `json:"spent"`
+}
+
+// SessionChange is sent when shopping session of a list is started or finished
+type SessionChange struct {
+	change
+
+	Session Session `json:"session"`
+}
+
 type change interface {
 	isChange()
 }
@@ -167,12 +414,317 @@ type Change struct {
 	Type EventType `json:"type"`
 }
 
+// ChangeMeta describes who changes a list and how, it's stored in the history of the list
+type ChangeMeta struct {
+	Author id.ID[user.User]
+	Type   EventType
+	At     time.Time
+}
+
+// UndoRecord keeps list before and after a single change made by Author, it's used to undo and redo changes
+type UndoRecord struct {
+	ID     string
+	Author id.ID[user.User]
+	Type   EventType
+	Before ProductList
+	After  ProductList
+}
+
+// HistoryEntry is a single change of a list.
+// ID is a commit hash for DoltDB or an id of change log record for other databases.
+type HistoryEntry struct {
+	ID        string             `json:"id"`
+	ListID    id.ID[ProductList] `json:"list_id" swaggertype:"string"`
+	Author    id.ID[user.User]   `json:"author" swaggertype:"string"`
+	Type      EventType          `json:"type" swaggertype:"string"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
 type Event struct {
 	ListID id.ID[ProductList] `json:"list_id"`
 	Member *Member            `json:"member"`
 	Change Change             `json:"change"`
 }
 
+// ENUM(addProducts=1, updateState, deleteProducts, reorderStates)
+type OperationType int32
+
+// Operation is a single mutation of a product list inside a batch.
+// Only fields related to the Type of the operation are used.
+type Operation struct {
+	Type OperationType `json:"type" swaggertype:"string"`
+	// Products are new product states for addProducts
+	Products map[id.ID[product.Product]]ProductStateOptions `json:"products,omitempty"`
+	// ProductID is an id of product state for updateState
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	// State is a new state for updateState
+	State ProductStateOptions `json:"state"`
+	// IDs are product ids for deleteProducts or new order of states for reorderStates
+	IDs []id.ID[product.Product] `json:"ids,omitempty" swaggertype:"array,string"`
+}
+
+// BatchResult is a result of applied batch, Results[i] is a change made by i-th operation
+type BatchResult struct {
+	List    ProductList `json:"list"`
+	Results []Change    `json:"results"`
+}
+
+// Session is a shopping trip over a list, it starts when the list moves to processing and finishes with archiving.
+type Session struct {
+	ID        id.ID[Session]            `json:"id" swaggertype:"string"`
+	ListID    id.ID[ProductList]        `json:"list_id" swaggertype:"string"`
+	Shoppers  []id.ID[user.User]        `json:"shoppers" swaggertype:"array,string"`
+	StartedAt time.Time                 `json:"started_at"`
+	Summary   mo.Option[SessionSummary] `json:"summary"`
+	// Spent are sums of prices of products purchased during the session by currencies,
+	// they're running totals until the session is finished
+	Spent []Price `json:"spent"`
+}
+
+// SessionSummary is stored when session is finished
+type SessionSummary struct {
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer"`
+	Taken      int           `json:"taken"`
+	Missed     int           `json:"missed"`
+	Replaced   int           `json:"replaced"`
+	// NextListID is an id of a new planning list with missed items, if they were rolled over
+	NextListID mo.Option[id.ID[ProductList]] `json:"next_list_id" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// StartOptions are options of new shopping session, the user who starts it is a shopper when Shoppers are empty
+type StartOptions struct {
+	Shoppers []id.ID[user.User] `json:"shoppers" swaggertype:"array,string"`
+}
+
+// FinishOptions are options of finishing shopping session
+type FinishOptions struct {
+	// RollOverMissed moves missed items into a new planning list
+	RollOverMissed bool `json:"roll_over_missed"`
+}
+
+// SessionUpdate is a list and its session changed together.
+// NextList is created in the same transaction if it's present.
+type SessionUpdate struct {
+	List     ProductList
+	Session  Session
+	NextList mo.Option[ProductList]
+}
+
+// ImportOptions are options of import of pasted plain-text list.
+// Text is parsed into a preview, if Commit is true confirmed Items of the preview are appended instead.
+type ImportOptions struct {
+	Text   string `json:"text" validate:"required_unless=Commit true,max=65536"`
+	Commit bool   `json:"commit"`
+	// Items are confirmed items of the preview, they're appended in their order
+	Items []ImportConfirmedItem `json:"items" validate:"required_if=Commit true"`
+}
+
+// ImportConfirmedItem is an item of import preview confirmed by user.
+// A new product is created by name of the item if product id is absent.
+type ImportConfirmedItem struct {
+	product.ParsedLine
+
+	ProductID mo.Option[id.ID[product.Product]] `json:"product_id" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// ImportItem is a parsed line of imported text and a product matched by its name.
+// Confidence is 0 for products which are created by import.
+type ImportItem struct {
+	product.ParsedLine
+	product.Match
+}
+
+// ImportResult is a preview of import, List is present if import is committed
+type ImportResult struct {
+	Items []ImportItem           `json:"items"`
+	List  mo.Option[ProductList] `json:"list" extensions:"x-nullable"`
+}
+
+// ENUM(md=1, csv, txt, pdf)
+type ExportFormat int32
+
+// ContentType returns MIME type of list exported in format f
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatMd:
+		return "text/markdown; charset=utf-8"
+	case ExportFormatCsv:
+		return "text/csv; charset=utf-8"
+	case ExportFormatPdf:
+		return "application/pdf"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// TemplateItem is a product of a list template, it has no status, assignee and thread
+type TemplateItem struct {
+	Product   product.Product             `json:"product"`
+	Quantity  mo.Option[product.Quantity] `json:"quantity" extensions:"x-nullable"`
+	FormIndex mo.Option[int32]            `json:"form_idx" swaggertype:"number" extensions:"x-nullable"`
+	Note      string                      `json:"note"`
+}
+
+// TemplateOptions are editable options of a list template.
+// Members are added to every list created from the template, the owner of the template isn't among them.
+type TemplateOptions struct {
+	Title     string                            `json:"title" validate:"required,max=255"`
+	ShopMapID mo.Option[id.ID[shopmap.ShopMap]] `json:"shop_map_id" swaggertype:"string" extensions:"x-nullable"`
+	Members   []MemberOptions                   `json:"members" validate:"unique=UserID"`
+}
+
+// Template is a reusable content of a list without members and statuses
+type Template struct {
+	TemplateOptions
+
+	ID        id.ID[Template]  `json:"id" swaggertype:"string"`
+	OwnerID   id.ID[user.User] `json:"owner_id" swaggertype:"string"`
+	Items     []TemplateItem   `json:"items"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CheckAccess returns error if user is neither the owner nor a member of the template
+func (t Template) CheckAccess(userID id.ID[user.User]) error {
+	if t.OwnerID == userID || slices.ContainsFunc(t.Members, func(m MemberOptions) bool { return m.UserID == userID }) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: user %s is not a member of template %s", myerr.ErrForbidden, userID, t.ID)
+}
+
+// ENUM(weekly=1, monthly, cron)
+type SchedulePeriod int32
+
+// ScheduleOptions define when lists are created from a template.
+// Weekly schedules use Weekday and Time, monthly ones use Day and Time and cron ones use Cron expression.
+type ScheduleOptions struct {
+	Period SchedulePeriod `json:"period" swaggertype:"string"`
+	// Weekday is a day of week from 0 (sunday) to 6 (saturday)
+	Weekday int `json:"weekday" validate:"min=0,max=6"`
+	// Day is a day of month, months without the day are skipped
+	Day int `json:"day" validate:"min=0,max=31"`
+	// Time is a time of day like 09:00
+	Time string `json:"time"`
+	// Cron is an expression of five fields like "0 9 * * fri"
+	Cron string `json:"cron" validate:"max=255"`
+	// Timezone is a name of IANA time zone like Europe/Berlin, UTC is used if it's empty
+	Timezone string `json:"timezone" validate:"max=64"`
+}
+
+// Expression returns cron expression of the schedule
+func (o ScheduleOptions) Expression() (string, error) {
+	if o.Period == SchedulePeriodCron {
+		return o.Cron, nil
+	}
+
+	at, err := time.Parse("15:04", o.Time)
+	if err != nil {
+		return "", fmt.Errorf("%w: time must be like 09:00", myerr.ErrInvalidArgument)
+	}
+
+	switch o.Period {
+	case SchedulePeriodWeekly:
+		return fmt.Sprintf("%d %d * * %d", at.Minute(), at.Hour(), o.Weekday), nil
+	case SchedulePeriodMonthly:
+		if o.Day == 0 {
+			return "", fmt.Errorf("%w: day of monthly schedule is required", myerr.ErrInvalidArgument)
+		}
+
+		return fmt.Sprintf("%d %d %d * *", at.Minute(), at.Hour(), o.Day), nil
+	default:
+		return "", fmt.Errorf("%w: unknown schedule period %d", myerr.ErrInvalidArgument, o.Period)
+	}
+}
+
+// Next returns the first time after t when the schedule fires
+func (o ScheduleOptions) Next(t time.Time) (time.Time, error) {
+	expr, err := o.Expression()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	spec, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	loc, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: unknown time zone %q", myerr.ErrInvalidArgument, o.Timezone)
+	}
+
+	next := spec.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: schedule never fires", myerr.ErrInvalidArgument)
+	}
+
+	return next, nil
+}
+
+// Schedule creates lists from a template, they are owned by OwnerID
+type Schedule struct {
+	ScheduleOptions
+
+	ID         id.ID[Schedule]               `json:"id" swaggertype:"string"`
+	TemplateID id.ID[Template]               `json:"template_id" swaggertype:"string"`
+	OwnerID    id.ID[user.User]              `json:"owner_id" swaggertype:"string"`
+	NextRunAt  time.Time                     `json:"next_run_at"`
+	LastRunAt  mo.Option[time.Time]          `json:"last_run_at" swaggertype:"string" extensions:"x-nullable"`
+	LastListID mo.Option[id.ID[ProductList]] `json:"last_list_id" swaggertype:"string" extensions:"x-nullable"`
+	CreatedAt  time.Time                     `json:"created_at"`
+}
+
+// ENUM(day=1, week, month)
+type SpendingPeriod int32
+
+// Start returns the beginning of UTC period which contains t, weeks start on Monday
+func (p SpendingPeriod) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch p {
+	case SpendingPeriodWeek:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+
+		return day.AddDate(0, 0, -daysSinceMonday)
+	case SpendingPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case SpendingPeriodDay:
+		return day
+	default:
+		return day
+	}
+}
+
+// SpendingFilter selects purchases made from From till To in lists of a user, spending is grouped by Period
+type SpendingFilter struct {
+	From   time.Time
+	To     time.Time
+	Period SpendingPeriod
+}
+
+// Purchase is a price paid for a product of a list, Category is a category of the replacement for replaced product
+type Purchase struct {
+	ListID      id.ID[ProductList]
+	ShopMapID   mo.Option[id.ID[shopmap.ShopMap]]
+	Category    mo.Option[product.Category]
+	PurchasedAt time.Time
+	Price       Price
+}
+
+// Spending is money spent during a period on products of a category in lists with a default shop map
+type Spending struct {
+	Price
+
+	PeriodStart time.Time                         `json:"period_start"`
+	Category    mo.Option[product.Category]       `json:"category" swaggertype:"string" extensions:"x-nullable"`
+	ShopMapID   mo.Option[id.ID[shopmap.ShopMap]] `json:"shop_map_id" swaggertype:"string" extensions:"x-nullable"`
+	// Items is a number of purchased products
+	Items int `json:"items"`
+}
+
 type RoleCheckFunc func([]Member) error
 
 func CheckRole(userID id.ID[user.User], role MemberType) (RoleCheckFunc, <-chan Member) {
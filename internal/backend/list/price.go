@@ -0,0 +1,74 @@
+package list
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/shopspring/decimal"
+
+	"go-backend/pkg/myerr"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+const (
+	// MaxPriceScale is the number of digits after the decimal point a price can have
+	MaxPriceScale = 4
+	// MaxPriceDigits is the number of digits before the decimal point, amounts and their sums are stored as text
+	// of 32 characters, so they must stay far shorter
+	MaxPriceDigits = 12
+)
+
+// maxPriceAmount is the smallest amount which has too many digits
+var maxPriceAmount = decimal.New(1, MaxPriceDigits)
+
+// Price is an amount of money paid for a product, Currency is an ISO 4217 code
+type Price struct {
+	Amount   decimal.Decimal `json:"amount" swaggertype:"string"`
+	Currency string          `json:"currency"`
+}
+
+// Validate checks that amount isn't negative, has at most MaxPriceDigits digits before the point
+// and MaxPriceScale after it, and currency is a code of three uppercase letters
+func (p Price) Validate() error {
+	if p.Amount.IsNegative() {
+		return fmt.Errorf("%w: price can't be negative, got %s", myerr.ErrInvalidArgument, p.Amount)
+	}
+
+	if p.Amount.Cmp(maxPriceAmount) >= 0 {
+		return fmt.Errorf("%w: price must have at most %d digits before the point, got %s",
+			myerr.ErrInvalidArgument, MaxPriceDigits, p.Amount)
+	}
+
+	if !p.Amount.Truncate(MaxPriceScale).Equal(p.Amount) {
+		return fmt.Errorf("%w: price must have at most %d digits after the point, got %s",
+			myerr.ErrInvalidArgument, MaxPriceScale, p.Amount)
+	}
+
+	if !currencyPattern.MatchString(p.Currency) {
+		return fmt.Errorf("%w: currency must be ISO 4217 code, got '%s'", myerr.ErrInvalidArgument, p.Currency)
+	}
+
+	return nil
+}
+
+// SumPrices adds up prices of the same currency, sums are sorted by currency
+func SumPrices(prices []Price) []Price {
+	sums := make([]Price, 0)
+
+	for _, price := range prices {
+		idx := slices.IndexFunc(sums, func(sum Price) bool { return sum.Currency == price.Currency })
+		if idx < 0 {
+			sums = append(sums, price)
+			continue
+		}
+
+		sums[idx].Amount = sums[idx].Amount.Add(price.Amount)
+	}
+
+	slices.SortFunc(sums, func(a, b Price) int { return cmp.Compare(a.Currency, b.Currency) })
+
+	return sums
+}
@@ -0,0 +1,91 @@
+package list_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/myerr"
+)
+
+func TestPriceValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		price list.Price
+		err   error
+	}{
+		{
+			name:  "valid",
+			price: list.Price{Amount: decimal.RequireFromString("12.30"), Currency: "EUR"},
+		},
+		{
+			name:  "free",
+			price: list.Price{Amount: decimal.Zero, Currency: "USD"},
+		},
+		{
+			name:  "negative amount",
+			price: list.Price{Amount: decimal.RequireFromString("-0.01"), Currency: "EUR"},
+			err:   myerr.ErrInvalidArgument,
+		},
+		{
+			name:  "trailing zeros",
+			price: list.Price{Amount: decimal.RequireFromString("0.12340000"), Currency: "EUR"},
+		},
+		{
+			name:  "largest amount",
+			price: list.Price{Amount: decimal.RequireFromString("999999999999.9999"), Currency: "EUR"},
+		},
+		{
+			name:  "too many digits",
+			price: list.Price{Amount: decimal.RequireFromString("1000000000000"), Currency: "EUR"},
+			err:   myerr.ErrInvalidArgument,
+		},
+		{
+			name:  "exponent",
+			price: list.Price{Amount: decimal.RequireFromString("1e40"), Currency: "EUR"},
+			err:   myerr.ErrInvalidArgument,
+		},
+		{
+			name:  "too precise",
+			price: list.Price{Amount: decimal.RequireFromString("0.00001"), Currency: "EUR"},
+			err:   myerr.ErrInvalidArgument,
+		},
+		{
+			name:  "lowercase currency",
+			price: list.Price{Amount: decimal.RequireFromString("1"), Currency: "eur"},
+			err:   myerr.ErrInvalidArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorIs(t, tc.price.Validate(), tc.err)
+		})
+	}
+}
+
+func TestSumPrices(t *testing.T) {
+	sums := list.SumPrices([]list.Price{
+		{Amount: decimal.RequireFromString("0.10"), Currency: "USD"},
+		{Amount: decimal.RequireFromString("1.5"), Currency: "EUR"},
+		{Amount: decimal.RequireFromString("0.20"), Currency: "USD"},
+	})
+
+	require.Len(t, sums, 2)
+	require.Equal(t, "EUR", sums[0].Currency)
+	require.True(t, decimal.RequireFromString("1.5").Equal(sums[0].Amount))
+	require.Equal(t, "USD", sums[1].Currency)
+	require.True(t, decimal.RequireFromString("0.3").Equal(sums[1].Amount))
+}
+
+func TestSpendingPeriodStart(t *testing.T) {
+	// Sunday evening in UTC+3 is still Sunday in UTC
+	at := time.Date(2026, time.October, 18, 23, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	require.Equal(t, time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), list.SpendingPeriodDay.Start(at))
+	require.Equal(t, time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC), list.SpendingPeriodWeek.Start(at))
+	require.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), list.SpendingPeriodMonth.Start(at))
+}
@@ -0,0 +1,354 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/mymysql"
+)
+
+// MergeProducts replaces sources by the target in states, templates and purchase histories,
+// it joins the transaction of ctx. Items of merged products in the same list, template and history are merged
+// into one, undo records of lists are rewritten the same way. It returns lists which had sources
+// and active ones of them.
+func (r *Repo) MergeProducts(
+	ctx context.Context,
+	merge dedup.Merge,
+) (
+	[]id.ID[list.ProductList],
+	[]id.ID[list.ProductList],
+	error,
+) {
+	m := productRepo.NewMerged(merge)
+
+	var listIDs, active []string
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		var err error
+		if listIDs, err = mergeStates(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err = mergeTemplateItems(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if err = mergeHistories(ctx, tx, m); err != nil {
+			return err
+		}
+
+		if len(listIDs) == 0 {
+			return nil
+		}
+
+		if err = mergeUndoRecords(ctx, tx, m, listIDs); err != nil {
+			return err
+		}
+
+		err = tx.WithContext(ctx).Model(new(ProductList)).
+			Where("id IN ? AND status <> ?", listIDs, int32(list.ExecStatusArchived)).
+			Pluck("id", &active).Error
+		if err != nil {
+			return fmt.Errorf("can't select active lists of %v: %w", listIDs, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return toListIDs(listIDs), toListIDs(active), nil
+}
+
+func toListIDs(rawIDs []string) []id.ID[list.ProductList] {
+	return lo.Map(rawIDs, func(item string, _ int) id.ID[list.ProductList] {
+		return id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(item))}
+	})
+}
+
+// mergeStates merges states of merged products in each list, threads of dropped states follow kept ones.
+// It returns lists which had states or replacements of sources.
+func mergeStates(ctx context.Context, tx *gorm.DB, m productRepo.Merged) ([]string, error) {
+	var listIDs []string
+
+	err := tx.WithContext(ctx).Model(new(ProductListState)).
+		Distinct().
+		Where("product_id IN ? OR replacement_product_id IN ?", m.Sources, m.Sources).
+		Pluck("list_id", &listIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select lists with merged products: %w", err)
+	}
+
+	states, err := productRepo.LoadMerged[ProductListState](ctx, tx, m, "list_id", "`index`")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, state := range states {
+		states[i].FormIdx = m.FormIdx(state.ProductID, state.FormIdx)
+	}
+
+	kept, dropped := productRepo.CombineMerged(
+		states,
+		m,
+		func(item *ProductListState) (string, *string) { return item.ListID, &item.ProductID },
+		func(kept, other ProductListState) ProductListState {
+			kept.Quantity = productRepo.QuantityToEntity(dedup.SumQuantities(
+				productRepo.QuantityToModel(kept.Quantity, kept.Count),
+				productRepo.QuantityToModel(other.Quantity, other.Count),
+			))
+			kept.Count = nil
+
+			return kept
+		},
+	)
+
+	keptIDs := lo.SliceToMap(kept, func(item ProductListState) (string, string) {
+		return item.ListID, item.ID
+	})
+
+	for _, state := range dropped {
+		for _, thread := range []any{new(ProductListComment), new(ProductListAttachment)} {
+			err = tx.WithContext(ctx).Model(thread).
+				Where("state_id = ?", state.ID).
+				Update("state_id", keptIDs[state.ListID]).Error
+			if err != nil {
+				return nil, fmt.Errorf("can't move thread of state %s: %w", state.ID, err)
+			}
+		}
+	}
+
+	err = productRepo.SaveMerged(ctx, tx, kept, dropped, func(item ProductListState) string { return item.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	if err = renumberStates(ctx, tx, lo.Uniq(lo.Map(dropped, func(item ProductListState, _ int) string {
+		return item.ListID
+	}))); err != nil {
+		return nil, err
+	}
+
+	var replaced []ProductListState
+
+	err = tx.WithContext(ctx).Select("id", "replacement_product_id", "replacement_form_idx").
+		Where("replacement_product_id IN ?", m.Sources).
+		Find(&replaced).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select states replaced by merged products: %w", err)
+	}
+
+	for _, state := range replaced {
+		err = tx.WithContext(ctx).Model(new(ProductListState)).
+			Where("id = ?", state.ID).
+			Updates(map[string]any{
+				"replacement_product_id": m.Target,
+				"replacement_form_idx":   m.FormIdx(*state.ReplacementProductID, state.ReplacementFormIdx),
+			}).Error
+		if err != nil {
+			return nil, fmt.Errorf("can't update replacement of state %s: %w", state.ID, err)
+		}
+	}
+
+	return listIDs, nil
+}
+
+// renumberStates closes gaps in indexes of states left by dropped states, indexes are positions in lists
+func renumberStates(ctx context.Context, tx *gorm.DB, listIDs []string) error {
+	if len(listIDs) == 0 {
+		return nil
+	}
+
+	var states []ProductListState
+
+	err := tx.WithContext(ctx).Select("id", "list_id", "`index`").
+		Where("list_id IN ?", listIDs).
+		Order("list_id").
+		Order("`index`").
+		Find(&states).Error
+	if err != nil {
+		return fmt.Errorf("can't select states of lists %v: %w", listIDs, err)
+	}
+
+	for _, group := range lo.PartitionBy(states, func(item ProductListState) string { return item.ListID }) {
+		for i, state := range group {
+			if state.Index == int64(i) {
+				continue
+			}
+
+			err = tx.WithContext(ctx).Model(new(ProductListState)).
+				Where("id = ?", state.ID).
+				Update("index", i).Error
+			if err != nil {
+				return fmt.Errorf("can't move state %s: %w", state.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func mergeTemplateItems(ctx context.Context, tx *gorm.DB, m productRepo.Merged) error {
+	items, err := productRepo.LoadMerged[ProductListTemplateItem](ctx, tx, m, "template_id", "`index`")
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		items[i].FormIdx = m.FormIdx(item.ProductID, item.FormIdx)
+	}
+
+	kept, dropped := productRepo.CombineMerged(
+		items,
+		m,
+		func(item *ProductListTemplateItem) (string, *string) { return item.TemplateID, &item.ProductID },
+		func(kept, other ProductListTemplateItem) ProductListTemplateItem {
+			kept.Quantity = productRepo.SumMerged(kept.Quantity, other.Quantity)
+			return kept
+		},
+	)
+
+	return productRepo.SaveMerged(ctx, tx, kept, dropped, func(item ProductListTemplateItem) string { return item.ID })
+}
+
+func mergeHistories(ctx context.Context, tx *gorm.DB, m productRepo.Merged) error {
+	histories, err := productRepo.LoadMerged[ProductListPurchaseHistory](ctx, tx, m, "user_id", "first_at")
+	if err != nil {
+		return err
+	}
+
+	kept, _ := productRepo.CombineMerged(
+		histories,
+		m,
+		func(item *ProductListPurchaseHistory) (string, *string) { return item.UserID, &item.ProductID },
+		func(kept, other ProductListPurchaseHistory) ProductListPurchaseHistory {
+			kept.Count += other.Count
+			kept.FirstAt = lo.Ternary(other.FirstAt.Before(kept.FirstAt), other.FirstAt, kept.FirstAt)
+			kept.LastAt = lo.Ternary(other.LastAt.After(kept.LastAt), other.LastAt, kept.LastAt)
+
+			return kept
+		},
+	)
+
+	return productRepo.ReplaceMerged(ctx, tx, m, "user_id", kept, func(item ProductListPurchaseHistory) string {
+		return item.UserID
+	})
+}
+
+// mergeUndoRecords replaces sources by the target in snapshots of undo records of the lists,
+// so merged changes can still be undone
+func mergeUndoRecords(ctx context.Context, tx *gorm.DB, m productRepo.Merged, listIDs []string) error {
+	var records []ProductListUndoRecord
+
+	if err := tx.WithContext(ctx).Where("list_id IN ?", listIDs).Find(&records).Error; err != nil {
+		return fmt.Errorf("can't select undo records of lists %v: %w", listIDs, err)
+	}
+
+	for _, record := range records {
+		before, err := mergeSnapshot(record.Before, m)
+		if err != nil {
+			return fmt.Errorf("can't merge products of undo record %s: %w", record.ID, err)
+		}
+
+		after, err := mergeSnapshot(record.After, m)
+		if err != nil {
+			return fmt.Errorf("can't merge products of undo record %s: %w", record.ID, err)
+		}
+
+		err = tx.WithContext(ctx).Model(&record).
+			Updates(map[string]any{"before": before, "after": after}).Error
+		if err != nil {
+			return fmt.Errorf("can't save undo record %s: %w", record.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// mergeSnapshot replaces sources by the target in the encoded snapshot. States of merged products are merged
+// as in the list, the state of the target or the first one is kept in its position.
+func mergeSnapshot(encoded []byte, m productRepo.Merged) ([]byte, error) {
+	var snapshot undoSnapshot
+	if err := json.Unmarshal(encoded, &snapshot); err != nil {
+		return nil, err
+	}
+
+	target := m.Plan.Target
+	isMerged := func(productID id.ID[product.Product]) bool {
+		return productID == target.ID || slices.Contains(m.Plan.SourceIDs, productID)
+	}
+
+	keptIdx := slices.IndexFunc(snapshot.States, func(s list.ProductState) bool { return s.Product.ID == target.ID })
+	if keptIdx == -1 {
+		keptIdx = slices.IndexFunc(snapshot.States, func(s list.ProductState) bool { return isMerged(s.Product.ID) })
+	}
+
+	states := make([]list.ProductState, 0, len(snapshot.States))
+
+	for i, state := range snapshot.States {
+		if replacement, found := state.Replacement.Get(); found && isMerged(replacement.Product.ID) {
+			replacement.FormIndex = m.Plan.FormIdx(replacement.Product.ID, replacement.FormIndex)
+			replacement.Product = target
+			state.Replacement = mo.Some(replacement)
+		}
+
+		if !isMerged(state.Product.ID) || i == keptIdx {
+			states = append(states, state)
+		}
+	}
+
+	if keptIdx != -1 {
+		kept := &states[slices.IndexFunc(states, func(s list.ProductState) bool { return isMerged(s.Product.ID) })]
+		kept.FormIndex = m.Plan.FormIdx(kept.Product.ID, kept.FormIndex)
+
+		for i, other := range snapshot.States {
+			if i == keptIdx || !isMerged(other.Product.ID) {
+				continue
+			}
+
+			kept.Quantity = dedup.SumQuantities(kept.Quantity, other.Quantity)
+			kept.Comments = append(kept.Comments, other.Comments...)
+			kept.Attachments = append(kept.Attachments, other.Attachments...)
+		}
+
+		kept.Product = target
+	}
+
+	snapshot.States = states
+
+	if snapshot.Order != nil {
+		keptID, found := lo.Find(snapshot.Order, func(item id.ID[product.Product]) bool { return item == target.ID })
+		if !found {
+			keptID, _ = lo.Find(snapshot.Order, isMerged)
+		}
+
+		order := make([]id.ID[product.Product], 0, len(snapshot.Order))
+		for _, productID := range snapshot.Order {
+			switch {
+			case !isMerged(productID):
+				order = append(order, productID)
+			case productID == keptID:
+				order = append(order, target.ID)
+			}
+		}
+
+		snapshot.Order = order
+	}
+
+	return json.Marshal(snapshot)
+}
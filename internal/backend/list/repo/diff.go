@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+)
+
+// rowDiff is a set of statements required to turn old rows of a table into new ones
+type rowDiff[T any] struct {
+	toInsert []T
+	toUpdate []T
+	toDelete []string
+}
+
+// diffRows matches rows by key, new rows inherit primary keys of matched old rows,
+// so row identity is kept between updates
+func diffRows[T any](
+	oldRows []T,
+	newRows []T,
+	key func(T) string,
+	rowID func(*T) *string,
+	equal func(T, T) bool,
+) rowDiff[T] {
+	diff := rowDiff[T]{toInsert: []T{}, toUpdate: []T{}, toDelete: []string{}}
+
+	oldByKey := make(map[string]T, len(oldRows))
+	for _, row := range oldRows {
+		oldByKey[key(row)] = row
+	}
+
+	for _, row := range newRows {
+		oldRow, found := oldByKey[key(row)]
+		if !found {
+			diff.toInsert = append(diff.toInsert, row)
+			continue
+		}
+
+		delete(oldByKey, key(row))
+
+		*rowID(&row) = *rowID(&oldRow)
+		if !equal(oldRow, row) {
+			diff.toUpdate = append(diff.toUpdate, row)
+		}
+	}
+
+	for _, row := range oldByKey {
+		diff.toDelete = append(diff.toDelete, *rowID(&row))
+	}
+
+	return diff
+}
+
+// saveDiff turns rows of oldEntity into rows of the model changed at given time, threads of removed states
+// are removed too and threads of inserted states are restored
+func (r *Repo) saveDiff(
+	ctx context.Context,
+	tx *gorm.DB,
+	oldEntity ProductList,
+	model list.ProductList,
+	changedAt time.Time,
+) error {
+	newEntity := listToEntity(model)
+
+	members := diffRows(
+		oldEntity.Members,
+		newEntity.Members,
+		func(m ProductListMember) string { return m.UserID },
+		func(m *ProductListMember) *string { return &m.ID },
+		membersEqual,
+	)
+
+	states := diffRows(
+		oldEntity.States,
+		newEntity.States,
+		func(s ProductListState) string { return s.ProductID },
+		func(s *ProductListState) *string { return &s.ID },
+		statesEqual,
+	)
+
+	if err := saveRows(ctx, tx, members); err != nil {
+		return fmt.Errorf("can't update members of list %s: %w", newEntity.ID, err)
+	}
+
+	if len(states.toDelete) != 0 {
+		if err := removeThreads(ctx, tx, newEntity.ID, states.toDelete, changedAt); err != nil {
+			return fmt.Errorf("can't update states of list %s: %w", newEntity.ID, err)
+		}
+	}
+
+	if err := saveRows(ctx, tx, states); err != nil {
+		return fmt.Errorf("can't update states of list %s: %w", newEntity.ID, err)
+	}
+
+	if err := restoreThreads(ctx, tx, model, states.toInsert); err != nil {
+		return fmt.Errorf("can't update states of list %s: %w", newEntity.ID, err)
+	}
+
+	if oldEntity.Title == newEntity.Title && oldEntity.Status == newEntity.Status &&
+		ptrEqual(oldEntity.ShopMapID, newEntity.ShopMapID) && oldEntity.UpdatedAt.Equal(newEntity.UpdatedAt) {
+		return nil
+	}
+
+	err := tx.WithContext(ctx).
+		Model(&ProductList{ID: newEntity.ID}). //nolint:exhaustruct
+		Select("title", "status", "shop_map_id", "updated_at").
+		Updates(&newEntity).Error
+	if err != nil {
+		return fmt.Errorf("can't update product list %s: %w", newEntity.ID, err)
+	}
+
+	return nil
+}
+
+func saveRows[T any](ctx context.Context, tx *gorm.DB, diff rowDiff[T]) error {
+	if len(diff.toDelete) != 0 {
+		if err := tx.WithContext(ctx).Where("id in ?", diff.toDelete).Delete(new(T)).Error; err != nil {
+			return fmt.Errorf("can't delete %d rows: %w", len(diff.toDelete), err)
+		}
+	}
+
+	for _, row := range diff.toUpdate {
+		if err := tx.WithContext(ctx).Omit(clause.Associations).Save(&row).Error; err != nil {
+			return fmt.Errorf("can't update row: %w", err)
+		}
+	}
+
+	if len(diff.toInsert) != 0 {
+		if err := tx.WithContext(ctx).Omit(clause.Associations).Create(&diff.toInsert).Error; err != nil {
+			return fmt.Errorf("can't insert %d rows: %w", len(diff.toInsert), err)
+		}
+	}
+
+	return nil
+}
+
+func membersEqual(a, b ProductListMember) bool {
+	return a.MemberType == b.MemberType &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+func statesEqual(a, b ProductListState) bool {
+	return a.Index == b.Index &&
+		a.Status == b.Status &&
+		ptrEqual(a.Count, b.Count) &&
+		ptrEqual(a.Quantity.Value, b.Quantity.Value) &&
+		ptrEqual(a.Quantity.Unit, b.Quantity.Unit) &&
+		ptrEqual(a.ReplacementQuantity.Value, b.ReplacementQuantity.Value) &&
+		ptrEqual(a.ReplacementQuantity.Unit, b.ReplacementQuantity.Unit) &&
+		ptrEqual(a.FormIdx, b.FormIdx) &&
+		ptrEqual(a.ReplacementCount, b.ReplacementCount) &&
+		ptrEqual(a.ReplacementFormIdx, b.ReplacementFormIdx) &&
+		ptrEqual(a.ReplacementProductID, b.ReplacementProductID) &&
+		ptrEqual(a.Price.Amount, b.Price.Amount) &&
+		ptrEqual(a.Price.Currency, b.Price.Currency) &&
+		ptrEqual(a.ReplacementPrice.Amount, b.ReplacementPrice.Amount) &&
+		ptrEqual(a.ReplacementPrice.Currency, b.ReplacementPrice.Currency) &&
+		timePtrEqual(a.PurchasedAt, b.PurchasedAt) &&
+		ptrEqual(a.AssigneeID, b.AssigneeID) &&
+		a.Note == b.Note &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+func timePtrEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
@@ -15,9 +15,11 @@ import (
 	"go-backend/internal/backend/list"
 	"go-backend/internal/backend/product"
 	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/shopmap"
 	"go-backend/internal/backend/user"
 	"go-backend/internal/backend/user/repo"
 	"go-backend/pkg/date"
+	"go-backend/pkg/dbtx"
 	"go-backend/pkg/god"
 	"go-backend/pkg/id"
 	"go-backend/pkg/myerr"
@@ -25,20 +27,30 @@ import (
 )
 
 type ProductListState struct {
-	ID                   string              `gorm:"primaryKey;size:36;notNull"`
-	ProductID            string              `gorm:"notNull"`
-	Product              productRepo.Product `gorm:"references:ID"`
-	ListID               string              `gorm:"size:36;notNull"`
-	CreatedAt            time.Time           `gorm:"notNull"`
-	UpdatedAt            time.Time           `gorm:"notNull"`
-	Index                int64               `gorm:"notNull"`
+	ID        string              `gorm:"primaryKey;size:36;notNull"`
+	ProductID string              `gorm:"notNull"`
+	Product   productRepo.Product `gorm:"references:ID"`
+	ListID    string              `gorm:"size:36;notNull"`
+	CreatedAt time.Time           `gorm:"notNull"`
+	UpdatedAt time.Time           `gorm:"notNull"`
+	Index     int64               `gorm:"notNull"`
+	// Count is a number of pieces, it's kept to read states saved before quantities with units
 	Count                *int32
+	Quantity             productRepo.Quantity `gorm:"embedded;embeddedPrefix:quantity_"`
 	FormIdx              *int32
 	Status               int `gorm:"notNull"`
 	ReplacementCount     *int32
+	ReplacementQuantity  productRepo.Quantity `gorm:"embedded;embeddedPrefix:replacement_quantity_"`
 	ReplacementFormIdx   *int32
 	ReplacementProduct   *productRepo.Product `gorm:"references:ID"`
 	ReplacementProductID *string
+	Price                Price                   `gorm:"embedded;embeddedPrefix:price_"`
+	ReplacementPrice     Price                   `gorm:"embedded;embeddedPrefix:replacement_price_"`
+	PurchasedAt          *time.Time              `gorm:"index"`
+	AssigneeID           *string                 `gorm:"size:36"`
+	Note                 string                  `gorm:"notNull;default:''"`
+	Comments             []ProductListComment    `gorm:"foreignKey:StateID;constraint:OnDelete:CASCADE"`
+	Attachments          []ProductListAttachment `gorm:"foreignKey:StateID;constraint:OnDelete:CASCADE"`
 }
 
 type ProductListMember struct {
@@ -57,117 +69,127 @@ type ProductList struct {
 	UpdatedAt time.Time           `gorm:"notNull"`
 	CreatedAt time.Time           `gorm:"notNull"`
 	Title     string              `gorm:"notNull,size:255"`
+	ShopMapID *string             `gorm:"size:36"`
 	Members   []ProductListMember `gorm:"foreignKey:ListID;constraint:OnDelete:CASCADE"`
 	States    []ProductListState  `gorm:"foreignKey:ListID"`
+	// DeletedAt is set when list is moved to the trash, such lists aren't selected by default
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	DeletedBy *string        `gorm:"size:36"`
 }
 
 type Repo struct {
-	db *gorm.DB
+	db      *gorm.DB
+	history history
 }
 
 func NewRepo(ctx context.Context, db *gorm.DB) (*Repo, error) {
-	err := db.WithContext(ctx).AutoMigrate(new(ProductList), new(ProductListMember), new(ProductListState))
+	err := db.WithContext(ctx).AutoMigrate(
+		new(ProductList),
+		new(ProductListMember),
+		new(ProductListState),
+		new(ProductListUndoRecord),
+		new(ProductListSession),
+		new(ProductListSessionSpending),
+		new(ProductListComment),
+		new(ProductListAttachment),
+		new(ProductListRemovedAttachment),
+		new(ProductListTemplate),
+		new(ProductListTemplateMember),
+		new(ProductListTemplateItem),
+		new(ProductListSchedule),
+		new(ProductListPurchaseHistory),
+		new(ProductListCountedList),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("can't create product list tables: %w", err)
 	}
 
-	return &Repo{db: db}, nil
-}
-
-func (r *Repo) GetListMetaByUserID(ctx context.Context, userID id.ID[user.User]) ([]list.ProductList, error) {
-	var relatedListIDs []string
-	var lists []ProductList
-
-	//nolint:exhaustruct
-	err := r.db.WithContext(ctx).Model(&ProductListMember{}).
-		Where(&ProductListMember{UserID: userID.String()}).
-		Pluck("list_id", &relatedListIDs).Error
-	if err != nil {
-		return nil, fmt.Errorf("can't find ids of lists related to user %s: %w", userID, err)
+	if err = fillBlobKeys(ctx, db); err != nil {
+		return nil, err
 	}
 
-	err = r.db.WithContext(ctx).
-		Preload("Members").
-		Preload("Members.User").
-		Preload("States").
-		Preload("States.Product.Forms").
-		Preload("States.Product.Category").
-		Preload("States.ReplacementProduct").
-		Preload("States.ReplacementProduct.Category").
-		Where("id in ?", relatedListIDs).
-		Find(&lists).Error
+	history, err := newHistory(ctx, db)
 	if err != nil {
-		return nil, fmt.Errorf("can't select lists related to user %s: %w", userID, err)
+		return nil, err
 	}
 
-	return lo.Map(lists, func(item ProductList, _ int) list.ProductList { return entityToModel(item) }), nil
+	return &Repo{db: db, history: history}, nil
 }
 
 func (r *Repo) GetByListID(ctx context.Context, listID id.ID[list.ProductList]) (list.ProductList, error) {
 	return r.getProductList(ctx, r.db, listID)
 }
 
-func (r *Repo) CreateList(ctx context.Context, model list.ProductList) error {
-	err := r.db.WithContext(ctx).Create(lo.ToPtr(listToEntity(model))).Error
+func (r *Repo) CreateList(ctx context.Context, model list.ProductList, meta list.ChangeMeta) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		return r.createList(ctx, tx, model, meta)
+	})
 	if err != nil {
-		return fmt.Errorf("can't insert new product list %s: %w", model.ID, err)
+		return fmt.Errorf("transaction failed: %w", err)
 	}
 
 	return nil
 }
 
+func (r *Repo) createList(ctx context.Context, tx *gorm.DB, model list.ProductList, meta list.ChangeMeta) error {
+	if err := tx.WithContext(ctx).Create(lo.ToPtr(listToEntity(model))).Error; err != nil {
+		return fmt.Errorf("can't insert new product list %s: %w", model.ID, err)
+	}
+
+	return r.history.commit(ctx, tx, model, meta)
+}
+
+func (r *Repo) GetHistory(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	offset, limit int,
+) (
+	[]list.HistoryEntry,
+	error,
+) {
+	return r.history.log(ctx, r.db, listID, offset, limit)
+}
+
+// GetAsOf returns list as it was at given point, point is a revision id or RFC3339 timestamp
+func (r *Repo) GetAsOf(ctx context.Context, listID id.ID[list.ProductList], point string) (list.ProductList, error) {
+	return r.history.asOf(ctx, r.db, listID, point)
+}
+
 func (r *Repo) GetAndUpdate(
 	ctx context.Context,
 	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
 	updateFunc func(list.ProductList) (list.ProductList, error),
 ) (
 	list.ProductList,
 	error,
 ) {
 	var model list.ProductList
-	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var err error
-
-		model, err = r.getProductList(ctx, tx, listID)
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		oldEntity, err := r.getEntity(ctx, tx, listID)
 		if err != nil {
 			return err
 		}
 
-		model, err = updateFunc(model)
+		model, err = updateFunc(entityToModel(oldEntity))
 		if err != nil {
 			return err
 		}
 
-		entity := listToEntity(model)
-		query := ProductList{ID: listID.String()} //nolint:exhaustruct
-
-		err = tx.WithContext(ctx).Where("list_id = ?", listID).Delete(&ProductListMember{}).Error
-		if err != nil {
+		if err = r.saveDiff(ctx, tx, oldEntity, model, meta.At); err != nil {
 			return err
 		}
 
-		err = tx.WithContext(ctx).Where("list_id = ?", listID).Delete(&ProductListState{}).Error
+		model, err = r.getProductList(ctx, tx, listID)
 		if err != nil {
 			return err
 		}
 
-		err = tx.WithContext(ctx).Model(&query).Association("Members").Unscoped().Replace(entity.Members)
-		if err != nil {
-			return fmt.Errorf("can't update members of list %s: %w", listID, err)
-		}
-
-		err = tx.WithContext(ctx).Model(&query).Association("States").Unscoped().Replace(entity.States)
-		if err != nil {
-			return fmt.Errorf("can't update states of list %s: %w", listID, err)
-		}
-
-		err = tx.WithContext(ctx).Model(&query).Updates(&entity).Error
-		if err != nil {
-			return fmt.Errorf("can't update product list %s: %w", listID, err)
+		if err = saveUndoRecord(ctx, tx, entityToModel(oldEntity), model, meta); err != nil {
+			return err
 		}
 
-		model, err = r.getProductList(ctx, tx, listID)
-		return err
+		return r.history.commit(ctx, tx, model, meta)
 	})
 	if err != nil {
 		return list.ProductList{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
@@ -176,49 +198,80 @@ func (r *Repo) GetAndUpdate(
 	return model, nil
 }
 
-func (r *Repo) GetAndDeleteList(
-	ctx context.Context,
-	listID id.ID[list.ProductList],
-	validateFunc func(list.ProductList) error,
-) error {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
-		model, err := r.getProductList(ctx, tx, listID)
+// Transaction calls f in one transaction, updates of lists and other repositories called with ctx of f join it
+func (r *Repo) Transaction(ctx context.Context, f func(context.Context) error) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, _ *gorm.DB) error {
+		return f(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
+
+// Commit bumps update dates of lists changed by other repositories and saves their revisions,
+// e.g. of lists whose products are merged. It joins the transaction of ctx, lists in the trash are committed too.
+func (r *Repo) Commit(ctx context.Context, listIDs []id.ID[list.ProductList], meta list.ChangeMeta) error {
+	if len(listIDs) == 0 {
+		return nil
+	}
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		rawIDs := lo.Map(listIDs, func(item id.ID[list.ProductList], _ int) string { return item.String() })
+
+		err := tx.WithContext(ctx).Unscoped().Model(new(ProductList)).
+			Where("id IN ?", rawIDs).
+			Update("updated_at", meta.At).Error
 		if err != nil {
-			return err
+			return fmt.Errorf("can't update lists %v: %w", listIDs, err)
 		}
 
-		if err = validateFunc(model); err != nil {
-			return err
-		}
+		for _, listID := range listIDs {
+			model, err := r.getProductList(ctx, tx.Unscoped(), listID)
+			if err != nil {
+				return err
+			}
 
-		//nolint:exhaustruct
-		err = tx.WithContext(ctx).Delete(&ProductList{ID: listID.String()}).Error
-		if err != nil {
-			return fmt.Errorf("can't delete product list %s: %w", listID, err)
+			if err = r.history.commit(ctx, tx, model, meta); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("transaction failed: %w", err)
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
 	}
 
 	return nil
 }
 
+// ApplyOrder sets order of states returned by orderFunc for the current list
 func (r *Repo) ApplyOrder(
 	ctx context.Context,
 	validateFunc list.RoleCheckFunc,
 	listID id.ID[list.ProductList],
-	ids []id.ID[product.Product],
-) error {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
-		members, err := r.getMembers(ctx, tx, listID)
+	meta list.ChangeMeta,
+	orderFunc func(list.ProductList) ([]id.ID[product.Product], error),
+) (
+	list.ProductList,
+	error,
+) {
+	var after list.ProductList
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		before, err := r.getProductList(ctx, tx, listID)
 		if err != nil {
-			return fmt.Errorf("failed to get product list members: %w", err)
+			return err
 		}
 
-		if err = validateFunc(members); err != nil {
+		if err = validateFunc(before.Members); err != nil {
+			return err
+		}
+
+		ids, err := orderFunc(before)
+		if err != nil {
 			return err
 		}
 
@@ -242,13 +295,22 @@ func (r *Repo) ApplyOrder(
 			return fmt.Errorf("can't apply order to DoltDB: %w", err)
 		}
 
-		return nil
+		after, err = r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		if err = saveUndoRecord(ctx, tx, before, after, meta); err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, after, meta)
 	})
 	if err != nil {
-		return fmt.Errorf("transaction failed: %w", err)
+		return list.ProductList{}, fmt.Errorf("transaction failed: %w", err)
 	}
 
-	return nil
+	return after, nil
 }
 
 func buildApplyOrderQeuery(ids []id.ID[product.Product]) string {
@@ -274,29 +336,31 @@ func buildApplyOrderQeuery(ids []id.ID[product.Product]) string {
 	return builder.String()
 }
 
-func (r *Repo) getMembers(ctx context.Context, tx *gorm.DB, listID id.ID[list.ProductList]) ([]list.Member, error) {
-	var members []ProductListMember
-
-	err := tx.WithContext(ctx).
-		Preload("User").
-		Where(&ProductListMember{ListID: listID.String()}). // nolint:exhaustruct
-		Find(&members).Error
-
+func (r *Repo) getProductList(ctx context.Context, tx *gorm.DB, listID id.ID[list.ProductList]) (
+	list.ProductList, error,
+) {
+	entity, err := r.getEntity(ctx, tx, listID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query members of list %s: %w", listID, err)
+		return list.ProductList{}, err
 	}
 
-	return lo.Map(members, func(m ProductListMember, _ int) list.Member {
-		return memberToModel(m)
-	}), nil
+	return entityToModel(entity), nil
 }
 
-func (r *Repo) getProductList(ctx context.Context, tx *gorm.DB, listID id.ID[list.ProductList]) (
-	list.ProductList, error,
-) {
+func (r *Repo) getEntity(ctx context.Context, tx *gorm.DB, listID id.ID[list.ProductList]) (ProductList, error) {
 	entity := ProductList{ID: listID.String()} //nolint:exhaustruct
 
-	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+	err := preloadList(tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"})).Find(&entity).Error
+	if err != nil {
+		return entity, fmt.Errorf("can't select product list %s: %w", listID, err)
+	}
+
+	return entity, nil
+}
+
+// preloadList selects lists with their members, states, products of states and threads
+func preloadList(db *gorm.DB) *gorm.DB {
+	return db.
 		Preload("Members").
 		Preload("Members.User").
 		Preload("States").
@@ -304,12 +368,8 @@ func (r *Repo) getProductList(ctx context.Context, tx *gorm.DB, listID id.ID[lis
 		Preload("States.Product.Category").
 		Preload("States.ReplacementProduct").
 		Preload("States.ReplacementProduct.Category").
-		Find(&entity).Error
-	if err != nil {
-		return list.ProductList{}, fmt.Errorf("can't select product list %s: %w", listID, err)
-	}
-
-	return entityToModel(entity), nil
+		Preload("States.Comments", orderByCreation).
+		Preload("States.Attachments", orderByCreation)
 }
 
 func entityToModel(entity ProductList) list.ProductList {
@@ -326,6 +386,10 @@ func entityToModel(entity ProductList) list.ProductList {
 		ListOptions: list.ListOptions{
 			Status: list.ExecStatus(entity.Status),
 			Title:  entity.Title,
+			ShopMapID: lo.If(entity.ShopMapID == nil, mo.None[id.ID[shopmap.ShopMap]]()).
+				ElseF(func() mo.Option[id.ID[shopmap.ShopMap]] {
+					return mo.Some(id.ID[shopmap.ShopMap]{UUID: god.Believe(uuid.Parse(*entity.ShopMapID))})
+				}),
 		},
 		ID:        id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(entity.ID))},
 		UpdatedAt: date.UpdateDate[list.ProductList]{Time: entity.UpdatedAt},
@@ -349,22 +413,34 @@ func stateToModel(entity ProductListState) list.ProductState {
 	var replacement *list.ProductStateReplacement
 	if entity.ReplacementProductID != nil {
 		replacement = &list.ProductStateReplacement{
-			Count:     mo.PointerToOption(entity.ReplacementCount),
+			Quantity:  productRepo.QuantityToModel(entity.ReplacementQuantity, entity.ReplacementCount),
 			FormIndex: mo.PointerToOption(entity.ReplacementFormIdx),
 			Product:   productRepo.EntityToModel(*entity.ReplacementProduct),
+			Price:     priceToModel(entity.ReplacementPrice),
 		}
 	}
 
 	return list.ProductState{
 		ProductStateOptions: list.ProductStateOptions{
-			Count:       mo.PointerToOption(entity.Count),
+			Quantity:    productRepo.QuantityToModel(entity.Quantity, entity.Count),
 			FormIndex:   mo.PointerToOption(entity.FormIdx),
 			Status:      list.StateStatus(entity.Status),
 			Replacement: mo.PointerToOption(replacement),
+			Note:        entity.Note,
+			Price:       priceToModel(entity.Price),
 		},
-		Product:   productRepo.EntityToModel(entity.Product),
-		CreatedAt: date.CreateDate[list.ProductState]{Time: entity.CreatedAt},
-		UpdatedAt: date.UpdateDate[list.ProductState]{Time: entity.UpdatedAt},
+		Product: productRepo.EntityToModel(entity.Product),
+		Assignee: lo.If(entity.AssigneeID == nil, mo.None[id.ID[user.User]]()).
+			ElseF(func() mo.Option[id.ID[user.User]] {
+				return mo.Some(id.ID[user.User]{UUID: god.Believe(uuid.Parse(*entity.AssigneeID))})
+			}),
+		Comments: lo.Map(entity.Comments, func(item ProductListComment, _ int) list.Comment { return commentToModel(item) }),
+		Attachments: lo.Map(entity.Attachments, func(item ProductListAttachment, _ int) list.Attachment {
+			return attachmentToModel(item)
+		}),
+		CreatedAt:   date.CreateDate[list.ProductState]{Time: entity.CreatedAt},
+		UpdatedAt:   date.UpdateDate[list.ProductState]{Time: entity.UpdatedAt},
+		PurchasedAt: mo.PointerToOption(entity.PurchasedAt),
 	}
 }
 
@@ -375,12 +451,16 @@ func listToEntity(model list.ProductList) ProductList {
 		UpdatedAt: model.UpdatedAt.Time,
 		CreatedAt: model.CreatedAt.Time,
 		Title:     model.Title,
+		ShopMapID: lo.If(model.ShopMapID.IsAbsent(), (*string)(nil)).
+			Else(lo.ToPtr(model.ShopMapID.OrEmpty().String())),
 		Members: lo.Map(model.Members, func(item list.Member, _ int) ProductListMember {
 			return memberToEntity(model.ID, item)
 		}),
 		States: lo.Map(model.States, func(item list.ProductState, index int) ProductListState {
 			return stateToEntity(model.ID, item, int64(index))
 		}),
+		DeletedAt: gorm.DeletedAt{},
+		DeletedBy: nil,
 	}
 }
 
@@ -398,19 +478,30 @@ func memberToEntity(listID id.ID[list.ProductList], model list.Member) ProductLi
 
 func stateToEntity(listID id.ID[list.ProductList], model list.ProductState, index int64) ProductListState {
 	return ProductListState{
-		ID:                 uuid.NewString(),
-		ProductID:          model.Product.ID.String(),
-		Product:            productRepo.Product{ID: model.Product.ID.String()}, //nolint:exhaustruct
-		Count:              model.Count.ToPointer(),
-		FormIdx:            model.FormIndex.ToPointer(),
-		Status:             int(model.Status),
-		ListID:             listID.String(),
-		CreatedAt:          model.CreatedAt.Time,
-		UpdatedAt:          model.UpdatedAt.Time,
-		Index:              index,
-		ReplacementCount:   model.Replacement.OrEmpty().Count.ToPointer(),
+		ID:               uuid.NewString(),
+		ProductID:        model.Product.ID.String(),
+		Product:          productRepo.Product{ID: model.Product.ID.String()}, //nolint:exhaustruct
+		Count:            nil,
+		Quantity:         productRepo.QuantityToEntity(model.Quantity),
+		FormIdx:          model.FormIndex.ToPointer(),
+		Status:           int(model.Status),
+		ListID:           listID.String(),
+		CreatedAt:        model.CreatedAt.Time,
+		UpdatedAt:        model.UpdatedAt.Time,
+		Index:            index,
+		ReplacementCount: nil,
+		ReplacementQuantity: productRepo.QuantityToEntity(lo.If(model.Replacement.IsPresent(),
+			model.Replacement.OrEmpty().Quantity).Else(mo.None[product.Quantity]())),
 		ReplacementFormIdx: model.Replacement.OrEmpty().FormIndex.ToPointer(),
 		ReplacementProductID: lo.If(model.Replacement.IsAbsent(), (*string)(nil)).
 			Else(lo.ToPtr(model.Replacement.OrEmpty().Product.ID.String())),
+		Price:            priceToEntity(model.Price),
+		ReplacementPrice: priceToEntity(model.Replacement.OrEmpty().Price),
+		PurchasedAt:      model.PurchasedAt.ToPointer(),
+		AssigneeID: lo.If(model.Assignee.IsAbsent(), (*string)(nil)).
+			Else(lo.ToPtr(model.Assignee.OrEmpty().String())),
+		Note:        model.Note,
+		Comments:    []ProductListComment{},
+		Attachments: []ProductListAttachment{},
 	}
 }
@@ -0,0 +1,768 @@
+package repo_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestRepo(t *testing.T) {
+	suite.Run(t, new(RepoSuite))
+}
+
+type RepoSuite struct {
+	suite.Suite
+	fixture
+}
+
+type fixture struct {
+	db      *gorm.DB
+	repo    *repo.Repo
+	listID  id.ID[list.ProductList]
+	ownerID id.ID[user.User]
+}
+
+func (s *RepoSuite) SetupTest() {
+	s.fixture = newFilledList(s.T(), 3)
+}
+
+func (s *RepoSuite) meta() list.ChangeMeta {
+	return list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeStateUpdated, At: time.Now()}
+}
+
+func (s *RepoSuite) TestGetAndUpdateKeepsRows() {
+	before := s.stateRowIDs()
+
+	ctx := context.Background()
+
+	model, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.States[1].Status = list.StateStatusTaken
+		return l, nil
+	})
+	s.Require().NoError(err)
+	s.Equal(list.StateStatusTaken, model.States[1].Status)
+	s.Equal(before, s.stateRowIDs())
+}
+
+func (s *RepoSuite) TestGetAndUpdateDeletesAndReorders() {
+	before := s.stateRowIDs()
+
+	ctx := context.Background()
+
+	model, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.States = []list.ProductState{l.States[2], l.States[0]}
+		return l, nil
+	})
+	s.Require().NoError(err)
+	s.Require().Len(model.States, 2)
+
+	after := s.stateRowIDs()
+	s.Len(after, 2)
+	for productID, rowID := range after {
+		s.Equal(before[productID], rowID)
+	}
+}
+
+func (s *RepoSuite) TestHistory() {
+	ctx := context.Background()
+
+	_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.States[0].Status = list.StateStatusMissed
+		return l, nil
+	})
+	s.Require().NoError(err)
+
+	entries, err := s.repo.GetHistory(ctx, s.listID, 0, 10)
+	s.Require().NoError(err)
+	s.Require().Len(entries, 2)
+	s.Equal(list.EventTypeStateUpdated, entries[0].Type)
+	s.Equal(list.EventTypeFull, entries[1].Type)
+	s.Equal(s.ownerID, entries[0].Author)
+
+	initial, err := s.repo.GetAsOf(ctx, s.listID, entries[1].ID)
+	s.Require().NoError(err)
+	s.Equal(list.StateStatusWaiting, initial.States[0].Status)
+
+	_, err = s.repo.GetAsOf(ctx, s.listID, entries[1].CreatedAt.Add(-time.Hour).Format(time.RFC3339))
+	s.ErrorIs(err, myerr.ErrNotFound)
+}
+
+func (s *RepoSuite) TestFailedUpdateRollsBackTransaction() {
+	ctx := context.Background()
+
+	other, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+	other.ID = id.NewID[list.ProductList]()
+	s.Require().NoError(s.repo.CreateList(ctx, other, s.meta()))
+
+	err = s.repo.Transaction(ctx, func(ctx context.Context) error {
+		_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+			l.States[0].Status = list.StateStatusTaken
+			return l, nil
+		})
+		s.Require().NoError(err)
+
+		_, err = s.repo.GetAndUpdate(ctx, other.ID, s.meta(), func(list.ProductList) (list.ProductList, error) {
+			return list.ProductList{}, myerr.ErrConflict
+		})
+
+		return err
+	})
+	s.Require().ErrorIs(err, myerr.ErrConflict)
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+	s.Equal(list.StateStatusWaiting, model.States[0].Status)
+
+	entries, err := s.repo.GetHistory(ctx, s.listID, 0, 10)
+	s.Require().NoError(err)
+	s.Len(entries, 1, "revision of the rolled back update isn't kept")
+}
+
+func (s *RepoSuite) TestUndoRedo() {
+	ctx := context.Background()
+
+	_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.States[0].Status = list.StateStatusTaken
+		return l, nil
+	})
+	s.Require().NoError(err)
+
+	// records keep positions of all states, but only the changed state is stored
+	revert := func(before bool) func(list.ProductList, []list.UndoRecord) (list.ProductList, error) {
+		return func(current list.ProductList, records []list.UndoRecord) (list.ProductList, error) {
+			s.Require().Len(records, 1)
+
+			to := records[0].After
+			if before {
+				to = records[0].Before
+			}
+
+			s.Require().Equal(productIDs(current), productIDs(to))
+			s.Require().Equal(current.States[0].CreatedAt, to.States[0].CreatedAt)
+			s.Require().Zero(to.States[1].Status)
+			s.Require().Zero(to.States[2].Status)
+
+			current.States[0].ProductStateOptions = to.States[0].ProductStateOptions
+
+			return current, nil
+		}
+	}
+
+	undoMeta := list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeUndone, At: time.Now()}
+	model, err := s.repo.GetAndUndo(ctx, s.listID, undoMeta, 5, revert(true))
+	s.Require().NoError(err)
+	s.Equal(list.StateStatusWaiting, model.States[0].Status)
+
+	_, err = s.repo.GetAndUndo(ctx, s.listID, undoMeta, 1, revert(true))
+	s.Require().ErrorIs(err, myerr.ErrNotFound)
+
+	redoMeta := list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeRedone, At: time.Now()}
+	model, err = s.repo.GetAndRedo(ctx, s.listID, redoMeta, 1, revert(false))
+	s.Require().NoError(err)
+	s.Equal(list.StateStatusTaken, model.States[0].Status)
+
+	_, err = s.repo.GetAndRedo(ctx, s.listID, redoMeta, 1, revert(false))
+	s.Require().ErrorIs(err, myerr.ErrNotFound)
+}
+
+func (s *RepoSuite) TestUndoRecordsArePruned() {
+	ctx := context.Background()
+
+	for range 105 {
+		_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+			l.States[0].Status = nextStatus(l.States[0].Status)
+			return l, nil
+		})
+		s.Require().NoError(err)
+	}
+
+	var count int64
+	s.Require().NoError(s.db.Model(new(repo.ProductListUndoRecord)).Where("list_id = ?", s.listID).Count(&count).Error)
+	s.LessOrEqual(count, int64(100))
+	s.Positive(count)
+}
+
+func (s *RepoSuite) TestGetAndUpdateListsMovesThreads() {
+	ctx := context.Background()
+	before := s.stateRowIDs()
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+
+	movedID := model.States[0].Product.ID
+	comment := repo.ProductListComment{
+		ID:        id.NewID[list.Comment]().String(),
+		StateID:   before[movedID.String()],
+		AuthorID:  s.ownerID.String(),
+		Text:      "fresh one",
+		CreatedAt: time.Now(),
+	}
+	s.Require().NoError(s.db.Create(&comment).Error)
+
+	listIDs := []id.ID[list.ProductList]{s.listID}
+	update, err := s.repo.GetAndUpdateLists(ctx, listIDs, s.meta(), func(lists []list.ProductList) (
+		list.ListsUpdate,
+		error,
+	) {
+		source := lists[0]
+		created := source
+		created.ID = id.NewID[list.ProductList]()
+		created.States = source.States[:1]
+		source.States = source.States[1:]
+
+		return list.ListsUpdate{Updated: []list.ProductList{source}, Created: []list.ProductList{created}}, nil
+	})
+	s.Require().NoError(err)
+
+	s.Require().Len(update.Created, 1)
+	s.Require().Len(update.Created[0].States, 1)
+	s.Equal(movedID, update.Created[0].States[0].Product.ID)
+	s.Require().Len(update.Created[0].States[0].Comments, 1)
+	s.Equal("fresh one", update.Created[0].States[0].Comments[0].Text)
+
+	s.Require().Len(update.Updated, 1)
+	s.Len(update.Updated[0].States, 2)
+	s.NotContains(s.stateRowIDs(), movedID.String())
+}
+
+func (s *RepoSuite) TestGetSummariesPages() {
+	ctx := context.Background()
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+
+	for _, title := range []string{"Weekly shopping", "Party 50% off"} {
+		model.ID = id.NewID[list.ProductList]()
+		model.Title = title
+		model.States = model.States[:1]
+		s.Require().NoError(s.repo.CreateList(ctx, model, s.meta()))
+	}
+
+	filter := list.ListFilter{Sort: list.ListSortTitle, Limit: 2}
+
+	page, err := s.repo.GetSummaries(ctx, s.ownerID, filter)
+	s.Require().NoError(err)
+	s.Require().Len(page.Lists, 2)
+	s.Equal("Party 50% off", page.Lists[0].Title)
+	s.Equal(1, page.Lists[0].ItemCount)
+	s.Equal(1, page.Lists[0].MemberCount)
+	s.Equal(list.MemberTypeOwner, page.Lists[0].Role)
+	s.NotEmpty(page.NextCursor)
+
+	filter.Cursor = page.NextCursor
+	page, err = s.repo.GetSummaries(ctx, s.ownerID, filter)
+	s.Require().NoError(err)
+	s.Require().Len(page.Lists, 1)
+	s.Equal("benchmark", page.Lists[0].Title)
+	s.Equal(map[list.StateStatus]int{list.StateStatusWaiting: 3}, page.Lists[0].Counts)
+	s.Empty(page.NextCursor)
+
+	filter = list.ListFilter{Search: "50%", Sort: list.ListSortUpdatedAt, Limit: 10}
+	page, err = s.repo.GetSummaries(ctx, s.ownerID, filter)
+	s.Require().NoError(err)
+	s.Require().Len(page.Lists, 1)
+	s.Equal("Party 50% off", page.Lists[0].Title)
+
+	page, err = s.repo.GetSummaries(ctx, id.NewID[user.User](), list.ListFilter{Sort: list.ListSortUpdatedAt, Limit: 10})
+	s.Require().NoError(err)
+	s.Empty(page.Lists)
+}
+
+func (s *RepoSuite) TestGetSummariesPagesEqualDates() {
+	ctx := context.Background()
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+
+	// lists changed at once, e.g. by a merge, are paged by ids
+	at := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)
+	created := []string{s.listID.String()}
+	for range 4 {
+		model.ID = id.NewID[list.ProductList]()
+		model.CreatedAt = date.CreateDate[list.ProductList]{Time: at}
+		model.UpdatedAt = date.UpdateDate[list.ProductList]{Time: at}
+		s.Require().NoError(s.repo.CreateList(ctx, model, s.meta()))
+		created = append(created, model.ID.String())
+	}
+
+	for _, sort := range []list.ListSort{list.ListSortUpdatedAt, list.ListSortCreatedAt} {
+		filter := list.ListFilter{Sort: sort, Limit: 2}
+		paged := make([]string, 0, len(created))
+
+		for range len(created) {
+			page, err := s.repo.GetSummaries(ctx, s.ownerID, filter)
+			s.Require().NoError(err)
+
+			for _, summary := range page.Lists {
+				paged = append(paged, summary.ID.String())
+			}
+
+			if filter.Cursor = page.NextCursor; filter.Cursor == "" {
+				break
+			}
+		}
+
+		// the first list is the newest one, others have the same date and go by ids
+		s.Equal(s.listID.String(), paged[0], sort.String())
+		s.ElementsMatch(created, paged, sort.String())
+		s.IsNonIncreasing(paged[1:], sort.String())
+	}
+}
+
+func (s *RepoSuite) TestRunScheduleOnce() {
+	ctx := context.Background()
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+
+	template := list.Template{
+		TemplateOptions: list.TemplateOptions{Title: "weekly", Members: []list.MemberOptions{}},
+		ID:              id.NewID[list.Template](),
+		OwnerID:         s.ownerID,
+		Items:           []list.TemplateItem{{Product: model.States[0].Product}},
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	s.Require().NoError(s.repo.CreateTemplate(ctx, template))
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	schedule := list.Schedule{
+		ScheduleOptions: list.ScheduleOptions{Period: list.SchedulePeriodWeekly, Weekday: 5, Time: "09:00"},
+		ID:              id.NewID[list.Schedule](),
+		TemplateID:      template.ID,
+		OwnerID:         s.ownerID,
+		NextRunAt:       now,
+		CreatedAt:       now.Add(-time.Hour),
+	}
+	s.Require().NoError(s.repo.CreateSchedule(ctx, schedule))
+
+	due, err := s.repo.GetDueSchedules(ctx, now, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 1)
+
+	create := func(template list.Template) (list.ProductList, error) {
+		created := model
+		created.ID = id.NewID[list.ProductList]()
+		created.Title = template.Title
+
+		return created, nil
+	}
+
+	// both replicas have selected the same due schedule
+	next := now.AddDate(0, 0, 7)
+	meta := list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeFull, At: now}
+	created, err := s.repo.RunSchedule(ctx, due[0], next, meta, create)
+	s.Require().NoError(err)
+
+	_, err = s.repo.RunSchedule(ctx, due[0], next, meta, create)
+	s.Require().ErrorIs(err, myerr.ErrConflict)
+
+	schedules, err := s.repo.GetSchedules(ctx, template.ID)
+	s.Require().NoError(err)
+	s.Require().Len(schedules, 1)
+	s.True(next.Equal(schedules[0].NextRunAt))
+	s.Equal(created.ID, schedules[0].LastListID.MustGet())
+
+	due, err = s.repo.GetDueSchedules(ctx, now, 10)
+	s.Require().NoError(err)
+	s.Empty(due)
+}
+
+func (s *RepoSuite) TestTrashRestoreAndPurge() {
+	ctx := context.Background()
+
+	deletedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	noCheck := func(list.ProductList) error { return nil }
+	meta := list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeDeleted, At: time.Now()}
+
+	s.Require().NoError(s.repo.GetAndTrashList(ctx, s.listID, meta, deletedAt, noCheck))
+
+	page, err := s.repo.GetSummaries(ctx, s.ownerID, list.ListFilter{Sort: list.ListSortUpdatedAt, Limit: 10})
+	s.Require().NoError(err)
+	s.Empty(page.Lists)
+
+	items, err := s.repo.GetTrash(ctx, s.ownerID, deletedAt.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.Equal(s.listID.UUID, items[0].ID)
+	s.Equal(s.ownerID, items[0].DeletedBy)
+	s.True(deletedAt.Equal(items[0].DeletedAt))
+
+	// retention of the list is over
+	meta.Type = list.EventTypeRestored
+	_, err = s.repo.RestoreList(ctx, s.listID, deletedAt.Add(time.Hour), meta, noCheck)
+	s.Require().ErrorIs(err, myerr.ErrNotFound)
+
+	restored, err := s.repo.RestoreList(ctx, s.listID, deletedAt.Add(-time.Hour), meta, noCheck)
+	s.Require().NoError(err)
+	s.Len(restored.States, 3)
+
+	meta.Type = list.EventTypeDeleted
+	s.Require().NoError(s.repo.GetAndTrashList(ctx, s.listID, meta, deletedAt, noCheck))
+
+	purged, err := s.repo.PurgeLists(ctx, deletedAt.Add(time.Hour), 10)
+	s.Require().NoError(err)
+	s.Require().Len(purged, 1)
+	s.Empty(s.stateRowIDs())
+
+	items, err = s.repo.GetTrash(ctx, s.ownerID, deletedAt.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Empty(items)
+}
+
+func (s *RepoSuite) TestPurchases() {
+	ctx := context.Background()
+
+	purchasedAt := time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)
+
+	_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		for i, amount := range []string{"1.10", "2.25"} {
+			l.States[i].Status = list.StateStatusTaken
+			l.States[i].Price = mo.Some(list.Price{Amount: decimal.RequireFromString(amount), Currency: "EUR"})
+			l.States[i].PurchasedAt = mo.Some(purchasedAt.AddDate(0, 0, i))
+		}
+
+		l.States[2].Status = list.StateStatusReplaced
+		l.States[2].Replacement = mo.Some(list.ProductStateReplacement{
+			Product: l.States[0].Product,
+			Price:   mo.Some(list.Price{Amount: decimal.RequireFromString("3"), Currency: "USD"}),
+		})
+		l.States[2].PurchasedAt = mo.Some(purchasedAt)
+
+		return l, nil
+	})
+	s.Require().NoError(err)
+
+	model, err := s.repo.GetByListID(ctx, s.listID)
+	s.Require().NoError(err)
+	s.Require().True(model.States[0].Price.IsPresent())
+	s.True(decimal.RequireFromString("1.1").Equal(model.States[0].Price.MustGet().Amount))
+	s.True(purchasedAt.Equal(model.States[0].PurchasedAt.MustGet()))
+
+	page, err := s.repo.GetSummaries(ctx, s.ownerID, list.ListFilter{Sort: list.ListSortUpdatedAt, Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Len(page.Lists, 1)
+	s.Require().Len(page.Lists[0].Spent, 2)
+	s.Equal("EUR", page.Lists[0].Spent[0].Currency)
+	s.True(decimal.RequireFromString("3.35").Equal(page.Lists[0].Spent[0].Amount))
+	s.Equal("USD", page.Lists[0].Spent[1].Currency)
+
+	purchases, err := s.repo.GetPurchases(ctx, []id.ID[user.User]{s.ownerID}, purchasedAt, purchasedAt.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Len(purchases, 2)
+
+	strangers := []id.ID[user.User]{id.NewID[user.User]()}
+	purchases, err = s.repo.GetPurchases(ctx, strangers, purchasedAt, purchasedAt.AddDate(0, 0, 7))
+	s.Require().NoError(err)
+	s.Empty(purchases)
+}
+
+func (s *RepoSuite) TestGetListMates() {
+	ctx := context.Background()
+
+	mateID, strangerID := id.NewID[user.User](), id.NewID[user.User]()
+	mate := userRepo.User{ID: mateID.String(), Login: "mate", Hash: "", Role: int32(user.RoleUser)}
+	s.Require().NoError(s.db.Create(&mate).Error)
+
+	_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.Members = append(l.Members, list.Member{
+			MemberOptions: list.MemberOptions{UserID: mateID, Role: list.MemberTypeViewer},
+			UserName:      "",
+			CreatedAt:     date.NewCreateDate[list.Member](),
+			UpdatedAt:     date.NewUpdateDate[list.Member](),
+		})
+
+		return l, nil
+	})
+	s.Require().NoError(err)
+
+	mates, err := s.repo.GetListMates(ctx, s.ownerID, []id.ID[user.User]{mateID, strangerID})
+	s.Require().NoError(err)
+	s.Equal([]id.ID[user.User]{mateID}, mates)
+
+	meta := list.ChangeMeta{Author: s.ownerID, Type: list.EventTypeDeleted, At: time.Now()}
+	s.Require().NoError(s.repo.GetAndTrashList(ctx, s.listID, meta, time.Now(), func(list.ProductList) error {
+		return nil
+	}))
+
+	mates, err = s.repo.GetListMates(ctx, s.ownerID, []id.ID[user.User]{mateID})
+	s.Require().NoError(err)
+	s.Empty(mates, "lists in the trash aren't shared")
+}
+
+func (s *RepoSuite) TestCountPurchases() {
+	ctx := context.Background()
+
+	purchasedAt := time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)
+
+	_, err := s.repo.GetAndUpdate(ctx, s.listID, s.meta(), func(l list.ProductList) (list.ProductList, error) {
+		l.Status = list.ExecStatusArchived
+		l.States[0].Status = list.StateStatusTaken
+		l.States[0].PurchasedAt = mo.Some(purchasedAt)
+
+		return l, nil
+	})
+	s.Require().NoError(err)
+
+	listIDs, err := s.repo.GetUncountedLists(ctx, 10)
+	s.Require().NoError(err)
+	s.Equal([]id.ID[list.ProductList]{s.listID}, listIDs)
+
+	count := func(model list.ProductList, histories []list.PurchaseHistory) ([]list.PurchaseHistory, error) {
+		s.Empty(histories)
+
+		return []list.PurchaseHistory{
+			list.PurchaseHistory{UserID: s.ownerID, ProductID: model.States[0].Product.ID}.Add(purchasedAt),
+		}, nil
+	}
+
+	s.Require().NoError(s.repo.CountPurchases(ctx, s.listID, purchasedAt, count))
+	s.Require().ErrorIs(s.repo.CountPurchases(ctx, s.listID, purchasedAt, count), myerr.ErrConflict)
+
+	listIDs, err = s.repo.GetUncountedLists(ctx, 10)
+	s.Require().NoError(err)
+	s.Empty(listIDs)
+
+	histories, err := s.repo.GetPurchaseHistories(ctx, []id.ID[user.User]{s.ownerID})
+	s.Require().NoError(err)
+	s.Require().Len(histories, 1)
+	s.Equal(1, histories[0].Count)
+	s.True(purchasedAt.Equal(histories[0].LastAt))
+	s.True(histories[0].Interval.IsAbsent())
+}
+
+func (s *RepoSuite) TestNewRepoFillsBlobKeys() {
+	ctx := context.Background()
+	rows := s.stateRowIDs()
+	stateID := rows[slices.Sorted(maps.Keys(rows))[0]]
+
+	attachmentID, removedID := id.NewID[list.Attachment](), id.NewID[list.Attachment]()
+	s.Require().NoError(s.db.Create(&repo.ProductListAttachment{
+		ID:          attachmentID.String(),
+		StateID:     stateID,
+		AuthorID:    s.ownerID.String(),
+		ContentType: "image/png",
+		Size:        1,
+		CreatedAt:   time.Now(),
+		BlobKey:     "",
+	}).Error)
+	s.Require().NoError(s.db.Create(&repo.ProductListRemovedAttachment{
+		ID:        removedID.String(),
+		ListID:    s.listID.String(),
+		RemovedAt: time.Now(),
+		BlobKey:   "",
+	}).Error)
+
+	_, err := repo.NewRepo(ctx, s.db)
+	s.Require().NoError(err)
+
+	var attachment repo.ProductListAttachment
+	s.Require().NoError(s.db.First(&attachment, "id = ?", attachmentID.String()).Error)
+	s.Equal(list.AttachmentKey(s.listID, attachmentID), attachment.BlobKey)
+
+	var removed repo.ProductListRemovedAttachment
+	s.Require().NoError(s.db.First(&removed, "id = ?", removedID.String()).Error)
+	s.Equal(list.AttachmentKey(s.listID, removedID), removed.BlobKey)
+}
+
+func (s *RepoSuite) stateRowIDs() map[string]string {
+	var rows []repo.ProductListState
+	s.Require().NoError(s.db.Where("list_id = ?", s.listID.String()).Find(&rows).Error)
+
+	ids := make(map[string]string, len(rows))
+	for _, row := range rows {
+		ids[row.ProductID] = row.ID
+	}
+
+	return ids
+}
+
+func BenchmarkGetAndUpdate(b *testing.B) {
+	const listSize = 300
+
+	cases := []struct {
+		name   string
+		update func(list.ProductList) list.ProductList
+	}{
+		{
+			name: "single state",
+			update: func(l list.ProductList) list.ProductList {
+				l.States[listSize/2].Status = nextStatus(l.States[listSize/2].Status)
+				return l
+			},
+		},
+		{
+			name: "all states",
+			update: func(l list.ProductList) list.ProductList {
+				for i := range l.States {
+					l.States[i].Status = nextStatus(l.States[i].Status)
+				}
+				return l
+			},
+		},
+		{
+			name: "reorder",
+			update: func(l list.ProductList) list.ProductList {
+				slices.Reverse(l.States)
+				return l
+			},
+		},
+	}
+
+	// rewrite is the former way of saving which deletes and inserts all states, it's a baseline for diffs
+	paths := []struct {
+		name string
+		save func(*repo.Repo) saveFunc
+	}{
+		{name: "diff", save: func(r *repo.Repo) saveFunc { return r.GetAndUpdate }},
+		{name: "rewrite", save: func(r *repo.Repo) saveFunc { return r.GetAndRewrite }},
+	}
+
+	for _, c := range cases {
+		for _, path := range paths {
+			b.Run(c.name+"/"+path.name, func(b *testing.B) {
+				f := newFilledList(b, listSize)
+				meta := list.ChangeMeta{Author: f.ownerID, Type: list.EventTypeStateUpdated, At: time.Now()}
+				ctx := context.Background()
+				save := path.save(f.repo)
+
+				b.ResetTimer()
+				for range b.N {
+					_, err := save(ctx, f.listID, meta, func(l list.ProductList) (list.ProductList, error) {
+						return c.update(l), nil
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// saveFunc updates the list by updateFunc
+type saveFunc func(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	updateFunc func(list.ProductList) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+)
+
+func nextStatus(status list.StateStatus) list.StateStatus {
+	if status == list.StateStatusWaiting {
+		return list.StateStatusTaken
+	}
+
+	return list.StateStatusWaiting
+}
+
+func newFilledList(tb testing.TB, size int) fixture {
+	tb.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(
+		sqlite.Open(filepath.Join(tb.TempDir(), "list.db")),
+		&gorm.Config{Logger: logger.Discard},
+	)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if err = db.AutoMigrate(new(userRepo.User)); err != nil {
+		tb.Fatal(err)
+	}
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	listRepo, err := repo.NewRepo(ctx, db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	ownerID := id.NewID[user.User]()
+	owner := userRepo.User{ID: ownerID.String(), Login: "owner", Hash: "", Role: int32(user.RoleUser)}
+	if err = db.Create(&owner).Error; err != nil {
+		tb.Fatal(err)
+	}
+
+	model := list.ProductList{
+		ListOptions: list.ListOptions{Status: list.ExecStatusPlanning, Title: "benchmark"},
+		States:      make([]list.ProductState, 0, size),
+		Members: []list.Member{{
+			MemberOptions: list.MemberOptions{
+				UserID: ownerID,
+				Role:   list.MemberTypeOwner,
+			},
+			UserName:  "",
+			CreatedAt: date.NewCreateDate[list.Member](),
+			UpdatedAt: date.NewUpdateDate[list.Member](),
+		}},
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.NewUpdateDate[list.ProductList](),
+		CreatedAt: date.NewCreateDate[list.ProductList](),
+	}
+
+	for i := range size {
+		p := product.Product{
+			Options:   product.Options{Name: product.Name(fmt.Sprintf("product %d", i)), Forms: []product.Form{}},
+			ID:        id.NewID[product.Product](),
+			CreatedAt: date.NewCreateDate[product.Product](),
+			UpdatedAt: date.NewUpdateDate[product.Product](),
+		}
+		if _, err = products.Create(ctx, p); err != nil {
+			tb.Fatal(err)
+		}
+
+		model.States = append(model.States, list.ProductState{
+			ProductStateOptions: list.ProductStateOptions{Status: list.StateStatusWaiting},
+			Product:             p,
+			CreatedAt:           date.NewCreateDate[list.ProductState](),
+			UpdatedAt:           date.NewUpdateDate[list.ProductState](),
+		})
+	}
+
+	meta := list.ChangeMeta{Author: ownerID, Type: list.EventTypeFull, At: time.Now()}
+	if err = listRepo.CreateList(ctx, model, meta); err != nil {
+		tb.Fatal(err)
+	}
+
+	return fixture{db: db, repo: listRepo, listID: model.ID, ownerID: ownerID}
+}
+
+func productIDs(model list.ProductList) []id.ID[product.Product] {
+	ids := make([]id.ID[product.Product], 0, len(model.States))
+	for _, state := range model.States {
+		ids = append(ids, state.Product.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/id"
+)
+
+// GetAndRewrite is GetAndUpdate which saves the list as it was saved before diffs,
+// by deleting and inserting all members and states. It's a baseline for benchmarks.
+func (r *Repo) GetAndRewrite(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	updateFunc func(list.ProductList) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+) {
+	var model list.ProductList
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		oldEntity, err := r.getEntity(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		model, err = updateFunc(entityToModel(oldEntity))
+		if err != nil {
+			return err
+		}
+
+		entity := listToEntity(model)
+		query := ProductList{ID: listID.String()} //nolint:exhaustruct
+
+		if err = tx.WithContext(ctx).Where("list_id = ?", listID).Delete(&ProductListMember{}).Error; err != nil {
+			return err
+		}
+
+		if err = tx.WithContext(ctx).Where("list_id = ?", listID).Delete(&ProductListState{}).Error; err != nil {
+			return err
+		}
+
+		err = tx.WithContext(ctx).Model(&query).Association("Members").Unscoped().Replace(entity.Members)
+		if err != nil {
+			return fmt.Errorf("can't update members of list %s: %w", listID, err)
+		}
+
+		err = tx.WithContext(ctx).Model(&query).Association("States").Unscoped().Replace(entity.States)
+		if err != nil {
+			return fmt.Errorf("can't update states of list %s: %w", listID, err)
+		}
+
+		if err = tx.WithContext(ctx).Model(&query).Updates(&entity).Error; err != nil {
+			return fmt.Errorf("can't update product list %s: %w", listID, err)
+		}
+
+		model, err = r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		if err = saveUndoRecord(ctx, tx, entityToModel(oldEntity), model, meta); err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, model, meta)
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return model, nil
+}
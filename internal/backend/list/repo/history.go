@@ -0,0 +1,312 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// history stores versions of product lists
+type history interface {
+	commit(ctx context.Context, tx *gorm.DB, model list.ProductList, meta list.ChangeMeta) error
+	log(ctx context.Context, db *gorm.DB, listID id.ID[list.ProductList], offset, limit int) ([]list.HistoryEntry, error)
+	asOf(ctx context.Context, db *gorm.DB, listID id.ID[list.ProductList], point string) (list.ProductList, error)
+}
+
+// ProductListRevision is a record of the change log, used when database has no versioning
+type ProductListRevision struct {
+	ID        string    `gorm:"primaryKey;size:36;notNull"`
+	ListID    string    `gorm:"size:36;notNull;index:idx_list_revision"`
+	AuthorID  string    `gorm:"size:36;notNull"`
+	EventType int32     `gorm:"notNull"`
+	CreatedAt time.Time `gorm:"notNull;index:idx_list_revision"`
+	Snapshot  []byte    `gorm:"notNull"`
+}
+
+func newHistory(ctx context.Context, db *gorm.DB) (history, error) {
+	var version string
+	if err := db.WithContext(ctx).Raw("SELECT dolt_version()").Scan(&version).Error; err == nil && version != "" {
+		return doltHistory{db: db}, nil
+	}
+
+	if err := db.WithContext(ctx).AutoMigrate(new(ProductListRevision)); err != nil {
+		return nil, fmt.Errorf("can't create product list change log table: %w", err)
+	}
+
+	return tableHistory{}, nil
+}
+
+// tableHistory keeps snapshots of lists in the change log table
+type tableHistory struct{}
+
+func (tableHistory) commit(ctx context.Context, tx *gorm.DB, model list.ProductList, meta list.ChangeMeta) error {
+	snapshot, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("can't encode snapshot of list %s: %w", model.ID, err)
+	}
+
+	err = tx.WithContext(ctx).Create(&ProductListRevision{
+		ID:        uuid.NewString(),
+		ListID:    model.ID.String(),
+		AuthorID:  meta.Author.String(),
+		EventType: int32(meta.Type),
+		CreatedAt: meta.At,
+		Snapshot:  snapshot,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("can't save revision of list %s: %w", model.ID, err)
+	}
+
+	return nil
+}
+
+func (tableHistory) log(
+	ctx context.Context,
+	db *gorm.DB,
+	listID id.ID[list.ProductList],
+	offset, limit int,
+) (
+	[]list.HistoryEntry,
+	error,
+) {
+	var revisions []ProductListRevision
+
+	err := db.WithContext(ctx).
+		Omit("snapshot").
+		Where("list_id = ?", listID.String()).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&revisions).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select change log of list %s: %w", listID, err)
+	}
+
+	return lo.Map(revisions, func(item ProductListRevision, _ int) list.HistoryEntry {
+		return list.HistoryEntry{
+			ID:        item.ID,
+			ListID:    listID,
+			Author:    id.ID[user.User]{UUID: god.Believe(uuid.Parse(item.AuthorID))},
+			Type:      list.EventType(item.EventType),
+			CreatedAt: item.CreatedAt,
+		}
+	}), nil
+}
+
+func (tableHistory) asOf(
+	ctx context.Context,
+	db *gorm.DB,
+	listID id.ID[list.ProductList],
+	point string,
+) (
+	list.ProductList,
+	error,
+) {
+	var revision ProductListRevision
+
+	query := db.WithContext(ctx).Where("list_id = ?", listID.String())
+	if pointTime, err := time.Parse(time.RFC3339, point); err == nil {
+		query = query.Where("created_at <= ?", pointTime).Order("created_at DESC")
+	} else {
+		query = query.Where("id = ?", point)
+	}
+
+	err := query.First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return list.ProductList{}, fmt.Errorf("%w: revision %s of list %s", myerr.ErrNotFound, point, listID)
+	} else if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't select revision %s of list %s: %w", point, listID, err)
+	}
+
+	var model list.ProductList
+	if err = json.Unmarshal(revision.Snapshot, &model); err != nil {
+		return list.ProductList{}, fmt.Errorf("can't decode revision %s of list %s: %w", revision.ID, listID, err)
+	}
+
+	return model, nil
+}
+
+// doltHistory makes DoltDB commit of lists changed by every transaction
+type doltHistory struct {
+	db *gorm.DB
+}
+
+// doltChange is a change of a list waiting for the DoltDB commit of its transaction
+type doltChange struct {
+	listID id.ID[list.ProductList]
+	meta   list.ChangeMeta
+}
+
+type doltChangesKey struct{}
+
+const doltListTrailer = "list: "
+
+// doltListTables are tables which make up a list, only they are staged,
+// so changes of other tables in the working set don't get into commits of lists
+var doltListTables = []string{
+	"product_lists",
+	"product_list_members",
+	"product_list_states",
+	"product_list_comments",
+	"product_list_attachments",
+}
+
+// commit defers DoltDB commit until the transaction is committed, DOLT_COMMIT commits the SQL transaction itself,
+// so a failure after it couldn't roll back changes made before
+func (h doltHistory) commit(ctx context.Context, _ *gorm.DB, model list.ProductList, meta list.ChangeMeta) error {
+	change := doltChange{listID: model.ID, meta: meta}
+
+	dbtx.Collect(ctx, doltChangesKey{}, change, func(changes []doltChange) {
+		if err := h.commitChanges(context.WithoutCancel(ctx), changes); err != nil {
+			log.Err(err).Msg("can't make DoltDB commit of lists")
+		}
+	})
+
+	return nil
+}
+
+// commitChanges makes one DoltDB commit of the changes, every list gets a trailer with the type of its change
+func (h doltHistory) commitChanges(ctx context.Context, changes []doltChange) error {
+	first := changes[0].meta
+	author := fmt.Sprintf("%s <%s@shoplanner>", first.Author, first.Author)
+	trailers := lo.Map(changes, func(item doltChange, _ int) string {
+		return fmt.Sprintf("%s%s %s", doltListTrailer, item.listID, item.meta.Type)
+	})
+	message := fmt.Sprintf("%s\n\n%s", first.Type, strings.Join(trailers, "\n"))
+
+	// staged tables belong to the session, so both calls go to one connection
+	err := h.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.WithContext(ctx).Exec("CALL DOLT_ADD(?)", doltListTables).Error; err != nil {
+			return fmt.Errorf("can't stage tables of lists: %w", err)
+		}
+
+		err := conn.WithContext(ctx).Exec("CALL DOLT_COMMIT('--allow-empty', '--author', ?, '--date', ?, '-m', ?)",
+			author, first.At.UTC().Format(time.RFC3339), message).Error
+		if err != nil {
+			return fmt.Errorf("can't make DoltDB commit: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't commit %d changes of lists: %w", len(changes), err)
+	}
+
+	return nil
+}
+
+// doltEventType returns type of the change of the list from the trailer of its commit,
+// commits made before trailers had types keep it in the title
+func doltEventType(message string, listID id.ID[list.ProductList]) list.EventType {
+	title, _, _ := strings.Cut(message, "\n")
+	eventType, _ := list.ParseEventType(strings.TrimSpace(title))
+
+	for line := range strings.Lines(message) {
+		rest, found := strings.CutPrefix(strings.TrimSpace(line), doltListTrailer+listID.String())
+		if parsed, err := list.ParseEventType(strings.TrimSpace(rest)); found && err == nil {
+			eventType = parsed
+		}
+	}
+
+	return eventType
+}
+
+func (doltHistory) log(
+	ctx context.Context,
+	db *gorm.DB,
+	listID id.ID[list.ProductList],
+	offset, limit int,
+) (
+	[]list.HistoryEntry,
+	error,
+) {
+	var commits []struct {
+		CommitHash string
+		Committer  string
+		Date       time.Time
+		Message    string
+	}
+
+	err := db.WithContext(ctx).
+		Raw("SELECT commit_hash, committer, date, message FROM dolt_log WHERE message LIKE ? "+
+			"ORDER BY date DESC LIMIT ? OFFSET ?", "%"+doltListTrailer+listID.String()+"%", limit, offset).
+		Scan(&commits).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select DoltDB log of list %s: %w", listID, err)
+	}
+
+	entries := make([]list.HistoryEntry, 0, len(commits))
+	for _, c := range commits {
+		entries = append(entries, list.HistoryEntry{
+			ID:        c.CommitHash,
+			ListID:    listID,
+			Author:    id.ID[user.User]{UUID: god.Believe(uuid.Parse(c.Committer))},
+			Type:      doltEventType(c.Message, listID),
+			CreatedAt: c.Date,
+		})
+	}
+
+	return entries, nil
+}
+
+func (doltHistory) asOf(
+	ctx context.Context,
+	db *gorm.DB,
+	listID id.ID[list.ProductList],
+	point string,
+) (
+	list.ProductList,
+	error,
+) {
+	var entity ProductList
+
+	if pointTime, err := time.Parse(time.RFC3339, point); err == nil {
+		point = pointTime.UTC().Format(time.DateTime)
+	}
+
+	// lists trashed later are still in history, and revisions before trash had no deleted_at column
+	err := db.WithContext(ctx).Unscoped().Table("product_lists AS OF ?", point).
+		Where("id = ?", listID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return list.ProductList{}, fmt.Errorf("%w: list %s as of %s", myerr.ErrNotFound, listID, point)
+	} else if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't select list %s as of %s: %w", listID, point, err)
+	}
+
+	err = db.WithContext(ctx).Table("product_list_members AS OF ?", point).
+		Preload("User").
+		Where("list_id = ?", listID.String()).
+		Find(&entity.Members).Error
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't select members of list %s as of %s: %w", listID, point, err)
+	}
+
+	err = db.WithContext(ctx).Table("product_list_states AS OF ?", point).
+		Preload("Product.Forms").
+		Preload("Product.Category").
+		Preload("ReplacementProduct").
+		Preload("ReplacementProduct.Category").
+		Where("list_id = ?", listID.String()).
+		Find(&entity.States).Error
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't select states of list %s as of %s: %w", listID, point, err)
+	}
+
+	return entityToModel(entity), nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// statements is a logger which records SQL, DoltDB isn't available in tests,
+// so statements of dolt history are checked in dry run
+type statements struct {
+	logger.Interface
+
+	lock sync.Mutex
+	sql  []string
+}
+
+func (s *statements) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sql, _ := fc()
+	s.sql = append(s.sql, sql)
+}
+
+func dryRun(t *testing.T) (*gorm.DB, *statements) {
+	t.Helper()
+
+	recorded := &statements{Interface: logger.Discard, lock: sync.Mutex{}, sql: nil}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dolt.db")), &gorm.Config{
+		Logger: recorded,
+		DryRun: true,
+	})
+	require.NoError(t, err)
+
+	return db, recorded
+}
+
+func TestDoltCommitStagesListTables(t *testing.T) {
+	db, recorded := dryRun(t)
+	history := doltHistory{db: db}
+
+	first := list.ProductList{ID: id.NewID[list.ProductList]()}  //nolint:exhaustruct
+	second := list.ProductList{ID: id.NewID[list.ProductList]()} //nolint:exhaustruct
+	meta := list.ChangeMeta{Author: id.NewID[user.User](), Type: list.EventTypeStateUpdated, At: time.Now()}
+
+	err := dbtx.Run(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+		require.NoError(t, history.commit(ctx, tx, first, meta))
+		require.NoError(t, history.commit(ctx, tx, second, list.ChangeMeta{
+			Author: meta.Author,
+			Type:   list.EventTypeDeleted,
+			At:     meta.At,
+		}))
+		require.Empty(t, recorded.sql, "DOLT_COMMIT commits the transaction, so it's called after it")
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, recorded.sql, 2)
+
+	require.True(t, strings.HasPrefix(recorded.sql[0], "CALL DOLT_ADD("))
+	for _, table := range doltListTables {
+		require.Contains(t, recorded.sql[0], "\""+table+"\"")
+	}
+
+	require.True(t, strings.HasPrefix(recorded.sql[1], "CALL DOLT_COMMIT("))
+	require.NotContains(t, recorded.sql[1], "'-A'")
+	require.NotContains(t, recorded.sql[1], "'-a'")
+	require.Contains(t, recorded.sql[1], doltListTrailer+first.ID.String()+" "+list.EventTypeStateUpdated.String())
+	require.Contains(t, recorded.sql[1], doltListTrailer+second.ID.String()+" "+list.EventTypeDeleted.String())
+}
+
+func TestDoltCommitRolledBack(t *testing.T) {
+	db, recorded := dryRun(t)
+
+	model := list.ProductList{ID: id.NewID[list.ProductList]()} //nolint:exhaustruct
+	meta := list.ChangeMeta{Author: id.NewID[user.User](), Type: list.EventTypeStateUpdated, At: time.Now()}
+
+	err := dbtx.Run(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+		require.NoError(t, doltHistory{db: db}.commit(ctx, tx, model, meta))
+		return myerr.ErrConflict
+	})
+	require.ErrorIs(t, err, myerr.ErrConflict)
+	require.Empty(t, recorded.sql)
+}
+
+func TestDoltEventType(t *testing.T) {
+	first, second := id.NewID[list.ProductList](), id.NewID[list.ProductList]()
+	message := fmt.Sprintf("%s\n\n%s%s %s\n%s%s %s", list.EventTypeFull,
+		doltListTrailer, first, list.EventTypeFull, doltListTrailer, second, list.EventTypeDeleted)
+
+	require.Equal(t, list.EventTypeFull, doltEventType(message, first))
+	require.Equal(t, list.EventTypeDeleted, doltEventType(message, second))
+
+	// commits of a single list had no type in the trailer
+	old := fmt.Sprintf("%s\n\n%s%s", list.EventTypeCommentAdded, doltListTrailer, first)
+	require.Equal(t, list.EventTypeCommentAdded, doltEventType(old, first))
+}
+
+func TestDoltAsOfIgnoresTrash(t *testing.T) {
+	db, recorded := dryRun(t)
+
+	listID := id.NewID[list.ProductList]()
+	point := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC).Format(time.RFC3339)
+
+	_, _ = doltHistory{}.asOf(context.Background(), db, listID, point)
+	require.NotEmpty(t, recorded.sql)
+
+	require.Contains(t, recorded.sql[0], "product_lists AS OF \"2026-10-16 09:00:00\"")
+	require.NotContains(t, recorded.sql[0], "deleted_at")
+}
@@ -0,0 +1,416 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+type ProductListComment struct {
+	ID        string    `gorm:"primaryKey;size:36;notNull"`
+	StateID   string    `gorm:"size:36;notNull;index"`
+	AuthorID  string    `gorm:"size:36;notNull"`
+	Text      string    `gorm:"notNull"`
+	CreatedAt time.Time `gorm:"notNull"`
+}
+
+type ProductListAttachment struct {
+	ID          string    `gorm:"primaryKey;size:36;notNull"`
+	StateID     string    `gorm:"size:36;notNull;index"`
+	AuthorID    string    `gorm:"size:36;notNull"`
+	ContentType string    `gorm:"size:255;notNull"`
+	Size        int64     `gorm:"notNull"`
+	CreatedAt   time.Time `gorm:"notNull"`
+	BlobKey     string    `gorm:"size:255;notNull;default:''"`
+}
+
+// ProductListRemovedAttachment is an attachment of a removed list item. Its content is kept until the trash is purged,
+// so the item is restored together with its attachments when removal is undone.
+type ProductListRemovedAttachment struct {
+	ID        string    `gorm:"primaryKey;size:36;notNull"`
+	ListID    string    `gorm:"size:36;notNull"`
+	RemovedAt time.Time `gorm:"notNull;index"`
+	BlobKey   string    `gorm:"size:255;notNull;default:''"`
+}
+
+// AddComment adds comment to the thread of list item, validateFunc checks that author can comment the list
+func (r *Repo) AddComment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	productID id.ID[product.Product],
+	meta list.ChangeMeta,
+	validateFunc func(list.ProductList) error,
+	comment list.Comment,
+) error {
+	return r.updateItem(ctx, listID, productID, meta, func(tx *gorm.DB, model list.ProductList, stateID string) error {
+		if err := validateFunc(model); err != nil {
+			return err
+		}
+
+		err := tx.WithContext(ctx).Create(lo.ToPtr(commentToEntity(stateID, comment))).Error
+		if err != nil {
+			return fmt.Errorf("can't insert comment %s: %w", comment.ID, err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteComment deletes comment of list item, validateFunc checks that user can delete the comment
+func (r *Repo) DeleteComment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	productID id.ID[product.Product],
+	meta list.ChangeMeta,
+	commentID id.ID[list.Comment],
+	validateFunc func(list.ProductList, list.Comment) error,
+) error {
+	return r.updateItem(ctx, listID, productID, meta, func(tx *gorm.DB, model list.ProductList, stateID string) error {
+		state := stateByProductID(model, productID)
+
+		idx := slices.IndexFunc(state.Comments, func(c list.Comment) bool { return c.ID == commentID })
+		if idx == -1 {
+			return fmt.Errorf("%w: comment %s of product %s", myerr.ErrNotFound, commentID, productID)
+		}
+
+		if err := validateFunc(model, state.Comments[idx]); err != nil {
+			return err
+		}
+
+		err := tx.WithContext(ctx).Where("id = ? AND state_id = ?", commentID.String(), stateID).
+			Delete(new(ProductListComment)).Error
+		if err != nil {
+			return fmt.Errorf("can't delete comment %s: %w", commentID, err)
+		}
+
+		return nil
+	})
+}
+
+// AddAttachment saves metadata of attachment of list item, content of attachment must be already stored
+func (r *Repo) AddAttachment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	productID id.ID[product.Product],
+	meta list.ChangeMeta,
+	validateFunc func(list.ProductList) error,
+	attachment list.Attachment,
+) error {
+	return r.updateItem(ctx, listID, productID, meta, func(tx *gorm.DB, model list.ProductList, stateID string) error {
+		if err := validateFunc(model); err != nil {
+			return err
+		}
+
+		err := tx.WithContext(ctx).Create(lo.ToPtr(attachmentToEntity(stateID, attachment))).Error
+		if err != nil {
+			return fmt.Errorf("can't insert attachment %s: %w", attachment.ID, err)
+		}
+
+		return nil
+	})
+}
+
+// DeleteAttachment deletes metadata of attachment of list item, validateFunc checks that user can delete it
+func (r *Repo) DeleteAttachment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	productID id.ID[product.Product],
+	meta list.ChangeMeta,
+	attachmentID id.ID[list.Attachment],
+	validateFunc func(list.ProductList, list.Attachment) error,
+) error {
+	return r.updateItem(ctx, listID, productID, meta, func(tx *gorm.DB, model list.ProductList, stateID string) error {
+		state := stateByProductID(model, productID)
+
+		idx := slices.IndexFunc(state.Attachments, func(a list.Attachment) bool { return a.ID == attachmentID })
+		if idx == -1 {
+			return fmt.Errorf("%w: attachment %s of product %s", myerr.ErrNotFound, attachmentID, productID)
+		}
+
+		if err := validateFunc(model, state.Attachments[idx]); err != nil {
+			return err
+		}
+
+		err := tx.WithContext(ctx).Where("id = ? AND state_id = ?", attachmentID.String(), stateID).
+			Delete(new(ProductListAttachment)).Error
+		if err != nil {
+			return fmt.Errorf("can't delete attachment %s: %w", attachmentID, err)
+		}
+
+		return nil
+	})
+}
+
+// updateItem runs updateFunc with id of state row of the product and commits new version of the list
+func (r *Repo) updateItem(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	productID id.ID[product.Product],
+	meta list.ChangeMeta,
+	updateFunc func(tx *gorm.DB, model list.ProductList, stateID string) error,
+) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getEntity(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		idx := slices.IndexFunc(entity.States, func(s ProductListState) bool { return s.ProductID == productID.String() })
+		if idx == -1 {
+			return fmt.Errorf("%w: product state with product id: %s", myerr.ErrNotFound, productID)
+		}
+
+		if err = updateFunc(tx, entityToModel(entity), entity.States[idx].ID); err != nil {
+			return err
+		}
+
+		model, err := r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, model, meta)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
+
+func stateByProductID(model list.ProductList, productID id.ID[product.Product]) list.ProductState {
+	idx := slices.IndexFunc(model.States, func(s list.ProductState) bool { return s.Product.ID == productID })
+
+	return model.States[idx]
+}
+
+func orderByCreation(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at")
+}
+
+func commentToModel(entity ProductListComment) list.Comment {
+	return list.Comment{
+		ID:        id.ID[list.Comment]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		Author:    id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.AuthorID))},
+		Text:      entity.Text,
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+func attachmentToModel(entity ProductListAttachment) list.Attachment {
+	return list.Attachment{
+		ID:          id.ID[list.Attachment]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		Author:      id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.AuthorID))},
+		ContentType: entity.ContentType,
+		Size:        entity.Size,
+		CreatedAt:   entity.CreatedAt,
+		BlobKey:     entity.BlobKey,
+	}
+}
+
+// PurgeRemovedAttachments deletes attachments of items removed before given time,
+// deleteFunc deletes content of every attachment by its blob key before its record is deleted
+func (r *Repo) PurgeRemovedAttachments(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+	deleteFunc func(string) error,
+) (
+	int,
+	error,
+) {
+	var entities []ProductListRemovedAttachment
+
+	err := r.db.WithContext(ctx).Where("removed_at < ?", before.UTC()).Order("removed_at").Limit(limit).
+		Find(&entities).Error
+	if err != nil {
+		return 0, fmt.Errorf("can't select removed attachments: %w", err)
+	}
+
+	for i, entity := range entities {
+		if err = deleteFunc(entity.BlobKey); err != nil {
+			return i, err
+		}
+
+		if err = r.db.WithContext(ctx).Delete(&entity).Error; err != nil {
+			return i, fmt.Errorf("can't delete removed attachment %s: %w", entity.ID, err)
+		}
+	}
+
+	return len(entities), nil
+}
+
+// fillBlobKeys saves blob keys of attachments stored before the keys were saved, their contents are kept
+// under keys of lists which they belong to
+func fillBlobKeys(ctx context.Context, db *gorm.DB) error {
+	var attachments []struct {
+		ID     string
+		ListID string
+	}
+
+	err := db.WithContext(ctx).Model(new(ProductListAttachment)).
+		Select("product_list_attachments.id, product_list_states.list_id").
+		Joins("JOIN product_list_states ON product_list_states.id = product_list_attachments.state_id").
+		Where("product_list_attachments.blob_key = ''").
+		Scan(&attachments).Error
+	if err != nil {
+		return fmt.Errorf("can't select attachments without blob keys: %w", err)
+	}
+
+	var removed []ProductListRemovedAttachment
+	if err = db.WithContext(ctx).Where("blob_key = ''").Find(&removed).Error; err != nil {
+		return fmt.Errorf("can't select removed attachments without blob keys: %w", err)
+	}
+
+	for _, item := range attachments {
+		if err = fillBlobKey(ctx, db, new(ProductListAttachment), item.ID, item.ListID); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range removed {
+		if err = fillBlobKey(ctx, db, new(ProductListRemovedAttachment), item.ID, item.ListID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fillBlobKey(ctx context.Context, db *gorm.DB, table any, attachmentID, listID string) error {
+	key := list.AttachmentKey(
+		id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(listID))},
+		id.ID[list.Attachment]{UUID: god.Believe(uuid.Parse(attachmentID))},
+	)
+
+	if err := db.WithContext(ctx).Model(table).Where("id = ?", attachmentID).Update("blob_key", key).Error; err != nil {
+		return fmt.Errorf("can't save blob key of attachment %s: %w", attachmentID, err)
+	}
+
+	return nil
+}
+
+// removeThreads deletes comments and attachments of states removed at given time,
+// contents of attachments are kept until records of removed attachments are purged
+func removeThreads(ctx context.Context, tx *gorm.DB, listID string, stateIDs []string, removedAt time.Time) error {
+	var attachments []ProductListAttachment
+
+	if err := tx.WithContext(ctx).Where("state_id IN ?", stateIDs).Find(&attachments).Error; err != nil {
+		return fmt.Errorf("can't select attachments of removed states: %w", err)
+	}
+
+	if len(attachments) != 0 {
+		removed := lo.Map(attachments, func(item ProductListAttachment, _ int) ProductListRemovedAttachment {
+			return ProductListRemovedAttachment{
+				ID:        item.ID,
+				ListID:    listID,
+				RemovedAt: removedAt.UTC(),
+				BlobKey:   item.BlobKey,
+			}
+		})
+
+		if err := tx.WithContext(ctx).Create(&removed).Error; err != nil {
+			return fmt.Errorf("can't save attachments of removed states: %w", err)
+		}
+	}
+
+	for _, table := range []any{new(ProductListComment), new(ProductListAttachment)} {
+		if err := tx.WithContext(ctx).Where("state_id IN ?", stateIDs).Delete(table).Error; err != nil {
+			return fmt.Errorf("can't delete threads of removed states: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreThreads inserts comments and attachments of states inserted again, e.g. when their removal is undone.
+// Comments moved to other lists stay there, attachments are restored only if their contents aren't purged yet.
+func restoreThreads(ctx context.Context, tx *gorm.DB, model list.ProductList, inserted []ProductListState) error {
+	states := lo.KeyBy(model.States, func(s list.ProductState) string { return s.Product.ID.String() })
+
+	for _, row := range inserted {
+		state := states[row.ProductID]
+
+		if len(state.Comments) != 0 {
+			comments := lo.Map(state.Comments, func(item list.Comment, _ int) ProductListComment {
+				return commentToEntity(row.ID, item)
+			})
+
+			conflict := clause.OnConflict{DoNothing: true} //nolint:exhaustruct
+			if err := tx.WithContext(ctx).Clauses(conflict).Create(&comments).Error; err != nil {
+				return fmt.Errorf("can't restore comments of product %s: %w", row.ProductID, err)
+			}
+		}
+
+		if len(state.Attachments) == 0 {
+			continue
+		}
+
+		var removed []ProductListRemovedAttachment
+
+		ids := lo.Map(state.Attachments, func(item list.Attachment, _ int) string { return item.ID.String() })
+		if err := tx.WithContext(ctx).Where("id IN ?", ids).Find(&removed).Error; err != nil {
+			return fmt.Errorf("can't select removed attachments of product %s: %w", row.ProductID, err)
+		}
+
+		if len(removed) == 0 {
+			continue
+		}
+
+		// blob keys aren't saved in versions of the list, so they are taken from removed attachments
+		kept := lo.KeyBy(removed, func(item ProductListRemovedAttachment) string { return item.ID })
+		attachments := lo.FilterMap(state.Attachments, func(item list.Attachment, _ int) (ProductListAttachment, bool) {
+			entity, found := kept[item.ID.String()]
+			item.BlobKey = entity.BlobKey
+
+			return attachmentToEntity(row.ID, item), found
+		})
+
+		if err := tx.WithContext(ctx).Create(&attachments).Error; err != nil {
+			return fmt.Errorf("can't restore attachments of product %s: %w", row.ProductID, err)
+		}
+
+		if err := tx.WithContext(ctx).Delete(&removed).Error; err != nil {
+			return fmt.Errorf("can't delete removed attachments of product %s: %w", row.ProductID, err)
+		}
+	}
+
+	return nil
+}
+
+func commentToEntity(stateID string, model list.Comment) ProductListComment {
+	return ProductListComment{
+		ID:        model.ID.String(),
+		StateID:   stateID,
+		AuthorID:  model.Author.String(),
+		Text:      model.Text,
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func attachmentToEntity(stateID string, model list.Attachment) ProductListAttachment {
+	return ProductListAttachment{
+		ID:          model.ID.String(),
+		StateID:     stateID,
+		AuthorID:    model.Author.String(),
+		ContentType: model.ContentType,
+		Size:        model.Size,
+		CreatedAt:   model.CreatedAt,
+		BlobKey:     model.BlobKey,
+	}
+}
@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+// GetAndUpdateLists applies update returned by updateFunc to lists with given ids in one transaction,
+// updateFunc gets lists in the order of ids. Comments and attachments of states removed from updated lists
+// follow states of the same products in created lists. Updated and created lists of the result are reloaded.
+func (r *Repo) GetAndUpdateLists(
+	ctx context.Context,
+	listIDs []id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	updateFunc func([]list.ProductList) (list.ListsUpdate, error),
+) (
+	list.ListsUpdate,
+	error,
+) {
+	var result list.ListsUpdate
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		oldEntities, err := r.getEntities(ctx, tx, listIDs)
+		if err != nil {
+			return err
+		}
+
+		update, err := updateFunc(lo.Map(listIDs, func(listID id.ID[list.ProductList], _ int) list.ProductList {
+			return entityToModel(oldEntities[listID.String()])
+		}))
+		if err != nil {
+			return err
+		}
+
+		result = list.ListsUpdate{Updated: nil, Created: nil, Deleted: update.Deleted, DeletedAt: update.DeletedAt}
+		moved := removedStates(oldEntities, update.Updated)
+
+		// lists are created before states are removed from updated ones, so comments can be moved
+		for _, model := range update.Created {
+			created, err := r.createWithMovedStates(ctx, tx, model, moved, meta)
+			if err != nil {
+				return err
+			}
+
+			result.Created = append(result.Created, created)
+		}
+
+		for _, model := range update.Updated {
+			updated, err := r.updateSelected(ctx, tx, oldEntities, model, meta)
+			if err != nil {
+				return err
+			}
+
+			result.Updated = append(result.Updated, updated)
+		}
+
+		for _, listID := range update.Deleted {
+			if err = r.trashSelected(ctx, tx, oldEntities, listID, update.DeletedAt, meta); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return list.ListsUpdate{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return result, nil
+}
+
+// getEntities locks lists in the same order in every transaction, so concurrent updates don't deadlock
+func (r *Repo) getEntities(ctx context.Context, tx *gorm.DB, listIDs []id.ID[list.ProductList]) (
+	map[string]ProductList,
+	error,
+) {
+	ids := lo.Uniq(listIDs)
+	slices.SortFunc(ids, func(a, b id.ID[list.ProductList]) int { return strings.Compare(a.String(), b.String()) })
+
+	entities := make(map[string]ProductList, len(ids))
+
+	for _, listID := range ids {
+		entity, err := r.getEntity(ctx, tx, listID)
+		if err != nil {
+			return nil, err
+		}
+
+		entities[listID.String()] = entity
+	}
+
+	return entities, nil
+}
+
+// removedStates returns ids of state rows removed from updated lists by product ids
+func removedStates(oldEntities map[string]ProductList, updated []list.ProductList) map[string]string {
+	removed := make(map[string]string)
+
+	for _, model := range updated {
+		kept := lo.SliceToMap(model.States, func(state list.ProductState) (string, struct{}) {
+			return state.Product.ID.String(), struct{}{}
+		})
+
+		for _, state := range oldEntities[model.ID.String()].States {
+			if _, found := kept[state.ProductID]; !found {
+				removed[state.ProductID] = state.ID
+			}
+		}
+	}
+
+	return removed
+}
+
+func (r *Repo) createWithMovedStates(
+	ctx context.Context,
+	tx *gorm.DB,
+	model list.ProductList,
+	moved map[string]string,
+	meta list.ChangeMeta,
+) (
+	list.ProductList,
+	error,
+) {
+	entity := listToEntity(model)
+	if err := tx.WithContext(ctx).Create(&entity).Error; err != nil {
+		return list.ProductList{}, fmt.Errorf("can't insert new product list %s: %w", model.ID, err)
+	}
+
+	for _, state := range entity.States {
+		oldStateID, found := moved[state.ProductID]
+		if !found {
+			continue
+		}
+
+		for _, table := range []any{new(ProductListComment), new(ProductListAttachment)} {
+			err := tx.WithContext(ctx).Model(table).Where("state_id = ?", oldStateID).Update("state_id", state.ID).Error
+			if err != nil {
+				return list.ProductList{}, fmt.Errorf("can't move thread of product %s: %w", state.ProductID, err)
+			}
+		}
+	}
+
+	created, err := r.getProductList(ctx, tx, model.ID)
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	createMeta := list.ChangeMeta{Author: meta.Author, Type: list.EventTypeFull, At: meta.At}
+
+	return created, r.history.commit(ctx, tx, created, createMeta)
+}
+
+func (r *Repo) updateSelected(
+	ctx context.Context,
+	tx *gorm.DB,
+	oldEntities map[string]ProductList,
+	model list.ProductList,
+	meta list.ChangeMeta,
+) (
+	list.ProductList,
+	error,
+) {
+	oldEntity, found := oldEntities[model.ID.String()]
+	if !found {
+		return list.ProductList{}, fmt.Errorf("%w: list %s isn't selected for update", myerr.ErrInternal, model.ID)
+	}
+
+	if err := r.saveDiff(ctx, tx, oldEntity, model, meta.At); err != nil {
+		return list.ProductList{}, err
+	}
+
+	updated, err := r.getProductList(ctx, tx, model.ID)
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	if err = saveUndoRecord(ctx, tx, entityToModel(oldEntity), updated, meta); err != nil {
+		return list.ProductList{}, err
+	}
+
+	return updated, r.history.commit(ctx, tx, updated, meta)
+}
+
+func (r *Repo) trashSelected(
+	ctx context.Context,
+	tx *gorm.DB,
+	oldEntities map[string]ProductList,
+	listID id.ID[list.ProductList],
+	deletedAt time.Time,
+	meta list.ChangeMeta,
+) error {
+	oldEntity, found := oldEntities[listID.String()]
+	if !found {
+		return fmt.Errorf("%w: list %s isn't selected for update", myerr.ErrInternal, listID)
+	}
+
+	if err := trashList(ctx, tx, listID, meta.Author, deletedAt); err != nil {
+		return err
+	}
+
+	trashMeta := list.ChangeMeta{Author: meta.Author, Type: list.EventTypeDeleted, At: meta.At}
+
+	return r.history.commit(ctx, tx, entityToModel(oldEntity), trashMeta)
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+
+	"go-backend/internal/backend/list"
+)
+
+// Price is kept as text, so amounts stay exact in every database
+type Price struct {
+	Amount   *string `gorm:"size:32"`
+	Currency *string `gorm:"size:3"`
+}
+
+func priceToModel(entity Price) mo.Option[list.Price] {
+	if entity.Amount == nil || entity.Currency == nil {
+		return mo.None[list.Price]()
+	}
+
+	amount, err := decimal.NewFromString(*entity.Amount)
+	if err != nil {
+		return mo.None[list.Price]()
+	}
+
+	return mo.Some(list.Price{Amount: amount, Currency: *entity.Currency})
+}
+
+func priceToEntity(model mo.Option[list.Price]) Price {
+	price, found := model.Get()
+	if !found {
+		return Price{Amount: nil, Currency: nil}
+	}
+
+	amount := price.Amount.String()
+
+	return Price{Amount: &amount, Currency: &price.Currency}
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/mymysql"
+)
+
+type ProductListSession struct {
+	ID         string                       `gorm:"primaryKey;size:36;notNull"`
+	ListID     string                       `gorm:"size:36;notNull;index"`
+	Shoppers   string                       `gorm:"notNull"`
+	StartedAt  time.Time                    `gorm:"notNull"`
+	FinishedAt *time.Time                   `gorm:""`
+	Taken      int32                        `gorm:"notNull"`
+	Missed     int32                        `gorm:"notNull"`
+	Replaced   int32                        `gorm:"notNull"`
+	NextListID *string                      `gorm:"size:36"`
+	Spent      []ProductListSessionSpending `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+}
+
+// ProductListSessionSpending is a sum of prices in one currency paid during finished session
+type ProductListSessionSpending struct {
+	SessionID string `gorm:"primaryKey;size:36;notNull"`
+	Currency  string `gorm:"primaryKey;size:3;notNull"`
+	Amount    string `gorm:"size:32;notNull"`
+}
+
+// GetAndUpdateSession changes list together with its shopping session.
+// updateFunc gets current unfinished session of the list, if there is one.
+func (r *Repo) GetAndUpdateSession(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	updateFunc func(list.ProductList, mo.Option[list.Session]) (list.SessionUpdate, error),
+) (
+	list.ProductList,
+	list.Session,
+	error,
+) {
+	var update list.SessionUpdate
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		var current mo.Option[list.Session]
+
+		oldEntity, err := r.getEntity(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		var session ProductListSession
+		err = tx.WithContext(ctx).Preload("Spent").Where("list_id = ? AND finished_at IS NULL", listID.String()).
+			Order("started_at DESC").First(&session).Error
+		if err == nil {
+			current = mo.Some(sessionToModel(session))
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("can't select current session of list %s: %w", listID, err)
+		}
+
+		update, err = updateFunc(entityToModel(oldEntity), current)
+		if err != nil {
+			return err
+		}
+
+		if err = r.saveDiff(ctx, tx, oldEntity, update.List, meta.At); err != nil {
+			return err
+		}
+
+		if err = tx.WithContext(ctx).Save(lo.ToPtr(sessionToEntity(update.Session))).Error; err != nil {
+			return fmt.Errorf("can't save session %s of list %s: %w", update.Session.ID, listID, err)
+		}
+
+		if update.List, err = r.getProductList(ctx, tx, listID); err != nil {
+			return err
+		}
+
+		if err = r.history.commit(ctx, tx, update.List, meta); err != nil {
+			return err
+		}
+
+		nextList, found := update.NextList.Get()
+		if !found {
+			return nil
+		}
+
+		if err = tx.WithContext(ctx).Create(lo.ToPtr(listToEntity(nextList))).Error; err != nil {
+			return fmt.Errorf("can't insert next product list %s: %w", nextList.ID, err)
+		}
+
+		nextMeta := list.ChangeMeta{Author: meta.Author, Type: list.EventTypeFull, At: meta.At}
+
+		return r.history.commit(ctx, tx, nextList, nextMeta)
+	})
+	if err != nil {
+		return list.ProductList{}, list.Session{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return update.List, update.Session, nil
+}
+
+// GetSessions returns all shopping sessions of the list from the newest to the oldest one
+func (r *Repo) GetSessions(ctx context.Context, listID id.ID[list.ProductList]) ([]list.Session, error) {
+	var sessions []ProductListSession
+
+	err := r.db.WithContext(ctx).Preload("Spent").Where("list_id = ?", listID.String()).Order("started_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select sessions of list %s: %w", listID, err)
+	}
+
+	return lo.Map(sessions, func(item ProductListSession, _ int) list.Session { return sessionToModel(item) }), nil
+}
+
+func sessionToModel(entity ProductListSession) list.Session {
+	session := list.Session{
+		ID:     id.ID[list.Session]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		ListID: id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(entity.ListID))},
+		Shoppers: lo.Map(strings.Split(entity.Shoppers, ","), func(item string, _ int) id.ID[user.User] {
+			return id.ID[user.User]{UUID: god.Believe(uuid.Parse(item))}
+		}),
+		StartedAt: entity.StartedAt,
+		Summary:   mo.None[list.SessionSummary](),
+		Spent: lo.Map(entity.Spent, func(item ProductListSessionSpending, _ int) list.Price {
+			return list.Price{Amount: god.Believe(decimal.NewFromString(item.Amount)), Currency: item.Currency}
+		}),
+	}
+
+	if entity.FinishedAt != nil {
+		session.Summary = mo.Some(list.SessionSummary{
+			FinishedAt: *entity.FinishedAt,
+			Duration:   entity.FinishedAt.Sub(entity.StartedAt),
+			Taken:      int(entity.Taken),
+			Missed:     int(entity.Missed),
+			Replaced:   int(entity.Replaced),
+			NextListID: lo.If(entity.NextListID == nil, mo.None[id.ID[list.ProductList]]()).
+				ElseF(func() mo.Option[id.ID[list.ProductList]] {
+					return mo.Some(id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(*entity.NextListID))})
+				}),
+		})
+	}
+
+	return session
+}
+
+func sessionToEntity(model list.Session) ProductListSession {
+	summary, finished := model.Summary.Get()
+
+	return ProductListSession{
+		ID:     model.ID.String(),
+		ListID: model.ListID.String(),
+		Shoppers: strings.Join(lo.Map(model.Shoppers, func(item id.ID[user.User], _ int) string {
+			return item.String()
+		}), ","),
+		StartedAt:  model.StartedAt,
+		FinishedAt: lo.If(finished, &summary.FinishedAt).Else(nil),
+		Taken:      int32(summary.Taken),
+		Missed:     int32(summary.Missed),
+		Replaced:   int32(summary.Replaced),
+		NextListID: lo.If(summary.NextListID.IsPresent(), lo.ToPtr(summary.NextListID.OrEmpty().String())).
+			Else(nil),
+		Spent: lo.Map(model.Spent, func(item list.Price, _ int) ProductListSessionSpending {
+			return ProductListSessionSpending{
+				SessionID: model.ID.String(),
+				Currency:  item.Currency,
+				Amount:    item.Amount.String(),
+			}
+		}),
+	}
+}
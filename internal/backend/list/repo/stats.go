@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+)
+
+// statsPurchaseRow is a purchased state joined with its list and categories of its product and replacement
+type statsPurchaseRow struct {
+	Purchase            purchaseRow `gorm:"embedded"`
+	ShopMapID           *string
+	PurchasedAt         time.Time
+	Category            *string
+	ReplacementCategory *string
+}
+
+// GetPurchases returns prices paid from the from time till the to time for products of lists
+// which any of given users is a member of. Lists in the trash are skipped.
+func (r *Repo) GetPurchases(
+	ctx context.Context,
+	userIDs []id.ID[user.User],
+	from, to time.Time,
+) (
+	[]list.Purchase,
+	error,
+) {
+	var rows []statsPurchaseRow
+
+	memberOf := r.db.Model(new(ProductListMember)).Select("1").
+		Where("product_list_members.list_id = product_list_states.list_id").
+		Where("product_list_members.user_id IN ?", lo.Map(userIDs, func(item id.ID[user.User], _ int) string {
+			return item.String()
+		}))
+
+	err := r.db.WithContext(ctx).Table("product_list_states").
+		Select("product_list_states.list_id, product_list_states.status, product_list_states.purchased_at, "+
+			"product_list_states.price_amount, product_list_states.price_currency, "+
+			"product_list_states.replacement_price_amount, product_list_states.replacement_price_currency, "+
+			"product_lists.shop_map_id, categories.name AS category, replacement_categories.name AS replacement_category").
+		Joins("JOIN product_lists ON product_lists.id = product_list_states.list_id "+
+			"AND product_lists.deleted_at IS NULL").
+		Joins("LEFT JOIN products ON products.id = product_list_states.product_id").
+		Joins("LEFT JOIN product_categories AS categories ON categories.id = products.category_id").
+		Joins("LEFT JOIN products AS replacements ON replacements.id = product_list_states.replacement_product_id").
+		Joins("LEFT JOIN product_categories AS replacement_categories "+
+			"ON replacement_categories.id = replacements.category_id").
+		Where("EXISTS (?)", memberOf).
+		Where("product_list_states.status IN ?", []int{int(list.StateStatusTaken), int(list.StateStatusReplaced)}).
+		Where("product_list_states.purchased_at >= ? AND product_list_states.purchased_at < ?", from, to).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select purchases of users %v: %w", userIDs, err)
+	}
+
+	purchases := make([]list.Purchase, 0, len(rows))
+	for _, row := range rows {
+		replaced := list.StateStatus(row.Purchase.Status) == list.StateStatusReplaced
+
+		price, found := priceToModel(lo.Ternary(replaced, row.Purchase.ReplacementPrice, row.Purchase.Price)).Get()
+		if !found {
+			continue
+		}
+
+		category := lo.Ternary(replaced, row.ReplacementCategory, row.Category)
+
+		purchases = append(purchases, list.Purchase{
+			ListID: id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(row.Purchase.ListID))},
+			ShopMapID: lo.If(row.ShopMapID == nil, mo.None[id.ID[shopmap.ShopMap]]()).
+				ElseF(func() mo.Option[id.ID[shopmap.ShopMap]] {
+					return mo.Some(id.ID[shopmap.ShopMap]{UUID: god.Believe(uuid.Parse(*row.ShopMapID))})
+				}),
+			Category: lo.If(category == nil, mo.None[product.Category]()).
+				ElseF(func() mo.Option[product.Category] { return mo.Some(product.Category(*category)) }),
+			PurchasedAt: row.PurchasedAt,
+			Price:       price,
+		})
+	}
+
+	return purchases, nil
+}
+
+// GetListMates returns those of candidates who are members of a list not in the trash together with the user
+func (r *Repo) GetListMates(
+	ctx context.Context,
+	userID id.ID[user.User],
+	candidateIDs []id.ID[user.User],
+) (
+	[]id.ID[user.User],
+	error,
+) {
+	if len(candidateIDs) == 0 {
+		return []id.ID[user.User]{}, nil
+	}
+
+	var mateIDs []string
+
+	err := r.db.WithContext(ctx).Model(new(ProductListMember)).Distinct("product_list_members.user_id").
+		Joins("JOIN product_list_members AS users ON users.list_id = product_list_members.list_id").
+		Joins("JOIN product_lists ON product_lists.id = product_list_members.list_id "+
+			"AND product_lists.deleted_at IS NULL").
+		Where("users.user_id = ?", userID.String()).
+		Where("product_list_members.user_id IN ?", lo.Map(candidateIDs, func(item id.ID[user.User], _ int) string {
+			return item.String()
+		})).
+		Pluck("product_list_members.user_id", &mateIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select list mates of user %s: %w", userID, err)
+	}
+
+	return lo.Map(mateIDs, func(item string, _ int) id.ID[user.User] {
+		return id.ID[user.User]{UUID: god.Believe(uuid.Parse(item))}
+	}), nil
+}
@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// ProductListPurchaseHistory is a history of purchases of a product by a user in archived lists
+type ProductListPurchaseHistory struct {
+	UserID    string    `gorm:"primaryKey;size:36;notNull"`
+	ProductID string    `gorm:"primaryKey;size:36;notNull"`
+	Count     int       `gorm:"notNull"`
+	FirstAt   time.Time `gorm:"notNull"`
+	LastAt    time.Time `gorm:"notNull"`
+	// Interval is a typical time between purchases in nanoseconds
+	Interval *int64
+}
+
+// ProductListCountedList marks archived list whose purchases are added to histories
+type ProductListCountedList struct {
+	ListID    string    `gorm:"primaryKey;size:36;notNull"`
+	CountedAt time.Time `gorm:"notNull"`
+}
+
+// GetUncountedLists returns archived lists whose purchases aren't added to histories yet, the oldest go first
+func (r *Repo) GetUncountedLists(ctx context.Context, limit int) ([]id.ID[list.ProductList], error) {
+	var rawIDs []string
+
+	counted := r.db.Model(new(ProductListCountedList)).Select("list_id")
+
+	err := r.db.WithContext(ctx).Model(new(ProductList)).
+		Where("status = ? AND id NOT IN (?)", int32(list.ExecStatusArchived), counted).
+		Order("updated_at").
+		Limit(limit).
+		Pluck("id", &rawIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select uncounted lists: %w", err)
+	}
+
+	return lo.Map(rawIDs, func(item string, _ int) id.ID[list.ProductList] {
+		return id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(item))}
+	}), nil
+}
+
+// CountPurchases marks the archived list as counted and saves histories returned by countFunc.
+// countFunc gets histories of products of the list bought by its members.
+// Each list is counted once, so when several replicas count it at once others get ErrConflict.
+func (r *Repo) CountPurchases(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	now time.Time,
+	countFunc func(list.ProductList, []list.PurchaseHistory) ([]list.PurchaseHistory, error),
+) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&ProductListCountedList{ListID: listID.String(), CountedAt: now.UTC()})
+		if result.Error != nil {
+			return fmt.Errorf("can't mark list %s as counted: %w", listID, result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: list %s is already counted", myerr.ErrConflict, listID)
+		}
+
+		model, err := r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		var entities []ProductListPurchaseHistory
+
+		err = tx.WithContext(ctx).
+			Where("user_id IN ? AND product_id IN ?",
+				lo.Map(model.Members, func(item list.Member, _ int) string { return item.UserID.String() }),
+				lo.Map(model.States, func(item list.ProductState, _ int) string { return item.Product.ID.String() })).
+			Find(&entities).Error
+		if err != nil {
+			return fmt.Errorf("can't select purchase histories of list %s: %w", listID, err)
+		}
+
+		histories := lo.Map(entities, func(item ProductListPurchaseHistory, _ int) list.PurchaseHistory {
+			return historyToModel(item)
+		})
+
+		if histories, err = countFunc(model, histories); err != nil {
+			return err
+		}
+
+		if len(histories) == 0 {
+			return nil
+		}
+
+		err = tx.WithContext(ctx).Save(lo.Map(histories, func(item list.PurchaseHistory, _ int) ProductListPurchaseHistory {
+			return historyToEntity(item)
+		})).Error
+		if err != nil {
+			return fmt.Errorf("can't save purchase histories of list %s: %w", listID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetPurchaseHistories returns histories of purchases of the users
+func (r *Repo) GetPurchaseHistories(ctx context.Context, userIDs []id.ID[user.User]) ([]list.PurchaseHistory, error) {
+	var entities []ProductListPurchaseHistory
+
+	err := r.db.WithContext(ctx).
+		Where("user_id IN ?", lo.Map(userIDs, func(item id.ID[user.User], _ int) string { return item.String() })).
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select purchase histories of users %v: %w", userIDs, err)
+	}
+
+	return lo.Map(entities, func(item ProductListPurchaseHistory, _ int) list.PurchaseHistory {
+		return historyToModel(item)
+	}), nil
+}
+
+func historyToModel(entity ProductListPurchaseHistory) list.PurchaseHistory {
+	return list.PurchaseHistory{
+		UserID:    id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.UserID))},
+		ProductID: id.ID[product.Product]{UUID: god.Believe(uuid.Parse(entity.ProductID))},
+		Count:     entity.Count,
+		FirstAt:   entity.FirstAt,
+		LastAt:    entity.LastAt,
+		Interval: lo.If(entity.Interval == nil, mo.None[time.Duration]()).
+			ElseF(func() mo.Option[time.Duration] { return mo.Some(time.Duration(*entity.Interval)) }),
+	}
+}
+
+func historyToEntity(model list.PurchaseHistory) ProductListPurchaseHistory {
+	return ProductListPurchaseHistory{
+		UserID:    model.UserID.String(),
+		ProductID: model.ProductID.String(),
+		Count:     model.Count,
+		FirstAt:   model.FirstAt.UTC(),
+		LastAt:    model.LastAt.UTC(),
+		Interval: lo.If(model.Interval.IsAbsent(), (*int64)(nil)).
+			ElseF(func() *int64 { return lo.ToPtr(int64(model.Interval.MustGet())) }),
+	}
+}
@@ -0,0 +1,263 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+// summaryRow is a list joined with the member row of the user who requested it
+type summaryRow struct {
+	ID         string
+	Status     int32
+	Title      string
+	ShopMapID  *string
+	UpdatedAt  time.Time
+	CreatedAt  time.Time
+	MemberType int32
+}
+
+type stateCountRow struct {
+	ListID string
+	Status int
+	Count  int
+}
+
+// purchaseRow is a price paid for a product of a list
+type purchaseRow struct {
+	ListID           string
+	Status           int
+	Price            Price `gorm:"embedded;embeddedPrefix:price_"`
+	ReplacementPrice Price `gorm:"embedded;embeddedPrefix:replacement_price_"`
+}
+
+type memberCountRow struct {
+	ListID string
+	Count  int
+}
+
+// summaryCursor is a sort key and an id of the last list of a page, it's passed to clients as base64 of JSON
+type summaryCursor struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+	Title     string    `json:"title"`
+	ID        string    `json:"id"`
+}
+
+// GetSummaries returns page of summaries of lists which user is a member of
+func (r *Repo) GetSummaries(ctx context.Context, userID id.ID[user.User], filter list.ListFilter) (
+	list.ListPage,
+	error,
+) {
+	query := r.db.WithContext(ctx).Table("product_lists").
+		Select("product_lists.id, product_lists.status, product_lists.title, product_lists.shop_map_id, "+
+			"product_lists.updated_at, product_lists.created_at, product_list_members.member_type").
+		Joins("JOIN product_list_members ON product_list_members.list_id = product_lists.id "+
+			"AND product_list_members.user_id = ?", userID.String()).
+		Where("product_lists.deleted_at IS NULL")
+
+	if status, found := filter.Status.Get(); found {
+		query = query.Where("product_lists.status = ?", int32(status))
+	}
+
+	if role, found := filter.Role.Get(); found {
+		query = query.Where("product_list_members.member_type = ?", int32(role))
+	}
+
+	for _, word := range strings.Fields(strings.ToLower(filter.Search)) {
+		query = query.Where("LOWER(product_lists.title) LIKE ? ESCAPE '!'", "%"+mymysql.EscapeLike(word)+"%")
+	}
+
+	query, err := applySummaryCursor(query, filter)
+	if err != nil {
+		return list.ListPage{}, err
+	}
+
+	var rows []summaryRow
+
+	// one more row is selected to find out whether there is the next page
+	err = query.Limit(filter.Limit + 1).Find(&rows).Error
+	if err != nil {
+		return list.ListPage{}, fmt.Errorf("can't select lists of user %s: %w", userID, err)
+	}
+
+	nextCursor := ""
+	if len(rows) > filter.Limit {
+		rows = rows[:filter.Limit]
+		nextCursor = encodeSummaryCursor(rows[len(rows)-1])
+	}
+
+	summaries, err := r.summarize(ctx, rows)
+	if err != nil {
+		return list.ListPage{}, err
+	}
+
+	return list.ListPage{Lists: summaries, NextCursor: nextCursor}, nil
+}
+
+// applySummaryCursor orders lists by filter and selects lists after the cursor, id makes the order unique
+func applySummaryCursor(query *gorm.DB, filter list.ListFilter) (*gorm.DB, error) {
+	var cursor mo.Option[summaryCursor]
+	if filter.Cursor != "" {
+		decoded, err := decodeSummaryCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor = mo.Some(decoded)
+	}
+
+	switch filter.Sort {
+	case list.ListSortTitle:
+		if c, found := cursor.Get(); found {
+			query = query.Where("(product_lists.title > ? OR (product_lists.title = ? AND product_lists.id > ?))",
+				c.Title, c.Title, c.ID)
+		}
+
+		return query.Order("product_lists.title").Order("product_lists.id"), nil
+	case list.ListSortCreatedAt, list.ListSortUpdatedAt:
+		column := lo.Ternary(filter.Sort == list.ListSortCreatedAt, "product_lists.created_at", "product_lists.updated_at")
+		if c, found := cursor.Get(); found {
+			last := lo.Ternary(filter.Sort == list.ListSortCreatedAt, c.CreatedAt, c.UpdatedAt)
+			query = query.Where("("+column+" < ? OR ("+column+" = ? AND product_lists.id < ?))", last, last, c.ID)
+		}
+
+		return query.Order(column + " DESC").Order("product_lists.id DESC"), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %d", myerr.ErrInvalidArgument, filter.Sort)
+	}
+}
+
+// summarize counts states and members of selected lists
+func (r *Repo) summarize(ctx context.Context, rows []summaryRow) ([]list.ListSummary, error) {
+	listIDs := lo.Map(rows, func(row summaryRow, _ int) string { return row.ID })
+	if len(listIDs) == 0 {
+		return []list.ListSummary{}, nil
+	}
+
+	var stateCounts []stateCountRow
+
+	err := r.db.WithContext(ctx).Model(new(ProductListState)).
+		Select("list_id, status, COUNT(*) AS count").
+		Where("list_id IN ?", listIDs).
+		Group("list_id, status").
+		Find(&stateCounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't count states of lists: %w", err)
+	}
+
+	var memberCounts []memberCountRow
+
+	err = r.db.WithContext(ctx).Model(new(ProductListMember)).
+		Select("list_id, COUNT(*) AS count").
+		Where("list_id IN ?", listIDs).
+		Group("list_id").
+		Find(&memberCounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't count members of lists: %w", err)
+	}
+
+	var purchases []purchaseRow
+
+	err = r.db.WithContext(ctx).Model(new(ProductListState)).
+		Select("list_id, status, price_amount, price_currency, replacement_price_amount, replacement_price_currency").
+		Where("list_id IN ?", listIDs).
+		Where("status IN ?", []int{int(list.StateStatusTaken), int(list.StateStatusReplaced)}).
+		Find(&purchases).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select purchases of lists: %w", err)
+	}
+
+	members := lo.SliceToMap(memberCounts, func(row memberCountRow) (string, int) { return row.ListID, row.Count })
+
+	return lo.Map(rows, func(row summaryRow, _ int) list.ListSummary {
+		summary := list.ListSummary{
+			ID:          id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(row.ID))},
+			Title:       row.Title,
+			Status:      list.ExecStatus(row.Status),
+			ShopMapID:   mo.None[id.ID[shopmap.ShopMap]](),
+			Role:        list.MemberType(row.MemberType),
+			Counts:      map[list.StateStatus]int{},
+			ItemCount:   0,
+			MemberCount: members[row.ID],
+			Spent:       spentByList(purchases, row.ID),
+			UpdatedAt:   row.UpdatedAt,
+			CreatedAt:   row.CreatedAt,
+		}
+
+		if row.ShopMapID != nil {
+			summary.ShopMapID = mo.Some(id.ID[shopmap.ShopMap]{UUID: god.Believe(uuid.Parse(*row.ShopMapID))})
+		}
+
+		for _, count := range stateCounts {
+			if count.ListID == row.ID {
+				summary.Counts[list.StateStatus(count.Status)] = count.Count
+				summary.ItemCount += count.Count
+			}
+		}
+
+		return summary
+	}), nil
+}
+
+// spentByList sums prices paid for products of the list, the price of replacement is paid for replaced product
+func spentByList(purchases []purchaseRow, listID string) []list.Price {
+	var prices []list.Price
+
+	for _, purchase := range purchases {
+		if purchase.ListID != listID {
+			continue
+		}
+
+		price := lo.Ternary(list.StateStatus(purchase.Status) == list.StateStatusReplaced,
+			purchase.ReplacementPrice, purchase.Price)
+		if model, found := priceToModel(price).Get(); found {
+			prices = append(prices, model)
+		}
+	}
+
+	return list.SumPrices(prices)
+}
+
+func encodeSummaryCursor(row summaryRow) string {
+	// every sort key of the last row is kept, so the cursor doesn't depend on the sort
+	data := god.Believe(json.Marshal(summaryCursor{
+		UpdatedAt: row.UpdatedAt,
+		CreatedAt: row.CreatedAt,
+		Title:     row.Title,
+		ID:        row.ID,
+	}))
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSummaryCursor(raw string) (summaryCursor, error) {
+	var cursor summaryCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: malformed cursor: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("%w: malformed cursor: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	return cursor, nil
+}
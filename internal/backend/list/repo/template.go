@@ -0,0 +1,452 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+type ProductListTemplate struct {
+	ID        string                      `gorm:"primaryKey;size:36;notNull"`
+	OwnerID   string                      `gorm:"size:36;notNull;index"`
+	Title     string                      `gorm:"notNull;size:255"`
+	ShopMapID *string                     `gorm:"size:36"`
+	CreatedAt time.Time                   `gorm:"notNull"`
+	UpdatedAt time.Time                   `gorm:"notNull"`
+	Members   []ProductListTemplateMember `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE"`
+	Items     []ProductListTemplateItem   `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE"`
+}
+
+type ProductListTemplateMember struct {
+	ID         string `gorm:"primaryKey;size:36;notNull"`
+	TemplateID string `gorm:"size:36;notNull;uniqueIndex:idx_template_user"`
+	UserID     string `gorm:"size:36;notNull;uniqueIndex:idx_template_user"`
+	MemberType int32  `gorm:"notNull"`
+}
+
+type ProductListTemplateItem struct {
+	ID         string               `gorm:"primaryKey;size:36;notNull"`
+	TemplateID string               `gorm:"size:36;notNull;index"`
+	ProductID  string               `gorm:"size:36;notNull"`
+	Product    productRepo.Product  `gorm:"references:ID"`
+	Index      int64                `gorm:"notNull"`
+	Quantity   productRepo.Quantity `gorm:"embedded;embeddedPrefix:quantity_"`
+	FormIdx    *int32
+	Note       string `gorm:"notNull;default:''"`
+}
+
+type ProductListSchedule struct {
+	ID         string     `gorm:"primaryKey;size:36;notNull"`
+	TemplateID string     `gorm:"size:36;notNull;index"`
+	OwnerID    string     `gorm:"size:36;notNull"`
+	Period     int32      `gorm:"notNull"`
+	Weekday    int32      `gorm:"notNull"`
+	Day        int32      `gorm:"notNull"`
+	Time       string     `gorm:"notNull;size:5"`
+	Cron       string     `gorm:"notNull;size:255"`
+	Timezone   string     `gorm:"notNull;size:64"`
+	NextRunAt  time.Time  `gorm:"notNull;index"`
+	LastRunAt  *time.Time `gorm:""`
+	LastListID *string    `gorm:"size:36"`
+	CreatedAt  time.Time  `gorm:"notNull"`
+}
+
+func (r *Repo) CreateTemplate(ctx context.Context, model list.Template) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(templateToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert template %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetTemplate(ctx context.Context, templateID id.ID[list.Template]) (list.Template, error) {
+	entity, err := r.getTemplate(ctx, r.db, templateID)
+	if err != nil {
+		return list.Template{}, err
+	}
+
+	return templateToModel(entity), nil
+}
+
+// GetTemplatesByUserID returns templates which user owns or is a member of
+func (r *Repo) GetTemplatesByUserID(ctx context.Context, userID id.ID[user.User]) ([]list.Template, error) {
+	var entities []ProductListTemplate
+
+	memberOf := r.db.Model(new(ProductListTemplateMember)).Select("template_id").Where("user_id = ?", userID.String())
+
+	err := r.db.WithContext(ctx).
+		Preload("Members").
+		Preload("Items", orderByIndex).
+		Preload("Items.Product.Forms").
+		Preload("Items.Product.Category").
+		Where("owner_id = ? OR id IN (?)", userID.String(), memberOf).
+		Order("created_at").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select templates of user %s: %w", userID, err)
+	}
+
+	return lo.Map(entities, func(item ProductListTemplate, _ int) list.Template { return templateToModel(item) }), nil
+}
+
+// GetAndUpdateTemplate replaces template by result of updateFunc
+func (r *Repo) GetAndUpdateTemplate(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	updateFunc func(list.Template) (list.Template, error),
+) (
+	list.Template,
+	error,
+) {
+	var model list.Template
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getTemplate(ctx, tx, templateID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(templateToModel(entity)); err != nil {
+			return err
+		}
+
+		entity = templateToEntity(model)
+
+		err = tx.WithContext(ctx).Where("template_id = ?", templateID.String()).
+			Delete(new(ProductListTemplateMember)).Error
+		if err != nil {
+			return fmt.Errorf("can't delete members of template %s: %w", templateID, err)
+		}
+
+		if len(entity.Members) != 0 {
+			if err = tx.WithContext(ctx).Create(&entity.Members).Error; err != nil {
+				return fmt.Errorf("can't insert members of template %s: %w", templateID, err)
+			}
+		}
+
+		query := &ProductListTemplate{ID: entity.ID} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Model(query).Select("title", "shop_map_id", "updated_at").Updates(&entity).Error
+		if err != nil {
+			return fmt.Errorf("can't update template %s: %w", templateID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return list.Template{}, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return model, nil
+}
+
+// DeleteTemplate deletes template with its schedules if checkFunc allows it
+func (r *Repo) DeleteTemplate(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	checkFunc func(list.Template) error,
+) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getTemplate(ctx, tx, templateID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkFunc(templateToModel(entity)); err != nil {
+			return err
+		}
+
+		for _, child := range []any{
+			new(ProductListTemplateMember),
+			new(ProductListTemplateItem),
+			new(ProductListSchedule),
+		} {
+			if err = tx.WithContext(ctx).Where("template_id = ?", templateID.String()).Delete(child).Error; err != nil {
+				return fmt.Errorf("can't delete contents of template %s: %w", templateID, err)
+			}
+		}
+
+		if err = tx.WithContext(ctx).Delete(&ProductListTemplate{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+			return fmt.Errorf("can't delete template %s: %w", templateID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) CreateSchedule(ctx context.Context, model list.Schedule) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(scheduleToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert schedule %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetSchedules(ctx context.Context, templateID id.ID[list.Template]) ([]list.Schedule, error) {
+	var entities []ProductListSchedule
+
+	err := r.db.WithContext(ctx).Where("template_id = ?", templateID.String()).Order("created_at").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select schedules of template %s: %w", templateID, err)
+	}
+
+	return lo.Map(entities, func(item ProductListSchedule, _ int) list.Schedule { return scheduleToModel(item) }), nil
+}
+
+func (r *Repo) DeleteSchedule(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	scheduleID id.ID[list.Schedule],
+) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND template_id = ?", scheduleID.String(), templateID.String()).
+		Delete(new(ProductListSchedule))
+	if result.Error != nil {
+		return fmt.Errorf("can't delete schedule %s: %w", scheduleID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: schedule %s of template %s", myerr.ErrNotFound, scheduleID, templateID)
+	}
+
+	return nil
+}
+
+// GetDueSchedules returns at most limit schedules which should have fired by now, the most late go first
+func (r *Repo) GetDueSchedules(ctx context.Context, now time.Time, limit int) ([]list.Schedule, error) {
+	var entities []ProductListSchedule
+
+	err := r.db.WithContext(ctx).Where("next_run_at <= ?", now.UTC()).Order("next_run_at").Limit(limit).
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select due schedules: %w", err)
+	}
+
+	return lo.Map(entities, func(item ProductListSchedule, _ int) list.Schedule { return scheduleToModel(item) }), nil
+}
+
+// RunSchedule moves schedule to the next run and creates list by createFunc in the same transaction.
+// Schedule is claimed only if its next run is still the same as in the given model,
+// so when several replicas run it at once only one of them creates a list and others get ErrConflict.
+func (r *Repo) RunSchedule(
+	ctx context.Context,
+	schedule list.Schedule,
+	next time.Time,
+	meta list.ChangeMeta,
+	createFunc func(list.Template) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+) {
+	var model list.ProductList
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		if err := claimSchedule(ctx, tx, schedule, next); err != nil {
+			return err
+		}
+
+		entity, err := r.getTemplate(ctx, tx, schedule.TemplateID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = createFunc(templateToModel(entity)); err != nil {
+			return err
+		}
+
+		if err = r.createList(ctx, tx, model, meta); err != nil {
+			return err
+		}
+
+		query := &ProductListSchedule{ID: schedule.ID.String()} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Model(query).Update("last_list_id", model.ID.String()).Error
+		if err != nil {
+			return fmt.Errorf("can't save last list of schedule %s: %w", schedule.ID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return model, nil
+}
+
+// SkipSchedule moves schedule to the next run without creating a list, it's claimed the same way as in RunSchedule
+func (r *Repo) SkipSchedule(ctx context.Context, schedule list.Schedule, next time.Time) error {
+	if err := claimSchedule(ctx, r.db, schedule, next); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func claimSchedule(ctx context.Context, tx *gorm.DB, schedule list.Schedule, next time.Time) error {
+	result := tx.WithContext(ctx).Model(new(ProductListSchedule)).
+		Where("id = ? AND next_run_at = ?", schedule.ID.String(), schedule.NextRunAt.UTC()).
+		Updates(map[string]any{"next_run_at": next.UTC(), "last_run_at": schedule.NextRunAt.UTC()})
+	if result.Error != nil {
+		return fmt.Errorf("can't claim schedule %s: %w", schedule.ID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: schedule %s was already run or deleted", myerr.ErrConflict, schedule.ID)
+	}
+
+	return nil
+}
+
+func (r *Repo) getTemplate(ctx context.Context, tx *gorm.DB, templateID id.ID[list.Template]) (
+	ProductListTemplate,
+	error,
+) {
+	var entity ProductListTemplate
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Members").
+		Preload("Items", orderByIndex).
+		Preload("Items.Product.Forms").
+		Preload("Items.Product.Category").
+		Where("id = ?", templateID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: template %s", myerr.ErrNotFound, templateID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select template %s: %w", templateID, err)
+	}
+
+	return entity, nil
+}
+
+func orderByIndex(db *gorm.DB) *gorm.DB {
+	return db.Order("`index`")
+}
+
+func templateToModel(entity ProductListTemplate) list.Template {
+	return list.Template{
+		TemplateOptions: list.TemplateOptions{
+			Title: entity.Title,
+			ShopMapID: lo.If(entity.ShopMapID == nil, mo.None[id.ID[shopmap.ShopMap]]()).
+				ElseF(func() mo.Option[id.ID[shopmap.ShopMap]] {
+					return mo.Some(id.ID[shopmap.ShopMap]{UUID: god.Believe(uuid.Parse(*entity.ShopMapID))})
+				}),
+			Members: lo.Map(entity.Members, func(item ProductListTemplateMember, _ int) list.MemberOptions {
+				return list.MemberOptions{
+					UserID: id.ID[user.User]{UUID: god.Believe(uuid.Parse(item.UserID))},
+					Role:   list.MemberType(item.MemberType),
+				}
+			}),
+		},
+		ID:      id.ID[list.Template]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		OwnerID: id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		Items: lo.Map(entity.Items, func(item ProductListTemplateItem, _ int) list.TemplateItem {
+			return list.TemplateItem{
+				Product:   productRepo.EntityToModel(item.Product),
+				Quantity:  productRepo.QuantityToModel(item.Quantity, nil),
+				FormIndex: mo.PointerToOption(item.FormIdx),
+				Note:      item.Note,
+			}
+		}),
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func templateToEntity(model list.Template) ProductListTemplate {
+	return ProductListTemplate{
+		ID:        model.ID.String(),
+		OwnerID:   model.OwnerID.String(),
+		Title:     model.Title,
+		ShopMapID: lo.If(model.ShopMapID.IsAbsent(), (*string)(nil)).Else(lo.ToPtr(model.ShopMapID.OrEmpty().String())),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		Members: lo.Map(model.Members, func(item list.MemberOptions, _ int) ProductListTemplateMember {
+			return ProductListTemplateMember{
+				ID:         uuid.NewString(),
+				TemplateID: model.ID.String(),
+				UserID:     item.UserID.String(),
+				MemberType: int32(item.Role),
+			}
+		}),
+		Items: lo.Map(model.Items, func(item list.TemplateItem, index int) ProductListTemplateItem {
+			return ProductListTemplateItem{
+				ID:         uuid.NewString(),
+				TemplateID: model.ID.String(),
+				ProductID:  item.Product.ID.String(),
+				Product:    productRepo.Product{ID: item.Product.ID.String()}, //nolint:exhaustruct
+				Index:      int64(index),
+				Quantity:   productRepo.QuantityToEntity(item.Quantity),
+				FormIdx:    item.FormIndex.ToPointer(),
+				Note:       item.Note,
+			}
+		}),
+	}
+}
+
+func scheduleToModel(entity ProductListSchedule) list.Schedule {
+	return list.Schedule{
+		ScheduleOptions: list.ScheduleOptions{
+			Period:   list.SchedulePeriod(entity.Period),
+			Weekday:  int(entity.Weekday),
+			Day:      int(entity.Day),
+			Time:     entity.Time,
+			Cron:     entity.Cron,
+			Timezone: entity.Timezone,
+		},
+		ID:         id.ID[list.Schedule]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		TemplateID: id.ID[list.Template]{UUID: god.Believe(uuid.Parse(entity.TemplateID))},
+		OwnerID:    id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		NextRunAt:  entity.NextRunAt,
+		LastRunAt:  mo.PointerToOption(entity.LastRunAt),
+		LastListID: lo.If(entity.LastListID == nil, mo.None[id.ID[list.ProductList]]()).
+			ElseF(func() mo.Option[id.ID[list.ProductList]] {
+				return mo.Some(id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(*entity.LastListID))})
+			}),
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+func scheduleToEntity(model list.Schedule) ProductListSchedule {
+	return ProductListSchedule{
+		ID:         model.ID.String(),
+		TemplateID: model.TemplateID.String(),
+		OwnerID:    model.OwnerID.String(),
+		Period:     int32(model.Period),
+		Weekday:    int32(model.Weekday),
+		Day:        int32(model.Day),
+		Time:       model.Time,
+		Cron:       model.Cron,
+		Timezone:   model.Timezone,
+		// times are kept in UTC, so they are compared correctly by databases which store them as strings
+		NextRunAt: model.NextRunAt.UTC(),
+		LastRunAt: lo.If(model.LastRunAt.IsAbsent(), (*time.Time)(nil)).Else(lo.ToPtr(model.LastRunAt.OrEmpty().UTC())),
+		LastListID: lo.If(model.LastListID.IsAbsent(), (*string)(nil)).
+			Else(lo.ToPtr(model.LastListID.OrEmpty().String())),
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+// GetAndTrashList moves list to the trash, validateFunc checks that user can delete the list
+func (r *Repo) GetAndTrashList(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	deletedAt time.Time,
+	validateFunc func(list.ProductList) error,
+) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		model, err := r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		if err = validateFunc(model); err != nil {
+			return err
+		}
+
+		if err = trashList(ctx, tx, listID, meta.Author, deletedAt); err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, model, meta)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
+
+func trashList(
+	ctx context.Context,
+	tx *gorm.DB,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	deletedAt time.Time,
+) error {
+	entity := ProductList{ID: listID.String()} //nolint:exhaustruct
+
+	// columns are updated directly, so update time of the list stays the same
+	err := tx.WithContext(ctx).Model(&entity).
+		UpdateColumns(map[string]any{"deleted_at": deletedAt.UTC(), "deleted_by": userID.String()}).Error
+	if err != nil {
+		return fmt.Errorf("can't move product list %s to the trash: %w", listID, err)
+	}
+
+	return nil
+}
+
+// GetTrash returns lists moved to the trash after given time, which user administrates
+func (r *Repo) GetTrash(ctx context.Context, userID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	var entities []ProductList
+
+	administrated := r.db.Model(new(ProductListMember)).
+		Select("list_id").
+		Where("user_id = ? AND member_type <= ?", userID.String(), int32(list.MemberTypeAdmin))
+
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at > ? AND id IN (?)", after.UTC(), administrated).
+		Order("deleted_at DESC").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select trashed lists of user %s: %w", userID, err)
+	}
+
+	return lo.Map(entities, func(entity ProductList, _ int) trash.Item {
+		return trash.Item{
+			Kind:      trash.KindList,
+			ID:        god.Believe(uuid.Parse(entity.ID)),
+			Title:     entity.Title,
+			DeletedBy: id.ID[user.User]{UUID: god.Believe(uuid.Parse(lo.FromPtr(entity.DeletedBy)))},
+			DeletedAt: entity.DeletedAt.Time,
+			ExpiresAt: time.Time{},
+		}
+	}), nil
+}
+
+// RestoreList takes list moved to the trash after given time back, validateFunc checks that user can restore it
+func (r *Repo) RestoreList(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	after time.Time,
+	meta list.ChangeMeta,
+	validateFunc func(list.ProductList) error,
+) (
+	list.ProductList,
+	error,
+) {
+	var model list.ProductList
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		var entity ProductList
+
+		err := preloadList(tx.WithContext(ctx).Unscoped().Clauses(clause.Locking{Strength: "UPDATE"})).
+			Where("id = ? AND deleted_at > ?", listID.String(), after.UTC()).
+			First(&entity).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: list %s isn't in the trash", myerr.ErrNotFound, listID)
+		}
+
+		if err != nil {
+			return fmt.Errorf("can't select trashed product list %s: %w", listID, err)
+		}
+
+		if err = validateFunc(entityToModel(entity)); err != nil {
+			return err
+		}
+
+		restored := ProductList{ID: entity.ID} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Unscoped().Model(&restored).
+			UpdateColumns(map[string]any{"deleted_at": nil, "deleted_by": nil}).Error
+		if err != nil {
+			return fmt.Errorf("can't restore product list %s: %w", listID, err)
+		}
+
+		if model, err = r.getProductList(ctx, tx, listID); err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, model, meta)
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// PurgeLists deletes at most limit lists moved to the trash before given time together with all their rows
+func (r *Repo) PurgeLists(ctx context.Context, before time.Time, limit int) ([]list.ProductList, error) {
+	var entities []ProductList
+
+	err := preloadList(r.db.WithContext(ctx).Unscoped()).
+		Where("deleted_at < ?", before.UTC()).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select expired lists: %w", err)
+	}
+
+	for _, entity := range entities {
+		err = dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+			return purgeList(ctx, tx, entity)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+		}
+	}
+
+	return lo.Map(entities, func(entity ProductList, _ int) list.ProductList { return entityToModel(entity) }), nil
+}
+
+func purgeList(ctx context.Context, tx *gorm.DB, entity ProductList) error {
+	stateIDs := lo.Map(entity.States, func(state ProductListState, _ int) string { return state.ID })
+	if len(stateIDs) != 0 {
+		for _, table := range []any{new(ProductListComment), new(ProductListAttachment)} {
+			if err := tx.WithContext(ctx).Where("state_id IN ?", stateIDs).Delete(table).Error; err != nil {
+				return fmt.Errorf("can't delete threads of product list %s: %w", entity.ID, err)
+			}
+		}
+	}
+
+	sessionIDs := tx.Model(new(ProductListSession)).Select("id").Where("list_id = ?", entity.ID)
+	err := tx.WithContext(ctx).Where("session_id IN (?)", sessionIDs).Delete(new(ProductListSessionSpending)).Error
+	if err != nil {
+		return fmt.Errorf("can't delete spending of sessions of product list %s: %w", entity.ID, err)
+	}
+
+	tables := []any{
+		new(ProductListState),
+		new(ProductListMember),
+		new(ProductListSession),
+		new(ProductListUndoRecord),
+		new(ProductListCountedList),
+	}
+	for _, table := range tables {
+		if err := tx.WithContext(ctx).Where("list_id = ?", entity.ID).Delete(table).Error; err != nil {
+			return fmt.Errorf("can't delete rows of product list %s: %w", entity.ID, err)
+		}
+	}
+
+	//nolint:exhaustruct
+	if err := tx.WithContext(ctx).Unscoped().Delete(&ProductList{ID: entity.ID}).Error; err != nil {
+		return fmt.Errorf("can't delete product list %s: %w", entity.ID, err)
+	}
+
+	return nil
+}
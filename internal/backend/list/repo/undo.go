@@ -0,0 +1,356 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+const (
+	undoStatusApplied int32 = iota + 1
+	undoStatusUndone
+	// undone records are discarded when author makes new change, so they can't be redone
+	undoStatusDiscarded
+)
+
+// ProductListUndoRecord is a list before and after a change, it's kept to undo the change
+type ProductListUndoRecord struct {
+	ID        string     `gorm:"primaryKey;size:36;notNull"`
+	ListID    string     `gorm:"size:36;notNull;index:idx_list_author_undo"`
+	AuthorID  string     `gorm:"size:36;notNull;index:idx_list_author_undo"`
+	EventType int32      `gorm:"notNull"`
+	Status    int32      `gorm:"notNull"`
+	CreatedAt time.Time  `gorm:"notNull"`
+	UndoneAt  *time.Time `gorm:""`
+	Before    []byte     `gorm:"notNull"`
+	After     []byte     `gorm:"notNull"`
+}
+
+// GetAndUndo passes last count changes of author, which are not undone yet, from the newest to the oldest one
+func (r *Repo) GetAndUndo(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	count int,
+	revertFunc func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+) {
+	return r.getAndRevert(ctx, listID, meta, count, revertFunc, undoStatusApplied, undoStatusUndone,
+		"created_at DESC")
+}
+
+// GetAndRedo passes last count undone changes of author in order they must be redone
+func (r *Repo) GetAndRedo(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	count int,
+	revertFunc func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+) {
+	return r.getAndRevert(ctx, listID, meta, count, revertFunc, undoStatusUndone, undoStatusApplied,
+		"undone_at DESC, created_at ASC")
+}
+
+func (r *Repo) getAndRevert(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	meta list.ChangeMeta,
+	count int,
+	revertFunc func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+	fromStatus, toStatus int32,
+	order string,
+) (
+	list.ProductList,
+	error,
+) {
+	var model list.ProductList
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		var entities []ProductListUndoRecord
+
+		oldEntity, err := r.getEntity(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.WithContext(ctx).
+			Where("list_id = ? AND author_id = ? AND status = ?", listID.String(), meta.Author.String(), fromStatus).
+			Order(order).
+			Limit(count).
+			Find(&entities).Error
+		if err != nil {
+			return fmt.Errorf("can't select undo records of list %s: %w", listID, err)
+		}
+
+		if len(entities) == 0 {
+			return fmt.Errorf("%w: no changes of user %s to revert in list %s", myerr.ErrNotFound, meta.Author, listID)
+		}
+
+		records := make([]list.UndoRecord, 0, len(entities))
+		ids := make([]string, 0, len(entities))
+		for _, entity := range entities {
+			record, err := undoRecordToModel(entity)
+			if err != nil {
+				return err
+			}
+
+			records = append(records, record)
+			ids = append(ids, entity.ID)
+		}
+
+		model, err = revertFunc(entityToModel(oldEntity), records)
+		if err != nil {
+			return err
+		}
+
+		if err = r.saveDiff(ctx, tx, oldEntity, model, meta.At); err != nil {
+			return err
+		}
+
+		var undoneAt *time.Time
+		if toStatus == undoStatusUndone {
+			undoneAt = lo.ToPtr(meta.At)
+		}
+
+		err = tx.WithContext(ctx).Model(new(ProductListUndoRecord)).Where("id in ?", ids).
+			Updates(map[string]any{"status": toStatus, "undone_at": undoneAt}).Error
+		if err != nil {
+			return fmt.Errorf("can't update undo records of list %s: %w", listID, err)
+		}
+
+		model, err = r.getProductList(ctx, tx, listID)
+		if err != nil {
+			return err
+		}
+
+		return r.history.commit(ctx, tx, model, meta)
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// undoRecordsLimit is a number of the newest undo records kept for a list, older changes can't be undone
+const undoRecordsLimit = 100
+
+// undoSnapshot is a part of a list touched by a change.
+// Order keeps positions of all states, states absent in States weren't changed and have only product id.
+type undoSnapshot struct {
+	list.ListOptions
+
+	ID      id.ID[list.ProductList]  `json:"id"`
+	Members []list.Member            `json:"members"`
+	States  []list.ProductState      `json:"states"`
+	Order   []id.ID[product.Product] `json:"order"`
+}
+
+// saveUndoRecord stores new change of the author, undone changes of the author can't be redone after that.
+// Only members and states touched by the change are stored, the oldest records of the list are pruned.
+func saveUndoRecord(ctx context.Context, tx *gorm.DB, before, after list.ProductList, meta list.ChangeMeta) error {
+	beforeSnapshot, afterSnapshot := touchedParts(before, after)
+
+	encodedBefore, err := json.Marshal(beforeSnapshot)
+	if err != nil {
+		return fmt.Errorf("can't encode list %s before change: %w", before.ID, err)
+	}
+
+	encodedAfter, err := json.Marshal(afterSnapshot)
+	if err != nil {
+		return fmt.Errorf("can't encode list %s after change: %w", after.ID, err)
+	}
+
+	err = tx.WithContext(ctx).Model(new(ProductListUndoRecord)).
+		Where("list_id = ? AND author_id = ? AND status = ?", after.ID.String(), meta.Author.String(), undoStatusUndone).
+		Update("status", undoStatusDiscarded).Error
+	if err != nil {
+		return fmt.Errorf("can't discard undone changes of list %s: %w", after.ID, err)
+	}
+
+	err = tx.WithContext(ctx).Create(&ProductListUndoRecord{
+		ID:        uuid.NewString(),
+		ListID:    after.ID.String(),
+		AuthorID:  meta.Author.String(),
+		EventType: int32(meta.Type),
+		Status:    undoStatusApplied,
+		CreatedAt: meta.At,
+		UndoneAt:  nil,
+		Before:    encodedBefore,
+		After:     encodedAfter,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("can't save undo record of list %s: %w", after.ID, err)
+	}
+
+	return pruneUndoRecords(ctx, tx, after.ID)
+}
+
+// pruneUndoRecords deletes records of the list older than the last undoRecordsLimit ones
+func pruneUndoRecords(ctx context.Context, tx *gorm.DB, listID id.ID[list.ProductList]) error {
+	var oldest []ProductListUndoRecord
+
+	err := tx.WithContext(ctx).
+		Select("created_at").
+		Where("list_id = ?", listID.String()).
+		Order("created_at DESC").
+		Offset(undoRecordsLimit - 1).
+		Limit(1).
+		Find(&oldest).Error
+	if err != nil {
+		return fmt.Errorf("can't select oldest undo record of list %s: %w", listID, err)
+	}
+
+	if len(oldest) == 0 {
+		return nil
+	}
+
+	err = tx.WithContext(ctx).
+		Where("list_id = ? AND created_at < ?", listID.String(), oldest[0].CreatedAt).
+		Delete(new(ProductListUndoRecord)).Error
+	if err != nil {
+		return fmt.Errorf("can't prune undo records of list %s: %w", listID, err)
+	}
+
+	return nil
+}
+
+// touchedParts returns snapshots of members and states which differ in before and after
+func touchedParts(before, after list.ProductList) (undoSnapshot, undoSnapshot) {
+	beforeStates := lo.KeyBy(before.States, stateKey)
+	afterStates := lo.KeyBy(after.States, stateKey)
+
+	// reordered states are all touched, as positions of every one of them are restored on undo
+	reordered := !slices.Equal(commonOrder(before.States, afterStates), commonOrder(after.States, beforeStates))
+
+	touched := func(state list.ProductState, other map[id.ID[product.Product]]list.ProductState) bool {
+		otherState, found := other[state.Product.ID]
+
+		return !found || reordered ||
+			!statesEqual(stateToEntity(before.ID, state, 0), stateToEntity(before.ID, otherState, 0))
+	}
+
+	beforeMembers := lo.KeyBy(before.Members, func(m list.Member) id.ID[user.User] { return m.UserID })
+	afterMembers := lo.KeyBy(after.Members, func(m list.Member) id.ID[user.User] { return m.UserID })
+
+	changed := func(member list.Member, other map[id.ID[user.User]]list.Member) bool {
+		otherMember, found := other[member.UserID]
+
+		return !found || !membersEqual(memberToEntity(before.ID, member), memberToEntity(before.ID, otherMember))
+	}
+
+	snapshot := func(
+		model list.ProductList,
+		otherStates map[id.ID[product.Product]]list.ProductState,
+		otherMembers map[id.ID[user.User]]list.Member,
+	) undoSnapshot {
+		return undoSnapshot{
+			ListOptions: model.ListOptions,
+			ID:          model.ID,
+			Members:     lo.Filter(model.Members, func(m list.Member, _ int) bool { return changed(m, otherMembers) }),
+			States: lo.Filter(model.States, func(s list.ProductState, _ int) bool {
+				return touched(s, otherStates)
+			}),
+			Order: stateKeys(model.States),
+		}
+	}
+
+	return snapshot(before, afterStates, afterMembers), snapshot(after, beforeStates, beforeMembers)
+}
+
+// commonOrder returns ids of states in order of states, only states presented in other are returned
+func commonOrder(
+	states []list.ProductState,
+	other map[id.ID[product.Product]]list.ProductState,
+) []id.ID[product.Product] {
+	order := make([]id.ID[product.Product], 0, len(states))
+	for _, state := range states {
+		if _, found := other[state.Product.ID]; found {
+			order = append(order, state.Product.ID)
+		}
+	}
+
+	return order
+}
+
+func stateKey(s list.ProductState) id.ID[product.Product] {
+	return s.Product.ID
+}
+
+func stateKeys(states []list.ProductState) []id.ID[product.Product] {
+	keys := make([]id.ID[product.Product], 0, len(states))
+	for _, state := range states {
+		keys = append(keys, state.Product.ID)
+	}
+
+	return keys
+}
+
+// snapshotToModel restores list from the snapshot, untouched states have only product ids,
+// so they are equal in both snapshots of a record. Records saved before snapshots have no order.
+func snapshotToModel(snapshot undoSnapshot) list.ProductList {
+	model := list.ProductList{
+		ListOptions: snapshot.ListOptions,
+		ID:          snapshot.ID,
+		Members:     snapshot.Members,
+		States:      snapshot.States,
+		UpdatedAt:   date.UpdateDate[list.ProductList]{Time: time.Time{}},
+		CreatedAt:   date.CreateDate[list.ProductList]{Time: time.Time{}},
+	}
+
+	if snapshot.Order == nil {
+		return model
+	}
+
+	states := lo.KeyBy(snapshot.States, stateKey)
+
+	model.States = lo.Map(snapshot.Order, func(productID id.ID[product.Product], _ int) list.ProductState {
+		if state, found := states[productID]; found {
+			return state
+		}
+
+		return list.ProductState{Product: product.Product{ID: productID}} //nolint:exhaustruct
+	})
+
+	return model
+}
+
+func undoRecordToModel(entity ProductListUndoRecord) (list.UndoRecord, error) {
+	var before, after undoSnapshot
+
+	if err := json.Unmarshal(entity.Before, &before); err != nil {
+		return list.UndoRecord{}, fmt.Errorf("can't decode undo record %s: %w", entity.ID, err)
+	}
+
+	if err := json.Unmarshal(entity.After, &after); err != nil {
+		return list.UndoRecord{}, fmt.Errorf("can't decode undo record %s: %w", entity.ID, err)
+	}
+
+	return list.UndoRecord{
+		ID:     entity.ID,
+		Author: id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.AuthorID))},
+		Type:   list.EventType(entity.EventType),
+		Before: snapshotToModel(before),
+		After:  snapshotToModel(after),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package list
+
+import (
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+)
+
+// ScanResult is an outcome of scanning a barcode in a list
+type ScanResult struct {
+	Product product.Product `json:"product"`
+	// Taken is a state of the scanned product which became taken, it's null when the product isn't waiting in the list
+	Taken mo.Option[ProductState] `json:"taken" extensions:"x-nullable"`
+	// Replaceable are products of waiting states which the scanned product may replace
+	Replaceable []id.ID[product.Product] `json:"replaceable" swaggertype:"array,string"`
+}
+
+// WaitingState returns waiting state of the product
+func (l ProductList) WaitingState(productID id.ID[product.Product]) (ProductState, bool) {
+	for _, state := range l.States {
+		if state.Product.ID == productID && state.Status == StateStatusWaiting {
+			return state, true
+		}
+	}
+
+	return ProductState{}, false
+}
+
+// ReplacementCandidates returns products of waiting states which the scanned product may replace,
+// they're of the same category unless the scanned product or the product of the state has no category
+func (l ProductList) ReplacementCandidates(scanned product.Product) []id.ID[product.Product] {
+	candidates := make([]id.ID[product.Product], 0)
+
+	for _, state := range l.States {
+		if state.Status != StateStatusWaiting || state.Product.ID == scanned.ID {
+			continue
+		}
+
+		category, found := scanned.Category.Get()
+		if listed, ok := state.Product.Category.Get(); found && ok && listed != category {
+			continue
+		}
+
+		candidates = append(candidates, state.Product.ID)
+	}
+
+	return candidates
+}
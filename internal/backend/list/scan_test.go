@@ -0,0 +1,49 @@
+package list_test
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+)
+
+func TestReplacementCandidates(t *testing.T) {
+	newProduct := func(category string) product.Product {
+		return product.Product{
+			Options: product.Options{Category: mo.EmptyableToOption(product.Category(category))},
+			ID:      id.NewID[product.Product](),
+		}
+	}
+	newState := func(model product.Product, status list.StateStatus) list.ProductState {
+		return list.ProductState{ProductStateOptions: list.ProductStateOptions{Status: status}, Product: model}
+	}
+
+	milk, kefir, cream, bread := newProduct("dairy"), newProduct("dairy"), newProduct("dairy"), newProduct("bakery")
+	salt := newProduct("")
+	model := list.ProductList{States: []list.ProductState{
+		newState(milk, list.StateStatusWaiting),
+		newState(kefir, list.StateStatusTaken),
+		newState(cream, list.StateStatusWaiting),
+		newState(bread, list.StateStatusWaiting),
+		newState(salt, list.StateStatusWaiting),
+	}}
+
+	state, found := model.WaitingState(milk.ID)
+	require.True(t, found)
+	require.Equal(t, milk.ID, state.Product.ID)
+
+	_, found = model.WaitingState(kefir.ID)
+	require.False(t, found)
+
+	// states without category may be replaced by any product
+	dairy := []id.ID[product.Product]{milk.ID, cream.ID, salt.ID}
+	require.Equal(t, dairy, model.ReplacementCandidates(newProduct("dairy")))
+	require.Equal(t, []id.ID[product.Product]{cream.ID, salt.ID}, model.ReplacementCandidates(milk))
+	all := []id.ID[product.Product]{milk.ID, cream.ID, bread.ID, salt.ID}
+	require.Equal(t, all, model.ReplacementCandidates(newProduct("")))
+	require.Equal(t, []id.ID[product.Product]{milk.ID, cream.ID, bread.ID}, model.ReplacementCandidates(salt))
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// placement puts new states of a list into positions by categories of its default shop map
+type placement struct {
+	categories []product.Category
+	// products are new products with categories, states are created only with product ids
+	products map[id.ID[product.Product]]product.Product
+}
+
+// Arrange orders states by categories of shop map, default shop map of the list is used if mapID is absent.
+// States without category or with category missing in the map go last, order inside categories is kept.
+func (s *Service) Arrange(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	mapID mo.Option[id.ID[shopmap.ShopMap]],
+) (
+	list.ProductList,
+	error,
+) {
+	if mapID.IsAbsent() {
+		model, err := s.GetByID(ctx, listID, userID)
+		if err != nil {
+			return list.ProductList{}, err
+		}
+
+		if mapID = model.ShopMapID; mapID.IsAbsent() {
+			return list.ProductList{}, fmt.Errorf("%w: list %s has no default shop map", myerr.ErrInvalidArgument, listID)
+		}
+	}
+
+	shopMap, err := s.getShopMap(ctx, userID, mapID.MustGet())
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	var ids []id.ID[product.Product]
+
+	checkFunc, ch := list.CheckRole(userID, list.MemberTypeEditor)
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeStatesReordered, At: s.clock.Now()}
+	model, err := s.repo.ApplyOrder(ctx, checkFunc, listID, meta, func(model list.ProductList) (
+		[]id.ID[product.Product],
+		error,
+	) {
+		if len(model.States) == 0 {
+			return nil, fmt.Errorf("%w: list is empty", myerr.ErrInvalidArgument)
+		}
+
+		ids = arrangedOrder(model.States, shopMap.CategoryList)
+
+		return ids, nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't arrange list %s by shop map %s: %w", listID, shopMap.ID, err)
+	}
+
+	member := <-ch
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeStatesReordered,
+		Data: list.StatesReorderedChange{IDs: ids},
+	})
+
+	return model, nil
+}
+
+// getShopMap returns shop map if user is its owner or viewer
+func (s *Service) getShopMap(
+	ctx context.Context,
+	userID id.ID[user.User],
+	mapID id.ID[shopmap.ShopMap],
+) (
+	shopmap.ShopMap,
+	error,
+) {
+	shopMap, err := s.shopMaps.GetByID(ctx, mapID)
+	if err != nil {
+		return shopmap.ShopMap{}, fmt.Errorf("can't get shop map %s: %w", mapID, err)
+	}
+
+	if shopMap.OwnerID != userID && !slices.Contains(shopMap.ViewerIDList, userID) {
+		return shopmap.ShopMap{}, fmt.Errorf("%w: only members of shop map %s can use it", myerr.ErrForbidden, mapID)
+	}
+
+	return shopMap, nil
+}
+
+// checkShopMap checks that user can use shop map which is going to be default map of a list
+func (s *Service) checkShopMap(
+	ctx context.Context,
+	userID id.ID[user.User],
+	mapID mo.Option[id.ID[shopmap.ShopMap]],
+) error {
+	if mapID, found := mapID.Get(); found {
+		if _, err := s.getShopMap(ctx, userID, mapID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newPlacement returns placement by default shop map of the list, it's absent if the list has no default map.
+// It's called inside of list update, so the map is the one the list has when the states are inserted.
+func (s *Service) newPlacement(
+	ctx context.Context,
+	model list.ProductList,
+	productIDs []id.ID[product.Product],
+) (
+	mo.Option[placement],
+	error,
+) {
+	mapID, found := model.ShopMapID.Get()
+	if !found {
+		return mo.None[placement](), nil
+	}
+
+	shopMap, err := s.shopMaps.GetByID(ctx, mapID)
+	if err != nil {
+		// the map could be deleted after it was linked, then states are just appended
+		s.log.Warn().Err(err).Stringer("list_id", model.ID).Stringer("map_id", mapID).Msg("can't get default shop map")
+		return mo.None[placement](), nil
+	}
+
+	// products of states taken from other lists have categories already
+	byID := make(map[id.ID[product.Product]]product.Product, len(productIDs))
+	if len(productIDs) == 0 {
+		return mo.Some(placement{categories: shopMap.CategoryList, products: byID}), nil
+	}
+
+	products, err := s.products.IDList(ctx, productIDs)
+	if err != nil {
+		return mo.None[placement](), fmt.Errorf("can't get categories of new products: %w", err)
+	}
+
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	return mo.Some(placement{categories: shopMap.CategoryList, products: byID}), nil
+}
+
+// insertState inserts state before the first state of later category of default shop map of the list,
+// the state is appended if there is no placement.
+// It returns inserted state and whether it isn't the last one.
+func insertState(model list.ProductList, state list.ProductState, place mo.Option[placement]) (
+	list.ProductList,
+	list.ProductState,
+	bool,
+) {
+	p, found := place.Get()
+	if !found {
+		model.States = append(model.States, state)
+		return model, state, false
+	}
+
+	if full, found := p.products[state.Product.ID]; found {
+		state.Product = full
+	}
+
+	rank := categoryRank(p.categories, state.Product)
+	idx := slices.IndexFunc(model.States, func(s list.ProductState) bool {
+		return categoryRank(p.categories, s.Product) > rank
+	})
+
+	if idx == -1 {
+		model.States = append(model.States, state)
+		return model, state, false
+	}
+
+	model.States = slices.Insert(model.States, idx, state)
+
+	return model, state, true
+}
+
+// arrangedOrder returns product ids of states stable sorted by categories
+func arrangedOrder(states []list.ProductState, categories []product.Category) []id.ID[product.Product] {
+	sorted := slices.Clone(states)
+	slices.SortStableFunc(sorted, func(a, b list.ProductState) int {
+		return cmp.Compare(categoryRank(categories, a.Product), categoryRank(categories, b.Product))
+	})
+
+	ids := make([]id.ID[product.Product], 0, len(sorted))
+	for _, state := range sorted {
+		ids = append(ids, state.Product.ID)
+	}
+
+	return ids
+}
+
+// categoryRank is a position of product category in categories, products without known category go last
+func categoryRank(categories []product.Category, model product.Product) int {
+	if category, found := model.Category.Get(); found {
+		if idx := slices.Index(categories, category); idx != -1 {
+			return idx
+		}
+	}
+
+	return len(categories)
+}
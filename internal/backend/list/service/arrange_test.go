@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// addProduct creates a product of the category, it's uncategorized if category is empty.
+// Products get only existing categories, so the category is created if it's missing.
+func (f fixture) addProduct(t *testing.T, name product.Name, category product.Category) product.Product {
+	t.Helper()
+
+	normalized := product.NormalizeName(product.Name(category))
+	if _, err := productRepo.FindCategory(f.db, normalized); errors.Is(err, gorm.ErrRecordNotFound) && category != "" {
+		require.NoError(t, f.db.Create(&productRepo.ProductCategory{
+			ID:             uuid.NewString(),
+			Name:           string(category),
+			NormalizedName: normalized,
+			ParentID:       sql.NullString{String: "", Valid: false},
+			Names:          []productRepo.ProductCategoryName{},
+		}).Error)
+	}
+
+	model, err := f.catalog.Create(context.Background(), product.Options{
+		Name:     name,
+		Category: lo.Ternary(category == "", mo.None[product.Category](), mo.Some(category)),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: []product.Barcode{},
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+// appendInOrder appends products to the list one by one
+func (f fixture) appendInOrder(t *testing.T, products ...product.Product) {
+	t.Helper()
+
+	for _, p := range products {
+		_, err := f.service.AppendProducts(context.Background(), f.listID, f.ownerID(),
+			map[id.ID[product.Product]]list.ProductStateOptions{p.ID: {Status: list.StateStatusWaiting}})
+		require.NoError(t, err)
+	}
+}
+
+func TestArrange(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	editor := f.members[list.MemberTypeEditor]
+	uncategorized := productIDs(f.list(t))
+
+	cheese, salt, bun := f.addProduct(t, "cheese", "dairy"), f.addProduct(t, "salt", ""),
+		f.addProduct(t, "bun", "bakery")
+	f.appendInOrder(t, cheese, salt, bun)
+
+	shopMap := f.addShopMap("bakery", "dairy")
+	shopMap.ViewerIDList = []id.ID[user.User]{editor}
+	f.shopMaps[shopMap.ID] = shopMap
+
+	// order inside of categories is kept, products of unknown categories go last
+	model, err := f.service.Arrange(ctx, f.listID, editor, mo.Some(shopMap.ID))
+	require.NoError(t, err)
+	require.Equal(t, append(append([]id.ID[product.Product]{bun.ID, cheese.ID}, uncategorized...), salt.ID),
+		productIDs(model))
+	require.Equal(t, productIDs(model), productIDs(f.list(t)))
+
+	_, err = f.service.Arrange(ctx, f.listID, editor, mo.None[id.ID[shopmap.ShopMap]]())
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	// the default map of the list is used if the map isn't given
+	reversed := f.addShopMap("dairy", "bakery")
+	_, err = f.service.Update(ctx, f.listID, f.ownerID(), list.ListOptions{
+		Title:     "weekly",
+		ShopMapID: mo.Some(reversed.ID),
+	})
+	require.NoError(t, err)
+
+	model, err = f.service.Arrange(ctx, f.listID, f.ownerID(), mo.None[id.ID[shopmap.ShopMap]]())
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[product.Product]{cheese.ID, bun.ID}, productIDs(model)[:2])
+
+	_, err = f.service.Arrange(ctx, f.listID, editor, mo.None[id.ID[shopmap.ShopMap]]())
+	require.ErrorIs(t, err, myerr.ErrForbidden, "the editor isn't a member of the default map")
+
+	_, err = f.service.Arrange(ctx, f.listID, f.members[list.MemberTypeViewer], mo.Some(shopMap.ID))
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	_, err = f.service.Arrange(ctx, f.listID, editor, mo.Some(id.NewID[shopmap.ShopMap]()))
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
+
+func TestArrangeEmptyList(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	shopMap := f.addShopMap("dairy")
+
+	model, err := f.service.Create(ctx, f.ownerID(), list.ListOptions{Title: "empty"})
+	require.NoError(t, err)
+
+	_, err = f.service.Arrange(ctx, model.ID, f.ownerID(), mo.Some(shopMap.ID))
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestAppendPlacesByDefaultMap(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	uncategorized := productIDs(f.list(t))
+	shopMap := f.addShopMap("bakery", "dairy")
+
+	_, err := f.service.Update(ctx, f.listID, f.ownerID(), list.ListOptions{
+		Title:     "weekly",
+		ShopMapID: mo.Some(shopMap.ID),
+	})
+	require.NoError(t, err)
+
+	cheese, salt, bun := f.addProduct(t, "cheese", "dairy"), f.addProduct(t, "salt", ""),
+		f.addProduct(t, "bun", "bakery")
+	f.appendInOrder(t, cheese, salt, bun)
+
+	require.Equal(t, append(append([]id.ID[product.Product]{bun.ID, cheese.ID}, uncategorized...), salt.ID),
+		productIDs(f.list(t)))
+
+	// products added by a batch are placed by the map the list has when the batch is applied
+	kefir := f.addProduct(t, "kefir", "dairy")
+	result, err := f.service.ApplyBatch(ctx, f.listID, f.ownerID(), []list.Operation{{
+		Type:     list.OperationTypeAddProducts,
+		Products: map[id.ID[product.Product]]list.ProductStateOptions{kefir.ID: {Status: list.StateStatusWaiting}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[product.Product]{bun.ID, cheese.ID, kefir.ID}, productIDs(result.List)[:3])
+
+	// states are appended if the default map is gone
+	delete(f.shopMaps, shopMap.ID)
+	rye := f.addProduct(t, "rye", "bakery")
+	f.appendInOrder(t, rye)
+
+	ids := productIDs(f.list(t))
+	require.Equal(t, rye.ID, ids[len(ids)-1])
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/deepcopy"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// assignFunc checks that user can change assignee of the state and returns new assignee
+type assignFunc func(oldList list.ProductList, state list.ProductState) (mo.Option[id.ID[user.User]], error)
+
+// Assign sets assignee of product state, state is released when assignee is absent
+func (s *Service) Assign(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	opts list.AssignOptions,
+) (
+	list.ProductState,
+	error,
+) {
+	return s.assign(ctx, listID, userID, productID, func(
+		oldList list.ProductList,
+		_ list.ProductState,
+	) (
+		mo.Option[id.ID[user.User]],
+		error,
+	) {
+		if _, err := oldList.CheckRole(userID, list.MemberTypeEditor); err != nil {
+			return opts.Assignee, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		if assigneeID, found := opts.Assignee.Get(); found {
+			if _, err := oldList.CheckRole(assigneeID, list.MemberTypeExecuting); err != nil {
+				return opts.Assignee, fmt.Errorf("%w: member %s can't be assignee: %w",
+					myerr.ErrInvalidArgument, assigneeID, err)
+			}
+		}
+
+		return opts.Assignee, nil
+	})
+}
+
+// Claim assigns product state to the user, it fails if state is already claimed by other member
+func (s *Service) Claim(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+) (
+	list.ProductState,
+	error,
+) {
+	return s.assign(ctx, listID, userID, productID, func(
+		oldList list.ProductList,
+		state list.ProductState,
+	) (
+		mo.Option[id.ID[user.User]],
+		error,
+	) {
+		if _, err := oldList.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+			return state.Assignee, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		if assigneeID, found := state.Assignee.Get(); found && assigneeID != userID {
+			return state.Assignee, fmt.Errorf("%w: product %s is already claimed by %s",
+				myerr.ErrConflict, productID, assigneeID)
+		}
+
+		return mo.Some(userID), nil
+	})
+}
+
+// Release removes assignee of product state, only assignee or editors can do it
+func (s *Service) Release(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+) (
+	list.ProductState,
+	error,
+) {
+	return s.assign(ctx, listID, userID, productID, func(
+		oldList list.ProductList,
+		state list.ProductState,
+	) (
+		mo.Option[id.ID[user.User]],
+		error,
+	) {
+		role := list.MemberTypeEditor
+		if state.Assignee.OrEmpty() == userID {
+			role = list.MemberTypeExecuting
+		}
+
+		if _, err := oldList.CheckRole(userID, role); err != nil {
+			return state.Assignee, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		return mo.None[id.ID[user.User]](), nil
+	})
+}
+
+func (s *Service) assign(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	assignFunc assignFunc,
+) (
+	list.ProductState,
+	error,
+) {
+	var member list.Member
+	var state list.ProductState
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeStateAssigned, At: s.clock.Now()}
+	_, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
+		var err error
+
+		if member, err = oldList.CheckRole(userID, list.MemberTypeViewer); err != nil {
+			return oldList, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		newList := deepcopy.MustCopy(oldList)
+
+		idx := slices.IndexFunc(newList.States, func(s list.ProductState) bool { return s.Product.ID == productID })
+		if idx == -1 {
+			return oldList, fmt.Errorf("%w: product state with product id: %s", myerr.ErrNotFound, productID)
+		}
+
+		if newList.States[idx].Assignee, err = assignFunc(oldList, newList.States[idx]); err != nil {
+			return oldList, err
+		}
+
+		state = newList.States[idx]
+
+		return newList, nil
+	})
+	if err != nil {
+		return list.ProductState{}, fmt.Errorf("can't assign product state %s in list %s: %w", productID, listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeStateAssigned,
+		Data: list.AssigneeChange{ProductID: productID, Assignee: state.Assignee},
+	})
+
+	return state, nil
+}
+
+// releaseMembers removes assignees which are not members of the list anymore
+func releaseMembers(model list.ProductList, members []id.ID[user.User]) (list.ProductList, []list.Change) {
+	var changes []list.Change
+
+	for i, state := range model.States {
+		if assigneeID, found := state.Assignee.Get(); found && slices.Contains(members, assigneeID) {
+			model.States[i].Assignee = mo.None[id.ID[user.User]]()
+			changes = append(changes, list.Change{
+				Type: list.EventTypeStateAssigned,
+				Data: list.AssigneeChange{ProductID: state.Product.ID, Assignee: model.States[i].Assignee},
+			})
+		}
+	}
+
+	return model, changes
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestAssign(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	editor, executing := f.members[list.MemberTypeEditor], f.members[list.MemberTypeExecuting]
+	milk := f.products[0].ID
+
+	state, err := f.service.Assign(ctx, f.listID, editor, milk, list.AssignOptions{Assignee: mo.Some(executing)})
+	require.NoError(t, err)
+	require.Equal(t, mo.Some(executing), state.Assignee)
+	require.Equal(t, mo.Some(executing), stateOf(t, f.list(t), milk).Assignee)
+
+	// viewers don't shop, so they can't be assignees
+	_, err = f.service.Assign(ctx, f.listID, editor, milk, list.AssignOptions{
+		Assignee: mo.Some(f.members[list.MemberTypeViewer]),
+	})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	_, err = f.service.Assign(ctx, f.listID, executing, milk, list.AssignOptions{Assignee: mo.Some(executing)})
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	_, err = f.service.Assign(ctx, f.listID, editor, id.NewID[product.Product](), list.AssignOptions{})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+
+	state, err = f.service.Assign(ctx, f.listID, editor, milk, list.AssignOptions{})
+	require.NoError(t, err)
+	require.True(t, state.Assignee.IsAbsent())
+}
+
+func TestClaimAndRelease(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	editor, executing := f.members[list.MemberTypeEditor], f.members[list.MemberTypeExecuting]
+	milk := f.products[0].ID
+
+	_, err := f.service.Claim(ctx, f.listID, f.members[list.MemberTypeViewer], milk)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	state, err := f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+	require.Equal(t, mo.Some(executing), state.Assignee)
+
+	// claiming own product again changes nothing, claiming product of other member is a conflict
+	_, err = f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	_, err = f.service.Claim(ctx, f.listID, editor, milk)
+	require.ErrorIs(t, err, myerr.ErrConflict)
+
+	// the assignee and editors can release the product, other members can't
+	_, err = f.service.Release(ctx, f.listID, f.members[list.MemberTypeViewer], milk)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	state, err = f.service.Release(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+	require.True(t, state.Assignee.IsAbsent())
+
+	_, err = f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	state, err = f.service.Release(ctx, f.listID, editor, milk)
+	require.NoError(t, err)
+	require.True(t, state.Assignee.IsAbsent())
+
+	_, err = f.service.Claim(ctx, f.listID, editor, milk)
+	require.NoError(t, err)
+
+	_, err = f.service.Release(ctx, f.listID, executing, milk)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+}
+
+func TestDeleteMembersReleasesStates(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	editor, executing := f.members[list.MemberTypeEditor], f.members[list.MemberTypeExecuting]
+	milk, bread := f.products[0].ID, f.products[1].ID
+
+	_, err := f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	_, err = f.service.Claim(ctx, f.listID, editor, bread)
+	require.NoError(t, err)
+
+	model, err := f.service.DeleteMembers(ctx, f.listID, f.ownerID(), []id.ID[user.User]{executing})
+	require.NoError(t, err)
+	require.True(t, stateOf(t, model, milk).Assignee.IsAbsent())
+	require.Equal(t, mo.Some(editor), stateOf(t, model, bread).Assignee)
+
+	// states of the remaining member are selected by assignee filter
+	assigned := f.list(t).AssignedTo(editor)
+	require.Equal(t, []id.ID[product.Product]{bread}, productIDs(assigned))
+	require.Empty(t, f.list(t).AssignedTo(executing).States)
+	require.Len(t, f.list(t).States, 3)
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/deepcopy"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+var operationRoles = map[list.OperationType]list.MemberType{
+	list.OperationTypeAddProducts:    list.MemberTypeEditor,
+	list.OperationTypeUpdateState:    list.MemberTypeAdmin,
+	list.OperationTypeDeleteProducts: list.MemberTypeEditor,
+	list.OperationTypeReorderStates:  list.MemberTypeEditor,
+}
+
+// ApplyBatch applies all operations to the list in one transaction.
+// If any operation fails, none of them is applied.
+func (s *Service) ApplyBatch(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	operations []list.Operation,
+) (
+	list.BatchResult,
+	error,
+) {
+	var member list.Member
+	var changes []list.Change
+	var spent map[id.ID[product.Product]]list.Price
+
+	if len(operations) == 0 {
+		return list.BatchResult{}, fmt.Errorf("%w: batch is empty", myerr.ErrInvalidArgument)
+	}
+
+	s.log.Info().Stringer("list_id", listID).Stringer("user_id", userID).Int("operations", len(operations)).
+		Msg("applying batch")
+
+	var addedIDs []id.ID[product.Product]
+	for _, operation := range operations {
+		if operation.Type == list.OperationTypeAddProducts {
+			addedIDs = slices.AppendSeq(addedIDs, maps.Keys(operation.Products))
+		}
+	}
+
+	now := s.clock.Now()
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeBatch, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
+		var err error
+
+		place, err := s.newPlacement(ctx, oldList, addedIDs)
+		if err != nil {
+			return oldList, err
+		}
+
+		changes = make([]list.Change, 0, len(operations))
+		spent = spentPrices(oldList)
+		newList := deepcopy.MustCopy(oldList)
+
+		for idx, operation := range operations {
+			role, found := operationRoles[operation.Type]
+			if !found {
+				return oldList, fmt.Errorf("%w: operation %d has unknown type %d",
+					myerr.ErrInvalidArgument, idx, operation.Type)
+			}
+
+			if member, err = oldList.CheckRole(userID, role); err != nil {
+				return oldList, fmt.Errorf("checking role for operation %d (%s) failed: %w", idx, operation.Type, err)
+			}
+
+			var change list.Change
+			newList, change, err = applyOperation(newList, operation, place, now)
+			if err != nil {
+				return oldList, fmt.Errorf("operation %d (%s) failed: %w", idx, operation.Type, err)
+			}
+
+			changes = append(changes, change)
+		}
+
+		if err = s.validate(newList); err != nil {
+			return oldList, err
+		}
+
+		return newList, nil
+	})
+	if err != nil {
+		return list.BatchResult{}, fmt.Errorf("can't apply batch to list %s: %w", listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeBatch,
+		Data: list.BatchChange{Changes: changes},
+	})
+
+	s.checkBudgets(ctx, spent, model)
+
+	return list.BatchResult{List: model, Results: changes}, nil
+}
+
+func applyOperation(
+	model list.ProductList,
+	operation list.Operation,
+	place mo.Option[placement],
+	now time.Time,
+) (
+	list.ProductList,
+	list.Change,
+	error,
+) {
+	var err error
+
+	switch operation.Type {
+	case list.OperationTypeAddProducts:
+		return appendStates(model, newProductStates(operation.Products, now), place)
+	case list.OperationTypeUpdateState:
+		var state list.ProductState
+		if model, state, err = updateState(model, operation.ProductID, operation.State, now); err != nil {
+			return model, list.Change{}, err
+		}
+
+		return model, list.Change{
+			Type: list.EventTypeStateUpdated,
+			Data: list.StateUpdatedChange{ProductID: operation.ProductID, State: state},
+		}, nil
+	case list.OperationTypeDeleteProducts:
+		if model, err = deleteStates(model, operation.IDs); err != nil {
+			return model, list.Change{}, err
+		}
+
+		return model, list.Change{
+			Type: list.EventTypeProductsRemoved,
+			Data: list.ProductsRemovedChange{IDs: operation.IDs},
+		}, nil
+	case list.OperationTypeReorderStates:
+		if model, err = reorderStates(model, operation.IDs); err != nil {
+			return model, list.Change{}, err
+		}
+
+		return model, list.Change{
+			Type: list.EventTypeStatesReordered,
+			Data: list.StatesReorderedChange{IDs: operation.IDs},
+		}, nil
+	default:
+		return model, list.Change{}, fmt.Errorf("%w: unknown operation type %d", myerr.ErrInvalidArgument, operation.Type)
+	}
+}
+
+// newProductStates returns states of new products, purchased ones are bought at now
+func newProductStates(states map[id.ID[product.Product]]list.ProductStateOptions, now time.Time) []list.ProductState {
+	newStates := make([]list.ProductState, 0, len(states))
+	for productID, stateOpts := range states {
+		newStates = append(newStates, newProductState(productID, stateOpts, now))
+	}
+
+	return newStates
+}
+
+// newProductState returns state of new product, it's bought at now if it's purchased
+func newProductState(
+	productID id.ID[product.Product],
+	stateOpts list.ProductStateOptions,
+	now time.Time,
+) list.ProductState {
+	return recordPurchase(list.ProductState{
+		ProductStateOptions: stateOpts,
+		Product: product.Product{
+			Options:   product.NewZeroOptions(),
+			ID:        productID,
+			CreatedAt: date.CreateDate[product.Product]{Time: time.Time{}},
+			UpdatedAt: date.UpdateDate[product.Product]{Time: time.Time{}},
+		},
+		Assignee:    mo.None[id.ID[user.User]](),
+		CreatedAt:   date.NewCreateDate[list.ProductState](),
+		UpdatedAt:   date.NewUpdateDate[list.ProductState](),
+		PurchasedAt: mo.None[time.Time](),
+	}, now)
+}
+
+// appendStates adds new states to the list, quantity of a state which is already in the list is increased instead.
+// New states are placed by categories of default shop map of the list if place is present.
+// Change is productsAdded, or batch of productsAdded, stateUpdated and statesReordered changes
+// when some states are merged or aren't added to the end.
+func appendStates(
+	model list.ProductList,
+	newStates []list.ProductState,
+	place mo.Option[placement],
+) (
+	list.ProductList,
+	list.Change,
+	error,
+) {
+	added := make([]list.ProductState, 0, len(newStates))
+	merged := []list.Change{}
+	reordered := false
+
+	for _, newState := range newStates {
+		idx := slices.IndexFunc(model.States, func(s list.ProductState) bool { return s.Product.ID == newState.Product.ID })
+		if idx == -1 {
+			var inserted bool
+
+			model, newState, inserted = insertState(model, newState, place)
+			added = append(added, newState)
+			reordered = reordered || inserted
+
+			continue
+		}
+
+		quantity, err := sumQuantities(model.States[idx].Quantity, newState.Quantity)
+		if err != nil {
+			return model, list.Change{}, fmt.Errorf("can't merge product %s: %w", newState.Product.ID, err)
+		}
+
+		model.States[idx].Quantity = quantity
+		merged = append(merged, list.Change{
+			Type: list.EventTypeStateUpdated,
+			Data: list.StateUpdatedChange{ProductID: newState.Product.ID, State: model.States[idx]},
+		})
+	}
+
+	addedChange := list.Change{Type: list.EventTypeProductsAdded, Data: list.ProductsAddedChange{Products: added}}
+	if len(merged) == 0 && !reordered {
+		return model, addedChange, nil
+	}
+
+	changes := merged
+	if len(added) != 0 {
+		changes = append([]list.Change{addedChange}, changes...)
+	}
+
+	if reordered {
+		ids := make([]id.ID[product.Product], 0, len(model.States))
+		for _, state := range model.States {
+			ids = append(ids, state.Product.ID)
+		}
+
+		changes = append(changes, list.Change{
+			Type: list.EventTypeStatesReordered,
+			Data: list.StatesReorderedChange{IDs: ids},
+		})
+	}
+
+	return model, list.Change{Type: list.EventTypeBatch, Data: list.BatchChange{Changes: changes}}, nil
+}
+
+// sumQuantities sums optional quantities, absent quantity is not specified one, so it doesn't change the sum
+func sumQuantities(a, b mo.Option[product.Quantity]) (mo.Option[product.Quantity], error) {
+	aQuantity, aFound := a.Get()
+	bQuantity, bFound := b.Get()
+
+	if !aFound || !bFound {
+		return lo.Ternary(aFound, a, b), nil
+	}
+
+	sum, err := aQuantity.Add(bQuantity)
+	if err != nil {
+		return a, err
+	}
+
+	return mo.Some(sum), nil
+}
+
+func updateState(
+	model list.ProductList,
+	productID id.ID[product.Product],
+	stateOpts list.ProductStateOptions,
+	now time.Time,
+) (
+	list.ProductList,
+	list.ProductState,
+	error,
+) {
+	idx := slices.IndexFunc(model.States, func(s list.ProductState) bool { return s.Product.ID == productID })
+	if idx == -1 {
+		return model, list.ProductState{}, fmt.Errorf("%w: product state with product id: %s", myerr.ErrNotFound, productID)
+	}
+
+	model.States[idx].ProductStateOptions = stateOpts
+	model.States[idx] = recordPurchase(model.States[idx], now)
+
+	return model, model.States[idx], nil
+}
+
+// recordPurchase sets time of purchase when state becomes taken or replaced and clears it when state isn't purchased
+func recordPurchase(state list.ProductState, now time.Time) list.ProductState {
+	switch {
+	case !state.Purchased():
+		state.PurchasedAt = mo.None[time.Time]()
+	case state.PurchasedAt.IsAbsent():
+		state.PurchasedAt = mo.Some(now)
+	}
+
+	return state
+}
+
+func deleteStates(model list.ProductList, toDelete []id.ID[product.Product]) (list.ProductList, error) {
+	currentStates := lo.SliceToMap(model.States, func(item list.ProductState) (id.ID[product.Product], struct{}) {
+		return item.Product.ID, struct{}{}
+	})
+
+	for _, productID := range toDelete {
+		if _, found := currentStates[productID]; !found {
+			return model, fmt.Errorf("%w: state with product id %s", myerr.ErrNotFound, productID)
+		}
+
+		delete(currentStates, productID)
+	}
+
+	model.States = slices.DeleteFunc(model.States, func(item list.ProductState) bool {
+		return !lo.HasKey(currentStates, item.Product.ID)
+	})
+
+	return model, nil
+}
+
+func reorderStates(model list.ProductList, ids []id.ID[product.Product]) (list.ProductList, error) {
+	if len(ids) != len(model.States) {
+		return model, fmt.Errorf("%w: new order must contain all %d states", myerr.ErrInvalidArgument, len(model.States))
+	}
+
+	currentStates := lo.SliceToMap(model.States, func(item list.ProductState) (id.ID[product.Product], list.ProductState) {
+		return item.Product.ID, item
+	})
+
+	newStates := make([]list.ProductState, 0, len(ids))
+	for _, productID := range ids {
+		state, found := currentStates[productID]
+		if !found {
+			return model, fmt.Errorf("%w: state with product id %s", myerr.ErrNotFound, productID)
+		}
+
+		newStates = append(newStates, state)
+		delete(currentStates, productID)
+	}
+
+	model.States = newStates
+
+	return model, nil
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestApplyBatch(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, bread, eggs := f.products[0].ID, f.products[1].ID, f.products[2].ID
+
+	result, err := f.service.ApplyBatch(ctx, f.listID, f.ownerID(), []list.Operation{
+		{Type: list.OperationTypeUpdateState, ProductID: milk, State: list.ProductStateOptions{
+			Status:   list.StateStatusWaiting,
+			Quantity: mo.Some(product.Quantity{Value: 2, Unit: product.UnitL}),
+		}},
+		{Type: list.OperationTypeDeleteProducts, IDs: []id.ID[product.Product]{bread}},
+		{Type: list.OperationTypeReorderStates, IDs: []id.ID[product.Product]{eggs, milk}},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 3)
+	require.Equal(t, list.EventTypeStateUpdated, result.Results[0].Type)
+	require.Equal(t, list.EventTypeProductsRemoved, result.Results[1].Type)
+	require.Equal(t, list.EventTypeStatesReordered, result.Results[2].Type)
+
+	model := f.list(t)
+	require.Equal(t, []id.ID[product.Product]{eggs, milk}, productIDs(model))
+	require.Equal(t, mo.Some(product.Quantity{Value: 2, Unit: product.UnitL}), model.States[1].Quantity)
+
+	_, err = f.service.ApplyBatch(ctx, f.listID, f.ownerID(), nil)
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestApplyBatchChecksRoles(t *testing.T) {
+	ctx := context.Background()
+
+	update := func(f fixture) list.Operation {
+		return list.Operation{
+			Type:      list.OperationTypeUpdateState,
+			ProductID: f.products[0].ID,
+			State:     list.ProductStateOptions{Status: list.StateStatusTaken},
+		}
+	}
+	remove := func(f fixture) list.Operation {
+		return list.Operation{Type: list.OperationTypeDeleteProducts, IDs: []id.ID[product.Product]{f.products[0].ID}}
+	}
+
+	for _, c := range []struct {
+		role      list.MemberType
+		operation func(fixture) list.Operation
+		allowed   bool
+	}{
+		{role: list.MemberTypeAdmin, operation: update, allowed: true},
+		{role: list.MemberTypeEditor, operation: update, allowed: false},
+		{role: list.MemberTypeEditor, operation: remove, allowed: true},
+		{role: list.MemberTypeExecuting, operation: remove, allowed: false},
+		{role: list.MemberTypeViewer, operation: remove, allowed: false},
+	} {
+		f := newFixture(t)
+		operation := c.operation(f)
+
+		t.Run(c.role.String()+" "+operation.Type.String(), func(t *testing.T) {
+			_, err := f.service.ApplyBatch(ctx, f.listID, f.members[c.role], []list.Operation{operation})
+			if c.allowed {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, myerr.ErrForbidden)
+			}
+		})
+	}
+
+	// the role is checked for every operation, not only for the first one
+	f := newFixture(t)
+	operations := []list.Operation{remove(f), update(f)}
+	_, err := f.service.ApplyBatch(ctx, f.listID, f.members[list.MemberTypeEditor], operations)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+	require.Len(t, f.list(t).States, 3)
+}
+
+func TestApplyBatchRollsBack(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, bread, eggs := f.products[0].ID, f.products[1].ID, f.products[2].ID
+	before := f.list(t)
+
+	_, err := f.service.ApplyBatch(ctx, f.listID, f.ownerID(), []list.Operation{
+		{Type: list.OperationTypeUpdateState, ProductID: milk, State: list.ProductStateOptions{
+			Status: list.StateStatusTaken,
+		}},
+		{Type: list.OperationTypeReorderStates, IDs: []id.ID[product.Product]{eggs, bread, milk}},
+		{Type: list.OperationTypeDeleteProducts, IDs: []id.ID[product.Product]{id.NewID[product.Product]()}},
+	})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+
+	after := f.list(t)
+	require.Equal(t, productIDs(before), productIDs(after))
+	require.Equal(t, list.StateStatusWaiting, after.States[0].Status)
+
+	// invalid result of all operations is rejected as a whole too
+	_, err = f.service.ApplyBatch(ctx, f.listID, f.ownerID(), []list.Operation{
+		{Type: list.OperationTypeDeleteProducts, IDs: []id.ID[product.Product]{bread}},
+		{Type: list.OperationTypeUpdateState, ProductID: milk, State: list.ProductStateOptions{
+			Status:   list.StateStatusWaiting,
+			Quantity: mo.Some(product.Quantity{Value: -1, Unit: product.UnitPieces}),
+		}},
+	})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+	require.Len(t, f.list(t).States, 3)
+}
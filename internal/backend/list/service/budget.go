@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+// budgets finds limits of budgets which are exceeded by purchases in lists
+type budgets interface {
+	CheckPurchases(context.Context, list.ProductList, []list.Purchase) ([]list.BudgetExceededChange, error)
+}
+
+// checkBudgets sends alerts about budgets exceeded by products purchased in the list under execution.
+// Spent are prices paid for products of the list before the change. Alerts are best effort, so errors are only logged.
+func (s *Service) checkBudgets(
+	ctx context.Context,
+	spent map[id.ID[product.Product]]list.Price,
+	newList list.ProductList,
+) {
+	if newList.Status != list.ExecStatusProcessing {
+		return
+	}
+
+	purchases := newPurchases(spent, newList)
+	if len(purchases) == 0 {
+		return
+	}
+
+	changes, err := s.budgets.CheckPurchases(ctx, newList, purchases)
+	if err != nil {
+		s.log.Error().Err(err).Stringer("list_id", newList.ID).Msg("can't check budgets")
+		return
+	}
+
+	for _, change := range changes {
+		s.sendAlertEvent(newList.ID, change.UserIDs, list.Change{Type: list.EventTypeBudgetExceeded, Data: change})
+	}
+}
+
+// spentPrices returns prices paid for products of the list by their ids.
+// States are shared with copies of the list, so it must be called before they're changed.
+func spentPrices(model list.ProductList) map[id.ID[product.Product]]list.Price {
+	spent := make(map[id.ID[product.Product]]list.Price, len(model.States))
+
+	for _, state := range model.States {
+		if price, found := state.Spent().Get(); found {
+			spent[state.Product.ID] = price
+		}
+	}
+
+	return spent
+}
+
+// newPurchases returns money spent on products of the list which isn't counted in spent yet
+func newPurchases(spent map[id.ID[product.Product]]list.Price, model list.ProductList) []list.Purchase {
+	purchases := make([]list.Purchase, 0)
+
+	for _, state := range model.States {
+		price, found := state.Spent().Get()
+		if !found {
+			continue
+		}
+
+		if old, found := spent[state.Product.ID]; found && old.Currency == price.Currency {
+			price.Amount = price.Amount.Sub(old.Amount)
+		}
+
+		if !price.Amount.IsPositive() {
+			continue
+		}
+
+		category := state.Product.Category
+		if state.Status == list.StateStatusReplaced {
+			category = state.Replacement.OrEmpty().Product.Category
+		}
+
+		purchases = append(purchases, list.Purchase{
+			ListID:      model.ID,
+			ShopMapID:   model.ShopMapID,
+			Category:    category,
+			PurchasedAt: state.PurchasedAt.OrEmpty(),
+			Price:       price,
+		})
+	}
+
+	return purchases
+}
+
+// sendAlertEvent sends change to listeners of the list who are among userIDs, the author of the change included
+func (s *Service) sendAlertEvent(listID id.ID[list.ProductList], userIDs []id.ID[user.User], change list.Change) {
+	s.channelsLock.RLock()
+	defer s.channelsLock.RUnlock()
+
+	event := list.Event{
+		Change: change,
+		ListID: listID,
+		Member: nil, // alerts aren't made by members
+	}
+
+	s.log.Info().Any("event", event).Msg("sending alert event")
+
+	for id, provider := range s.channels {
+		if id.ListID == listID && slices.Contains(userIDs, id.UserID) {
+			provider.ch <- event
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+func TestBudgetAlerts(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	viewer := f.members[list.MemberTypeViewer]
+
+	_, err := f.service.StartSession(ctx, f.listID, f.ownerID(), list.StartOptions{})
+	require.NoError(t, err)
+
+	f.budgets.exceeded = []list.BudgetExceededChange{{
+		BudgetID: uuid.New(),
+		UserIDs:  []id.ID[user.User]{f.ownerID()},
+		Title:    "groceries",
+		Category: mo.None[product.Category](),
+	}}
+
+	// events of every listener are collected until the listener is stopped
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	received := map[id.ID[user.User]][]list.EventType{}
+
+	for _, userID := range []id.ID[user.User]{f.ownerID(), viewer} {
+		events, err := f.service.ListenEvents(ctx, userID, f.listID)
+		require.NoError(t, err)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for event := range events {
+				lock.Lock()
+				received[userID] = append(received[userID], event.Change.Type)
+				lock.Unlock()
+			}
+		}()
+	}
+
+	_, err = f.service.UpdateProductState(ctx, f.listID, f.ownerID(), f.products[0].ID, list.ProductStateOptions{
+		Status: list.StateStatusTaken,
+		Price:  mo.Some(list.Price{Amount: decimal.RequireFromString("12"), Currency: "EUR"}),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.service.StopListenEvents(f.ownerID(), f.listID))
+	require.NoError(t, f.service.StopListenEvents(viewer, f.listID))
+	wg.Wait()
+
+	require.Contains(t, received[f.ownerID()], list.EventTypeBudgetExceeded)
+	require.Contains(t, received[viewer], list.EventTypeStateUpdated)
+	require.NotContains(t, received[viewer], list.EventTypeBudgetExceeded, "the viewer isn't a member of the budget")
+}
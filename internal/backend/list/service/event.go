@@ -119,3 +119,20 @@ func (s *Service) sendUpdateEvent(
 		provider.ch <- event
 	}
 }
+
+// Refresh sends full updates of lists changed outside the service, e.g. when products are merged
+func (s *Service) Refresh(ctx context.Context, listIDs []id.ID[list.ProductList]) error {
+	for _, listID := range listIDs {
+		model, err := s.repo.GetByListID(ctx, listID)
+		if err != nil {
+			return fmt.Errorf("can't get list %s from storage: %w", listID, err)
+		}
+
+		s.sendUpdateEvent(listID, list.NewZeroMember(), list.Change{
+			Data: list.FullUpdateChange{ProductList: model},
+			Type: list.EventTypeFull,
+		})
+	}
+
+	return nil
+}
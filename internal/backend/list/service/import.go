@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// maxImportLines is a maximal number of products in imported text
+const maxImportLines = 200
+
+// ImportText parses pasted plain-text list and matches its lines with products.
+// If opts.Commit is true, items confirmed by user are appended to the list, products are created for new items.
+func (s *Service) ImportText(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	opts list.ImportOptions,
+) (
+	list.ImportResult,
+	error,
+) {
+	if err := validator.New().Struct(opts); err != nil {
+		return list.ImportResult{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.ImportResult{}, err
+	}
+
+	if _, err = model.CheckRole(userID, list.MemberTypeEditor); err != nil {
+		return list.ImportResult{}, fmt.Errorf("checking role failed: %w", err)
+	}
+
+	if opts.Commit {
+		return s.commitImport(ctx, listID, userID, opts.Items)
+	}
+
+	lines := product.ParseText(opts.Text)
+	if len(lines) == 0 || len(lines) > maxImportLines {
+		return list.ImportResult{}, fmt.Errorf("%w: text must contain from 1 to %d products, got %d",
+			myerr.ErrInvalidArgument, maxImportLines, len(lines))
+	}
+
+	names := make([]product.Name, 0, len(lines))
+	for _, line := range lines {
+		names = append(names, line.Name)
+	}
+
+	matches, err := s.products.Match(ctx, names)
+	if err != nil {
+		return list.ImportResult{}, fmt.Errorf("can't match imported products: %w", err)
+	}
+
+	items := make([]list.ImportItem, 0, len(lines))
+	for i, line := range lines {
+		items = append(items, list.ImportItem{ParsedLine: line, Match: matches[i]})
+	}
+
+	return list.ImportResult{Items: items, List: mo.None[list.ProductList]()}, nil
+}
+
+// commitImport appends confirmed items to the list in their order, products are created for items without product.
+// Products are created in the same transaction with the list update, so they don't remain if the list isn't updated.
+func (s *Service) commitImport(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	confirmed []list.ImportConfirmedItem,
+) (
+	list.ImportResult,
+	error,
+) {
+	var model list.ProductList
+	var member list.Member
+	var change list.Change
+
+	if len(confirmed) == 0 || len(confirmed) > maxImportLines {
+		return list.ImportResult{}, fmt.Errorf("%w: import must contain from 1 to %d products, got %d",
+			myerr.ErrInvalidArgument, maxImportLines, len(confirmed))
+	}
+
+	items, err := s.confirmedItems(ctx, confirmed)
+	if err != nil {
+		return list.ImportResult{}, err
+	}
+
+	err = s.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.createImported(ctx, items); err != nil {
+			return err
+		}
+
+		states, err := importedStates(items, s.clock.Now())
+		if err != nil {
+			return err
+		}
+
+		model, member, change, err = s.appendProducts(ctx, listID, userID, states)
+
+		return err
+	})
+	if err != nil {
+		return list.ImportResult{}, fmt.Errorf("can't import products to list %s: %w", listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, change)
+
+	return list.ImportResult{Items: items, List: mo.Some(model)}, nil
+}
+
+// confirmedItems returns import items with confirmed products, the products must exist
+func (s *Service) confirmedItems(ctx context.Context, confirmed []list.ImportConfirmedItem) ([]list.ImportItem, error) {
+	productIDs := make([]id.ID[product.Product], 0, len(confirmed))
+	for _, item := range confirmed {
+		if productID, found := item.ProductID.Get(); found {
+			productIDs = append(productIDs, productID)
+		} else if product.NormalizeName(item.Name) == "" {
+			return nil, fmt.Errorf("%w: item %q has neither product nor name", myerr.ErrInvalidArgument, item.Line)
+		}
+	}
+
+	products, err := s.products.IDList(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get confirmed products: %w", err)
+	}
+
+	byID := make(map[id.ID[product.Product]]product.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	items := make([]list.ImportItem, 0, len(confirmed))
+	for _, item := range confirmed {
+		match := product.Match{Product: mo.None[product.Product](), Confidence: 0}
+
+		if productID, found := item.ProductID.Get(); found {
+			p, found := byID[productID]
+			if !found {
+				return nil, fmt.Errorf("%w: product %s", myerr.ErrNotFound, productID)
+			}
+
+			match = product.Match{Product: mo.Some(p), Confidence: 1}
+		}
+
+		items = append(items, list.ImportItem{ParsedLine: item.ParsedLine, Match: match})
+	}
+
+	return items, nil
+}
+
+// createImported creates products for items without product, items with equal names share a product.
+// Products are created in the transaction of ctx.
+func (s *Service) createImported(ctx context.Context, items []list.ImportItem) error {
+	created := map[string]product.Product{}
+
+	for i, item := range items {
+		if item.Product.IsPresent() {
+			continue
+		}
+
+		name := product.NormalizeName(item.Name)
+
+		model, found := created[name]
+		if !found {
+			var err error
+
+			model, err = s.products.Create(ctx, product.Options{
+				Name:     item.Name,
+				Category: mo.None[product.Category](),
+				Forms:    []product.Form{},
+				Aliases:  []product.Name{},
+				Barcodes: []product.Barcode{},
+			})
+			if err != nil {
+				return fmt.Errorf("can't create imported product %q: %w", item.Name, err)
+			}
+
+			created[name] = model
+		}
+
+		items[i].Match = product.Match{Product: mo.Some(model), Confidence: 0}
+	}
+
+	return nil
+}
+
+// importedStates returns states of imported products in order of items, quantities of repeated products are summed
+func importedStates(items []list.ImportItem, now time.Time) ([]list.ProductState, error) {
+	states := make([]list.ProductState, 0, len(items))
+
+	for _, item := range items {
+		productID := item.Product.MustGet().ID
+
+		idx := slices.IndexFunc(states, func(s list.ProductState) bool { return s.Product.ID == productID })
+		if idx == -1 {
+			states = append(states, newProductState(productID, list.ProductStateOptions{
+				Quantity:    item.Quantity,
+				FormIndex:   mo.None[int32](),
+				Status:      list.StateStatusWaiting,
+				Replacement: mo.None[list.ProductStateReplacement](),
+				Note:        "",
+				Price:       mo.None[list.Price](),
+			}, now))
+
+			continue
+		}
+
+		quantity, err := sumQuantities(states[idx].Quantity, item.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("can't import line %q: %w", item.Line, err)
+		}
+
+		states[idx].Quantity = quantity
+	}
+
+	return states, nil
+}
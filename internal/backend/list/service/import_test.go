@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestImportTextPreview(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	result, err := f.service.ImportText(ctx, f.listID, f.ownerID(), list.ImportOptions{Text: "- milk 1l\n- 100% juice"})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	require.Equal(t, f.products[0].ID, result.Items[0].Product.MustGet().ID)
+	require.Equal(t, 1.0, result.Items[0].Confidence)
+	require.True(t, result.Items[1].Product.IsAbsent())
+	require.True(t, result.List.IsAbsent())
+	require.Len(t, f.list(t).States, 3)
+}
+
+func TestImportTextCommit(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk := f.products[0].ID
+	existing := productIDs(f.list(t))
+	pieces := func(value float64) mo.Option[product.Quantity] {
+		return mo.Some(product.Quantity{Value: value, Unit: product.UnitPieces})
+	}
+
+	result, err := f.service.ImportText(ctx, f.listID, f.members[list.MemberTypeEditor], list.ImportOptions{
+		Commit: true,
+		Items: []list.ImportConfirmedItem{
+			confirmed("cheese", pieces(1), mo.None[id.ID[product.Product]]()),
+			confirmed("apples", pieces(6), mo.None[id.ID[product.Product]]()),
+			// the user picked existing product instead of the matched one
+			confirmed("moloko", pieces(2), mo.Some(milk)),
+			confirmed("Cheese", pieces(2), mo.None[id.ID[product.Product]]()),
+		},
+	})
+	require.NoError(t, err)
+
+	model := result.List.MustGet()
+	cheese, apples := result.Items[0].Product.MustGet().ID, result.Items[1].Product.MustGet().ID
+	require.Equal(t, cheese, result.Items[3].Product.MustGet().ID)
+	require.Equal(t, append(existing, cheese, apples), productIDs(model))
+	require.Equal(t, pieces(3), stateOf(t, model, cheese).Quantity)
+	require.Equal(t, pieces(2), stateOf(t, model, milk).Quantity)
+
+	_, err = f.service.ImportText(ctx, f.listID, f.ownerID(), list.ImportOptions{
+		Commit: true,
+		Items: []list.ImportConfirmedItem{
+			confirmed("", mo.None[product.Quantity](), mo.Some(id.NewID[product.Product]())),
+		},
+	})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+
+	_, err = f.service.ImportText(ctx, f.listID, f.ownerID(), list.ImportOptions{Commit: true})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestImportTextCommitIsAtomic(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	noProduct := mo.None[id.ID[product.Product]]()
+
+	// quantities of the repeated product can't be summed, so nothing is appended and created
+	_, err := f.service.ImportText(ctx, f.listID, f.ownerID(), list.ImportOptions{
+		Commit: true,
+		Items: []list.ImportConfirmedItem{
+			confirmed("cheese", mo.Some(product.Quantity{Value: 1, Unit: product.UnitKg}), noProduct),
+			confirmed("cheese", mo.Some(product.NewPieces(2)), noProduct),
+		},
+	})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	var count int64
+	require.NoError(t, f.db.Model(new(productRepo.Product)).Where("normalized_name = ?", "cheese").Count(&count).Error)
+	require.Zero(t, count)
+	require.Len(t, f.list(t).States, 3)
+}
+
+func confirmed(
+	name product.Name,
+	quantity mo.Option[product.Quantity],
+	productID mo.Option[id.ID[product.Product]],
+) list.ImportConfirmedItem {
+	return list.ImportConfirmedItem{
+		ParsedLine: product.ParsedLine{Line: string(name), Name: name, Quantity: quantity},
+		ProductID:  productID,
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// sniffLen is a number of bytes used to detect type of content
+const sniffLen = 512
+
+// countingReader counts bytes read from underlying reader
+type countingReader struct {
+	r    io.Reader
+	size int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.size += int64(n)
+
+	return n, err //nolint:wrapcheck // reader errors are passed as is
+}
+
+// AddComment adds comment to the thread of list item
+func (s *Service) AddComment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	text string,
+) (
+	list.Comment,
+	error,
+) {
+	var member list.Member
+
+	comment := list.Comment{
+		ID:        id.NewID[list.Comment](),
+		Author:    userID,
+		Text:      text,
+		CreatedAt: s.clock.Now(),
+	}
+
+	if err := validator.New().Struct(comment); err != nil {
+		return list.Comment{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeCommentAdded, At: s.clock.Now()}
+	err := s.repo.AddComment(ctx, listID, productID, meta, func(model list.ProductList) error {
+		var err error
+		if member, err = model.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+			return fmt.Errorf("checking role failed: %w", err)
+		}
+
+		return nil
+	}, comment)
+	if err != nil {
+		return list.Comment{}, fmt.Errorf("can't add comment to product %s in list %s: %w", productID, listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeCommentAdded,
+		Data: list.CommentAddedChange{ProductID: productID, Comment: comment},
+	})
+
+	return comment, nil
+}
+
+// DeleteComment deletes comment of list item, only its author or admins can do it
+func (s *Service) DeleteComment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	commentID id.ID[list.Comment],
+) error {
+	var member list.Member
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeCommentDeleted, At: s.clock.Now()}
+	err := s.repo.DeleteComment(ctx, listID, productID, meta, commentID, func(
+		model list.ProductList,
+		comment list.Comment,
+	) error {
+		var err error
+		if member, err = model.CheckRole(userID, authorRole(comment.Author, userID)); err != nil {
+			return fmt.Errorf("checking role failed: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete comment %s of product %s in list %s: %w", commentID, productID, listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeCommentDeleted,
+		Data: list.CommentDeletedChange{ProductID: productID, CommentID: commentID},
+	})
+
+	return nil
+}
+
+// AddAttachment stores image in blob store and attaches it to list item
+func (s *Service) AddAttachment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	content io.Reader,
+) (
+	list.Attachment,
+	error,
+) {
+	var member list.Member
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return list.Attachment{}, fmt.Errorf("can't read content of attachment: %w", err)
+	}
+
+	// declared type isn't trusted, the type is detected by content, so it's safe to serve the image back
+	contentType := http.DetectContentType(head[:n])
+	if !slices.Contains(list.AttachmentTypes, contentType) {
+		return list.Attachment{}, fmt.Errorf("%w: only %s images can be attached, got %s",
+			myerr.ErrInvalidArgument, strings.Join(list.AttachmentTypes, ", "), contentType)
+	}
+
+	content = io.MultiReader(bytes.NewReader(head[:n]), content)
+
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.Attachment{}, err
+	}
+
+	if _, err = model.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+		return list.Attachment{}, fmt.Errorf("checking role failed: %w", err)
+	}
+
+	attachment := list.Attachment{
+		ID:          id.NewID[list.Attachment](),
+		Author:      userID,
+		ContentType: contentType,
+		Size:        0,
+		CreatedAt:   s.clock.Now(),
+	}
+	attachment.BlobKey = list.AttachmentKey(listID, attachment.ID)
+
+	counter := &countingReader{r: content, size: 0}
+	if err = s.blobs.Put(ctx, attachment.BlobKey, counter); err != nil {
+		return list.Attachment{}, fmt.Errorf("can't store content of attachment: %w", err)
+	}
+
+	attachment.Size = counter.size
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeAttachmentAdded, At: s.clock.Now()}
+	err = s.repo.AddAttachment(ctx, listID, productID, meta, func(model list.ProductList) error {
+		var err error
+		if member, err = model.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+			return fmt.Errorf("checking role failed: %w", err)
+		}
+
+		return nil
+	}, attachment)
+	if err != nil {
+		s.deleteBlob(ctx, attachment)
+		return list.Attachment{}, fmt.Errorf("can't attach image to product %s in list %s: %w", productID, listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeAttachmentAdded,
+		Data: list.AttachmentAddedChange{ProductID: productID, Attachment: attachment},
+	})
+
+	return attachment, nil
+}
+
+// GetAttachment returns attachment of list item and its content, content must be closed by caller
+func (s *Service) GetAttachment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	attachmentID id.ID[list.Attachment],
+) (
+	list.Attachment,
+	io.ReadCloser,
+	error,
+) {
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.Attachment{}, nil, err
+	}
+
+	stateIdx := slices.IndexFunc(model.States, func(s list.ProductState) bool { return s.Product.ID == productID })
+	if stateIdx == -1 {
+		return list.Attachment{}, nil, fmt.Errorf("%w: product state with product id: %s", myerr.ErrNotFound, productID)
+	}
+
+	attachments := model.States[stateIdx].Attachments
+	idx := slices.IndexFunc(attachments, func(a list.Attachment) bool { return a.ID == attachmentID })
+	if idx == -1 {
+		return list.Attachment{}, nil, fmt.Errorf("%w: attachment %s of product %s", myerr.ErrNotFound,
+			attachmentID, productID)
+	}
+
+	content, err := s.blobs.Get(ctx, attachments[idx].BlobKey)
+	if err != nil {
+		return list.Attachment{}, nil, fmt.Errorf("can't get content of attachment %s: %w", attachmentID, err)
+	}
+
+	return attachments[idx], content, nil
+}
+
+// DeleteAttachment deletes attachment of list item, only its author or admins can do it
+func (s *Service) DeleteAttachment(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	productID id.ID[product.Product],
+	attachmentID id.ID[list.Attachment],
+) error {
+	var member list.Member
+	var deleted list.Attachment
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeAttachmentDeleted, At: s.clock.Now()}
+	err := s.repo.DeleteAttachment(ctx, listID, productID, meta, attachmentID, func(
+		model list.ProductList,
+		attachment list.Attachment,
+	) error {
+		var err error
+
+		deleted = attachment
+		if member, err = model.CheckRole(userID, authorRole(attachment.Author, userID)); err != nil {
+			return fmt.Errorf("checking role failed: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete attachment %s of product %s in list %s: %w", attachmentID, productID, listID, err)
+	}
+
+	s.deleteBlob(ctx, deleted)
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeAttachmentDeleted,
+		Data: list.AttachmentDeletedChange{ProductID: productID, AttachmentID: attachmentID},
+	})
+
+	return nil
+}
+
+func (s *Service) deleteBlob(ctx context.Context, attachment list.Attachment) {
+	if err := s.blobs.Delete(ctx, attachment.BlobKey); err != nil {
+		s.log.Error().Err(err).Stringer("attachment_id", attachment.ID).Msg("can't delete content of attachment")
+	}
+}
+
+// authorRole is a role required to delete something created by author
+func authorRole(authorID, userID id.ID[user.User]) list.MemberType {
+	if authorID == userID {
+		return list.MemberTypeExecuting
+	}
+
+	return list.MemberTypeAdmin
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const pngContent = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestAddAttachmentDetectsType(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+	milk := f.products[0].ID
+
+	attachment, err := f.service.AddAttachment(ctx, f.listID, executing, milk, strings.NewReader(pngContent))
+	require.NoError(t, err)
+	require.Equal(t, "image/png", attachment.ContentType)
+	require.Equal(t, int64(len(pngContent)), attachment.Size)
+	require.Equal(t, f.clock.Now(), attachment.CreatedAt)
+
+	for name, content := range map[string]string{
+		"svg":   `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`,
+		"html":  "<html><script>alert(1)</script></html>",
+		"empty": "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := f.service.AddAttachment(ctx, f.listID, executing, milk, strings.NewReader(content))
+			require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+		})
+	}
+
+	_, err = f.service.AddAttachment(ctx, f.listID, f.members[list.MemberTypeViewer], milk,
+		strings.NewReader(pngContent))
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+}
+
+func TestUndoRestoresThread(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk := f.products[0].ID
+
+	comment, err := f.service.AddComment(ctx, f.listID, f.ownerID(), milk, "2.5% fat")
+	require.NoError(t, err)
+	require.Equal(t, f.clock.Now(), comment.CreatedAt)
+
+	attachment, err := f.service.AddAttachment(ctx, f.listID, f.ownerID(), milk, strings.NewReader(pngContent))
+	require.NoError(t, err)
+
+	// the last change of the owner is undone
+	f.clock.Add(time.Minute)
+	_, err = f.service.DeleteProducts(ctx, f.listID, f.ownerID(), []id.ID[product.Product]{milk})
+	require.NoError(t, err)
+
+	var removed repo.ProductListRemovedAttachment
+	require.NoError(t, f.db.First(&removed, "id = ?", attachment.ID.String()).Error)
+	require.True(t, f.clock.Now().Equal(removed.RemovedAt), "attachments are removed at time of the change")
+
+	model, err := f.service.Undo(ctx, f.listID, f.ownerID(), 1)
+	require.NoError(t, err)
+
+	state := stateOf(t, model, milk)
+	require.Equal(t, []string{comment.Text}, commentTexts(state))
+	require.Len(t, state.Attachments, 1)
+	require.Equal(t, pngContent, readAttachment(t, f, milk, attachment.ID))
+
+	// content of removed attachment is deleted with the trash, the item is restored without it after that
+	f.clock.Add(time.Minute)
+	_, err = f.service.DeleteProducts(ctx, f.listID, f.ownerID(), []id.ID[product.Product]{milk})
+	require.NoError(t, err)
+
+	_, err = f.service.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	model, err = f.service.Undo(ctx, f.listID, f.ownerID(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{comment.Text}, commentTexts(stateOf(t, model, milk)))
+	require.Empty(t, stateOf(t, model, milk).Attachments)
+
+	_, _, err = f.service.GetAttachment(ctx, f.listID, f.ownerID(), milk, attachment.ID)
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
+
+func TestSplitKeepsAttachments(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk := f.products[0].ID
+	sourceID := f.listID
+
+	attachment, err := f.service.AddAttachment(ctx, f.listID, f.ownerID(), milk, strings.NewReader(pngContent))
+	require.NoError(t, err)
+
+	model, err := f.service.Split(ctx, f.listID, f.ownerID(), list.SplitOptions{
+		ProductIDs: []id.ID[product.Product]{milk},
+		Title:      "dairy",
+	})
+	require.NoError(t, err)
+	require.Len(t, stateOf(t, model, milk).Attachments, 1)
+
+	// the content is read by the key it was stored with, so nothing is moved after the split is committed
+	f.listID = model.ID
+	require.Equal(t, pngContent, readAttachment(t, f, milk, attachment.ID))
+
+	_, err = f.service.DeleteProducts(ctx, f.listID, f.ownerID(), []id.ID[product.Product]{milk})
+	require.NoError(t, err)
+
+	_, err = f.service.Undo(ctx, f.listID, f.ownerID(), 1)
+	require.NoError(t, err)
+	require.Equal(t, pngContent, readAttachment(t, f, milk, attachment.ID))
+
+	require.NoError(t, f.service.DeleteAttachment(ctx, f.listID, f.ownerID(), milk, attachment.ID))
+
+	_, err = f.blobs.Get(ctx, list.AttachmentKey(sourceID, attachment.ID))
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
+
+func commentTexts(state list.ProductState) []string {
+	texts := make([]string, 0, len(state.Comments))
+	for _, comment := range state.Comments {
+		texts = append(texts, comment.Text)
+	}
+
+	return texts
+}
+
+func readAttachment(
+	t *testing.T, f fixture, productID id.ID[product.Product], attachmentID id.ID[list.Attachment],
+) string {
+	t.Helper()
+
+	_, content, err := f.service.GetAttachment(context.Background(), f.listID, f.ownerID(), productID, attachmentID)
+	require.NoError(t, err)
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+
+	return string(data)
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// Duplicate creates a planning copy of the list owned by the user, statuses, assignees and threads aren't copied.
+// Other members are copied with roles capped at the role of the user.
+func (s *Service) Duplicate(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+) (
+	list.ProductList,
+	error,
+) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeFull, At: s.clock.Now()}
+	listIDs := []id.ID[list.ProductList]{listID}
+
+	update, err := s.repo.GetAndUpdateLists(ctx, listIDs, meta, func(lists []list.ProductList) (list.ListsUpdate, error) {
+		source := lists[0]
+
+		member, err := source.CheckRole(userID, list.MemberTypeViewer)
+		if err != nil {
+			return list.ListsUpdate{}, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		model := newDerivedList(source, member, source.Title+" (copy)")
+		model.States = lo.Map(source.States, func(state list.ProductState, _ int) list.ProductState {
+			return copyState(state)
+		})
+
+		if err = s.validate(model); err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		return list.ListsUpdate{Updated: nil, Created: []list.ProductList{model}, Deleted: nil, DeletedAt: time.Time{}}, nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't duplicate list %s: %w", listID, err)
+	}
+
+	return update.Created[0], nil
+}
+
+// Merge adds products of source lists to the target list, quantities of the same products are summed.
+// Members of sources are added to the target with roles capped at the role of the user in the target.
+func (s *Service) Merge(
+	ctx context.Context,
+	userID id.ID[user.User],
+	options list.MergeOptions,
+) (
+	list.ProductList,
+	error,
+) {
+	if err := validator.New().Struct(options); err != nil {
+		return list.ProductList{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	if slices.Contains(options.SourceIDs, options.TargetID) {
+		return list.ProductList{}, fmt.Errorf("%w: list %s can't be merged into itself",
+			myerr.ErrInvalidArgument, options.TargetID)
+	}
+
+	var member list.Member
+	var changes []list.Change
+
+	sourceMembers := make(map[id.ID[list.ProductList]]list.Member, len(options.SourceIDs))
+	sourceRole := lo.Ternary(options.DeleteSources, list.MemberTypeOwner, list.MemberTypeViewer)
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeBatch, At: s.clock.Now()}
+	listIDs := append([]id.ID[list.ProductList]{options.TargetID}, options.SourceIDs...)
+
+	update, err := s.repo.GetAndUpdateLists(ctx, listIDs, meta, func(lists []list.ProductList) (list.ListsUpdate, error) {
+		var err error
+
+		target := lists[0]
+		if member, err = target.CheckRole(userID, list.MemberTypeEditor); err != nil {
+			return list.ListsUpdate{}, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		place, err := s.newPlacement(ctx, target, nil)
+		if err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		newStates := []list.ProductState{}
+		newMembers := []list.Member{}
+
+		for _, source := range lists[1:] {
+			if sourceMembers[source.ID], err = source.CheckRole(userID, sourceRole); err != nil {
+				return list.ListsUpdate{}, fmt.Errorf("checking role in list %s failed: %w", source.ID, err)
+			}
+
+			for _, state := range source.States {
+				newStates = append(newStates, copyState(state))
+			}
+
+			for _, sourceMember := range source.Members {
+				isMember := func(m list.Member) bool { return m.UserID == sourceMember.UserID }
+				if !slices.ContainsFunc(target.Members, isMember) && !slices.ContainsFunc(newMembers, isMember) {
+					newMembers = append(newMembers, newMember(sourceMember.UserID, cappedRole(sourceMember.Role, member.Role)))
+				}
+			}
+		}
+
+		target, change, err := appendStates(target, newStates, place)
+		if err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		target.Members = append(target.Members, newMembers...)
+
+		if err = s.validate(target); err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		changes = []list.Change{change}
+		if len(newMembers) != 0 {
+			changes = append(changes, list.Change{
+				Type: list.EventTypeMembersAdded,
+				Data: list.MembersAddedChange{NewMembers: newMembers},
+			})
+		}
+
+		deleted := lo.Ternary(options.DeleteSources, options.SourceIDs, nil)
+
+		return list.ListsUpdate{
+			Updated:   []list.ProductList{target},
+			Created:   nil,
+			Deleted:   deleted,
+			DeletedAt: s.clock.Now(),
+		}, nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't merge lists into %s: %w", options.TargetID, err)
+	}
+
+	s.sendUpdateEvent(options.TargetID, member, list.Change{
+		Type: list.EventTypeBatch,
+		Data: list.BatchChange{Changes: changes},
+	})
+
+	for _, sourceID := range update.Deleted {
+		s.sendUpdateEvent(sourceID, sourceMembers[sourceID], list.Change{
+			Type: list.EventTypeDeleted,
+			Data: list.ListDeletedChange{},
+		})
+	}
+
+	return update.Updated[0], nil
+}
+
+// Split moves products with their threads to a new planning list owned by the user.
+// Members are copied with roles capped at the role of the user.
+func (s *Service) Split(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	options list.SplitOptions,
+) (
+	list.ProductList,
+	error,
+) {
+	if err := validator.New().Struct(options); err != nil {
+		return list.ProductList{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	var member list.Member
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeProductsRemoved, At: s.clock.Now()}
+	listIDs := []id.ID[list.ProductList]{listID}
+
+	update, err := s.repo.GetAndUpdateLists(ctx, listIDs, meta, func(lists []list.ProductList) (list.ListsUpdate, error) {
+		var err error
+
+		source := lists[0]
+		if member, err = source.CheckRole(userID, list.MemberTypeEditor); err != nil {
+			return list.ListsUpdate{}, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		moved, kept := lo.FilterReject(source.States, func(state list.ProductState, _ int) bool {
+			return slices.Contains(options.ProductIDs, state.Product.ID)
+		})
+		if len(moved) != len(options.ProductIDs) {
+			return list.ListsUpdate{}, fmt.Errorf("%w: some of products aren't in list %s", myerr.ErrNotFound, listID)
+		}
+
+		model := newDerivedList(source, member, lo.CoalesceOrEmpty(options.Title, source.Title))
+		model.States = moved
+		source.States = kept
+
+		if err = s.validate(model); err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		if err = s.validate(source); err != nil {
+			return list.ListsUpdate{}, err
+		}
+
+		return list.ListsUpdate{
+			Updated:   []list.ProductList{source},
+			Created:   []list.ProductList{model},
+			Deleted:   nil,
+			DeletedAt: time.Time{},
+		}, nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't split list %s: %w", listID, err)
+	}
+
+	// contents of moved attachments stay under their blob keys
+	model := update.Created[0]
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeProductsRemoved,
+		Data: list.ProductsRemovedChange{IDs: options.ProductIDs},
+	})
+
+	return model, nil
+}
+
+// newDerivedList returns empty planning list owned by the member of source list,
+// other members of the source are added with roles capped at the role of the member
+func newDerivedList(source list.ProductList, owner list.Member, title string) list.ProductList {
+	members := []list.Member{newMember(owner.UserID, list.MemberTypeOwner)}
+	for _, member := range source.Members {
+		if member.UserID != owner.UserID {
+			members = append(members, newMember(member.UserID, cappedRole(member.Role, owner.Role)))
+		}
+	}
+
+	return list.ProductList{
+		ListOptions: list.ListOptions{
+			Status:    list.ExecStatusPlanning,
+			Title:     title,
+			ShopMapID: source.ShopMapID,
+		},
+		States:    []list.ProductState{},
+		Members:   members,
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.NewUpdateDate[list.ProductList](),
+		CreatedAt: date.NewCreateDate[list.ProductList](),
+	}
+}
+
+// cappedRole returns role which isn't higher than the role of the user who grants it, only admin at most
+func cappedRole(role, granterRole list.MemberType) list.MemberType {
+	return max(role, granterRole, list.MemberTypeAdmin)
+}
+
+// copyState returns waiting state with the same product, quantity and note
+func copyState(state list.ProductState) list.ProductState {
+	return list.ProductState{
+		ProductStateOptions: list.ProductStateOptions{
+			Quantity:    state.Quantity,
+			FormIndex:   state.FormIndex,
+			Status:      list.StateStatusWaiting,
+			Replacement: mo.None[list.ProductStateReplacement](),
+			Note:        state.Note,
+			Price:       mo.None[list.Price](),
+		},
+		Product:     state.Product,
+		Assignee:    mo.None[id.ID[user.User]](),
+		Comments:    []list.Comment{},
+		Attachments: []list.Attachment{},
+		CreatedAt:   date.NewCreateDate[list.ProductState](),
+		UpdatedAt:   date.NewUpdateDate[list.ProductState](),
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+// Scan finds product by barcode scanned in the shop and marks its waiting state in the list taken.
+// When the product isn't waiting in the list, waiting states which it may replace are offered instead.
+func (s *Service) Scan(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	code string,
+) (
+	list.ScanResult,
+	error,
+) {
+	scanned, err := s.products.Barcode(ctx, code)
+	if err != nil {
+		return list.ScanResult{}, fmt.Errorf("can't find scanned product: %w", err)
+	}
+
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.ScanResult{}, err
+	}
+
+	state, found := model.WaitingState(scanned.ID)
+	if !found {
+		return list.ScanResult{
+			Product:     scanned,
+			Taken:       mo.None[list.ProductState](),
+			Replaceable: model.ReplacementCandidates(scanned),
+		}, nil
+	}
+
+	state.Status = list.StateStatusTaken
+
+	taken, err := s.UpdateProductState(ctx, listID, userID, scanned.ID, state.ProductStateOptions)
+	if err != nil {
+		return list.ScanResult{}, err
+	}
+
+	return list.ScanResult{
+		Product:     scanned,
+		Taken:       mo.Some(taken),
+		Replaceable: []id.ID[product.Product]{},
+	}, nil
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// addScannable creates product with the barcode, products get only categories which exist
+func (f fixture) addScannable(t *testing.T, name product.Name, category product.Category, code string) product.Product {
+	t.Helper()
+
+	require.NoError(t, f.db.FirstOrCreate(&productRepo.ProductCategory{
+		ID:             uuid.NewString(),
+		Name:           string(category),
+		NormalizedName: product.NormalizeName(product.Name(category)),
+		ParentID:       sql.NullString{String: "", Valid: false},
+		Names:          []productRepo.ProductCategoryName{},
+	}, "name = ?", category).Error)
+
+	model, err := f.catalog.Create(context.Background(), product.Options{
+		Name:     name,
+		Category: mo.Some(category),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: []product.Barcode{product.Barcode(code)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mo.Some(category), model.Category)
+
+	return model
+}
+
+func TestScan(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, bread, eggs := f.products[0].ID, f.products[1].ID, f.products[2].ID
+
+	cream := f.addScannable(t, "cream", "dairy", "4006381333931")
+	butter := f.addScannable(t, "butter", "dairy", "5901234123457")
+	bun := f.addScannable(t, "bun", "bakery", "96385074")
+
+	_, err := f.service.AppendProducts(ctx, f.listID, f.ownerID(), map[id.ID[product.Product]]list.ProductStateOptions{
+		cream.ID: {Quantity: mo.Some(product.NewPieces(1)), Status: list.StateStatusWaiting},
+	})
+	require.NoError(t, err)
+
+	adminID := f.members[list.MemberTypeAdmin]
+
+	// butter isn't in the list, it may replace cream and states without category
+	result, err := f.service.Scan(ctx, f.listID, adminID, "5901234123457")
+	require.NoError(t, err)
+	require.Equal(t, butter.ID, result.Product.ID)
+	require.True(t, result.Taken.IsAbsent())
+	require.ElementsMatch(t, []id.ID[product.Product]{milk, bread, eggs, cream.ID}, result.Replaceable)
+	require.Equal(t, list.StateStatusWaiting, stateOf(t, f.list(t), cream.ID).Status, "offers don't change the list")
+
+	result, err = f.service.Scan(ctx, f.listID, adminID, "96385074")
+	require.NoError(t, err)
+	require.Equal(t, bun.ID, result.Product.ID)
+	require.ElementsMatch(t, []id.ID[product.Product]{milk, bread, eggs}, result.Replaceable)
+
+	// dashes of printed barcodes are ignored
+	result, err = f.service.Scan(ctx, f.listID, adminID, "4-006381-333931")
+	require.NoError(t, err)
+	require.Equal(t, cream.ID, result.Product.ID)
+	require.Empty(t, result.Replaceable)
+
+	taken, found := result.Taken.Get()
+	require.True(t, found)
+	require.Equal(t, list.StateStatusTaken, taken.Status)
+	require.Equal(t, mo.Some(product.NewPieces(1)), taken.Quantity)
+	require.Equal(t, list.StateStatusTaken, stateOf(t, f.list(t), cream.ID).Status)
+
+	// taken cream isn't waiting anymore, so it's offered as a replacement of others
+	result, err = f.service.Scan(ctx, f.listID, adminID, "4006381333931")
+	require.NoError(t, err)
+	require.True(t, result.Taken.IsAbsent())
+	require.ElementsMatch(t, []id.ID[product.Product]{milk, bread, eggs}, result.Replaceable)
+}
+
+func TestScanErrors(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	f.addScannable(t, "cream", "dairy", "4006381333931")
+
+	_, err := f.service.Scan(ctx, f.listID, f.ownerID(), "73513537")
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+
+	_, err = f.service.Scan(ctx, f.listID, f.ownerID(), "4006381333932")
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument, "wrong check digit")
+
+	milk, err := f.catalog.ID(ctx, f.products[0].ID)
+	require.NoError(t, err)
+
+	milk.Barcodes = []product.Barcode{"5901234123457"}
+	_, err = f.catalog.Update(ctx, milk.ID, milk.Options)
+	require.NoError(t, err)
+
+	// taking a product updates its state, it's allowed to admins as other updates
+	_, err = f.service.Scan(ctx, f.listID, f.members[list.MemberTypeExecuting], "5901234123457")
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+	require.Equal(t, list.StateStatusWaiting, stateOf(t, f.list(t), milk.ID).Status)
+}
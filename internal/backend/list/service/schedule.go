@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const (
+	// scheduleInterval is how often due schedules are checked, schedules fire with at most this delay
+	scheduleInterval = time.Minute
+	// scheduleBatch is a number of due schedules selected at once
+	scheduleBatch = 100
+	// scheduleRetryDelay is a delay of the next run of a schedule whose next run can't be calculated
+	scheduleRetryDelay = 24 * time.Hour
+)
+
+// CreateSchedule adds schedule which creates lists from the template, lists are owned by the template owner
+func (s *Service) CreateSchedule(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	userID id.ID[user.User],
+	options list.ScheduleOptions,
+) (
+	list.Schedule,
+	error,
+) {
+	if err := validator.New().Struct(options); err != nil {
+		return list.Schedule{}, fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	template, err := s.GetTemplate(ctx, templateID, userID)
+	if err != nil {
+		return list.Schedule{}, err
+	}
+
+	if err = checkTemplateOwner(template, userID); err != nil {
+		return list.Schedule{}, err
+	}
+
+	now := s.clock.Now()
+
+	next, err := options.Next(now)
+	if err != nil {
+		return list.Schedule{}, err
+	}
+
+	schedule := list.Schedule{
+		ScheduleOptions: options,
+		ID:              id.NewID[list.Schedule](),
+		TemplateID:      templateID,
+		OwnerID:         userID,
+		NextRunAt:       next,
+		LastRunAt:       mo.None[time.Time](),
+		LastListID:      mo.None[id.ID[list.ProductList]](),
+		CreatedAt:       now,
+	}
+
+	if err = s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return list.Schedule{}, fmt.Errorf("can't save schedule of template %s: %w", templateID, err)
+	}
+
+	return schedule, nil
+}
+
+func (s *Service) GetSchedules(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	userID id.ID[user.User],
+) (
+	[]list.Schedule,
+	error,
+) {
+	if _, err := s.GetTemplate(ctx, templateID, userID); err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.repo.GetSchedules(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get schedules of template %s: %w", templateID, err)
+	}
+
+	return schedules, nil
+}
+
+func (s *Service) DeleteSchedule(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	scheduleID id.ID[list.Schedule],
+	userID id.ID[user.User],
+) error {
+	template, err := s.GetTemplate(ctx, templateID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = checkTemplateOwner(template, userID); err != nil {
+		return err
+	}
+
+	if err = s.repo.DeleteSchedule(ctx, templateID, scheduleID); err != nil {
+		return fmt.Errorf("can't delete schedule %s: %w", scheduleID, err)
+	}
+
+	return nil
+}
+
+// RunSchedules creates lists by due schedules until ctx is done.
+// It's safe to run it on every replica, each run of a schedule creates only one list.
+func (s *Service) RunSchedules(ctx context.Context) {
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDueSchedules(ctx); err != nil {
+			s.log.Error().Err(err).Msg("can't run due schedules")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDueSchedules creates lists by schedules which should have fired by now and returns number of created lists.
+// Runs missed while no replica was working are merged into one, then the schedule continues from now.
+func (s *Service) RunDueSchedules(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	created := 0
+
+	for {
+		schedules, err := s.repo.GetDueSchedules(ctx, now, scheduleBatch)
+		if err != nil {
+			return created, fmt.Errorf("can't get due schedules: %w", err)
+		}
+
+		stuck := 0
+
+		for _, schedule := range schedules {
+			isCreated, err := s.runSchedule(ctx, schedule, now)
+			if err != nil {
+				s.log.Error().Err(err).Stringer("schedule_id", schedule.ID).Msg("can't move schedule to the next run")
+				stuck++
+			} else if isCreated {
+				created++
+			}
+		}
+
+		// the same schedules would be selected again if none of them were moved
+		if len(schedules) < scheduleBatch || stuck == len(schedules) || ctx.Err() != nil {
+			return created, nil
+		}
+	}
+}
+
+// runSchedule creates list by the schedule and moves it to the next run.
+// It returns false if schedule was run by another replica or list can't be created, then the run is skipped.
+// Error is returned only if schedule isn't moved.
+func (s *Service) runSchedule(ctx context.Context, schedule list.Schedule, now time.Time) (bool, error) {
+	log := s.log.With().Stringer("schedule_id", schedule.ID).Stringer("template_id", schedule.TemplateID).Logger()
+
+	next, err := schedule.Next(now)
+	if err != nil {
+		// schedules are validated on creation, so it's possible only if time zone database was changed
+		log.Error().Err(err).Msg("can't get next run of schedule, it's retried in a day")
+		next = now.Add(scheduleRetryDelay)
+	}
+
+	meta := list.ChangeMeta{Author: schedule.OwnerID, Type: list.EventTypeFull, At: now}
+
+	model, err := s.repo.RunSchedule(ctx, schedule, next, meta, func(template list.Template) (list.ProductList, error) {
+		model := newListFromTemplate(template, schedule.OwnerID)
+		return model, s.validate(model)
+	})
+
+	switch {
+	case errors.Is(err, myerr.ErrConflict):
+		return false, nil
+	case err != nil:
+		log.Error().Err(err).Msg("can't create list by schedule, the run is skipped")
+
+		if err = s.repo.SkipSchedule(ctx, schedule, next); err != nil && !errors.Is(err, myerr.ErrConflict) {
+			return false, fmt.Errorf("can't skip run of schedule %s: %w", schedule.ID, err)
+		}
+
+		return false, nil
+	default:
+		log.Info().Stringer("list_id", model.ID).Time("next_run_at", next).Msg("list is created by schedule")
+		return true, nil
+	}
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/pkg/id"
+)
+
+func TestRunDueSchedules(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	template, err := f.service.SaveTemplate(ctx, f.listID, f.ownerID(), list.TemplateOptions{
+		Title:     "daily",
+		ShopMapID: mo.None[id.ID[shopmap.ShopMap]](),
+		Members:   []list.MemberOptions{},
+	})
+	require.NoError(t, err)
+
+	// the clock is at 09:00, so the first run is today
+	schedule, err := f.service.CreateSchedule(ctx, template.ID, f.ownerID(), list.ScheduleOptions{
+		Period: list.SchedulePeriodCron,
+		Cron:   "0 10 * * *",
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, time.October, 16, 10, 0, 0, 0, time.UTC), schedule.NextRunAt)
+
+	run := func() int {
+		created, err := f.service.RunDueSchedules(ctx)
+		require.NoError(t, err)
+
+		return created
+	}
+	current := func() list.Schedule {
+		schedules, err := f.service.GetSchedules(ctx, template.ID, f.ownerID())
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+
+		return schedules[0]
+	}
+
+	require.Zero(t, run(), "the schedule isn't due yet")
+
+	f.clock.Add(time.Hour)
+	require.Equal(t, 1, run())
+	require.Zero(t, run(), "the run is done already")
+
+	schedule = current()
+	require.Equal(t, mo.Some(f.clock.Now()), schedule.LastRunAt)
+	require.Equal(t, time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC), schedule.NextRunAt)
+
+	model, err := f.service.GetByID(ctx, schedule.LastListID.MustGet(), f.ownerID())
+	require.NoError(t, err)
+	require.Equal(t, "daily", model.Title)
+	require.ElementsMatch(t, productIDs(f.list(t)), productIDs(model))
+
+	// runs missed for four days are merged into one list and the schedule continues from now
+	f.clock.Set(time.Date(2026, time.October, 20, 12, 30, 0, 0, time.UTC))
+	require.Equal(t, 1, run())
+	require.Zero(t, run())
+
+	missed := current()
+	require.NotEqual(t, schedule.LastListID, missed.LastListID)
+	require.Equal(t, time.Date(2026, time.October, 21, 10, 0, 0, 0, time.UTC), missed.NextRunAt)
+
+	// lists are created from the template, changes of the list it was saved from are ignored
+	milk := f.products[0].ID
+	_, err = f.service.DeleteProducts(ctx, f.listID, f.ownerID(), []id.ID[product.Product]{milk})
+	require.NoError(t, err)
+
+	f.clock.Set(missed.NextRunAt)
+	require.Equal(t, 1, run())
+
+	model, err = f.service.GetByID(ctx, current().LastListID.MustGet(), f.ownerID())
+	require.NoError(t, err)
+	require.Contains(t, productIDs(model), milk)
+}
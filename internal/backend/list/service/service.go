@@ -11,45 +11,241 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/samber/lo"
+	"github.com/samber/mo"
 
 	"go-backend/internal/backend/list"
 	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/trash"
 	"go-backend/internal/backend/user"
+	"go-backend/pkg/blob"
+	"go-backend/pkg/clock"
 	"go-backend/pkg/date"
 	"go-backend/pkg/deepcopy"
 	"go-backend/pkg/id"
 	"go-backend/pkg/myerr"
 )
 
+// maxSummaryLimit is the largest page of list summaries
+const maxSummaryLimit = 100
+
 type repo interface {
-	CreateList(context.Context, list.ProductList) error
+	CreateList(context.Context, list.ProductList, list.ChangeMeta) error
 	GetByListID(context.Context, id.ID[list.ProductList]) (list.ProductList, error)
-	GetListMetaByUserID(context.Context, id.ID[user.User]) ([]list.ProductList, error)
+	GetSummaries(context.Context, id.ID[user.User], list.ListFilter) (list.ListPage, error)
+	GetPurchases(context.Context, []id.ID[user.User], time.Time, time.Time) ([]list.Purchase, error)
+	GetHistory(context.Context, id.ID[list.ProductList], int, int) ([]list.HistoryEntry, error)
+	GetAsOf(context.Context, id.ID[list.ProductList], string) (list.ProductList, error)
 
+	// Transaction calls function in one transaction, updates made with its context join the transaction
+	Transaction(context.Context, func(context.Context) error) error
 	GetAndUpdate(
 		context.Context,
 		id.ID[list.ProductList],
+		list.ChangeMeta,
 		func(list.ProductList) (list.ProductList, error),
 	) (
 		list.ProductList,
 		error,
 	)
 
-	GetAndDeleteList(context.Context, id.ID[list.ProductList], func(list.ProductList) error) error
-	ApplyOrder(context.Context, list.RoleCheckFunc, id.ID[list.ProductList], []id.ID[product.Product]) error
+	GetAndUpdateLists(
+		context.Context,
+		[]id.ID[list.ProductList],
+		list.ChangeMeta,
+		func([]list.ProductList) (list.ListsUpdate, error),
+	) (
+		list.ListsUpdate,
+		error,
+	)
+
+	GetAndUndo(
+		context.Context,
+		id.ID[list.ProductList],
+		list.ChangeMeta,
+		int,
+		func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+	) (
+		list.ProductList,
+		error,
+	)
+	GetAndRedo(
+		context.Context,
+		id.ID[list.ProductList],
+		list.ChangeMeta,
+		int,
+		func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+	) (
+		list.ProductList,
+		error,
+	)
+
+	GetAndUpdateSession(
+		context.Context,
+		id.ID[list.ProductList],
+		list.ChangeMeta,
+		func(list.ProductList, mo.Option[list.Session]) (list.SessionUpdate, error),
+	) (
+		list.ProductList,
+		list.Session,
+		error,
+	)
+	GetSessions(context.Context, id.ID[list.ProductList]) ([]list.Session, error)
+
+	AddComment(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[product.Product],
+		list.ChangeMeta,
+		func(list.ProductList) error,
+		list.Comment,
+	) error
+	DeleteComment(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[product.Product],
+		list.ChangeMeta,
+		id.ID[list.Comment],
+		func(list.ProductList, list.Comment) error,
+	) error
+	AddAttachment(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[product.Product],
+		list.ChangeMeta,
+		func(list.ProductList) error,
+		list.Attachment,
+	) error
+	DeleteAttachment(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[product.Product],
+		list.ChangeMeta,
+		id.ID[list.Attachment],
+		func(list.ProductList, list.Attachment) error,
+	) error
+
+	GetAndTrashList(
+		context.Context,
+		id.ID[list.ProductList],
+		list.ChangeMeta,
+		time.Time,
+		func(list.ProductList) error,
+	) error
+	GetTrash(context.Context, id.ID[user.User], time.Time) ([]trash.Item, error)
+	RestoreList(
+		context.Context,
+		id.ID[list.ProductList],
+		time.Time,
+		list.ChangeMeta,
+		func(list.ProductList) error,
+	) (
+		list.ProductList,
+		error,
+	)
+	PurgeLists(context.Context, time.Time, int) ([]list.ProductList, error)
+	PurgeRemovedAttachments(
+		context.Context,
+		time.Time,
+		int,
+		func(string) error,
+	) (
+		int,
+		error,
+	)
+	ApplyOrder(
+		context.Context,
+		list.RoleCheckFunc,
+		id.ID[list.ProductList],
+		list.ChangeMeta,
+		func(list.ProductList) ([]id.ID[product.Product], error),
+	) (
+		list.ProductList,
+		error,
+	)
+
+	CreateTemplate(context.Context, list.Template) error
+	GetTemplate(context.Context, id.ID[list.Template]) (list.Template, error)
+	GetTemplatesByUserID(context.Context, id.ID[user.User]) ([]list.Template, error)
+	GetAndUpdateTemplate(
+		context.Context,
+		id.ID[list.Template],
+		func(list.Template) (list.Template, error),
+	) (
+		list.Template,
+		error,
+	)
+	DeleteTemplate(context.Context, id.ID[list.Template], func(list.Template) error) error
+
+	CreateSchedule(context.Context, list.Schedule) error
+	GetSchedules(context.Context, id.ID[list.Template]) ([]list.Schedule, error)
+	DeleteSchedule(context.Context, id.ID[list.Template], id.ID[list.Schedule]) error
+	GetDueSchedules(context.Context, time.Time, int) ([]list.Schedule, error)
+	RunSchedule(
+		context.Context,
+		list.Schedule,
+		time.Time,
+		list.ChangeMeta,
+		func(list.Template) (list.ProductList, error),
+	) (
+		list.ProductList,
+		error,
+	)
+	SkipSchedule(context.Context, list.Schedule, time.Time) error
+
+	GetUncountedLists(context.Context, int) ([]id.ID[list.ProductList], error)
+	CountPurchases(
+		context.Context,
+		id.ID[list.ProductList],
+		time.Time,
+		func(list.ProductList, []list.PurchaseHistory) ([]list.PurchaseHistory, error),
+	) error
+	GetPurchaseHistories(context.Context, []id.ID[user.User]) ([]list.PurchaseHistory, error)
+}
+
+// products finds and creates products for imported lists, gives categories of new states
+// and finds scanned products by barcodes
+type products interface {
+	IDList(context.Context, []id.ID[product.Product]) ([]product.Product, error)
+	Match(context.Context, []product.Name) ([]product.Match, error)
+	Create(context.Context, product.Options) (product.Product, error)
+	Barcode(context.Context, string) (product.Product, error)
+}
+
+// shopMaps gives order of categories for export and arrangement of states
+type shopMaps interface {
+	GetByID(context.Context, id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error)
 }
 
 type Service struct {
 	channels     map[providerID]*eventProvider
 	channelsLock sync.RWMutex
 	repo         repo
+	products     products
+	shopMaps     shopMaps
+	budgets      budgets
+	blobs        blob.Store
+	clock        clock.Clock
 	log          zerolog.Logger
 }
 
-func NewService(repo repo, log zerolog.Logger) *Service {
+func NewService(
+	repo repo,
+	products products,
+	shopMaps shopMaps,
+	budgets budgets,
+	blobs blob.Store,
+	clock clock.Clock,
+	log zerolog.Logger,
+) *Service {
 	return &Service{
+		clock:        clock,
 		log:          log.With().Str("component", "product list service").Logger(),
 		repo:         repo,
+		products:     products,
+		shopMaps:     shopMaps,
+		budgets:      budgets,
+		blobs:        blobs,
 		channels:     map[providerID]*eventProvider{},
 		channelsLock: sync.RWMutex{},
 	}
@@ -62,12 +258,16 @@ func (s *Service) ReoderStates(
 	ids []id.ID[product.Product],
 ) error {
 	checkFunc, ch := list.CheckRole(userID, list.MemberTypeEditor)
-	err := s.repo.ApplyOrder(ctx, checkFunc, listID, ids)
-	member := <-ch
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeStatesReordered, At: s.clock.Now()}
+	_, err := s.repo.ApplyOrder(ctx, checkFunc, listID, meta, func(list.ProductList) ([]id.ID[product.Product], error) {
+		return ids, nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to apply new order to list %s: %w", listID, err)
 	}
 
+	member := <-ch
+
 	s.sendUpdateEvent(listID, member, list.Change{
 		Type: list.EventTypeStatesReordered,
 		Data: list.StatesReorderedChange{IDs: ids},
@@ -84,6 +284,10 @@ func (s *Service) Create(
 	list.ProductList,
 	error,
 ) {
+	if err := s.checkShopMap(ctx, ownerID, options.ShopMapID); err != nil {
+		return list.ProductList{}, err
+	}
+
 	newList := list.ProductList{
 		States: []list.ProductState{},
 		Members: []list.Member{
@@ -98,8 +302,9 @@ func (s *Service) Create(
 			},
 		},
 		ListOptions: list.ListOptions{
-			Status: list.ExecStatusPlanning,
-			Title:  options.Title,
+			Status:    list.ExecStatusPlanning,
+			Title:     options.Title,
+			ShopMapID: options.ShopMapID,
 		},
 		ID:        id.NewID[list.ProductList](),
 		CreatedAt: date.NewCreateDate[list.ProductList](),
@@ -110,7 +315,8 @@ func (s *Service) Create(
 		return list.ProductList{}, err
 	}
 
-	if err := s.repo.CreateList(ctx, newList); err != nil {
+	meta := list.ChangeMeta{Author: ownerID, Type: list.EventTypeFull, At: s.clock.Now()}
+	if err := s.repo.CreateList(ctx, newList, meta); err != nil {
 		return list.ProductList{}, fmt.Errorf("can't create new list: %w", err)
 	}
 
@@ -126,17 +332,28 @@ func (s *Service) Update(
 	list.ProductList,
 	error,
 ) {
+	if err := s.checkShopMap(ctx, userID, options.ShopMapID); err != nil {
+		return list.ProductList{}, err
+	}
+
 	var member list.Member
 	var err error
-	model, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeOptsUpdated, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
 		member, err = oldList.CheckRole(userID, list.MemberTypeAdmin)
 		if err != nil {
 			return oldList, fmt.Errorf("checking role failed: %w", err)
 		}
 
+		if options.Status != 0 && options.Status != oldList.Status {
+			return oldList, fmt.Errorf("%w: status of list is changed by start and finish of shopping",
+				myerr.ErrInvalidArgument)
+		}
+
 		newList := deepcopy.MustCopy(oldList)
 
 		newList.ListOptions = options
+		newList.Status = oldList.Status
 
 		if err = s.validate(newList); err != nil {
 			return oldList, err
@@ -150,17 +367,19 @@ func (s *Service) Update(
 
 	s.sendUpdateEvent(listID, member, list.Change{
 		Type: list.EventTypeOptsUpdated,
-		Data: list.ListOptionsChange{NewOptions: options},
+		Data: list.ListOptionsChange{NewOptions: model.ListOptions},
 	})
 
 	return model, nil
 }
 
+// DeleteList moves list to the trash, it can be restored until it's purged
 func (s *Service) DeleteList(ctx context.Context, userID id.ID[user.User], listID id.ID[list.ProductList]) error {
 	var member list.Member
 	var err error
 
-	err = s.repo.GetAndDeleteList(ctx, listID, func(oldList list.ProductList) error {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeDeleted, At: s.clock.Now()}
+	err = s.repo.GetAndTrashList(ctx, listID, meta, s.clock.Now(), func(oldList list.ProductList) error {
 		member, err = oldList.CheckRole(userID, list.MemberTypeOwner)
 		if err != nil {
 			return fmt.Errorf("role verification failed: %w", err)
@@ -196,13 +415,72 @@ func (s *Service) GetByID(
 	return model, nil
 }
 
-func (s *Service) GetByUserID(ctx context.Context, userID id.ID[user.User]) ([]list.ProductList, error) {
-	models, err := s.repo.GetListMetaByUserID(ctx, userID)
+// GetAsOf returns list as it was at given point of its history
+func (s *Service) GetAsOf(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	point string,
+) (
+	list.ProductList,
+	error,
+) {
+	if _, err := s.GetByID(ctx, listID, userID); err != nil {
+		return list.ProductList{}, err
+	}
+
+	model, err := s.repo.GetAsOf(ctx, listID, point)
 	if err != nil {
-		return nil, fmt.Errorf("can't get disk meta related to user %s: %w", userID, err)
+		return list.ProductList{}, fmt.Errorf("can't get list %s as of %s: %w", listID, point, err)
 	}
 
-	return models, nil
+	return model, nil
+}
+
+func (s *Service) GetHistory(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	offset, limit int,
+) (
+	[]list.HistoryEntry,
+	error,
+) {
+	if offset < 0 || limit <= 0 {
+		return nil, fmt.Errorf("%w: offset must be non-negative and limit must be positive", myerr.ErrInvalidArgument)
+	}
+
+	if _, err := s.GetByID(ctx, listID, userID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.GetHistory(ctx, listID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("can't get history of list %s: %w", listID, err)
+	}
+
+	return entries, nil
+}
+
+// GetSummaries returns page of summaries of lists which user is a member of
+func (s *Service) GetSummaries(ctx context.Context, userID id.ID[user.User], filter list.ListFilter) (
+	list.ListPage,
+	error,
+) {
+	if filter.Limit <= 0 || filter.Limit > maxSummaryLimit {
+		return list.ListPage{}, fmt.Errorf("%w: limit must be between 1 and %d", myerr.ErrInvalidArgument, maxSummaryLimit)
+	}
+
+	if !filter.Sort.IsValid() {
+		return list.ListPage{}, fmt.Errorf("%w: unknown sort %d", myerr.ErrInvalidArgument, filter.Sort)
+	}
+
+	page, err := s.repo.GetSummaries(ctx, userID, filter)
+	if err != nil {
+		return list.ListPage{}, fmt.Errorf("can't get lists of user %s: %w", userID, err)
+	}
+
+	return page, nil
 }
 
 func (s *Service) AppendMembers(
@@ -226,7 +504,8 @@ func (s *Service) AppendMembers(
 		}
 	})
 
-	model, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeMembersAdded, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
 		// FIXME: may be for some reason it will be implemented on the frontend
 		// if member, err = oldList.CheckRole(userID, list.MemberTypeAdmin); err != nil {
 		// 	return oldList, fmt.Errorf("checking role failed: %w", err)
@@ -264,9 +543,11 @@ func (s *Service) DeleteMembers(
 	error,
 ) {
 	var member list.Member
+	var released []list.Change
 	var err error
 
-	model, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeMembersRemoved, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
 		if len(toDelete) != 1 || toDelete[0] != userID { // or member deleting himself
 			if member, err = oldList.CheckRole(userID, list.MemberTypeAdmin); err != nil {
 				return oldList, fmt.Errorf("checking role failed: %w", err)
@@ -289,6 +570,7 @@ func (s *Service) DeleteMembers(
 		}
 
 		newList.Members = slices.Collect(maps.Values(currentMembers))
+		newList, released = releaseMembers(newList, toDelete)
 
 		if err = s.validate(newList); err != nil {
 			return oldList, err
@@ -305,6 +587,10 @@ func (s *Service) DeleteMembers(
 		Type: list.EventTypeMembersRemoved,
 	})
 
+	for _, change := range released {
+		s.sendUpdateEvent(listID, member, change)
+	}
+
 	return model, nil
 }
 
@@ -317,55 +603,70 @@ func (s *Service) AppendProducts(
 	list.ProductList,
 	error,
 ) {
-	var member list.Member
-	var err error
-
 	s.log.Info().Stringer("list_id", listID).Stringer("user_id", userID).Any("states", states).Msg("appending products")
 
-	newStates := make([]list.ProductState, 0, len(states))
-	for productID, stateOpts := range states {
-		newProductState := list.ProductState{
-			ProductStateOptions: stateOpts,
-			Product: product.Product{
-				Options:   product.NewZeroOptions(),
-				ID:        productID,
-				CreatedAt: date.CreateDate[product.Product]{Time: time.Time{}},
-				UpdatedAt: date.UpdateDate[product.Product]{Time: time.Time{}},
-			},
-			CreatedAt: date.NewCreateDate[list.ProductState](),
-			UpdatedAt: date.NewUpdateDate[list.ProductState](),
-		}
+	model, member, change, err := s.appendProducts(ctx, listID, userID, newProductStates(states, s.clock.Now()))
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	s.log.Info().Stringer("list_id", listID).Stringer("user_id", userID).Any("model", model).Msg("updated")
 
-		newStates = append(newStates, newProductState)
+	s.sendUpdateEvent(listID, member, change)
+
+	return model, nil
+}
+
+// appendProducts appends new states in their order, it doesn't send events, so it can be a part of a larger transaction
+func (s *Service) appendProducts(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	newStates []list.ProductState,
+) (
+	list.ProductList,
+	list.Member,
+	list.Change,
+	error,
+) {
+	var member list.Member
+	var change list.Change
+
+	productIDs := make([]id.ID[product.Product], 0, len(newStates))
+	for _, state := range newStates {
+		productIDs = append(productIDs, state.Product.ID)
 	}
 
-	model, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeProductsAdded, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
+		var err error
+
 		if member, err = oldList.CheckRole(userID, list.MemberTypeEditor); err != nil {
 			return oldList, fmt.Errorf("checking role failed: %w", err)
 		}
 
+		place, err := s.newPlacement(ctx, oldList, productIDs)
+		if err != nil {
+			return oldList, err
+		}
+
 		newList := deepcopy.MustCopy(oldList)
 
-		newList.States = append(newList.States, newStates...)
+		if newList, change, err = appendStates(newList, newStates, place); err != nil {
+			return oldList, err
+		}
 
-		if err = s.validate(oldList); err != nil {
+		if err = s.validate(newList); err != nil {
 			return oldList, err
 		}
 
 		return newList, nil
 	})
 	if err != nil {
-		return list.ProductList{}, fmt.Errorf("can't append products: %w", err)
+		return list.ProductList{}, member, change, fmt.Errorf("can't append products: %w", err)
 	}
 
-	s.log.Info().Stringer("list_id", listID).Stringer("user_id", userID).Any("model", model).Msg("updated")
-
-	s.sendUpdateEvent(listID, member, list.Change{
-		Type: list.EventTypeProductsAdded,
-		Data: list.ProductsAddedChange{Products: newStates},
-	})
-
-	return model, nil
+	return model, member, change, nil
 }
 
 func (s *Service) UpdateProductState(ctx context.Context,
@@ -379,6 +680,7 @@ func (s *Service) UpdateProductState(ctx context.Context,
 ) {
 	var member list.Member
 	var state list.ProductState
+	var spent map[id.ID[product.Product]]list.Price
 
 	s.log.Info().
 		Any("product_state_opts", stateOpts).
@@ -386,7 +688,8 @@ func (s *Service) UpdateProductState(ctx context.Context,
 		Stringer("user_id", userID).
 		Msg("updating product state")
 
-	_, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeStateUpdated, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
 		var err error
 
 		if member, err = oldList.CheckRole(userID, list.MemberTypeAdmin); err != nil {
@@ -395,16 +698,14 @@ func (s *Service) UpdateProductState(ctx context.Context,
 
 		s.log.Debug().Any("model", oldList).Msg("got old list from repo")
 
+		spent = spentPrices(oldList)
+
 		newList := deepcopy.MustCopy(oldList)
 
-		idx := slices.IndexFunc(newList.States, func(s list.ProductState) bool { return s.Product.ID == productID })
-		if idx == -1 {
-			return oldList, fmt.Errorf("%w: product state with product id: %s", myerr.ErrNotFound, productID)
+		if newList, state, err = updateState(newList, productID, stateOpts, s.clock.Now()); err != nil {
+			return oldList, err
 		}
 
-		newList.States[idx].ProductStateOptions = stateOpts
-		state = newList.States[idx]
-
 		if err = s.validate(newList); err != nil {
 			return oldList, err
 		}
@@ -420,6 +721,8 @@ func (s *Service) UpdateProductState(ctx context.Context,
 		Data: list.StateUpdatedChange{ProductID: productID, State: state},
 	})
 
+	s.checkBudgets(ctx, spent, model)
+
 	s.log.Info().Any("product_state", state).Stringer("product_id", productID).Stringer("user_id", userID).
 		Msg("updated product state")
 
@@ -438,30 +741,18 @@ func (s *Service) DeleteProducts(
 	var member list.Member
 	var err error
 
-	model, err := s.repo.GetAndUpdate(ctx, listID, func(oldList list.ProductList) (list.ProductList, error) {
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeProductsRemoved, At: s.clock.Now()}
+	model, err := s.repo.GetAndUpdate(ctx, listID, meta, func(oldList list.ProductList) (list.ProductList, error) {
 		if member, err = oldList.CheckRole(userID, list.MemberTypeEditor); err != nil {
 			return oldList, fmt.Errorf("checking role failed: %w", err)
 		}
 
 		newList := deepcopy.MustCopy(oldList)
 
-		currentStates := lo.SliceToMap(
-			newList.States,
-			func(item list.ProductState) (id.ID[product.Product], list.ProductState) {
-				return item.Product.ID, item
-			},
-		)
-
-		for _, productID := range toDelete {
-			if _, found := currentStates[productID]; !found {
-				return oldList, fmt.Errorf("%w: state with product id %s", myerr.ErrNotFound, productID)
-			}
-
-			delete(currentStates, productID)
+		if newList, err = deleteStates(newList, toDelete); err != nil {
+			return oldList, err
 		}
 
-		newList.States = slices.Collect(maps.Values(currentStates))
-
 		if err = s.validate(newList); err != nil {
 			return oldList, err
 		}
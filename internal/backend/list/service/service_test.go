@@ -0,0 +1,236 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/list/service"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/blob"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// fixture is a list service over sqlite with a list of three products shared by members of every role
+type fixture struct {
+	db       *gorm.DB
+	service  *service.Service
+	clock    *clock.Fake
+	catalog  *productService.Service
+	blobs    blob.Store
+	shopMaps shopMaps
+	budgets  *budgets
+	listID   id.ID[list.ProductList]
+	products []product.Product
+	members  map[list.MemberType]id.ID[user.User]
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "list.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	lists, err := repo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	f := fixture{
+		db:       db,
+		clock:    clock.NewFake(time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)),
+		blobs:    blobs,
+		shopMaps: shopMaps{},
+		budgets:  &budgets{},
+		members:  map[list.MemberType]id.ID[user.User]{},
+	}
+	f.catalog = productService.NewService(products)
+	f.service = service.NewService(
+		lists,
+		f.catalog,
+		f.shopMaps,
+		f.budgets,
+		f.blobs,
+		f.clock,
+		zerolog.Nop(),
+	)
+
+	for _, role := range list.MemberTypeValues() {
+		userID := id.NewID[user.User]()
+		require.NoError(t, db.Create(&userRepo.User{
+			ID:    userID.String(),
+			Login: role.String(),
+			Hash:  "",
+			Role:  int32(user.RoleUser),
+		}).Error)
+
+		f.members[role] = userID
+	}
+
+	model, err := f.service.Create(ctx, f.members[list.MemberTypeOwner], list.ListOptions{Title: "weekly"})
+	require.NoError(t, err)
+	f.listID = model.ID
+
+	members := make([]list.MemberOptions, 0, len(f.members)-1)
+	for _, role := range list.MemberTypeValues()[1:] {
+		members = append(members, list.MemberOptions{UserID: f.members[role], Role: role})
+	}
+
+	_, err = f.service.AppendMembers(ctx, f.listID, f.ownerID(), members)
+	require.NoError(t, err)
+
+	states := map[id.ID[product.Product]]list.ProductStateOptions{}
+	for _, name := range []string{"milk", "bread", "eggs"} {
+		model, err := f.catalog.Create(ctx, product.Options{
+			Name:     product.Name(name),
+			Category: mo.None[product.Category](),
+			Forms:    []product.Form{},
+			Aliases:  []product.Name{},
+			Barcodes: []product.Barcode{},
+		})
+		require.NoError(t, err)
+
+		f.products = append(f.products, model)
+		states[model.ID] = list.ProductStateOptions{Status: list.StateStatusWaiting}
+	}
+
+	_, err = f.service.AppendProducts(ctx, f.listID, f.ownerID(), states)
+	require.NoError(t, err)
+
+	return f
+}
+
+func (f fixture) ownerID() id.ID[user.User] {
+	return f.members[list.MemberTypeOwner]
+}
+
+// list returns the list as the owner sees it
+func (f fixture) list(t *testing.T) list.ProductList {
+	t.Helper()
+
+	model, err := f.service.GetByID(context.Background(), f.listID, f.ownerID())
+	require.NoError(t, err)
+
+	return model
+}
+
+// productIDs returns products of states of the list in their order
+func productIDs(model list.ProductList) []id.ID[product.Product] {
+	ids := make([]id.ID[product.Product], 0, len(model.States))
+	for _, state := range model.States {
+		ids = append(ids, state.Product.ID)
+	}
+
+	return ids
+}
+
+func TestUpdate(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	shopMap := f.addShopMap()
+
+	model, err := f.service.Update(ctx, f.listID, f.ownerID(), list.ListOptions{
+		Title:     "monthly",
+		ShopMapID: mo.Some(shopMap.ID),
+	})
+	require.NoError(t, err)
+	require.Equal(t, list.ListOptions{
+		Status:    list.ExecStatusPlanning,
+		Title:     "monthly",
+		ShopMapID: mo.Some(shopMap.ID),
+	}, model.ListOptions)
+	require.Equal(t, model.ListOptions, f.list(t).ListOptions)
+
+	_, err = f.service.Update(ctx, f.listID, f.ownerID(), list.ListOptions{Status: list.ExecStatusArchived})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	_, err = f.service.Update(ctx, f.listID, f.members[list.MemberTypeEditor], list.ListOptions{Title: "daily"})
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+}
+
+// stateOf returns the state of the product in the list
+func stateOf(t *testing.T, model list.ProductList, productID id.ID[product.Product]) list.ProductState {
+	t.Helper()
+
+	for _, state := range model.States {
+		if state.Product.ID == productID {
+			return state
+		}
+	}
+
+	require.Failf(t, "state not found", "product %s isn't in list %s", productID, model.ID)
+
+	return list.ProductState{}
+}
+
+// addShopMap stores a shop map of the owner with given order of categories
+func (f fixture) addShopMap(categories ...product.Category) shopmap.ShopMap {
+	model := shopmap.ShopMap{
+		Options: shopmap.Options{
+			CategoryList: categories,
+			ViewerIDList: []id.ID[user.User]{},
+			Title:        "corner shop",
+		},
+		ID:        id.NewID[shopmap.ShopMap](),
+		OwnerID:   f.ownerID(),
+		CreatedAt: date.NewCreateDate[shopmap.ShopMap](),
+		UpdatedAt: date.NewUpdateDate[shopmap.ShopMap](),
+	}
+	f.shopMaps[model.ID] = model
+
+	return model
+}
+
+// shopMaps are shop maps by ids, they aren't stored as the shop map repo needs MySQL
+type shopMaps map[id.ID[shopmap.ShopMap]]shopmap.ShopMap
+
+func (m shopMaps) GetByID(_ context.Context, mapID id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error) {
+	model, found := m[mapID]
+	if !found {
+		return shopmap.ShopMap{}, fmt.Errorf("%w: shop map %s", myerr.ErrNotFound, mapID)
+	}
+
+	return model, nil
+}
+
+// budgets returns the same exceeded limits for every purchase
+type budgets struct {
+	exceeded []list.BudgetExceededChange
+}
+
+func (b *budgets) CheckPurchases(
+	context.Context,
+	list.ProductList,
+	[]list.Purchase,
+) (
+	[]list.BudgetExceededChange,
+	error,
+) {
+	return b.exceeded, nil
+}
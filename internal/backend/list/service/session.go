@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/deepcopy"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// StartSession moves planning list to processing and records members who are shopping
+func (s *Service) StartSession(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	opts list.StartOptions,
+) (
+	list.Session,
+	error,
+) {
+	var member list.Member
+
+	shoppers := lo.Uniq(opts.Shoppers)
+	if len(shoppers) == 0 {
+		shoppers = []id.ID[user.User]{userID}
+	}
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeSessionStarted, At: s.clock.Now()}
+	_, session, err := s.repo.GetAndUpdateSession(ctx, listID, meta, func(
+		oldList list.ProductList,
+		current mo.Option[list.Session],
+	) (
+		list.SessionUpdate,
+		error,
+	) {
+		var err error
+
+		if member, err = oldList.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+			return list.SessionUpdate{}, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		if oldList.Status != list.ExecStatusPlanning || current.IsPresent() {
+			return list.SessionUpdate{}, fmt.Errorf("%w: can't start shopping of list in status %s",
+				myerr.ErrConflict, oldList.Status)
+		}
+
+		for _, shopperID := range shoppers {
+			if _, err = oldList.CheckRole(shopperID, list.MemberTypeExecuting); err != nil {
+				return list.SessionUpdate{}, fmt.Errorf("shopper %s can't execute list: %w", shopperID, err)
+			}
+		}
+
+		newList := deepcopy.MustCopy(oldList)
+		newList.Status = list.ExecStatusProcessing
+
+		return list.SessionUpdate{
+			List: newList,
+			Session: list.Session{
+				ID:        id.NewID[list.Session](),
+				ListID:    listID,
+				Shoppers:  shoppers,
+				StartedAt: s.clock.Now(),
+				Summary:   mo.None[list.SessionSummary](),
+				Spent:     []list.Price{},
+			},
+			NextList: mo.None[list.ProductList](),
+		}, nil
+	})
+	if err != nil {
+		return list.Session{}, fmt.Errorf("can't start shopping of list %s: %w", listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeSessionStarted,
+		Data: list.SessionChange{Session: session},
+	})
+
+	return session, nil
+}
+
+// FinishSession archives processing list, marks waiting items as missed and stores summary of the session.
+// Missed items are moved to a new planning list if it's requested.
+func (s *Service) FinishSession(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	opts list.FinishOptions,
+) (
+	list.Session,
+	error,
+) {
+	var member list.Member
+	var nextList mo.Option[list.ProductList]
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeSessionFinished, At: s.clock.Now()}
+	_, session, err := s.repo.GetAndUpdateSession(ctx, listID, meta, func(
+		oldList list.ProductList,
+		current mo.Option[list.Session],
+	) (
+		list.SessionUpdate,
+		error,
+	) {
+		var err error
+
+		if member, err = oldList.CheckRole(userID, list.MemberTypeExecuting); err != nil {
+			return list.SessionUpdate{}, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		session, found := current.Get()
+		if oldList.Status != list.ExecStatusProcessing || !found {
+			return list.SessionUpdate{}, fmt.Errorf("%w: list in status %s has no shopping in progress",
+				myerr.ErrConflict, oldList.Status)
+		}
+
+		newList := deepcopy.MustCopy(oldList)
+		newList.Status = list.ExecStatusArchived
+
+		summary := list.SessionSummary{
+			FinishedAt: s.clock.Now(),
+			Duration:   0,
+			Taken:      0,
+			Missed:     0,
+			Replaced:   0,
+			NextListID: mo.None[id.ID[list.ProductList]](),
+		}
+		summary.Duration = summary.FinishedAt.Sub(session.StartedAt)
+
+		var missed []list.ProductState
+		for i, state := range newList.States {
+			if state.Status == list.StateStatusWaiting {
+				newList.States[i].Status = list.StateStatusMissed
+			}
+
+			switch newList.States[i].Status {
+			case list.StateStatusTaken:
+				summary.Taken++
+			case list.StateStatusReplaced:
+				summary.Replaced++
+			case list.StateStatusMissed:
+				summary.Missed++
+				missed = append(missed, newList.States[i])
+			case list.StateStatusWaiting:
+			}
+		}
+
+		nextList = mo.None[list.ProductList]()
+		if opts.RollOverMissed && len(missed) != 0 {
+			next := newPlanningList(oldList, missed)
+			if err = s.validate(next); err != nil {
+				return list.SessionUpdate{}, err
+			}
+
+			summary.NextListID = mo.Some(next.ID)
+			nextList = mo.Some(next)
+		}
+
+		session.Summary = mo.Some(summary)
+		session.Spent = newList.Spent(session.StartedAt)
+
+		return list.SessionUpdate{List: newList, Session: session, NextList: nextList}, nil
+	})
+	if err != nil {
+		return list.Session{}, fmt.Errorf("can't finish shopping of list %s: %w", listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeSessionFinished,
+		Data: list.SessionChange{Session: session},
+	})
+
+	if next, found := nextList.Get(); found {
+		s.sendUpdateEvent(next.ID, member, list.Change{
+			Data: list.FullUpdateChange{ProductList: next},
+			Type: list.EventTypeFull,
+		})
+	}
+
+	return session, nil
+}
+
+// GetSessions returns shopping sessions of the list from the newest to the oldest one with their spending
+func (s *Service) GetSessions(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+) (
+	[]list.Session,
+	error,
+) {
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.GetSessions(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get sessions of list %s: %w", listID, err)
+	}
+
+	// spending of unfinished session isn't stored yet, it's counted over current purchases
+	for i, session := range sessions {
+		if session.Summary.IsAbsent() {
+			sessions[i].Spent = model.Spent(session.StartedAt)
+		}
+	}
+
+	return sessions, nil
+}
+
+// newPlanningList makes a list with the same title and members, which contains given states waiting again
+func newPlanningList(oldList list.ProductList, states []list.ProductState) list.ProductList {
+	members := deepcopy.MustCopy(oldList.Members)
+	for i := range members {
+		members[i].CreatedAt = date.NewCreateDate[list.Member]()
+		members[i].UpdatedAt = date.NewUpdateDate[list.Member]()
+	}
+
+	states = slices.Clone(states)
+	for i := range states {
+		states[i].Status = list.StateStatusWaiting
+		states[i].Replacement = mo.None[list.ProductStateReplacement]()
+		states[i].Price = mo.None[list.Price]()
+		states[i].PurchasedAt = mo.None[time.Time]()
+		states[i].Assignee = mo.None[id.ID[user.User]]()
+		states[i].CreatedAt = date.NewCreateDate[list.ProductState]()
+		states[i].UpdatedAt = date.NewUpdateDate[list.ProductState]()
+	}
+
+	return list.ProductList{
+		ListOptions: list.ListOptions{
+			Status:    list.ExecStatusPlanning,
+			Title:     oldList.Title,
+			ShopMapID: oldList.ShopMapID,
+		},
+		States:    states,
+		Members:   members,
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.NewUpdateDate[list.ProductList](),
+		CreatedAt: date.NewCreateDate[list.ProductList](),
+	}
+}
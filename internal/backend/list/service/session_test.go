@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestStartSession(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+
+	_, err := f.service.StartSession(ctx, f.listID, f.members[list.MemberTypeViewer], list.StartOptions{})
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	// viewer can't be a shopper
+	_, err = f.service.StartSession(ctx, f.listID, executing, list.StartOptions{
+		Shoppers: []id.ID[user.User]{f.members[list.MemberTypeViewer]},
+	})
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	session, err := f.service.StartSession(ctx, f.listID, executing, list.StartOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[user.User]{executing}, session.Shoppers)
+	require.Equal(t, f.clock.Now(), session.StartedAt)
+	require.True(t, session.Summary.IsAbsent())
+	require.Equal(t, list.ExecStatusProcessing, f.list(t).Status)
+
+	_, err = f.service.StartSession(ctx, f.listID, executing, list.StartOptions{})
+	require.ErrorIs(t, err, myerr.ErrConflict)
+}
+
+func TestFinishSession(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+	milk, bread, eggs := f.products[0].ID, f.products[1].ID, f.products[2].ID
+
+	_, err := f.service.FinishSession(ctx, f.listID, executing, list.FinishOptions{})
+	require.ErrorIs(t, err, myerr.ErrConflict)
+
+	started, err := f.service.StartSession(ctx, f.listID, executing, list.StartOptions{})
+	require.NoError(t, err)
+
+	f.clock.Add(45 * time.Minute)
+
+	_, err = f.service.UpdateProductState(ctx, f.listID, f.ownerID(), milk, list.ProductStateOptions{
+		Status: list.StateStatusTaken,
+		Price:  mo.Some(list.Price{Amount: decimal.RequireFromString("1.20"), Currency: "EUR"}),
+	})
+	require.NoError(t, err)
+
+	_, err = f.service.UpdateProductState(ctx, f.listID, f.ownerID(), bread, list.ProductStateOptions{
+		Status: list.StateStatusReplaced,
+		Replacement: mo.Some(list.ProductStateReplacement{
+			Product: f.products[2],
+			Price:   mo.Some(list.Price{Amount: decimal.RequireFromString("2.30"), Currency: "EUR"}),
+		}),
+	})
+	require.NoError(t, err)
+
+	session, err := f.service.FinishSession(ctx, f.listID, executing, list.FinishOptions{})
+	require.NoError(t, err)
+	require.Equal(t, started.ID, session.ID)
+
+	summary, found := session.Summary.Get()
+	require.True(t, found)
+	require.Equal(t, 45*time.Minute, summary.Duration)
+	require.Equal(t, 1, summary.Taken)
+	require.Equal(t, 1, summary.Replaced)
+	require.Equal(t, 1, summary.Missed)
+	require.True(t, summary.NextListID.IsAbsent())
+	require.Len(t, session.Spent, 1)
+	require.True(t, decimal.RequireFromString("3.50").Equal(session.Spent[0].Amount))
+
+	model := f.list(t)
+	require.Equal(t, list.ExecStatusArchived, model.Status)
+	require.Equal(t, list.StateStatusMissed, stateOf(t, model, eggs).Status)
+
+	_, err = f.service.FinishSession(ctx, f.listID, executing, list.FinishOptions{})
+	require.ErrorIs(t, err, myerr.ErrConflict)
+
+	sessions, err := f.service.GetSessions(ctx, f.listID, f.ownerID())
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session.Summary, sessions[0].Summary)
+}
+
+func TestFinishSessionRollsOverMissed(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+	milk, bread, eggs := f.products[0].ID, f.products[1].ID, f.products[2].ID
+
+	_, err := f.service.StartSession(ctx, f.listID, executing, list.StartOptions{})
+	require.NoError(t, err)
+
+	_, err = f.service.UpdateProductState(ctx, f.listID, f.ownerID(), milk, list.ProductStateOptions{
+		Status: list.StateStatusTaken,
+	})
+	require.NoError(t, err)
+
+	_, err = f.service.Claim(ctx, f.listID, executing, bread)
+	require.NoError(t, err)
+
+	session, err := f.service.FinishSession(ctx, f.listID, executing, list.FinishOptions{RollOverMissed: true})
+	require.NoError(t, err)
+
+	nextID, found := session.Summary.OrEmpty().NextListID.Get()
+	require.True(t, found)
+
+	next, err := f.service.GetByID(ctx, nextID, f.ownerID())
+	require.NoError(t, err)
+	require.Equal(t, list.ExecStatusPlanning, next.Status)
+	require.Equal(t, "weekly", next.Title)
+	require.Len(t, next.Members, len(f.members))
+	require.ElementsMatch(t, []id.ID[product.Product]{bread, eggs}, productIDs(next))
+
+	// rolled over items are waiting again and released
+	for _, state := range next.States {
+		require.Equal(t, list.StateStatusWaiting, state.Status)
+		require.True(t, state.Assignee.IsAbsent())
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/shopspring/decimal"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// spendingKey groups purchases of one period, category, shop map and currency
+type spendingKey struct {
+	periodStart time.Time
+	category    mo.Option[product.Category]
+	shopMapID   mo.Option[id.ID[shopmap.ShopMap]]
+	currency    string
+}
+
+// GetSpending returns money spent in lists of the user grouped by periods, categories, shop maps and currencies.
+// Groups are sorted by these keys in the same order.
+func (s *Service) GetSpending(
+	ctx context.Context,
+	userID id.ID[user.User],
+	filter list.SpendingFilter,
+) (
+	[]list.Spending,
+	error,
+) {
+	if !filter.Period.IsValid() {
+		return nil, fmt.Errorf("%w: unknown period %d", myerr.ErrInvalidArgument, filter.Period)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("%w: start of spending range must be before its end", myerr.ErrInvalidArgument)
+	}
+
+	purchases, err := s.repo.GetPurchases(ctx, []id.ID[user.User]{userID}, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("can't get purchases of user %s: %w", userID, err)
+	}
+
+	spending := make([]list.Spending, 0)
+	indexes := make(map[spendingKey]int)
+
+	for _, purchase := range purchases {
+		key := spendingKey{
+			periodStart: filter.Period.Start(purchase.PurchasedAt),
+			category:    purchase.Category,
+			shopMapID:   purchase.ShopMapID,
+			currency:    purchase.Price.Currency,
+		}
+
+		idx, found := indexes[key]
+		if !found {
+			idx = len(spending)
+			indexes[key] = idx
+			spending = append(spending, list.Spending{
+				Price:       list.Price{Amount: decimal.Zero, Currency: key.currency},
+				PeriodStart: key.periodStart,
+				Category:    key.category,
+				ShopMapID:   key.shopMapID,
+				Items:       0,
+			})
+		}
+
+		spending[idx].Amount = spending[idx].Amount.Add(purchase.Price.Amount)
+		spending[idx].Items++
+	}
+
+	slices.SortFunc(spending, func(a, b list.Spending) int {
+		return cmp.Or(
+			a.PeriodStart.Compare(b.PeriodStart),
+			cmp.Compare(a.Category.OrEmpty(), b.Category.OrEmpty()),
+			cmp.Compare(a.ShopMapID.OrEmpty().String(), b.ShopMapID.OrEmpty().String()),
+			cmp.Compare(a.Currency, b.Currency),
+		)
+	})
+
+	return spending, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const (
+	// historyInterval is how often purchases of archived lists are added to histories
+	historyInterval = 10 * time.Minute
+	// historyBatch is a number of archived lists selected at once
+	historyBatch = 100
+	// maxSuggestionLimit is the largest number of returned suggestions
+	maxSuggestionLimit = 100
+)
+
+// GetSuggestions returns products which members of the list usually buy and which aren't in the list yet
+func (s *Service) GetSuggestions(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	limit int,
+) (
+	[]list.Suggestion,
+	error,
+) {
+	if limit <= 0 || limit > maxSuggestionLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", myerr.ErrInvalidArgument, maxSuggestionLimit)
+	}
+
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberIDs := lo.Map(model.Members, func(item list.Member, _ int) id.ID[user.User] { return item.UserID })
+
+	histories, err := s.repo.GetPurchaseHistories(ctx, memberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("can't get purchase histories of members of list %s: %w", listID, err)
+	}
+
+	suggestions := list.Suggest(histories, model, s.clock.Now())
+	suggestions = suggestions[:min(limit, len(suggestions))]
+
+	products, err := s.products.IDList(ctx, lo.Map(suggestions, func(item list.Suggestion, _ int) id.ID[product.Product] {
+		return item.Product.ID
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("can't get suggested products: %w", err)
+	}
+
+	for i, suggestion := range suggestions {
+		idx := slices.IndexFunc(products, func(p product.Product) bool { return p.ID == suggestion.Product.ID })
+		if idx != -1 {
+			suggestions[i].Product = products[idx]
+		}
+	}
+
+	return suggestions, nil
+}
+
+// RunPurchaseHistories adds purchases of archived lists to histories until ctx is done.
+// It's safe to run it on every replica, each list is counted once.
+func (s *Service) RunPurchaseHistories(ctx context.Context) {
+	ticker := time.NewTicker(historyInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.UpdatePurchaseHistories(ctx); err != nil {
+			s.log.Error().Err(err).Msg("can't update purchase histories")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdatePurchaseHistories adds purchases of archived lists which aren't counted yet to histories of their members
+// and returns number of counted lists. A list which can't be counted is logged and skipped till the next run,
+// so it doesn't hold back the others.
+func (s *Service) UpdatePurchaseHistories(ctx context.Context) (int, error) {
+	counted := 0
+	failed := make(map[id.ID[list.ProductList]]struct{})
+
+	for {
+		// failed lists stay uncounted, so they're selected again besides a full batch of others
+		limit := historyBatch + len(failed)
+
+		listIDs, err := s.repo.GetUncountedLists(ctx, limit)
+		if err != nil {
+			return counted, fmt.Errorf("can't get uncounted lists: %w", err)
+		}
+
+		for _, listID := range listIDs {
+			if _, found := failed[listID]; found {
+				continue
+			}
+
+			err = s.repo.CountPurchases(ctx, listID, s.clock.Now(), addPurchases)
+			if errors.Is(err, myerr.ErrConflict) {
+				continue
+			} else if err != nil {
+				s.log.Error().Err(err).Stringer("list_id", listID).Msg("can't count purchases of list")
+				failed[listID] = struct{}{}
+
+				continue
+			}
+
+			counted++
+		}
+
+		if len(listIDs) < limit {
+			return counted, nil
+		}
+	}
+}
+
+// addPurchases adds products bought in the list to histories of its members.
+// Replaced products count as the planned ones, as they were bought for the same need.
+// States purchased before purchase times were recorded count as bought when the list was last updated.
+func addPurchases(model list.ProductList, histories []list.PurchaseHistory) ([]list.PurchaseHistory, error) {
+	for _, state := range model.States {
+		if !state.Purchased() {
+			continue
+		}
+
+		at := state.PurchasedAt.OrElse(model.UpdatedAt.Time)
+
+		for _, member := range model.Members {
+			idx := slices.IndexFunc(histories, func(h list.PurchaseHistory) bool {
+				return h.UserID == member.UserID && h.ProductID == state.Product.ID
+			})
+			if idx == -1 {
+				idx = len(histories)
+				histories = append(histories, list.PurchaseHistory{ //nolint:exhaustruct
+					UserID:    member.UserID,
+					ProductID: state.Product.ID,
+				})
+			}
+
+			histories[idx] = histories[idx].Add(at)
+		}
+	}
+
+	return histories, nil
+}
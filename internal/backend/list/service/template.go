@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// SaveTemplate saves content of the list as a new template owned by the user, members and statuses aren't copied
+func (s *Service) SaveTemplate(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	options list.TemplateOptions,
+) (
+	list.Template,
+	error,
+) {
+	if err := validateTemplate(options, userID); err != nil {
+		return list.Template{}, err
+	}
+
+	if err := s.checkShopMap(ctx, userID, options.ShopMapID); err != nil {
+		return list.Template{}, err
+	}
+
+	model, err := s.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.Template{}, err
+	}
+
+	now := s.clock.Now()
+	template := list.Template{
+		TemplateOptions: options,
+		ID:              id.NewID[list.Template](),
+		OwnerID:         userID,
+		Items: lo.Map(model.States, func(state list.ProductState, _ int) list.TemplateItem {
+			return list.TemplateItem{
+				Product:   state.Product,
+				Quantity:  state.Quantity,
+				FormIndex: state.FormIndex,
+				Note:      state.Note,
+			}
+		}),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err = s.repo.CreateTemplate(ctx, template); err != nil {
+		return list.Template{}, fmt.Errorf("can't save template of list %s: %w", listID, err)
+	}
+
+	return template, nil
+}
+
+// GetTemplates returns templates which user owns or is a member of
+func (s *Service) GetTemplates(ctx context.Context, userID id.ID[user.User]) ([]list.Template, error) {
+	templates, err := s.repo.GetTemplatesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get templates of user %s: %w", userID, err)
+	}
+
+	return templates, nil
+}
+
+func (s *Service) GetTemplate(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	userID id.ID[user.User],
+) (
+	list.Template,
+	error,
+) {
+	template, err := s.repo.GetTemplate(ctx, templateID)
+	if err != nil {
+		return list.Template{}, fmt.Errorf("can't get template %s: %w", templateID, err)
+	}
+
+	if err = template.CheckAccess(userID); err != nil {
+		return list.Template{}, err
+	}
+
+	return template, nil
+}
+
+// UpdateTemplate replaces options of the template, only its owner can do it
+func (s *Service) UpdateTemplate(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	userID id.ID[user.User],
+	options list.TemplateOptions,
+) (
+	list.Template,
+	error,
+) {
+	if err := validateTemplate(options, userID); err != nil {
+		return list.Template{}, err
+	}
+
+	if err := s.checkShopMap(ctx, userID, options.ShopMapID); err != nil {
+		return list.Template{}, err
+	}
+
+	template, err := s.repo.GetAndUpdateTemplate(ctx, templateID, func(template list.Template) (list.Template, error) {
+		if err := checkTemplateOwner(template, userID); err != nil {
+			return template, err
+		}
+
+		template.TemplateOptions = options
+		template.UpdatedAt = s.clock.Now()
+
+		return template, nil
+	})
+	if err != nil {
+		return list.Template{}, fmt.Errorf("can't update template %s: %w", templateID, err)
+	}
+
+	return template, nil
+}
+
+// DeleteTemplate deletes the template and its schedules, only its owner can do it
+func (s *Service) DeleteTemplate(ctx context.Context, templateID id.ID[list.Template], userID id.ID[user.User]) error {
+	err := s.repo.DeleteTemplate(ctx, templateID, func(template list.Template) error {
+		return checkTemplateOwner(template, userID)
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete template %s: %w", templateID, err)
+	}
+
+	return nil
+}
+
+// CreateFromTemplate creates a planning list owned by the user from the template, members of the template are added
+func (s *Service) CreateFromTemplate(
+	ctx context.Context,
+	templateID id.ID[list.Template],
+	userID id.ID[user.User],
+) (
+	list.ProductList,
+	error,
+) {
+	template, err := s.GetTemplate(ctx, templateID, userID)
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	model := newListFromTemplate(template, userID)
+
+	if err = s.validate(model); err != nil {
+		return list.ProductList{}, err
+	}
+
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeFull, At: s.clock.Now()}
+	if err = s.repo.CreateList(ctx, model, meta); err != nil {
+		return list.ProductList{}, fmt.Errorf("can't create list from template %s: %w", templateID, err)
+	}
+
+	return model, nil
+}
+
+func checkTemplateOwner(template list.Template, userID id.ID[user.User]) error {
+	if template.OwnerID != userID {
+		return fmt.Errorf("%w: only owner can change template %s", myerr.ErrForbidden, template.ID)
+	}
+
+	return nil
+}
+
+// newListFromTemplate returns planning list with items of the template, template members except owner are added
+func newListFromTemplate(template list.Template, ownerID id.ID[user.User]) list.ProductList {
+	members := []list.Member{newMember(ownerID, list.MemberTypeOwner)}
+	for _, member := range template.Members {
+		if member.UserID != ownerID {
+			members = append(members, newMember(member.UserID, member.Role))
+		}
+	}
+
+	states := lo.Map(template.Items, func(item list.TemplateItem, _ int) list.ProductState {
+		return list.ProductState{
+			ProductStateOptions: list.ProductStateOptions{
+				Quantity:    item.Quantity,
+				FormIndex:   item.FormIndex,
+				Status:      list.StateStatusWaiting,
+				Replacement: mo.None[list.ProductStateReplacement](),
+				Note:        item.Note,
+				Price:       mo.None[list.Price](),
+			},
+			Product:     item.Product,
+			Assignee:    mo.None[id.ID[user.User]](),
+			Comments:    []list.Comment{},
+			Attachments: []list.Attachment{},
+			CreatedAt:   date.NewCreateDate[list.ProductState](),
+			UpdatedAt:   date.NewUpdateDate[list.ProductState](),
+			PurchasedAt: mo.None[time.Time](),
+		}
+	})
+
+	return list.ProductList{
+		ListOptions: list.ListOptions{
+			Status:    list.ExecStatusPlanning,
+			Title:     template.Title,
+			ShopMapID: template.ShopMapID,
+		},
+		States:    states,
+		Members:   members,
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.NewUpdateDate[list.ProductList](),
+		CreatedAt: date.NewCreateDate[list.ProductList](),
+	}
+}
+
+func newMember(userID id.ID[user.User], role list.MemberType) list.Member {
+	return list.Member{
+		MemberOptions: list.MemberOptions{UserID: userID, Role: role},
+		UserName:      "",
+		CreatedAt:     date.NewCreateDate[list.Member](),
+		UpdatedAt:     date.NewUpdateDate[list.Member](),
+	}
+}
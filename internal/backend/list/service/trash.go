@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+// purgeBatch is the number of lists purged in one call of the repository
+const purgeBatch = 100
+
+// GetTrash returns lists moved to the trash after given time, which user can restore
+func (s *Service) GetTrash(ctx context.Context, userID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	items, err := s.repo.GetTrash(ctx, userID, after)
+	if err != nil {
+		return nil, fmt.Errorf("can't get trashed lists of user %s: %w", userID, err)
+	}
+
+	return items, nil
+}
+
+// RestoreFromTrash takes list moved to the trash after given time back, user must be an admin of the list
+func (s *Service) RestoreFromTrash(
+	ctx context.Context,
+	userID id.ID[user.User],
+	entityID uuid.UUID,
+	after time.Time,
+) error {
+	var member list.Member
+	var err error
+
+	listID := id.ID[list.ProductList]{UUID: entityID}
+	meta := list.ChangeMeta{Author: userID, Type: list.EventTypeRestored, At: s.clock.Now()}
+
+	model, err := s.repo.RestoreList(ctx, listID, after, meta, func(oldList list.ProductList) error {
+		if member, err = oldList.CheckRole(userID, list.MemberTypeAdmin); err != nil {
+			return fmt.Errorf("role verification failed: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't restore product list %s: %w", listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: list.EventTypeRestored,
+		Data: list.ListRestoredChange{ProductList: model},
+	})
+
+	return nil
+}
+
+// PurgeTrash deletes lists moved to the trash before given time together with contents of their attachments.
+// Contents of attachments of items removed from lists before that time are deleted too.
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	if err := s.purgeRemovedAttachments(ctx, before); err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for {
+		models, err := s.repo.PurgeLists(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, fmt.Errorf("can't purge lists: %w", err)
+		}
+
+		for _, model := range models {
+			for _, state := range model.States {
+				for _, attachment := range state.Attachments {
+					s.deleteBlob(ctx, attachment)
+				}
+			}
+		}
+
+		purged += len(models)
+		if len(models) < purgeBatch {
+			return purged, nil
+		}
+	}
+}
+
+func (s *Service) purgeRemovedAttachments(ctx context.Context, before time.Time) error {
+	for {
+		count, err := s.repo.PurgeRemovedAttachments(ctx, before, purgeBatch, func(key string) error {
+			return s.blobs.Delete(ctx, key) //nolint:wrapcheck // store wraps errors
+		})
+		if err != nil {
+			return fmt.Errorf("can't purge attachments of removed items: %w", err)
+		}
+
+		if count < purgeBatch {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/deepcopy"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// eventRoles are roles required to make a change of given type
+var eventRoles = map[list.EventType]list.MemberType{
+	list.EventTypeProductsAdded:   list.MemberTypeEditor,
+	list.EventTypeProductsRemoved: list.MemberTypeEditor,
+	list.EventTypeStatesReordered: list.MemberTypeEditor,
+	list.EventTypeStateUpdated:    list.MemberTypeAdmin,
+	list.EventTypeMembersAdded:    list.MemberTypeAdmin,
+	list.EventTypeMembersRemoved:  list.MemberTypeAdmin,
+	list.EventTypeOptsUpdated:     list.MemberTypeAdmin,
+	list.EventTypeStateAssigned:   list.MemberTypeEditor,
+}
+
+type revertRepoFunc func(
+	context.Context,
+	id.ID[list.ProductList],
+	list.ChangeMeta,
+	int,
+	func(list.ProductList, []list.UndoRecord) (list.ProductList, error),
+) (
+	list.ProductList,
+	error,
+)
+
+// Undo reverts last count changes made by the user.
+// Changes made by other members later than reverted ones are not overwritten, conflict is returned instead.
+func (s *Service) Undo(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	count int,
+) (
+	list.ProductList,
+	error,
+) {
+	return s.revert(ctx, listID, userID, count, list.EventTypeUndone, s.repo.GetAndUndo,
+		func(record list.UndoRecord) (list.ProductList, list.ProductList) { return record.After, record.Before })
+}
+
+// Redo applies again last count changes undone by the user
+func (s *Service) Redo(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	count int,
+) (
+	list.ProductList,
+	error,
+) {
+	return s.revert(ctx, listID, userID, count, list.EventTypeRedone, s.repo.GetAndRedo,
+		func(record list.UndoRecord) (list.ProductList, list.ProductList) { return record.Before, record.After })
+}
+
+func (s *Service) revert(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+	count int,
+	eventType list.EventType,
+	repoFunc revertRepoFunc,
+	direction func(list.UndoRecord) (list.ProductList, list.ProductList),
+) (
+	list.ProductList,
+	error,
+) {
+	var member list.Member
+	var changes []list.Change
+
+	if count <= 0 {
+		return list.ProductList{}, fmt.Errorf("%w: count must be positive", myerr.ErrInvalidArgument)
+	}
+
+	meta := list.ChangeMeta{Author: userID, Type: eventType, At: s.clock.Now()}
+	model, err := repoFunc(ctx, listID, meta, count, func(
+		oldList list.ProductList,
+		records []list.UndoRecord,
+	) (
+		list.ProductList,
+		error,
+	) {
+		var err error
+
+		if member, err = oldList.CheckRole(userID, list.MemberTypeViewer); err != nil {
+			return oldList, fmt.Errorf("checking role failed: %w", err)
+		}
+
+		changes = []list.Change{}
+		newList := deepcopy.MustCopy(oldList)
+
+		for _, record := range records {
+			from, to := direction(record)
+
+			var recordChanges []list.Change
+			newList, recordChanges, err = revertChange(newList, from, to)
+			if err != nil {
+				return oldList, fmt.Errorf("can't revert %s change %s: %w", record.Type, record.ID, err)
+			}
+
+			changes = append(changes, recordChanges...)
+		}
+
+		for _, change := range changes {
+			if _, err = oldList.CheckRole(userID, changeRole(oldList, userID, change)); err != nil {
+				return oldList, fmt.Errorf("checking role for %s failed: %w", change.Type, err)
+			}
+		}
+
+		if err = s.validate(newList); err != nil {
+			return oldList, err
+		}
+
+		return newList, nil
+	})
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't %s changes of list %s: %w", eventType, listID, err)
+	}
+
+	s.sendUpdateEvent(listID, member, list.Change{
+		Type: eventType,
+		Data: list.BatchChange{Changes: changes},
+	})
+
+	return model, nil
+}
+
+// changeRole returns role required to revert the change.
+// Member can claim and release products for themselves, so reverting it requires the same role.
+func changeRole(oldList list.ProductList, userID id.ID[user.User], change list.Change) list.MemberType {
+	assigned, ok := change.Data.(list.AssigneeChange)
+	if !ok || assigned.Assignee.OrElse(userID) != userID {
+		return eventRoles[change.Type]
+	}
+
+	idx := slices.IndexFunc(oldList.States, func(s list.ProductState) bool {
+		return s.Product.ID == assigned.ProductID
+	})
+	if idx == -1 || oldList.States[idx].Assignee.OrElse(userID) != userID {
+		return eventRoles[change.Type]
+	}
+
+	return list.MemberTypeExecuting
+}
+
+// revertChange applies change from -> to to the current list.
+// Every part of current list touched by the change must be the same as in from, otherwise it's a conflict.
+func revertChange(current, from, to list.ProductList) (list.ProductList, []list.Change, error) {
+	var conflicts []string
+	var changes []list.Change
+
+	// status is changed only by shopping sessions, so it's never reverted
+	optionsChanged := false
+
+	if from.Title != to.Title {
+		if current.Title != from.Title {
+			conflicts = append(conflicts, "title was changed")
+		} else {
+			current.Title = to.Title
+			optionsChanged = true
+		}
+	}
+
+	if from.ShopMapID != to.ShopMapID {
+		if current.ShopMapID != from.ShopMapID {
+			conflicts = append(conflicts, "default shop map was changed")
+		} else {
+			current.ShopMapID = to.ShopMapID
+			optionsChanged = true
+		}
+	}
+
+	if optionsChanged {
+		changes = append(changes, list.Change{
+			Type: list.EventTypeOptsUpdated,
+			Data: list.ListOptionsChange{NewOptions: current.ListOptions},
+		})
+	}
+
+	current, memberChanges, memberConflicts := revertMembers(current, from, to)
+	current, stateChanges, stateConflicts := revertStates(current, from, to)
+
+	changes = append(changes, memberChanges...)
+	changes = append(changes, stateChanges...)
+	conflicts = append(conflicts, memberConflicts...)
+	conflicts = append(conflicts, stateConflicts...)
+
+	if len(conflicts) != 0 {
+		return current, nil, fmt.Errorf("%w: %s", myerr.ErrConflict, strings.Join(conflicts, "; "))
+	}
+
+	return current, changes, nil
+}
+
+func revertMembers(current, from, to list.ProductList) (list.ProductList, []list.Change, []string) {
+	var conflicts []string
+	var changes []list.Change
+
+	fromMembers := lo.KeyBy(from.Members, func(m list.Member) id.ID[user.User] { return m.UserID })
+	toMembers := lo.KeyBy(to.Members, func(m list.Member) id.ID[user.User] { return m.UserID })
+	currentMembers := lo.KeyBy(current.Members, func(m list.Member) id.ID[user.User] { return m.UserID })
+
+	var added []list.Member
+	var removed []id.ID[user.User]
+
+	for _, toMember := range to.Members {
+		fromMember, existed := fromMembers[toMember.UserID]
+		currentMember, exists := currentMembers[toMember.UserID]
+
+		switch {
+		case existed && fromMember.Role == toMember.Role:
+			continue
+		case !existed && exists:
+			conflicts = append(conflicts, fmt.Sprintf("member %s was added", toMember.UserID))
+		case existed && (!exists || currentMember.Role != fromMember.Role):
+			conflicts = append(conflicts, fmt.Sprintf("member %s was changed", toMember.UserID))
+		default:
+			added = append(added, toMember)
+		}
+	}
+
+	for _, fromMember := range from.Members {
+		if _, stays := toMembers[fromMember.UserID]; stays {
+			continue
+		}
+
+		currentMember, exists := currentMembers[fromMember.UserID]
+		if !exists || currentMember.Role != fromMember.Role {
+			conflicts = append(conflicts, fmt.Sprintf("member %s was changed", fromMember.UserID))
+			continue
+		}
+
+		removed = append(removed, fromMember.UserID)
+	}
+
+	if len(removed) != 0 {
+		current.Members = slices.DeleteFunc(current.Members, func(m list.Member) bool {
+			return slices.Contains(removed, m.UserID)
+		})
+		changes = append(changes, list.Change{
+			Type: list.EventTypeMembersRemoved,
+			Data: list.MembersDeletedChange{UserIDs: removed},
+		})
+	}
+
+	if len(added) != 0 {
+		current.Members = slices.DeleteFunc(current.Members, func(m list.Member) bool {
+			return slices.ContainsFunc(added, func(a list.Member) bool { return a.UserID == m.UserID })
+		})
+		current.Members = append(current.Members, added...)
+		changes = append(changes, list.Change{
+			Type: list.EventTypeMembersAdded,
+			Data: list.MembersAddedChange{NewMembers: added},
+		})
+	}
+
+	return current, changes, conflicts
+}
+
+func revertStates(current, from, to list.ProductList) (list.ProductList, []list.Change, []string) {
+	var conflicts []string
+	var changes []list.Change
+
+	fromStates := lo.KeyBy(from.States, stateProductID)
+	toStates := lo.KeyBy(to.States, stateProductID)
+	currentStates := lo.KeyBy(current.States, stateProductID)
+
+	var added []list.ProductState
+	var removed []id.ID[product.Product]
+
+	for _, fromState := range from.States {
+		if _, stays := toStates[fromState.Product.ID]; stays {
+			continue
+		}
+
+		currentState, exists := currentStates[fromState.Product.ID]
+		if !exists || !sameState(currentState, fromState) {
+			conflicts = append(conflicts, fmt.Sprintf("state of product %s was changed", fromState.Product.ID))
+			continue
+		}
+
+		removed = append(removed, fromState.Product.ID)
+	}
+
+	if len(removed) != 0 {
+		current.States = slices.DeleteFunc(current.States, func(s list.ProductState) bool {
+			return slices.Contains(removed, s.Product.ID)
+		})
+		changes = append(changes, list.Change{
+			Type: list.EventTypeProductsRemoved,
+			Data: list.ProductsRemovedChange{IDs: removed},
+		})
+	}
+
+	for toIdx, toState := range to.States {
+		fromState, existed := fromStates[toState.Product.ID]
+		currentState, exists := currentStates[toState.Product.ID]
+
+		switch {
+		case !existed && exists:
+			conflicts = append(conflicts, fmt.Sprintf("product %s was added", toState.Product.ID))
+		case !existed:
+			added = append(added, toState)
+			current.States = slices.Insert(current.States, min(toIdx, len(current.States)), toState)
+		case sameState(fromState, toState):
+			continue
+		case !exists || !sameState(currentState, fromState):
+			conflicts = append(conflicts, fmt.Sprintf("state of product %s was changed", toState.Product.ID))
+		case sameStateOptions(fromState.ProductStateOptions, toState.ProductStateOptions):
+			// only assignee is changed, so it's reverted as a claim or release
+			idx := slices.IndexFunc(current.States, func(s list.ProductState) bool {
+				return s.Product.ID == toState.Product.ID
+			})
+			current.States[idx].Assignee = toState.Assignee
+			changes = append(changes, list.Change{
+				Type: list.EventTypeStateAssigned,
+				Data: list.AssigneeChange{ProductID: toState.Product.ID, Assignee: toState.Assignee},
+			})
+		default:
+			idx := slices.IndexFunc(current.States, func(s list.ProductState) bool {
+				return s.Product.ID == toState.Product.ID
+			})
+			current.States[idx].ProductStateOptions = toState.ProductStateOptions
+			current.States[idx].PurchasedAt = toState.PurchasedAt
+			current.States[idx].Assignee = toState.Assignee
+			changes = append(changes, list.Change{
+				Type: list.EventTypeStateUpdated,
+				Data: list.StateUpdatedChange{ProductID: toState.Product.ID, State: current.States[idx]},
+			})
+		}
+	}
+
+	if len(added) != 0 {
+		changes = append(changes, list.Change{
+			Type: list.EventTypeProductsAdded,
+			Data: list.ProductsAddedChange{Products: added},
+		})
+	}
+
+	fromOrder := commonOrder(from.States, toStates)
+	toOrder := commonOrder(to.States, fromStates)
+
+	if slices.Equal(fromOrder, toOrder) {
+		return current, changes, conflicts
+	}
+
+	if !slices.Equal(commonOrder(current.States, fromStates), fromOrder) {
+		return current, changes, append(conflicts, "order of products was changed")
+	}
+
+	current.States = applyOrder(current.States, to.States)
+	changes = append(changes, list.Change{
+		Type: list.EventTypeStatesReordered,
+		Data: list.StatesReorderedChange{IDs: lo.Map(current.States, func(s list.ProductState, _ int) id.ID[product.Product] {
+			return s.Product.ID
+		})},
+	})
+
+	return current, changes, conflicts
+}
+
+// commonOrder returns ids of states in order of states, only states presented in other are returned
+func commonOrder(
+	states []list.ProductState,
+	other map[id.ID[product.Product]]list.ProductState,
+) []id.ID[product.Product] {
+	order := make([]id.ID[product.Product], 0, len(states))
+	for _, state := range states {
+		if _, found := other[state.Product.ID]; found {
+			order = append(order, state.Product.ID)
+		}
+	}
+
+	return order
+}
+
+// applyOrder places states presented in target in order of target, other states keep their positions
+func applyOrder(states, target []list.ProductState) []list.ProductState {
+	rank := make(map[id.ID[product.Product]]int, len(target))
+	for idx, state := range target {
+		rank[state.Product.ID] = idx
+	}
+
+	positions := make([]int, 0, len(states))
+	ranked := make([]list.ProductState, 0, len(states))
+	for idx, state := range states {
+		if _, found := rank[state.Product.ID]; found {
+			positions = append(positions, idx)
+			ranked = append(ranked, state)
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b list.ProductState) int {
+		return rank[a.Product.ID] - rank[b.Product.ID]
+	})
+
+	for i, position := range positions {
+		states[position] = ranked[i]
+	}
+
+	return states
+}
+
+func sameState(a, b list.ProductState) bool {
+	return a.Assignee == b.Assignee && sameStateOptions(a.ProductStateOptions, b.ProductStateOptions)
+}
+
+func sameStateOptions(a, b list.ProductStateOptions) bool {
+	if a.Status != b.Status || a.Quantity != b.Quantity || a.FormIndex != b.FormIndex || a.Note != b.Note {
+		return false
+	}
+
+	if a.Replacement.IsPresent() != b.Replacement.IsPresent() || !samePrice(a.Price, b.Price) {
+		return false
+	}
+
+	aReplacement, bReplacement := a.Replacement.OrEmpty(), b.Replacement.OrEmpty()
+
+	return aReplacement.Product.ID == bReplacement.Product.ID &&
+		aReplacement.Quantity == bReplacement.Quantity &&
+		aReplacement.FormIndex == bReplacement.FormIndex &&
+		samePrice(aReplacement.Price, bReplacement.Price)
+}
+
+// samePrice compares amounts by value, so 1.5 and 1.50 are the same
+func samePrice(a, b mo.Option[list.Price]) bool {
+	aPrice, aFound := a.Get()
+	bPrice, bFound := b.Get()
+
+	if !aFound || !bFound {
+		return aFound == bFound
+	}
+
+	return aPrice.Currency == bPrice.Currency && aPrice.Amount.Equal(bPrice.Amount)
+}
+
+func stateProductID(s list.ProductState) id.ID[product.Product] {
+	return s.Product.ID
+}
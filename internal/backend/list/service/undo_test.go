@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestUndoClaim(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+	milk := f.products[0].ID
+
+	_, err := f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	// the member who claimed the product can undo the claim without editor role
+	model, err := f.service.Undo(ctx, f.listID, executing, 1)
+	require.NoError(t, err)
+	require.Equal(t, mo.None[id.ID[user.User]](), stateOf(t, model, milk).Assignee)
+
+	model, err = f.service.Redo(ctx, f.listID, executing, 1)
+	require.NoError(t, err)
+	require.Equal(t, mo.Some(executing), stateOf(t, model, milk).Assignee)
+
+	// releasing own claim is undone by the member too
+	f.clock.Add(time.Minute)
+	_, err = f.service.Release(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	model, err = f.service.Undo(ctx, f.listID, executing, 1)
+	require.NoError(t, err)
+	require.Equal(t, mo.Some(executing), stateOf(t, model, milk).Assignee)
+}
+
+func TestUndoConflict(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	executing := f.members[list.MemberTypeExecuting]
+	admin := f.members[list.MemberTypeAdmin]
+	milk := f.products[0].ID
+
+	_, err := f.service.Claim(ctx, f.listID, executing, milk)
+	require.NoError(t, err)
+
+	_, err = f.service.Assign(ctx, f.listID, admin, milk, list.AssignOptions{Assignee: mo.Some(admin)})
+	require.NoError(t, err)
+
+	// the claim is overwritten by the admin, so it's a conflict and the record stays applied
+	_, err = f.service.Undo(ctx, f.listID, executing, 1)
+	require.ErrorIs(t, err, myerr.ErrConflict)
+	require.Equal(t, mo.Some(admin), stateOf(t, f.list(t), milk).Assignee)
+
+	_, err = f.service.Redo(ctx, f.listID, executing, 1)
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
+
+func TestUndoRestoresPosition(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	before := productIDs(f.list(t))
+
+	// records are ordered by time of changes, the list is filled by the owner a minute ago
+	f.clock.Add(time.Minute)
+	deletedAt := f.clock.Now()
+
+	_, err := f.service.DeleteProducts(ctx, f.listID, f.ownerID(), before[1:2])
+	require.NoError(t, err)
+
+	// untouched states aren't stored in the record, but their positions are
+	f.clock.Add(time.Minute)
+	model, err := f.service.Undo(ctx, f.listID, f.ownerID(), 1)
+	require.NoError(t, err)
+	require.Equal(t, before, productIDs(model))
+	require.Equal(t, "milk", string(stateOf(t, model, f.products[0].ID).Product.Name))
+
+	var record repo.ProductListUndoRecord
+	require.NoError(t, f.db.Where("undone_at IS NOT NULL").First(&record).Error)
+	require.True(t, deletedAt.Equal(record.CreatedAt))
+	require.True(t, f.clock.Now().Equal(*record.UndoneAt))
+}
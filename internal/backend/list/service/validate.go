@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/samber/lo"
@@ -13,6 +14,8 @@ import (
 	"go-backend/pkg/myerr"
 )
 
+const maxNoteLength = 1024
+
 func (s *Service) validate(model list.ProductList) error {
 	err := validator.New().Struct(model)
 	if err != nil {
@@ -31,5 +34,74 @@ func (s *Service) validate(model list.ProductList) error {
 		return fmt.Errorf("%w: non-unique product states", myerr.ErrInvalidArgument)
 	}
 
+	for _, state := range model.States {
+		if utf8.RuneCountInString(state.Note) > maxNoteLength {
+			return fmt.Errorf("%w: note of product %s is longer than %d characters",
+				myerr.ErrInvalidArgument, state.Product.ID, maxNoteLength)
+		}
+
+		if quantity, found := state.Quantity.Get(); found {
+			if err = quantity.Validate(); err != nil {
+				return fmt.Errorf("invalid quantity of product %s: %w", state.Product.ID, err)
+			}
+		}
+
+		if quantity, found := state.Replacement.OrEmpty().Quantity.Get(); found {
+			if err = quantity.Validate(); err != nil {
+				return fmt.Errorf("invalid quantity of replacement of product %s: %w", state.Product.ID, err)
+			}
+		}
+
+		if err = validatePrices(state); err != nil {
+			return fmt.Errorf("invalid price of product %s: %w", state.Product.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// validatePrices checks that only taken product has its price and only replaced one has a price of the replacement
+func validatePrices(state list.ProductState) error {
+	if price, found := state.Price.Get(); found {
+		if state.Status != list.StateStatusTaken {
+			return fmt.Errorf("%w: price is set for product in status %s", myerr.ErrInvalidArgument, state.Status)
+		}
+
+		if err := price.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if price, found := state.Replacement.OrEmpty().Price.Get(); found {
+		if state.Status != list.StateStatusReplaced {
+			return fmt.Errorf("%w: price of replacement is set for product in status %s",
+				myerr.ErrInvalidArgument, state.Status)
+		}
+
+		if err := price.Validate(); err != nil {
+			return fmt.Errorf("replacement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateTemplate checks options of a template owned by ownerID
+func validateTemplate(options list.TemplateOptions, ownerID id.ID[user.User]) error {
+	if err := validator.New().Struct(options); err != nil {
+		return fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	for _, member := range options.Members {
+		if member.UserID == ownerID {
+			return fmt.Errorf("%w: owner of template can't be its member", myerr.ErrInvalidArgument)
+		}
+
+		if member.Role <= list.MemberTypeOwner || !member.Role.IsValid() {
+			return fmt.Errorf("%w: role of template member %s must be admin, editor, executing or viewer",
+				myerr.ErrInvalidArgument, member.UserID)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,164 @@
+package list
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+const (
+	// intervalSmoothing is a weight of the latest gap between purchases in a typical interval,
+	// so the interval follows changes of habits but isn't broken by a single late purchase
+	intervalSmoothing = 0.3
+	// recencyHalfLife is a time after which a purchase weighs half as much in a score of suggestion
+	recencyHalfLife = 30 * day
+	// overdueIntervals is a number of intervals after the due time when a product stops being overdue,
+	// it's likely not bought anymore, so it shouldn't stay on top of suggestions forever
+	overdueIntervals = 2
+	day              = 24 * time.Hour
+)
+
+// PurchaseHistory is a history of purchases of a product by a user in archived lists.
+// It's updated incrementally by Add, so lists are counted once without keeping every purchase.
+type PurchaseHistory struct {
+	UserID    id.ID[user.User]
+	ProductID id.ID[product.Product]
+	Count     int
+	FirstAt   time.Time
+	LastAt    time.Time
+	// Interval is a typical time between purchases, it's known after the second purchase
+	Interval mo.Option[time.Duration]
+}
+
+// Add returns history with one more purchase made at the given time.
+// Purchases older than the last one are counted, but don't change the interval.
+func (h PurchaseHistory) Add(at time.Time) PurchaseHistory {
+	h.Count++
+
+	switch {
+	case h.Count == 1:
+		h.FirstAt, h.LastAt = at, at
+	case at.Before(h.LastAt):
+		if at.Before(h.FirstAt) {
+			h.FirstAt = at
+		}
+	default:
+		gap := at.Sub(h.LastAt)
+		h.LastAt = at
+
+		interval, found := h.Interval.Get()
+		if !found {
+			h.Interval = mo.Some(gap)
+			break
+		}
+
+		h.Interval = mo.Some(interval + time.Duration(intervalSmoothing*float64(gap-interval)))
+	}
+
+	return h
+}
+
+// DueAt returns time when the product is expected to be bought again
+func (h PurchaseHistory) DueAt() mo.Option[time.Time] {
+	interval, found := h.Interval.Get()
+	if !found {
+		return mo.None[time.Time]()
+	}
+
+	return mo.Some(h.LastAt.Add(interval))
+}
+
+// Overdue reports whether the product is past its due time by at most overdueIntervals intervals
+func (h PurchaseHistory) Overdue(now time.Time) bool {
+	dueAt, found := h.DueAt().Get()
+	if !found || !now.After(dueAt) {
+		return false
+	}
+
+	return !now.After(dueAt.Add(overdueIntervals * h.Interval.MustGet()))
+}
+
+// Suggestion is a product which members of a list usually buy
+type Suggestion struct {
+	Product product.Product `json:"product"`
+	// Count is a number of archived lists where the product was bought
+	Count           int       `json:"count"`
+	LastPurchasedAt time.Time `json:"last_purchased_at"`
+	// IntervalDays is a typical number of days between purchases
+	IntervalDays mo.Option[float64]   `json:"interval_days" swaggertype:"number" extensions:"x-nullable"`
+	DueAt        mo.Option[time.Time] `json:"due_at" swaggertype:"string" extensions:"x-nullable"`
+	// Overdue is set when the product should have been bought again by now,
+	// products which weren't bought for long after their due time aren't overdue anymore
+	Overdue bool `json:"overdue"`
+	// Score grows with number of purchases and falls with time since the last one
+	Score float64 `json:"score"`
+}
+
+// Suggest ranks products from histories of list members, products of the list are skipped.
+// Members usually share lists, so the most complete history of a product is used instead of their sum.
+// Overdue products go first, others are sorted by score. Only ids of products are set.
+func Suggest(histories []PurchaseHistory, model ProductList, now time.Time) []Suggestion {
+	best := make(map[id.ID[product.Product]]PurchaseHistory)
+
+	for _, history := range histories {
+		if slices.ContainsFunc(model.States, func(s ProductState) bool { return s.Product.ID == history.ProductID }) {
+			continue
+		}
+
+		current, found := best[history.ProductID]
+		if !found || cmp.Or(cmp.Compare(history.Count, current.Count), history.LastAt.Compare(current.LastAt)) > 0 {
+			best[history.ProductID] = history
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(best))
+
+	for _, history := range best {
+		dueAt := history.DueAt()
+		age := max(now.Sub(history.LastAt), 0)
+
+		intervalDays := mo.None[float64]()
+		if interval, found := history.Interval.Get(); found {
+			intervalDays = mo.Some(float64(interval) / float64(day))
+		}
+
+		suggestions = append(suggestions, Suggestion{
+			Product:         product.Product{ID: history.ProductID}, //nolint:exhaustruct
+			Count:           history.Count,
+			LastPurchasedAt: history.LastAt,
+			IntervalDays:    intervalDays,
+			DueAt:           dueAt,
+			Overdue:         history.Overdue(now),
+			Score:           float64(history.Count) * math.Exp2(-float64(age)/float64(recencyHalfLife)),
+		})
+	}
+
+	slices.SortFunc(suggestions, func(a, b Suggestion) int {
+		return cmp.Or(
+			compareBool(b.Overdue, a.Overdue),
+			cmp.Compare(b.Score, a.Score),
+			b.LastPurchasedAt.Compare(a.LastPurchasedAt),
+			cmp.Compare(a.Product.ID.String(), b.Product.ID.String()),
+		)
+	})
+
+	return suggestions
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,96 @@
+package list_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+const day = 24 * time.Hour
+
+// history returns synthetic history of purchases made every interval days until the last day
+func history(productID id.ID[product.Product], last time.Time, count, interval int) list.PurchaseHistory {
+	h := list.PurchaseHistory{UserID: id.NewID[user.User](), ProductID: productID}
+
+	for i := count - 1; i >= 0; i-- {
+		h = h.Add(last.AddDate(0, 0, -i*interval))
+	}
+
+	return h
+}
+
+func TestPurchaseHistoryAdd(t *testing.T) {
+	start := time.Date(2026, time.September, 1, 10, 0, 0, 0, time.UTC)
+
+	h := history(id.NewID[product.Product](), start, 1, 0)
+	require.True(t, h.Interval.IsAbsent())
+	require.True(t, h.DueAt().IsAbsent())
+
+	h = history(id.NewID[product.Product](), start.AddDate(0, 0, 16), 5, 4)
+	require.Equal(t, 5, h.Count)
+	require.Equal(t, start, h.FirstAt)
+	require.Equal(t, 4*day, h.Interval.MustGet())
+	require.Equal(t, start.AddDate(0, 0, 20), h.DueAt().MustGet())
+
+	// a late purchase moves the interval only partly
+	late := h.Add(h.LastAt.AddDate(0, 0, 14))
+	require.Greater(t, late.Interval.MustGet(), 4*day)
+	require.Less(t, late.Interval.MustGet(), 14*day)
+
+	// an older purchase is counted without changing the interval
+	older := h.Add(start.AddDate(0, 0, -3))
+	require.Equal(t, 6, older.Count)
+	require.Equal(t, start.AddDate(0, 0, -3), older.FirstAt)
+	require.Equal(t, h.LastAt, older.LastAt)
+	require.Equal(t, h.Interval, older.Interval)
+}
+
+func TestSuggest(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	milk, bread, cake, eggs := id.NewID[product.Product](), id.NewID[product.Product](), id.NewID[product.Product](),
+		id.NewID[product.Product]()
+	coffee := id.NewID[product.Product]()
+
+	histories := []list.PurchaseHistory{
+		// milk every 4 days, last bought 6 days ago, so it's overdue
+		history(milk, now.AddDate(0, 0, -6), 10, 4),
+		// a shorter history of the same milk by another member isn't summed up
+		history(milk, now.AddDate(0, 0, -6), 3, 4),
+		// bread every week, bought yesterday
+		history(bread, now.AddDate(0, 0, -1), 20, 7),
+		// cake was bought once long ago
+		history(cake, now.AddDate(0, -6, 0), 1, 0),
+		// coffee every 3 days wasn't bought for a month, so it's given up rather than overdue
+		history(coffee, now.AddDate(0, 0, -30), 10, 3),
+		// eggs are already in the list
+		history(eggs, now.AddDate(0, 0, -30), 10, 7),
+	}
+
+	model := list.ProductList{
+		States: []list.ProductState{{Product: product.Product{ID: eggs}}},
+	}
+
+	suggestions := list.Suggest(histories, model, now)
+	require.Len(t, suggestions, 4)
+
+	require.Equal(t, milk, suggestions[0].Product.ID)
+	require.True(t, suggestions[0].Overdue)
+	require.Equal(t, 10, suggestions[0].Count)
+	require.InDelta(t, 4, suggestions[0].IntervalDays.MustGet(), 0.001)
+
+	require.Equal(t, bread, suggestions[1].Product.ID)
+	require.False(t, suggestions[1].Overdue)
+
+	require.Equal(t, coffee, suggestions[2].Product.ID)
+	require.False(t, suggestions[2].Overdue)
+
+	require.Equal(t, cake, suggestions[3].Product.ID)
+	require.True(t, suggestions[3].IntervalDays.IsAbsent())
+	require.Less(t, suggestions[3].Score, suggestions[2].Score)
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/pantry"
+	"go-backend/internal/backend/pantry/service"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+const defaultExpiringDays = 3
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	group := r.Group("/pantry")
+
+	log = log.With().Str("component", "pantry rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	group.POST("", h.AddItem)
+	group.GET("", h.GetItems)
+	group.GET("/expiring", h.GetExpiring)
+	group.GET("/low", h.GetLowStock)
+	group.PUT("/:id", h.UpdateItem)
+	group.DELETE("/:id", h.DeleteItem)
+	group.POST("/:id/consume", h.Consume)
+	group.GET("/thresholds", h.GetThresholds)
+	group.PUT("/thresholds/:product_id", h.SetThreshold)
+	group.DELETE("/thresholds/:product_id", h.DeleteThreshold)
+}
+
+// @Summary put product to the pantry of the user
+// @ID pantry-add
+// @Tags Pantry
+// @Param body body pantry.NewItem true "product, quantity, location and expiry date"
+// @Produce json
+// @Accept json
+// @Router /pantry [post]
+// @Security ApiKeyAuth
+func (h *Handler) AddItem(ctx *gin.Context) {
+	var options pantry.NewItem
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.AddItem(ctx, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get items of the pantry, the ones expiring first go first
+// @ID pantry-get
+// @Tags Pantry
+// @Produce json
+// @Router /pantry [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetItems(ctx *gin.Context) {
+	items, err := h.service.GetItems(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// @Summary get items which expire soon or are already expired
+// @ID pantry-expiring
+// @Tags Pantry
+// @Param days query int false "number of days from now, 3 by default"
+// @Produce json
+// @Router /pantry/expiring [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetExpiring(ctx *gin.Context) {
+	days, ok := rerr.QueryInt(ctx, "days", defaultExpiringDays)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.GetExpiring(ctx, api.GetUserID(ctx), days)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// @Summary get products whose stock is below their thresholds
+// @ID pantry-low
+// @Tags Pantry
+// @Produce json
+// @Router /pantry/low [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetLowStock(ctx *gin.Context) {
+	stocks, err := h.service.GetLowStock(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stocks)
+}
+
+// @Summary update quantity, location and expiry date of pantry item
+// @ID pantry-update
+// @Tags Pantry
+// @Param id path string true "pantry item id"
+// @Param body body pantry.ItemOptions true "new options of item"
+// @Produce json
+// @Accept json
+// @Router /pantry/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) UpdateItem(ctx *gin.Context) {
+	var options pantry.ItemOptions
+
+	itemID, ok := rerr.PathID[pantry.Item](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.UpdateItem(ctx, itemID, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete pantry item without recording consumption
+// @ID pantry-delete
+// @Tags Pantry
+// @Param id path string true "pantry item id"
+// @Router /pantry/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteItem(ctx *gin.Context) {
+	itemID, ok := rerr.PathID[pantry.Item](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteItem(ctx, itemID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary record consumption of pantry item, the item is deleted when nothing is left
+// @ID pantry-consume
+// @Tags Pantry
+// @Param id path string true "pantry item id"
+// @Param body body pantry.Consumption true "consumed quantity"
+// @Produce json
+// @Accept json
+// @Router /pantry/{id}/consume [post]
+// @Security ApiKeyAuth
+func (h *Handler) Consume(ctx *gin.Context) {
+	var consumption pantry.Consumption
+
+	itemID, ok := rerr.PathID[pantry.Item](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &consumption); !ok {
+		return
+	}
+
+	model, err := h.service.Consume(ctx, itemID, api.GetUserID(ctx), consumption)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get low stock thresholds of the user
+// @ID pantry-thresholds-get
+// @Tags Pantry
+// @Produce json
+// @Router /pantry/thresholds [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetThresholds(ctx *gin.Context) {
+	thresholds, err := h.service.GetThresholds(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, thresholds)
+}
+
+// @Summary set low stock threshold of product, with list id the product is added to the list when stock is low
+// @ID pantry-threshold-set
+// @Tags Pantry
+// @Param product_id path string true "product id"
+// @Param body body pantry.ThresholdOptions true "minimal quantity and planning list"
+// @Produce json
+// @Accept json
+// @Router /pantry/thresholds/{product_id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) SetThreshold(ctx *gin.Context) {
+	var options pantry.ThresholdOptions
+
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.SetThreshold(ctx, api.GetUserID(ctx), productID, options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete low stock threshold of product
+// @ID pantry-threshold-delete
+// @Tags Pantry
+// @Param product_id path string true "product id"
+// @Router /pantry/thresholds/{product_id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteThreshold(ctx *gin.Context) {
+	productID, ok := rerr.Path[product.Product](ctx, "product_id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteThreshold(ctx, api.GetUserID(ctx), productID); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,173 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package pantry
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// LocationFridge is a Location of type Fridge.
+	LocationFridge Location = iota + 1
+	// LocationFreezer is a Location of type Freezer.
+	LocationFreezer
+	// LocationCupboard is a Location of type Cupboard.
+	LocationCupboard
+)
+
+var ErrInvalidLocation = fmt.Errorf("not a valid Location, try [%s]", strings.Join(_LocationNames, ", "))
+
+const _LocationName = "fridgefreezercupboard"
+
+var _LocationNames = []string{
+	_LocationName[0:6],
+	_LocationName[6:13],
+	_LocationName[13:21],
+}
+
+// LocationNames returns a list of possible string values of Location.
+func LocationNames() []string {
+	tmp := make([]string, len(_LocationNames))
+	copy(tmp, _LocationNames)
+	return tmp
+}
+
+// LocationValues returns a list of the values for Location
+func LocationValues() []Location {
+	return []Location{
+		LocationFridge,
+		LocationFreezer,
+		LocationCupboard,
+	}
+}
+
+var _LocationMap = map[Location]string{
+	LocationFridge:   _LocationName[0:6],
+	LocationFreezer:  _LocationName[6:13],
+	LocationCupboard: _LocationName[13:21],
+}
+
+// String implements the Stringer interface.
+func (x Location) String() string {
+	if str, ok := _LocationMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Location(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Location) IsValid() bool {
+	_, ok := _LocationMap[x]
+	return ok
+}
+
+var _LocationValue = map[string]Location{
+	_LocationName[0:6]:   LocationFridge,
+	_LocationName[6:13]:  LocationFreezer,
+	_LocationName[13:21]: LocationCupboard,
+}
+
+// ParseLocation attempts to convert a string to a Location.
+func ParseLocation(name string) (Location, error) {
+	if x, ok := _LocationValue[name]; ok {
+		return x, nil
+	}
+	return Location(0), fmt.Errorf("%s is %w", name, ErrInvalidLocation)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Location) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Location) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseLocation(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errLocationNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *Location) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = Location(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = Location(v)
+	case string:
+		*x, err = ParseLocation(v)
+	case []byte:
+		*x, err = ParseLocation(string(v))
+	case Location:
+		*x = v
+	case int:
+		*x = Location(v)
+	case *Location:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = Location(v)
+	case uint64:
+		*x = Location(v)
+	case *int:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = Location(*v)
+	case *int64:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = Location(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = Location(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = Location(*v)
+	case *uint:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = Location(*v)
+	case *uint64:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x = Location(*v)
+	case *string:
+		if v == nil {
+			return errLocationNilPtr
+		}
+		*x, err = ParseLocation(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x Location) Value() (driver.Value, error) {
+	return x.String(), nil
+}
@@ -0,0 +1,208 @@
+package pantry
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+//go:generate python $GOENUM
+
+// ENUM(fridge=1, freezer, cupboard)
+type Location int32
+
+// ItemOptions are editable options of a pantry item
+type ItemOptions struct {
+	Quantity  product.Quantity     `json:"quantity"`
+	Location  Location             `json:"location" swaggertype:"string"`
+	ExpiresAt mo.Option[time.Time] `json:"expires_at" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// NewItem is a product put to the pantry manually
+type NewItem struct {
+	ItemOptions
+
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+}
+
+// Item is an amount of a product kept at home.
+// The same product may be kept in several items, e.g. with different expiry dates.
+type Item struct {
+	ItemOptions
+
+	ID      id.ID[Item]      `json:"id" swaggertype:"string"`
+	OwnerID id.ID[user.User] `json:"owner_id" swaggertype:"string"`
+	Product product.Product  `json:"product"`
+	// ListID is an archived list where the item was bought, it's absent for items added manually
+	ListID    mo.Option[id.ID[list.ProductList]] `json:"list_id" swaggertype:"string" extensions:"x-nullable"`
+	CreatedAt time.Time                          `json:"created_at"`
+	UpdatedAt time.Time                          `json:"updated_at"`
+}
+
+// Empty reports whether nothing is left of the item
+func (i Item) Empty() bool {
+	return i.Quantity.Value <= 0
+}
+
+// ExpiresBefore reports whether the item has an expiry date before t
+func (i Item) ExpiresBefore(t time.Time) bool {
+	expiresAt, found := i.ExpiresAt.Get()
+	return found && expiresAt.Before(t)
+}
+
+// Consume returns the item with the quantity taken out of it, the result is empty when everything is consumed
+func (i Item) Consume(quantity product.Quantity) (Item, error) {
+	if err := quantity.Validate(); err != nil {
+		return i, err
+	}
+
+	converted, err := quantity.Convert(i.Quantity.Unit)
+	if err != nil {
+		return i, err
+	}
+
+	i.Quantity.Value = max(i.Quantity.Value-converted.Value, 0)
+
+	return i, nil
+}
+
+// Consumption is an amount of an item used up at home
+type Consumption struct {
+	Quantity product.Quantity `json:"quantity"`
+}
+
+// ItemsFromList returns products purchased in the archived list as items of the list owner.
+// Replaced products are kept as their replacements, as they're what was brought home.
+// Products without quantity count as one piece and all items go to the cupboard, they can be moved later.
+func ItemsFromList(model list.ProductList, now time.Time) []Item {
+	ownerIdx := slices.IndexFunc(model.Members, func(m list.Member) bool { return m.Role == list.MemberTypeOwner })
+	if ownerIdx == -1 {
+		return nil
+	}
+
+	items := make([]Item, 0)
+
+	for _, state := range model.States {
+		if !state.Purchased() {
+			continue
+		}
+
+		bought, quantity := state.Product, state.Quantity
+		if replacement, found := state.Replacement.Get(); found && state.Status == list.StateStatusReplaced {
+			bought, quantity = replacement.Product, replacement.Quantity
+		}
+
+		items = append(items, Item{
+			ItemOptions: ItemOptions{
+				Quantity:  quantity.OrElse(product.NewPieces(1)),
+				Location:  LocationCupboard,
+				ExpiresAt: mo.None[time.Time](),
+			},
+			ID:        id.NewID[Item](),
+			OwnerID:   model.Members[ownerIdx].UserID,
+			Product:   bought,
+			ListID:    mo.Some(model.ID),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return items
+}
+
+// ThresholdOptions are options of a low stock threshold of a product
+type ThresholdOptions struct {
+	Min product.Quantity `json:"min"`
+	// ListID is a planning list where the product is added when its stock falls below Min.
+	// Without it low stock is only suggested.
+	ListID mo.Option[id.ID[list.ProductList]] `json:"list_id" swaggertype:"string" extensions:"x-nullable"`
+}
+
+// Threshold is a minimal amount of a product which the user wants to keep at home
+type Threshold struct {
+	ThresholdOptions
+
+	OwnerID   id.ID[user.User]       `json:"owner_id" swaggertype:"string"`
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// Stock is a total amount of a product at home compared with its threshold
+type Stock struct {
+	Product product.Product `json:"product"`
+	// Quantity is a sum of items in the unit of the threshold
+	Quantity  product.Quantity `json:"quantity"`
+	Threshold Threshold        `json:"threshold"`
+	Low       bool             `json:"low"`
+}
+
+// Missing returns the amount which should be bought to reach the threshold
+func (s Stock) Missing() product.Quantity {
+	return product.Quantity{Value: max(s.Threshold.Min.Value-s.Quantity.Value, 0), Unit: s.Threshold.Min.Unit}
+}
+
+// GetStock sums items of the product of the threshold, items in units incompatible with the threshold are skipped
+func GetStock(items []Item, threshold Threshold) (Stock, error) {
+	stock := Stock{
+		Product:   product.Product{ID: threshold.ProductID}, //nolint:exhaustruct
+		Quantity:  product.Quantity{Value: 0, Unit: threshold.Min.Unit},
+		Threshold: threshold,
+		Low:       false,
+	}
+
+	for _, item := range items {
+		if item.Product.ID != threshold.ProductID {
+			continue
+		}
+
+		stock.Product = item.Product
+
+		if !item.Quantity.Compatible(stock.Quantity) {
+			continue
+		}
+
+		sum, err := stock.Quantity.Add(item.Quantity)
+		if err != nil {
+			return stock, fmt.Errorf("can't sum items of product %s: %w", threshold.ProductID, err)
+		}
+
+		stock.Quantity = sum
+	}
+
+	cmpMin, err := stock.Quantity.Compare(threshold.Min)
+	if err != nil {
+		return stock, fmt.Errorf("%w: can't compare stock with threshold: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	stock.Low = cmpMin < 0
+
+	return stock, nil
+}
+
+// SortByExpiry sorts items so the ones expiring first go first, items without expiry dates go last
+func SortByExpiry(items []Item) {
+	slices.SortFunc(items, func(a, b Item) int {
+		aAt, aFound := a.ExpiresAt.Get()
+		bAt, bFound := b.ExpiresAt.Get()
+
+		switch {
+		case aFound && bFound:
+			return cmp.Or(aAt.Compare(bAt), a.CreatedAt.Compare(b.CreatedAt))
+		case aFound:
+			return -1
+		case bFound:
+			return 1
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	})
+}
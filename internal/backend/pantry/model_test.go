@@ -0,0 +1,120 @@
+package pantry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/pantry"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+func TestItemsFromList(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	ownerID := id.NewID[user.User]()
+	milk, kefir, bread := id.NewID[product.Product](), id.NewID[product.Product](), id.NewID[product.Product]()
+
+	state := func(productID id.ID[product.Product], status list.StateStatus) list.ProductState {
+		return list.ProductState{
+			ProductStateOptions: list.ProductStateOptions{Status: status},
+			Product:             product.Product{ID: productID},
+		}
+	}
+
+	replaced := state(milk, list.StateStatusReplaced)
+	replaced.Replacement = mo.Some(list.ProductStateReplacement{
+		Quantity: mo.Some(product.Quantity{Value: 0.5, Unit: product.UnitL}),
+		Product:  product.Product{ID: kefir},
+	})
+
+	model := list.ProductList{
+		ID: id.NewID[list.ProductList](),
+		Members: []list.Member{
+			{MemberOptions: list.MemberOptions{UserID: id.NewID[user.User](), Role: list.MemberTypeEditor}},
+			{MemberOptions: list.MemberOptions{UserID: ownerID, Role: list.MemberTypeOwner}},
+		},
+		States: []list.ProductState{state(bread, list.StateStatusTaken), replaced, state(milk, list.StateStatusMissed)},
+	}
+
+	items := pantry.ItemsFromList(model, now)
+	require.Len(t, items, 2)
+
+	require.Equal(t, bread, items[0].Product.ID)
+	require.Equal(t, product.NewPieces(1), items[0].Quantity)
+	require.Equal(t, pantry.LocationCupboard, items[0].Location)
+	require.Equal(t, ownerID, items[0].OwnerID)
+	require.Equal(t, mo.Some(model.ID), items[0].ListID)
+
+	require.Equal(t, kefir, items[1].Product.ID)
+	require.Equal(t, product.Quantity{Value: 0.5, Unit: product.UnitL}, items[1].Quantity)
+}
+
+func TestConsume(t *testing.T) {
+	item := pantry.Item{ItemOptions: pantry.ItemOptions{Quantity: product.Quantity{Value: 1, Unit: product.UnitKg}}}
+
+	item, err := item.Consume(product.Quantity{Value: 300, Unit: product.UnitG})
+	require.NoError(t, err)
+	require.Equal(t, product.Quantity{Value: 0.7, Unit: product.UnitKg}, item.Quantity)
+	require.False(t, item.Empty())
+
+	_, err = item.Consume(product.NewPieces(1))
+	require.Error(t, err)
+
+	item, err = item.Consume(product.Quantity{Value: 1, Unit: product.UnitKg})
+	require.NoError(t, err)
+	require.True(t, item.Empty())
+}
+
+func TestGetStock(t *testing.T) {
+	milk := id.NewID[product.Product]()
+	threshold := pantry.Threshold{
+		ThresholdOptions: pantry.ThresholdOptions{Min: product.Quantity{Value: 2, Unit: product.UnitL}},
+		ProductID:        milk,
+	}
+
+	item := func(productID id.ID[product.Product], quantity product.Quantity) pantry.Item {
+		return pantry.Item{ItemOptions: pantry.ItemOptions{Quantity: quantity}, Product: product.Product{ID: productID}}
+	}
+
+	stock, err := pantry.GetStock([]pantry.Item{
+		item(milk, product.Quantity{Value: 1, Unit: product.UnitL}),
+		item(milk, product.Quantity{Value: 500, Unit: product.UnitMl}),
+		item(milk, product.NewPieces(3)),
+		item(id.NewID[product.Product](), product.Quantity{Value: 5, Unit: product.UnitL}),
+	}, threshold)
+	require.NoError(t, err)
+	require.Equal(t, product.Quantity{Value: 1.5, Unit: product.UnitL}, stock.Quantity)
+	require.True(t, stock.Low)
+	require.Equal(t, product.Quantity{Value: 0.5, Unit: product.UnitL}, stock.Missing())
+}
+
+func TestSortByExpiry(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	rice, yogurt, milk := id.NewID[product.Product](), id.NewID[product.Product](), id.NewID[product.Product]()
+	item := func(productID id.ID[product.Product], expiresAt mo.Option[time.Time]) pantry.Item {
+		return pantry.Item{
+			ItemOptions: pantry.ItemOptions{ExpiresAt: expiresAt},
+			Product:     product.Product{ID: productID},
+			CreatedAt:   now,
+		}
+	}
+
+	items := []pantry.Item{
+		item(rice, mo.None[time.Time]()),
+		item(yogurt, mo.Some(now.Add(48*time.Hour))),
+		item(milk, mo.Some(now.Add(24*time.Hour))),
+	}
+
+	pantry.SortByExpiry(items)
+
+	require.Equal(t, milk, items[0].Product.ID)
+	require.Equal(t, yogurt, items[1].Product.ID)
+	require.Equal(t, rice, items[2].Product.ID)
+	require.True(t, items[0].ExpiresBefore(now.Add(25*time.Hour)))
+	require.False(t, items[2].ExpiresBefore(now.Add(25*time.Hour)))
+}
@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/dedup"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/dbtx"
+)
+
+// MergeProducts moves pantry items of sources to the target, it joins the transaction of ctx.
+// Thresholds of the target or the first source are kept.
+func (r *Repo) MergeProducts(ctx context.Context, merge dedup.Merge) error {
+	m := productRepo.NewMerged(merge)
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		err := tx.WithContext(ctx).Model(new(PantryItem)).
+			Where("product_id IN ?", m.Sources).
+			Update("product_id", m.Target).Error
+		if err != nil {
+			return fmt.Errorf("can't move pantry items: %w", err)
+		}
+
+		thresholds, err := productRepo.LoadMerged[PantryThreshold](ctx, tx, m, "owner_id", "updated_at")
+		if err != nil {
+			return err
+		}
+
+		kept, _ := productRepo.CombineMerged(
+			thresholds,
+			m,
+			func(item *PantryThreshold) (string, *string) { return item.OwnerID, &item.ProductID },
+			func(kept, _ PantryThreshold) PantryThreshold { return kept },
+		)
+
+		return productRepo.ReplaceMerged(ctx, tx, m, "owner_id", kept, func(item PantryThreshold) string {
+			return item.OwnerID
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,405 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/list"
+	listRepo "go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/pantry"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+type PantryItem struct {
+	ID        string               `gorm:"primaryKey;size:36;notNull"`
+	OwnerID   string               `gorm:"size:36;notNull;index"`
+	ProductID string               `gorm:"size:36;notNull"`
+	Product   productRepo.Product  `gorm:"references:ID"`
+	Quantity  productRepo.Quantity `gorm:"embedded;embeddedPrefix:quantity_"`
+	Location  int32                `gorm:"notNull"`
+	ExpiresAt *time.Time
+	ListID    *string   `gorm:"size:36"`
+	CreatedAt time.Time `gorm:"notNull"`
+	UpdatedAt time.Time `gorm:"notNull"`
+}
+
+type PantryThreshold struct {
+	OwnerID   string               `gorm:"primaryKey;size:36;notNull"`
+	ProductID string               `gorm:"primaryKey;size:36;notNull"`
+	Min       productRepo.Quantity `gorm:"embedded;embeddedPrefix:min_"`
+	ListID    *string              `gorm:"size:36"`
+	UpdatedAt time.Time            `gorm:"notNull"`
+}
+
+// PantryImportedList marks archived list whose purchases are put to the pantry of its owner
+type PantryImportedList struct {
+	ListID     string    `gorm:"primaryKey;size:36;notNull"`
+	ImportedAt time.Time `gorm:"notNull"`
+}
+
+// skipBatch is a number of archived lists marked as imported by one insert
+const skipBatch = 1000
+
+type Repo struct {
+	db *gorm.DB
+}
+
+// NewRepo creates pantry tables, lists of the list repo must be migrated before it.
+// Lists archived before the tables are created are marked imported at now.
+func NewRepo(ctx context.Context, db *gorm.DB, now time.Time) (*Repo, error) {
+	imports := db.Migrator().HasTable(new(PantryImportedList))
+
+	err := db.WithContext(ctx).AutoMigrate(new(PantryItem), new(PantryThreshold), new(PantryImportedList))
+	if err != nil {
+		return nil, fmt.Errorf("can't create pantry tables: %w", err)
+	}
+
+	if !imports {
+		if err = skipArchivedLists(ctx, db, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Repo{db: db}, nil
+}
+
+// skipArchivedLists marks lists archived before pantries appeared as imported,
+// so food bought long ago isn't put to pantries
+func skipArchivedLists(ctx context.Context, db *gorm.DB, now time.Time) error {
+	var rawIDs []string
+
+	err := db.WithContext(ctx).Unscoped().Model(new(listRepo.ProductList)).
+		Where("status = ?", int32(list.ExecStatusArchived)).
+		Pluck("id", &rawIDs).Error
+	if err != nil {
+		return fmt.Errorf("can't select archived lists: %w", err)
+	}
+
+	if len(rawIDs) == 0 {
+		return nil
+	}
+
+	entities := lo.Map(rawIDs, func(item string, _ int) PantryImportedList {
+		return PantryImportedList{ListID: item, ImportedAt: now.UTC()}
+	})
+
+	if err = db.WithContext(ctx).CreateInBatches(&entities, skipBatch).Error; err != nil {
+		return fmt.Errorf("can't skip archived lists: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) Create(ctx context.Context, model pantry.Item) error {
+	err := r.db.WithContext(ctx).Omit(clause.Associations).Create(lo.ToPtr(itemToEntity(model))).Error
+	if err != nil {
+		return fmt.Errorf("%w: can't insert pantry item %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+// GetByOwnerID returns items of the user, the oldest go first
+func (r *Repo) GetByOwnerID(ctx context.Context, ownerID id.ID[user.User]) ([]pantry.Item, error) {
+	var entities []PantryItem
+
+	err := r.db.WithContext(ctx).
+		Preload("Product.Category").
+		Preload("Product.Forms").
+		Where("owner_id = ?", ownerID.String()).
+		Order("created_at").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select pantry items of user %s: %w", ownerID, err)
+	}
+
+	return lo.Map(entities, func(item PantryItem, _ int) pantry.Item { return itemToModel(item) }), nil
+}
+
+// GetAndUpdate replaces item by result of updateFunc, the item is deleted when the result is empty
+func (r *Repo) GetAndUpdate(
+	ctx context.Context,
+	itemID id.ID[pantry.Item],
+	updateFunc func(pantry.Item) (pantry.Item, error),
+) (
+	pantry.Item,
+	error,
+) {
+	var model pantry.Item
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entity, err := r.get(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(itemToModel(entity)); err != nil {
+			return err
+		}
+
+		if model.Empty() {
+			if err = tx.WithContext(ctx).Delete(&PantryItem{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+				return fmt.Errorf("can't delete pantry item %s: %w", itemID, err)
+			}
+
+			return nil
+		}
+
+		if err = tx.WithContext(ctx).Omit(clause.Associations).Save(lo.ToPtr(itemToEntity(model))).Error; err != nil {
+			return fmt.Errorf("can't update pantry item %s: %w", itemID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return pantry.Item{}, fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return model, nil
+}
+
+// Delete deletes item if checkFunc allows it
+func (r *Repo) Delete(ctx context.Context, itemID id.ID[pantry.Item], checkFunc func(pantry.Item) error) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		entity, err := r.get(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkFunc(itemToModel(entity)); err != nil {
+			return err
+		}
+
+		if err = tx.WithContext(ctx).Delete(&PantryItem{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+			return fmt.Errorf("can't delete pantry item %s: %w", itemID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+// SaveThreshold creates or replaces threshold of the product
+func (r *Repo) SaveThreshold(ctx context.Context, model pantry.Threshold) error {
+	if err := r.db.WithContext(ctx).Save(lo.ToPtr(thresholdToEntity(model))).Error; err != nil {
+		return fmt.Errorf("can't save threshold of product %s: %w", model.ProductID, err)
+	}
+
+	return nil
+}
+
+// GetThresholds returns thresholds of the user
+func (r *Repo) GetThresholds(ctx context.Context, ownerID id.ID[user.User]) ([]pantry.Threshold, error) {
+	var entities []PantryThreshold
+
+	err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID.String()).Order("updated_at").Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select thresholds of user %s: %w", ownerID, err)
+	}
+
+	return lo.Map(entities, func(item PantryThreshold, _ int) pantry.Threshold { return thresholdToModel(item) }), nil
+}
+
+// GetThreshold returns threshold of the product set by the user
+func (r *Repo) GetThreshold(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	productID id.ID[product.Product],
+) (
+	pantry.Threshold,
+	error,
+) {
+	var entity PantryThreshold
+
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? AND product_id = ?", ownerID.String(), productID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return pantry.Threshold{}, fmt.Errorf("%w: threshold of product %s", myerr.ErrNotFound, productID)
+	} else if err != nil {
+		return pantry.Threshold{}, fmt.Errorf("can't select threshold of product %s: %w", productID, err)
+	}
+
+	return thresholdToModel(entity), nil
+}
+
+func (r *Repo) DeleteThreshold(ctx context.Context, ownerID id.ID[user.User], productID id.ID[product.Product]) error {
+	result := r.db.WithContext(ctx).
+		Where("owner_id = ? AND product_id = ?", ownerID.String(), productID.String()).
+		Delete(new(PantryThreshold))
+	if result.Error != nil {
+		return fmt.Errorf("can't delete threshold of product %s: %w", productID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: threshold of product %s", myerr.ErrNotFound, productID)
+	}
+
+	return nil
+}
+
+// GetUnimportedLists returns archived lists whose purchases aren't put to pantries yet, the oldest go first.
+// Lists in the trash are skipped until they're restored.
+func (r *Repo) GetUnimportedLists(ctx context.Context, limit int) ([]id.ID[list.ProductList], error) {
+	var rawIDs []string
+
+	imported := r.db.Model(new(PantryImportedList)).Select("list_id")
+
+	err := r.db.WithContext(ctx).Model(new(listRepo.ProductList)).
+		Where("status = ? AND id NOT IN (?)", int32(list.ExecStatusArchived), imported).
+		Order("updated_at").
+		Limit(limit).
+		Pluck("id", &rawIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select unimported lists: %w", err)
+	}
+
+	return lo.Map(rawIDs, func(item string, _ int) id.ID[list.ProductList] {
+		return id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(item))}
+	}), nil
+}
+
+// ImportList marks the archived list as imported and saves items bought in it.
+// Each list is imported once, so when several replicas import it at once others get ErrConflict.
+func (r *Repo) ImportList(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	now time.Time,
+	items []pantry.Item,
+) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&PantryImportedList{ListID: listID.String(), ImportedAt: now.UTC()})
+		if result.Error != nil {
+			return fmt.Errorf("can't mark list %s as imported: %w", listID, result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: list %s is already imported", myerr.ErrConflict, listID)
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		entities := lo.Map(items, func(item pantry.Item, _ int) PantryItem { return itemToEntity(item) })
+
+		if err := tx.WithContext(ctx).Omit(clause.Associations).Create(&entities).Error; err != nil {
+			return fmt.Errorf("can't insert pantry items of list %s: %w", listID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) get(ctx context.Context, tx *gorm.DB, itemID id.ID[pantry.Item]) (PantryItem, error) {
+	var entity PantryItem
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Product.Category").
+		Preload("Product.Forms").
+		Where("id = ?", itemID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: pantry item %s", myerr.ErrNotFound, itemID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select pantry item %s: %w", itemID, err)
+	}
+
+	return entity, nil
+}
+
+func itemToModel(entity PantryItem) pantry.Item {
+	return pantry.Item{
+		ItemOptions: pantry.ItemOptions{
+			Quantity: productRepo.QuantityToModel(entity.Quantity, nil).OrEmpty(),
+			Location: pantry.Location(entity.Location),
+			ExpiresAt: lo.If(entity.ExpiresAt == nil, mo.None[time.Time]()).
+				ElseF(func() mo.Option[time.Time] { return mo.Some(*entity.ExpiresAt) }),
+		},
+		ID:        id.ID[pantry.Item]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		OwnerID:   id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		Product:   productRepo.EntityToModel(entity.Product),
+		ListID:    listIDToModel(entity.ListID),
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func itemToEntity(model pantry.Item) PantryItem {
+	return PantryItem{
+		ID:        model.ID.String(),
+		OwnerID:   model.OwnerID.String(),
+		ProductID: model.Product.ID.String(),
+		Product:   productRepo.Product{ID: model.Product.ID.String()}, //nolint:exhaustruct
+		Quantity:  productRepo.QuantityToEntity(mo.Some(model.Quantity)),
+		Location:  int32(model.Location),
+		ExpiresAt: lo.If(model.ExpiresAt.IsAbsent(), (*time.Time)(nil)).
+			ElseF(func() *time.Time { return lo.ToPtr(model.ExpiresAt.MustGet().UTC()) }),
+		ListID:    listIDToEntity(model.ListID),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func thresholdToModel(entity PantryThreshold) pantry.Threshold {
+	return pantry.Threshold{
+		ThresholdOptions: pantry.ThresholdOptions{
+			Min:    productRepo.QuantityToModel(entity.Min, nil).OrEmpty(),
+			ListID: listIDToModel(entity.ListID),
+		},
+		OwnerID:   id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		ProductID: id.ID[product.Product]{UUID: god.Believe(uuid.Parse(entity.ProductID))},
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func thresholdToEntity(model pantry.Threshold) PantryThreshold {
+	return PantryThreshold{
+		OwnerID:   model.OwnerID.String(),
+		ProductID: model.ProductID.String(),
+		Min:       productRepo.QuantityToEntity(mo.Some(model.Min)),
+		ListID:    listIDToEntity(model.ListID),
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func listIDToModel(rawID *string) mo.Option[id.ID[list.ProductList]] {
+	if rawID == nil {
+		return mo.None[id.ID[list.ProductList]]()
+	}
+
+	return mo.Some(id.ID[list.ProductList]{UUID: god.Believe(uuid.Parse(*rawID))})
+}
+
+func listIDToEntity(model mo.Option[id.ID[list.ProductList]]) *string {
+	listID, found := model.Get()
+	if !found {
+		return nil
+	}
+
+	return lo.ToPtr(listID.String())
+}
@@ -0,0 +1,529 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/pantry"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const (
+	// importInterval is how often purchases of archived lists are put to pantries
+	importInterval = 10 * time.Minute
+	// importBatch is a number of archived lists selected at once
+	importBatch = 100
+	// maxExpiringDays is the longest period in which expiring items are looked for
+	maxExpiringDays = 365
+)
+
+type repo interface {
+	Create(context.Context, pantry.Item) error
+	GetByOwnerID(context.Context, id.ID[user.User]) ([]pantry.Item, error)
+	GetAndUpdate(context.Context, id.ID[pantry.Item], func(pantry.Item) (pantry.Item, error)) (pantry.Item, error)
+	Delete(context.Context, id.ID[pantry.Item], func(pantry.Item) error) error
+	SaveThreshold(context.Context, pantry.Threshold) error
+	GetThresholds(context.Context, id.ID[user.User]) ([]pantry.Threshold, error)
+	GetThreshold(context.Context, id.ID[user.User], id.ID[product.Product]) (pantry.Threshold, error)
+	DeleteThreshold(context.Context, id.ID[user.User], id.ID[product.Product]) error
+	GetUnimportedLists(context.Context, int) ([]id.ID[list.ProductList], error)
+	ImportList(context.Context, id.ID[list.ProductList], time.Time, []pantry.Item) error
+}
+
+// archive gives archived lists whose purchases go to pantries
+type archive interface {
+	GetByListID(context.Context, id.ID[list.ProductList]) (list.ProductList, error)
+}
+
+// planner adds products with low stock to planning lists
+type planner interface {
+	GetByID(context.Context, id.ID[list.ProductList], id.ID[user.User]) (list.ProductList, error)
+	AppendProducts(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[user.User],
+		map[id.ID[product.Product]]list.ProductStateOptions,
+	) (
+		list.ProductList,
+		error,
+	)
+}
+
+type products interface {
+	IDList(context.Context, []id.ID[product.Product]) ([]product.Product, error)
+}
+
+type Service struct {
+	repo     repo
+	archive  archive
+	planner  planner
+	products products
+	clock    clock.Clock
+	log      zerolog.Logger
+}
+
+func NewService(
+	repo repo,
+	archive archive,
+	planner planner,
+	products products,
+	clock clock.Clock,
+	log zerolog.Logger,
+) *Service {
+	return &Service{
+		repo:     repo,
+		archive:  archive,
+		planner:  planner,
+		products: products,
+		clock:    clock,
+		log:      log.With().Str("component", "pantry service").Logger(),
+	}
+}
+
+// AddItem puts the product to the pantry of the user
+func (s *Service) AddItem(ctx context.Context, ownerID id.ID[user.User], options pantry.NewItem) (pantry.Item, error) {
+	if err := validateItem(options.ItemOptions); err != nil {
+		return pantry.Item{}, err
+	}
+
+	found, err := s.getProduct(ctx, options.ProductID)
+	if err != nil {
+		return pantry.Item{}, err
+	}
+
+	now := s.clock.Now()
+	model := pantry.Item{
+		ItemOptions: options.ItemOptions,
+		ID:          id.NewID[pantry.Item](),
+		OwnerID:     ownerID,
+		Product:     found,
+		ListID:      mo.None[id.ID[list.ProductList]](),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err = s.repo.Create(ctx, model); err != nil {
+		return pantry.Item{}, fmt.Errorf("can't add pantry item: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetItems returns items of the user, the ones expiring first go first
+func (s *Service) GetItems(ctx context.Context, ownerID id.ID[user.User]) ([]pantry.Item, error) {
+	items, err := s.repo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get pantry of user %s: %w", ownerID, err)
+	}
+
+	pantry.SortByExpiry(items)
+
+	return items, nil
+}
+
+// GetExpiring returns items of the user which expire in the given number of days or are already expired
+func (s *Service) GetExpiring(ctx context.Context, ownerID id.ID[user.User], days int) ([]pantry.Item, error) {
+	if days < 0 || days > maxExpiringDays {
+		return nil, fmt.Errorf("%w: days must be between 0 and %d", myerr.ErrInvalidArgument, maxExpiringDays)
+	}
+
+	items, err := s.GetItems(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := s.clock.Now().AddDate(0, 0, days)
+
+	return lo.Filter(items, func(item pantry.Item, _ int) bool { return item.ExpiresBefore(until) }), nil
+}
+
+// UpdateItem replaces quantity, location and expiry date of the item
+func (s *Service) UpdateItem(
+	ctx context.Context,
+	itemID id.ID[pantry.Item],
+	ownerID id.ID[user.User],
+	options pantry.ItemOptions,
+) (
+	pantry.Item,
+	error,
+) {
+	if err := validateItem(options); err != nil {
+		return pantry.Item{}, err
+	}
+
+	model, err := s.repo.GetAndUpdate(ctx, itemID, func(model pantry.Item) (pantry.Item, error) {
+		if err := checkOwner(model, ownerID); err != nil {
+			return model, err
+		}
+
+		model.ItemOptions = options
+		model.UpdatedAt = s.clock.Now()
+
+		return model, nil
+	})
+	if err != nil {
+		return pantry.Item{}, fmt.Errorf("can't update pantry item %s: %w", itemID, err)
+	}
+
+	s.restock(ctx, ownerID, model.Product.ID)
+
+	return model, nil
+}
+
+// Consume takes the consumed quantity out of the item, the item is deleted when nothing is left.
+// A product whose stock falls below its threshold is added to the list of the threshold.
+func (s *Service) Consume(
+	ctx context.Context,
+	itemID id.ID[pantry.Item],
+	ownerID id.ID[user.User],
+	consumption pantry.Consumption,
+) (
+	pantry.Item,
+	error,
+) {
+	model, err := s.repo.GetAndUpdate(ctx, itemID, func(model pantry.Item) (pantry.Item, error) {
+		if err := checkOwner(model, ownerID); err != nil {
+			return model, err
+		}
+
+		model, err := model.Consume(consumption.Quantity)
+		if err != nil {
+			return model, err
+		}
+
+		model.UpdatedAt = s.clock.Now()
+
+		return model, nil
+	})
+	if err != nil {
+		return pantry.Item{}, fmt.Errorf("can't consume pantry item %s: %w", itemID, err)
+	}
+
+	s.restock(ctx, ownerID, model.Product.ID)
+
+	return model, nil
+}
+
+// DeleteItem takes the item out of the pantry without recording consumption, e.g. when it's thrown away
+func (s *Service) DeleteItem(ctx context.Context, itemID id.ID[pantry.Item], ownerID id.ID[user.User]) error {
+	var productID id.ID[product.Product]
+
+	err := s.repo.Delete(ctx, itemID, func(model pantry.Item) error {
+		productID = model.Product.ID
+		return checkOwner(model, ownerID)
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete pantry item %s: %w", itemID, err)
+	}
+
+	s.restock(ctx, ownerID, productID)
+
+	return nil
+}
+
+// SetThreshold sets minimal amount of the product which the user wants to keep at home.
+// The list of the threshold must be a planning list which the user can edit.
+func (s *Service) SetThreshold(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	productID id.ID[product.Product],
+	options pantry.ThresholdOptions,
+) (
+	pantry.Threshold,
+	error,
+) {
+	if err := options.Min.Validate(); err != nil {
+		return pantry.Threshold{}, err
+	}
+
+	if _, err := s.getProduct(ctx, productID); err != nil {
+		return pantry.Threshold{}, err
+	}
+
+	if listID, found := options.ListID.Get(); found {
+		if _, err := s.getPlanningList(ctx, listID, ownerID); err != nil {
+			return pantry.Threshold{}, err
+		}
+	}
+
+	model := pantry.Threshold{
+		ThresholdOptions: options,
+		OwnerID:          ownerID,
+		ProductID:        productID,
+		UpdatedAt:        s.clock.Now(),
+	}
+
+	if err := s.repo.SaveThreshold(ctx, model); err != nil {
+		return pantry.Threshold{}, fmt.Errorf("can't set threshold: %w", err)
+	}
+
+	s.restock(ctx, ownerID, productID)
+
+	return model, nil
+}
+
+func (s *Service) GetThresholds(ctx context.Context, ownerID id.ID[user.User]) ([]pantry.Threshold, error) {
+	thresholds, err := s.repo.GetThresholds(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get thresholds of user %s: %w", ownerID, err)
+	}
+
+	return thresholds, nil
+}
+
+func (s *Service) DeleteThreshold(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	productID id.ID[product.Product],
+) error {
+	if err := s.repo.DeleteThreshold(ctx, ownerID, productID); err != nil {
+		return fmt.Errorf("can't delete threshold: %w", err)
+	}
+
+	return nil
+}
+
+// GetLowStock returns products of the user whose stock is below their thresholds, they're suggested for buying
+func (s *Service) GetLowStock(ctx context.Context, ownerID id.ID[user.User]) ([]pantry.Stock, error) {
+	thresholds, err := s.GetThresholds(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetByOwnerID(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get pantry of user %s: %w", ownerID, err)
+	}
+
+	low := make([]pantry.Stock, 0)
+
+	for _, threshold := range thresholds {
+		stock, err := pantry.GetStock(items, threshold)
+		if err != nil {
+			return nil, err
+		}
+
+		if stock.Low {
+			low = append(low, stock)
+		}
+	}
+
+	products, err := s.products.IDList(ctx, lo.Map(low, func(item pantry.Stock, _ int) id.ID[product.Product] {
+		return item.Product.ID
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("can't get products with low stock: %w", err)
+	}
+
+	for i, stock := range low {
+		idx := slices.IndexFunc(products, func(p product.Product) bool { return p.ID == stock.Product.ID })
+		if idx != -1 {
+			low[i].Product = products[idx]
+		}
+	}
+
+	return low, nil
+}
+
+// RunImports puts purchases of archived lists to pantries until ctx is done.
+// It's safe to run it on every replica, each list is imported once.
+func (s *Service) RunImports(ctx context.Context) {
+	ticker := time.NewTicker(importInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.UpdateImports(ctx); err != nil {
+			s.log.Error().Err(err).Msg("can't import archived lists")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdateImports puts products taken in archived lists to pantries of list owners and returns number of imported lists.
+// A list which can't be imported is logged and skipped till the next run, so it doesn't hold back the others.
+func (s *Service) UpdateImports(ctx context.Context) (int, error) {
+	imported := 0
+	failed := make(map[id.ID[list.ProductList]]struct{})
+
+	for {
+		// failed lists stay unimported, so they're selected again besides a full batch of others
+		limit := importBatch + len(failed)
+
+		listIDs, err := s.repo.GetUnimportedLists(ctx, limit)
+		if err != nil {
+			return imported, fmt.Errorf("can't get unimported lists: %w", err)
+		}
+
+		for _, listID := range listIDs {
+			if _, found := failed[listID]; found {
+				continue
+			}
+
+			err = s.importList(ctx, listID)
+			if errors.Is(err, myerr.ErrConflict) {
+				continue
+			} else if err != nil {
+				s.log.Error().Err(err).Stringer("list_id", listID).Msg("can't import list")
+				failed[listID] = struct{}{}
+
+				continue
+			}
+
+			imported++
+		}
+
+		if len(listIDs) < limit {
+			return imported, nil
+		}
+	}
+}
+
+// importList puts products taken in the archived list to the pantry of its owner
+func (s *Service) importList(ctx context.Context, listID id.ID[list.ProductList]) error {
+	model, err := s.archive.GetByListID(ctx, listID)
+	if err != nil {
+		return fmt.Errorf("can't get archived list %s: %w", listID, err)
+	}
+
+	now := s.clock.Now()
+
+	if err = s.repo.ImportList(ctx, listID, now, pantry.ItemsFromList(model, now)); err != nil {
+		return fmt.Errorf("can't import list %s: %w", listID, err)
+	}
+
+	return nil
+}
+
+// restock adds the product to the list of its threshold when its stock is low.
+// It's best effort, failures are logged as the pantry itself is already updated.
+func (s *Service) restock(ctx context.Context, ownerID id.ID[user.User], productID id.ID[product.Product]) {
+	threshold, err := s.repo.GetThreshold(ctx, ownerID, productID)
+	if errors.Is(err, myerr.ErrNotFound) {
+		return
+	} else if err != nil {
+		s.log.Error().Err(err).Stringer("product_id", productID).Msg("can't get threshold")
+		return
+	}
+
+	listID, found := threshold.ListID.Get()
+	if !found {
+		return
+	}
+
+	if err = s.addToList(ctx, threshold, listID); err != nil {
+		s.log.Error().Err(err).Stringer("product_id", productID).Stringer("list_id", listID).Msg("can't restock")
+	}
+}
+
+// addToList appends the missing amount of the product to the list, unless it's already planned there
+func (s *Service) addToList(ctx context.Context, threshold pantry.Threshold, listID id.ID[list.ProductList]) error {
+	items, err := s.repo.GetByOwnerID(ctx, threshold.OwnerID)
+	if err != nil {
+		return fmt.Errorf("can't get pantry of user %s: %w", threshold.OwnerID, err)
+	}
+
+	stock, err := pantry.GetStock(items, threshold)
+	if err != nil || !stock.Low {
+		return err
+	}
+
+	model, err := s.getPlanningList(ctx, listID, threshold.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(model.States, func(st list.ProductState) bool { return st.Product.ID == threshold.ProductID }) {
+		return nil
+	}
+
+	_, err = s.planner.AppendProducts(ctx, listID, threshold.OwnerID, map[id.ID[product.Product]]list.ProductStateOptions{
+		threshold.ProductID: {
+			Quantity:    mo.Some(stock.Missing()),
+			FormIndex:   mo.None[int32](),
+			Status:      list.StateStatusWaiting,
+			Replacement: mo.None[list.ProductStateReplacement](),
+			Note:        "",
+			Price:       mo.None[list.Price](),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("can't append product %s: %w", threshold.ProductID, err)
+	}
+
+	return nil
+}
+
+// getPlanningList returns the list if it's being planned and the user can edit it
+func (s *Service) getPlanningList(
+	ctx context.Context,
+	listID id.ID[list.ProductList],
+	userID id.ID[user.User],
+) (
+	list.ProductList,
+	error,
+) {
+	model, err := s.planner.GetByID(ctx, listID, userID)
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't get list %s: %w", listID, err)
+	}
+
+	if _, err = model.CheckRole(userID, list.MemberTypeEditor); err != nil {
+		return list.ProductList{}, fmt.Errorf("checking role failed: %w", err)
+	}
+
+	if model.Status != list.ExecStatusPlanning {
+		return list.ProductList{}, fmt.Errorf("%w: list %s is not being planned", myerr.ErrInvalidArgument, listID)
+	}
+
+	return model, nil
+}
+
+func (s *Service) getProduct(ctx context.Context, productID id.ID[product.Product]) (product.Product, error) {
+	found, err := s.products.IDList(ctx, []id.ID[product.Product]{productID})
+	if err != nil {
+		return product.Product{}, fmt.Errorf("can't get product %s: %w", productID, err)
+	}
+
+	if len(found) == 0 {
+		return product.Product{}, fmt.Errorf("%w: product %s", myerr.ErrNotFound, productID)
+	}
+
+	return found[0], nil
+}
+
+func checkOwner(model pantry.Item, userID id.ID[user.User]) error {
+	if model.OwnerID != userID {
+		return fmt.Errorf("%w: pantry item %s belongs to another user", myerr.ErrForbidden, model.ID)
+	}
+
+	return nil
+}
+
+func validateItem(options pantry.ItemOptions) error {
+	if err := options.Quantity.Validate(); err != nil {
+		return err
+	}
+
+	if !options.Location.IsValid() {
+		return fmt.Errorf("%w: unknown location %d", myerr.ErrInvalidArgument, options.Location)
+	}
+
+	return nil
+}
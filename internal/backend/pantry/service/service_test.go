@@ -0,0 +1,298 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/list"
+	listRepo "go-backend/internal/backend/list/repo"
+	"go-backend/internal/backend/pantry"
+	"go-backend/internal/backend/pantry/repo"
+	"go-backend/internal/backend/pantry/service"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// planner keeps planning lists in memory and records appended products
+type planner struct {
+	lists    map[id.ID[list.ProductList]]list.ProductList
+	appended map[id.ID[product.Product]]list.ProductStateOptions
+}
+
+func (p *planner) GetByID(
+	_ context.Context,
+	listID id.ID[list.ProductList],
+	_ id.ID[user.User],
+) (
+	list.ProductList,
+	error,
+) {
+	model, found := p.lists[listID]
+	if !found {
+		return list.ProductList{}, fmt.Errorf("%w: list %s", myerr.ErrNotFound, listID)
+	}
+
+	return model, nil
+}
+
+func (p *planner) AppendProducts(
+	_ context.Context,
+	listID id.ID[list.ProductList],
+	_ id.ID[user.User],
+	states map[id.ID[product.Product]]list.ProductStateOptions,
+) (
+	list.ProductList,
+	error,
+) {
+	for productID, state := range states {
+		p.appended[productID] = state
+	}
+
+	return p.lists[listID], nil
+}
+
+// fixture is a pantry service over sqlite, lists of the list repo are the archive
+type fixture struct {
+	db      *gorm.DB
+	service *service.Service
+	lists   *listRepo.Repo
+	catalog *productService.Service
+	planner *planner
+	clock   *clock.Fake
+	ownerID id.ID[user.User]
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "pantry.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	lists, err := listRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	ownerID := id.NewID[user.User]()
+	require.NoError(t, db.Create(&userRepo.User{
+		ID:    ownerID.String(),
+		Login: "owner",
+		Hash:  "",
+		Role:  int32(user.RoleUser),
+	}).Error)
+
+	return fixture{
+		db:      db,
+		lists:   lists,
+		catalog: productService.NewService(products),
+		planner: &planner{
+			lists:    map[id.ID[list.ProductList]]list.ProductList{},
+			appended: map[id.ID[product.Product]]list.ProductStateOptions{},
+		},
+		clock:   clock.NewFake(time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)),
+		ownerID: ownerID,
+	}
+}
+
+// start creates pantry repo and service, lists archived before it are never imported
+func (f *fixture) start(t *testing.T) {
+	t.Helper()
+
+	pantries, err := repo.NewRepo(context.Background(), f.db, f.clock.Now())
+	require.NoError(t, err)
+
+	f.service = service.NewService(pantries, f.lists, f.planner, f.catalog, f.clock, zerolog.Nop())
+}
+
+func (f fixture) addProduct(t *testing.T, name product.Name) product.Product {
+	t.Helper()
+
+	model, err := f.catalog.Create(context.Background(), product.Options{
+		Name:     name,
+		Category: mo.None[product.Category](),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: []product.Barcode{},
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+// newList returns list of the owner with the products in the given status
+func (f fixture) newList(
+	status list.ExecStatus,
+	stateStatus list.StateStatus,
+	products ...product.Product,
+) list.ProductList {
+	model := list.ProductList{
+		ListOptions: list.ListOptions{Status: status, Title: "weekly"},
+		States:      []list.ProductState{},
+		Members: []list.Member{{
+			MemberOptions: list.MemberOptions{UserID: f.ownerID, Role: list.MemberTypeOwner},
+			UserName:      "",
+			CreatedAt:     date.NewCreateDate[list.Member](),
+			UpdatedAt:     date.NewUpdateDate[list.Member](),
+		}},
+		ID:        id.NewID[list.ProductList](),
+		UpdatedAt: date.NewUpdateDate[list.ProductList](),
+		CreatedAt: date.NewCreateDate[list.ProductList](),
+	}
+
+	for _, p := range products {
+		model.States = append(model.States, list.ProductState{
+			ProductStateOptions: list.ProductStateOptions{
+				Quantity: mo.Some(product.NewPieces(2)),
+				Status:   stateStatus,
+			},
+			Product:   p,
+			CreatedAt: date.NewCreateDate[list.ProductState](),
+			UpdatedAt: date.NewUpdateDate[list.ProductState](),
+		})
+	}
+
+	return model
+}
+
+func (f fixture) archive(t *testing.T, products ...product.Product) list.ProductList {
+	t.Helper()
+
+	model := f.newList(list.ExecStatusArchived, list.StateStatusTaken, products...)
+	require.NoError(t, f.lists.CreateList(context.Background(), model, list.ChangeMeta{
+		Author: f.ownerID,
+		Type:   list.EventTypeFull,
+		At:     time.Now(),
+	}))
+
+	return model
+}
+
+func TestUpdateImports(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	milk, bread := f.addProduct(t, "milk"), f.addProduct(t, "bread")
+
+	skipped := f.archive(t, bread)
+	f.start(t)
+
+	var marked repo.PantryImportedList
+	require.NoError(t, f.db.First(&marked, "list_id = ?", skipped.ID.String()).Error)
+	require.True(t, f.clock.Now().Equal(marked.ImportedAt), "skipped lists are marked at time of the start")
+
+	archived := f.archive(t, milk)
+
+	imported, err := f.service.UpdateImports(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported, "lists archived before pantries appeared are skipped")
+
+	imported, err = f.service.UpdateImports(ctx)
+	require.NoError(t, err)
+	require.Zero(t, imported, "lists are imported once")
+
+	items, err := f.service.GetItems(ctx, f.ownerID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, milk.ID, items[0].Product.ID)
+	require.Equal(t, product.NewPieces(2), items[0].Quantity)
+	require.Equal(t, mo.Some(archived.ID), items[0].ListID)
+
+	// the pantry repo may be created again on restart, imports go on from where they stopped
+	f.start(t)
+	f.archive(t, bread)
+
+	imported, err = f.service.UpdateImports(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported)
+}
+
+func TestConsumeRestocks(t *testing.T) {
+	f := newFixture(t)
+	f.start(t)
+	ctx := context.Background()
+	milk := f.addProduct(t, "milk")
+
+	planning := f.newList(list.ExecStatusPlanning, list.StateStatusWaiting)
+	f.planner.lists[planning.ID] = planning
+
+	item, err := f.service.AddItem(ctx, f.ownerID, pantry.NewItem{
+		ItemOptions: pantry.ItemOptions{
+			Quantity:  product.NewPieces(3),
+			Location:  pantry.LocationFridge,
+			ExpiresAt: mo.None[time.Time](),
+		},
+		ProductID: milk.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = f.service.SetThreshold(ctx, f.ownerID, milk.ID, pantry.ThresholdOptions{
+		Min:    product.NewPieces(2),
+		ListID: mo.Some(planning.ID),
+	})
+	require.NoError(t, err)
+	require.Empty(t, f.planner.appended, "stock isn't low yet")
+
+	_, err = f.service.Consume(ctx, item.ID, id.NewID[user.User](), pantry.Consumption{Quantity: product.NewPieces(1)})
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	item, err = f.service.Consume(ctx, item.ID, f.ownerID, pantry.Consumption{Quantity: product.NewPieces(2)})
+	require.NoError(t, err)
+	require.Equal(t, product.NewPieces(1), item.Quantity)
+
+	require.Equal(t, mo.Some(product.NewPieces(1)), f.planner.appended[milk.ID].Quantity)
+
+	low, err := f.service.GetLowStock(ctx, f.ownerID)
+	require.NoError(t, err)
+	require.Len(t, low, 1)
+	require.Equal(t, milk.Name, low[0].Product.Name)
+
+	// the item is deleted when it's eaten up
+	_, err = f.service.Consume(ctx, item.ID, f.ownerID, pantry.Consumption{Quantity: product.NewPieces(1)})
+	require.NoError(t, err)
+
+	items, err := f.service.GetItems(ctx, f.ownerID)
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestSetThresholdNeedsPlanningList(t *testing.T) {
+	f := newFixture(t)
+	f.start(t)
+	ctx := context.Background()
+	milk := f.addProduct(t, "milk")
+
+	archived := f.newList(list.ExecStatusArchived, list.StateStatusTaken)
+	f.planner.lists[archived.ID] = archived
+
+	_, err := f.service.SetThreshold(ctx, f.ownerID, milk.ID, pantry.ThresholdOptions{
+		Min:    product.NewPieces(2),
+		ListID: mo.Some(archived.ID),
+	})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	_, err = f.service.SetThreshold(ctx, f.ownerID, id.NewID[product.Product](), pantry.ThresholdOptions{
+		Min:    product.NewPieces(2),
+		ListID: mo.None[id.ID[list.ProductList]](),
+	})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,7 +11,9 @@ import (
 
 	"go-backend/internal/backend/product"
 	"go-backend/internal/backend/product/service"
+	"go-backend/pkg/api/rest/rerr"
 	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
 )
 
 type ProductHandler struct {
@@ -26,6 +29,22 @@ func RegisterREST(r *gin.RouterGroup, service *service.Service) {
 	group.PUT("/id/:id", h.Update)
 	group.POST("", h.Create)
 	group.GET("/list/:ids", h.GetList)
+	group.GET("/search", h.Search)
+	group.GET("/barcode/:code", h.GetByBarcode)
+}
+
+// errorStatus returns status of response to a request failed with the error
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, myerr.ErrInvalidArgument):
+		return http.StatusBadRequest
+	case errors.Is(err, myerr.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, myerr.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 // @Summary	Creates new product
@@ -44,7 +63,10 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 	created, err := h.service.Create(c, model)
-	if err != nil {
+	if status := errorStatus(err); status != http.StatusInternalServerError {
+		c.String(status, err.Error())
+		return
+	} else if err != nil {
 		c.String(http.StatusInternalServerError, "Internal error")
 		return
 	}
@@ -73,7 +95,10 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 	updated, err := h.service.Update(c, id.ID[product.Product]{UUID: productID}, model)
-	if err != nil {
+	if status := errorStatus(err); status != http.StatusInternalServerError {
+		c.String(status, err.Error())
+		return
+	} else if err != nil {
 		log.Err(err).Msg("updating product")
 		c.String(http.StatusInternalServerError, "internal error")
 		return
@@ -137,3 +162,57 @@ func (h *ProductHandler) GetList(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models)
 }
+
+// @Summary	Search products by names, aliases and categories, typos and transliteration are tolerated
+// @ID			product-search
+//
+// @Tags		Product
+// @Param		q		query	string	true	"query"
+// @Param		offset	query	int		false	"number of skipped products"
+// @Param		limit	query	int		false	"page size, 20 by default and 100 at most"
+// @Produce	json
+// @Router		/product/search [get]
+// @Security ApiKeyAuth
+func (h *ProductHandler) Search(c *gin.Context) {
+	offset, ok := rerr.QueryInt(c, "offset", 0)
+	if !ok {
+		return
+	}
+
+	limit, ok := rerr.QueryInt(c, "limit", 0)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.Search(c.Query("q"), offset, limit)
+	if status := errorStatus(err); status != http.StatusInternalServerError {
+		c.String(status, err.Error())
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary	Get product by EAN-13, UPC-A or EAN-8 barcode
+// @ID			product-get-by-barcode
+//
+// @Tags		Product
+// @Param		code	path	string	true	"barcode"
+// @Produce	json
+// @Router		/product/barcode/{code} [get]
+// @Security ApiKeyAuth
+func (h *ProductHandler) GetByBarcode(c *gin.Context) {
+	model, err := h.service.Barcode(c, c.Param("code"))
+	if status := errorStatus(err); status != http.StatusInternalServerError {
+		c.String(status, err.Error())
+		return
+	} else if err != nil {
+		c.String(http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	c.JSON(http.StatusOK, model)
+}
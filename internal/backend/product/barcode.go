@@ -0,0 +1,86 @@
+package product
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-backend/pkg/myerr"
+)
+
+// Barcode is a normalized barcode of a Product, it's EAN-13 or EAN-8.
+// UPC-A barcodes are stored as EAN-13 with leading zero.
+type Barcode string
+
+const (
+	ean8Length  = 8
+	upcALength  = 12
+	ean13Length = 13
+)
+
+// ParseBarcode checks digits and checksum of EAN-13, UPC-A or EAN-8 barcode and normalizes it,
+// spaces and dashes are ignored
+func ParseBarcode(code string) (Barcode, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+
+		return r
+	}, code)
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w: barcode %q must contain digits only", myerr.ErrInvalidArgument, code)
+		}
+	}
+
+	switch len(digits) {
+	case ean8Length, ean13Length:
+	case upcALength:
+		digits = "0" + digits
+	default:
+		return "", fmt.Errorf("%w: barcode %q must have 8, 12 or 13 digits", myerr.ErrInvalidArgument, code)
+	}
+
+	if checkDigit(digits[:len(digits)-1]) != digits[len(digits)-1] {
+		return "", fmt.Errorf("%w: barcode %q has wrong check digit", myerr.ErrInvalidArgument, code)
+	}
+
+	return Barcode(digits), nil
+}
+
+// NormalizeBarcodes parses barcodes and drops duplicates
+func NormalizeBarcodes(codes []Barcode) ([]Barcode, error) {
+	normalized := make([]Barcode, 0, len(codes))
+
+	for _, code := range codes {
+		barcode, err := ParseBarcode(string(code))
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(normalized, barcode) {
+			normalized = append(normalized, barcode)
+		}
+	}
+
+	return normalized, nil
+}
+
+// checkDigit returns check digit of EAN barcode without it,
+// digits are weighted by 3 and 1 alternately starting from the rightmost one
+func checkDigit(digits string) byte {
+	sum := 0
+
+	for i := range len(digits) {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+
+		sum += digit
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
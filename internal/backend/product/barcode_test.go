@@ -0,0 +1,37 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/myerr"
+)
+
+func TestParseBarcode(t *testing.T) {
+	cases := []struct {
+		code    string
+		barcode product.Barcode
+	}{
+		{code: "4006381333931", barcode: "4006381333931"},
+		{code: "400-6381 333931", barcode: "4006381333931"},
+		{code: "036000291452", barcode: "0036000291452"},
+		{code: "96385074", barcode: "96385074"},
+	}
+
+	for _, c := range cases {
+		barcode, err := product.ParseBarcode(c.code)
+		require.NoError(t, err, c.code)
+		require.Equal(t, c.barcode, barcode, c.code)
+	}
+
+	for _, code := range []string{"", "4006381333932", "96385075", "40063813339", "4OO6381333931"} {
+		_, err := product.ParseBarcode(code)
+		require.ErrorIs(t, err, myerr.ErrInvalidArgument, code)
+	}
+
+	barcodes, err := product.NormalizeBarcodes([]product.Barcode{"036000291452", "0036000291452", "96385074"})
+	require.NoError(t, err)
+	require.Equal(t, []product.Barcode{"0036000291452", "96385074"}, barcodes)
+}
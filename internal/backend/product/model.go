@@ -21,6 +21,10 @@ type Options struct {
 	Name     Name                `json:"name" swaggertype:"string"`
 	Category mo.Option[Category] `json:"category" swaggertype:"string"`
 	Forms    []Form              `json:"forms" swaggertype:"array,string"`
+	// Aliases are other names of the product, the product is found by them too
+	Aliases []Name `json:"aliases" swaggertype:"array,string"`
+	// Barcodes are EAN-13, UPC-A or EAN-8 barcodes of the product, each barcode belongs to one product only
+	Barcodes []Barcode `json:"barcodes" swaggertype:"array,string"`
 }
 
 // NewZeroOptions returns zero options
@@ -29,9 +33,17 @@ func NewZeroOptions() Options {
 		Name:     "",
 		Category: mo.Option[Category]{},
 		Forms:    []Form{},
+		Aliases:  []Name{},
+		Barcodes: []Barcode{},
 	}
 }
 
+// Match is a product found by name, confidence is from 0 to 1 and product is absent when nothing is similar enough
+type Match struct {
+	Product    mo.Option[Product] `json:"product" extensions:"x-nullable"`
+	Confidence float64            `json:"confidence"`
+}
+
 // Category is a category of a Product
 type Category string
 
@@ -0,0 +1,88 @@
+package openfoodfacts
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go-backend/pkg/myerr"
+)
+
+// Reader streams records of gzipped JSONL dump, one record per line
+type Reader struct {
+	gzip  *gzip.Reader
+	lines *bufio.Reader
+	line  int
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: dump isn't gzipped: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	return &Reader{gzip: gz, lines: bufio.NewReader(gz), line: 0}, nil
+}
+
+// Line returns number of lines read
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Skip skips lines without decoding them, it's used to resume reading
+func (r *Reader) Skip(lines int) error {
+	for range lines {
+		if _, err := r.readLine(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Next returns the next record, io.EOF is returned at the end of dump.
+// Malformed lines are reported with myerr.ErrInvalidArgument, reading may go on after them.
+func (r *Reader) Next() (Record, error) {
+	var record Record
+
+	line, err := r.readLine()
+	for err == nil && len(bytes.TrimSpace(line)) == 0 {
+		line, err = r.readLine()
+	}
+
+	if err != nil {
+		return record, err
+	}
+
+	if err = json.Unmarshal(line, &record); err != nil {
+		return record, fmt.Errorf("%w: malformed line %d: %w", myerr.ErrInvalidArgument, r.line, err)
+	}
+
+	return record, nil
+}
+
+func (r *Reader) Close() error {
+	if err := r.gzip.Close(); err != nil {
+		return fmt.Errorf("can't close dump: %w", err)
+	}
+
+	return nil
+}
+
+// readLine returns the next line, long lines are read completely
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.lines.ReadBytes('\n')
+	if errors.Is(err, io.EOF) && len(line) == 0 {
+		return nil, io.EOF
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("can't read line %d of dump: %w", r.line+1, err)
+	}
+
+	r.line++
+
+	return line, nil
+}
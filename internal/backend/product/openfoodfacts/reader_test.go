@@ -0,0 +1,66 @@
+package openfoodfacts_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/product/openfoodfacts"
+	"go-backend/pkg/myerr"
+)
+
+func TestReader(t *testing.T) {
+	var dump bytes.Buffer
+
+	gz := gzip.NewWriter(&dump)
+	_, err := gz.Write([]byte(`{"code":"4006381333931","product_name":"Молоко  3,2%","product_name_en":"Milk 3.2%",` +
+		`"product_name_ru":"молоко 3,2%","categories":"Dairies, en:Milks","quantity":"1 L"}
+not json
+
+{"code":"123","product_name":"Unknown"}
+{"code":"96385074","categories":"Snacks"}`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	reader, err := openfoodfacts.NewReader(&dump)
+	require.NoError(t, err)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+
+	options, err := record.Options()
+	require.NoError(t, err)
+	require.Equal(t, product.Options{
+		Name:     "Молоко 3,2%",
+		Category: mo.Some[product.Category]("Milks"),
+		Forms:    []product.Form{"1 l"},
+		Aliases:  []product.Name{"Milk 3.2%"},
+		Barcodes: []product.Barcode{"4006381333931"},
+	}, options)
+
+	_, err = reader.Next()
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 4, reader.Line())
+
+	_, err = record.Options()
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+
+	_, err = record.Options()
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	_, err = reader.Next()
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 5, reader.Line())
+	require.NoError(t, reader.Close())
+}
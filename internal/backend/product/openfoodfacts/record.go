@@ -0,0 +1,84 @@
+// Package openfoodfacts reads products from Open Food Facts data dumps
+package openfoodfacts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/myerr"
+)
+
+// Record is a product of Open Food Facts dump, only fields mapped to product.Options are decoded
+type Record struct {
+	Code          string `json:"code"`
+	ProductName   string `json:"product_name"`
+	ProductNameEn string `json:"product_name_en"`
+	ProductNameRu string `json:"product_name_ru"`
+	GenericName   string `json:"generic_name"`
+	// Categories are comma separated categories from the most general to the most specific one
+	Categories string `json:"categories"`
+	Quantity   string `json:"quantity"`
+}
+
+// Options maps the record to options of a product, the product is identified by its barcode.
+// The most specific category is used, quantity becomes a form and names in other languages become aliases.
+func (r Record) Options() (product.Options, error) {
+	barcode, err := product.ParseBarcode(r.Code)
+	if err != nil {
+		return product.Options{}, err
+	}
+
+	names := make([]product.Name, 0)
+	for _, name := range []string{r.ProductName, r.ProductNameEn, r.ProductNameRu, r.GenericName} {
+		if name = strings.Join(strings.Fields(name), " "); name == "" {
+			continue
+		}
+
+		if !containsName(names, product.Name(name)) {
+			names = append(names, product.Name(name))
+		}
+	}
+
+	if len(names) == 0 {
+		return product.Options{}, fmt.Errorf("%w: product %s has no name", myerr.ErrInvalidArgument, barcode)
+	}
+
+	forms := make([]product.Form, 0)
+	if quantity := strings.ToLower(strings.Join(strings.Fields(r.Quantity), " ")); quantity != "" {
+		forms = append(forms, product.Form(quantity))
+	}
+
+	return product.Options{
+		Name:     names[0],
+		Category: r.category(),
+		Forms:    forms,
+		Aliases:  names[1:],
+		Barcodes: []product.Barcode{barcode},
+	}, nil
+}
+
+// category returns the most specific category without language prefix like "en:"
+func (r Record) category() mo.Option[product.Category] {
+	categories := strings.Split(r.Categories, ",")
+
+	category := strings.TrimSpace(categories[len(categories)-1])
+	if prefix, rest, found := strings.Cut(category, ":"); found && len(prefix) == 2 {
+		category = strings.TrimSpace(rest)
+	}
+
+	return mo.EmptyableToOption(product.Category(category))
+}
+
+// containsName reports whether names contain the name ignoring case and punctuation
+func containsName(names []product.Name, name product.Name) bool {
+	for _, item := range names {
+		if product.NormalizeName(item) == product.NormalizeName(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,305 @@
+package product
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/samber/mo"
+)
+
+// ParsedLine is a line of a pasted shopping list split into product name and quantity
+type ParsedLine struct {
+	Line     string              `json:"line"`
+	Name     Name                `json:"name" swaggertype:"string"`
+	Quantity mo.Option[Quantity] `json:"quantity" extensions:"x-nullable"`
+}
+
+var (
+	// listMarker matches bullets, checkboxes and numbering of list items
+	listMarker = regexp.MustCompile(`^(?:(?:[-*•·–—+]+|\[[ xX✓]?]|\d+[.)])\s+)+`)
+	// numberPrefix matches number at the beginning of token, like 2, 1.5, 0,5 or 1/2
+	numberPrefix = regexp.MustCompile(`^(\d+(?:[.,]\d+)?(?:/\d+)?)(.*)$`)
+	// multiplierToken matches multipliers like x2 or ×2
+	multiplierToken = regexp.MustCompile(`^[xх×*](\d+)$`)
+)
+
+// unitWords are english and russian spellings of units
+var unitWords = map[string]Unit{
+	"pc": UnitPieces, "pcs": UnitPieces, "piece": UnitPieces, "pieces": UnitPieces,
+	"шт": UnitPieces, "штук": UnitPieces, "штука": UnitPieces, "штуки": UnitPieces, "штучки": UnitPieces,
+
+	"g": UnitG, "gr": UnitG, "gram": UnitG, "grams": UnitG, "gramme": UnitG, "grammes": UnitG,
+	"г": UnitG, "гр": UnitG, "грамм": UnitG, "грамма": UnitG, "граммов": UnitG,
+
+	"kg": UnitKg, "kilo": UnitKg, "kilos": UnitKg, "kilogram": UnitKg, "kilograms": UnitKg,
+	"кг": UnitKg, "кило": UnitKg, "килограмм": UnitKg, "килограмма": UnitKg, "килограммов": UnitKg,
+
+	"ml": UnitMl, "milliliter": UnitMl, "milliliters": UnitMl, "millilitre": UnitMl, "millilitres": UnitMl,
+	"мл": UnitMl, "миллилитр": UnitMl, "миллилитра": UnitMl, "миллилитров": UnitMl,
+
+	"l": UnitL, "liter": UnitL, "liters": UnitL, "litre": UnitL, "litres": UnitL,
+	"л": UnitL, "литр": UnitL, "литра": UnitL, "литров": UnitL,
+
+	"pack": UnitPacks, "packs": UnitPacks, "package": UnitPacks, "packages": UnitPacks, "pkg": UnitPacks,
+	"уп": UnitPacks, "упаковка": UnitPacks, "упаковки": UnitPacks, "упаковок": UnitPacks,
+	"пачка": UnitPacks, "пачки": UnitPacks, "пачек": UnitPacks,
+	"пакет": UnitPacks, "пакета": UnitPacks, "пакетов": UnitPacks,
+}
+
+// numberWords are english and russian number words, they are recognized only at the beginning of a line
+var numberWords = map[string]float64{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"dozen": 12, "half": 0.5,
+
+	"один": 1, "одна": 1, "одно": 1, "одну": 1, "два": 2, "две": 2, "три": 3, "четыре": 4, "пять": 5,
+	"шесть": 6, "семь": 7, "восемь": 8, "девять": 9, "десять": 10,
+	"дюжина": 12, "дюжину": 12, "пол": 0.5, "половина": 0.5, "половину": 0.5,
+}
+
+// articles are words which mean one only if they are followed by unit, like "a pack of eggs"
+var articles = map[string]bool{"a": true, "an": true}
+
+// fillers are words between quantity and name, like "of" in "500 g of chicken"
+var fillers = map[string]bool{"of": true}
+
+// quantityPart is a recognized piece of quantity, unit is absent for bare numbers and multipliers
+type quantityPart struct {
+	value float64
+	unit  mo.Option[Unit]
+}
+
+// ParseText parses pasted shopping list line by line skipping empty lines
+func ParseText(text string) []ParsedLine {
+	lines := []ParsedLine{}
+
+	for _, line := range strings.Split(text, "\n") {
+		if parsed, ok := ParseLine(line); ok {
+			lines = append(lines, parsed)
+		}
+	}
+
+	return lines
+}
+
+// ParseLine parses line like "2x milk 1L" or "500 г куриной грудки", false is returned if there is no name
+func ParseLine(line string) (ParsedLine, bool) {
+	line = strings.TrimSpace(line)
+	tokens := strings.Fields(listMarker.ReplaceAllString(line, ""))
+
+	var parts []quantityPart
+
+	nameTokens := make([]string, 0, len(tokens))
+
+	for i := 0; i < len(tokens); {
+		part, consumed := parseQuantity(tokens, i, len(nameTokens) == 0 && len(parts) == 0)
+		if consumed == 0 {
+			nameTokens = append(nameTokens, tokens[i])
+			i++
+
+			continue
+		}
+
+		parts = append(parts, part)
+		i += consumed
+
+		if i < len(tokens) && len(nameTokens) == 0 && fillers[strings.ToLower(tokens[i])] {
+			i++
+		}
+	}
+
+	name := strings.Trim(strings.Join(nameTokens, " "), " ,;:-–—")
+	if name == "" {
+		return ParsedLine{}, false
+	}
+
+	return ParsedLine{Line: line, Name: Name(name), Quantity: combineQuantity(parts)}, true
+}
+
+// parseQuantity tries to parse quantity starting at tokens[i], it returns number of consumed tokens
+func parseQuantity(tokens []string, i int, first bool) (quantityPart, int) {
+	token := strings.ToLower(strings.TrimRight(tokens[i], ",;"))
+
+	unitAt := func(j int) (Unit, bool) {
+		if j >= len(tokens) {
+			return 0, false
+		}
+
+		return lookupUnit(tokens[j])
+	}
+
+	if match := multiplierToken.FindStringSubmatch(token); match != nil {
+		value, _ := strconv.ParseFloat(match[1], 64)
+		return quantityPart{value: value, unit: mo.None[Unit]()}, 1
+	}
+
+	if (token == "x" || token == "х" || token == "×") && i+1 < len(tokens) {
+		if value, err := strconv.ParseFloat(tokens[i+1], 64); err == nil {
+			return quantityPart{value: value, unit: mo.None[Unit]()}, 2
+		}
+	}
+
+	if match := numberPrefix.FindStringSubmatch(token); match != nil {
+		value, ok := parseNumber(match[1])
+		if !ok {
+			return quantityPart{}, 0
+		}
+
+		switch rest := match[2]; {
+		case rest == "":
+			if unit, ok := unitAt(i + 1); ok {
+				return quantityPart{value: value, unit: mo.Some(unit)}, 2
+			}
+
+			return quantityPart{value: value, unit: mo.None[Unit]()}, 1
+		case rest == "x" || rest == "х" || rest == "×":
+			return quantityPart{value: value, unit: mo.None[Unit]()}, 1
+		default:
+			if unit, ok := lookupUnit(rest); ok {
+				return quantityPart{value: value, unit: mo.Some(unit)}, 1
+			}
+
+			return quantityPart{}, 0
+		}
+	}
+
+	if !first {
+		return quantityPart{}, 0
+	}
+
+	if value, ok := numberWords[token]; ok {
+		if unit, ok := unitAt(i + 1); ok {
+			return quantityPart{value: value, unit: mo.Some(unit)}, 2
+		}
+
+		// "half a kilo"
+		if unit, ok := unitAt(i + 2); ok && articles[strings.ToLower(tokens[i+1])] {
+			return quantityPart{value: value, unit: mo.Some(unit)}, 3
+		}
+
+		return quantityPart{value: value, unit: mo.None[Unit]()}, 1
+	}
+
+	if articles[token] {
+		if unit, ok := unitAt(i + 1); ok {
+			return quantityPart{value: 1, unit: mo.Some(unit)}, 2
+		}
+
+		if next := i + 1; next < len(tokens) && strings.ToLower(tokens[next]) == "dozen" {
+			return quantityPart{value: numberWords["dozen"], unit: mo.None[Unit]()}, 2
+		}
+	}
+
+	// russian "полкило" or "пол-литра"
+	if rest, found := strings.CutPrefix(token, "пол"); found {
+		if unit, ok := lookupUnit(strings.TrimPrefix(rest, "-")); ok {
+			return quantityPart{value: numberWords["пол"], unit: mo.Some(unit)}, 1
+		}
+	}
+
+	return quantityPart{}, 0
+}
+
+// combineQuantity multiplies measured part like 1L by counts like 2x, the first measured part wins
+func combineQuantity(parts []quantityPart) mo.Option[Quantity] {
+	if len(parts) == 0 {
+		return mo.None[Quantity]()
+	}
+
+	count := 1.0
+	measure := mo.None[Quantity]()
+
+	for _, part := range parts {
+		unit, found := part.unit.Get()
+
+		switch {
+		case !found || unit == UnitPieces:
+			count *= part.value
+		case measure.IsAbsent():
+			measure = mo.Some(Quantity{Value: part.value, Unit: unit})
+		}
+	}
+
+	quantity := measure.OrElse(Quantity{Value: 1, Unit: UnitPieces})
+	quantity.Value = round(quantity.Value * count)
+
+	if quantity.Validate() != nil {
+		return mo.None[Quantity]()
+	}
+
+	return mo.Some(quantity)
+}
+
+func lookupUnit(word string) (Unit, bool) {
+	unit, ok := unitWords[strings.TrimRight(strings.ToLower(word), ".,;")]
+	return unit, ok
+}
+
+func parseNumber(raw string) (float64, bool) {
+	numerator, denominator, isFraction := strings.Cut(raw, "/")
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(numerator, ",", "."), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if !isFraction {
+		return value, true
+	}
+
+	divider, err := strconv.ParseFloat(denominator, 64)
+	if err != nil || divider == 0 {
+		return 0, false
+	}
+
+	return value / divider, true
+}
+
+// NormalizeName returns form of name used for matching: lowercase, single spaces, no punctuation and ё replaced by е
+func NormalizeName(name Name) string {
+	fields := strings.FieldsFunc(strings.ToLower(string(name)), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+
+	return strings.ReplaceAll(strings.Join(fields, " "), "ё", "е")
+}
+
+// NameSimilarity returns similarity of normalized names from 0 to 1 based on edit distance
+func NameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,79 @@
+package product_test
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+)
+
+func TestParseLine(t *testing.T) {
+	quantity := func(value float64, unit product.Unit) mo.Option[product.Quantity] {
+		return mo.Some(product.Quantity{Value: value, Unit: unit})
+	}
+
+	cases := []struct {
+		line     string
+		name     product.Name
+		quantity mo.Option[product.Quantity]
+	}{
+		{line: "eggs", name: "eggs", quantity: mo.None[product.Quantity]()},
+		{line: "2x milk 1L", name: "milk", quantity: quantity(2, product.UnitL)},
+		{line: "500g chicken breast", name: "chicken breast", quantity: quantity(500, product.UnitG)},
+		{line: "1.5 kg of potatoes", name: "potatoes", quantity: quantity(1.5, product.UnitKg)},
+		{line: "bread x2", name: "bread", quantity: quantity(2, product.UnitPieces)},
+		{line: "apples 6 pcs", name: "apples", quantity: quantity(6, product.UnitPieces)},
+		{line: "3 bananas", name: "bananas", quantity: quantity(3, product.UnitPieces)},
+		{line: "two packs of butter", name: "butter", quantity: quantity(2, product.UnitPacks)},
+		{line: "a dozen eggs", name: "eggs", quantity: quantity(12, product.UnitPieces)},
+		{line: "half a kilo of flour", name: "flour", quantity: quantity(0.5, product.UnitKg)},
+		{line: "- [ ] 250 ml cream", name: "cream", quantity: quantity(250, product.UnitMl)},
+		{line: "1) 7up", name: "7up", quantity: mo.None[product.Quantity]()},
+		{line: "yogurt 1/2 l", name: "yogurt", quantity: quantity(0.5, product.UnitL)},
+		{line: "three cheese pizza", name: "cheese pizza", quantity: quantity(3, product.UnitPieces)},
+		{line: "молоко 1,5 л", name: "молоко", quantity: quantity(1.5, product.UnitL)},
+		{line: "500 г куриной грудки", name: "куриной грудки", quantity: quantity(500, product.UnitG)},
+		{line: "три яйца", name: "яйца", quantity: quantity(3, product.UnitPieces)},
+		{line: "две пачки масла", name: "масла", quantity: quantity(2, product.UnitPacks)},
+		{line: "хлеб 2 шт.", name: "хлеб", quantity: quantity(2, product.UnitPieces)},
+		{line: "пол-литра кефира", name: "кефира", quantity: quantity(0.5, product.UnitL)},
+		{line: "полкило сахара", name: "сахара", quantity: quantity(0.5, product.UnitKg)},
+		{line: "Сыр х2 200гр", name: "Сыр", quantity: quantity(400, product.UnitG)},
+		{line: "• Молоко 3.2% 1л", name: "Молоко 3.2%", quantity: quantity(1, product.UnitL)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			parsed, ok := product.ParseLine(c.line)
+			require.True(t, ok)
+			require.Equal(t, c.name, parsed.Name)
+			require.Equal(t, c.quantity, parsed.Quantity)
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	lines := product.ParseText("2x milk 1L\n\neggs\n  \n500g chicken breast\n1 kg\n")
+
+	require.Len(t, lines, 3)
+	require.Equal(t, []product.Name{"milk", "eggs", "chicken breast"}, []product.Name{
+		lines[0].Name, lines[1].Name, lines[2].Name,
+	})
+}
+
+func TestNormalizeName(t *testing.T) {
+	cases := []struct {
+		name       product.Name
+		normalized string
+	}{
+		{name: "  Chicken   Breast ", normalized: "chicken breast"},
+		{name: "Ёжики, в тумане!", normalized: "ежики в тумане"},
+		{name: "Coca-Cola", normalized: "coca cola"},
+	}
+
+	for _, c := range cases {
+		require.Equal(t, c.normalized, product.NormalizeName(c.name))
+	}
+}
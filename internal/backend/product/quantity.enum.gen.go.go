@@ -0,0 +1,191 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package product
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// UnitPieces is a Unit of type Pieces.
+	UnitPieces Unit = iota + 1
+	// UnitG is a Unit of type G.
+	UnitG
+	// UnitKg is a Unit of type Kg.
+	UnitKg
+	// UnitMl is a Unit of type Ml.
+	UnitMl
+	// UnitL is a Unit of type L.
+	UnitL
+	// UnitPacks is a Unit of type Packs.
+	UnitPacks
+)
+
+var ErrInvalidUnit = fmt.Errorf("not a valid Unit, try [%s]", strings.Join(_UnitNames, ", "))
+
+const _UnitName = "piecesgkgmllpacks"
+
+var _UnitNames = []string{
+	_UnitName[0:6],
+	_UnitName[6:7],
+	_UnitName[7:9],
+	_UnitName[9:11],
+	_UnitName[11:12],
+	_UnitName[12:17],
+}
+
+// UnitNames returns a list of possible string values of Unit.
+func UnitNames() []string {
+	tmp := make([]string, len(_UnitNames))
+	copy(tmp, _UnitNames)
+	return tmp
+}
+
+// UnitValues returns a list of the values for Unit
+func UnitValues() []Unit {
+	return []Unit{
+		UnitPieces,
+		UnitG,
+		UnitKg,
+		UnitMl,
+		UnitL,
+		UnitPacks,
+	}
+}
+
+var _UnitMap = map[Unit]string{
+	UnitPieces: _UnitName[0:6],
+	UnitG:      _UnitName[6:7],
+	UnitKg:     _UnitName[7:9],
+	UnitMl:     _UnitName[9:11],
+	UnitL:      _UnitName[11:12],
+	UnitPacks:  _UnitName[12:17],
+}
+
+// String implements the Stringer interface.
+func (x Unit) String() string {
+	if str, ok := _UnitMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Unit(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Unit) IsValid() bool {
+	_, ok := _UnitMap[x]
+	return ok
+}
+
+var _UnitValue = map[string]Unit{
+	_UnitName[0:6]:   UnitPieces,
+	_UnitName[6:7]:   UnitG,
+	_UnitName[7:9]:   UnitKg,
+	_UnitName[9:11]:  UnitMl,
+	_UnitName[11:12]: UnitL,
+	_UnitName[12:17]: UnitPacks,
+}
+
+// ParseUnit attempts to convert a string to a Unit.
+func ParseUnit(name string) (Unit, error) {
+	if x, ok := _UnitValue[name]; ok {
+		return x, nil
+	}
+	return Unit(0), fmt.Errorf("%s is %w", name, ErrInvalidUnit)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Unit) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Unit) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseUnit(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errUnitNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *Unit) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = Unit(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = Unit(v)
+	case string:
+		*x, err = ParseUnit(v)
+	case []byte:
+		*x, err = ParseUnit(string(v))
+	case Unit:
+		*x = v
+	case int:
+		*x = Unit(v)
+	case *Unit:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = Unit(v)
+	case uint64:
+		*x = Unit(v)
+	case *int:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = Unit(*v)
+	case *int64:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = Unit(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = Unit(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = Unit(*v)
+	case *uint:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = Unit(*v)
+	case *uint64:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x = Unit(*v)
+	case *string:
+		if v == nil {
+			return errUnitNilPtr
+		}
+		*x, err = ParseUnit(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x Unit) Value() (driver.Value, error) {
+	return x.String(), nil
+}
@@ -0,0 +1,118 @@
+package product
+
+import (
+	"fmt"
+	"math"
+
+	"go-backend/pkg/myerr"
+)
+
+//go:generate python $GOENUM
+
+// ENUM(pieces=1, g, kg, ml, l, packs)
+type Unit int32
+
+// dimension is a group of units which can be converted to each other
+type dimension int
+
+const (
+	dimensionPieces dimension = iota + 1
+	dimensionMass
+	dimensionVolume
+	dimensionPacks
+)
+
+// quantityPrecision is a number of kept fractional digits of quantity value
+const quantityPrecision = 1000
+
+// unitScales are dimensions of units and numbers of base units of dimension in one unit
+var unitScales = map[Unit]struct {
+	dimension dimension
+	scale     float64
+}{
+	UnitPieces: {dimension: dimensionPieces, scale: 1},
+	UnitG:      {dimension: dimensionMass, scale: 1},
+	UnitKg:     {dimension: dimensionMass, scale: 1000},
+	UnitMl:     {dimension: dimensionVolume, scale: 1},
+	UnitL:      {dimension: dimensionVolume, scale: 1000},
+	UnitPacks:  {dimension: dimensionPacks, scale: 1},
+}
+
+// Quantity is an amount of a product in some unit
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  Unit    `json:"unit" swaggertype:"string"`
+}
+
+// NewPieces returns quantity of count pieces
+func NewPieces(count int32) Quantity {
+	return Quantity{Value: float64(count), Unit: UnitPieces}
+}
+
+// Validate checks that value is positive finite number and unit is known
+func (q Quantity) Validate() error {
+	if !q.Unit.IsValid() {
+		return fmt.Errorf("%w: unknown unit %d", myerr.ErrInvalidArgument, q.Unit)
+	}
+
+	if math.IsNaN(q.Value) || math.IsInf(q.Value, 0) || q.Value <= 0 {
+		return fmt.Errorf("%w: quantity must be positive number, got %v", myerr.ErrInvalidArgument, q.Value)
+	}
+
+	return nil
+}
+
+// Compatible reports whether quantities can be converted to each other
+func (q Quantity) Compatible(other Quantity) bool {
+	return unitScales[q.Unit].dimension == unitScales[other.Unit].dimension
+}
+
+// Convert returns the same amount in another unit
+func (q Quantity) Convert(unit Unit) (Quantity, error) {
+	from, to := unitScales[q.Unit], unitScales[unit]
+	if from.dimension != to.dimension || to.scale == 0 {
+		return q, fmt.Errorf("%w: %s can't be converted to %s", myerr.ErrInvalidArgument, q.Unit, unit)
+	}
+
+	return Quantity{Value: round(q.Value * from.scale / to.scale), Unit: unit}, nil
+}
+
+// Add sums quantities, result has unit of q
+func (q Quantity) Add(other Quantity) (Quantity, error) {
+	converted, err := other.Convert(q.Unit)
+	if err != nil {
+		return q, err
+	}
+
+	return Quantity{Value: round(q.Value + converted.Value), Unit: q.Unit}, nil
+}
+
+// Scale returns quantity multiplied by factor, e.g. to cook a recipe for more servings
+func (q Quantity) Scale(factor float64) Quantity {
+	return Quantity{Value: round(q.Value * factor), Unit: q.Unit}
+}
+
+// Compare returns -1, 0 or 1 if q is less, equal or greater than other, quantities must be compatible
+func (q Quantity) Compare(other Quantity) (int, error) {
+	converted, err := other.Convert(q.Unit)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case q.Value < converted.Value:
+		return -1, nil
+	case q.Value > converted.Value:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (q Quantity) String() string {
+	return fmt.Sprintf("%v %s", q.Value, q.Unit)
+}
+
+func round(value float64) float64 {
+	return math.Round(value*quantityPrecision) / quantityPrecision
+}
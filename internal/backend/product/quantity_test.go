@@ -0,0 +1,103 @@
+package product_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/myerr"
+)
+
+func TestQuantityConvert(t *testing.T) {
+	cases := []struct {
+		name     string
+		from     product.Quantity
+		to       product.Unit
+		expected product.Quantity
+		err      error
+	}{
+		{
+			name:     "grams to kilograms",
+			from:     product.Quantity{Value: 500, Unit: product.UnitG},
+			to:       product.UnitKg,
+			expected: product.Quantity{Value: 0.5, Unit: product.UnitKg},
+		},
+		{
+			name:     "liters to milliliters",
+			from:     product.Quantity{Value: 1.5, Unit: product.UnitL},
+			to:       product.UnitMl,
+			expected: product.Quantity{Value: 1500, Unit: product.UnitMl},
+		},
+		{
+			name:     "same unit",
+			from:     product.Quantity{Value: 2, Unit: product.UnitPacks},
+			to:       product.UnitPacks,
+			expected: product.Quantity{Value: 2, Unit: product.UnitPacks},
+		},
+		{
+			name: "mass to volume",
+			from: product.Quantity{Value: 1, Unit: product.UnitKg},
+			to:   product.UnitL,
+			err:  myerr.ErrInvalidArgument,
+		},
+		{
+			name: "pieces to packs",
+			from: product.Quantity{Value: 1, Unit: product.UnitPieces},
+			to:   product.UnitPacks,
+			err:  myerr.ErrInvalidArgument,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			converted, err := c.from.Convert(c.to)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, c.expected, converted)
+		})
+	}
+}
+
+func TestQuantityAdd(t *testing.T) {
+	sum, err := product.Quantity{Value: 500, Unit: product.UnitG}.Add(product.Quantity{Value: 0.25, Unit: product.UnitKg})
+	require.NoError(t, err)
+	require.Equal(t, product.Quantity{Value: 750, Unit: product.UnitG}, sum)
+
+	sum, err = product.Quantity{Value: 0.1, Unit: product.UnitL}.Add(product.Quantity{Value: 0.2, Unit: product.UnitL})
+	require.NoError(t, err)
+	require.Equal(t, product.Quantity{Value: 0.3, Unit: product.UnitL}, sum)
+
+	_, err = product.NewPieces(2).Add(product.Quantity{Value: 1, Unit: product.UnitL})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestQuantityValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		quantity product.Quantity
+		valid    bool
+	}{
+		{name: "valid", quantity: product.Quantity{Value: 1.5, Unit: product.UnitKg}, valid: true},
+		{name: "zero", quantity: product.Quantity{Value: 0, Unit: product.UnitKg}, valid: false},
+		{name: "negative", quantity: product.Quantity{Value: -1, Unit: product.UnitG}, valid: false},
+		{name: "not a number", quantity: product.Quantity{Value: math.NaN(), Unit: product.UnitG}, valid: false},
+		{name: "unknown unit", quantity: product.Quantity{Value: 1, Unit: 0}, valid: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.quantity.Validate()
+			if c.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/dedup"
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/mymysql"
+)
+
+// Merged is a plan of merge with ids of products as they are stored,
+// repos which refer products rewrite their rows by it
+type Merged struct {
+	Plan    dedup.Merge
+	Target  string
+	Sources []string
+}
+
+func NewMerged(merge dedup.Merge) Merged {
+	return Merged{
+		Plan:    merge,
+		Target:  merge.Target.ID.String(),
+		Sources: lo.Map(merge.SourceIDs, func(item id.ID[product.Product], _ int) string { return item.String() }),
+	}
+}
+
+// Products returns ids of the target and sources
+func (m Merged) Products() []string {
+	return append([]string{m.Target}, m.Sources...)
+}
+
+// FormIdx returns index of form of the target which replaces the form of the product
+func (m Merged) FormIdx(productID string, formIdx *int32) *int32 {
+	model := id.ID[product.Product]{UUID: god.Believe(uuid.Parse(productID))}
+
+	return m.Plan.FormIdx(model, mo.PointerToOption(formIdx)).ToPointer()
+}
+
+// Merge deletes sources and saves the target with their names, forms and barcodes, it joins the transaction of ctx.
+// Rows of other repos which refer sources must be merged before it.
+func (r *GormRepo) Merge(ctx context.Context, merge dedup.Merge) (product.Product, error) {
+	m := NewMerged(merge)
+
+	var target product.Product
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		for _, child := range []any{new(ProductForm), new(ProductAlias), new(ProductBarcode)} {
+			if err := tx.WithContext(ctx).Where("product_id IN ?", m.Sources).Delete(child).Error; err != nil {
+				return fmt.Errorf("can't delete contents of merged products: %w", err)
+			}
+		}
+
+		if err := tx.WithContext(ctx).Where("id IN ?", m.Sources).Delete(new(Product)).Error; err != nil {
+			return fmt.Errorf("can't delete merged products: %w", err)
+		}
+
+		var err error
+		if target, err = Save(ctx, tx, m.Plan.Target); err != nil {
+			return fmt.Errorf("can't save product %s: %w", m.Target, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return product.Product{}, wrapErr(fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err))
+	}
+
+	return target, nil
+}
+
+// LoadMerged selects rows of merged products whose parents, e.g. lists, have rows of sources
+func LoadMerged[T any](ctx context.Context, tx *gorm.DB, m Merged, parentColumn string, order string) ([]T, error) {
+	var rows []T
+
+	parents := tx.WithContext(ctx).Model(new(T)).Select(parentColumn).Where("product_id IN ?", m.Sources)
+
+	err := tx.WithContext(ctx).
+		Where(parentColumn+" IN (?) AND product_id IN ?", parents, m.Products()).
+		Order(parentColumn).
+		Order(order).
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select %T of merged products: %w", rows, err)
+	}
+
+	return rows, nil
+}
+
+// CombineMerged merges rows of merged products with the same parent into one row which gets the target.
+// The row of the target or the first row is kept and gets the rest of rows, which are dropped.
+// fields returns the parent of the row and its product id field.
+func CombineMerged[T any](
+	rows []T,
+	m Merged,
+	fields func(*T) (string, *string),
+	merge func(kept, other T) T,
+) (
+	[]T,
+	[]T,
+) {
+	var kept, dropped []T
+
+	parent := func(item T, _ int) string { return god.Believe(fields(&item)) }
+	groups := lo.GroupBy(rows, func(item T) string { return parent(item, 0) })
+
+	for _, parentID := range lo.Uniq(lo.Map(rows, parent)) {
+		group := groups[parentID]
+		idx := max(slices.IndexFunc(group, func(item T) bool { return *god.OnlySecond(fields(&item)) == m.Target }), 0)
+
+		row := group[idx]
+		for i, other := range group {
+			if i != idx {
+				row = merge(row, other)
+				dropped = append(dropped, other)
+			}
+		}
+
+		*god.OnlySecond(fields(&row)) = m.Target
+		kept = append(kept, row)
+	}
+
+	return kept, dropped
+}
+
+// SaveMerged deletes dropped rows and saves kept ones, rows have primary keys returned by rowID
+func SaveMerged[T any](ctx context.Context, tx *gorm.DB, kept []T, dropped []T, rowID func(T) string) error {
+	if len(dropped) != 0 {
+		ids := lo.Map(dropped, func(item T, _ int) string { return rowID(item) })
+
+		if err := tx.WithContext(ctx).Where("id IN ?", ids).Delete(new(T)).Error; err != nil {
+			return fmt.Errorf("can't delete %T of merged products: %w", dropped, err)
+		}
+	}
+
+	for _, row := range kept {
+		if err := tx.WithContext(ctx).Omit(clause.Associations).Save(&row).Error; err != nil {
+			return fmt.Errorf("can't save %T of merged products: %w", row, err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceMerged replaces rows of merged products by kept ones, product id is a part of their primary keys
+func ReplaceMerged[T any](
+	ctx context.Context,
+	tx *gorm.DB,
+	m Merged,
+	parentColumn string,
+	kept []T,
+	parent func(T) string,
+) error {
+	if len(kept) == 0 {
+		return nil
+	}
+
+	parents := lo.Map(kept, func(item T, _ int) string { return parent(item) })
+
+	err := tx.WithContext(ctx).
+		Where(parentColumn+" IN ? AND product_id IN ?", parents, m.Products()).
+		Delete(new(T)).Error
+	if err != nil {
+		return fmt.Errorf("can't delete %T of merged products: %w", kept, err)
+	}
+
+	if err = tx.WithContext(ctx).Omit(clause.Associations).Create(&kept).Error; err != nil {
+		return fmt.Errorf("can't insert %T of merged products: %w", kept, err)
+	}
+
+	return nil
+}
+
+// SumMerged returns sum of quantities of merged rows, see dedup.SumQuantities
+func SumMerged(a, b Quantity) Quantity {
+	return QuantityToEntity(dedup.SumQuantities(QuantityToModel(a, nil), QuantityToModel(b, nil)))
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,49 +16,79 @@ import (
 
 	"go-backend/internal/backend/product"
 	"go-backend/pkg/date"
+	"go-backend/pkg/dbtx"
 	"go-backend/pkg/god"
 	"go-backend/pkg/id"
+	"go-backend/pkg/mymysql"
 )
 
 type Product struct {
-	ID         string           `gorm:"primaryKey;size:36"`
-	CreatedAt  time.Time        `gorm:"notNull"`
-	UpdatedAt  time.Time        `gorm:"notNull"`
-	Name       string           `gorm:"size:256;notNull"`
-	CategoryID sql.NullString   `gorm:"size:36"`
-	Category   *ProductCategory `gorm:"references:ID"`
-	Forms      []ProductForm
+	ID        string    `gorm:"primaryKey;size:36"`
+	CreatedAt time.Time `gorm:"notNull"`
+	UpdatedAt time.Time `gorm:"notNull;index"`
+	Name      string    `gorm:"size:256;notNull"`
+	// NormalizedName is a name used for matching products by names, see product.NormalizeName
+	NormalizedName string           `gorm:"size:256;notNull;default:'';index"`
+	CategoryID     sql.NullString   `gorm:"size:36"`
+	Category       *ProductCategory `gorm:"references:ID"`
+	Forms          []ProductForm
+	Aliases        []ProductAlias
+	Barcodes       []ProductBarcode
 }
 
-func (c *Product) BeforeSave(_ *gorm.DB) error {
-	if !c.CategoryID.Valid {
+// BeforeSave finds category of the product by name. Categories are created only in the category tree,
+// so products of unknown categories are saved uncategorized.
+func (c *Product) BeforeSave(tx *gorm.DB) error {
+	if c.Category == nil || c.Category.ID != "" {
 		return nil
 	}
-	if c.Category == nil {
+
+	name := product.NormalizeName(product.Name(c.Category.Name))
+
+	existed, err := FindCategory(tx.Session(&gorm.Session{NewDB: true}), name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.Category, c.CategoryID = nil, sql.NullString{String: "", Valid: false}
 		return nil
+	} else if err != nil {
+		return err
 	}
 
-	c.CategoryID = sql.NullString{String: c.Category.Name, Valid: true}
+	c.Category, c.CategoryID = &existed, sql.NullString{String: existed.ID, Valid: true}
+
 	return nil
 }
 
+// ProductCategory is a node of category tree, products with any of its names get the category
 type ProductCategory struct {
 	ID   string `gorm:"primaryKey;size:36"`
 	Name string `gorm:"size:255"`
+	// NormalizedName is used for finding categories by names, see product.NormalizeName
+	NormalizedName string                `gorm:"size:255;notNull;default:'';index"`
+	ParentID       sql.NullString        `gorm:"size:36;index"`
+	Names          []ProductCategoryName `gorm:"foreignKey:CategoryID"`
 }
 
-func (c *ProductCategory) BeforeSave(tx *gorm.DB) error {
-	var existed ProductCategory
-	err := tx.First(&existed, "name = ?", c.Name).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		c.ID = uuid.NewString()
-		return nil
-	} else if err != nil {
-		return err
+// ProductCategoryName is a localized name of a category, names without language are other spellings
+type ProductCategoryName struct {
+	ID             string `gorm:"primaryKey;size:36"`
+	CategoryID     string `gorm:"size:36;notNull;index"`
+	Lang           string `gorm:"size:8;notNull;default:''"`
+	Name           string `gorm:"size:255;notNull"`
+	NormalizedName string `gorm:"size:255;notNull;index"`
+}
+
+// FindCategory returns category whose name or one of other names has normalized name
+func FindCategory(db *gorm.DB, normalizedName string) (ProductCategory, error) {
+	var entity ProductCategory
+
+	names := db.Model(new(ProductCategoryName)).Select("category_id").Where("normalized_name = ?", normalizedName)
+
+	err := db.Where("normalized_name = ?", normalizedName).Or("id IN (?)", names).First(&entity).Error
+	if err != nil {
+		return entity, fmt.Errorf("can't find category %q: %w", normalizedName, err)
 	}
 
-	c.ID = existed.ID
-	return nil
+	return entity, nil
 }
 
 type ProductForm struct {
@@ -66,19 +97,151 @@ type ProductForm struct {
 	Name      string `gorm:"size:255"`
 }
 
+// ProductAlias is another name of a product, it's used by search
+type ProductAlias struct {
+	ProductID string `gorm:"size:36;references:ID;index"`
+	ID        string `gorm:"primaryKey;size:36"`
+	Name      string `gorm:"size:256"`
+}
+
+// ProductBarcode is a normalized barcode of a product, see product.ParseBarcode
+type ProductBarcode struct {
+	Code      string `gorm:"primaryKey;size:13"`
+	ProductID string `gorm:"size:36;references:ID;index"`
+}
+
 type GormRepo struct {
 	db *gorm.DB
 }
 
 func NewGormRepo(ctx context.Context, db *gorm.DB) (*GormRepo, error) {
-	err := db.WithContext(ctx).AutoMigrate(new(ProductCategory), new(Product), new(ProductForm))
+	err := db.WithContext(ctx).AutoMigrate(new(ProductCategory), new(ProductCategoryName), new(Product),
+		new(ProductForm), new(ProductAlias), new(ProductBarcode))
 	if err != nil {
 		return nil, fmt.Errorf("can't initialize product tables: %w", err)
 	}
 
+	if err = normalizeNames(ctx, db); err != nil {
+		return nil, err
+	}
+
+	if err = normalizeCategories(ctx, db); err != nil {
+		return nil, err
+	}
+
 	return &GormRepo{db: db}, nil
 }
 
+// normalizeNames fills normalized names of products created before they were introduced
+func normalizeNames(ctx context.Context, db *gorm.DB) error {
+	var entities []Product
+
+	err := db.WithContext(ctx).Select("id", "name").Where("normalized_name = ''").Find(&entities).Error
+	if err != nil {
+		return fmt.Errorf("can't select products without normalized names: %w", err)
+	}
+
+	for _, entity := range entities {
+		err = db.WithContext(ctx).Model(&Product{ID: entity.ID}).
+			Update("normalized_name", product.NormalizeName(product.Name(entity.Name))).Error
+		if err != nil {
+			return fmt.Errorf("can't normalize name of product %s: %w", entity.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// normalizeCategories fills normalized names of categories created before they were introduced
+// and merges categories with equal normalized names, e.g. "Dairy" and "dairy" created by old hooks
+func normalizeCategories(ctx context.Context, db *gorm.DB) error {
+	var entities []ProductCategory
+
+	err := db.WithContext(ctx).Select("id", "name").Where("normalized_name = ''").Find(&entities).Error
+	if err != nil {
+		return fmt.Errorf("can't select categories without normalized names: %w", err)
+	}
+
+	for _, entity := range entities {
+		err = db.WithContext(ctx).Model(&ProductCategory{ID: entity.ID}).
+			Update("normalized_name", product.NormalizeName(product.Name(entity.Name))).Error
+		if err != nil {
+			return fmt.Errorf("can't normalize name of category %s: %w", entity.ID, err)
+		}
+	}
+
+	var duplicated []string
+
+	err = db.WithContext(ctx).Model(new(ProductCategory)).
+		Group("normalized_name").
+		Having("COUNT(*) > 1").
+		Pluck("normalized_name", &duplicated).Error
+	if err != nil {
+		return fmt.Errorf("can't select duplicated categories: %w", err)
+	}
+
+	for _, name := range duplicated {
+		if err = mergeCategories(ctx, db, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeCategories merges categories with the normalized name into the first one by name,
+// products, children and other names of duplicates are moved to it
+func mergeCategories(ctx context.Context, db *gorm.DB, normalizedName string) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entities []ProductCategory
+
+		err := tx.Preload("Names").Where("normalized_name = ?", normalizedName).Order("name, id").Find(&entities).Error
+		if err != nil {
+			return fmt.Errorf("can't select categories %q: %w", normalizedName, err)
+		}
+
+		target, duplicates := entities[0], entities[1:]
+		duplicateIDs := lo.Map(duplicates, func(item ProductCategory, _ int) string { return item.ID })
+
+		err = tx.Model(new(Product)).Where("category_id IN ?", duplicateIDs).Update("category_id", target.ID).Error
+		if err != nil {
+			return fmt.Errorf("can't move products to category %s: %w", target.ID, err)
+		}
+
+		err = tx.Model(new(ProductCategory)).Where("parent_id IN ?", duplicateIDs).Update("parent_id", target.ID).Error
+		if err != nil {
+			return fmt.Errorf("can't move children to category %s: %w", target.ID, err)
+		}
+
+		// the target keeps its localized names, other names of duplicates in the same languages become aliases
+		langs := lo.Map(target.Names, func(item ProductCategoryName, _ int) string { return item.Lang })
+
+		for _, name := range lo.FlatMap(duplicates, func(item ProductCategory, _ int) []ProductCategoryName {
+			return item.Names
+		}) {
+			lang := lo.Ternary(slices.Contains(langs, name.Lang), "", name.Lang)
+			langs = append(langs, lang)
+
+			err = tx.Model(&ProductCategoryName{ID: name.ID}).
+				Updates(map[string]any{"category_id": target.ID, "lang": lang}).Error
+			if err != nil {
+				return fmt.Errorf("can't move name %q to category %s: %w", name.Name, target.ID, err)
+			}
+		}
+
+		if err = tx.Where("id IN ?", duplicateIDs).Delete(new(ProductCategory)).Error; err != nil {
+			return fmt.Errorf("can't delete duplicates of category %s: %w", target.ID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't merge categories %q: %w", normalizedName, err)
+	}
+
+	return nil
+}
+
 func (r *GormRepo) GetAndUpdate(
 	ctx context.Context,
 	productID id.ID[product.Product],
@@ -99,30 +262,99 @@ func (r *GormRepo) GetAndUpdate(
 		if err != nil {
 			return err
 		}
-		entity = ModelToEntity(model)
 
-		err = tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Model(&Product{ID: entity.ID}).
-			Association("Forms").Unscoped().Replace(entity.Forms)
-		if err != nil {
-			return fmt.Errorf("can't update product %s associations: %w", productID, err)
-		}
-		err = tx.WithContext(ctx).Save(&entity).Error
-		if err != nil {
-			return fmt.Errorf("can't update product %s: %w", productID, err)
+		model, err = Save(ctx, tx, model)
+
+		return err
+	})
+	if err != nil {
+		return model, wrapErr(fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err))
+	}
+
+	return model, nil
+}
+
+// Upsert creates new products and replaces existing ones in one transaction,
+// saved products have canonical names of categories
+func (r *GormRepo) Upsert(ctx context.Context, models []product.Product) ([]product.Product, error) {
+	saved := make([]product.Product, 0, len(models))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range models {
+			model, err := Save(ctx, tx, model)
+			if err != nil {
+				return err
+			}
+
+			saved = append(saved, model)
 		}
 
 		return nil
 	})
 	if err != nil {
-		return model, wrapErr(fmt.Errorf("transaction failed: %w", err))
+		return nil, wrapErr(fmt.Errorf("can't upsert %d products: %w", len(models), err))
 	}
 
-	return model, nil
+	return saved, nil
+}
+
+// Save replaces product and its associations in the transaction, the product gets canonical name of its category
+func Save(ctx context.Context, tx *gorm.DB, model product.Product) (product.Product, error) {
+	entity := ModelToEntity(model)
+
+	err := tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Model(&Product{ID: entity.ID}).
+		Association("Forms").Unscoped().Replace(entity.Forms)
+	if err != nil {
+		return model, fmt.Errorf("can't update product %s associations: %w", entity.ID, err)
+	}
+	err = tx.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Model(&Product{ID: entity.ID}).
+		Association("Aliases").Unscoped().Replace(entity.Aliases)
+	if err != nil {
+		return model, fmt.Errorf("can't update product %s aliases: %w", entity.ID, err)
+	}
+	err = tx.WithContext(ctx).Where("product_id = ?", entity.ID).Delete(new(ProductBarcode)).Error
+	if err != nil {
+		return model, fmt.Errorf("can't delete product %s barcodes: %w", entity.ID, err)
+	}
+	if err = createBarcodes(ctx, tx, entity); err != nil {
+		return model, err
+	}
+	err = tx.WithContext(ctx).Omit("Barcodes").Save(&entity).Error
+	if err != nil {
+		return model, fmt.Errorf("can't update product %s: %w", entity.ID, err)
+	}
+
+	return withCategory(model, entity), nil
+}
+
+// createBarcodes inserts barcodes of the product. Associations are saved ignoring conflicts,
+// so barcodes are inserted separately to fail when another product has taken them meanwhile.
+func createBarcodes(ctx context.Context, tx *gorm.DB, entity Product) error {
+	if len(entity.Barcodes) == 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Create(&entity.Barcodes).Error; err != nil {
+		return fmt.Errorf("can't insert product %s barcodes: %w", entity.ID, err)
+	}
+
+	return nil
+}
+
+// withCategory returns the product with name of category found by hooks of the saved entity,
+// the product is uncategorized if its category is unknown
+func withCategory(model product.Product, entity Product) product.Product {
+	model.Category = mo.None[product.Category]()
+	if entity.Category != nil {
+		model.Category = mo.Some(product.Category(entity.Category.Name))
+	}
+
+	return model
 }
 
 func (r *GormRepo) GetByID(ctx context.Context, productID id.ID[product.Product]) (product.Product, error) {
 	var entity Product
-	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
 		First(&entity, "id = ?", productID.String()).Error
 	if err != nil {
 		return product.Product{}, wrapErr(fmt.Errorf("can't get product %s: %w", productID, err))
@@ -136,7 +368,9 @@ func (r *GormRepo) GetByListID(ctx context.Context, idList []id.ID[product.Produ
 
 	uuids := lo.Map(idList, func(item id.ID[product.Product], _ int) uuid.UUID { return item.UUID })
 
-	err := r.db.WithContext(ctx).Preload("Forms").Find(&entities, uuids).Error
+	// products created in the transaction of ctx are found too
+	err := dbtx.DB(ctx, r.db).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Find(&entities, uuids).Error
 	if err != nil {
 		return nil, wrapErr(fmt.Errorf("can't select products %v: %w", idList, err))
 	}
@@ -144,19 +378,104 @@ func (r *GormRepo) GetByListID(ctx context.Context, idList []id.ID[product.Produ
 	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
 }
 
-func (r *GormRepo) Create(ctx context.Context, model product.Product) error {
+// GetByNormalizedNames returns products which normalized names are equal to one of names
+func (r *GormRepo) GetByNormalizedNames(ctx context.Context, names []string) ([]product.Product, error) {
+	var entities []Product
+
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Where("normalized_name IN ?", names).Order("created_at").Find(&entities).Error
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't select products by names %v: %w", names, err))
+	}
+
+	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
+}
+
+// GetByNameFragments returns up to limit products which normalized names contain one of fragments
+func (r *GormRepo) GetByNameFragments(ctx context.Context, fragments []string, limit int) ([]product.Product, error) {
+	var entities []Product
+
+	if len(fragments) == 0 {
+		return []product.Product{}, nil
+	}
+
+	condition := r.db.Where("normalized_name LIKE ? ESCAPE '!'", "%"+mymysql.EscapeLike(fragments[0])+"%")
+	for _, fragment := range fragments[1:] {
+		condition = condition.Or("normalized_name LIKE ? ESCAPE '!'", "%"+mymysql.EscapeLike(fragment)+"%")
+	}
+
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Where(condition).Order("created_at").Limit(limit).Find(&entities).Error
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't select products by name fragments %v: %w", fragments, err))
+	}
+
+	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
+}
+
+// GetAll returns all products, they're used to build search index
+func (r *GormRepo) GetAll(ctx context.Context) ([]product.Product, error) {
+	var entities []Product
+
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Order("created_at").Find(&entities).Error
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't select products: %w", err))
+	}
+
+	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
+}
+
+// GetUpdatedSince returns products created or updated since the given time, the oldest changes go first
+func (r *GormRepo) GetUpdatedSince(ctx context.Context, since time.Time) ([]product.Product, error) {
+	var entities []Product
+
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Where("updated_at >= ?", since.UTC()).Order("updated_at").Find(&entities).Error
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't select products updated since %s: %w", since, err))
+	}
+
+	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
+}
+
+// GetByBarcodes returns products which have one of barcodes
+func (r *GormRepo) GetByBarcodes(ctx context.Context, barcodes []product.Barcode) ([]product.Product, error) {
+	var entities []Product
+
+	codes := r.db.Model(new(ProductBarcode)).Select("product_id").Where("code IN ?", barcodes)
+
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Forms").Preload("Aliases").Preload("Barcodes").
+		Where("id IN (?)", codes).Find(&entities).Error
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't select products by barcodes %v: %w", barcodes, err))
+	}
+
+	return lo.Map(entities, func(item Product, _ int) product.Product { return EntityToModel(item) }), nil
+}
+
+// Create inserts the product, created product has canonical name of its category.
+// The product is inserted in the transaction of ctx if there is one.
+func (r *GormRepo) Create(ctx context.Context, model product.Product) (product.Product, error) {
 	entity := ModelToEntity(model)
 	log.Info().Any("model", model).Any("entity", entity).Msg("inserting new product")
-	err := r.db.WithContext(ctx).Create(&entity).Error
+	err := dbtx.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Barcodes").Create(&entity).Error; err != nil {
+			return err
+		}
+
+		return createBarcodes(ctx, tx, entity)
+	})
 	if err != nil {
-		return wrapErr(fmt.Errorf("can't update product %s: %w", model.ID, err))
+		// barcodes are primary keys, so a product can't take barcodes of another one created at the same time
+		return model, wrapErr(fmt.Errorf("%w: can't insert product %s: %w", mymysql.GetType(err), model.ID, err))
 	}
 
-	return nil
+	return withCategory(model, entity), nil
 }
 
 func (r *GormRepo) Delete(ctx context.Context, productID id.ID[product.Product]) error {
-	err := r.db.WithContext(ctx).Delete(new(Product), productID.UUID[:]).Error
+	err := r.db.WithContext(ctx).Where("id = ?", productID.String()).Delete(new(Product)).Error
 	if err != nil {
 		return wrapErr(fmt.Errorf("can't delete product %s: %w", productID, err))
 	}
@@ -177,6 +496,12 @@ func EntityToModel(entity Product) product.Product {
 			Forms: lo.Map(entity.Forms, func(item ProductForm, _ int) product.Form {
 				return product.Form(item.Name)
 			}),
+			Aliases: lo.Map(entity.Aliases, func(item ProductAlias, _ int) product.Name {
+				return product.Name(item.Name)
+			}),
+			Barcodes: lo.Map(entity.Barcodes, func(item ProductBarcode, _ int) product.Barcode {
+				return product.Barcode(item.Code)
+			}),
 		},
 		ID:        id.ID[product.Product]{UUID: god.Believe(uuid.Parse(entity.ID))},
 		CreatedAt: date.CreateDate[product.Product]{Time: entity.CreatedAt},
@@ -194,12 +519,13 @@ func ModelToEntity(model product.Product) Product {
 	}
 
 	return Product{
-		ID:         model.ID.String(),
-		CreatedAt:  model.CreatedAt.Time,
-		UpdatedAt:  model.UpdatedAt.Time,
-		Name:       string(model.Name),
-		CategoryID: sql.NullString{String: "", Valid: false}, // will be set in hooks
-		Category:   category,
+		ID:             model.ID.String(),
+		CreatedAt:      model.CreatedAt.Time,
+		UpdatedAt:      model.UpdatedAt.Time,
+		Name:           string(model.Name),
+		NormalizedName: product.NormalizeName(model.Name),
+		CategoryID:     sql.NullString{String: "", Valid: false}, // will be set in hooks
+		Category:       category,
 		Forms: lo.Map(model.Forms, func(item product.Form, _ int) ProductForm {
 			return ProductForm{
 				ProductID: model.ID.String(),
@@ -207,6 +533,19 @@ func ModelToEntity(model product.Product) Product {
 				Name:      string(item),
 			}
 		}),
+		Aliases: lo.Map(model.Aliases, func(item product.Name, _ int) ProductAlias {
+			return ProductAlias{
+				ProductID: model.ID.String(),
+				ID:        uuid.NewString(),
+				Name:      string(item),
+			}
+		}),
+		Barcodes: lo.Map(model.Barcodes, func(item product.Barcode, _ int) ProductBarcode {
+			return ProductBarcode{
+				Code:      string(item),
+				ProductID: model.ID.String(),
+			}
+		}),
 	}
 }
 
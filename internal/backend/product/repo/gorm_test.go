@@ -0,0 +1,125 @@
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/product/repo"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "product.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	return db
+}
+
+// newCategory returns category entity with the name, categories are created by the category repo in the app
+func newCategory(name string, aliases ...string) repo.ProductCategory {
+	entity := repo.ProductCategory{
+		ID:             uuid.NewString(),
+		Name:           name,
+		NormalizedName: product.NormalizeName(product.Name(name)),
+		ParentID:       sql.NullString{String: "", Valid: false},
+		Names:          []repo.ProductCategoryName{},
+	}
+
+	for _, alias := range aliases {
+		entity.Names = append(entity.Names, repo.ProductCategoryName{
+			ID:             uuid.NewString(),
+			CategoryID:     entity.ID,
+			Lang:           "",
+			Name:           alias,
+			NormalizedName: product.NormalizeName(product.Name(alias)),
+		})
+	}
+
+	return entity
+}
+
+func newProduct(name product.Name, category product.Category) product.Product {
+	return product.Product{
+		Options: product.Options{
+			Name:     name,
+			Category: mo.Some(category),
+			Forms:    []product.Form{},
+			Aliases:  []product.Name{},
+			Barcodes: []product.Barcode{},
+		},
+		ID:        id.NewID[product.Product](),
+		CreatedAt: date.NewCreateDate[product.Product](),
+		UpdatedAt: date.NewUpdateDate[product.Product](),
+	}
+}
+
+func TestCategoryOfProduct(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+
+	products, err := repo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+	dairy := newCategory("Dairy", "milk products")
+	require.NoError(t, db.Create(&dairy).Error)
+
+	kefir, err := products.Create(ctx, newProduct("kefir", "Milk Products"))
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[product.Category]("Dairy"), kefir.Category, "products get canonical names of categories")
+
+	// categories aren't created by products, e.g. by categories of imported products
+	chips, err := products.Create(ctx, newProduct("chips", "snacks"))
+	require.NoError(t, err)
+	require.Equal(t, mo.None[product.Category](), chips.Category)
+
+	chips, err = products.GetByID(ctx, chips.ID)
+	require.NoError(t, err)
+	require.Equal(t, mo.None[product.Category](), chips.Category)
+
+	var categories int64
+	require.NoError(t, db.Model(new(repo.ProductCategory)).Count(&categories).Error)
+	require.EqualValues(t, 1, categories)
+}
+
+func TestMergeDuplicatedCategories(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+
+	products, err := repo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	// old hooks created a category for every spelling
+	upper, lower := newCategory("Dairy"), newCategory("dairy", "milk products")
+	child := newCategory("cheese")
+	child.ParentID = sql.NullString{String: lower.ID, Valid: true}
+	require.NoError(t, db.Create([]*repo.ProductCategory{&upper, &lower, &child}).Error)
+
+	kefir, err := products.Create(ctx, newProduct("kefir", "milk products"))
+	require.NoError(t, err)
+
+	products, err = repo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	var categories []repo.ProductCategory
+	require.NoError(t, db.Preload("Names").Order("name").Find(&categories).Error)
+	require.Len(t, categories, 2)
+	require.Equal(t, upper.ID, categories[0].ID)
+	require.Equal(t, "milk products", categories[0].Names[0].Name)
+	require.Equal(t, sql.NullString{String: upper.ID, Valid: true}, categories[1].ParentID)
+
+	kefir, err = products.GetByID(ctx, kefir.ID)
+	require.NoError(t, err)
+	require.Equal(t, mo.Some[product.Category]("Dairy"), kefir.Category)
+}
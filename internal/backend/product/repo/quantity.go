@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/product"
+)
+
+// Quantity is a pair of nullable columns keeping optional product.Quantity
+type Quantity struct {
+	Value *float64
+	Unit  *int32
+}
+
+// QuantityToModel converts columns to quantity, legacyCount is a number of pieces stored before units were added
+func QuantityToModel(entity Quantity, legacyCount *int32) mo.Option[product.Quantity] {
+	if entity.Value == nil || entity.Unit == nil {
+		if legacyCount != nil {
+			return mo.Some(product.NewPieces(*legacyCount))
+		}
+
+		return mo.None[product.Quantity]()
+	}
+
+	return mo.Some(product.Quantity{Value: *entity.Value, Unit: product.Unit(*entity.Unit)})
+}
+
+func QuantityToEntity(model mo.Option[product.Quantity]) Quantity {
+	quantity, found := model.Get()
+	if !found {
+		return Quantity{Value: nil, Unit: nil}
+	}
+
+	unit := int32(quantity.Unit)
+
+	return Quantity{Value: &quantity.Value, Unit: &unit}
+}
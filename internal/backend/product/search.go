@@ -0,0 +1,439 @@
+package product
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+
+	"go-backend/pkg/id"
+)
+
+const (
+	// nameWeight, aliasWeight and categoryWeight are weights of words found in fields of a product
+	nameWeight     = 1.0
+	aliasWeight    = 0.8
+	categoryWeight = 0.5
+	// exactQuality, prefixQuality and typoQuality are weights of a query word equal to a word of a product,
+	// being its beginning or being similar to its beginning
+	exactQuality  = 1.0
+	prefixQuality = 0.8
+	typoQuality   = 0.5
+	// leadingBonus is added when the product name starts with the query
+	leadingBonus = 0.5
+	// scriptBonus is added when the query is typed in the script of the product name, not transliterated
+	scriptBonus = 0.2
+	// minPendingWords is a number of new words kept unsorted before they're merged into sorted ones,
+	// the number grows with the index so merges stay rare
+	minPendingWords = 1024
+	// minTypoLength is a length of query words from which one typo is tolerated,
+	// from longTypoLength on two typos are tolerated
+	minTypoLength  = 4
+	longTypoLength = 8
+)
+
+// cyrillicToLatin transliterates russian letters, so words typed in any script are compared
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// SearchHit is a product found by a query, products with higher scores match better
+type SearchHit struct {
+	Product Product `json:"product"`
+	Score   float64 `json:"score"`
+}
+
+// SearchPage is a page of found products, Total is a number of all found products
+type SearchPage struct {
+	Hits  []SearchHit `json:"hits"`
+	Total int         `json:"total"`
+}
+
+// SearchIndex finds products by beginnings of words of their names, aliases and categories.
+// Words are transliterated to latin and a few typos are tolerated, so it suits search as you type.
+// It's safe for concurrent use.
+type SearchIndex struct {
+	lock    sync.RWMutex
+	entries map[id.ID[Product]]indexEntry
+	// postings are products containing a word
+	postings map[string]map[id.ID[Product]]struct{}
+	// words are indexed words in sorted order, they're used to find words by prefix.
+	// New words are pending until there are enough of them to merge, deleted words are dropped on merge.
+	words   []string
+	pending []string
+	// bigrams are words containing a pair of letters, they're used to find words with typos
+	bigrams map[string]map[string]struct{}
+}
+
+type indexEntry struct {
+	product Product
+	// weights are the largest weights of fields where words of the product are found
+	weights map[string]float64
+	// name is a normalized name and key is its transliteration
+	name string
+	key  string
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		lock:     sync.RWMutex{},
+		entries:  make(map[id.ID[Product]]indexEntry),
+		postings: make(map[string]map[id.ID[Product]]struct{}),
+		words:    make([]string, 0),
+		pending:  make([]string, 0),
+		bigrams:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Put adds the product to the index or replaces its previous version
+func (x *SearchIndex) Put(model Product) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.delete(model.ID)
+
+	entry := indexEntry{
+		product: model,
+		weights: make(map[string]float64),
+		name:    NormalizeName(model.Name),
+		key:     searchKey(string(model.Name)),
+	}
+
+	addWords := func(text string, weight float64) {
+		for _, word := range strings.Fields(searchKey(text)) {
+			entry.weights[word] = max(entry.weights[word], weight)
+		}
+	}
+
+	addWords(string(model.Name), nameWeight)
+
+	for _, alias := range model.Aliases {
+		addWords(string(alias), aliasWeight)
+	}
+
+	if category, found := model.Category.Get(); found {
+		addWords(string(category), categoryWeight)
+	}
+
+	for word := range entry.weights {
+		x.addPosting(word, model.ID)
+	}
+
+	x.entries[model.ID] = entry
+}
+
+// Delete removes the product from the index
+func (x *SearchIndex) Delete(productID id.ID[Product]) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.delete(productID)
+}
+
+// Len returns number of indexed products
+func (x *SearchIndex) Len() int {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return len(x.entries)
+}
+
+// Search returns a page of products whose words start with every word of the query or are similar to it.
+// Products are sorted by score, shorter names go first among equally scored ones.
+func (x *SearchIndex) Search(query string, offset, limit int) SearchPage {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	queryWords := strings.Fields(searchKey(query))
+	if len(queryWords) == 0 {
+		return SearchPage{Hits: []SearchHit{}, Total: 0}
+	}
+
+	// qualities are qualities of indexed words matching each query word
+	qualities := make([]map[string]float64, 0, len(queryWords))
+	rarest := 0
+
+	for i, word := range queryWords {
+		qualities = append(qualities, x.match(word))
+
+		if x.countProducts(qualities[i]) < x.countProducts(qualities[rarest]) {
+			rarest = i
+		}
+	}
+
+	normalized, key := NormalizeName(Name(query)), strings.Join(queryWords, " ")
+	hits := make([]SearchHit, 0)
+	seen := make(map[id.ID[Product]]struct{})
+
+	for word := range qualities[rarest] {
+		for productID := range x.postings[word] {
+			if _, found := seen[productID]; found {
+				continue
+			}
+
+			seen[productID] = struct{}{}
+
+			if score, found := x.score(x.entries[productID], qualities, normalized, key); found {
+				hits = append(hits, SearchHit{Product: x.entries[productID].product, Score: score})
+			}
+		}
+	}
+
+	slices.SortFunc(hits, compareHits)
+
+	total := len(hits)
+	hits = hits[min(offset, total):min(offset+limit, total)]
+
+	return SearchPage{Hits: hits, Total: total}
+}
+
+// match returns indexed words which start with the query word or with a word similar to it and their qualities
+func (x *SearchIndex) match(queryWord string) map[string]float64 {
+	qualities := make(map[string]float64)
+
+	addPrefixed := func(word string) {
+		if _, found := x.postings[word]; !found {
+			return
+		}
+
+		if word == queryWord {
+			qualities[word] = exactQuality
+		} else {
+			qualities[word] = prefixQuality * (1 + float64(len(queryWord))/float64(len(word))) / 2
+		}
+	}
+
+	start, _ := slices.BinarySearch(x.words, queryWord)
+	for _, word := range x.words[start:] {
+		if !strings.HasPrefix(word, queryWord) {
+			break
+		}
+
+		addPrefixed(word)
+	}
+
+	for _, word := range x.pending {
+		if strings.HasPrefix(word, queryWord) {
+			addPrefixed(word)
+		}
+	}
+
+	typos := maxTypos(queryWord)
+	if typos == 0 {
+		return qualities
+	}
+
+	grams := wordBigrams(queryWord)
+
+	// a typo breaks at most two pairs of letters, so similar words share the rest of pairs
+	minShared := len(grams) - 2*typos
+	if minShared <= 0 {
+		return qualities
+	}
+
+	shared := make(map[string]int)
+	for _, gram := range grams {
+		for word := range x.bigrams[gram] {
+			shared[word]++
+		}
+	}
+
+	for word, count := range shared {
+		if _, found := qualities[word]; found || count < minShared {
+			continue
+		}
+
+		if distance := prefixDistance([]rune(queryWord), []rune(word), typos); distance <= typos {
+			qualities[word] = typoQuality / float64(distance)
+		}
+	}
+
+	return qualities
+}
+
+// score returns score of the product which must match every query word
+func (x *SearchIndex) score(entry indexEntry, qualities []map[string]float64, normalized, key string) (float64, bool) {
+	score := 0.0
+
+	for _, wordQualities := range qualities {
+		best := 0.0
+
+		for word, weight := range entry.weights {
+			best = max(best, wordQualities[word]*weight)
+		}
+
+		if best == 0 {
+			return 0, false
+		}
+
+		score += best
+	}
+
+	if strings.HasPrefix(entry.key, key) {
+		score += leadingBonus
+	}
+
+	if strings.Contains(entry.name, normalized) {
+		score += scriptBonus
+	}
+
+	return score, true
+}
+
+// compareHits puts better hits first, among equally scored hits shorter names go first
+func compareHits(a, b SearchHit) int {
+	if order := cmp.Compare(b.Score, a.Score); order != 0 {
+		return order
+	}
+
+	if order := cmp.Compare(len(a.Product.Name), len(b.Product.Name)); order != 0 {
+		return order
+	}
+
+	if order := cmp.Compare(a.Product.Name, b.Product.Name); order != 0 {
+		return order
+	}
+
+	return slices.Compare(a.Product.ID.UUID[:], b.Product.ID.UUID[:])
+}
+
+func (x *SearchIndex) countProducts(qualities map[string]float64) int {
+	count := 0
+	for word := range qualities {
+		count += len(x.postings[word])
+	}
+
+	return count
+}
+
+func (x *SearchIndex) addPosting(word string, productID id.ID[Product]) {
+	products, found := x.postings[word]
+	if !found {
+		products = make(map[id.ID[Product]]struct{})
+		x.postings[word] = products
+
+		x.pending = append(x.pending, word)
+		if len(x.pending) >= max(minPendingWords, len(x.words)/8) {
+			x.mergeWords()
+		}
+
+		for _, gram := range wordBigrams(word) {
+			if x.bigrams[gram] == nil {
+				x.bigrams[gram] = make(map[string]struct{})
+			}
+
+			x.bigrams[gram][word] = struct{}{}
+		}
+	}
+
+	products[productID] = struct{}{}
+}
+
+func (x *SearchIndex) delete(productID id.ID[Product]) {
+	entry, found := x.entries[productID]
+	if !found {
+		return
+	}
+
+	for word := range entry.weights {
+		delete(x.postings[word], productID)
+
+		if len(x.postings[word]) != 0 {
+			continue
+		}
+
+		delete(x.postings, word)
+
+		for _, gram := range wordBigrams(word) {
+			delete(x.bigrams[gram], word)
+
+			if len(x.bigrams[gram]) == 0 {
+				delete(x.bigrams, gram)
+			}
+		}
+	}
+
+	delete(x.entries, productID)
+}
+
+// mergeWords moves pending words to sorted ones and drops deleted words
+func (x *SearchIndex) mergeWords() {
+	slices.Sort(x.pending)
+
+	words := make([]string, 0, len(x.words)+len(x.pending))
+	add := func(word string) {
+		if _, found := x.postings[word]; found && (len(words) == 0 || words[len(words)-1] != word) {
+			words = append(words, word)
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x.words) || j < len(x.pending) {
+		if j == len(x.pending) || i < len(x.words) && x.words[i] < x.pending[j] {
+			add(x.words[i])
+			i++
+		} else {
+			add(x.pending[j])
+			j++
+		}
+	}
+
+	x.words = words
+	x.pending = x.pending[:0]
+}
+
+// searchKey returns normalized text transliterated to latin
+func searchKey(text string) string {
+	var builder strings.Builder
+
+	for _, r := range NormalizeName(Name(text)) {
+		if latin, found := cyrillicToLatin[r]; found {
+			builder.WriteString(latin)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// maxTypos returns number of typos tolerated in the query word
+func maxTypos(word string) int {
+	switch length := len([]rune(word)); {
+	case length >= longTypoLength:
+		return 2
+	case length >= minTypoLength:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// wordBigrams returns distinct pairs of adjacent letters of the word
+func wordBigrams(word string) []string {
+	runes := []rune(word)
+	grams := make([]string, 0, len(runes))
+
+	for i := 1; i < len(runes); i++ {
+		if gram := string(runes[i-1 : i+1]); !slices.Contains(grams, gram) {
+			grams = append(grams, gram)
+		}
+	}
+
+	return grams
+}
+
+// prefixDistance returns the smallest edit distance between query and beginnings of the word,
+// only beginnings which differ from query in length by at most limit are compared
+func prefixDistance(query, word []rune, limit int) int {
+	best := limit + 1
+
+	for length := max(len(query)-limit, 1); length <= min(len(query)+limit, len(word)); length++ {
+		best = min(best, editDistance(query, word[:length]))
+	}
+
+	return best
+}
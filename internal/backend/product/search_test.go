@@ -0,0 +1,142 @@
+package product_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/id"
+)
+
+func TestSearchIndex(t *testing.T) {
+	index := product.NewSearchIndex()
+
+	newProduct := func(name string, category string, aliases ...product.Name) product.Product {
+		model := product.Product{
+			Options: product.Options{
+				Name:     product.Name(name),
+				Category: mo.EmptyableToOption(product.Category(category)),
+				Forms:    []product.Form{},
+				Aliases:  aliases,
+			},
+			ID: id.NewID[product.Product](),
+		}
+		index.Put(model)
+
+		return model
+	}
+
+	names := func(page product.SearchPage) []product.Name {
+		return lo.Map(page.Hits, func(item product.SearchHit, _ int) product.Name { return item.Product.Name })
+	}
+
+	milk := newProduct("Молоко", "Молочные продукты", "milk")
+	newProduct("Молочный коктейль", "Молочные продукты")
+	newProduct("Кефир", "Молочные продукты")
+	newProduct("Chocolate milk", "Sweets")
+	newProduct("Шоколад", "Sweets")
+
+	require.Equal(t, []product.Name{"Молоко", "Молочный коктейль"}, names(index.Search("мол", 0, 2)))
+	require.Empty(t, index.Search(" ", 0, 10).Hits)
+
+	// transliterated, with typo and by alias
+	require.Equal(t, product.Name("Молоко"), names(index.Search("moloko", 0, 10))[0])
+	require.Equal(t, product.Name("Молоко"), names(index.Search("малоко", 0, 10))[0])
+	require.Equal(t, []product.Name{"Chocolate milk", "Молоко"}, names(index.Search("milk", 0, 10)))
+	require.Equal(t, []product.Name{"Шоколад"}, names(index.Search("shokolad", 0, 10)))
+
+	// every word must match, category words match with lower score
+	require.Equal(t, []product.Name{"Chocolate milk"}, names(index.Search("choc mil", 0, 10)))
+	require.Equal(t, 3, index.Search("молочн", 0, 10).Total)
+	require.Equal(t, product.Name("Молочный коктейль"), names(index.Search("молочн", 0, 10))[0])
+
+	page := index.Search("молочн", 1, 1)
+	require.Equal(t, 3, page.Total)
+	require.Len(t, page.Hits, 1)
+
+	milk.Name = "Сливки"
+	index.Put(milk)
+	require.Equal(t, []product.Name{"Chocolate milk", "Сливки"}, names(index.Search("milk", 0, 10)))
+	require.Empty(t, index.Search("молоко", 0, 10).Hits)
+
+	index.Delete(milk.ID)
+	require.Equal(t, 4, index.Len())
+	require.Empty(t, index.Search("сливки", 0, 10).Hits)
+}
+
+// benchmarkProducts is a size of the catalog, which the index must keep fast with
+const benchmarkProducts = 100_000
+
+// newBenchmarkIndex returns index of products with names of two or three words from a vocabulary of 5000 words
+func newBenchmarkIndex(b *testing.B) (*product.SearchIndex, []string) {
+	b.Helper()
+
+	random := rand.New(rand.NewPCG(1, 2))
+	letters := []rune("abcdefghijklmnoprstuvyz")
+	vocabulary := make([]string, 5000)
+
+	for i := range vocabulary {
+		word := make([]rune, 4+random.IntN(7))
+		for j := range word {
+			word[j] = letters[random.IntN(len(letters))]
+		}
+
+		vocabulary[i] = string(word)
+	}
+
+	index := product.NewSearchIndex()
+
+	for i := range benchmarkProducts {
+		name := fmt.Sprintf("%s %s", vocabulary[random.IntN(len(vocabulary))], vocabulary[random.IntN(len(vocabulary))])
+		if i%3 == 0 {
+			name += " " + vocabulary[random.IntN(len(vocabulary))]
+		}
+
+		index.Put(product.Product{
+			Options: product.Options{
+				Name:     product.Name(name),
+				Category: mo.Some(product.Category(vocabulary[i%50])),
+				Forms:    []product.Form{},
+				Aliases:  []product.Name{},
+			},
+			ID: id.NewID[product.Product](),
+		})
+	}
+
+	return index, vocabulary
+}
+
+func BenchmarkSearchIndex(b *testing.B) {
+	index, vocabulary := newBenchmarkIndex(b)
+	require.Equal(b, benchmarkProducts, index.Len())
+
+	word := vocabulary[100]
+	typo := word[:2] + "x" + word[3:]
+
+	for _, query := range []struct{ name, text string }{
+		{name: "prefix", text: word[:3]},
+		{name: "word", text: word},
+		{name: "typo", text: typo},
+		{name: "two words", text: word + " " + vocabulary[200][:2]},
+	} {
+		b.Run(query.name, func(b *testing.B) {
+			for b.Loop() {
+				index.Search(query.text, 0, 20)
+			}
+		})
+	}
+
+	b.Run("put", func(b *testing.B) {
+		for b.Loop() {
+			index.Put(product.Product{
+				Options: product.Options{Name: product.Name(word + " " + vocabulary[300]), Forms: []product.Form{}},
+				ID:      id.NewID[product.Product](),
+			})
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+)
+
+// Upsert saves a batch of imported products in one transaction, products are matched by barcodes.
+// Matched products get new names and categories, their forms, aliases and barcodes are extended.
+// It returns numbers of created and updated products.
+func (s *Service) Upsert(ctx context.Context, batch []product.Options) (int, int, error) {
+	var barcodes []product.Barcode
+
+	for i := range batch {
+		var err error
+		if batch[i].Barcodes, err = product.NormalizeBarcodes(batch[i].Barcodes); err != nil {
+			return 0, 0, wrapErr(err)
+		}
+
+		barcodes = append(barcodes, batch[i].Barcodes...)
+	}
+
+	existing, err := s.repo.GetByBarcodes(ctx, barcodes)
+	if err != nil {
+		return 0, 0, wrapErr(fmt.Errorf("can't get products by barcodes: %w", err))
+	}
+
+	known := make(map[id.ID[product.Product]]product.Product, len(existing))
+	owners := make(map[product.Barcode]id.ID[product.Product])
+
+	for _, model := range existing {
+		known[model.ID] = model
+
+		for _, barcode := range model.Barcodes {
+			owners[barcode] = model.ID
+		}
+	}
+
+	// updated are created and matched products of the batch
+	updated := make(map[id.ID[product.Product]]product.Product, len(batch))
+	created := 0
+
+	for _, options := range batch {
+		var model product.Product
+
+		ownerIdx := slices.IndexFunc(options.Barcodes, func(barcode product.Barcode) bool {
+			return lo.HasKey(owners, barcode)
+		})
+		if ownerIdx != -1 {
+			model = mergeImported(known[owners[options.Barcodes[ownerIdx]]], options)
+		} else {
+			model = newProduct(options)
+			created++
+		}
+
+		// barcodes of other products are left to them
+		model.Barcodes = slices.DeleteFunc(model.Barcodes, func(barcode product.Barcode) bool {
+			ownerID, found := owners[barcode]
+			return found && ownerID != model.ID
+		})
+
+		for _, barcode := range model.Barcodes {
+			owners[barcode] = model.ID
+		}
+
+		known[model.ID] = model
+		updated[model.ID] = model
+	}
+
+	saved, err := s.repo.Upsert(ctx, slices.Collect(maps.Values(updated)))
+	if err != nil {
+		return 0, 0, wrapErr(fmt.Errorf("can't save imported products: %w", err))
+	}
+
+	index := s.index.Load()
+	for _, model := range saved {
+		index.Put(model)
+	}
+
+	return created, len(updated) - created, nil
+}
+
+func newProduct(options product.Options) product.Product {
+	return product.Product{
+		Options:   options,
+		ID:        id.NewID[product.Product](),
+		CreatedAt: date.NewCreateDate[product.Product](),
+		UpdatedAt: date.NewUpdateDate[product.Product](),
+	}
+}
+
+// mergeImported replaces name and category of the product by imported ones and adds new forms, aliases and barcodes
+func mergeImported(model product.Product, options product.Options) product.Product {
+	if product.NormalizeName(model.Name) != product.NormalizeName(options.Name) &&
+		!slices.Contains(options.Aliases, model.Name) {
+		options.Aliases = append(options.Aliases, model.Name)
+	}
+
+	model.Name = options.Name
+	if options.Category.IsPresent() {
+		model.Category = options.Category
+	}
+
+	model.Forms = appendMissing(model.Forms, options.Forms)
+	model.Aliases = slices.DeleteFunc(appendMissing(model.Aliases, options.Aliases), func(alias product.Name) bool {
+		return alias == model.Name
+	})
+	model.Barcodes = appendMissing(model.Barcodes, options.Barcodes)
+	model.UpdatedAt = date.NewUpdateDate[product.Product]()
+
+	return model
+}
+
+func appendMissing[T comparable](items, added []T) []T {
+	for _, item := range added {
+		if !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -3,17 +3,31 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/mo"
 
 	"go-backend/internal/backend/product"
 	"go-backend/pkg/date"
+	"go-backend/pkg/dbtx"
 	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
 )
 
 type repo interface {
 	GetByListID(context.Context, []id.ID[product.Product]) ([]product.Product, error)
 	GetByID(context.Context, id.ID[product.Product]) (product.Product, error)
-	Create(context.Context, product.Product) error
+	GetByNormalizedNames(context.Context, []string) ([]product.Product, error)
+	GetByNameFragments(context.Context, []string, int) ([]product.Product, error)
+	GetAll(context.Context) ([]product.Product, error)
+	GetUpdatedSince(context.Context, time.Time) ([]product.Product, error)
+	GetByBarcodes(context.Context, []product.Barcode) ([]product.Product, error)
+	Upsert(context.Context, []product.Product) ([]product.Product, error)
+	Create(context.Context, product.Product) (product.Product, error)
 	GetAndUpdate(
 		context.Context,
 		id.ID[product.Product],
@@ -21,22 +35,29 @@ type repo interface {
 	) (product.Product, error)
 }
 
+// Service keeps search index of all products in memory. Products changed by other replicas
+// get to the index by RunIndexRefresh, the index is swapped as a whole when it's reloaded.
 type Service struct {
-	repo repo
-	lock sync.RWMutex
+	repo  repo
+	index atomic.Pointer[product.SearchIndex]
+	// syncLock guards syncedAt, the latest update time of products put to the index from the database
+	syncLock sync.Mutex
+	syncedAt time.Time
 }
 
 func NewService(repo repo) *Service {
-	return &Service{
-		repo: repo,
-		lock: sync.RWMutex{},
+	s := &Service{
+		repo:     repo,
+		index:    atomic.Pointer[product.SearchIndex]{},
+		syncLock: sync.Mutex{},
+		syncedAt: time.Time{},
 	}
+	s.index.Store(product.NewSearchIndex())
+
+	return s
 }
 
 func (s *Service) ID(ctx context.Context, productID id.ID[product.Product]) (product.Product, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
 	model, err := s.repo.GetByID(ctx, productID)
 	if err != nil {
 		return model, wrapErr(fmt.Errorf("can't get product %s: %w", productID, err))
@@ -46,9 +67,6 @@ func (s *Service) ID(ctx context.Context, productID id.ID[product.Product]) (pro
 }
 
 func (s *Service) IDList(ctx context.Context, ids []id.ID[product.Product]) ([]product.Product, error) {
-	s.lock.RLock()
-	defer s.lock.RUnlock()
-
 	models, err := s.repo.GetByListID(ctx, ids)
 	if err != nil {
 		return nil, wrapErr(fmt.Errorf("can't get products %v: %w", ids, err))
@@ -58,8 +76,10 @@ func (s *Service) IDList(ctx context.Context, ids []id.ID[product.Product]) ([]p
 }
 
 func (s *Service) Create(ctx context.Context, options product.Options) (product.Product, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	var err error
+	if options.Barcodes, err = s.checkBarcodes(ctx, id.ID[product.Product]{}, options.Barcodes); err != nil {
+		return product.Product{}, err
+	}
 
 	full := product.Product{
 		Options:   options,
@@ -68,10 +88,13 @@ func (s *Service) Create(ctx context.Context, options product.Options) (product.
 		UpdatedAt: date.NewUpdateDate[product.Product](),
 	}
 
-	if err := s.repo.Create(ctx, full); err != nil {
+	if full, err = s.repo.Create(ctx, full); err != nil {
 		return product.Product{}, wrapErr(fmt.Errorf("can't create product: %w", err))
 	}
 
+	// product created in a transaction of ctx isn't searchable until the transaction is committed
+	dbtx.AfterCommit(ctx, func() { s.index.Load().Put(full) })
+
 	return full, nil
 }
 
@@ -79,20 +102,264 @@ func (s *Service) Update(ctx context.Context, productID id.ID[product.Product],
 	product.Product,
 	error,
 ) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	var err error
+	if options.Barcodes, err = s.checkBarcodes(ctx, productID, options.Barcodes); err != nil {
+		return product.Product{}, err
+	}
 
 	model, err := s.repo.GetAndUpdate(ctx, productID, func(p product.Product) (product.Product, error) {
 		p.Options = options
+		p.UpdatedAt = date.NewUpdateDate[product.Product]()
+
 		return p, nil
 	})
 	if err != nil {
 		return model, wrapErr(fmt.Errorf("can't update product: %w", err))
 	}
 
+	s.index.Load().Put(model)
+
 	return model, nil
 }
 
+// Barcode returns product with the barcode, the barcode may be EAN-13, UPC-A or EAN-8
+func (s *Service) Barcode(ctx context.Context, code string) (product.Product, error) {
+	barcode, err := product.ParseBarcode(code)
+	if err != nil {
+		return product.Product{}, wrapErr(err)
+	}
+
+	models, err := s.repo.GetByBarcodes(ctx, []product.Barcode{barcode})
+	if err != nil {
+		return product.Product{}, wrapErr(fmt.Errorf("can't get product by barcode %s: %w", barcode, err))
+	}
+
+	if len(models) == 0 {
+		return product.Product{}, wrapErr(fmt.Errorf("%w: no product with barcode %s", myerr.ErrNotFound, barcode))
+	}
+
+	return models[0], nil
+}
+
+// checkBarcodes normalizes barcodes of the product, they must not belong to other products
+func (s *Service) checkBarcodes(
+	ctx context.Context,
+	productID id.ID[product.Product],
+	barcodes []product.Barcode,
+) (
+	[]product.Barcode,
+	error,
+) {
+	barcodes, err := product.NormalizeBarcodes(barcodes)
+	if err != nil || len(barcodes) == 0 {
+		return barcodes, wrapErr(err)
+	}
+
+	owners, err := s.repo.GetByBarcodes(ctx, barcodes)
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't get products by barcodes: %w", err))
+	}
+
+	for _, owner := range owners {
+		if owner.ID != productID {
+			return nil, wrapErr(fmt.Errorf("%w: some barcodes belong to product %s", myerr.ErrAlreadyExists, owner.ID))
+		}
+	}
+
+	return barcodes, nil
+}
+
+// Reindex puts the changed product into the search index and removes deleted products from it
+func (s *Service) Reindex(model product.Product, deleted []id.ID[product.Product]) {
+	index := s.index.Load()
+
+	for _, productID := range deleted {
+		index.Delete(productID)
+	}
+
+	index.Put(model)
+}
+
+const (
+	// defaultSearchLimit and maxSearchLimit are default and maximal sizes of pages of found products
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	// refreshInterval is how often products changed by other replicas are put to the index
+	refreshInterval = 30 * time.Second
+	// refreshOverlap is subtracted from the sync time, so products saved by transactions committed late
+	// or by replicas with clocks behind are put to the index too
+	refreshOverlap = time.Minute
+	// reloadInterval is how often the index is loaded anew, so products deleted by other replicas are dropped
+	reloadInterval = time.Hour
+)
+
+// LoadIndex fills a new search index with all products and replaces the current one by it,
+// it must be called before search is used
+func (s *Service) LoadIndex(ctx context.Context) error {
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+
+	models, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return wrapErr(fmt.Errorf("can't get products for search index: %w", err))
+	}
+
+	index := product.NewSearchIndex()
+	for _, model := range models {
+		index.Put(model)
+		s.syncedAt = later(s.syncedAt, model.UpdatedAt.Time)
+	}
+
+	s.index.Store(index)
+
+	return nil
+}
+
+// RefreshIndex puts products changed since the last sync to the index and returns their number
+func (s *Service) RefreshIndex(ctx context.Context) (int, error) {
+	s.syncLock.Lock()
+	defer s.syncLock.Unlock()
+
+	models, err := s.repo.GetUpdatedSince(ctx, s.syncedAt.Add(-refreshOverlap))
+	if err != nil {
+		return 0, wrapErr(fmt.Errorf("can't get changed products for search index: %w", err))
+	}
+
+	index := s.index.Load()
+	for _, model := range models {
+		index.Put(model)
+		s.syncedAt = later(s.syncedAt, model.UpdatedAt.Time)
+	}
+
+	return len(models), nil
+}
+
+// RunIndexRefresh keeps the index in sync with products saved by other replicas until ctx is done
+func (s *Service) RunIndexRefresh(ctx context.Context) {
+	refresh := time.NewTicker(refreshInterval)
+	defer refresh.Stop()
+
+	reload := time.NewTicker(reloadInterval)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			if _, err := s.RefreshIndex(ctx); err != nil {
+				log.Error().Err(err).Msg("can't refresh search index")
+			}
+		case <-reload.C:
+			if err := s.LoadIndex(ctx); err != nil {
+				log.Error().Err(err).Msg("can't reload search index")
+			}
+		}
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+
+	return a
+}
+
+// Search returns a page of products whose names, aliases or categories match the query,
+// the query may be a beginning of words, contain typos or be typed in another script.
+// Zero limit means the default page size.
+func (s *Service) Search(query string, offset, limit int) (product.SearchPage, error) {
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	if offset < 0 || limit < 0 || limit > maxSearchLimit {
+		return product.SearchPage{}, wrapErr(fmt.Errorf(
+			"%w: offset must not be negative and limit must be between 1 and %d",
+			myerr.ErrInvalidArgument,
+			maxSearchLimit,
+		))
+	}
+
+	return s.index.Load().Search(query, offset, limit), nil
+}
+
+const (
+	// minMatchConfidence is a minimal similarity of names of not exactly matched product
+	minMatchConfidence = 0.7
+	// fragmentLength is a length of word prefixes used to find candidates for not exactly matched names
+	fragmentLength = 3
+	// maxMatchCandidates is a maximal number of candidates compared with not exactly matched name
+	maxMatchCandidates = 50
+)
+
+// Match finds products by names, products with equal normalized names have confidence 1,
+// otherwise the most similar product is matched
+func (s *Service) Match(ctx context.Context, names []product.Name) ([]product.Match, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, product.NormalizeName(name))
+	}
+
+	exact, err := s.repo.GetByNormalizedNames(ctx, normalized)
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't get products by names: %w", err))
+	}
+
+	byName := make(map[string]product.Product, len(exact))
+	for _, model := range exact {
+		if _, found := byName[product.NormalizeName(model.Name)]; !found {
+			byName[product.NormalizeName(model.Name)] = model
+		}
+	}
+
+	matches := make([]product.Match, 0, len(names))
+
+	for _, name := range normalized {
+		if model, found := byName[name]; found {
+			matches = append(matches, product.Match{Product: mo.Some(model), Confidence: 1})
+			continue
+		}
+
+		match, err := s.matchSimilar(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// matchSimilar returns the most similar product which names contain beginnings of words of name
+func (s *Service) matchSimilar(ctx context.Context, name string) (product.Match, error) {
+	var fragments []string
+
+	for _, word := range strings.Fields(name) {
+		if runes := []rune(word); len(runes) >= fragmentLength {
+			fragments = append(fragments, string(runes[:fragmentLength]))
+		}
+	}
+
+	candidates, err := s.repo.GetByNameFragments(ctx, fragments, maxMatchCandidates)
+	if err != nil {
+		return product.Match{}, wrapErr(fmt.Errorf("can't get products similar to %q: %w", name, err))
+	}
+
+	best := product.Match{Product: mo.None[product.Product](), Confidence: 0}
+
+	for _, candidate := range candidates {
+		confidence := product.NameSimilarity(name, product.NormalizeName(candidate.Name))
+		if confidence >= minMatchConfidence && confidence > best.Confidence {
+			best = product.Match{Product: mo.Some(candidate), Confidence: confidence}
+		}
+	}
+
+	return best, nil
+}
+
 func wrapErr(err error) error {
 	if err != nil {
 		return fmt.Errorf("product service: %w", err)
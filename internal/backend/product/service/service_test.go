@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/product/service"
+)
+
+// newReplicas returns services of two replicas sharing the database, their indexes are loaded
+func newReplicas(t *testing.T) (*repo.GormRepo, *service.Service, *service.Service) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "product.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	products, err := repo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	first, second := service.NewService(products), service.NewService(products)
+	require.NoError(t, first.LoadIndex(ctx))
+	require.NoError(t, second.LoadIndex(ctx))
+
+	return products, first, second
+}
+
+func newOptions(name product.Name, barcodes ...product.Barcode) product.Options {
+	return product.Options{
+		Name:     name,
+		Category: mo.None[product.Category](),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: barcodes,
+	}
+}
+
+func names(page product.SearchPage) []product.Name {
+	return lo.Map(page.Hits, func(item product.SearchHit, _ int) product.Name { return item.Product.Name })
+}
+
+func TestRefreshIndex(t *testing.T) {
+	ctx := context.Background()
+	products, first, second := newReplicas(t)
+
+	milk, err := first.Create(ctx, newOptions("milk"))
+	require.NoError(t, err)
+
+	page, err := second.Search("milk", 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, page.Hits, "the product is created by another replica")
+
+	refreshed, err := second.RefreshIndex(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, refreshed)
+
+	page, err = second.Search("milk", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []product.Name{"milk"}, names(page))
+
+	_, err = first.Update(ctx, milk.ID, newOptions("kefir"))
+	require.NoError(t, err)
+
+	_, err = second.RefreshIndex(ctx)
+	require.NoError(t, err)
+
+	page, err = second.Search("kefir", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []product.Name{"kefir"}, names(page))
+
+	// deleted products are dropped when the index is reloaded
+	require.NoError(t, products.Delete(ctx, milk.ID))
+	require.NoError(t, second.LoadIndex(ctx))
+
+	page, err = second.Search("kefir", 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, page.Hits)
+}
+
+func TestCreateConcurrently(t *testing.T) {
+	ctx := context.Background()
+	_, first, second := newReplicas(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s := lo.Ternary(i%2 == 0, first, second)
+			_, errs[i] = s.Create(ctx, newOptions(product.Name("bread"), "4006381333931"))
+		}()
+	}
+
+	wg.Wait()
+
+	// products are created without a lock of the service, but only one of them gets the barcode
+	created := lo.CountBy(errs, func(err error) bool { return err == nil })
+	require.Equal(t, 1, created)
+
+	found, err := first.Barcode(ctx, "4006381333931")
+	require.NoError(t, err)
+	require.Equal(t, product.Name("bread"), found.Name)
+}
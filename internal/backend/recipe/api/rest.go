@@ -0,0 +1,302 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/recipe"
+	"go-backend/internal/backend/recipe/service"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	log = log.With().Str("component", "recipe rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	recipes := r.Group("/recipes")
+	recipes.POST("", h.CreateRecipe)
+	recipes.GET("", h.GetRecipes)
+	recipes.GET("/:id", h.GetRecipe)
+	recipes.PUT("/:id", h.UpdateRecipe)
+	recipes.DELETE("/:id", h.DeleteRecipe)
+
+	plans := r.Group("/mealplans")
+	plans.POST("", h.CreateMealPlan)
+	plans.GET("", h.GetMealPlans)
+	plans.GET("/:id", h.GetMealPlan)
+	plans.PUT("/:id", h.UpdateMealPlan)
+	plans.DELETE("/:id", h.DeleteMealPlan)
+	plans.POST("/:id/to-list", h.ToList)
+}
+
+// @Summary create new recipe
+// @ID recipe-create
+// @Tags Recipes
+// @Param body body recipe.Options true "title, servings, instructions and ingredients"
+// @Produce json
+// @Accept json
+// @Router /recipes [post]
+// @Security ApiKeyAuth
+func (h *Handler) CreateRecipe(ctx *gin.Context) {
+	var options recipe.Options
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.CreateRecipe(ctx, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get recipes of the user sorted by title
+// @ID recipes-get
+// @Tags Recipes
+// @Produce json
+// @Router /recipes [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetRecipes(ctx *gin.Context) {
+	recipes, err := h.service.GetRecipes(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipes)
+}
+
+// @Summary get recipe, with servings its ingredients are scaled to them
+// @ID recipe-get-by-id
+// @Tags Recipes
+// @Param id path string true "recipe id"
+// @Param servings query int false "number of servings, servings of the recipe by default"
+// @Produce json
+// @Router /recipes/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetRecipe(ctx *gin.Context) {
+	recipeID, ok := rerr.PathID[recipe.Recipe](ctx)
+	if !ok {
+		return
+	}
+
+	servings, ok := rerr.QueryInt(ctx, "servings", 0)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.GetRecipe(ctx, recipeID, api.GetUserID(ctx), servings)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary update recipe
+// @ID recipe-update
+// @Tags Recipes
+// @Param id path string true "recipe id"
+// @Param body body recipe.Options true "new options of recipe"
+// @Produce json
+// @Accept json
+// @Router /recipes/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) UpdateRecipe(ctx *gin.Context) {
+	var options recipe.Options
+
+	recipeID, ok := rerr.PathID[recipe.Recipe](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.UpdateRecipe(ctx, recipeID, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete recipe, its meals are deleted from meal plans
+// @ID recipe-delete
+// @Tags Recipes
+// @Param id path string true "recipe id"
+// @Router /recipes/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteRecipe(ctx *gin.Context) {
+	recipeID, ok := rerr.PathID[recipe.Recipe](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRecipe(ctx, recipeID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary create meal plan of a week
+// @ID mealplan-create
+// @Tags MealPlans
+// @Param body body recipe.MealPlanOptions true "title, week and meals"
+// @Produce json
+// @Accept json
+// @Router /mealplans [post]
+// @Security ApiKeyAuth
+func (h *Handler) CreateMealPlan(ctx *gin.Context) {
+	var options recipe.MealPlanOptions
+
+	if ok := h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.CreateMealPlan(ctx, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary get meal plans of the user, the latest weeks go first
+// @ID mealplans-get
+// @Tags MealPlans
+// @Produce json
+// @Router /mealplans [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetMealPlans(ctx *gin.Context) {
+	plans, err := h.service.GetMealPlans(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plans)
+}
+
+// @Summary get meal plan by id
+// @ID mealplan-get-by-id
+// @Tags MealPlans
+// @Param id path string true "meal plan id"
+// @Produce json
+// @Router /mealplans/{id} [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetMealPlan(ctx *gin.Context) {
+	planID, ok := rerr.PathID[recipe.MealPlan](ctx)
+	if !ok {
+		return
+	}
+
+	model, err := h.service.GetMealPlan(ctx, planID, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary update meal plan
+// @ID mealplan-update
+// @Tags MealPlans
+// @Param id path string true "meal plan id"
+// @Param body body recipe.MealPlanOptions true "new options of meal plan"
+// @Produce json
+// @Accept json
+// @Router /mealplans/{id} [put]
+// @Security ApiKeyAuth
+func (h *Handler) UpdateMealPlan(ctx *gin.Context) {
+	var options recipe.MealPlanOptions
+
+	planID, ok := rerr.PathID[recipe.MealPlan](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.UpdateMealPlan(ctx, planID, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
+
+// @Summary delete meal plan
+// @ID mealplan-delete
+// @Tags MealPlans
+// @Param id path string true "meal plan id"
+// @Router /mealplans/{id} [delete]
+// @Security ApiKeyAuth
+func (h *Handler) DeleteMealPlan(ctx *gin.Context) {
+	planID, ok := rerr.PathID[recipe.MealPlan](ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteMealPlan(ctx, planID, api.GetUserID(ctx)); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary add ingredients of all meals of the plan to the list, quantities of the same product are summed
+// @ID mealplan-to-list
+// @Tags MealPlans
+// @Param id path string true "meal plan id"
+// @Param body body recipe.ToListOptions true "list where ingredients are added"
+// @Produce json
+// @Accept json
+// @Router /mealplans/{id}/to-list [post]
+// @Security ApiKeyAuth
+func (h *Handler) ToList(ctx *gin.Context) {
+	var options recipe.ToListOptions
+
+	planID, ok := rerr.PathID[recipe.MealPlan](ctx)
+	if !ok {
+		return
+	}
+
+	if ok = h.Decode(ctx, &options); !ok {
+		return
+	}
+
+	model, err := h.service.ToList(ctx, planID, api.GetUserID(ctx), options)
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model)
+}
@@ -0,0 +1,179 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package recipe
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SlotBreakfast is a Slot of type Breakfast.
+	SlotBreakfast Slot = iota + 1
+	// SlotLunch is a Slot of type Lunch.
+	SlotLunch
+	// SlotDinner is a Slot of type Dinner.
+	SlotDinner
+	// SlotSnack is a Slot of type Snack.
+	SlotSnack
+)
+
+var ErrInvalidSlot = fmt.Errorf("not a valid Slot, try [%s]", strings.Join(_SlotNames, ", "))
+
+const _SlotName = "breakfastlunchdinnersnack"
+
+var _SlotNames = []string{
+	_SlotName[0:9],
+	_SlotName[9:14],
+	_SlotName[14:20],
+	_SlotName[20:25],
+}
+
+// SlotNames returns a list of possible string values of Slot.
+func SlotNames() []string {
+	tmp := make([]string, len(_SlotNames))
+	copy(tmp, _SlotNames)
+	return tmp
+}
+
+// SlotValues returns a list of the values for Slot
+func SlotValues() []Slot {
+	return []Slot{
+		SlotBreakfast,
+		SlotLunch,
+		SlotDinner,
+		SlotSnack,
+	}
+}
+
+var _SlotMap = map[Slot]string{
+	SlotBreakfast: _SlotName[0:9],
+	SlotLunch:     _SlotName[9:14],
+	SlotDinner:    _SlotName[14:20],
+	SlotSnack:     _SlotName[20:25],
+}
+
+// String implements the Stringer interface.
+func (x Slot) String() string {
+	if str, ok := _SlotMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Slot(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Slot) IsValid() bool {
+	_, ok := _SlotMap[x]
+	return ok
+}
+
+var _SlotValue = map[string]Slot{
+	_SlotName[0:9]:   SlotBreakfast,
+	_SlotName[9:14]:  SlotLunch,
+	_SlotName[14:20]: SlotDinner,
+	_SlotName[20:25]: SlotSnack,
+}
+
+// ParseSlot attempts to convert a string to a Slot.
+func ParseSlot(name string) (Slot, error) {
+	if x, ok := _SlotValue[name]; ok {
+		return x, nil
+	}
+	return Slot(0), fmt.Errorf("%s is %w", name, ErrInvalidSlot)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Slot) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Slot) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseSlot(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errSlotNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *Slot) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = Slot(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = Slot(v)
+	case string:
+		*x, err = ParseSlot(v)
+	case []byte:
+		*x, err = ParseSlot(string(v))
+	case Slot:
+		*x = v
+	case int:
+		*x = Slot(v)
+	case *Slot:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = Slot(v)
+	case uint64:
+		*x = Slot(v)
+	case *int:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = Slot(*v)
+	case *int64:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = Slot(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = Slot(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = Slot(*v)
+	case *uint:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = Slot(*v)
+	case *uint64:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x = Slot(*v)
+	case *string:
+		if v == nil {
+			return errSlotNilPtr
+		}
+		*x, err = ParseSlot(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x Slot) Value() (driver.Value, error) {
+	return x.String(), nil
+}
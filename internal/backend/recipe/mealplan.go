@@ -0,0 +1,104 @@
+package recipe
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+//go:generate python $GOENUM
+
+// ENUM(breakfast=1, lunch, dinner, snack)
+type Slot int32
+
+// Meal is a recipe cooked for a number of servings on a day of the plan week
+type Meal struct {
+	RecipeID id.ID[Recipe] `json:"recipe_id" swaggertype:"string"`
+	// Day is a day of the week starting from 0 for Monday
+	Day      int  `json:"day" validate:"min=0,max=6"`
+	Slot     Slot `json:"slot" swaggertype:"string"`
+	Servings int  `json:"servings" validate:"min=1,max=100"`
+}
+
+// MealPlanOptions are editable options of a meal plan, WeekStart is moved to Monday of its week
+type MealPlanOptions struct {
+	Title     string    `json:"title" validate:"max=255"`
+	WeekStart time.Time `json:"week_start"`
+	Meals     []Meal    `json:"meals" validate:"dive"`
+}
+
+// MealPlan is a calendar of meals cooked in a week
+type MealPlan struct {
+	MealPlanOptions
+
+	ID        id.ID[MealPlan]  `json:"id" swaggertype:"string"`
+	OwnerID   id.ID[user.User] `json:"owner_id" swaggertype:"string"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// ToListOptions choose a list where ingredients of a meal plan are added
+type ToListOptions struct {
+	ListID id.ID[list.ProductList] `json:"list_id" swaggertype:"string"`
+}
+
+// CheckOwner returns error if the user doesn't own the meal plan
+func (p MealPlan) CheckOwner(userID id.ID[user.User]) error {
+	if p.OwnerID != userID {
+		return fmt.Errorf("%w: meal plan %s belongs to another user", myerr.ErrForbidden, p.ID)
+	}
+
+	return nil
+}
+
+// RecipeIDs returns recipes cooked in the plan without repeats
+func (o MealPlanOptions) RecipeIDs() []id.ID[Recipe] {
+	return lo.Uniq(lo.Map(o.Meals, func(item Meal, _ int) id.ID[Recipe] { return item.RecipeID }))
+}
+
+// Ingredients sums ingredients of all meals of the plan scaled to servings of the meals.
+// Products counted in pieces or packs are rounded up, as they're bought whole.
+// A product measured in incompatible units in different recipes can't be summed, it's an error.
+func (p MealPlan) Ingredients(recipes []Recipe) (map[id.ID[product.Product]]product.Quantity, error) {
+	sums := make(map[id.ID[product.Product]]product.Quantity)
+
+	for _, meal := range p.Meals {
+		idx := slices.IndexFunc(recipes, func(r Recipe) bool { return r.ID == meal.RecipeID })
+		if idx == -1 {
+			return nil, fmt.Errorf("%w: recipe %s", myerr.ErrNotFound, meal.RecipeID)
+		}
+
+		for _, ingredient := range recipes[idx].Scale(meal.Servings).Ingredients {
+			sum, found := sums[ingredient.ProductID]
+			if !found {
+				sums[ingredient.ProductID] = ingredient.Quantity
+				continue
+			}
+
+			sum, err := sum.Add(ingredient.Quantity)
+			if err != nil {
+				return nil, fmt.Errorf("%w: product %s is measured in incompatible units: %w",
+					myerr.ErrInvalidArgument, ingredient.ProductID, err)
+			}
+
+			sums[ingredient.ProductID] = sum
+		}
+	}
+
+	for productID, sum := range sums {
+		if sum.Unit == product.UnitPieces || sum.Unit == product.UnitPacks {
+			sums[productID] = product.Quantity{Value: math.Ceil(sum.Value), Unit: sum.Unit}
+		}
+	}
+
+	return sums, nil
+}
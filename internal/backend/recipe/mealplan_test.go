@@ -0,0 +1,66 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/recipe"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func TestIngredients(t *testing.T) {
+	flour, eggs, milk := id.NewID[product.Product](), id.NewID[product.Product](), id.NewID[product.Product]()
+
+	pancakes := recipe.Recipe{
+		Options: recipe.Options{
+			Servings: 4,
+			Ingredients: []recipe.Ingredient{
+				{ProductID: flour, Quantity: product.Quantity{Value: 200, Unit: product.UnitG}},
+				{ProductID: eggs, Quantity: product.NewPieces(2)},
+				{ProductID: milk, Quantity: product.Quantity{Value: 0.5, Unit: product.UnitL}},
+			},
+		},
+		ID: id.NewID[recipe.Recipe](),
+	}
+	omelette := recipe.Recipe{
+		Options: recipe.Options{
+			Servings: 1,
+			Ingredients: []recipe.Ingredient{
+				{ProductID: eggs, Quantity: product.NewPieces(2)},
+				{ProductID: milk, Quantity: product.Quantity{Value: 50, Unit: product.UnitMl}},
+			},
+		},
+		ID: id.NewID[recipe.Recipe](),
+	}
+
+	plan := recipe.MealPlan{
+		MealPlanOptions: recipe.MealPlanOptions{
+			Meals: []recipe.Meal{
+				{RecipeID: pancakes.ID, Day: 0, Slot: recipe.SlotBreakfast, Servings: 2},
+				{RecipeID: omelette.ID, Day: 1, Slot: recipe.SlotBreakfast, Servings: 1},
+				{RecipeID: pancakes.ID, Day: 5, Slot: recipe.SlotBreakfast, Servings: 3},
+			},
+		},
+	}
+
+	require.Len(t, plan.RecipeIDs(), 2)
+
+	ingredients, err := plan.Ingredients([]recipe.Recipe{pancakes, omelette})
+	require.NoError(t, err)
+	require.Equal(t, map[id.ID[product.Product]]product.Quantity{
+		flour: {Value: 250, Unit: product.UnitG},
+		eggs:  product.NewPieces(5),
+		milk:  {Value: 0.675, Unit: product.UnitL},
+	}, ingredients)
+
+	omelette.Ingredients[1].Quantity = product.NewPieces(1)
+
+	_, err = plan.Ingredients([]recipe.Recipe{pancakes, omelette})
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+
+	_, err = plan.Ingredients([]recipe.Recipe{pancakes})
+	require.ErrorIs(t, err, myerr.ErrNotFound)
+}
@@ -0,0 +1,59 @@
+package recipe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// Ingredient is an amount of a product needed for a recipe
+type Ingredient struct {
+	ProductID id.ID[product.Product] `json:"product_id" swaggertype:"string"`
+	Quantity  product.Quantity       `json:"quantity"`
+	Note      string                 `json:"note" validate:"max=255"`
+}
+
+// Options are editable options of a recipe, quantities of ingredients are given for Servings portions
+type Options struct {
+	Title        string       `json:"title" validate:"required,max=255"`
+	Servings     int          `json:"servings" validate:"min=1,max=100"`
+	Instructions string       `json:"instructions" validate:"max=16384"`
+	Ingredients  []Ingredient `json:"ingredients" validate:"unique=ProductID,dive"`
+}
+
+type Recipe struct {
+	Options
+
+	ID        id.ID[Recipe]    `json:"id" swaggertype:"string"`
+	OwnerID   id.ID[user.User] `json:"owner_id" swaggertype:"string"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CheckOwner returns error if the user doesn't own the recipe
+func (r Recipe) CheckOwner(userID id.ID[user.User]) error {
+	if r.OwnerID != userID {
+		return fmt.Errorf("%w: recipe %s belongs to another user", myerr.ErrForbidden, r.ID)
+	}
+
+	return nil
+}
+
+// Scale returns the recipe with quantities of ingredients for the given number of servings
+func (r Recipe) Scale(servings int) Recipe {
+	factor := float64(servings) / float64(r.Servings)
+
+	r.Ingredients = lo.Map(r.Ingredients, func(item Ingredient, _ int) Ingredient {
+		item.Quantity = item.Quantity.Scale(factor)
+		return item
+	})
+	r.Servings = servings
+
+	return r
+}
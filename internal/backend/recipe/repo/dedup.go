@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"go-backend/internal/backend/dedup"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/mymysql"
+)
+
+// MergeProducts replaces sources by the target in ingredients of recipes, it joins the transaction of ctx.
+// Ingredients of merged products in the same recipe are merged into one.
+func (r *Repo) MergeProducts(ctx context.Context, merge dedup.Merge) error {
+	m := productRepo.NewMerged(merge)
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		ingredients, err := productRepo.LoadMerged[RecipeIngredient](ctx, tx, m, "recipe_id", "`index`")
+		if err != nil {
+			return err
+		}
+
+		kept, _ := productRepo.CombineMerged(
+			ingredients,
+			m,
+			func(item *RecipeIngredient) (string, *string) { return item.RecipeID, &item.ProductID },
+			func(kept, other RecipeIngredient) RecipeIngredient {
+				kept.Quantity = productRepo.SumMerged(kept.Quantity, other.Quantity)
+				return kept
+			},
+		)
+
+		return productRepo.ReplaceMerged(ctx, tx, m, "recipe_id", kept, func(item RecipeIngredient) string {
+			return item.RecipeID
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,448 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	"go-backend/internal/backend/recipe"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/dbtx"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+	"go-backend/pkg/mymysql"
+)
+
+type Recipe struct {
+	ID           string             `gorm:"primaryKey;size:36;notNull"`
+	OwnerID      string             `gorm:"size:36;notNull;index"`
+	Title        string             `gorm:"notNull;size:255"`
+	Servings     int                `gorm:"notNull"`
+	Instructions string             `gorm:"notNull;default:''"`
+	CreatedAt    time.Time          `gorm:"notNull"`
+	UpdatedAt    time.Time          `gorm:"notNull"`
+	Ingredients  []RecipeIngredient `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+}
+
+type RecipeIngredient struct {
+	RecipeID  string               `gorm:"primaryKey;size:36;notNull"`
+	ProductID string               `gorm:"primaryKey;size:36;notNull"`
+	Index     int                  `gorm:"notNull"`
+	Quantity  productRepo.Quantity `gorm:"embedded;embeddedPrefix:quantity_"`
+	Note      string               `gorm:"notNull;default:''"`
+}
+
+type MealPlan struct {
+	ID        string         `gorm:"primaryKey;size:36;notNull"`
+	OwnerID   string         `gorm:"size:36;notNull;index"`
+	Title     string         `gorm:"notNull;size:255"`
+	WeekStart time.Time      `gorm:"notNull;index"`
+	CreatedAt time.Time      `gorm:"notNull"`
+	UpdatedAt time.Time      `gorm:"notNull"`
+	Meals     []MealPlanMeal `gorm:"foreignKey:MealPlanID;constraint:OnDelete:CASCADE"`
+}
+
+type MealPlanMeal struct {
+	ID         string `gorm:"primaryKey;size:36;notNull"`
+	MealPlanID string `gorm:"size:36;notNull;index"`
+	RecipeID   string `gorm:"size:36;notNull;index"`
+	Index      int    `gorm:"notNull"`
+	Day        int    `gorm:"notNull"`
+	Slot       int32  `gorm:"notNull"`
+	Servings   int    `gorm:"notNull"`
+}
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(ctx context.Context, db *gorm.DB) (*Repo, error) {
+	err := db.WithContext(ctx).AutoMigrate(new(Recipe), new(RecipeIngredient), new(MealPlan), new(MealPlanMeal))
+	if err != nil {
+		return nil, fmt.Errorf("can't create recipe tables: %w", err)
+	}
+
+	return &Repo{db: db}, nil
+}
+
+func (r *Repo) CreateRecipe(ctx context.Context, model recipe.Recipe) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(recipeToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert recipe %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetRecipe(ctx context.Context, recipeID id.ID[recipe.Recipe]) (recipe.Recipe, error) {
+	entity, err := r.getRecipe(ctx, r.db, recipeID)
+	if err != nil {
+		return recipe.Recipe{}, err
+	}
+
+	return recipeToModel(entity), nil
+}
+
+// GetRecipes returns recipes with the given ids, missing ones are skipped
+func (r *Repo) GetRecipes(ctx context.Context, recipeIDs []id.ID[recipe.Recipe]) ([]recipe.Recipe, error) {
+	var entities []Recipe
+
+	err := r.db.WithContext(ctx).
+		Preload("Ingredients", orderByIndex).
+		Where("id IN ?", lo.Map(recipeIDs, func(item id.ID[recipe.Recipe], _ int) string { return item.String() })).
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select recipes %v: %w", recipeIDs, err)
+	}
+
+	return lo.Map(entities, func(item Recipe, _ int) recipe.Recipe { return recipeToModel(item) }), nil
+}
+
+// GetRecipesByOwnerID returns recipes of the user sorted by title
+func (r *Repo) GetRecipesByOwnerID(ctx context.Context, ownerID id.ID[user.User]) ([]recipe.Recipe, error) {
+	var entities []Recipe
+
+	err := r.db.WithContext(ctx).
+		Preload("Ingredients", orderByIndex).
+		Where("owner_id = ?", ownerID.String()).
+		Order("title").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select recipes of user %s: %w", ownerID, err)
+	}
+
+	return lo.Map(entities, func(item Recipe, _ int) recipe.Recipe { return recipeToModel(item) }), nil
+}
+
+// GetAndUpdateRecipe replaces recipe by result of updateFunc
+func (r *Repo) GetAndUpdateRecipe(
+	ctx context.Context,
+	recipeID id.ID[recipe.Recipe],
+	updateFunc func(recipe.Recipe) (recipe.Recipe, error),
+) (
+	recipe.Recipe,
+	error,
+) {
+	var model recipe.Recipe
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getRecipe(ctx, tx, recipeID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(recipeToModel(entity)); err != nil {
+			return err
+		}
+
+		entity = recipeToEntity(model)
+
+		err = tx.WithContext(ctx).Where("recipe_id = ?", recipeID.String()).Delete(new(RecipeIngredient)).Error
+		if err != nil {
+			return fmt.Errorf("can't delete ingredients of recipe %s: %w", recipeID, err)
+		}
+
+		if len(entity.Ingredients) != 0 {
+			if err = tx.WithContext(ctx).Create(&entity.Ingredients).Error; err != nil {
+				return fmt.Errorf("can't insert ingredients of recipe %s: %w", recipeID, err)
+			}
+		}
+
+		query := &Recipe{ID: entity.ID} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Model(query).
+			Select("title", "servings", "instructions", "updated_at").
+			Updates(&entity).Error
+		if err != nil {
+			return fmt.Errorf("can't update recipe %s: %w", recipeID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return recipe.Recipe{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// DeleteRecipe deletes recipe if checkFunc allows it, meals of the recipe are deleted from meal plans
+func (r *Repo) DeleteRecipe(
+	ctx context.Context,
+	recipeID id.ID[recipe.Recipe],
+	checkFunc func(recipe.Recipe) error,
+) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getRecipe(ctx, tx, recipeID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkFunc(recipeToModel(entity)); err != nil {
+			return err
+		}
+
+		for _, child := range []any{new(RecipeIngredient), new(MealPlanMeal)} {
+			if err = tx.WithContext(ctx).Where("recipe_id = ?", recipeID.String()).Delete(child).Error; err != nil {
+				return fmt.Errorf("can't delete contents of recipe %s: %w", recipeID, err)
+			}
+		}
+
+		if err = tx.WithContext(ctx).Delete(&Recipe{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+			return fmt.Errorf("can't delete recipe %s: %w", recipeID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) CreateMealPlan(ctx context.Context, model recipe.MealPlan) error {
+	if err := r.db.WithContext(ctx).Create(lo.ToPtr(mealPlanToEntity(model))).Error; err != nil {
+		return fmt.Errorf("%w: can't insert meal plan %s: %w", mymysql.GetType(err), model.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) GetMealPlan(ctx context.Context, planID id.ID[recipe.MealPlan]) (recipe.MealPlan, error) {
+	entity, err := r.getMealPlan(ctx, r.db, planID)
+	if err != nil {
+		return recipe.MealPlan{}, err
+	}
+
+	return mealPlanToModel(entity), nil
+}
+
+// GetMealPlansByOwnerID returns meal plans of the user, the latest weeks go first
+func (r *Repo) GetMealPlansByOwnerID(ctx context.Context, ownerID id.ID[user.User]) ([]recipe.MealPlan, error) {
+	var entities []MealPlan
+
+	err := r.db.WithContext(ctx).
+		Preload("Meals", orderByIndex).
+		Where("owner_id = ?", ownerID.String()).
+		Order("week_start DESC").
+		Order("created_at").
+		Find(&entities).Error
+	if err != nil {
+		return nil, fmt.Errorf("can't select meal plans of user %s: %w", ownerID, err)
+	}
+
+	return lo.Map(entities, func(item MealPlan, _ int) recipe.MealPlan { return mealPlanToModel(item) }), nil
+}
+
+// GetAndUpdateMealPlan replaces meal plan by result of updateFunc
+func (r *Repo) GetAndUpdateMealPlan(
+	ctx context.Context,
+	planID id.ID[recipe.MealPlan],
+	updateFunc func(recipe.MealPlan) (recipe.MealPlan, error),
+) (
+	recipe.MealPlan,
+	error,
+) {
+	var model recipe.MealPlan
+
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getMealPlan(ctx, tx, planID)
+		if err != nil {
+			return err
+		}
+
+		if model, err = updateFunc(mealPlanToModel(entity)); err != nil {
+			return err
+		}
+
+		entity = mealPlanToEntity(model)
+
+		if err = tx.WithContext(ctx).Where("meal_plan_id = ?", planID.String()).Delete(new(MealPlanMeal)).Error; err != nil {
+			return fmt.Errorf("can't delete meals of meal plan %s: %w", planID, err)
+		}
+
+		if len(entity.Meals) != 0 {
+			if err = tx.WithContext(ctx).Create(&entity.Meals).Error; err != nil {
+				return fmt.Errorf("can't insert meals of meal plan %s: %w", planID, err)
+			}
+		}
+
+		query := &MealPlan{ID: entity.ID} //nolint:exhaustruct
+
+		err = tx.WithContext(ctx).Model(query).Select("title", "week_start", "updated_at").Updates(&entity).Error
+		if err != nil {
+			return fmt.Errorf("can't update meal plan %s: %w", planID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return recipe.MealPlan{}, fmt.Errorf("%w: transaction failed: %w", mymysql.GetType(err), err)
+	}
+
+	return model, nil
+}
+
+// DeleteMealPlan deletes meal plan if checkFunc allows it
+func (r *Repo) DeleteMealPlan(
+	ctx context.Context,
+	planID id.ID[recipe.MealPlan],
+	checkFunc func(recipe.MealPlan) error,
+) error {
+	err := dbtx.Run(ctx, r.db, func(ctx context.Context, tx *gorm.DB) error {
+		entity, err := r.getMealPlan(ctx, tx, planID)
+		if err != nil {
+			return err
+		}
+
+		if err = checkFunc(mealPlanToModel(entity)); err != nil {
+			return err
+		}
+
+		if err = tx.WithContext(ctx).Where("meal_plan_id = ?", planID.String()).Delete(new(MealPlanMeal)).Error; err != nil {
+			return fmt.Errorf("can't delete meals of meal plan %s: %w", planID, err)
+		}
+
+		if err = tx.WithContext(ctx).Delete(&MealPlan{ID: entity.ID}).Error; err != nil { //nolint:exhaustruct
+			return fmt.Errorf("can't delete meal plan %s: %w", planID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("transaction failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repo) getRecipe(ctx context.Context, tx *gorm.DB, recipeID id.ID[recipe.Recipe]) (Recipe, error) {
+	var entity Recipe
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Ingredients", orderByIndex).
+		Where("id = ?", recipeID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: recipe %s", myerr.ErrNotFound, recipeID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select recipe %s: %w", recipeID, err)
+	}
+
+	return entity, nil
+}
+
+func (r *Repo) getMealPlan(ctx context.Context, tx *gorm.DB, planID id.ID[recipe.MealPlan]) (MealPlan, error) {
+	var entity MealPlan
+
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Meals", orderByIndex).
+		Where("id = ?", planID.String()).
+		First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity, fmt.Errorf("%w: meal plan %s", myerr.ErrNotFound, planID)
+	} else if err != nil {
+		return entity, fmt.Errorf("can't select meal plan %s: %w", planID, err)
+	}
+
+	return entity, nil
+}
+
+func orderByIndex(db *gorm.DB) *gorm.DB {
+	return db.Order("`index`")
+}
+
+func recipeToModel(entity Recipe) recipe.Recipe {
+	return recipe.Recipe{
+		Options: recipe.Options{
+			Title:        entity.Title,
+			Servings:     entity.Servings,
+			Instructions: entity.Instructions,
+			Ingredients: lo.Map(entity.Ingredients, func(item RecipeIngredient, _ int) recipe.Ingredient {
+				return recipe.Ingredient{
+					ProductID: id.ID[product.Product]{UUID: god.Believe(uuid.Parse(item.ProductID))},
+					Quantity:  productRepo.QuantityToModel(item.Quantity, nil).OrEmpty(),
+					Note:      item.Note,
+				}
+			}),
+		},
+		ID:        id.ID[recipe.Recipe]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		OwnerID:   id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func recipeToEntity(model recipe.Recipe) Recipe {
+	return Recipe{
+		ID:           model.ID.String(),
+		OwnerID:      model.OwnerID.String(),
+		Title:        model.Title,
+		Servings:     model.Servings,
+		Instructions: model.Instructions,
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+		Ingredients: lo.Map(model.Ingredients, func(item recipe.Ingredient, idx int) RecipeIngredient {
+			return RecipeIngredient{
+				RecipeID:  model.ID.String(),
+				ProductID: item.ProductID.String(),
+				Index:     idx,
+				Quantity:  productRepo.QuantityToEntity(mo.Some(item.Quantity)),
+				Note:      item.Note,
+			}
+		}),
+	}
+}
+
+func mealPlanToModel(entity MealPlan) recipe.MealPlan {
+	return recipe.MealPlan{
+		MealPlanOptions: recipe.MealPlanOptions{
+			Title:     entity.Title,
+			WeekStart: entity.WeekStart,
+			Meals: lo.Map(entity.Meals, func(item MealPlanMeal, _ int) recipe.Meal {
+				return recipe.Meal{
+					RecipeID: id.ID[recipe.Recipe]{UUID: god.Believe(uuid.Parse(item.RecipeID))},
+					Day:      item.Day,
+					Slot:     recipe.Slot(item.Slot),
+					Servings: item.Servings,
+				}
+			}),
+		},
+		ID:        id.ID[recipe.MealPlan]{UUID: god.Believe(uuid.Parse(entity.ID))},
+		OwnerID:   id.ID[user.User]{UUID: god.Believe(uuid.Parse(entity.OwnerID))},
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func mealPlanToEntity(model recipe.MealPlan) MealPlan {
+	return MealPlan{
+		ID:        model.ID.String(),
+		OwnerID:   model.OwnerID.String(),
+		Title:     model.Title,
+		WeekStart: model.WeekStart.UTC(),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+		Meals: lo.Map(model.Meals, func(item recipe.Meal, idx int) MealPlanMeal {
+			return MealPlanMeal{
+				ID:         uuid.NewString(),
+				MealPlanID: model.ID.String(),
+				RecipeID:   item.RecipeID.String(),
+				Index:      idx,
+				Day:        item.Day,
+				Slot:       int32(item.Slot),
+				Servings:   item.Servings,
+			}
+		}),
+	}
+}
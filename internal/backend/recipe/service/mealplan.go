@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/mo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/recipe"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+func (s *Service) CreateMealPlan(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	options recipe.MealPlanOptions,
+) (
+	recipe.MealPlan,
+	error,
+) {
+	if err := s.validateMealPlan(ctx, ownerID, options); err != nil {
+		return recipe.MealPlan{}, err
+	}
+
+	now := s.clock.Now()
+	model := recipe.MealPlan{
+		MealPlanOptions: options,
+		ID:              id.NewID[recipe.MealPlan](),
+		OwnerID:         ownerID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	model.WeekStart = list.SpendingPeriodWeek.Start(options.WeekStart)
+
+	if err := s.repo.CreateMealPlan(ctx, model); err != nil {
+		return recipe.MealPlan{}, fmt.Errorf("can't create meal plan: %w", err)
+	}
+
+	return model, nil
+}
+
+func (s *Service) GetMealPlan(
+	ctx context.Context,
+	planID id.ID[recipe.MealPlan],
+	userID id.ID[user.User],
+) (
+	recipe.MealPlan,
+	error,
+) {
+	model, err := s.repo.GetMealPlan(ctx, planID)
+	if err != nil {
+		return recipe.MealPlan{}, fmt.Errorf("can't get meal plan %s: %w", planID, err)
+	}
+
+	if err = model.CheckOwner(userID); err != nil {
+		return recipe.MealPlan{}, err
+	}
+
+	return model, nil
+}
+
+// GetMealPlans returns meal plans of the user, the latest weeks go first
+func (s *Service) GetMealPlans(ctx context.Context, userID id.ID[user.User]) ([]recipe.MealPlan, error) {
+	plans, err := s.repo.GetMealPlansByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get meal plans of user %s: %w", userID, err)
+	}
+
+	return plans, nil
+}
+
+func (s *Service) UpdateMealPlan(
+	ctx context.Context,
+	planID id.ID[recipe.MealPlan],
+	userID id.ID[user.User],
+	options recipe.MealPlanOptions,
+) (
+	recipe.MealPlan,
+	error,
+) {
+	if err := s.validateMealPlan(ctx, userID, options); err != nil {
+		return recipe.MealPlan{}, err
+	}
+
+	model, err := s.repo.GetAndUpdateMealPlan(ctx, planID, func(model recipe.MealPlan) (recipe.MealPlan, error) {
+		if err := model.CheckOwner(userID); err != nil {
+			return model, err
+		}
+
+		model.MealPlanOptions = options
+		model.WeekStart = list.SpendingPeriodWeek.Start(options.WeekStart)
+		model.UpdatedAt = s.clock.Now()
+
+		return model, nil
+	})
+	if err != nil {
+		return recipe.MealPlan{}, fmt.Errorf("can't update meal plan %s: %w", planID, err)
+	}
+
+	return model, nil
+}
+
+func (s *Service) DeleteMealPlan(ctx context.Context, planID id.ID[recipe.MealPlan], userID id.ID[user.User]) error {
+	err := s.repo.DeleteMealPlan(ctx, planID, func(model recipe.MealPlan) error {
+		return model.CheckOwner(userID)
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete meal plan %s: %w", planID, err)
+	}
+
+	return nil
+}
+
+// ToList sums ingredients of all meals of the plan and appends them to the list.
+// Products which are already in the list get their quantities increased.
+func (s *Service) ToList(
+	ctx context.Context,
+	planID id.ID[recipe.MealPlan],
+	userID id.ID[user.User],
+	options recipe.ToListOptions,
+) (
+	list.ProductList,
+	error,
+) {
+	plan, err := s.GetMealPlan(ctx, planID, userID)
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	if len(plan.Meals) == 0 {
+		return list.ProductList{}, fmt.Errorf("%w: meal plan %s has no meals", myerr.ErrInvalidArgument, planID)
+	}
+
+	recipes, err := s.repo.GetRecipes(ctx, plan.RecipeIDs())
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't get recipes of meal plan %s: %w", planID, err)
+	}
+
+	ingredients, err := plan.Ingredients(recipes)
+	if err != nil {
+		return list.ProductList{}, err
+	}
+
+	states := make(map[id.ID[product.Product]]list.ProductStateOptions, len(ingredients))
+	for productID, quantity := range ingredients {
+		states[productID] = list.ProductStateOptions{
+			Quantity:    mo.Some(quantity),
+			FormIndex:   mo.None[int32](),
+			Status:      list.StateStatusWaiting,
+			Replacement: mo.None[list.ProductStateReplacement](),
+			Note:        "",
+			Price:       mo.None[list.Price](),
+		}
+	}
+
+	model, err := s.lists.AppendProducts(ctx, options.ListID, userID, states)
+	if err != nil {
+		return list.ProductList{}, fmt.Errorf("can't add ingredients of meal plan %s to list: %w", planID, err)
+	}
+
+	return model, nil
+}
+
+// validateMealPlan checks meals of a plan owned by ownerID, only recipes of the owner can be cooked
+func (s *Service) validateMealPlan(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	options recipe.MealPlanOptions,
+) error {
+	if err := validator.New().Struct(options); err != nil {
+		return fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	for _, meal := range options.Meals {
+		if !meal.Slot.IsValid() {
+			return fmt.Errorf("%w: unknown meal slot %d", myerr.ErrInvalidArgument, meal.Slot)
+		}
+	}
+
+	recipeIDs := options.RecipeIDs()
+	if len(recipeIDs) == 0 {
+		return nil
+	}
+
+	recipes, err := s.repo.GetRecipes(ctx, recipeIDs)
+	if err != nil {
+		return fmt.Errorf("can't get recipes of meal plan: %w", err)
+	}
+
+	if len(recipes) != len(recipeIDs) {
+		return fmt.Errorf("%w: some recipes of meal plan are missing", myerr.ErrNotFound)
+	}
+
+	for _, item := range recipes {
+		if err = item.CheckOwner(ownerID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
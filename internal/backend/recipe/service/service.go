@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/list"
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/recipe"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const maxServings = 100
+
+type repo interface {
+	CreateRecipe(context.Context, recipe.Recipe) error
+	GetRecipe(context.Context, id.ID[recipe.Recipe]) (recipe.Recipe, error)
+	GetRecipes(context.Context, []id.ID[recipe.Recipe]) ([]recipe.Recipe, error)
+	GetRecipesByOwnerID(context.Context, id.ID[user.User]) ([]recipe.Recipe, error)
+	GetAndUpdateRecipe(
+		context.Context,
+		id.ID[recipe.Recipe],
+		func(recipe.Recipe) (recipe.Recipe, error),
+	) (
+		recipe.Recipe,
+		error,
+	)
+	DeleteRecipe(context.Context, id.ID[recipe.Recipe], func(recipe.Recipe) error) error
+	CreateMealPlan(context.Context, recipe.MealPlan) error
+	GetMealPlan(context.Context, id.ID[recipe.MealPlan]) (recipe.MealPlan, error)
+	GetMealPlansByOwnerID(context.Context, id.ID[user.User]) ([]recipe.MealPlan, error)
+	GetAndUpdateMealPlan(
+		context.Context,
+		id.ID[recipe.MealPlan],
+		func(recipe.MealPlan) (recipe.MealPlan, error),
+	) (
+		recipe.MealPlan,
+		error,
+	)
+	DeleteMealPlan(context.Context, id.ID[recipe.MealPlan], func(recipe.MealPlan) error) error
+}
+
+// lists receive ingredients of meal plans
+type lists interface {
+	AppendProducts(
+		context.Context,
+		id.ID[list.ProductList],
+		id.ID[user.User],
+		map[id.ID[product.Product]]list.ProductStateOptions,
+	) (
+		list.ProductList,
+		error,
+	)
+}
+
+type products interface {
+	IDList(context.Context, []id.ID[product.Product]) ([]product.Product, error)
+}
+
+type Service struct {
+	repo     repo
+	lists    lists
+	products products
+	clock    clock.Clock
+	log      zerolog.Logger
+}
+
+func NewService(repo repo, lists lists, products products, clock clock.Clock, log zerolog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		lists:    lists,
+		products: products,
+		clock:    clock,
+		log:      log.With().Str("component", "recipe service").Logger(),
+	}
+}
+
+func (s *Service) CreateRecipe(
+	ctx context.Context,
+	ownerID id.ID[user.User],
+	options recipe.Options,
+) (
+	recipe.Recipe,
+	error,
+) {
+	if err := s.validateRecipe(ctx, options); err != nil {
+		return recipe.Recipe{}, err
+	}
+
+	now := s.clock.Now()
+	model := recipe.Recipe{
+		Options:   options,
+		ID:        id.NewID[recipe.Recipe](),
+		OwnerID:   ownerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.CreateRecipe(ctx, model); err != nil {
+		return recipe.Recipe{}, fmt.Errorf("can't create recipe: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetRecipe returns recipe of the user, with servings above zero ingredients are scaled to them
+func (s *Service) GetRecipe(
+	ctx context.Context,
+	recipeID id.ID[recipe.Recipe],
+	userID id.ID[user.User],
+	servings int,
+) (
+	recipe.Recipe,
+	error,
+) {
+	if servings < 0 || servings > maxServings {
+		return recipe.Recipe{}, fmt.Errorf("%w: servings must be between 1 and %d", myerr.ErrInvalidArgument, maxServings)
+	}
+
+	model, err := s.repo.GetRecipe(ctx, recipeID)
+	if err != nil {
+		return recipe.Recipe{}, fmt.Errorf("can't get recipe %s: %w", recipeID, err)
+	}
+
+	if err = model.CheckOwner(userID); err != nil {
+		return recipe.Recipe{}, err
+	}
+
+	if servings != 0 {
+		model = model.Scale(servings)
+	}
+
+	return model, nil
+}
+
+func (s *Service) GetRecipes(ctx context.Context, userID id.ID[user.User]) ([]recipe.Recipe, error) {
+	recipes, err := s.repo.GetRecipesByOwnerID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get recipes of user %s: %w", userID, err)
+	}
+
+	return recipes, nil
+}
+
+func (s *Service) UpdateRecipe(
+	ctx context.Context,
+	recipeID id.ID[recipe.Recipe],
+	userID id.ID[user.User],
+	options recipe.Options,
+) (
+	recipe.Recipe,
+	error,
+) {
+	if err := s.validateRecipe(ctx, options); err != nil {
+		return recipe.Recipe{}, err
+	}
+
+	model, err := s.repo.GetAndUpdateRecipe(ctx, recipeID, func(model recipe.Recipe) (recipe.Recipe, error) {
+		if err := model.CheckOwner(userID); err != nil {
+			return model, err
+		}
+
+		model.Options = options
+		model.UpdatedAt = s.clock.Now()
+
+		return model, nil
+	})
+	if err != nil {
+		return recipe.Recipe{}, fmt.Errorf("can't update recipe %s: %w", recipeID, err)
+	}
+
+	return model, nil
+}
+
+// DeleteRecipe deletes the recipe and its meals from meal plans
+func (s *Service) DeleteRecipe(ctx context.Context, recipeID id.ID[recipe.Recipe], userID id.ID[user.User]) error {
+	err := s.repo.DeleteRecipe(ctx, recipeID, func(model recipe.Recipe) error {
+		return model.CheckOwner(userID)
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete recipe %s: %w", recipeID, err)
+	}
+
+	return nil
+}
+
+// validateRecipe checks options of a recipe, all ingredients must be known products
+func (s *Service) validateRecipe(ctx context.Context, options recipe.Options) error {
+	if err := validator.New().Struct(options); err != nil {
+		return fmt.Errorf("%w: %w", myerr.ErrInvalidArgument, err)
+	}
+
+	for _, ingredient := range options.Ingredients {
+		if err := ingredient.Quantity.Validate(); err != nil {
+			return fmt.Errorf("invalid quantity of product %s: %w", ingredient.ProductID, err)
+		}
+	}
+
+	if len(options.Ingredients) == 0 {
+		return nil
+	}
+
+	productIDs := lo.Map(options.Ingredients, func(item recipe.Ingredient, _ int) id.ID[product.Product] {
+		return item.ProductID
+	})
+
+	found, err := s.products.IDList(ctx, productIDs)
+	if err != nil {
+		return fmt.Errorf("can't get ingredients: %w", err)
+	}
+
+	if len(found) != len(productIDs) {
+		return fmt.Errorf("%w: some ingredients are unknown products", myerr.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/list"
+	listRepo "go-backend/internal/backend/list/repo"
+	listService "go-backend/internal/backend/list/service"
+	"go-backend/internal/backend/product"
+	productRepo "go-backend/internal/backend/product/repo"
+	productService "go-backend/internal/backend/product/service"
+	"go-backend/internal/backend/recipe"
+	"go-backend/internal/backend/recipe/repo"
+	"go-backend/internal/backend/recipe/service"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/user"
+	userRepo "go-backend/internal/backend/user/repo"
+	"go-backend/pkg/blob"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// fixture is a recipe service over sqlite, ingredients are appended to lists of the real list service
+type fixture struct {
+	service *service.Service
+	lists   *listService.Service
+	catalog *productService.Service
+	ownerID id.ID[user.User]
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "recipe.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(userRepo.User)))
+
+	products, err := productRepo.NewGormRepo(ctx, db)
+	require.NoError(t, err)
+
+	lists, err := listRepo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	recipes, err := repo.NewRepo(ctx, db)
+	require.NoError(t, err)
+
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	ownerID := id.NewID[user.User]()
+	require.NoError(t, db.Create(&userRepo.User{
+		ID:    ownerID.String(),
+		Login: "cook",
+		Hash:  "",
+		Role:  int32(user.RoleUser),
+	}).Error)
+
+	now := clock.NewFake(time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC))
+	catalog := productService.NewService(products)
+	listsService := listService.NewService(lists, catalog, shopMaps{}, budgets{}, blobs, now, zerolog.Nop())
+
+	return fixture{
+		service: service.NewService(recipes, listsService, catalog, now, zerolog.Nop()),
+		lists:   listsService,
+		catalog: catalog,
+		ownerID: ownerID,
+	}
+}
+
+func (f fixture) addProduct(t *testing.T, name product.Name) product.Product {
+	t.Helper()
+
+	model, err := f.catalog.Create(context.Background(), product.Options{
+		Name:     name,
+		Category: mo.None[product.Category](),
+		Forms:    []product.Form{},
+		Aliases:  []product.Name{},
+		Barcodes: []product.Barcode{},
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+func (f fixture) addRecipe(t *testing.T, title string, servings int, ingredients ...recipe.Ingredient) recipe.Recipe {
+	t.Helper()
+
+	model, err := f.service.CreateRecipe(context.Background(), f.ownerID, recipe.Options{
+		Title:        title,
+		Servings:     servings,
+		Instructions: "",
+		Ingredients:  ingredients,
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+func (f fixture) addMealPlan(t *testing.T, meals ...recipe.Meal) recipe.MealPlan {
+	t.Helper()
+
+	model, err := f.service.CreateMealPlan(context.Background(), f.ownerID, recipe.MealPlanOptions{
+		Title:     "week",
+		WeekStart: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Meals:     meals,
+	})
+	require.NoError(t, err)
+
+	return model
+}
+
+func ingredient(p product.Product, value float64, unit product.Unit) recipe.Ingredient {
+	return recipe.Ingredient{ProductID: p.ID, Quantity: product.Quantity{Value: value, Unit: unit}, Note: ""}
+}
+
+func meal(model recipe.Recipe, day, servings int) recipe.Meal {
+	return recipe.Meal{RecipeID: model.ID, Day: day, Slot: recipe.SlotBreakfast, Servings: servings}
+}
+
+func quantities(model list.ProductList) map[id.ID[product.Product]]product.Quantity {
+	result := make(map[id.ID[product.Product]]product.Quantity, len(model.States))
+	for _, state := range model.States {
+		result[state.Product.ID] = state.Quantity.OrEmpty()
+	}
+
+	return result
+}
+
+func TestToList(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	flour, eggs, milk := f.addProduct(t, "flour"), f.addProduct(t, "eggs"), f.addProduct(t, "milk")
+
+	pancakes := f.addRecipe(t, "pancakes", 2,
+		ingredient(flour, 200, product.UnitG),
+		ingredient(eggs, 1, product.UnitPieces),
+		ingredient(milk, 300, product.UnitMl),
+	)
+	omelette := f.addRecipe(t, "omelette", 1,
+		ingredient(eggs, 3, product.UnitPieces),
+		ingredient(milk, 0.1, product.UnitL),
+	)
+
+	// pancakes are cooked for 3 and the omelette for 2, so eggs are 1.5 + 6 and milk is 450 ml + 0.2 l
+	plan := f.addMealPlan(t, meal(pancakes, 0, 3), meal(omelette, 1, 2))
+
+	model, err := f.lists.Create(ctx, f.ownerID, list.ListOptions{Title: "weekly"})
+	require.NoError(t, err)
+
+	_, err = f.lists.AppendProducts(ctx, model.ID, f.ownerID, map[id.ID[product.Product]]list.ProductStateOptions{
+		milk.ID: {Quantity: mo.Some(product.Quantity{Value: 1, Unit: product.UnitL}), Status: list.StateStatusWaiting},
+	})
+	require.NoError(t, err)
+
+	model, err = f.service.ToList(ctx, plan.ID, f.ownerID, recipe.ToListOptions{ListID: model.ID})
+	require.NoError(t, err)
+	require.Len(t, model.States, 3)
+
+	require.Equal(t, map[id.ID[product.Product]]product.Quantity{
+		flour.ID: {Value: 300, Unit: product.UnitG},
+		eggs.ID:  product.NewPieces(8),
+		// milk already in the list gets the sum in its own unit
+		milk.ID: {Value: 1.65, Unit: product.UnitL},
+	}, quantities(model))
+
+	// the plan is appended once more on every call
+	model, err = f.service.ToList(ctx, plan.ID, f.ownerID, recipe.ToListOptions{ListID: model.ID})
+	require.NoError(t, err)
+	require.Len(t, model.States, 3)
+	require.Equal(t, product.NewPieces(16), quantities(model)[eggs.ID])
+}
+
+func TestToListErrors(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	flour := f.addProduct(t, "flour")
+
+	bread := f.addRecipe(t, "bread", 1, ingredient(flour, 500, product.UnitG))
+	cookies := f.addRecipe(t, "cookies", 1, ingredient(flour, 2, product.UnitPacks))
+
+	model, err := f.lists.Create(ctx, f.ownerID, list.ListOptions{Title: "weekly"})
+	require.NoError(t, err)
+
+	options := recipe.ToListOptions{ListID: model.ID}
+
+	plan := f.addMealPlan(t, meal(bread, 0, 1), meal(cookies, 1, 1))
+	_, err = f.service.ToList(ctx, plan.ID, f.ownerID, options)
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument, "grams and packs can't be summed")
+
+	_, err = f.service.ToList(ctx, f.addMealPlan(t).ID, f.ownerID, options)
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument, "plan without meals")
+
+	plan = f.addMealPlan(t, meal(bread, 0, 2))
+	_, err = f.service.ToList(ctx, plan.ID, id.NewID[user.User](), options)
+	require.ErrorIs(t, err, myerr.ErrForbidden)
+
+	model, err = f.lists.GetByID(ctx, model.ID, f.ownerID)
+	require.NoError(t, err)
+	require.Empty(t, model.States)
+}
+
+// shopMaps has no shop maps, lists of the tests have no default ones
+type shopMaps struct{}
+
+func (shopMaps) GetByID(_ context.Context, mapID id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error) {
+	return shopmap.ShopMap{}, fmt.Errorf("%w: shop map %s", myerr.ErrNotFound, mapID)
+}
+
+// budgets are never exceeded
+type budgets struct{}
+
+func (budgets) CheckPurchases(
+	context.Context,
+	list.ProductList,
+	[]list.Purchase,
+) (
+	[]list.BudgetExceededChange,
+	error,
+) {
+	return nil, nil
+}
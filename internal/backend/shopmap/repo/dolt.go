@@ -1,10 +1,12 @@
 package repo
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -15,6 +17,7 @@ import (
 	"go-backend/internal/backend/shopmap/repo/sqlgen"
 	"go-backend/internal/backend/user"
 	"go-backend/pkg/date"
+	"go-backend/pkg/dbtx"
 	"go-backend/pkg/god"
 	"go-backend/pkg/id"
 )
@@ -35,6 +38,8 @@ func NewShopMapRepo(ctx context.Context, db *sql.DB) (*ShopMapRepo, error) {
 		return nil, wrapErr(fmt.Errorf("can't init shop map categories: %w", err))
 	} else if err = queries.InitShopMapViewers(ctx); err != nil {
 		return nil, wrapErr(fmt.Errorf("can't init shop map viewers: %w", err))
+	} else if err = initTrashColumns(ctx, queries); err != nil {
+		return nil, err
 	}
 
 	return &ShopMapRepo{queries: queries, db: db}, nil
@@ -94,6 +99,7 @@ func (s *ShopMapRepo) Create(ctx context.Context, model shopmap.ShopMap) error {
 	return wrapErr(tx.Commit())
 }
 
+// Delete removes shop map with its categories and viewers, it's used to purge maps from the trash
 func (s *ShopMapRepo) Delete(ctx context.Context, mapID id.ID[shopmap.ShopMap]) error {
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -146,6 +152,61 @@ func (s *ShopMapRepo) GetAndUpdate(
 	return model, wrapErr(tx.Commit())
 }
 
+// RenameCategories replaces renamed categories of shop maps by the name and removes repeated ones,
+// categories stay numbered from zero. It joins the transaction of ctx, see dbtx.Conn.
+func (s *ShopMapRepo) RenameCategories(
+	ctx context.Context,
+	name product.Category,
+	renamed func(product.Category) bool,
+) error {
+	qtx := sqlgen.New(dbtx.Conn(ctx, s.db))
+
+	categories, err := qtx.GetCategoryNames(ctx)
+	if err != nil {
+		return wrapErr(fmt.Errorf("can't get categories of shop maps: %w", err))
+	}
+
+	olds := lo.Filter(categories, func(item string, _ int) bool { return renamed(product.Category(item)) })
+	if len(olds) == 0 {
+		return nil
+	}
+
+	mapIDs, err := qtx.GetMapsWithCategories(ctx, olds)
+	if err != nil {
+		return wrapErr(fmt.Errorf("can't get shop maps with categories %v: %w", olds, err))
+	}
+
+	for _, mapID := range mapIDs {
+		rows, err := qtx.GetCategoriesByID(ctx, mapID)
+		if err != nil {
+			return wrapErr(fmt.Errorf("can't get categories of shop map %s: %w", mapID, err))
+		}
+
+		slices.SortFunc(rows, func(a, b sqlgen.ShopMapCategory) int { return cmp.Compare(a.Number, b.Number) })
+
+		names := lo.Uniq(lo.Map(rows, func(item sqlgen.ShopMapCategory, _ int) string {
+			return lo.Ternary(slices.Contains(olds, item.Category), string(name), item.Category)
+		}))
+
+		if err = qtx.DeleteCategoriesByMapID(ctx, mapID); err != nil {
+			return wrapErr(fmt.Errorf("can't delete categories of shop map %s: %w", mapID, err))
+		}
+
+		for number, category := range names {
+			err = qtx.InsertCategory(ctx, sqlgen.InsertCategoryParams{
+				MapID:    mapID,
+				Number:   uint32(number), //nolint:gosec // slice index can't be negative
+				Category: category,
+			})
+			if err != nil {
+				return wrapErr(fmt.Errorf("can't insert categories of shop map %s: %w", mapID, err))
+			}
+		}
+	}
+
+	return nil
+}
+
 // GetByID implements service.repo.
 func (s *ShopMapRepo) GetByID(ctx context.Context, mapID id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error) {
 	tx, err := s.db.Begin()
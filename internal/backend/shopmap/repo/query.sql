@@ -5,6 +5,7 @@ FROM
     shop_maps AS m
 WHERE
     m.id = ?
+    AND m.deleted_at IS NULL
 LIMIT
     1;
 
@@ -14,15 +15,17 @@ SELECT
 FROM
     shop_maps AS m
 WHERE
-    m.owner_id = ?;
+    m.owner_id = ?
+    AND m.deleted_at IS NULL;
 
 -- name: GetByListID :many
 SELECT
     *
 FROM
-    shop_maps
+    shop_maps AS m
 WHERE
-    id IN (sqlc.slice('map_ids'));
+    m.id IN (sqlc.slice('map_ids'))
+    AND m.deleted_at IS NULL;
 
 -- name: GetCategoriesByID :many
 SELECT
@@ -32,6 +35,20 @@ FROM
 WHERE
     map_id = ?;
 
+-- name: GetCategoryNames :many
+SELECT
+    DISTINCT category
+FROM
+    shop_map_categories;
+
+-- name: GetMapsWithCategories :many
+SELECT
+    DISTINCT map_id
+FROM
+    shop_map_categories
+WHERE
+    category IN (sqlc.slice('categories'));
+
 -- name: GetViewersByMapID :many
 SELECT
     *
@@ -72,6 +89,12 @@ INSERT INTO
 VALUES
     (?, ?, ?);
 
+-- name: InsertCategory :exec
+INSERT INTO
+    shop_map_categories(map_id, number, category)
+VALUES
+    (?, ?, ?);
+
 -- name: UpdateShopMap :exec
 UPDATE
     shop_maps
@@ -121,3 +144,66 @@ DELETE FROM
     shop_map_viewers
 WHERE
     user_id IN (sqlc.slice('user_ids'));
+
+-- name: TrashShopMap :exec
+UPDATE
+    shop_maps
+SET
+    deleted_at = ?,
+    deleted_by = ?
+WHERE
+    id = ?
+    AND deleted_at IS NULL;
+
+-- name: RestoreShopMap :execrows
+UPDATE
+    shop_maps
+SET
+    deleted_at = NULL,
+    deleted_by = NULL
+WHERE
+    id = ?
+    AND deleted_at > ?
+    AND owner_id = ?;
+
+-- name: GetTrashedByOwnerID :many
+SELECT
+    id,
+    title,
+    deleted_by,
+    deleted_at
+FROM
+    shop_maps
+WHERE
+    owner_id = ?
+    AND deleted_at > ?
+ORDER BY
+    deleted_at DESC;
+
+-- name: GetTrashedBefore :many
+SELECT
+    id
+FROM
+    shop_maps
+WHERE
+    deleted_at < ?
+ORDER BY
+    deleted_at
+LIMIT
+    ?;
+
+-- name: CheckTrashColumns :exec
+SELECT
+    deleted_at
+FROM
+    shop_maps
+LIMIT
+    0;
+
+-- name: AddTrashColumns :exec
+ALTER TABLE
+    shop_maps
+ADD
+    COLUMN deleted_at timestamp NULL,
+ADD
+    COLUMN deleted_by varchar(36) NULL;
@@ -4,7 +4,9 @@ CREATE TABLE IF NOT EXISTS shop_maps (
     owner_id varchar(36) NOT NULL,
     title varchar(255) NOT NULL,
     created_at timestamp NOT NULL,
-    updated_at timestamp NOT NULL
+    updated_at timestamp NOT NULL,
+    deleted_at timestamp NULL,
+    deleted_by varchar(36) NULL
 );
 
 -- name: InitShopMapViewers :exec
@@ -22,4 +24,4 @@ CREATE TABLE IF NOT EXISTS shop_map_categories (
     category varchar(255) NOT NULL,
     PRIMARY KEY (map_id, number),
     FOREIGN KEY(map_id) REFERENCES shop_maps(id)
-)
+);
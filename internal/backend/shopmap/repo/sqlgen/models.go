@@ -5,6 +5,7 @@
 package sqlgen
 
 import (
+	"database/sql"
 	"time"
 )
 
@@ -14,6 +15,8 @@ type ShopMap struct {
 	Title     string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+	DeletedBy sql.NullString
 }
 
 type ShopMapCategory struct {
@@ -7,10 +7,39 @@ package sqlgen
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 	"time"
 )
 
+const addTrashColumns = `-- name: AddTrashColumns :exec
+ALTER TABLE
+    shop_maps
+ADD
+    COLUMN deleted_at timestamp NULL,
+ADD
+    COLUMN deleted_by varchar(36) NULL
+`
+
+func (q *Queries) AddTrashColumns(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, addTrashColumns)
+	return err
+}
+
+const checkTrashColumns = `-- name: CheckTrashColumns :exec
+SELECT
+    deleted_at
+FROM
+    shop_maps
+LIMIT
+    0
+`
+
+func (q *Queries) CheckTrashColumns(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, checkTrashColumns)
+	return err
+}
+
 const createShopMap = `-- name: CreateShopMap :exec
 INSERT INTO
     shop_maps(
@@ -103,11 +132,12 @@ func (q *Queries) DeleteViewersByListID(ctx context.Context, userIds []string) e
 
 const getByID = `-- name: GetByID :one
 SELECT
-    id, owner_id, title, created_at, updated_at
+    id, owner_id, title, created_at, updated_at, deleted_at, deleted_by
 FROM
     shop_maps AS m
 WHERE
     m.id = ?
+    AND m.deleted_at IS NULL
 LIMIT
     1
 `
@@ -121,17 +151,20 @@ func (q *Queries) GetByID(ctx context.Context, id string) (ShopMap, error) {
 		&i.Title,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
 
 const getByListID = `-- name: GetByListID :many
 SELECT
-    id, owner_id, title, created_at, updated_at
+    id, owner_id, title, created_at, updated_at, deleted_at, deleted_by
 FROM
-    shop_maps
+    shop_maps AS m
 WHERE
-    id IN (/*SLICE:map_ids*/?)
+    m.id IN (/*SLICE:map_ids*/?)
+    AND m.deleted_at IS NULL
 `
 
 func (q *Queries) GetByListID(ctx context.Context, mapIds []string) ([]ShopMap, error) {
@@ -159,6 +192,8 @@ func (q *Queries) GetByListID(ctx context.Context, mapIds []string) ([]ShopMap,
 			&i.Title,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -175,11 +210,12 @@ func (q *Queries) GetByListID(ctx context.Context, mapIds []string) ([]ShopMap,
 
 const getByOwnerID = `-- name: GetByOwnerID :many
 SELECT
-    id, owner_id, title, created_at, updated_at
+    id, owner_id, title, created_at, updated_at, deleted_at, deleted_by
 FROM
     shop_maps AS m
 WHERE
     m.owner_id = ?
+    AND m.deleted_at IS NULL
 `
 
 func (q *Queries) GetByOwnerID(ctx context.Context, ownerID string) ([]ShopMap, error) {
@@ -197,6 +233,8 @@ func (q *Queries) GetByOwnerID(ctx context.Context, ownerID string) ([]ShopMap,
 			&i.Title,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DeletedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -285,6 +323,78 @@ func (q *Queries) GetCategoriesByListID(ctx context.Context, mapIds []string) ([
 	return items, nil
 }
 
+const getCategoryNames = `-- name: GetCategoryNames :many
+SELECT
+    DISTINCT category
+FROM
+    shop_map_categories
+`
+
+func (q *Queries) GetCategoryNames(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoryNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		items = append(items, category)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMapsWithCategories = `-- name: GetMapsWithCategories :many
+SELECT
+    DISTINCT map_id
+FROM
+    shop_map_categories
+WHERE
+    category IN (/*SLICE:categories*/?)
+`
+
+func (q *Queries) GetMapsWithCategories(ctx context.Context, categories []string) ([]string, error) {
+	query := getMapsWithCategories
+	var queryParams []interface{}
+	if len(categories) > 0 {
+		for _, v := range categories {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:categories*/?", strings.Repeat(",?", len(categories))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:categories*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var map_id string
+		if err := rows.Scan(&map_id); err != nil {
+			return nil, err
+		}
+		items = append(items, map_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMapsWithViewer = `-- name: GetMapsWithViewer :many
 SELECT
     map_id
@@ -317,6 +427,102 @@ func (q *Queries) GetMapsWithViewer(ctx context.Context, userID string) ([]strin
 	return items, nil
 }
 
+const getTrashedBefore = `-- name: GetTrashedBefore :many
+SELECT
+    id
+FROM
+    shop_maps
+WHERE
+    deleted_at < ?
+ORDER BY
+    deleted_at
+LIMIT
+    ?
+`
+
+type GetTrashedBeforeParams struct {
+	DeletedAt sql.NullTime
+	Limit     int32
+}
+
+func (q *Queries) GetTrashedBefore(ctx context.Context, arg GetTrashedBeforeParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedBefore, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashedByOwnerID = `-- name: GetTrashedByOwnerID :many
+SELECT
+    id,
+    title,
+    deleted_by,
+    deleted_at
+FROM
+    shop_maps
+WHERE
+    owner_id = ?
+    AND deleted_at > ?
+ORDER BY
+    deleted_at DESC
+`
+
+type GetTrashedByOwnerIDParams struct {
+	OwnerID   string
+	DeletedAt sql.NullTime
+}
+
+type GetTrashedByOwnerIDRow struct {
+	ID        string
+	Title     string
+	DeletedBy sql.NullString
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) GetTrashedByOwnerID(ctx context.Context, arg GetTrashedByOwnerIDParams) ([]GetTrashedByOwnerIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedByOwnerID, arg.OwnerID, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrashedByOwnerIDRow
+	for rows.Next() {
+		var i GetTrashedByOwnerIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.DeletedBy,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getViewersByListID = `-- name: GetViewersByListID :many
 SELECT
     map_id, user_id
@@ -404,6 +610,24 @@ type InsertCategoriesParams struct {
 	Category string
 }
 
+const insertCategory = `-- name: InsertCategory :exec
+INSERT INTO
+    shop_map_categories(map_id, number, category)
+VALUES
+    (?, ?, ?)
+`
+
+type InsertCategoryParams struct {
+	MapID    string
+	Number   uint32
+	Category string
+}
+
+func (q *Queries) InsertCategory(ctx context.Context, arg InsertCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, insertCategory, arg.MapID, arg.Number, arg.Category)
+	return err
+}
+
 const insertViewers = `-- name: InsertViewers :copyfrom
 INSERT INTO
     shop_map_viewers(map_id, user_id)
@@ -416,6 +640,54 @@ type InsertViewersParams struct {
 	UserID string
 }
 
+const restoreShopMap = `-- name: RestoreShopMap :execrows
+UPDATE
+    shop_maps
+SET
+    deleted_at = NULL,
+    deleted_by = NULL
+WHERE
+    id = ?
+    AND deleted_at > ?
+    AND owner_id = ?
+`
+
+type RestoreShopMapParams struct {
+	ID        string
+	DeletedAt sql.NullTime
+	OwnerID   string
+}
+
+func (q *Queries) RestoreShopMap(ctx context.Context, arg RestoreShopMapParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreShopMap, arg.ID, arg.DeletedAt, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const trashShopMap = `-- name: TrashShopMap :exec
+UPDATE
+    shop_maps
+SET
+    deleted_at = ?,
+    deleted_by = ?
+WHERE
+    id = ?
+    AND deleted_at IS NULL
+`
+
+type TrashShopMapParams struct {
+	DeletedAt sql.NullTime
+	DeletedBy sql.NullString
+	ID        string
+}
+
+func (q *Queries) TrashShopMap(ctx context.Context, arg TrashShopMapParams) error {
+	_, err := q.db.ExecContext(ctx, trashShopMap, arg.DeletedAt, arg.DeletedBy, arg.ID)
+	return err
+}
+
 const updateShopMap = `-- name: UpdateShopMap :exec
 UPDATE
     shop_maps
@@ -44,7 +44,9 @@ CREATE TABLE IF NOT EXISTS shop_maps (
     owner_id varchar(36) NOT NULL,
     title varchar(255) NOT NULL,
     created_at timestamp NOT NULL,
-    updated_at timestamp NOT NULL
+    updated_at timestamp NOT NULL,
+    deleted_at timestamp NULL,
+    deleted_by varchar(36) NULL
 )
 `
 
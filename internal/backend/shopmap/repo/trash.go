@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/shopmap/repo/sqlgen"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/god"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// initTrashColumns adds columns of the trash to shop maps created before the trash
+func initTrashColumns(ctx context.Context, queries *sqlgen.Queries) error {
+	if err := queries.CheckTrashColumns(ctx); err == nil {
+		return nil
+	}
+
+	if err := queries.AddTrashColumns(ctx); err != nil {
+		return wrapErr(fmt.Errorf("can't add trash columns to shop maps: %w", err))
+	}
+
+	return nil
+}
+
+// Trash moves shop map to the trash, maps in the trash aren't returned by other methods
+func (s *ShopMapRepo) Trash(
+	ctx context.Context,
+	mapID id.ID[shopmap.ShopMap],
+	userID id.ID[user.User],
+	deletedAt time.Time,
+) error {
+	err := s.queries.TrashShopMap(ctx, sqlgen.TrashShopMapParams{
+		DeletedAt: sql.NullTime{Time: deletedAt.UTC(), Valid: true},
+		DeletedBy: sql.NullString{String: userID.String(), Valid: true},
+		ID:        mapID.String(),
+	})
+	if err != nil {
+		return wrapErr(fmt.Errorf("can't move shop map %s to the trash: %w", mapID, err))
+	}
+
+	return nil
+}
+
+// GetTrash returns maps of the owner moved to the trash after given time
+func (s *ShopMapRepo) GetTrash(ctx context.Context, ownerID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	rows, err := s.queries.GetTrashedByOwnerID(ctx, sqlgen.GetTrashedByOwnerIDParams{
+		OwnerID:   ownerID.String(),
+		DeletedAt: sql.NullTime{Time: after.UTC(), Valid: true},
+	})
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't get trashed shop maps of user %s: %w", ownerID, err))
+	}
+
+	return lo.Map(rows, func(row sqlgen.GetTrashedByOwnerIDRow, _ int) trash.Item {
+		return trash.Item{
+			Kind:      trash.KindShopMap,
+			ID:        god.Believe(uuid.Parse(row.ID)),
+			Title:     row.Title,
+			DeletedBy: id.ID[user.User]{UUID: god.Believe(uuid.Parse(row.DeletedBy.String))},
+			DeletedAt: row.DeletedAt.Time,
+			ExpiresAt: time.Time{},
+		}
+	}), nil
+}
+
+// Restore takes map of the owner moved to the trash after given time back
+func (s *ShopMapRepo) Restore(
+	ctx context.Context,
+	mapID id.ID[shopmap.ShopMap],
+	ownerID id.ID[user.User],
+	after time.Time,
+) error {
+	restored, err := s.queries.RestoreShopMap(ctx, sqlgen.RestoreShopMapParams{
+		ID:        mapID.String(),
+		DeletedAt: sql.NullTime{Time: after.UTC(), Valid: true},
+		OwnerID:   ownerID.String(),
+	})
+	if err != nil {
+		return wrapErr(fmt.Errorf("can't restore shop map %s: %w", mapID, err))
+	}
+
+	if restored == 0 {
+		return fmt.Errorf("%w: shop map %s of user %s isn't in the trash", myerr.ErrNotFound, mapID, ownerID)
+	}
+
+	return nil
+}
+
+// GetTrashedBefore returns ids of at most limit maps moved to the trash before given time
+func (s *ShopMapRepo) GetTrashedBefore(ctx context.Context, before time.Time, limit int) (
+	[]id.ID[shopmap.ShopMap],
+	error,
+) {
+	mapIDs, err := s.queries.GetTrashedBefore(ctx, sqlgen.GetTrashedBeforeParams{
+		DeletedAt: sql.NullTime{Time: before.UTC(), Valid: true},
+		Limit:     int32(limit), //nolint:gosec // limit is a small constant
+	})
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't get expired shop maps: %w", err))
+	}
+
+	return lo.Map(mapIDs, func(mapID string, _ int) id.ID[shopmap.ShopMap] {
+		return id.ID[shopmap.ShopMap]{UUID: god.Believe(uuid.Parse(mapID))}
+	}), nil
+}
@@ -0,0 +1,134 @@
+package repo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/internal/backend/product"
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/shopmap/repo"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/date"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+// newRepo returns repo over sqlite, maps are created without categories and viewers,
+// as they are inserted by LOAD DATA of MySQL
+func newRepo(t *testing.T) *repo.ShopMapRepo {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "shopmap.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	r, err := repo.NewShopMapRepo(context.Background(), sqlDB)
+	require.NoError(t, err)
+
+	return r
+}
+
+func newShopMap(ownerID id.ID[user.User], title string) shopmap.ShopMap {
+	return shopmap.ShopMap{
+		Options: shopmap.Options{
+			CategoryList: []product.Category{},
+			ViewerIDList: []id.ID[user.User]{},
+			Title:        title,
+		},
+		ID:        id.NewID[shopmap.ShopMap](),
+		OwnerID:   ownerID,
+		CreatedAt: date.NewCreateDate[shopmap.ShopMap](),
+		UpdatedAt: date.NewUpdateDate[shopmap.ShopMap](),
+	}
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	ownerID, otherID := id.NewID[user.User](), id.NewID[user.User]()
+	deletedAt := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	corner, mall := newShopMap(ownerID, "corner"), newShopMap(ownerID, "mall")
+	require.NoError(t, r.Create(ctx, corner))
+	require.NoError(t, r.Create(ctx, mall))
+
+	require.NoError(t, r.Trash(ctx, corner.ID, otherID, deletedAt))
+
+	_, err := r.GetByID(ctx, corner.ID)
+	require.Error(t, err, "maps in the trash aren't returned")
+
+	models, err := r.GetByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+	require.Equal(t, mall.ID, models[0].ID)
+
+	items, err := r.GetTrash(ctx, ownerID, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []trash.Item{{
+		Kind:      trash.KindShopMap,
+		ID:        corner.ID.UUID,
+		Title:     "corner",
+		DeletedBy: otherID,
+		DeletedAt: deletedAt,
+		ExpiresAt: time.Time{},
+	}}, items)
+
+	items, err = r.GetTrash(ctx, otherID, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, items, "only the owner sees the map in the trash")
+
+	// the map can't be restored by others or after its retention is over
+	require.ErrorIs(t, r.Restore(ctx, corner.ID, otherID, deletedAt.Add(-time.Hour)), myerr.ErrNotFound)
+	require.ErrorIs(t, r.Restore(ctx, corner.ID, ownerID, deletedAt.Add(time.Hour)), myerr.ErrNotFound)
+	require.ErrorIs(t, r.Restore(ctx, mall.ID, ownerID, deletedAt.Add(-time.Hour)), myerr.ErrNotFound)
+
+	require.NoError(t, r.Restore(ctx, corner.ID, ownerID, deletedAt.Add(-time.Hour)))
+
+	restored, err := r.GetByID(ctx, corner.ID)
+	require.NoError(t, err)
+	require.Equal(t, "corner", restored.Title)
+
+	items, err = r.GetTrash(ctx, ownerID, deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, items)
+}
+
+func TestGetTrashedBefore(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	ownerID := id.NewID[user.User]()
+	deletedAt := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	maps := []shopmap.ShopMap{newShopMap(ownerID, "first"), newShopMap(ownerID, "second"), newShopMap(ownerID, "kept")}
+	for i, model := range maps {
+		require.NoError(t, r.Create(ctx, model))
+
+		if i < 2 {
+			require.NoError(t, r.Trash(ctx, model.ID, ownerID, deletedAt.Add(time.Duration(i)*time.Hour)))
+		}
+	}
+
+	expired, err := r.GetTrashedBefore(ctx, deletedAt.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[shopmap.ShopMap]{maps[0].ID, maps[1].ID}, expired)
+
+	expired, err = r.GetTrashedBefore(ctx, deletedAt.Add(2*time.Hour), 1)
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[shopmap.ShopMap]{maps[0].ID}, expired)
+
+	require.NoError(t, r.Delete(ctx, maps[0].ID))
+
+	expired, err = r.GetTrashedBefore(ctx, deletedAt.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, []id.ID[shopmap.ShopMap]{maps[1].ID}, expired)
+}
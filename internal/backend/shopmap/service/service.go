@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
@@ -13,7 +14,9 @@ import (
 
 	"go-backend/internal/backend/product"
 	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/trash"
 	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
 	"go-backend/pkg/date"
 	"go-backend/pkg/id"
 	"go-backend/pkg/myerr"
@@ -28,6 +31,10 @@ type repo interface {
 		updateFunc func(shopmap.ShopMap) (shopmap.ShopMap, error),
 	) (shopmap.ShopMap, error)
 	Delete(context.Context, id.ID[shopmap.ShopMap]) error
+	Trash(context.Context, id.ID[shopmap.ShopMap], id.ID[user.User], time.Time) error
+	GetTrash(context.Context, id.ID[user.User], time.Time) ([]trash.Item, error)
+	Restore(context.Context, id.ID[shopmap.ShopMap], id.ID[user.User], time.Time) error
+	GetTrashedBefore(context.Context, time.Time, int) ([]id.ID[shopmap.ShopMap], error)
 	GetByID(context.Context, id.ID[shopmap.ShopMap]) (shopmap.ShopMap, error)
 	GetByUserID(context.Context, id.ID[user.User]) ([]shopmap.ShopMap, error)
 }
@@ -36,24 +43,44 @@ type userService interface {
 	GetByID(ctx context.Context, userID id.ID[user.User]) (user.User, error)
 }
 
+// categories give canonical names of categories, shop maps keep them instead of names typed by users
+type categories interface {
+	Canonical(context.Context, []product.Category) ([]product.Category, error)
+}
+
 type Service struct {
-	log       zerolog.Logger
-	users     userService
-	repo      repo
-	validator *validator.Validate
+	log        zerolog.Logger
+	users      userService
+	repo       repo
+	categories categories
+	validator  *validator.Validate
+	clock      clock.Clock
 }
 
-func NewService(log zerolog.Logger, userService userService, repo repo) *Service {
+func NewService(
+	log zerolog.Logger,
+	userService userService,
+	repo repo,
+	categories categories,
+	clock clock.Clock,
+) *Service {
 	s := &Service{
-		log:       log.With().Str("component", "shopmap.service").Logger(),
-		users:     userService,
-		repo:      repo,
-		validator: validator.New(),
+		log:        log.With().Str("component", "shopmap.service").Logger(),
+		users:      userService,
+		repo:       repo,
+		categories: categories,
+		validator:  validator.New(),
+		clock:      clock,
 	}
 	return s
 }
 
 func (s *Service) Create(ctx context.Context, ownerID id.ID[user.User], cfg shopmap.Options) (shopmap.ShopMap, error) {
+	var err error
+	if cfg.CategoryList, err = s.canonical(ctx, cfg.CategoryList); err != nil {
+		return shopmap.ShopMap{}, err
+	}
+
 	shopMap := shopmap.ShopMap{
 		Options:   cfg,
 		ID:        id.NewID[shopmap.ShopMap](),
@@ -116,6 +143,7 @@ func (s *Service) RemoveViewerList(
 	})
 }
 
+// DeleteMap moves shop map to the trash, it can be restored until it's purged
 func (s *Service) DeleteMap(
 	ctx context.Context,
 	mapID id.ID[shopmap.ShopMap],
@@ -130,7 +158,11 @@ func (s *Service) DeleteMap(
 		return shopMap, fmt.Errorf("%w: only owner can delete shop map %s", myerr.ErrForbidden, mapID)
 	}
 
-	return shopMap, s.repoDelete(ctx, mapID)
+	if err = s.repo.Trash(ctx, mapID, userID, s.clock.Now()); err != nil {
+		return shopMap, fmt.Errorf("can't move shop map %s to the trash: %w", mapID, err)
+	}
+
+	return shopMap, nil
 }
 
 func (s *Service) UpdateMap(
@@ -141,6 +173,11 @@ func (s *Service) UpdateMap(
 ) (shopmap.ShopMap, error) {
 	s.log.Info().Stringer("map_id", mapID).Stringer("user_id", userID).Any("options", cfg).Msg("updating shopmap")
 
+	var err error
+	if cfg.CategoryList, err = s.canonical(ctx, cfg.CategoryList); err != nil {
+		return shopmap.ShopMap{}, err
+	}
+
 	return s.repoGetAndUpdate(ctx, mapID, func(shopMap shopmap.ShopMap) (shopmap.ShopMap, error) {
 		s.log.Debug().Stringer("map_id", mapID).Any("shopmap", shopMap).Msg("got shopmap from repo")
 		if userID != shopMap.OwnerID && !slices.Contains(shopMap.ViewerIDList, userID) {
@@ -166,6 +203,11 @@ func (s *Service) ReorderMap(
 	userID id.ID[user.User],
 	categories []product.Category,
 ) (shopmap.ShopMap, error) {
+	categories, err := s.canonical(ctx, categories)
+	if err != nil {
+		return shopmap.ShopMap{}, err
+	}
+
 	return s.repoGetAndUpdate(ctx, mapID, func(shopMap shopmap.ShopMap) (shopmap.ShopMap, error) {
 		if userID != shopMap.OwnerID {
 			return shopMap, fmt.Errorf("%w: only owner can change order", myerr.ErrForbidden)
@@ -181,6 +223,16 @@ func (s *Service) ReorderMap(
 	})
 }
 
+// canonical replaces categories by their canonical names, categories which become repeated are dropped
+func (s *Service) canonical(ctx context.Context, categories []product.Category) ([]product.Category, error) {
+	canonical, err := s.categories.Canonical(ctx, categories)
+	if err != nil {
+		return nil, wrapErr(fmt.Errorf("can't get canonical names of categories: %w", err))
+	}
+
+	return lo.Uniq(canonical), nil
+}
+
 func (s *Service) repoCreate(ctx context.Context, shopMap shopmap.ShopMap) error {
 	err := s.repo.Create(ctx, shopMap)
 	if err != nil {
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-backend/internal/backend/shopmap"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+// purgeBatch is the number of maps selected for purging at once
+const purgeBatch = 100
+
+// GetTrash returns maps of the owner moved to the trash after given time
+func (s *Service) GetTrash(ctx context.Context, userID id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	items, err := s.repo.GetTrash(ctx, userID, after)
+	if err != nil {
+		return nil, fmt.Errorf("can't get trashed shop maps of user %s: %w", userID, err)
+	}
+
+	return items, nil
+}
+
+// RestoreFromTrash takes map moved to the trash after given time back, only owner can restore it
+func (s *Service) RestoreFromTrash(
+	ctx context.Context,
+	userID id.ID[user.User],
+	entityID uuid.UUID,
+	after time.Time,
+) error {
+	mapID := id.ID[shopmap.ShopMap]{UUID: entityID}
+
+	if err := s.repo.Restore(ctx, mapID, userID, after); err != nil {
+		return fmt.Errorf("can't restore shop map %s: %w", mapID, err)
+	}
+
+	return nil
+}
+
+// PurgeTrash deletes maps moved to the trash before given time
+func (s *Service) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+
+	for {
+		mapIDs, err := s.repo.GetTrashedBefore(ctx, before, purgeBatch)
+		if err != nil {
+			return purged, fmt.Errorf("can't get expired shop maps: %w", err)
+		}
+
+		for _, mapID := range mapIDs {
+			if err = s.repoDelete(ctx, mapID); err != nil {
+				return purged, err
+			}
+		}
+
+		purged += len(mapIDs)
+		if len(mapIDs) < purgeBatch {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/auth/api"
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/trash/service"
+	"go-backend/pkg/api/rest/rerr"
+)
+
+type Handler struct {
+	rerr.BaseHandler
+
+	service *service.Service
+}
+
+func RegisterREST(r *gin.RouterGroup, service *service.Service, log zerolog.Logger) {
+	group := r.Group("/trash")
+
+	log = log.With().Str("component", "trash rest handler").Logger()
+	h := Handler{
+		BaseHandler: rerr.NewBaseHandler(log),
+		service:     service,
+	}
+
+	group.GET("", h.GetTrash)
+	group.POST("/:kind/:id/restore", h.Restore)
+}
+
+// @Summary get lists, shop maps and favorites of the user moved to the trash, which can be restored
+// @ID trash-get
+// @Tags Trash
+// @Produce json
+// @Success 200 {array} trash.Item
+// @Router /trash [get]
+// @Security ApiKeyAuth
+func (h *Handler) GetTrash(ctx *gin.Context) {
+	items, err := h.service.GetTrash(ctx, api.GetUserID(ctx))
+	if err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// @Summary restore entity from the trash
+// @ID trash-restore
+// @Tags Trash
+// @Param kind path string true "kind of entity" Enums(list, shopMap, favorites)
+// @Param id path string true "id of entity"
+// @Router /trash/{kind}/{id}/restore [post]
+// @Security ApiKeyAuth
+func (h *Handler) Restore(ctx *gin.Context) {
+	kind, err := trash.ParseKind(ctx.Param("kind"))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "unknown kind of entity")
+		return
+	}
+
+	entityID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "id must be valid UUID")
+		return
+	}
+
+	if err = h.service.Restore(ctx, api.GetUserID(ctx), kind, entityID); err != nil {
+		h.HandleError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,173 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version:
+// Revision:
+// Build Date:
+// Built By:
+
+package trash
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// KindList is a Kind of type List.
+	KindList Kind = iota + 1
+	// KindShopMap is a Kind of type ShopMap.
+	KindShopMap
+	// KindFavorites is a Kind of type Favorites.
+	KindFavorites
+)
+
+var ErrInvalidKind = fmt.Errorf("not a valid Kind, try [%s]", strings.Join(_KindNames, ", "))
+
+const _KindName = "listshopMapfavorites"
+
+var _KindNames = []string{
+	_KindName[0:4],
+	_KindName[4:11],
+	_KindName[11:20],
+}
+
+// KindNames returns a list of possible string values of Kind.
+func KindNames() []string {
+	tmp := make([]string, len(_KindNames))
+	copy(tmp, _KindNames)
+	return tmp
+}
+
+// KindValues returns a list of the values for Kind
+func KindValues() []Kind {
+	return []Kind{
+		KindList,
+		KindShopMap,
+		KindFavorites,
+	}
+}
+
+var _KindMap = map[Kind]string{
+	KindList:      _KindName[0:4],
+	KindShopMap:   _KindName[4:11],
+	KindFavorites: _KindName[11:20],
+}
+
+// String implements the Stringer interface.
+func (x Kind) String() string {
+	if str, ok := _KindMap[x]; ok {
+		return str
+	}
+	return fmt.Sprintf("Kind(%d)", x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x Kind) IsValid() bool {
+	_, ok := _KindMap[x]
+	return ok
+}
+
+var _KindValue = map[string]Kind{
+	_KindName[0:4]:   KindList,
+	_KindName[4:11]:  KindShopMap,
+	_KindName[11:20]: KindFavorites,
+}
+
+// ParseKind attempts to convert a string to a Kind.
+func ParseKind(name string) (Kind, error) {
+	if x, ok := _KindValue[name]; ok {
+		return x, nil
+	}
+	return Kind(0), fmt.Errorf("%s is %w", name, ErrInvalidKind)
+}
+
+// MarshalText implements the text marshaller method.
+func (x Kind) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *Kind) UnmarshalText(text []byte) error {
+	name := string(text)
+	tmp, err := ParseKind(name)
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+var errKindNilPtr = errors.New("value pointer is nil") // one per type for package clashes
+
+// Scan implements the Scanner interface.
+func (x *Kind) Scan(value interface{}) (err error) {
+	if value == nil {
+		*x = Kind(0)
+		return
+	}
+
+	// A wider range of scannable types.
+	// driver.Value values at the top of the list for expediency
+	switch v := value.(type) {
+	case int64:
+		*x = Kind(v)
+	case string:
+		*x, err = ParseKind(v)
+	case []byte:
+		*x, err = ParseKind(string(v))
+	case Kind:
+		*x = v
+	case int:
+		*x = Kind(v)
+	case *Kind:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = *v
+	case uint:
+		*x = Kind(v)
+	case uint64:
+		*x = Kind(v)
+	case *int:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = Kind(*v)
+	case *int64:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = Kind(*v)
+	case float64: // json marshals everything as a float64 if it's a number
+		*x = Kind(v)
+	case *float64: // json marshals everything as a float64 if it's a number
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = Kind(*v)
+	case *uint:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = Kind(*v)
+	case *uint64:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x = Kind(*v)
+	case *string:
+		if v == nil {
+			return errKindNilPtr
+		}
+		*x, err = ParseKind(*v)
+	}
+
+	return
+}
+
+// Value implements the driver Valuer interface.
+func (x Kind) Value() (driver.Value, error) {
+	return x.String(), nil
+}
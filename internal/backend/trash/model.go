@@ -0,0 +1,25 @@
+package trash
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/id"
+)
+
+//go:generate python $GOENUM
+
+// ENUM(list=1, shopMap, favorites)
+type Kind int32
+
+// Item is an entity moved to the trash, it can be restored until ExpiresAt and it's purged after that
+type Item struct {
+	Kind      Kind             `json:"kind" swaggertype:"string"`
+	ID        uuid.UUID        `json:"id" swaggertype:"string"`
+	Title     string           `json:"title"`
+	DeletedBy id.ID[user.User] `json:"deleted_by" swaggertype:"string"`
+	DeletedAt time.Time        `json:"deleted_at"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
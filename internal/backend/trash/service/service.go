@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const (
+	// defaultRetention is used when retention isn't configured
+	defaultRetention = 30 * 24 * time.Hour
+	// purgeInterval is how often expired entities are purged
+	purgeInterval = time.Hour
+)
+
+// Bin keeps entities of one kind moved to the trash
+type Bin interface {
+	// GetTrash returns entities moved to the trash after given time, which user can restore
+	GetTrash(context.Context, id.ID[user.User], time.Time) ([]trash.Item, error)
+	// RestoreFromTrash takes entity moved to the trash after given time back
+	RestoreFromTrash(context.Context, id.ID[user.User], uuid.UUID, time.Time) error
+	// PurgeTrash deletes entities moved to the trash before given time and returns their number
+	PurgeTrash(context.Context, time.Time) (int, error)
+}
+
+// Service lists, restores and purges entities of every kind moved to the trash
+type Service struct {
+	bins      map[trash.Kind]Bin
+	retention time.Duration
+	clock     clock.Clock
+	log       zerolog.Logger
+}
+
+func NewService(bins map[trash.Kind]Bin, retention time.Duration, clock clock.Clock, log zerolog.Logger) *Service {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return &Service{
+		bins:      bins,
+		retention: retention,
+		clock:     clock,
+		log:       log.With().Str("component", "trash service").Logger(),
+	}
+}
+
+// GetTrash returns entities of user which can be restored yet, recently deleted entities go first
+func (s *Service) GetTrash(ctx context.Context, userID id.ID[user.User]) ([]trash.Item, error) {
+	after := s.clock.Now().Add(-s.retention)
+	items := []trash.Item{}
+
+	for _, kind := range trash.KindValues() {
+		bin, found := s.bins[kind]
+		if !found {
+			continue
+		}
+
+		binItems, err := bin.GetTrash(ctx, userID, after)
+		if err != nil {
+			return nil, fmt.Errorf("can't get trash of kind %s: %w", kind, err)
+		}
+
+		for _, item := range binItems {
+			item.Kind = kind
+			item.ExpiresAt = item.DeletedAt.Add(s.retention)
+			items = append(items, item)
+		}
+	}
+
+	slices.SortStableFunc(items, func(a, b trash.Item) int { return b.DeletedAt.Compare(a.DeletedAt) })
+
+	return items, nil
+}
+
+// Restore takes entity back from the trash if its retention isn't over
+func (s *Service) Restore(ctx context.Context, userID id.ID[user.User], kind trash.Kind, entityID uuid.UUID) error {
+	bin, found := s.bins[kind]
+	if !found {
+		return fmt.Errorf("%w: unknown kind %s", myerr.ErrInvalidArgument, kind)
+	}
+
+	if err := bin.RestoreFromTrash(ctx, userID, entityID, s.clock.Now().Add(-s.retention)); err != nil {
+		return fmt.Errorf("can't restore %s %s: %w", kind, entityID, err)
+	}
+
+	return nil
+}
+
+// RunPurge purges expired entities until ctx is done
+func (s *Service) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeExpired(ctx); err != nil {
+			s.log.Error().Err(err).Msg("can't purge trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired deletes entities which retention is over and returns their number
+func (s *Service) PurgeExpired(ctx context.Context) (int, error) {
+	before := s.clock.Now().Add(-s.retention)
+	purged := 0
+
+	for _, kind := range trash.KindValues() {
+		bin, found := s.bins[kind]
+		if !found {
+			continue
+		}
+
+		count, err := bin.PurgeTrash(ctx, before)
+		purged += count
+
+		if err != nil {
+			return purged, fmt.Errorf("can't purge trash of kind %s: %w", kind, err)
+		}
+	}
+
+	if purged != 0 {
+		s.log.Info().Int("purged", purged).Msg("purged expired trash")
+	}
+
+	return purged, nil
+}
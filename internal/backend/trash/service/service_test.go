@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"go-backend/internal/backend/trash"
+	"go-backend/internal/backend/trash/service"
+	"go-backend/internal/backend/user"
+	"go-backend/pkg/clock"
+	"go-backend/pkg/id"
+	"go-backend/pkg/myerr"
+)
+
+const retention = 7 * 24 * time.Hour
+
+// bin keeps items in memory and records times passed to it
+type bin struct {
+	lock     sync.Mutex
+	items    []trash.Item
+	purged   []time.Time
+	restored []time.Time
+	err      error
+}
+
+func (b *bin) GetTrash(_ context.Context, _ id.ID[user.User], after time.Time) ([]trash.Item, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var items []trash.Item
+	for _, item := range b.items {
+		if item.DeletedAt.After(after) {
+			items = append(items, item)
+		}
+	}
+
+	return items, b.err
+}
+
+func (b *bin) RestoreFromTrash(_ context.Context, _ id.ID[user.User], _ uuid.UUID, after time.Time) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.restored = append(b.restored, after)
+
+	return b.err
+}
+
+func (b *bin) PurgeTrash(_ context.Context, before time.Time) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.purged = append(b.purged, before)
+	purged := 0
+	for _, item := range b.items {
+		if item.DeletedAt.Before(before) {
+			purged++
+		}
+	}
+
+	return purged, b.err
+}
+
+func (b *bin) purges() []time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.purged
+}
+
+func TestGetTrash(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)
+	userID := id.NewID[user.User]()
+	deleted := func(ago time.Duration) trash.Item {
+		return trash.Item{ID: uuid.New(), DeletedBy: userID, DeletedAt: now.Add(-ago)}
+	}
+
+	lists := &bin{items: []trash.Item{deleted(time.Hour), deleted(8 * 24 * time.Hour)}}
+	shopMaps := &bin{items: []trash.Item{deleted(time.Minute), deleted(3 * time.Hour)}}
+	s := service.NewService(map[trash.Kind]service.Bin{trash.KindList: lists, trash.KindShopMap: shopMaps},
+		retention, clock.NewFake(now), zerolog.Nop())
+
+	items, err := s.GetTrash(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, items, 3, "items deleted before retention aren't listed")
+
+	require.Equal(t, []trash.Kind{trash.KindShopMap, trash.KindList, trash.KindShopMap},
+		[]trash.Kind{items[0].Kind, items[1].Kind, items[2].Kind}, "recently deleted items go first")
+	require.Equal(t, now.Add(-time.Minute).Add(retention), items[0].ExpiresAt)
+
+	shopMaps.err = errors.New("unavailable")
+	_, err = s.GetTrash(context.Background(), userID)
+	require.Error(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)
+	lists := &bin{}
+	s := service.NewService(map[trash.Kind]service.Bin{trash.KindList: lists}, retention, clock.NewFake(now),
+		zerolog.Nop())
+
+	require.NoError(t, s.Restore(context.Background(), id.NewID[user.User](), trash.KindList, uuid.New()))
+	require.Equal(t, []time.Time{now.Add(-retention)}, lists.restored)
+
+	err := s.Restore(context.Background(), id.NewID[user.User](), trash.KindFavorites, uuid.New())
+	require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+}
+
+func TestRunPurge(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	expired := trash.Item{DeletedAt: now.Add(-retention - time.Minute)}
+	kept := trash.Item{DeletedAt: now.Add(-time.Minute)}
+
+	lists := &bin{items: []trash.Item{expired, kept}}
+	favorites := &bin{items: []trash.Item{expired}, err: errors.New("unavailable")}
+	s := service.NewService(map[trash.Kind]service.Bin{trash.KindList: lists, trash.KindFavorites: favorites},
+		retention, fake, zerolog.Nop())
+
+	// an error of one kind is returned with the number purged before it
+	purged, err := s.PurgeExpired(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 2, purged)
+
+	favorites.err = nil
+	fake.Add(time.Hour)
+
+	// the first purge is done at once, then the job waits for the next tick until ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		s.RunPurge(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(favorites.purges()) == 2 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	require.Equal(t, []time.Time{now.Add(-retention), now.Add(time.Hour - retention)}, lists.purges())
+	require.Equal(t, lists.purges(), favorites.purges())
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -46,6 +48,9 @@ func (h BaseHandler) HandleError(c *gin.Context, err error) {
 	case errors.Is(err, myerr.ErrNotFound):
 		c.String(http.StatusNotFound, err.Error())
 		return
+	case errors.Is(err, myerr.ErrConflict):
+		c.String(http.StatusConflict, err.Error())
+		return
 	default:
 		c.String(http.StatusInternalServerError, "internal error")
 		return
@@ -80,6 +85,43 @@ func QueryID[T any](ctx *gin.Context, name string) (id.ID[T], bool) {
 	return id.ID[T]{UUID: uuidID}, true
 }
 
+// QueryInt returns integer query parameter or defaultValue if parameter is not set
+func QueryInt(ctx *gin.Context, name string, defaultValue int) (int, bool) {
+	rawValue, found := ctx.GetQuery(name)
+	if !found {
+		return defaultValue, true
+	}
+
+	value, err := strconv.Atoi(rawValue)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "%s must be integer", name)
+		return 0, false
+	}
+
+	return value, true
+}
+
+// QueryTime returns RFC3339 timestamp or date query parameter or defaultValue if parameter is not set,
+// date is the beginning of the day in UTC
+func QueryTime(ctx *gin.Context, name string, defaultValue time.Time) (time.Time, bool) {
+	rawValue, found := ctx.GetQuery(name)
+	if !found {
+		return defaultValue, true
+	}
+
+	if value, err := time.Parse(time.RFC3339, rawValue); err == nil {
+		return value, true
+	}
+
+	value, err := time.Parse(time.DateOnly, rawValue)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "%s must be RFC3339 timestamp or date", name)
+		return time.Time{}, false
+	}
+
+	return value, true
+}
+
 func (h BaseHandler) Decode(c *gin.Context, obj any) bool {
 	if err := c.BindJSON(obj); err != nil {
 		h.log.Info().Ctx(c).Err(err).Msg("decoding request failed")
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -61,3 +62,83 @@ func TestPathID(t *testing.T) {
 		require.Equal(t, uuid.Nil, id.UUID)
 	})
 }
+
+func TestQueryInt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("valid query int", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/?limit=15", nil)
+
+		value, ok := rerr.QueryInt(ctx, "limit", 10)
+		require.True(t, ok)
+		require.Equal(t, 15, value)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+		value, ok := rerr.QueryInt(ctx, "limit", 10)
+		require.True(t, ok)
+		require.Equal(t, 10, value)
+	})
+
+	t.Run("invalid query int", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/?limit=ten", nil)
+
+		_, ok := rerr.QueryInt(ctx, "limit", 10)
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestQueryTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defaultValue := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("timestamp", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/?from=2024-05-02T10:30:00Z", nil)
+
+		value, ok := rerr.QueryTime(ctx, "from", defaultValue)
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, time.May, 2, 10, 30, 0, 0, time.UTC), value)
+	})
+
+	t.Run("date", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/?from=2024-05-02", nil)
+
+		value, ok := rerr.QueryTime(ctx, "from", defaultValue)
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC), value)
+	})
+
+	t.Run("default value", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+		value, ok := rerr.QueryTime(ctx, "from", defaultValue)
+		require.True(t, ok)
+		require.Equal(t, defaultValue, value)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/?from=yesterday", nil)
+
+		_, ok := rerr.QueryTime(ctx, "from", defaultValue)
+		require.False(t, ok)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,115 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"go-backend/pkg/myerr"
+)
+
+const (
+	dirPerm  = 0o750
+	filePerm = 0o640
+)
+
+// Store keeps binary objects by keys, keys are slash separated paths
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore keeps objects as files in a directory of local filesystem
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("can't create blob directory %s: %w", dir, err)
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Put writes object to temporary file and renames it, so readers never see partially written objects
+func (s *FileStore) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("can't create directory for blob %s: %w", key, err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("can't create file for blob %s: %w", key, err)
+	}
+	defer os.Remove(file.Name()) //nolint:errcheck // file is already renamed on success
+
+	if _, err = io.Copy(file, r); err != nil {
+		file.Close()
+		return fmt.Errorf("can't write blob %s: %w", key, err)
+	}
+
+	if err = file.Chmod(filePerm); err != nil {
+		file.Close()
+		return fmt.Errorf("can't change mode of blob %s: %w", key, err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("can't close blob %s: %w", key, err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("can't save blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: blob %s", myerr.ErrNotFound, key)
+	} else if err != nil {
+		return nil, fmt.Errorf("can't open blob %s: %w", key, err)
+	}
+
+	return file, nil
+}
+
+// Delete removes object, it's not an error if object doesn't exist
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("can't delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	localPath := filepath.FromSlash(key)
+	if !filepath.IsLocal(localPath) {
+		return "", fmt.Errorf("%w: blob key %s is not local path", myerr.ErrInvalidArgument, key)
+	}
+
+	return filepath.Join(s.dir, localPath), nil
+}
@@ -0,0 +1,80 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/pkg/blob"
+	"go-backend/pkg/myerr"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	testStore(t, store)
+
+	t.Run("key outside of store", func(t *testing.T) {
+		err := store.Put(ctx, "../escaped", strings.NewReader("content"))
+		require.ErrorIs(t, err, myerr.ErrInvalidArgument)
+	})
+}
+
+func TestDBStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blob.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	store, err := blob.NewDBStore(context.Background(), db)
+	require.NoError(t, err)
+
+	testStore(t, store)
+}
+
+func testStore(t *testing.T, store blob.Store) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	read := func(t *testing.T, key string) string {
+		t.Helper()
+
+		r, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		defer r.Close()
+
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	t.Run("put and get", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "lists/a/b", strings.NewReader("content")))
+		require.Equal(t, "content", read(t, "lists/a/b"))
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "lists/a/c", strings.NewReader("old")))
+		require.NoError(t, store.Put(ctx, "lists/a/c", strings.NewReader("new")))
+		require.Equal(t, "new", read(t, "lists/a/c"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "deleted", strings.NewReader("content")))
+		require.NoError(t, store.Delete(ctx, "deleted"))
+		require.NoError(t, store.Delete(ctx, "deleted"))
+
+		_, err := store.Get(ctx, "deleted")
+		require.ErrorIs(t, err, myerr.ErrNotFound)
+	})
+}
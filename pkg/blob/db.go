@@ -0,0 +1,74 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-backend/pkg/myerr"
+)
+
+// Blob is an object kept in the database
+type Blob struct {
+	Key       string    `gorm:"primaryKey;size:255;notNull"`
+	Content   []byte    `gorm:"notNull"`
+	UpdatedAt time.Time `gorm:"notNull"`
+}
+
+// DBStore keeps objects in a table of the database, so every replica of the service sees the same objects
+type DBStore struct {
+	db *gorm.DB
+}
+
+func NewDBStore(ctx context.Context, db *gorm.DB) (*DBStore, error) {
+	if err := db.WithContext(ctx).AutoMigrate(new(Blob)); err != nil {
+		return nil, fmt.Errorf("can't create blob table: %w", err)
+	}
+
+	return &DBStore{db: db}, nil
+}
+
+// Put replaces object in one statement, so readers never see partially written objects
+func (s *DBStore) Put(ctx context.Context, key string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read blob %s: %w", key, err)
+	}
+
+	err = s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}). //nolint:exhaustruct
+		Create(&Blob{Key: key, Content: content, UpdatedAt: time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("can't save blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *DBStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	var entity Blob
+
+	err := s.db.WithContext(ctx).Where("`key` = ?", key).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: blob %s", myerr.ErrNotFound, key)
+	} else if err != nil {
+		return nil, fmt.Errorf("can't select blob %s: %w", key, err)
+	}
+
+	return io.NopCloser(bytes.NewReader(entity.Content)), nil
+}
+
+// Delete removes object, it's not an error if object doesn't exist
+func (s *DBStore) Delete(ctx context.Context, key string) error {
+	if err := s.db.WithContext(ctx).Where("`key` = ?", key).Delete(new(Blob)).Error; err != nil {
+		return fmt.Errorf("can't delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package clock abstracts current time, so time-driven code can be tested with a fake clock.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells current time
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is a clock of the system
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a clock which is moved only manually, it's safe for concurrent use
+type Fake struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewFake returns fake clock stopped at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{lock: sync.Mutex{}, now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.now
+}
+
+// Set moves clock to now
+func (f *Fake) Set(now time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.now = now
+}
+
+// Add moves clock forward by d
+func (f *Fake) Add(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.now = f.now.Add(d)
+}
@@ -0,0 +1,214 @@
+// Package cron parses classic cron expressions and finds times when they fire.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+// searchLimit bounds search of the next activation, expressions like "0 0 30 2 *" never fire
+const searchLimit = 5
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var fields = [...]field{
+	{name: "minute", min: 0, max: 59, names: nil},
+	{name: "hour", min: 0, max: 23, names: nil},
+	{name: "day of month", min: 1, max: 31, names: nil},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	// both 0 and 7 are sunday
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// macros are shortcuts of common expressions
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// Schedule is a parsed expression of five fields: minute, hour, day of month, month and day of week.
+// Fields are numbers, names of months and weekdays, ranges, lists and steps like "*/15" or "mon-fri".
+// As in classic cron, when both day fields are restricted, a day matches if either of them matches.
+type Schedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// anyDay is true if day of month or day of week is "*"
+	anyDay bool
+}
+
+// Parse parses expression of five fields or one of macros @hourly, @daily, @weekly, @monthly and @yearly
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, found := macros[expr]; found {
+		expr = macro
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf("%w: %q has %d fields instead of %d", ErrInvalidExpression, expr, len(parts),
+			len(fields))
+	}
+
+	var bits [len(fields)]uint64
+
+	for i, part := range parts {
+		var err error
+		if bits[i], err = parseField(strings.ToLower(part), fields[i]); err != nil {
+			return Schedule{}, err
+		}
+	}
+
+	weekdays := bits[4]
+	if weekdays&(1<<7) != 0 {
+		weekdays |= 1
+	}
+
+	return Schedule{
+		minutes:  bits[0],
+		hours:    bits[1],
+		days:     bits[2],
+		months:   bits[3],
+		weekdays: weekdays,
+		anyDay:   strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// MustParse is like Parse but panics if expression is invalid
+func MustParse(expr string) Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Next returns the first time after t when schedule fires, it's in location of t.
+// Zero time is returned if schedule doesn't fire in the next five years.
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.AddDate(searchLimit, 0, 0)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+
+		switch {
+		case !has(s.months, int(month)):
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case !has(s.hours, t.Hour()):
+			next := time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+			// wall clock goes back when daylight saving time ends
+			if !next.After(t) {
+				next = t.Truncate(time.Hour).Add(time.Hour)
+			}
+
+			t = next
+		case !has(s.minutes, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	byDay := has(s.days, t.Day())
+	byWeekday := has(s.weekdays, int(t.Weekday()))
+
+	if s.anyDay {
+		return byDay && byWeekday
+	}
+
+	return byDay || byWeekday
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
+
+// parseField parses comma separated list of values, ranges and steps to bit set
+func parseField(raw string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(raw, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: bad step %q of %s", ErrInvalidExpression, stepPart, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			if low, err = parseValue(from, f); err != nil {
+				return 0, err
+			}
+
+			switch {
+			case isRange:
+				if high, err = parseValue(to, f); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				high = low
+			}
+
+			if low > high {
+				return 0, fmt.Errorf("%w: bad range %q of %s", ErrInvalidExpression, rangePart, f.name)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(raw string, f field) (int, error) {
+	if value, found := f.names[raw]; found {
+		return value, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("%w: %s must be from %d to %d, got %q", ErrInvalidExpression, f.name, f.min, f.max, raw)
+	}
+
+	return value, nil
+}
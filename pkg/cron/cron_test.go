@@ -0,0 +1,116 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go-backend/pkg/cron"
+)
+
+func TestNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2026-10-16 is friday
+	base := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "every minute",
+			expr:     "* * * * *",
+			after:    base.Add(20 * time.Second),
+			expected: base.Add(time.Minute),
+		},
+		{
+			name:     "later today",
+			expr:     "0 18 * * *",
+			after:    base,
+			expected: time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly on friday fires a week later at the same time",
+			expr:     "30 9 * * fri",
+			after:    base,
+			expected: time.Date(2026, 10, 23, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as 7",
+			expr:     "0 10 * * 7",
+			after:    base,
+			expected: time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "steps",
+			expr:     "*/20 9-17/4 * * *",
+			after:    base,
+			expected: time.Date(2026, 10, 16, 9, 40, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly skips months without the day",
+			expr:     "0 8 31 * *",
+			after:    time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 12, 31, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "either day of month or day of week",
+			expr:     "0 0 1 * mon",
+			after:    base,
+			expected: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap day",
+			expr:     "0 0 29 feb *",
+			after:    base,
+			expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "time zone",
+			expr:     "0 9 * * *",
+			after:    base.In(berlin),
+			expected: time.Date(2026, 10, 17, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:     "missing hour at start of daylight saving time",
+			expr:     "30 2 * * *",
+			after:    time.Date(2026, 3, 29, 0, 0, 0, 0, berlin),
+			expected: time.Date(2026, 3, 30, 2, 30, 0, 0, berlin),
+		},
+		{
+			name:     "macro",
+			expr:     "@monthly",
+			after:    base,
+			expected: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "never",
+			expr:     "0 0 30 feb *",
+			after:    base,
+			expected: time.Time{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schedule, err := cron.Parse(c.expr)
+			require.NoError(t, err)
+			require.True(t, c.expected.Equal(schedule.Next(c.after)), "got %s", schedule.Next(c.after))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * * funday", "@often"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := cron.Parse(expr)
+			require.ErrorIs(t, err, cron.ErrInvalidExpression)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package dbtx shares a gorm transaction between repositories through a context
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// transaction is a running transaction, functions called after it's committed and values collected for them
+type transaction struct {
+	db        *gorm.DB
+	lock      sync.Mutex
+	committed []func()
+	collected map[any]any
+}
+
+// Run calls f in a transaction of db, repositories use the transaction if they get their connection by DB(ctx, ...).
+// If ctx already carries a transaction, f joins it and the outer Run commits it.
+func Run(ctx context.Context, db *gorm.DB, f func(ctx context.Context, tx *gorm.DB) error) error {
+	if outer, found := ctx.Value(txKey{}).(*transaction); found {
+		return f(ctx, outer.db)
+	}
+
+	current := &transaction{db: nil, lock: sync.Mutex{}, committed: nil, collected: map[any]any{}}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current.db = tx
+		return f(context.WithValue(ctx, txKey{}, current), tx)
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, callback := range current.committed {
+		callback()
+	}
+
+	return nil
+}
+
+// DB returns the transaction carried by ctx or db if there is no transaction
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if current, found := ctx.Value(txKey{}).(*transaction); found {
+		return current.db.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
+
+// Conn returns connection of the transaction carried by ctx or db if there is no transaction,
+// repositories over database/sql join transactions of the same database by it
+func Conn(ctx context.Context, db *sql.DB) gorm.ConnPool {
+	if current, found := ctx.Value(txKey{}).(*transaction); found {
+		return current.db.Statement.ConnPool
+	}
+
+	return db
+}
+
+// AfterCommit calls f after the transaction carried by ctx is committed, or at once if there is no transaction.
+// It's used to update in-memory state only when changes are saved.
+func AfterCommit(ctx context.Context, f func()) {
+	current, found := ctx.Value(txKey{}).(*transaction)
+	if !found {
+		f()
+		return
+	}
+
+	current.lock.Lock()
+	defer current.lock.Unlock()
+
+	current.committed = append(current.committed, f)
+}
+
+// Collect adds value to values of the key in the transaction carried by ctx, f gets all of them once
+// after the transaction is committed. Without a transaction f gets the value at once.
+// It's used to make one record of changes made by several repositories in one transaction.
+func Collect[T any](ctx context.Context, key any, value T, f func([]T)) {
+	current, found := ctx.Value(txKey{}).(*transaction)
+	if !found {
+		f([]T{value})
+		return
+	}
+
+	current.lock.Lock()
+	defer current.lock.Unlock()
+
+	if values, found := current.collected[key].(*[]T); found {
+		*values = append(*values, value)
+		return
+	}
+
+	values := &[]T{value}
+	current.collected[key] = values
+	current.committed = append(current.committed, func() { f(*values) })
+}
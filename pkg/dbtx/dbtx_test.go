@@ -0,0 +1,132 @@
+package dbtx_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"go-backend/pkg/dbtx"
+)
+
+type record struct {
+	Name string `gorm:"primaryKey"`
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(new(record)))
+
+	count := func() int64 {
+		var n int64
+		require.NoError(t, db.Model(new(record)).Count(&n).Error)
+
+		return n
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		committed := false
+
+		err := dbtx.Run(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+			require.NoError(t, tx.Create(&record{Name: "outer"}).Error)
+
+			// nested run and DB of ctx join the outer transaction
+			return dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+				dbtx.AfterCommit(ctx, func() { committed = true })
+				require.False(t, committed)
+
+				return dbtx.DB(ctx, db).Create(&record{Name: "inner"}).Error
+			})
+		})
+		require.NoError(t, err)
+		require.True(t, committed)
+		require.EqualValues(t, 2, count())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		committed := false
+
+		err := dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+			dbtx.AfterCommit(ctx, func() { committed = true })
+			require.NoError(t, dbtx.DB(ctx, db).Create(&record{Name: "rolled back"}).Error)
+
+			return errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+		require.False(t, committed)
+		require.EqualValues(t, 2, count())
+	})
+
+	t.Run("conn", func(t *testing.T) {
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+
+		errFailed := errors.New("failed")
+		err = dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+			_, err := dbtx.Conn(ctx, sqlDB).ExecContext(ctx, "INSERT INTO records (name) VALUES ('sql')")
+			require.NoError(t, err)
+
+			return errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+		require.EqualValues(t, 2, count(), "statements of database/sql are rolled back with the transaction")
+
+		_, err = dbtx.Conn(ctx, sqlDB).ExecContext(ctx, "INSERT INTO records (name) VALUES ('sql')")
+		require.NoError(t, err)
+		require.EqualValues(t, 3, count())
+	})
+
+	t.Run("without transaction", func(t *testing.T) {
+		committed := false
+
+		dbtx.AfterCommit(ctx, func() { committed = true })
+		require.True(t, committed)
+	})
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	type key struct{}
+
+	var calls [][]string
+
+	collect := func(ctx context.Context, value string) {
+		dbtx.Collect(ctx, key{}, value, func(values []string) { calls = append(calls, values) })
+	}
+
+	err = dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+		collect(ctx, "first")
+
+		return dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+			collect(ctx, "second")
+			require.Empty(t, calls)
+
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"first", "second"}}, calls, "values of a transaction are passed once")
+
+	err = dbtx.Run(ctx, db, func(ctx context.Context, _ *gorm.DB) error {
+		collect(ctx, "rolled back")
+		return errors.New("failed")
+	})
+	require.Error(t, err)
+	require.Len(t, calls, 1)
+
+	collect(ctx, "without transaction")
+	require.Equal(t, []string{"without transaction"}, calls[1])
+}
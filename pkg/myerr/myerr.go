@@ -8,4 +8,5 @@ var (
 	ErrAlreadyExists   = errors.New("already exists")
 	ErrForbidden       = errors.New("forbidden")
 	ErrInternal        = errors.New("internal error")
+	ErrConflict        = errors.New("conflict")
 )
@@ -0,0 +1,8 @@
+package mymysql
+
+import "strings"
+
+// EscapeLike escapes wildcards of LIKE pattern with '!', the pattern must be used with ESCAPE '!'
+func EscapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,405 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// composite glyph flags used to walk components
+const (
+	argsAreWords   = 0x0001
+	haveScale      = 0x0008
+	moreComponents = 0x0020
+	haveXYScale    = 0x0040
+	haveTwoByTwo   = 0x0080
+)
+
+// missingGlyphRune is drawn instead of runes missing in the font
+const missingGlyphRune = '?'
+
+// subsetTables are tables of TrueType font kept in subsets, layout tables aren't needed to draw single glyphs
+var subsetTables = []string{
+	"OS/2", "cmap", "cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post", "prep",
+}
+
+var errBadFont = errors.New("malformed font")
+
+// font is an embedded TrueType font, only glyphs used in a document are written into the document.
+// Text is drawn by glyph ids, so every rune of the font can be used.
+type font struct {
+	name   string
+	data   []byte
+	parsed *sfnt.Font
+	buf    sfnt.Buffer
+	// glyphs are used glyph ids by runes
+	glyphs map[rune]uint16
+}
+
+func newFont(name string, data []byte) (*font, error) {
+	parsed, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse font %s: %w", name, err)
+	}
+
+	return &font{name: name, data: data, parsed: parsed, buf: sfnt.Buffer{}, glyphs: map[rune]uint16{}}, nil
+}
+
+// encode returns hex string of glyph ids of text, runes missing in the font are drawn as question marks
+func (f *font) encode(text string) string {
+	var b strings.Builder
+
+	b.WriteByte('<')
+
+	for _, r := range text {
+		fmt.Fprintf(&b, "%04X", f.glyph(r))
+	}
+
+	b.WriteByte('>')
+
+	return b.String()
+}
+
+func (f *font) glyph(r rune) uint16 {
+	if glyph, found := f.glyphs[r]; found {
+		return glyph
+	}
+
+	glyph, err := f.parsed.GlyphIndex(&f.buf, r)
+	if (err != nil || glyph == 0) && r != missingGlyphRune {
+		return f.glyph(missingGlyphRune)
+	}
+
+	f.glyphs[r] = uint16(glyph)
+
+	return uint16(glyph)
+}
+
+// baseName is a name of the subset, its prefix depends on used glyphs as PDF requires
+func (f *font) baseName() string {
+	h := fnv.New32a()
+	for _, r := range slices.Sorted(maps.Keys(f.glyphs)) {
+		fmt.Fprint(h, r)
+	}
+
+	sum := h.Sum32()
+	tag := make([]byte, 0, 6)
+
+	for range 6 {
+		tag = append(tag, byte('A'+sum%26))
+		sum /= 26
+	}
+
+	return string(tag) + "+" + f.name
+}
+
+// toUnicode returns CMap which maps glyph ids back to runes, so text of document can be copied and searched
+func (f *font) toUnicode() []byte {
+	var b bytes.Buffer
+
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	mapped := map[uint16]rune{}
+	for _, r := range slices.Sorted(maps.Keys(f.glyphs)) {
+		if _, found := mapped[f.glyphs[r]]; !found {
+			mapped[f.glyphs[r]] = r
+		}
+	}
+
+	glyphs := slices.Sorted(maps.Keys(mapped))
+	// a block of bfchar mappings can't be longer than 100 entries
+	for chunk := range slices.Chunk(glyphs, 100) {
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+
+		for _, glyph := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", glyph)
+
+			for _, c := range utf16.Encode([]rune{mapped[glyph]}) {
+				fmt.Fprintf(&b, "%04X", c)
+			}
+
+			b.WriteString(">\n")
+		}
+
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+
+	return b.Bytes()
+}
+
+// metrics are font-wide metrics in thousandths of font size
+type metrics struct {
+	bbox    [4]int
+	ascent  int
+	descent int
+	width   int
+}
+
+func (f *font) metrics() (metrics, error) {
+	tables, err := f.tables()
+	if err != nil {
+		return metrics{}, err
+	}
+
+	head, hhea, hmtx := tables["head"], tables["hhea"], tables["hmtx"]
+	if len(head) < 54 || len(hhea) < 36 || len(hmtx) < 2 {
+		return metrics{}, fmt.Errorf("%w: %s has short metric tables", errBadFont, f.name)
+	}
+
+	unitsPerEm := int(binary.BigEndian.Uint16(head[18:]))
+	if unitsPerEm == 0 {
+		return metrics{}, fmt.Errorf("%w: %s has zero units per em", errBadFont, f.name)
+	}
+
+	scale := func(b []byte) int {
+		return int(int16(binary.BigEndian.Uint16(b))) * 1000 / unitsPerEm //nolint:gosec
+	}
+
+	return metrics{
+		bbox:    [4]int{scale(head[36:]), scale(head[38:]), scale(head[40:]), scale(head[42:])},
+		ascent:  scale(hhea[4:]),
+		descent: scale(hhea[6:]),
+		// the font is monospaced, so the advance of the first glyph is the width of every glyph
+		width: scale(hmtx[0:]),
+	}, nil
+}
+
+// subset returns the font with outlines of used glyphs only, glyph ids aren't changed
+func (f *font) subset() ([]byte, error) {
+	tables, err := f.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	head, maxp := slices.Clone(tables["head"]), tables["maxp"]
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, fmt.Errorf("%w: %s has short head or maxp table", errBadFont, f.name)
+	}
+
+	locations, err := glyphLocations(tables["loca"], int(binary.BigEndian.Uint16(maxp[4:])), head[51] == 1)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.name, err)
+	}
+
+	used, err := usedGlyphs(tables["glyf"], locations, slices.Collect(maps.Values(f.glyphs)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.name, err)
+	}
+
+	var glyf bytes.Buffer
+
+	loca := make([]byte, 0, 4*len(locations))
+
+	for glyph := range len(locations) - 1 {
+		loca = binary.BigEndian.AppendUint32(loca, uint32(glyf.Len())) //nolint:gosec
+
+		if used[glyph] {
+			glyf.Write(tables["glyf"][locations[glyph]:locations[glyph+1]])
+			glyf.Write(make([]byte, (4-glyf.Len()%4)%4))
+		}
+	}
+
+	loca = binary.BigEndian.AppendUint32(loca, uint32(glyf.Len())) //nolint:gosec
+
+	// locations are written in long format, and checksum of the whole font isn't kept as it's changed
+	binary.BigEndian.PutUint16(head[50:], 1)
+	binary.BigEndian.PutUint32(head[8:], 0)
+
+	tables["head"], tables["loca"], tables["glyf"] = head, loca, glyf.Bytes()
+
+	return writeFont(tables), nil
+}
+
+// tables returns tables of the font which are needed for the subset
+func (f *font) tables() (map[string][]byte, error) {
+	if len(f.data) < 12 {
+		return nil, fmt.Errorf("%w: %s has no table directory", errBadFont, f.name)
+	}
+
+	count := int(binary.BigEndian.Uint16(f.data[4:]))
+	tables := make(map[string][]byte, len(subsetTables))
+
+	for i := range count {
+		record := 12 + 16*i
+		if record+16 > len(f.data) {
+			return nil, fmt.Errorf("%w: %s has short table directory", errBadFont, f.name)
+		}
+
+		tag := string(f.data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(f.data[record+8:]))
+		length := int(binary.BigEndian.Uint32(f.data[record+12:]))
+
+		if offset < 0 || length < 0 || offset+length > len(f.data) {
+			return nil, fmt.Errorf("%w: table %q of %s is out of font", errBadFont, tag, f.name)
+		}
+
+		if slices.Contains(subsetTables, tag) {
+			tables[tag] = f.data[offset : offset+length]
+		}
+	}
+
+	return tables, nil
+}
+
+// glyphLocations returns offsets of glyphs in glyf table, the last one is the end of the last glyph
+func glyphLocations(loca []byte, count int, long bool) ([]int, error) {
+	size := 2
+	if long {
+		size = 4
+	}
+
+	if len(loca) < size*(count+1) {
+		return nil, fmt.Errorf("%w: loca table is shorter than %d glyphs", errBadFont, count)
+	}
+
+	locations := make([]int, 0, count+1)
+	for i := range count + 1 {
+		if long {
+			locations = append(locations, int(binary.BigEndian.Uint32(loca[4*i:])))
+		} else {
+			locations = append(locations, 2*int(binary.BigEndian.Uint16(loca[2*i:])))
+		}
+	}
+
+	return locations, nil
+}
+
+// usedGlyphs returns glyphs which outlines are kept, they're the notdef glyph,
+// given glyphs and components of composite glyphs
+func usedGlyphs(glyf []byte, locations []int, glyphs []uint16) (map[int]bool, error) {
+	used := map[int]bool{}
+	queue := []int{0}
+
+	for _, glyph := range glyphs {
+		queue = append(queue, int(glyph))
+	}
+
+	for len(queue) != 0 {
+		glyph := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		if used[glyph] {
+			continue
+		}
+
+		if glyph+1 >= len(locations) || locations[glyph+1] > len(glyf) || locations[glyph] > locations[glyph+1] {
+			return nil, fmt.Errorf("%w: glyph %d is out of glyf table", errBadFont, glyph)
+		}
+
+		used[glyph] = true
+
+		data := glyf[locations[glyph]:locations[glyph+1]]
+		if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 { //nolint:gosec
+			continue
+		}
+
+		components, err := glyphComponents(data[10:])
+		if err != nil {
+			return nil, fmt.Errorf("glyph %d: %w", glyph, err)
+		}
+
+		queue = append(queue, components...)
+	}
+
+	return used, nil
+}
+
+// glyphComponents returns glyphs referred by composite glyph description
+func glyphComponents(data []byte) ([]int, error) {
+	var components []int
+
+	for {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("%w: short composite glyph", errBadFont)
+		}
+
+		flags := binary.BigEndian.Uint16(data)
+		components = append(components, int(binary.BigEndian.Uint16(data[2:])))
+
+		size := 4 + 2
+		if flags&argsAreWords != 0 {
+			size = 4 + 4
+		}
+
+		switch {
+		case flags&haveScale != 0:
+			size += 2
+		case flags&haveXYScale != 0:
+			size += 4
+		case flags&haveTwoByTwo != 0:
+			size += 8
+		}
+
+		if flags&moreComponents == 0 {
+			return components, nil
+		}
+
+		if len(data) < size {
+			return nil, fmt.Errorf("%w: short composite glyph", errBadFont)
+		}
+
+		data = data[size:]
+	}
+}
+
+// writeFont writes TrueType font of tables
+func writeFont(tables map[string][]byte) []byte {
+	tags := slices.Sorted(maps.Keys(tables))
+
+	searchRange, selector := 1, 0
+	for searchRange*2 <= len(tags) {
+		searchRange *= 2
+		selector++
+	}
+
+	var b bytes.Buffer
+
+	header := binary.BigEndian.AppendUint32(nil, 0x00010000)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(tags)))                  //nolint:gosec
+	header = binary.BigEndian.AppendUint16(header, uint16(16*searchRange))             //nolint:gosec
+	header = binary.BigEndian.AppendUint16(header, uint16(selector))                   //nolint:gosec
+	header = binary.BigEndian.AppendUint16(header, uint16(16*(len(tags)-searchRange))) //nolint:gosec
+	b.Write(header)
+
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		b.WriteString(tag)
+		b.Write(binary.BigEndian.AppendUint32(nil, checksum(tables[tag])))
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(offset)))           //nolint:gosec
+		b.Write(binary.BigEndian.AppendUint32(nil, uint32(len(tables[tag])))) //nolint:gosec
+
+		offset += (len(tables[tag]) + 3) &^ 3
+	}
+
+	for _, tag := range tags {
+		b.Write(tables[tag])
+		b.Write(make([]byte, (4-len(tables[tag])%4)%4))
+	}
+
+	return b.Bytes()
+}
+
+// checksum is a sum of big endian words of table padded with zeros
+func checksum(table []byte) uint32 {
+	var sum uint32
+
+	padded := append(slices.Clone(table), make([]byte, (4-len(table)%4)%4)...)
+	for i := 0; i < len(padded); i += 4 {
+		sum += binary.BigEndian.Uint32(padded[i:])
+	}
+
+	return sum
+}
@@ -0,0 +1,319 @@
+// Package pdf writes simple printable documents of text lines.
+// Documents embed subsets of Go Mono fonts, so text in any script covered by the fonts looks the same in every viewer.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+)
+
+// A4 page in points and its margins
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+
+	headingSize = 16
+	textSize    = 11
+	// lineSpacing is a height of a line relative to font size
+	lineSpacing = 1.4
+	// glyphWidth is a width of Go Mono glyph relative to font size
+	glyphWidth = 0.6
+	// wrapIndent is an extra indent of continuation of wrapped line
+	wrapIndent = 4
+)
+
+type line struct {
+	text string
+	bold bool
+	size float64
+}
+
+// Document is a PDF document of monospaced text lines on A4 pages.
+// Runes missing in the fonts are replaced by question marks.
+type Document struct {
+	title string
+	lines []line
+}
+
+// New returns empty document with title in its metadata
+func New(title string) *Document {
+	return &Document{
+		title: title,
+		lines: []line{},
+	}
+}
+
+// Heading adds bold line of larger font
+func (d *Document) Heading(text string) {
+	d.lines = append(d.lines, line{text: text, bold: true, size: headingSize})
+}
+
+// Bold adds bold line of regular size
+func (d *Document) Bold(text string) {
+	d.lines = append(d.lines, line{text: text, bold: true, size: textSize})
+}
+
+// Text adds line of regular font, long lines are wrapped keeping indent of leading spaces
+func (d *Document) Text(text string) {
+	d.lines = append(d.lines, line{text: text, bold: false, size: textSize})
+}
+
+// WriteTo writes rendered document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	regular, err := newFont("GoMono", gomono.TTF)
+	if err != nil {
+		return 0, err
+	}
+
+	bold, err := newFont("GoMono-Bold", gomonobold.TTF)
+	if err != nil {
+		return 0, err
+	}
+
+	pages, err := d.renderPages(regular, bold)
+	if err != nil {
+		return 0, err
+	}
+
+	out := &writer{buf: bytes.Buffer{}, offsets: []int{}}
+	out.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const (
+		catalogObj = iota + 1
+		pagesObj
+		regularFontObj
+		boldFontObj = regularFontObj + fontObjects
+		infoObj     = boldFontObj + fontObjects
+		firstPageObj
+	)
+
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObj+2*i))
+	}
+
+	out.object("<< /Type /Catalog /Pages %d 0 R >>", pagesObj)
+	out.object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	if err = out.font(regular, regularFontObj); err != nil {
+		return 0, err
+	}
+
+	if err = out.font(bold, boldFontObj); err != nil {
+		return 0, err
+	}
+
+	out.object("<< /Title %s /Producer (ShoPlanner) >>", textString(d.title))
+
+	for i, content := range pages {
+		out.object("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, pageWidth, pageHeight, regularFontObj, boldFontObj, firstPageObj+2*i+1)
+		out.stream(content, "/Filter /FlateDecode")
+	}
+
+	out.finish(catalogObj, infoObj)
+
+	n, err := out.buf.WriteTo(w)
+	if err != nil {
+		return n, fmt.Errorf("can't write pdf: %w", err)
+	}
+
+	return n, nil
+}
+
+// renderPages returns compressed content streams of pages, fonts remember glyphs used on the pages
+func (d *Document) renderPages(regular, bold *font) ([][]byte, error) {
+	var pages [][]byte
+
+	var content bytes.Buffer
+
+	y := float64(pageHeight - margin)
+
+	flush := func() error {
+		compressed, err := compress(content.Bytes())
+		if err != nil {
+			return err
+		}
+
+		pages = append(pages, compressed)
+		content.Reset()
+		y = pageHeight - margin
+
+		return nil
+	}
+
+	for _, l := range d.lines {
+		for _, text := range wrap(l.text, int((pageWidth-2*margin)/(glyphWidth*l.size))) {
+			height := l.size * lineSpacing
+			if y-height < margin && content.Len() != 0 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+
+			y -= height
+
+			name, f := "F1", regular
+			if l.bold {
+				name, f = "F2", bold
+			}
+
+			fmt.Fprintf(&content, "BT /%s %g Tf %d %.2f Td %s Tj ET\n", name, l.size, margin, y, f.encode(text))
+		}
+	}
+
+	if content.Len() != 0 || len(pages) == 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return pages, nil
+}
+
+// wrap splits text by words to lines of at most width runes
+func wrap(text string, width int) []string {
+	text = strings.ReplaceAll(text, "\t", "    ")
+	indent := len(text) - len(strings.TrimLeft(text, " "))
+
+	var lines []string
+
+	current := text[:indent]
+	currentLen := indent
+
+	for _, word := range strings.Fields(text) {
+		wordLen := utf8.RuneCountInString(word)
+
+		if currentLen+wordLen+1 > width && strings.TrimSpace(current) != "" {
+			lines = append(lines, current)
+			current = strings.Repeat(" ", indent+wrapIndent)
+			currentLen = indent + wrapIndent
+		}
+
+		if strings.TrimSpace(current) != "" {
+			current += " "
+			currentLen++
+		}
+
+		current += word
+		currentLen += wordLen
+	}
+
+	return append(lines, current)
+}
+
+func compress(content []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write(content); err != nil {
+		return nil, fmt.Errorf("can't compress page: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("can't compress page: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// textString encodes text as UTF-16 string used in document metadata
+func textString(text string) string {
+	var b strings.Builder
+
+	b.WriteString("<FEFF")
+
+	for _, c := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&b, "%04X", c)
+	}
+
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// writer writes numbered objects and remembers their offsets for cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) object(format string, args ...any) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n", len(w.offsets))
+	fmt.Fprintf(&w.buf, format, args...)
+	w.buf.WriteString("\nendobj\n")
+}
+
+// stream writes stream object of content, dict are entries of its dictionary besides the length
+func (w *writer) stream(content []byte, dict string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d %s >>\nstream\n", len(w.offsets), len(content), dict)
+	w.buf.Write(content)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+// fontObjects is a number of objects of an embedded font
+const fontObjects = 5
+
+// font writes objects of subset of f, the first of them is a font object with number obj
+func (w *writer) font(f *font, obj int) error {
+	m, err := f.metrics()
+	if err != nil {
+		return err
+	}
+
+	subset, err := f.subset()
+	if err != nil {
+		return err
+	}
+
+	file, err := compress(subset)
+	if err != nil {
+		return err
+	}
+
+	toUnicode, err := compress(f.toUnicode())
+	if err != nil {
+		return err
+	}
+
+	name := f.baseName()
+
+	w.object("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", name, obj+1, obj+4)
+	w.object("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /DW %d /CIDToGIDMap /Identity >>", name, obj+2, m.width)
+	// flags are fixed pitch and symbolic
+	w.object("<< /Type /FontDescriptor /FontName /%s /Flags 5 /FontBBox [%d %d %d %d] /ItalicAngle 0 "+
+		"/Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, m.bbox[0], m.bbox[1], m.bbox[2], m.bbox[3], m.ascent, m.descent, m.ascent, obj+3)
+	w.stream(file, fmt.Sprintf("/Length1 %d /Filter /FlateDecode", len(subset)))
+	w.stream(toUnicode, "/Filter /FlateDecode")
+
+	return nil
+}
+
+func (w *writer) finish(rootObj, infoObj int) {
+	xref := w.buf.Len()
+
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, rootObj, infoObj, xref)
+}
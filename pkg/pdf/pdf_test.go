@@ -0,0 +1,126 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"go-backend/pkg/god"
+	"go-backend/pkg/pdf"
+)
+
+func TestDocument(t *testing.T) {
+	doc := pdf.New("Покупки")
+	doc.Heading("Покупки (weekly)")
+
+	for i := range 100 {
+		doc.Text(fmt.Sprintf("[ ] product %d, молоко %s", i, strings.Repeat("long words ", i%20)))
+	}
+
+	var out bytes.Buffer
+	_, err := doc.WriteTo(&out)
+	require.NoError(t, err)
+
+	content := out.String()
+	require.True(t, strings.HasPrefix(content, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(content, "%%EOF\n"))
+	require.Contains(t, content, "+GoMono /Encoding /Identity-H")
+	require.Contains(t, content, "+GoMono-Bold /Encoding /Identity-H")
+
+	pages := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(content)
+	require.NotNil(t, pages)
+	require.Greater(t, god.Believe(strconv.Atoi(pages[1])), 1)
+
+	// every cross-reference entry must point to the beginning of its object
+	xrefAt := god.Believe(strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(content)[1]))
+	require.True(t, strings.HasPrefix(content[xrefAt:], "xref\n"))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(content[xrefAt:], -1)
+	require.NotEmpty(t, entries)
+
+	for i, entry := range entries {
+		offset := god.Believe(strconv.Atoi(entry[1]))
+		require.True(t, strings.HasPrefix(content[offset:], fmt.Sprintf("%d 0 obj\n", i+1)))
+	}
+}
+
+func TestDocumentEmbedsFontSubsets(t *testing.T) {
+	doc := pdf.New("list")
+	doc.Heading("Ёлка")
+	doc.Text("молоко, Brot, €5 ₽")
+
+	var out bytes.Buffer
+	_, err := doc.WriteTo(&out)
+	require.NoError(t, err)
+
+	streams := inflatedStreams(t, out.Bytes())
+
+	fonts := 0
+
+	var cmaps strings.Builder
+
+	for _, stream := range streams {
+		if strings.Contains(string(stream), "begincmap") {
+			cmaps.Write(stream)
+			continue
+		}
+
+		parsed, err := sfnt.Parse(stream)
+		if err != nil {
+			continue
+		}
+
+		fonts++
+
+		var buf sfnt.Buffer
+
+		// glyphs of runes missing in the document aren't embedded
+		for r, embedded := range map[rune]bool{'л': true, 'Z': false, 'ж': false} {
+			glyph, err := parsed.GlyphIndex(&buf, r)
+			require.NoError(t, err)
+			require.NotZero(t, glyph)
+
+			segments, err := parsed.LoadGlyph(&buf, glyph, fixed.I(12), nil)
+			require.NoError(t, err)
+			require.Equal(t, embedded, len(segments) != 0, string(r))
+		}
+	}
+
+	require.Equal(t, 2, fonts)
+
+	// copied text keeps runes, the ruble sign is missing in the font and is drawn as question mark
+	require.Contains(t, cmaps.String(), "<0401>")
+	require.Contains(t, cmaps.String(), "<043C>")
+	require.NotContains(t, cmaps.String(), "<20BD>")
+	require.Less(t, len(out.Bytes()), 64<<10)
+}
+
+// inflatedStreams returns decompressed content of every stream of document
+func inflatedStreams(t *testing.T, content []byte) [][]byte {
+	t.Helper()
+
+	var streams [][]byte
+
+	for _, match := range regexp.MustCompile(`<< /Length (\d+) [^>]*>>\nstream\n`).FindAllSubmatchIndex(content, -1) {
+		length := god.Believe(strconv.Atoi(string(content[match[2]:match[3]])))
+
+		r, err := zlib.NewReader(bytes.NewReader(content[match[1] : match[1]+length]))
+		require.NoError(t, err)
+
+		stream, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		streams = append(streams, stream)
+	}
+
+	return streams
+}